@@ -0,0 +1,53 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/schemadiff"
+)
+
+// runDiff implements "codegen diff old.json new.json". It prints every
+// classified change and returns 1 when any change is breaking, 2 on usage or
+// parse errors.
+func runDiff(args []string) int {
+	fs := flag.NewFlagSet("diff", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: codegen diff old.json new.json")
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return 2
+	}
+
+	old, err := parser.Parse(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to parse %s: %v\n", fs.Arg(0), err)
+		return 2
+	}
+	new, err := parser.Parse(fs.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to parse %s: %v\n", fs.Arg(1), err)
+		return 2
+	}
+
+	changes := schemadiff.Compare(old, new)
+	if len(changes) == 0 {
+		fmt.Println("✓ No schema changes")
+		return 0
+	}
+	for _, c := range changes {
+		fmt.Println(c)
+	}
+	if schemadiff.HasBreaking(changes) {
+		fmt.Fprintln(os.Stderr, "❌ Breaking schema changes detected")
+		return 1
+	}
+	fmt.Println("✓ All schema changes are compatible")
+	return 0
+}
@@ -0,0 +1,54 @@
+package generators
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// DocsGenerator renders REFERENCE.md, the Markdown reference of the schema,
+// next to the schema itself, with examples from the conformance vectors in
+// tools/tests/vectors. The output directory is not used.
+type DocsGenerator struct{}
+
+func (g *DocsGenerator) Generate(s *parser.Schema, outputDir string) error {
+	if err := checkUnsuffixed(s, g.Language()); err != nil {
+		return err
+	}
+	dir, vectorsDir := docsDirs(s)
+	if err := templates.WriteDocs(dir, s, vectorsDir); err != nil {
+		return fmt.Errorf("failed to write reference: %w", err)
+	}
+	return nil
+}
+
+// Check compares REFERENCE.md on disk with what the schema and vectors
+// would produce.
+func (g *DocsGenerator) Check(s *parser.Schema, outputDir string) error {
+	if err := checkUnsuffixed(s, g.Language()); err != nil {
+		return err
+	}
+	dir, vectorsDir := docsDirs(s)
+	want, err := templates.Docs(s, vectorsDir)
+	if err != nil {
+		return err
+	}
+	return checkFile(filepath.Join(dir, "REFERENCE.md"), want)
+}
+
+// docsDirs returns the schema's directory and the vectors directory of the
+// repository it is in.
+func docsDirs(s *parser.Schema) (dir, vectorsDir string) {
+	dir = filepath.Dir(s.Path)
+	return dir, filepath.Join(dir, "..", "tools", "tests", "vectors")
+}
+
+func (g *DocsGenerator) Language() string {
+	return "Markdown reference"
+}
+
+func (g *DocsGenerator) NeedsExternal() bool {
+	return false
+}
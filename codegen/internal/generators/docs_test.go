@@ -0,0 +1,121 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// TestDocsUpToDate fails when schema/REFERENCE.md has drifted from the
+// schema or the vectors.
+func TestDocsUpToDate(t *testing.T) {
+	if err := (&DocsGenerator{}).Check(parseRealSchema(t), ""); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestDocsGenerator(t *testing.T) {
+	root := t.TempDir()
+	schemaDir := filepath.Join(root, "schema")
+	vectorsDir := filepath.Join(root, "tools", "tests", "vectors")
+	if err := os.MkdirAll(schemaDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(vectorsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	schemaPath := filepath.Join(schemaDir, "schema.json")
+	content := `{
+		"title": "Test Schema v0.1",
+		"$defs": {
+			"Statement": {
+				"type": "object",
+				"properties": {
+					"where": { "$ref": "#/$defs/Filter" },
+					"group": {
+						"type": "object",
+						"description": "Grouping | keys",
+						"properties": { "keys": { "type": "array", "items": { "type": "string" } } }
+					}
+				}
+			},
+			"Filter": {
+				"type": "object",
+				"description": "Boolean predicate.",
+				"properties": {
+					"not": { "$ref": "#/$defs/Filter" },
+					"conditions": { "type": "array", "items": { "$ref": "#/$defs/Condition" } }
+				}
+			},
+			"Condition": {
+				"type": "object",
+				"required": ["field", "op"],
+				"properties": {
+					"field": { "type": "string", "minLength": 1 },
+					"op": { "oneOf": [{ "enum": ["eq", "in"] }, { "type": "string", "pattern": "^custom:.+$" }] },
+					"path": { "type": "array", "items": { "type": "string" }, "deprecated": true }
+				}
+			}
+		}
+	}`
+	if err := os.WriteFile(schemaPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vectors := `[
+		{ "name": "small", "shape": { "where": { "conditions": [{ "field": "a", "op": "eq", "value": 1 }] } } },
+		{ "name": "larger", "shape": { "where": { "conditions": [{ "field": "b", "op": "in", "value": [1, 2] }] }, "group": { "keys": ["b"] } } }
+	]`
+	if err := os.WriteFile(filepath.Join(vectorsDir, "query-shapes.json"), []byte(vectors), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(schemaPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	gen := &DocsGenerator{}
+	if err := gen.Generate(s, ""); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := gen.Check(s, ""); err != nil {
+		t.Fatalf("Check() after Generate() error = %v", err)
+	}
+	reference, err := os.ReadFile(filepath.Join(schemaDir, "REFERENCE.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<!-- Code generated by codegen from schema/schema.json. DO NOT EDIT. -->\n",
+		"- [Statement.group](#statementgroup)\n",
+		"| `where` | [Filter](#filter) |  |  |  |\n",
+		"| `group` | [object](#statementgroup) |  |  | Grouping \\| keys |\n",
+		"## Filter\n\nBoolean predicate.\n\n",
+		"| `not` | [Filter](#filter) |  |  |  |\n",
+		"| `conditions` | [Condition](#condition)[] |  |  |  |\n",
+		"| `field` | string | yes | min length 1 |  |\n",
+		"| `op` | [operator](#operators) | yes |  |  |\n",
+		"| `path` | string[] |  | deprecated |  |\n",
+		// The statement with more properties is its example; the smaller
+		// filter of the other is the filter's
+		"Example, from `query-shapes.json` vector `larger`:\n",
+		"vector `small`:\n\n```json\n{\n  \"conditions\": [\n",
+		"Custom operators match `^custom:.+$`",
+		"| `eq` | any value | Field equals the value | `{\"field\":\"a\",\"op\":\"eq\",\"value\":1}` |\n",
+		"| `in` | array | Field equals one of the values | `{\"field\":\"b\",\"op\":\"in\",\"value\":[1,2]}` |\n",
+	} {
+		if !strings.Contains(string(reference), want) {
+			t.Errorf("REFERENCE.md missing %q", want)
+		}
+	}
+
+	// An operator without documented semantics fails generation
+	s.Definitions["Condition"].(map[string]interface{})["properties"].(map[string]interface{})["op"] = map[string]interface{}{
+		"enum": []interface{}{"eq", "near"},
+	}
+	if err := gen.Generate(s, ""); err == nil || !strings.Contains(err.Error(), "near") {
+		t.Errorf("Generate() with an undocumented operator error = %v", err)
+	}
+}
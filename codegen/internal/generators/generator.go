@@ -13,6 +13,12 @@ type Generator interface {
 	NeedsExternal() bool // Does it need external tools like npm?
 }
 
+// Checker is implemented by generators whose output is committed by hand and
+// can be verified against the schema without writing it
+type Checker interface {
+	Check(s *parser.Schema, outputDir string) error
+}
+
 // Get returns a generator for the specified language
 func Get(lang string) Generator {
 	switch lang {
@@ -26,6 +32,18 @@ func Get(lang string) Generator {
 		return &DotNetGenerator{}
 	case "python", "py":
 		return &PythonGenerator{}
+	case "rust", "rs":
+		return &RustGenerator{}
+	case "proto", "grpc":
+		return &ProtoGenerator{}
+	case "openapi":
+		return &OpenAPIGenerator{}
+	case "kotlin", "kt":
+		return &KotlinGenerator{}
+	case "swift":
+		return &SwiftGenerator{}
+	case "docs", "markdown", "md":
+		return &DocsGenerator{}
 	case "php":
 		return &PHPGenerator{}
 	default:
@@ -33,6 +51,15 @@ func Get(lang string) Generator {
 	}
 }
 
+// checkUnsuffixed returns an error when s is an older spec version, for
+// generators whose output has no version-suffixed form.
+func checkUnsuffixed(s *parser.Schema, lang string) error {
+	if s.Suffix != "" {
+		return fmt.Errorf("%s has no version-suffixed packages, so it is generated from the newest spec version only, not v%s", lang, s.SpecVersion())
+	}
+	return nil
+}
+
 // Placeholder generators for future languages
 
 type JavaGenerator struct{}
@@ -55,16 +82,6 @@ func (g *DotNetGenerator) Language() string { return ".NET/C#" }
 
 func (g *DotNetGenerator) NeedsExternal() bool { return false }
 
-type PythonGenerator struct{}
-
-func (g *PythonGenerator) Generate(s *parser.Schema, outputDir string) error {
-	return fmt.Errorf("python generator not yet implemented")
-}
-
-func (g *PythonGenerator) Language() string { return "Python" }
-
-func (g *PythonGenerator) NeedsExternal() bool { return false }
-
 type PHPGenerator struct{}
 
 func (g *PHPGenerator) Generate(s *parser.Schema, outputDir string) error {
@@ -1,108 +1,123 @@
 package generators
 
 import (
+	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 
 	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
 )
 
+// GoGenerator renders pkgs/go/types/types.go with a first-party template that
+// keeps the hand-written idioms (pointer-to-slice optionals, doc comments,
+// field comments). The file stays hand-maintained; Check reports drift. It
+// also generates pkgs/go/types/version.go, the version constants, and
+// pkgs/go/tests/constraints.go, the enums and patterns the validators check.
+// An older spec version, which has a Schema.Suffix, gets types and version.go
+// only, in a package of its own such as pkgs/go/typesv0_1_0.
 type GoGenerator struct{}
 
 func (g *GoGenerator) Generate(s *parser.Schema, outputDir string) error {
-	// NOTE: Go types are currently HAND-WRITTEN to preserve idiomatic patterns:
-	// - Sealed Scalar interface (vs plain interface{})
-	// - Pointer-to-slice for optional arrays (nil vs empty distinction)
-	// - Custom package documentation
-	//
-	// See GO_GENERATION_ANALYSIS.md for details on why we don't auto-generate.
-	//
-	// To enable auto-generation, uncomment the following block and update testkit code:
-	/*
-	if err := g.generateTypes(s, outputDir); err != nil {
-		return fmt.Errorf("failed to generate types: %w", err)
-	}
-	*/
+	typesDir, err := goTypesDir(outputDir, s)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(typesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
 
-	// For now, just verify the packages exist
-	typesDir := filepath.Join(outputDir, "go", "types")
-	if _, err := os.Stat(typesDir); os.IsNotExist(err) {
-		return fmt.Errorf("go types directory does not exist: %s", typesDir)
+	if err := templates.WriteGoTypes(typesDir, s); err != nil {
+		return fmt.Errorf("failed to write types: %w", err)
+	}
+	if err := templates.WriteGoVersion(typesDir, s); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+	// The testkit validates the newest version only
+	if s.Suffix != "" {
+		return nil
 	}
 
-	testsDir := filepath.Join(outputDir, "go", "tests")
-	if _, err := os.Stat(testsDir); os.IsNotExist(err) {
-		return fmt.Errorf("go tests directory does not exist: %s", testsDir)
+	testsDir := filepath.Join(filepath.Dir(typesDir), "tests")
+	if err := os.MkdirAll(testsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := templates.WriteGoConstraints(testsDir, s); err != nil {
+		return fmt.Errorf("failed to write constraints: %w", err)
 	}
 
 	return nil
 }
 
-func (g *GoGenerator) generateTypes(s *parser.Schema, outputDir string) error {
-	// Validate and clean output directory
-	outputDir = filepath.Clean(outputDir)
-	if strings.Contains(outputDir, "..") {
-		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+// Check compares types.go, version.go and constraints.go on disk with what the schema
+// would produce and returns an error naming the first differing line.
+func (g *GoGenerator) Check(s *parser.Schema, outputDir string) error {
+	typesDir, err := goTypesDir(outputDir, s)
+	if err != nil {
+		return err
 	}
-
-	typesDir := filepath.Join(outputDir, "go", "types")
-	if err := os.MkdirAll(typesDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+	want, err := templates.GoTypes(s)
+	if err != nil {
+		return err
+	}
+	if err := checkFile(filepath.Join(typesDir, "types.go"), want); err != nil {
+		return err
 	}
 
-	outputFile := filepath.Join(typesDir, "types.go")
-
-	// Check if go-jsonschema is available
-	goJsonSchemaPath, err := exec.LookPath("go-jsonschema")
+	want, err = templates.GoVersion(s)
 	if err != nil {
-		// Try in GOPATH/bin
-		cmd := exec.Command("go", "env", "GOPATH")
-		gopathBytes, err := cmd.Output()
-		if err != nil {
-			return fmt.Errorf("go-jsonschema not found and could not determine GOPATH: %w. Install with: go install github.com/atombender/go-jsonschema@latest", err)
-		}
-		gopath := strings.TrimSpace(string(gopathBytes))
-		if gopath == "" {
-			return fmt.Errorf("go-jsonschema not found in PATH and GOPATH is empty. Install with: go install github.com/atombender/go-jsonschema@latest")
-		}
-		goJsonSchemaPath = filepath.Join(gopath, "bin", "go-jsonschema")
-		if _, err := os.Stat(goJsonSchemaPath); err != nil {
-			return fmt.Errorf("go-jsonschema not found at %s. Install with: go install github.com/atombender/go-jsonschema@latest", goJsonSchemaPath)
-		}
+		return err
 	}
-
-	// Validate schema path before using
-	schemaPath := filepath.Clean(s.Path)
-	if !filepath.IsAbs(schemaPath) {
-		var err error
-		schemaPath, err = filepath.Abs(schemaPath)
-		if err != nil {
-			return fmt.Errorf("failed to resolve schema path: %w", err)
-		}
+	if err := checkFile(filepath.Join(typesDir, "version.go"), want); err != nil {
+		return err
 	}
-	if strings.Contains(schemaPath, "..") {
-		return fmt.Errorf("invalid schema path (directory traversal detected): %s", schemaPath)
+	if s.Suffix != "" {
+		return nil
 	}
 
-	// Call go-jsonschema
-	cmd := exec.Command(goJsonSchemaPath,
-		"-p", "types",
-		"--only-models",
-		"--capitalization", "ID",
-		"--tags", "json",
-		"-o", outputFile,
-		schemaPath,
-	)
+	want, err = templates.GoConstraints(s)
+	if err != nil {
+		return err
+	}
+	return checkFile(filepath.Join(filepath.Dir(typesDir), "tests", "constraints.go"), want)
+}
 
-	output, err := cmd.CombinedOutput()
+// checkFile returns an error naming the first line where the file at path
+// differs from want.
+func checkFile(path string, want []byte) error {
+	got, err := os.ReadFile(path)
 	if err != nil {
-		return fmt.Errorf("go-jsonschema failed: %w\nOutput: %s", err, output)
+		return fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	if bytes.Equal(got, want) {
+		return nil
 	}
 
-	return nil
+	gotLines := strings.Split(string(got), "\n")
+	wantLines := strings.Split(string(want), "\n")
+	for i := 0; ; i++ {
+		var gl, wl string
+		if i < len(gotLines) {
+			gl = gotLines[i]
+		}
+		if i < len(wantLines) {
+			wl = wantLines[i]
+		}
+		if gl != wl || i >= len(gotLines) || i >= len(wantLines) {
+			return fmt.Errorf("%s has drifted from the schema at line %d:\n  file:   %s\n  schema: %s", path, i+1, gl, wl)
+		}
+	}
+}
+
+func goTypesDir(outputDir string, s *parser.Schema) (string, error) {
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return "", fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+	return filepath.Join(outputDir, "go", templates.GoPackage(s)), nil
 }
 
 func (g *GoGenerator) Language() string {
@@ -110,5 +125,5 @@ func (g *GoGenerator) Language() string {
 }
 
 func (g *GoGenerator) NeedsExternal() bool {
-	return true // Needs go-jsonschema
+	return false
 }
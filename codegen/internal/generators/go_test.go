@@ -0,0 +1,86 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func parseRealSchema(t *testing.T) *parser.Schema {
+	t.Helper()
+	schemaPath, err := filepath.Abs("../../../schema/v0-1-0.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(schemaPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return s
+}
+
+// TestGoTypesUpToDate fails when pkgs/go/types/types.go has drifted from
+// the schema.
+func TestGoTypesUpToDate(t *testing.T) {
+	pkgs, err := filepath.Abs("../../../pkgs")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := (&GoGenerator{}).Check(parseRealSchema(t), pkgs); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestGoGeneratorCheckReportsDrift(t *testing.T) {
+	s := parseRealSchema(t)
+	out := t.TempDir()
+	gen := &GoGenerator{}
+	if err := gen.Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if err := gen.Check(s, out); err != nil {
+		t.Fatalf("Check() after Generate() error = %v", err)
+	}
+
+	// A definition added to the schema is rendered with default naming and
+	// reported as drift until types.go is updated
+	s.Definitions["Extra"] = map[string]interface{}{
+		"type":        "object",
+		"description": "Extra is not in the Go type list.",
+		"properties": map[string]interface{}{
+			"tx_id": map[string]interface{}{"type": "string"},
+			"tags":  map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+			"meta": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"size": map[string]interface{}{"type": "integer"}},
+			},
+		},
+	}
+	err := gen.Check(s, out)
+	if err == nil || !strings.Contains(err.Error(), "drifted") {
+		t.Fatalf("Check() error = %v, want drift", err)
+	}
+
+	if err := gen.Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	src, err := os.ReadFile(filepath.Join(out, "go", "types", "types.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"// Extra is not in the Go type list.\ntype Extra struct {\n",
+		"\tMeta *ExtraMeta `json:\"meta,omitempty\"`\n",
+		"\tTags *[]string  `json:\"tags,omitempty\"`\n",
+		"\tTxID *string    `json:\"tx_id,omitempty\"`\n",
+		"type ExtraMeta struct {\n\tSize *int `json:\"size,omitempty\"`\n}\n",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("types.go missing %q\n%s", want, src)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// KotlinGenerator emits kotlinx.serialization data classes and a Gradle
+// build under <output>/kotlin, using Go templates only.
+type KotlinGenerator struct{}
+
+func (g *KotlinGenerator) Generate(s *parser.Schema, outputDir string) error {
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+
+	projectDir := filepath.Join(outputDir, templates.Project(s, "kotlin"))
+	packageDir := filepath.Join(append([]string{projectDir, "src", "main", "kotlin"}, strings.Split(templates.KotlinPackage(s), ".")...)...)
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := templates.WriteKotlinModels(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write models: %w", err)
+	}
+
+	if err := templates.WriteKotlinVersion(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
+	if err := templates.WriteKotlinProject(projectDir, s); err != nil {
+		return fmt.Errorf("failed to write Gradle build: %w", err)
+	}
+
+	return nil
+}
+
+func (g *KotlinGenerator) Language() string {
+	return "Kotlin"
+}
+
+func (g *KotlinGenerator) NeedsExternal() bool {
+	return false
+}
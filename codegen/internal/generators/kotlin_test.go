@@ -0,0 +1,84 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func TestKotlinGenerator(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	content := `{
+		"title": "Test Schema v0.1",
+		"$defs": {
+			"Filter": {
+				"type": "object",
+				"properties": {
+					"not": { "$ref": "#/$defs/Filter" },
+					"conditions": { "type": "array", "items": { "$ref": "#/$defs/Condition" } }
+				}
+			},
+			"Condition": {
+				"type": "object",
+				"required": ["field", "op"],
+				"properties": {
+					"field": { "type": "string", "minLength": 1 },
+					"op": { "enum": ["eq", "ne"] },
+					"field_path": { "type": "array", "items": { "type": "string" } },
+					"group": {
+						"type": "object",
+						"properties": { "keys": { "type": "array", "items": { "type": "string" } } }
+					}
+				}
+			}
+		}
+	}`
+	if err := os.WriteFile(schemaPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(schemaPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := (&KotlinGenerator{}).Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	models, err := os.ReadFile(filepath.Join(out, "kotlin", "src", "main", "kotlin", "dev", "includekit", "spec", "Models.kt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"data class Filter(\n    @SerialName(\"not\") val not: Filter? = null,\n",
+		"    @SerialName(\"field\") val field: String,\n",
+		"@Serializable\nenum class ConditionOp {\n    @SerialName(\"eq\") EQ,\n    @SerialName(\"ne\") NE,\n}\n",
+		"    @SerialName(\"op\") val op: ConditionOp,\n",
+		"    @SerialName(\"field_path\") val fieldPath: List<String>? = null,\n",
+		"data class ConditionGroup(\n",
+		"    @SerialName(\"group\") val group: ConditionGroup? = null,\n",
+	} {
+		if !strings.Contains(string(models), want) {
+			t.Errorf("Models.kt missing %q\n%s", want, models)
+		}
+	}
+
+	version, err := os.ReadFile(filepath.Join(out, "kotlin", "src", "main", "kotlin", "dev", "includekit", "spec", "Version.kt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(version), "const val SPEC_VERSION = \"0.1.0\"\n") {
+		t.Errorf("Version.kt missing SPEC_VERSION:\n%s", version)
+	}
+
+	for _, name := range []string{"build.gradle.kts", "settings.gradle.kts"} {
+		if _, err := os.Stat(filepath.Join(out, "kotlin", name)); err != nil {
+			t.Errorf("%s not written: %v", name, err)
+		}
+	}
+}
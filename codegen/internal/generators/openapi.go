@@ -0,0 +1,47 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// OpenAPIGenerator emits an OpenAPI 3.1 document of the Engine HTTP
+// contract, for generating HTTP clients in any language, under
+// <output>/openapi.
+type OpenAPIGenerator struct{}
+
+func (g *OpenAPIGenerator) Generate(s *parser.Schema, outputDir string) error {
+	if err := checkUnsuffixed(s, g.Language()); err != nil {
+		return err
+	}
+
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+
+	dir := filepath.Join(outputDir, "openapi")
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := templates.WriteOpenAPI(dir, s); err != nil {
+		return fmt.Errorf("failed to write openapi.json: %w", err)
+	}
+
+	return nil
+}
+
+func (g *OpenAPIGenerator) Language() string {
+	return "OpenAPI"
+}
+
+func (g *OpenAPIGenerator) NeedsExternal() bool {
+	return false
+}
@@ -0,0 +1,71 @@
+package generators
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIGenerator(t *testing.T) {
+	out := t.TempDir()
+	s := parseRealSchema(t)
+	if err := (&OpenAPIGenerator{}).Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(out, "openapi", "openapi.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		OpenAPI    string                                       `json:"openapi"`
+		Paths      map[string]map[string]json.RawMessage        `json:"paths"`
+		Components map[string]map[string]map[string]interface{} `json:"components"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatalf("openapi.json is not JSON: %v", err)
+	}
+	if doc.OpenAPI != "3.1.0" {
+		t.Errorf("openapi = %q", doc.OpenAPI)
+	}
+
+	// Every schema definition is a component, in schema order
+	schemas := doc.Components["schemas"]
+	for name := range s.Definitions {
+		if _, ok := schemas[name]; !ok {
+			t.Errorf("components.schemas missing %s", name)
+		}
+	}
+	if !strings.Contains(string(data), "\"Query\": {\n        \"type\": \"object\",\n        \"additionalProperties\": false,\n        \"properties\": {\n          \"model\"") {
+		t.Error("Query keys are not in schema order")
+	}
+
+	// Every reference resolves
+	for _, ref := range regexp.MustCompile(`"\$ref": "([^"]*)"`).FindAllStringSubmatch(string(data), -1) {
+		parts := strings.Split(strings.TrimPrefix(ref[1], "#/components/"), "/")
+		if len(parts) != 2 || doc.Components[parts[0]][parts[1]] == nil {
+			t.Errorf("unresolved $ref %s", ref[1])
+		}
+	}
+
+	// Every route of the HTTP server is described
+	server, err := os.ReadFile("../../../pkgs/go/tests/httpserver/httpserver.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := regexp.MustCompile(`HandleFunc\("(\w+) (/[\w-]*)"`).FindAllStringSubmatch(string(server), -1)
+	if len(routes) == 0 {
+		t.Fatal("no routes found in httpserver.go")
+	}
+	for _, route := range routes {
+		if _, ok := doc.Paths[route[2]][strings.ToLower(route[1])]; !ok {
+			t.Errorf("paths missing %s %s", route[1], route[2])
+		}
+	}
+	if len(doc.Paths) != len(routes) {
+		t.Errorf("%d paths, want %d", len(doc.Paths), len(routes))
+	}
+}
@@ -0,0 +1,52 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// ProtoGenerator emits a proto3 definition of the schema and the Engine
+// service, plus a Go gRPC server adapter for any mock.Engine, under
+// <output>/proto/includekit/v0. Compiling them needs protoc; generating
+// them does not.
+type ProtoGenerator struct{}
+
+func (g *ProtoGenerator) Generate(s *parser.Schema, outputDir string) error {
+	if err := checkUnsuffixed(s, g.Language()); err != nil {
+		return err
+	}
+
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+
+	packageDir := filepath.Join(outputDir, "proto", "includekit", "v0")
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := templates.WriteProto(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write engine.proto: %w", err)
+	}
+
+	if err := templates.WriteProtoServer(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write server adapter: %w", err)
+	}
+
+	return nil
+}
+
+func (g *ProtoGenerator) Language() string {
+	return "Protobuf/gRPC"
+}
+
+func (g *ProtoGenerator) NeedsExternal() bool {
+	return false
+}
@@ -0,0 +1,307 @@
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestProtoGenerator(t *testing.T) {
+	out := t.TempDir()
+	if err := (&ProtoGenerator{}).Generate(parseRealSchema(t), out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	dir := filepath.Join(out, "proto", "includekit", "v0")
+
+	protoFile, err := os.ReadFile(filepath.Join(dir, "engine.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proto := string(protoFile)
+	for _, want := range []string{
+		"package includekit.v0;\n",
+		"message Filter {\n  repeated Filter and = 1;\n  repeated Filter or = 2;\n  Filter not = 3;\n",
+		"  optional int32 limit = 5;\n",
+		"  google.protobuf.Value value = 4;\n",
+		"  repeated string path = 5 [deprecated = true];\n",
+		"  string action = 2; // \"insert\" | \"update\" | \"delete\"\n",
+		"  map<string, google.protobuf.ListValue> records = 3;\n",
+		"  DependenciesGroupBy group_by = 7;\n",
+		"  repeated google.protobuf.Struct values = 2;\n",
+		"  rpc Invalidate(Mutation) returns (InvalidateResponse);\n",
+	} {
+		if !strings.Contains(proto, want) {
+			t.Errorf("engine.proto missing %q", want)
+		}
+	}
+
+	// Every Engine method is served
+	fset := token.NewFileSet()
+	iface, err := parser.ParseFile(fset, "../../../pkgs/go/tests/mock/interface.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	engine := iface.Scope.Lookup("Engine").Decl.(*ast.TypeSpec).Type.(*ast.InterfaceType)
+	server, err := parser.ParseFile(fset, filepath.Join(dir, "engine_server.go"), nil, 0)
+	if err != nil {
+		t.Fatalf("engine_server.go does not parse: %v", err)
+	}
+	served := map[string]bool{}
+	for _, decl := range server.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv != nil {
+			served[fn.Name.Name] = true
+		}
+	}
+	for _, method := range engine.Methods.List {
+		name := method.Names[0].Name
+		if !strings.Contains(proto, "  rpc "+name+"(") {
+			t.Errorf("engine.proto has no rpc for Engine.%s", name)
+		}
+		if !served[name] {
+			t.Errorf("engine_server.go does not implement Engine.%s", name)
+		}
+	}
+}
+
+// TestProtoJSONMapping checks that the JSON format survives a round trip
+// through engine.proto with protojson and UseProtoNames, as the server
+// adapter does: every field of every vector document, and of the Go mock's
+// engine messages, has a proto field of a type that holds its value.
+func TestProtoJSONMapping(t *testing.T) {
+	out := t.TempDir()
+	if err := (&ProtoGenerator{}).Generate(parseRealSchema(t), out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	protoFile, err := os.ReadFile(filepath.Join(out, "proto", "includekit", "v0", "engine.proto"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	messages := parseProtoMessages(string(protoFile))
+
+	for file, docs := range map[string]map[string]string{
+		"query-shapes.json":       {"shape": "Statement"},
+		"mutations.json":          {"mutation": "Mutation"},
+		"dependencies.json":       {"shape": "Statement", "dependencies": "Dependencies"},
+		"pagination-cursors.json": {"shape": "Statement", "dependencies": "Dependencies"},
+	} {
+		data, err := os.ReadFile(filepath.Join("../../../tools/tests/vectors", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var vectors []map[string]interface{}
+		if err := json.Unmarshal(data, &vectors); err != nil {
+			t.Fatalf("%s: %v", file, err)
+		}
+		for _, vector := range vectors {
+			for key, message := range docs {
+				if vector[key] == nil {
+					continue
+				}
+				path := fmt.Sprintf("%s %v.%s", file, vector["name"], key)
+				for _, lost := range protoRoundTrip(messages, message, vector[key], path) {
+					t.Error(lost)
+				}
+			}
+		}
+	}
+
+	// Every field of the engine messages has a proto field
+	fset := token.NewFileSet()
+	iface, err := parser.ParseFile(fset, "../../../pkgs/go/tests/mock/interface.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag := regexp.MustCompile(`json:"([^",]+)`)
+	for name, obj := range iface.Scope.Objects {
+		spec, ok := obj.Decl.(*ast.TypeSpec)
+		if !ok {
+			continue
+		}
+		st, ok := spec.Type.(*ast.StructType)
+		if !ok || messages[name] == nil {
+			continue
+		}
+		for _, field := range st.Fields.List {
+			if field.Tag == nil {
+				continue
+			}
+			wire := tag.FindStringSubmatch(field.Tag.Value)
+			if wire == nil {
+				continue
+			}
+			if _, ok := messages[name][wire[1]]; !ok {
+				t.Errorf("message %s has no field %s for mock.%s", name, wire[1], name)
+			}
+		}
+	}
+}
+
+func TestProtoRoundTripReportsLosses(t *testing.T) {
+	messages := parseProtoMessages(`
+message Query {
+  string model = 1;
+  optional int32 limit = 2;
+  repeated string select = 3;
+  Filter where = 4;
+}
+message Filter {
+  map<string, google.protobuf.Value> fields = 1;
+}
+`)
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"model":"","limit":0.5,"select":[],"where":null,"distinct":true}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"q.distinct: no field Query.distinct",
+		"q.limit: 0.5 does not fit int32",
+		"q.model: the zero value of a field without presence",
+		"q.where: null",
+	}
+	if got := protoRoundTrip(messages, "Query", doc, "q"); !reflect.DeepEqual(got, want) {
+		t.Errorf("protoRoundTrip() =\n  %q\nwant\n  %q", got, want)
+	}
+}
+
+// protoField is a field of a message in engine.proto.
+type protoField struct {
+	typ      string
+	repeated bool
+	optional bool
+	mapValue string
+}
+
+var (
+	protoMessageLine = regexp.MustCompile(`^message (\w+) \{`)
+	protoFieldLine   = regexp.MustCompile(`^  (optional |repeated )?(?:map<string, ([\w.]+)>|([\w.]+)) (\w+) = \d+`)
+)
+
+// parseProtoMessages returns the fields of every top-level message, by
+// message and field name.
+func parseProtoMessages(src string) map[string]map[string]protoField {
+	messages := map[string]map[string]protoField{}
+	var current map[string]protoField
+	for _, line := range strings.Split(src, "\n") {
+		if m := protoMessageLine.FindStringSubmatch(line); m != nil {
+			current = map[string]protoField{}
+			messages[m[1]] = current
+			continue
+		}
+		if line == "}" {
+			current = nil
+			continue
+		}
+		if m := protoFieldLine.FindStringSubmatch(line); m != nil && current != nil {
+			current[m[4]] = protoField{
+				typ:      m[3],
+				repeated: m[1] == "repeated ",
+				optional: m[1] == "optional ",
+				mapValue: m[2],
+			}
+		}
+	}
+	return messages
+}
+
+// protoRoundTrip lists what of the JSON value v, read into message and
+// written back with protojson, would be lost or rejected. Empty lists and
+// maps are not reported: proto3 cannot tell them from absent ones, so they
+// are omitted on the way back, which the WriteProto doc calls out.
+func protoRoundTrip(messages map[string]map[string]protoField, message string, v interface{}, path string) []string {
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return []string{fmt.Sprintf("%s: %s is not an object", path, message)}
+	}
+	var lost []string
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		value, fieldPath := obj[key], path+"."+key
+		field, ok := messages[message][key]
+		if !ok {
+			lost = append(lost, fmt.Sprintf("%s: no field %s.%s", fieldPath, message, key))
+			continue
+		}
+		switch {
+		case field.mapValue != "":
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				lost = append(lost, fmt.Sprintf("%s: not a map", fieldPath))
+				continue
+			}
+			for k, elem := range m {
+				lost = append(lost, protoValueLosses(messages, field.mapValue, elem, fieldPath+"."+k, true)...)
+			}
+		case field.repeated:
+			list, ok := value.([]interface{})
+			if !ok {
+				lost = append(lost, fmt.Sprintf("%s: not a list", fieldPath))
+				continue
+			}
+			for i, elem := range list {
+				lost = append(lost, protoValueLosses(messages, field.typ, elem, fmt.Sprintf("%s[%d]", fieldPath, i), true)...)
+			}
+		default:
+			lost = append(lost, protoValueLosses(messages, field.typ, value, fieldPath, field.optional)...)
+		}
+	}
+	return lost
+}
+
+// protoValueLosses is protoRoundTrip for a single value of type typ.
+// Without presence, a scalar's zero value is not written back.
+func protoValueLosses(messages map[string]map[string]protoField, typ string, v interface{}, path string, presence bool) []string {
+	var zero, fits bool
+	switch typ {
+	case "string":
+		s, ok := v.(string)
+		zero, fits = s == "", ok
+	case "bool":
+		b, ok := v.(bool)
+		zero, fits = !b, ok
+	case "double":
+		n, ok := v.(float64)
+		zero, fits = n == 0, ok
+	case "int32":
+		n, ok := v.(float64)
+		zero, fits = n == 0, ok && n == math.Trunc(n) && n >= math.MinInt32 && n <= math.MaxInt32
+	case "google.protobuf.Value":
+		return nil
+	case "google.protobuf.Struct":
+		if _, ok := v.(map[string]interface{}); !ok {
+			return []string{fmt.Sprintf("%s: not an object", path)}
+		}
+		return nil
+	case "google.protobuf.ListValue":
+		if _, ok := v.([]interface{}); !ok {
+			return []string{fmt.Sprintf("%s: not a list", path)}
+		}
+		return nil
+	default:
+		if v == nil {
+			return []string{fmt.Sprintf("%s: null", path)}
+		}
+		return protoRoundTrip(messages, typ, v, path)
+	}
+	if !fits {
+		data, _ := json.Marshal(v)
+		return []string{fmt.Sprintf("%s: %s does not fit %s", path, data, typ)}
+	}
+	if zero && !presence {
+		return []string{fmt.Sprintf("%s: the zero value of a field without presence", path)}
+	}
+	return nil
+}
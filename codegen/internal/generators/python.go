@@ -0,0 +1,55 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// PythonGenerator emits Pydantic v2 models and a pyproject.toml skeleton
+// under <output>/python, using Go templates only.
+type PythonGenerator struct{}
+
+func (g *PythonGenerator) Generate(s *parser.Schema, outputDir string) error {
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+
+	projectDir := filepath.Join(outputDir, templates.Project(s, "python"))
+	packageDir := filepath.Join(projectDir, templates.PythonPackage(s))
+	if err := os.MkdirAll(packageDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := templates.WritePythonModels(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write models: %w", err)
+	}
+
+	if err := templates.WritePythonVersion(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
+	if err := templates.WritePythonInit(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write __init__: %w", err)
+	}
+
+	if err := templates.WritePythonProject(projectDir, s); err != nil {
+		return fmt.Errorf("failed to write pyproject.toml: %w", err)
+	}
+
+	return nil
+}
+
+func (g *PythonGenerator) Language() string {
+	return "Python"
+}
+
+func (g *PythonGenerator) NeedsExternal() bool {
+	return false
+}
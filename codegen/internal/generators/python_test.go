@@ -0,0 +1,89 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func TestPythonGenerator(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	content := `{
+		"title": "Test Schema v0.1",
+		"$defs": {
+			"Filter": {
+				"type": "object",
+				"properties": {
+					"not": { "$ref": "#/$defs/Filter" },
+					"conditions": { "type": "array", "items": { "$ref": "#/$defs/Condition" } }
+				}
+			},
+			"Condition": {
+				"type": "object",
+				"required": ["field", "op"],
+				"properties": {
+					"field": { "type": "string", "minLength": 1 },
+					"op": { "enum": ["eq", "ne"] },
+					"field_path": { "type": "array", "items": { "type": "string" } },
+					"group": {
+						"type": "object",
+						"properties": { "keys": { "type": "array", "items": { "type": "string" } } }
+					}
+				}
+			}
+		}
+	}`
+	if err := os.WriteFile(schemaPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(schemaPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := (&PythonGenerator{}).Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	models, err := os.ReadFile(filepath.Join(out, "python", "includekit_spec", "models.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"class Filter(SpecModel):\n    not_: Optional[Filter] = Field(default=None, alias=\"not\")\n",
+		"    field: str = Field(alias=\"field\", min_length=1)\n",
+		"    op: Literal[\"eq\", \"ne\"] = Field(alias=\"op\")\n",
+		"    field_path: Optional[List[str]] = Field(default=None, alias=\"field_path\")\n",
+		"class ConditionGroup(SpecModel):\n",
+		"    group: Optional[ConditionGroup] = Field(default=None, alias=\"group\")\n",
+		"Filter.model_rebuild()\nConditionGroup.model_rebuild()\nCondition.model_rebuild()\n",
+	} {
+		if !strings.Contains(string(models), want) {
+			t.Errorf("models.py missing %q\n%s", want, models)
+		}
+	}
+
+	init, err := os.ReadFile(filepath.Join(out, "python", "includekit_spec", "__init__.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(init), "from .version import CONTRACT_VERSION, SCHEMA_ID, SPEC_VERSION\n") {
+		t.Errorf("__init__.py does not re-export the version:\n%s", init)
+	}
+	version, err := os.ReadFile(filepath.Join(out, "python", "includekit_spec", "version.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(version), `SPEC_VERSION = "0.1.0"`) {
+		t.Errorf("version.py missing SPEC_VERSION:\n%s", version)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "python", "pyproject.toml")); err != nil {
+		t.Errorf("pyproject.toml not written: %v", err)
+	}
+}
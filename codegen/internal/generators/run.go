@@ -0,0 +1,77 @@
+package generators
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// Result is the outcome of one language in a Run.
+type Result struct {
+	Lang     string // as requested, e.g. "ts"
+	Language string // the generator's name, or Lang when unknown
+	Duration time.Duration
+	Err      error
+}
+
+// Run generates every language, or verifies it when check is set, running
+// at most parallel generators at a time. A failing language does not stop
+// the others, so one broken external tool cannot hide how the rest fared.
+// Results are in the order of langs; a language requested twice, such as
+// "ts,typescript", runs once.
+func Run(s *parser.Schema, langs []string, outputDir string, parallel int, check bool) []Result {
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	var results []Result
+	var gens []Generator
+	seen := map[string]bool{}
+	for _, lang := range langs {
+		gen := Get(lang)
+		if gen == nil {
+			results = append(results, Result{Lang: lang, Language: lang, Err: fmt.Errorf("unknown language: %s", lang)})
+			gens = append(gens, nil)
+			continue
+		}
+		if seen[gen.Language()] {
+			continue
+		}
+		seen[gen.Language()] = true
+		results = append(results, Result{Lang: lang, Language: gen.Language()})
+		gens = append(gens, gen)
+	}
+
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, gen := range gens {
+		if gen == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(r *Result, gen Generator) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			start := time.Now()
+			r.Err = runOne(gen, s, outputDir, check)
+			r.Duration = time.Since(start)
+		}(&results[i], gen)
+	}
+	wg.Wait()
+	return results
+}
+
+func runOne(gen Generator, s *parser.Schema, outputDir string, check bool) error {
+	if !check {
+		return gen.Generate(s, outputDir)
+	}
+	checker, ok := gen.(Checker)
+	if !ok {
+		return fmt.Errorf("%s does not support -check", gen.Language())
+	}
+	return checker.Check(s, outputDir)
+}
@@ -0,0 +1,94 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	s := parseRealSchema(t)
+	out := t.TempDir()
+
+	langs := []string{"python", "kotlin", "nope", "swift", "rust", "py", "proto", "openapi", "java"}
+	results := Run(s, langs, out, 3, false)
+
+	var got []string
+	for _, r := range results {
+		status := "ok"
+		if r.Err != nil {
+			status = "failed"
+		}
+		got = append(got, r.Language+":"+status)
+	}
+	// In request order, once per language, with failures not stopping the rest
+	want := "Python:ok Kotlin:ok nope:failed Swift:ok Rust:ok Protobuf/gRPC:ok OpenAPI:ok Java:failed"
+	if strings.Join(got, " ") != want {
+		t.Errorf("Run() = %s, want %s", strings.Join(got, " "), want)
+	}
+	for _, r := range results {
+		if r.Err == nil && r.Duration <= 0 {
+			t.Errorf("%s has no duration", r.Language)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(out, "rust", "src", "lib.rs")); err != nil {
+		t.Errorf("rust not generated: %v", err)
+	}
+
+	// Check runs only generators that support it
+	Run(s, []string{"go"}, out, 1, false)
+	results = Run(s, []string{"go", "python"}, out, 2, true)
+	if results[0].Err != nil {
+		t.Errorf("Go check failed: %v", results[0].Err)
+	}
+	if results[1].Err == nil || !strings.Contains(results[1].Err.Error(), "does not support -check") {
+		t.Errorf("Python check error = %v, want unsupported", results[1].Err)
+	}
+}
+
+func TestRunSuffixed(t *testing.T) {
+	s := parseRealSchema(t)
+	s.Suffix = "v0_1_0"
+	out := t.TempDir()
+
+	langs := []string{"go", "python", "kotlin", "swift", "rust", "openapi"}
+	results := Run(s, langs, out, 2, false)
+	for _, r := range results[:5] {
+		if r.Err != nil {
+			t.Errorf("%s failed: %v", r.Language, r.Err)
+		}
+	}
+	if results[5].Err == nil || !strings.Contains(results[5].Err.Error(), "version-suffixed") {
+		t.Errorf("OpenAPI error = %v, want no version-suffixed packages", results[5].Err)
+	}
+
+	// Every package is named for the version, so it can sit beside the
+	// newest one's
+	for path, want := range map[string]string{
+		"go/typesv0_1_0/types.go":                                             "package typesv0_1_0",
+		"go/typesv0_1_0/version.go":                                           "package typesv0_1_0",
+		"python-v0-1-0/includekit_spec_v0_1_0/version.py":                     `SPEC_VERSION = "0.1.0"`,
+		"python-v0-1-0/pyproject.toml":                                        `name = "includekit-spec-v0-1-0"`,
+		"kotlin-v0-1-0/src/main/kotlin/dev/includekit/spec/v0_1_0/Version.kt": "package dev.includekit.spec.v0_1_0",
+		"swift-v0-1-0/Sources/IncludeKitSpecV0_1_0/Version.swift":             `public let specVersion = "0.1.0"`,
+		"swift-v0-1-0/Package.swift":                                          `"IncludeKitSpecV0_1_0"`,
+		"rust-v0-1-0/Cargo.toml":                                              `name = "includekit-spec-v0-1-0"`,
+	} {
+		got, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(path)))
+		if err != nil {
+			t.Error(err)
+			continue
+		}
+		if !strings.Contains(string(got), want) {
+			t.Errorf("%s does not contain %s", path, want)
+		}
+	}
+	// The testkit is generated for the newest version only
+	if _, err := os.Stat(filepath.Join(out, "go", "tests")); !os.IsNotExist(err) {
+		t.Errorf("go/tests generated for an older version: %v", err)
+	}
+	if err := (&GoGenerator{}).Check(s, out); err != nil {
+		t.Errorf("Check() error = %v", err)
+	}
+}
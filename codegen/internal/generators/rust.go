@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// RustGenerator emits serde structs and a Cargo manifest under
+// <output>/rust, using Go templates only.
+type RustGenerator struct{}
+
+func (g *RustGenerator) Generate(s *parser.Schema, outputDir string) error {
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+
+	crateDir := filepath.Join(outputDir, templates.Project(s, "rust"))
+	srcDir := filepath.Join(crateDir, "src")
+	if err := os.MkdirAll(srcDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := templates.WriteRustModels(srcDir, s); err != nil {
+		return fmt.Errorf("failed to write models: %w", err)
+	}
+
+	if err := templates.WriteRustVersion(srcDir, s); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
+	if err := templates.WriteRustCrate(crateDir, s); err != nil {
+		return fmt.Errorf("failed to write Cargo.toml: %w", err)
+	}
+
+	return nil
+}
+
+func (g *RustGenerator) Language() string {
+	return "Rust"
+}
+
+func (g *RustGenerator) NeedsExternal() bool {
+	return false
+}
@@ -0,0 +1,86 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func TestRustGenerator(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	content := `{
+		"title": "Test Schema v0.1",
+		"$defs": {
+			"Filter": {
+				"type": "object",
+				"properties": {
+					"not": { "$ref": "#/$defs/Filter" },
+					"conditions": { "type": "array", "items": { "$ref": "#/$defs/Condition" } }
+				}
+			},
+			"Condition": {
+				"type": "object",
+				"required": ["field", "op"],
+				"properties": {
+					"field": { "type": "string", "minLength": 1 },
+					"op": { "oneOf": [{ "enum": ["eq", "notIn"] }, { "type": "string", "pattern": "^custom:.+$" }] },
+					"field_path": { "type": "array", "items": { "type": "string" } },
+					"group": {
+						"type": "object",
+						"properties": { "keys": { "type": "array", "items": { "type": "string" } } }
+					}
+				}
+			}
+		}
+	}`
+	if err := os.WriteFile(schemaPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(schemaPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := (&RustGenerator{}).Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	lib, err := os.ReadFile(filepath.Join(out, "rust", "src", "lib.rs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"mod version;\n\npub use version::{CONTRACT_VERSION, SCHEMA_ID, SPEC_VERSION};\n",
+		// Filter.not is boxed, as a struct cannot contain itself
+		"pub struct Filter {\n    #[serde(skip_serializing_if = \"Option::is_none\")]\n    pub not: Option<Box<Filter>>,\n",
+		"    pub conditions: Option<Vec<Condition>>,\n",
+		"pub struct Condition {\n    pub field: String,\n    pub op: Operator,\n",
+		"pub enum Operator {\n    Eq,\n    NotIn,\n    /// custom:<name>, holding the name\n    Custom(String),\n}\n",
+		"            \"notIn\" => Operator::NotIn,\n",
+		"            _ => match s.strip_prefix(\"custom:\") {\n",
+		"    pub field_path: Option<Vec<String>>,\n",
+		"pub struct ConditionGroup {\n",
+		"    pub group: Option<ConditionGroup>,\n",
+	} {
+		if !strings.Contains(string(lib), want) {
+			t.Errorf("lib.rs missing %q\n%s", want, lib)
+		}
+	}
+
+	version, err := os.ReadFile(filepath.Join(out, "rust", "src", "version.rs"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(version), "pub const SPEC_VERSION: &str = \"0.1.0\";\n") {
+		t.Errorf("version.rs missing SPEC_VERSION:\n%s", version)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "rust", "Cargo.toml")); err != nil {
+		t.Errorf("Cargo.toml not written: %v", err)
+	}
+}
@@ -0,0 +1,51 @@
+package generators
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// SwiftGenerator emits Codable structs and a Swift package manifest under
+// <output>/swift, using Go templates only.
+type SwiftGenerator struct{}
+
+func (g *SwiftGenerator) Generate(s *parser.Schema, outputDir string) error {
+	// Validate and clean output directory
+	outputDir = filepath.Clean(outputDir)
+	if strings.Contains(outputDir, "..") {
+		return fmt.Errorf("invalid output directory (directory traversal detected): %s", outputDir)
+	}
+
+	packageDir := filepath.Join(outputDir, templates.Project(s, "swift"))
+	sourcesDir := filepath.Join(packageDir, "Sources", templates.SwiftModule(s))
+	if err := os.MkdirAll(sourcesDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	if err := templates.WriteSwiftModels(sourcesDir, s); err != nil {
+		return fmt.Errorf("failed to write models: %w", err)
+	}
+
+	if err := templates.WriteSwiftVersion(sourcesDir, s); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
+	if err := templates.WriteSwiftPackage(packageDir, s); err != nil {
+		return fmt.Errorf("failed to write Package.swift: %w", err)
+	}
+
+	return nil
+}
+
+func (g *SwiftGenerator) Language() string {
+	return "Swift"
+}
+
+func (g *SwiftGenerator) NeedsExternal() bool {
+	return false
+}
@@ -0,0 +1,90 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func TestSwiftGenerator(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.json")
+	content := `{
+		"title": "Test Schema v0.1",
+		"$defs": {
+			"Filter": {
+				"type": "object",
+				"properties": {
+					"not": { "$ref": "#/$defs/Filter" },
+					"conditions": { "type": "array", "items": { "$ref": "#/$defs/Condition" } }
+				}
+			},
+			"Condition": {
+				"type": "object",
+				"required": ["field", "op"],
+				"properties": {
+					"field": { "type": "string", "minLength": 1 },
+					"op": { "enum": ["eq", "ne"] },
+					"field_path": { "type": "array", "items": { "type": "string" } },
+					"group": {
+						"type": "object",
+						"properties": { "keys": { "type": "array", "items": { "type": "string" } } }
+					}
+				}
+			}
+		}
+	}`
+	if err := os.WriteFile(schemaPath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(schemaPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	out := filepath.Join(dir, "out")
+	if err := (&SwiftGenerator{}).Generate(s, out); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	models, err := os.ReadFile(filepath.Join(out, "swift", "Sources", "IncludeKitSpec", "Models.swift"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		// Filter.not is boxed, as a struct cannot contain itself
+		"    public var not: Filter? {\n        get { _not?.value }\n",
+		"    private var _not: Indirect<Filter>?\n",
+		"        self._not = not.map(Indirect.init)\n",
+		"        case _not = \"not\"\n",
+		"final class Indirect<",
+		"public enum ConditionOp: String, Codable, CaseIterable, Sendable {\n    case eq\n    case ne\n}\n",
+		"    public var field: String\n",
+		"    public var fieldPath: [String]?\n",
+		"        case fieldPath = \"field_path\"\n",
+		"    public init(field: String, op: ConditionOp, fieldPath: [String]? = nil, group: ConditionGroup? = nil) {\n",
+		"public struct ConditionGroup: Codable, Equatable, Sendable {\n",
+	} {
+		if !strings.Contains(string(models), want) {
+			t.Errorf("Models.swift missing %q\n%s", want, models)
+		}
+	}
+	version, err := os.ReadFile(filepath.Join(out, "swift", "Sources", "IncludeKitSpec", "Version.swift"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(version), "public let specVersion = \"0.1.0\"\n") {
+		t.Errorf("Version.swift missing specVersion:\n%s", version)
+	}
+	// Only properties that lead back to their struct are boxed
+	if strings.Contains(string(models), "Indirect<Condition>") {
+		t.Errorf("Models.swift boxes Filter.conditions:\n%s", models)
+	}
+
+	if _, err := os.Stat(filepath.Join(out, "swift", "Package.swift")); err != nil {
+		t.Errorf("Package.swift not written: %v", err)
+	}
+}
@@ -19,6 +19,10 @@ import (
 type TypeScriptGenerator struct{}
 
 func (g *TypeScriptGenerator) Generate(s *parser.Schema, outputDir string) error {
+	if err := checkUnsuffixed(s, g.Language()); err != nil {
+		return err
+	}
+
 	// Generate production types using json-schema-to-typescript
 	if err := g.generateTypes(s, outputDir); err != nil {
 		return fmt.Errorf("failed to generate types: %w", err)
@@ -84,7 +88,27 @@ func (g *TypeScriptGenerator) generateTypes(s *parser.Schema, outputDir string)
 		return err
 	}
 
-	return nil
+	return appendAliases(outputFile)
+}
+
+// typeAliases keeps the names the 0.1.0 documentation used for the
+// top-level documents compiling; the Go types declare the same aliases.
+const typeAliases = `/** @deprecated Use Statement */
+export type QueryShape = Statement;
+/** @deprecated Use Mutation */
+export type MutationEvent = Mutation;
+`
+
+func appendAliases(filepath string) error {
+	f, err := os.OpenFile(filepath, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := f.WriteString(typeAliases); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
 }
 
 func (g *TypeScriptGenerator) generateTestkit(s *parser.Schema, outputDir string) error {
@@ -99,6 +123,16 @@ func (g *TypeScriptGenerator) generateTestkit(s *parser.Schema, outputDir string
 		return fmt.Errorf("failed to write validators: %w", err)
 	}
 
+	// Generate constraints.ts, which validators.ts imports
+	if err := templates.WriteTypeScriptConstraints(testkitDir, s); err != nil {
+		return fmt.Errorf("failed to write constraints: %w", err)
+	}
+
+	// Generate version.ts
+	if err := templates.WriteTypeScriptVersion(testkitDir, s); err != nil {
+		return fmt.Errorf("failed to write version: %w", err)
+	}
+
 	// Generate canonicalize.ts
 	if err := templates.WriteTypeScriptCanonicalize(testkitDir); err != nil {
 		return fmt.Errorf("failed to write canonicalize: %w", err)
@@ -109,6 +143,11 @@ func (g *TypeScriptGenerator) generateTestkit(s *parser.Schema, outputDir string
 		return fmt.Errorf("failed to write shapeId: %w", err)
 	}
 
+	// Generate schemas.ts
+	if err := templates.WriteTypeScriptZod(testkitDir, s); err != nil {
+		return fmt.Errorf("failed to write schemas: %w", err)
+	}
+
 	// Generate index.ts
 	if err := templates.WriteTypeScriptIndex(testkitDir); err != nil {
 		return fmt.Errorf("failed to write index: %w", err)
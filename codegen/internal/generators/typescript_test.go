@@ -0,0 +1,119 @@
+package generators
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// TestTypeScriptTestkitUpToDate fails when the generated testkit sources in
+// pkgs/ts/tests/src have drifted from the templates, which would be
+// overwritten by the next codegen run.
+func TestTypeScriptTestkitUpToDate(t *testing.T) {
+	out := t.TempDir()
+	if err := (&TypeScriptGenerator{}).generateTestkit(parseRealSchema(t), out); err != nil {
+		t.Fatalf("generateTestkit() error = %v", err)
+	}
+
+	dir := filepath.Join(out, "ts", "tests", "src")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		want, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(filepath.Join("../../../pkgs/ts/tests/src", entry.Name()))
+		if err != nil {
+			t.Errorf("%s is not checked in: %v", entry.Name(), err)
+			continue
+		}
+		if string(got) != string(want) {
+			t.Errorf("pkgs/ts/tests/src/%s has drifted from its template; regenerate with codegen -lang ts", entry.Name())
+		}
+	}
+}
+
+// TestTypeScriptConstraints checks the tables validators.ts reads from
+// constraints.ts, which replace the lists it used to keep by hand.
+func TestTypeScriptConstraints(t *testing.T) {
+	out := t.TempDir()
+	if err := (&TypeScriptGenerator{}).generateTestkit(parseRealSchema(t), out); err != nil {
+		t.Fatalf("generateTestkit() error = %v", err)
+	}
+	src := filepath.Join(out, "ts", "tests", "src")
+	data, err := os.ReadFile(filepath.Join(src, "constraints.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	constraints := string(data)
+
+	for _, want := range []string{
+		"  'Condition.op': ['eq', 'ne', 'in', 'notIn', 'isNull', ",
+		"  'Join.kind': ['inner', 'left'],\n",
+		"  'Condition.op': /^custom:.+$/,\n",
+		"  'Dependencies.spec_version': /^\\d+\\.\\d+\\.\\d+$/,\n",
+		// deprecated properties are not allowed in strict mode
+		"  'Condition': ['field', 'field_path', 'op', 'value', 'subquery'],\n",
+		"  'Dependencies.group_by': ['keys', 'values'],\n",
+	} {
+		if !strings.Contains(constraints, want) {
+			t.Errorf("constraints.ts missing %q", want)
+		}
+	}
+
+	// Every table validators.ts reads is generated
+	validators, err := os.ReadFile(filepath.Join(src, "validators.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	uses := regexp.MustCompile(`(?:specAllows\(|SPEC_ENUMS\[|assertSpecKeys\([^,]+, )'([\w.]+)'`).FindAllStringSubmatch(string(validators), -1)
+	if len(uses) == 0 {
+		t.Fatal("validators.ts reads no constraints")
+	}
+	for _, use := range uses {
+		if !strings.Contains(constraints, "  '"+use[1]+"': ") {
+			t.Errorf("validators.ts reads %s, which constraints.ts does not define", use[1])
+		}
+	}
+}
+
+func TestTypeScriptZodSchemas(t *testing.T) {
+	out := t.TempDir()
+	if err := (&TypeScriptGenerator{}).generateTestkit(parseRealSchema(t), out); err != nil {
+		t.Fatalf("generateTestkit() error = %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(out, "ts", "tests", "src", "schemas.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	schemas := string(data)
+
+	for _, want := range []string{
+		"import { z } from 'zod';\n",
+		"export const FilterSchema: z.ZodType<Filter> = z.lazy(() =>\n  z.object({\n    and: z.array(FilterSchema).optional(),\n",
+		"    field: z.string().min(1),\n",
+		"z.string().regex(/^custom:.+$/)])",
+		"    subquery: QuerySchema.optional(),\n  }).strict()\n);\n",
+		"    first: z.number().int().min(1).optional(),\n",
+		"    value: jsonValue,\n",
+		"    shape_id: z.string().regex(/^s_[0-9a-f]{64}$/),\n",
+		"    records: z.record(z.string(), z.array(z.string())),\n",
+		"    group_by: z.object({\n      keys: z.array(z.string()),\n",
+		"    }).strict().optional(),\n",
+		"    valid_until: z.string().datetime({ offset: true }).optional(),\n",
+	} {
+		if !strings.Contains(schemas, want) {
+			t.Errorf("schemas.ts missing %q", want)
+		}
+	}
+	for name := range parseRealSchema(t).Definitions {
+		if !strings.Contains(schemas, "export const "+name+"Schema: z.ZodType<"+name+">") {
+			t.Errorf("schemas.ts has no schema for %s", name)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package parser
+
+import "fmt"
+
+// Constraint is what the schema requires of one property of a definition.
+// Object properties with properties of their own are constrained as
+// definitions too, named after their path, e.g. "Dependencies.group_by".
+type Constraint struct {
+	Definition string
+	Property   string
+	Required   bool
+	Deprecated bool
+	// Diagnostic marks properties annotated "x-diagnostic": they describe
+	// the emitter rather than the read, so canonicalization leaves them out
+	// and they never change a shape ID.
+	Diagnostic bool
+	// Enum lists the allowed values. When Pattern is also set, as for a
+	// oneOf of an enum and a pattern, a value may match either.
+	Enum      []string
+	Pattern   string
+	Format    string
+	MinLength int // 0 when unconstrained
+	Minimum   *float64
+	Maximum   *float64
+}
+
+// Key names the property as "<Definition>.<Property>".
+func (c Constraint) Key() string {
+	return c.Definition + "." + c.Property
+}
+
+// Diagnostics returns the diagnostic properties of each definition that has
+// any, in schema order: the fields canonicalization excludes.
+func Diagnostics(constraints []Constraint) map[string][]string {
+	out := map[string][]string{}
+	for _, c := range constraints {
+		if c.Diagnostic {
+			out[c.Definition] = append(out[c.Definition], c.Property)
+		}
+	}
+	return out
+}
+
+// Constraints returns the constraints of every property of every
+// definition, definitions and properties in schema order. Array items are
+// constrained like the array property, which suits the string arrays the
+// schema uses.
+func (s *Schema) Constraints() ([]Constraint, error) {
+	var out []Constraint
+	for _, name := range s.Keys("/$defs", s.Definitions) {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("definition %s is not an object", name)
+		}
+		var err error
+		if out, err = s.appendConstraints(out, name, "/$defs/"+name, def); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+func (s *Schema) appendConstraints(out []Constraint, name, pointer string, def map[string]interface{}) ([]Constraint, error) {
+	props, _ := def["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := def["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var nested []string
+	propsPointer := pointer + "/properties"
+	for _, prop := range s.Keys(propsPointer, props) {
+		schema, ok := props[prop].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s.%s is not an object", name, prop)
+		}
+		c := Constraint{Definition: name, Property: prop, Required: required[prop]}
+		c.Deprecated, _ = schema["deprecated"].(bool)
+		c.Diagnostic, _ = schema["x-diagnostic"].(bool)
+		if items, ok := schema["items"].(map[string]interface{}); ok && schema["type"] == "array" {
+			schema = items
+		}
+		if err := readConstraint(&c, schema); err != nil {
+			return nil, fmt.Errorf("%s.%s: %w", name, prop, err)
+		}
+		out = append(out, c)
+		if _, ok := schema["properties"]; ok && schema["type"] == "object" {
+			nested = append(nested, prop)
+		}
+	}
+
+	for _, prop := range nested {
+		var err error
+		if out, err = s.appendConstraints(out, name+"."+prop, propsPointer+"/"+prop, props[prop].(map[string]interface{})); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// readConstraint reads the keywords of one schema into c, following the
+// variants of a oneOf or anyOf.
+func readConstraint(c *Constraint, schema map[string]interface{}) error {
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		for _, v := range enum {
+			str, ok := v.(string)
+			if !ok {
+				return fmt.Errorf("enum value %v is not a string", v)
+			}
+			c.Enum = append(c.Enum, str)
+		}
+	}
+	if p, ok := schema["pattern"].(string); ok {
+		if c.Pattern != "" {
+			return fmt.Errorf("more than one pattern")
+		}
+		c.Pattern = p
+	}
+	if f, ok := schema["format"].(string); ok {
+		c.Format = f
+	}
+	if n, ok := schema["minLength"].(float64); ok {
+		c.MinLength = int(n)
+	}
+	if n, ok := schema["minimum"].(float64); ok {
+		c.Minimum = &n
+	}
+	if n, ok := schema["maximum"].(float64); ok {
+		c.Maximum = &n
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		variants, _ := schema[key].([]interface{})
+		for _, v := range variants {
+			variant, ok := v.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s variant is not an object", key)
+			}
+			if err := readConstraint(c, variant); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
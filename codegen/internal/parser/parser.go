@@ -6,11 +6,13 @@
 package parser
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
 )
 
@@ -25,6 +27,14 @@ type Schema struct {
 	Definitions map[string]interface{} `json:"$defs"`
 	Properties  map[string]interface{} `json:"properties"`
 	Raw         map[string]interface{} // Full raw schema
+	// KeyOrder lists the keys of every object in document order, by JSON
+	// pointer (e.g., "/$defs" or "/$defs/Query/properties"). Go maps lose
+	// the order, which generators need to emit definitions as written.
+	KeyOrder map[string][]string
+	// Suffix is appended to the names of the generated packages when this
+	// version is generated beside a newer one (e.g., "v0_1_0"); it is
+	// empty for the newest version. Set by Select.
+	Suffix string
 }
 
 // Parse reads and parses a JSON Schema file from the given path.
@@ -60,6 +70,11 @@ func Parse(path string) (*Schema, error) {
 		return nil, err
 	}
 
+	s.KeyOrder = map[string][]string{}
+	if err := readKeyOrder(json.NewDecoder(bytes.NewReader(data)), "", s.KeyOrder); err != nil {
+		return nil, err
+	}
+
 	s.Path = cleanPath
 	s.Version = extractVersion(s.Title, cleanPath)
 
@@ -89,3 +104,69 @@ func extractVersion(title, path string) string {
 
 	return "unknown"
 }
+
+// Keys returns the keys of the object at pointer in document order. Keys of
+// m missing from the recorded order (or all of them, for an unknown pointer)
+// follow in sorted order.
+func (s *Schema) Keys(pointer string, m map[string]interface{}) []string {
+	seen := map[string]bool{}
+	keys := []string{}
+	for _, k := range s.KeyOrder[pointer] {
+		if _, ok := m[k]; ok {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+
+	var rest []string
+	for k := range m {
+		if !seen[k] {
+			rest = append(rest, k)
+		}
+	}
+	sort.Strings(rest)
+	return append(keys, rest...)
+}
+
+// readKeyOrder consumes one JSON value from dec, recording the key order of
+// every object within it under its JSON pointer.
+func readKeyOrder(dec *json.Decoder, pointer string, order map[string][]string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		keys := []string{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			key := keyTok.(string)
+			keys = append(keys, key)
+			if err := readKeyOrder(dec, pointer+"/"+escapePointer(key), order); err != nil {
+				return err
+			}
+		}
+		order[pointer] = keys
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := readKeyOrder(dec, fmt.Sprintf("%s/%d", pointer, i), order); err != nil {
+				return err
+			}
+		}
+	default:
+		return nil
+	}
+
+	// Consume the closing delimiter
+	_, err = dec.Token()
+	return err
+}
+
+// escapePointer escapes a key for use as a JSON pointer segment (RFC 6901).
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
@@ -1,7 +1,10 @@
 package parser
 
 import (
+	"encoding/json"
+	"fmt"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -120,3 +123,133 @@ func TestExtractVersion(t *testing.T) {
 		})
 	}
 }
+
+func TestKeyOrder(t *testing.T) {
+	tmpfile, _ := os.CreateTemp("", "schema-*.json")
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Write([]byte(`{
+		"title": "Test Schema v1.0.0",
+		"$defs": {
+			"Zeta": {"properties": {"b": {}, "a": {}, "c/d": {}}},
+			"Alpha": {"properties": {"items": [{"y": 1, "x": 2}]}}
+		}
+	}`))
+	tmpfile.Close()
+
+	schema, err := Parse(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := schema.Keys("/$defs", schema.Definitions); strings.Join(got, ",") != "Zeta,Alpha" {
+		t.Errorf("Keys(/$defs) = %v, want [Zeta Alpha]", got)
+	}
+	props := schema.Definitions["Zeta"].(map[string]interface{})["properties"].(map[string]interface{})
+	if got := schema.Keys("/$defs/Zeta/properties", props); strings.Join(got, ",") != "b,a,c/d" {
+		t.Errorf("Keys(properties) = %v, want [b a c/d]", got)
+	}
+	if got := schema.KeyOrder["/$defs/Alpha/properties/items/0"]; strings.Join(got, ",") != "y,x" {
+		t.Errorf("KeyOrder in array = %v, want [y x]", got)
+	}
+	if _, ok := schema.KeyOrder["/$defs/Zeta/properties/c~1d"]; !ok {
+		t.Error("KeyOrder should escape / in pointer segments")
+	}
+
+	// Unknown pointers fall back to sorted keys
+	got := schema.Keys("/nowhere", map[string]interface{}{"b": 1, "a": 2})
+	if strings.Join(got, ",") != "a,b" {
+		t.Errorf("Keys(unknown) = %v, want [a b]", got)
+	}
+}
+
+func TestConstraints(t *testing.T) {
+	tmpfile, _ := os.CreateTemp("", "schema-*.json")
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Write([]byte(`{
+		"title": "Test Schema v1.0.0",
+		"$defs": {
+			"Op": {
+				"required": ["op"],
+				"properties": {
+					"op": {"oneOf": [{"enum": ["eq", "ne"]}, {"type": "string", "pattern": "^custom:.+$"}]},
+					"old": {"type": "string", "deprecated": true},
+					"tags": {"type": "array", "items": {"type": "string", "minLength": 1}},
+					"group": {"type": "object", "properties": {"keys": {"type": "array", "items": {"type": "string"}}}}
+				}
+			},
+			"Page": {"properties": {"limit": {"type": "integer", "minimum": 0}, "trace": {"type": "string", "x-diagnostic": true}}}
+		}
+	}`))
+	tmpfile.Close()
+
+	schema, err := Parse(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	constraints, err := schema.Constraints()
+	if err != nil {
+		t.Fatalf("Constraints() error = %v", err)
+	}
+
+	var keys []string
+	for _, c := range constraints {
+		keys = append(keys, c.Key())
+	}
+	if got := strings.Join(keys, ","); got != "Op.op,Op.old,Op.tags,Op.group,Op.group.keys,Page.limit,Page.trace" {
+		t.Fatalf("Constraints() keys = %s", got)
+	}
+
+	op := constraints[0]
+	if !op.Required || strings.Join(op.Enum, ",") != "eq,ne" || op.Pattern != "^custom:.+$" {
+		t.Errorf("Op.op = %+v, want required enum eq,ne and the custom pattern", op)
+	}
+	if !constraints[1].Deprecated || constraints[1].Required {
+		t.Errorf("Op.old = %+v, want deprecated and optional", constraints[1])
+	}
+	if constraints[2].MinLength != 1 {
+		t.Errorf("Op.tags MinLength = %d, want the items' 1", constraints[2].MinLength)
+	}
+	if m := constraints[5].Minimum; m == nil || *m != 0 {
+		t.Errorf("Page.limit Minimum = %v, want 0", m)
+	}
+	if constraints[5].Diagnostic || !constraints[6].Diagnostic {
+		t.Errorf("Page.trace Diagnostic = %v, want only it diagnostic", constraints[6].Diagnostic)
+	}
+	if got := Diagnostics(constraints); len(got) != 1 || strings.Join(got["Page"], ",") != "trace" {
+		t.Errorf("Diagnostics() = %v, want Page: trace", got)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	tmpfile, _ := os.CreateTemp("", "schema-*.json")
+	defer os.Remove(tmpfile.Name())
+	tmpfile.Write([]byte(`{
+		"title": "Test Schema v1.0.0",
+		"$defs": {
+			"Filter": {
+				"properties": {
+					"not": {"$ref": "#/$defs/Filter"},
+					"conditions": {"type": "array", "items": {"$ref": "#/$defs/Condition"}},
+					"group": {"type": "object", "properties": {"keys": {"type": "array", "items": {"type": "string"}}}}
+				}
+			},
+			"Condition": {"properties": {"op": {"type": "string"}}}
+		}
+	}`))
+	tmpfile.Close()
+
+	schema, err := Parse(tmpfile.Name())
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	var doc interface{}
+	json.Unmarshal([]byte(`{"not": {"conditions": [{"op": "eq"}, "bad"]}, "group": {"keys": ["a"]}, "extra": {"op": "ne"}}`), &doc)
+
+	var visited []string
+	schema.Walk("Filter", doc, func(def string, obj map[string]interface{}) {
+		visited = append(visited, fmt.Sprintf("%s%d", def, len(obj)))
+	})
+	if got := strings.Join(visited, ","); got != "Filter3,Filter.group1,Filter1,Condition1" {
+		t.Errorf("Walk() visited %s", got)
+	}
+}
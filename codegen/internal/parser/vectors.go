@@ -0,0 +1,90 @@
+package parser
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// VectorFiles are the conformance vector files in tools/tests/vectors that
+// hold schema documents: each vector in File holds a document of
+// Definition under Key.
+var VectorFiles = []struct{ File, Key, Definition string }{
+	{"query-shapes.json", "shape", "Statement"},
+	{"mutations.json", "mutation", "Mutation"},
+	{"dependencies.json", "dependencies", "Dependencies"},
+	{"pagination-cursors.json", "dependencies", "Dependencies"},
+}
+
+// ReadVectors calls fn with every document in the vector files in dir, with
+// the file and vector it came from. Missing files are skipped.
+func ReadVectors(dir string, fn func(file, vector, def string, doc interface{})) error {
+	for _, v := range VectorFiles {
+		data, err := os.ReadFile(filepath.Join(dir, v.File))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		var vectors []map[string]interface{}
+		if err := json.Unmarshal(data, &vectors); err != nil {
+			return fmt.Errorf("%s: %w", v.File, err)
+		}
+		for _, vector := range vectors {
+			name, _ := vector["name"].(string)
+			fn(v.File, name, v.Definition, vector[v.Key])
+		}
+	}
+	return nil
+}
+
+// Walk calls fn with doc, a document of the definition def, and with every
+// object within it that is a value of a definition, following $refs,
+// arrays and nested object properties. Nested object properties are named
+// after their path, as in Constraints. Values that do not match the schema
+// are skipped.
+func (s *Schema) Walk(def string, doc interface{}, fn func(def string, obj map[string]interface{})) {
+	schema, _ := s.Definitions[def].(map[string]interface{})
+	s.walkObject(def, schema, doc, fn)
+}
+
+func (s *Schema) walkObject(def string, schema map[string]interface{}, doc interface{}, fn func(string, map[string]interface{})) {
+	obj, ok := doc.(map[string]interface{})
+	if !ok || schema == nil {
+		return
+	}
+	fn(def, obj)
+	props, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(obj))
+	for name := range obj {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		prop, _ := props[name].(map[string]interface{})
+		s.walkProperty(def+"."+name, prop, obj[name], fn)
+	}
+}
+
+func (s *Schema) walkProperty(path string, prop map[string]interface{}, value interface{}, fn func(string, map[string]interface{})) {
+	if ref, ok := prop["$ref"].(string); ok {
+		s.Walk(strings.TrimPrefix(ref, "#/$defs/"), value, fn)
+		return
+	}
+	switch prop["type"] {
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		list, _ := value.([]interface{})
+		for _, item := range list {
+			s.walkProperty(path, items, item, fn)
+		}
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			s.walkObject(path, prop, value, fn)
+		}
+	}
+}
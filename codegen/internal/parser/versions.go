@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// schemaFileVersion matches the version in a schema file name such as
+// v0-1-0.json.
+var schemaFileVersion = regexp.MustCompile(`^v(\d+)-(\d+)-(\d+)\.json$`)
+
+// SpecVersion returns the X.Y.Z version of the schema: the one in its file
+// name, such as v0-1-0.json, which tools/version keeps in step with VERSION,
+// or else the title's, which carries major.minor only, padded with zeros.
+func (s *Schema) SpecVersion() string {
+	if m := schemaFileVersion.FindStringSubmatch(filepath.Base(s.Path)); m != nil {
+		return m[1] + "." + m[2] + "." + m[3]
+	}
+	if strings.Count(s.Version, ".") == 1 {
+		return s.Version + ".0"
+	}
+	return s.Version
+}
+
+// ParseDir parses every versioned schema file (vX-Y-Z.json) in dir, so
+// several spec versions can coexist, and returns them oldest first.
+func ParseDir(dir string) ([]*Schema, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema directory: %w", err)
+	}
+	var schemas []*Schema
+	for _, e := range entries {
+		if e.IsDir() || !schemaFileVersion.MatchString(e.Name()) {
+			continue
+		}
+		s, err := Parse(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		schemas = append(schemas, s)
+	}
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no versioned schema (vX-Y-Z.json) in %s", dir)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return versionLess(schemas[i].SpecVersion(), schemas[j].SpecVersion())
+	})
+	return schemas, nil
+}
+
+// Select returns the schema of version among schemas parsed by ParseDir, or
+// the newest when version is empty. A leading "v" is ignored. An older version gets a Suffix, such
+// as "v0_1_0", so its packages can be generated beside the newest one's.
+func Select(schemas []*Schema, version string) (*Schema, error) {
+	if len(schemas) == 0 {
+		return nil, fmt.Errorf("no schemas")
+	}
+	version = strings.TrimPrefix(version, "v")
+	newest := schemas[len(schemas)-1]
+	if version == "" || version == newest.SpecVersion() {
+		return newest, nil
+	}
+
+	var found []string
+	for _, s := range schemas {
+		if s.SpecVersion() == version {
+			s.Suffix = "v" + strings.ReplaceAll(version, ".", "_")
+			return s, nil
+		}
+		found = append(found, s.SpecVersion())
+	}
+	return nil, fmt.Errorf("no schema for spec version %s (found %s)", version, strings.Join(found, ", "))
+}
+
+// versionLess compares X.Y.Z versions numerically; versions that are not
+// X.Y.Z compare as strings.
+func versionLess(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	if len(pa) != 3 || len(pb) != 3 {
+		return a < b
+	}
+	for i := range pa {
+		x, errX := strconv.Atoi(pa[i])
+		y, errY := strconv.Atoi(pb[i])
+		if errX != nil || errY != nil {
+			return a < b
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
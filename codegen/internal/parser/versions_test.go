@@ -0,0 +1,51 @@
+package parser
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, title := range map[string]string{
+		"v0-10-0.json": "Test Schema v0.10",
+		"v0-2-1.json":  "Test Schema v0.2",
+		"v0-9-0.json":  "Test Schema v0.9",
+		"draft.json":   "Draft",
+	} {
+		content := `{"title": "` + title + `", "$defs": {}}`
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schemas, err := ParseDir(dir)
+	if err != nil {
+		t.Fatalf("ParseDir() error = %v", err)
+	}
+	var got []string
+	for _, s := range schemas {
+		got = append(got, s.SpecVersion())
+	}
+	if strings.Join(got, " ") != "0.2.1 0.9.0 0.10.0" {
+		t.Errorf("ParseDir() versions = %v, want oldest first, skipping draft.json", got)
+	}
+
+	newest, err := Select(schemas, "")
+	if err != nil || newest.SpecVersion() != "0.10.0" || newest.Suffix != "" {
+		t.Errorf("Select(\"\") = %v, %v, want 0.10.0 unsuffixed", newest, err)
+	}
+	older, err := Select(schemas, "v0.2.1")
+	if err != nil || older.SpecVersion() != "0.2.1" || older.Suffix != "v0_2_1" {
+		t.Errorf("Select(v0.2.1) = %v, %v, want 0.2.1 with suffix v0_2_1", older, err)
+	}
+	if _, err := Select(schemas, "0.3.0"); err == nil || !strings.Contains(err.Error(), "0.2.1, 0.9.0, 0.10.0") {
+		t.Errorf("Select(0.3.0) error = %v, want the versions found", err)
+	}
+
+	if _, err := ParseDir(t.TempDir()); err == nil {
+		t.Error("ParseDir() of a directory without versioned schemas succeeded")
+	}
+}
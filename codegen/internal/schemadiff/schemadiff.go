@@ -0,0 +1,328 @@
+// Package schemadiff compares two versions of the IncludeKit JSON Schema and
+// classifies each difference as compatible or breaking.
+//
+// A change is breaking when a document valid under the old schema may be
+// rejected by the new one, or may mean something different on the wire.
+// Shape IDs hash the canonical wire format, so a breaking change also
+// invalidates every stored shape ID.
+package schemadiff
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// Change kinds reported by Compare
+const (
+	AddedDefinition     = "added definition"
+	RemovedDefinition   = "removed definition"
+	AddedOptionalField  = "added optional field"
+	AddedRequiredField  = "added required field"
+	RemovedField        = "removed field"
+	NowRequired         = "field now required"
+	NowOptional         = "field now optional"
+	ChangedType         = "changed type"
+	AddedEnumValue      = "added enum value"
+	RemovedEnumValue    = "removed enum value"
+	TightenedConstraint = "tightened constraint"
+	LoosenedConstraint  = "loosened constraint"
+	Deprecated          = "deprecated field"
+)
+
+// Change is one classified difference between two schemas.
+type Change struct {
+	Kind     string
+	Path     string // dotted location, e.g. "Query.order_by[]" or "Condition.op|0"
+	Detail   string
+	Breaking bool
+}
+
+func (c Change) String() string {
+	level := "compatible"
+	if c.Breaking {
+		level = "BREAKING"
+	}
+	s := fmt.Sprintf("%-10s %-20s %s", level, c.Kind, c.Path)
+	if c.Detail != "" {
+		s += " (" + c.Detail + ")"
+	}
+	return s
+}
+
+// HasBreaking reports whether any change is breaking.
+func HasBreaking(changes []Change) bool {
+	for _, c := range changes {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// Compare returns the differences from old to new, sorted by path.
+// Descriptions and other annotations are ignored.
+func Compare(old, new *parser.Schema) []Change {
+	d := &differ{}
+	d.node("schema", old.Raw, new.Raw)
+
+	for name, oldDef := range old.Definitions {
+		newDef, ok := new.Definitions[name]
+		if !ok {
+			d.add(RemovedDefinition, name, "", true)
+			continue
+		}
+		d.node(name, asObject(oldDef), asObject(newDef))
+	}
+	for name := range new.Definitions {
+		if _, ok := old.Definitions[name]; !ok {
+			d.add(AddedDefinition, name, "", false)
+		}
+	}
+
+	sort.SliceStable(d.changes, func(i, j int) bool {
+		return d.changes[i].Path < d.changes[j].Path
+	})
+	return d.changes
+}
+
+type differ struct {
+	changes []Change
+}
+
+func (d *differ) add(kind, path, detail string, breaking bool) {
+	d.changes = append(d.changes, Change{Kind: kind, Path: path, Detail: detail, Breaking: breaking})
+}
+
+// node compares two schema nodes at path.
+func (d *differ) node(path string, old, new map[string]interface{}) {
+	if oldRef, newRef := old["$ref"], new["$ref"]; oldRef != newRef {
+		d.add(ChangedType, path, fmt.Sprintf("%s -> %s", describe(old), describe(new)), true)
+		return
+	}
+	if oldType, newType := old["type"], new["type"]; !reflect.DeepEqual(oldType, newType) {
+		d.add(ChangedType, path, fmt.Sprintf("%s -> %s", describe(old), describe(new)), true)
+		return
+	}
+
+	d.enum(path, old["enum"], new["enum"])
+	d.constraints(path, old, new)
+	d.properties(path, old, new)
+
+	if oldItems, newItems := asObject(old["items"]), asObject(new["items"]); oldItems != nil || newItems != nil {
+		d.node(path+"[]", oldItems, newItems)
+	}
+	d.additional(path, old["additionalProperties"], new["additionalProperties"])
+
+	for _, key := range []string{"oneOf", "anyOf", "allOf"} {
+		d.variants(path, key, old[key], new[key])
+	}
+
+	if oldDep, _ := old["deprecated"].(bool); !oldDep {
+		if newDep, _ := new["deprecated"].(bool); newDep {
+			d.add(Deprecated, path, "", false)
+		}
+	}
+}
+
+// variants compares oneOf/anyOf/allOf branches by position. Removing a
+// oneOf or anyOf branch rejects the documents it matched, while adding an
+// allOf branch adds a requirement; the reverse changes only loosen.
+func (d *differ) variants(path, key string, old, new interface{}) {
+	oldVariants, _ := old.([]interface{})
+	newVariants, _ := new.([]interface{})
+	switch {
+	case len(oldVariants) == 0 && len(newVariants) == 0:
+		return
+	case len(oldVariants) == 0:
+		d.add(TightenedConstraint, path, key+" added", true)
+		return
+	case len(newVariants) == 0:
+		d.add(LoosenedConstraint, path, key+" removed", false)
+		return
+	}
+
+	for i := 0; i < len(oldVariants) || i < len(newVariants); i++ {
+		variant := fmt.Sprintf("%s|%d", path, i)
+		switch {
+		case i >= len(newVariants):
+			breaking := key != "allOf"
+			d.add(kindFor(breaking), variant, key+" branch removed", breaking)
+		case i >= len(oldVariants):
+			breaking := key == "allOf"
+			d.add(kindFor(breaking), variant, key+" branch added", breaking)
+		default:
+			d.node(variant, asObject(oldVariants[i]), asObject(newVariants[i]))
+		}
+	}
+}
+
+func kindFor(breaking bool) string {
+	if breaking {
+		return TightenedConstraint
+	}
+	return LoosenedConstraint
+}
+
+func (d *differ) enum(path string, old, new interface{}) {
+	oldValues, oldOK := old.([]interface{})
+	newValues, newOK := new.([]interface{})
+	switch {
+	case !oldOK && !newOK:
+		return
+	case !oldOK:
+		d.add(TightenedConstraint, path, "enum added", true)
+		return
+	case !newOK:
+		d.add(LoosenedConstraint, path, "enum removed", false)
+		return
+	}
+
+	for _, v := range oldValues {
+		if !contains(newValues, v) {
+			d.add(RemovedEnumValue, path, fmt.Sprintf("%q", fmt.Sprint(v)), true)
+		}
+	}
+	for _, v := range newValues {
+		if !contains(oldValues, v) {
+			d.add(AddedEnumValue, path, fmt.Sprintf("%q", fmt.Sprint(v)), false)
+		}
+	}
+}
+
+// lowerBounds and upperBounds are numeric keywords; raising a lower bound or
+// lowering an upper bound tightens the schema.
+var (
+	lowerBounds = []string{"minLength", "minimum", "exclusiveMinimum", "minItems", "minProperties"}
+	upperBounds = []string{"maxLength", "maximum", "exclusiveMaximum", "maxItems", "maxProperties"}
+)
+
+func (d *differ) constraints(path string, old, new map[string]interface{}) {
+	for _, key := range lowerBounds {
+		d.bound(path, key, old[key], new[key], func(o, n float64) bool { return n > o })
+	}
+	for _, key := range upperBounds {
+		d.bound(path, key, old[key], new[key], func(o, n float64) bool { return n < o })
+	}
+
+	for _, key := range []string{"pattern", "format", "const"} {
+		o, n := old[key], new[key]
+		switch {
+		case reflect.DeepEqual(o, n):
+		case n == nil:
+			d.add(LoosenedConstraint, path, key+" removed", false)
+		case o == nil:
+			d.add(TightenedConstraint, path, fmt.Sprintf("%s %v added", key, n), true)
+		default:
+			// Patterns cannot be compared for inclusion, so any change is
+			// treated as breaking
+			d.add(TightenedConstraint, path, fmt.Sprintf("%s %v -> %v", key, o, n), true)
+		}
+	}
+}
+
+func (d *differ) bound(path, key string, old, new interface{}, tighter func(o, n float64) bool) {
+	o, oldOK := old.(float64)
+	n, newOK := new.(float64)
+	switch {
+	case !oldOK && !newOK:
+	case !oldOK:
+		d.add(TightenedConstraint, path, fmt.Sprintf("%s %v added", key, n), true)
+	case !newOK:
+		d.add(LoosenedConstraint, path, key+" removed", false)
+	case tighter(o, n):
+		d.add(TightenedConstraint, path, fmt.Sprintf("%s %v -> %v", key, o, n), true)
+	case o != n:
+		d.add(LoosenedConstraint, path, fmt.Sprintf("%s %v -> %v", key, o, n), false)
+	}
+}
+
+func (d *differ) properties(path string, old, new map[string]interface{}) {
+	oldProps, _ := old["properties"].(map[string]interface{})
+	newProps, _ := new["properties"].(map[string]interface{})
+	oldRequired := requiredSet(old)
+	newRequired := requiredSet(new)
+
+	for name, oldProp := range oldProps {
+		field := path + "." + name
+		newProp, ok := newProps[name]
+		if !ok {
+			d.add(RemovedField, field, "", true)
+			continue
+		}
+		switch {
+		case !oldRequired[name] && newRequired[name]:
+			d.add(NowRequired, field, "", true)
+		case oldRequired[name] && !newRequired[name]:
+			d.add(NowOptional, field, "", false)
+		}
+		d.node(field, asObject(oldProp), asObject(newProp))
+	}
+	for name := range newProps {
+		if _, ok := oldProps[name]; ok {
+			continue
+		}
+		if newRequired[name] {
+			d.add(AddedRequiredField, path+"."+name, "", true)
+		} else {
+			d.add(AddedOptionalField, path+"."+name, "", false)
+		}
+	}
+}
+
+// additional compares additionalProperties, which is either a boolean or a
+// schema for the extra values.
+func (d *differ) additional(path string, old, new interface{}) {
+	if reflect.DeepEqual(old, new) {
+		return
+	}
+	oldClosed := old == false
+	newClosed := new == false
+	switch {
+	case !oldClosed && newClosed:
+		d.add(TightenedConstraint, path, "additionalProperties closed", true)
+	case oldClosed && !newClosed:
+		d.add(LoosenedConstraint, path, "additionalProperties opened", false)
+	default:
+		oldSchema, _ := old.(map[string]interface{})
+		newSchema, _ := new.(map[string]interface{})
+		d.node(path+"{}", oldSchema, newSchema)
+	}
+}
+
+func requiredSet(node map[string]interface{}) map[string]bool {
+	set := map[string]bool{}
+	required, _ := node["required"].([]interface{})
+	for _, r := range required {
+		set[fmt.Sprint(r)] = true
+	}
+	return set
+}
+
+func asObject(v interface{}) map[string]interface{} {
+	m, _ := v.(map[string]interface{})
+	return m
+}
+
+func contains(values []interface{}, v interface{}) bool {
+	for _, x := range values {
+		if reflect.DeepEqual(x, v) {
+			return true
+		}
+	}
+	return false
+}
+
+// describe summarises a node's type for change details.
+func describe(node map[string]interface{}) string {
+	if ref, ok := node["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/")
+	}
+	if t, ok := node["type"]; ok {
+		return fmt.Sprint(t)
+	}
+	return "any"
+}
@@ -0,0 +1,143 @@
+package schemadiff
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func parse(t *testing.T, content string) *parser.Schema {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return s
+}
+
+const base = `{
+	"title": "Test Schema v0.1",
+	"$defs": {
+		"Query": {
+			"type": "object",
+			"required": ["model"],
+			"properties": {
+				"model": { "type": "string", "minLength": 1 },
+				"limit": { "type": "integer" },
+				"op": { "oneOf": [{ "enum": ["eq", "ne"] }, { "type": "string", "pattern": "^custom:.+$" }] },
+				"fields": { "type": "array", "items": { "type": "string" } }
+			}
+		},
+		"Legacy": { "type": "object" }
+	}
+}`
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		name string
+		new  string
+		want []Change
+	}{
+		{
+			name: "identical",
+			new:  base,
+			want: nil,
+		},
+		{
+			name: "compatible additions",
+			new: `{
+				"title": "Test Schema v0.2",
+				"$defs": {
+					"Query": {
+						"type": "object",
+						"required": ["model"],
+						"properties": {
+							"model": { "type": "string", "description": "Target model" },
+							"limit": { "type": "integer", "deprecated": true },
+							"op": { "oneOf": [{ "enum": ["eq", "ne", "gt"] }, { "type": "string", "pattern": "^custom:.+$" }] },
+							"fields": { "type": "array", "items": { "type": "string" } },
+							"offset": { "type": "integer" }
+						}
+					},
+					"Legacy": { "type": "object" },
+					"Page": { "type": "object" }
+				}
+			}`,
+			want: []Change{
+				{Kind: AddedDefinition, Path: "Page"},
+				{Kind: Deprecated, Path: "Query.limit"},
+				{Kind: LoosenedConstraint, Path: "Query.model", Detail: "minLength removed"},
+				{Kind: AddedOptionalField, Path: "Query.offset"},
+				{Kind: AddedEnumValue, Path: "Query.op|0", Detail: `"gt"`},
+			},
+		},
+		{
+			name: "breaking changes",
+			new: `{
+				"title": "Test Schema v0.2",
+				"$defs": {
+					"Query": {
+						"type": "object",
+						"additionalProperties": false,
+						"required": ["model", "limit", "where"],
+						"properties": {
+							"model": { "type": "string", "minLength": 2 },
+							"limit": { "type": "integer" },
+							"op": { "oneOf": [{ "enum": ["eq"] }] },
+							"fields": { "type": "array", "items": { "type": "integer" } },
+							"where": { "type": "object" }
+						}
+					}
+				}
+			}`,
+			want: []Change{
+				{Kind: RemovedDefinition, Path: "Legacy", Breaking: true},
+				{Kind: TightenedConstraint, Path: "Query", Detail: "additionalProperties closed", Breaking: true},
+				{Kind: ChangedType, Path: "Query.fields[]", Detail: "string -> integer", Breaking: true},
+				{Kind: NowRequired, Path: "Query.limit", Breaking: true},
+				{Kind: TightenedConstraint, Path: "Query.model", Detail: "minLength 1 -> 2", Breaking: true},
+				{Kind: RemovedEnumValue, Path: "Query.op|0", Detail: `"ne"`, Breaking: true},
+				{Kind: TightenedConstraint, Path: "Query.op|1", Detail: "oneOf branch removed", Breaking: true},
+				{Kind: AddedRequiredField, Path: "Query.where", Breaking: true},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Compare(parse(t, base), parse(t, tt.new))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compare() =\n%v\nwant\n%v", got, tt.want)
+			}
+			if HasBreaking(got) != HasBreaking(tt.want) {
+				t.Errorf("HasBreaking() = %v", HasBreaking(got))
+			}
+		})
+	}
+}
+
+func TestCompareRemovedField(t *testing.T) {
+	old := parse(t, base)
+	new := parse(t, base)
+	delete(new.Definitions["Query"].(map[string]interface{})["properties"].(map[string]interface{}), "limit")
+
+	want := []Change{{Kind: RemovedField, Path: "Query.limit", Breaking: true}}
+	if got := Compare(old, new); !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %v, want %v", got, want)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	c := Change{Kind: RemovedEnumValue, Path: "Query.op|0", Detail: `"ne"`, Breaking: true}
+	want := `BREAKING   removed enum value   Query.op|0 ("ne")`
+	if got := c.String(); got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,211 @@
+// Package schemalint checks the IncludeKit JSON Schema for problems the
+// parser and generators tolerate silently: undocumented definitions and
+// properties, enum values no conformance vector exercises, definitions
+// nothing refers to, and objects that leave additionalProperties unset.
+package schemalint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// Checks run by Lint, which Options.Skip may name
+const (
+	MissingDescription   = "missing-description"
+	UncoveredEnumValue   = "uncovered-enum-value"
+	UnreferencedDef      = "unreferenced-definition"
+	AdditionalProperties = "additional-properties"
+)
+
+// Checks lists every check, in the order problems are reported.
+var Checks = []string{MissingDescription, UncoveredEnumValue, UnreferencedDef, AdditionalProperties}
+
+// Problem is one finding of a check.
+type Problem struct {
+	Check  string
+	Path   string // dotted location, e.g. "Query.order_by" or "Condition.op"
+	Detail string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%-24s %s: %s", p.Check, p.Path, p.Detail)
+}
+
+// Options configure Lint.
+type Options struct {
+	// VectorsDir is the conformance vectors directory enum values must be
+	// covered by; the check is skipped when it is empty.
+	VectorsDir string
+	// Skip names checks not to run.
+	Skip []string
+}
+
+// Lint returns the problems found in s, grouped by check and in schema
+// order within a check.
+func Lint(s *parser.Schema, opts Options) ([]Problem, error) {
+	skip := map[string]bool{}
+	for _, c := range opts.Skip {
+		if !known(c) {
+			return nil, fmt.Errorf("unknown check %q", c)
+		}
+		skip[c] = true
+	}
+
+	l := &linter{schema: s}
+	for _, name := range s.Keys("/$defs", s.Definitions) {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("definition %s is not an object", name)
+		}
+		l.object(name, "/$defs/"+name, def)
+	}
+	l.unreferenced()
+	if opts.VectorsDir != "" && !skip[UncoveredEnumValue] {
+		if err := l.uncoveredEnums(opts.VectorsDir); err != nil {
+			return nil, err
+		}
+	}
+
+	var problems []Problem
+	for _, check := range Checks {
+		if !skip[check] {
+			problems = append(problems, l.problems[check]...)
+		}
+	}
+	return problems, nil
+}
+
+func known(check string) bool {
+	for _, c := range Checks {
+		if c == check {
+			return true
+		}
+	}
+	return false
+}
+
+type linter struct {
+	schema   *parser.Schema
+	problems map[string][]Problem
+}
+
+func (l *linter) add(check, path, detail string) {
+	if l.problems == nil {
+		l.problems = map[string][]Problem{}
+	}
+	l.problems[check] = append(l.problems[check], Problem{Check: check, Path: path, Detail: detail})
+}
+
+// object checks a definition, or an object property with properties of its
+// own, which is named after its path.
+func (l *linter) object(name, pointer string, def map[string]interface{}) {
+	if _, ok := def["description"]; !ok && !strings.Contains(name, ".") {
+		l.add(MissingDescription, name, "definition has no description")
+	}
+	if _, ok := def["additionalProperties"]; !ok {
+		l.add(AdditionalProperties, name, "unknown properties are allowed implicitly")
+	}
+
+	props, _ := def["properties"].(map[string]interface{})
+	propsPointer := pointer + "/properties"
+	for _, prop := range l.schema.Keys(propsPointer, props) {
+		p, _ := props[prop].(map[string]interface{})
+		path := name + "." + prop
+		if _, ok := p["description"]; !ok {
+			l.add(MissingDescription, path, "property has no description")
+		}
+		if items, ok := p["items"].(map[string]interface{}); ok && p["type"] == "array" {
+			p = items
+		}
+		if p["type"] != "object" {
+			continue
+		}
+		if _, ok := p["properties"]; ok {
+			l.object(path, propsPointer+"/"+prop, p)
+		} else if _, ok := p["additionalProperties"]; !ok {
+			l.add(AdditionalProperties, path, "map values are unconstrained implicitly")
+		}
+	}
+}
+
+// unreferenced reports definitions no $ref outside themselves points to,
+// including the root schema's properties.
+func (l *linter) unreferenced() {
+	refs := map[string]bool{}
+	collectRefs(l.schema.Raw["properties"], refs)
+	for name, def := range l.schema.Definitions {
+		inner := map[string]bool{}
+		collectRefs(def, inner)
+		delete(inner, name)
+		for ref := range inner {
+			refs[ref] = true
+		}
+	}
+	for _, name := range l.schema.Keys("/$defs", l.schema.Definitions) {
+		if !refs[name] {
+			l.add(UnreferencedDef, name, "no $ref points to it")
+		}
+	}
+}
+
+func collectRefs(node interface{}, refs map[string]bool) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok {
+			refs[strings.TrimPrefix(ref, "#/$defs/")] = true
+		}
+		for _, v := range n {
+			collectRefs(v, refs)
+		}
+	case []interface{}:
+		for _, v := range n {
+			collectRefs(v, refs)
+		}
+	}
+}
+
+// uncoveredEnums reports enum values that no document in the vectors uses.
+func (l *linter) uncoveredEnums(vectorsDir string) error {
+	constraints, err := l.schema.Constraints()
+	if err != nil {
+		return err
+	}
+	used := map[string]map[string]bool{}
+	err = parser.ReadVectors(vectorsDir, func(file, vector, def string, doc interface{}) {
+		l.schema.Walk(def, doc, func(def string, obj map[string]interface{}) {
+			for prop, value := range obj {
+				key := def + "." + prop
+				if used[key] == nil {
+					used[key] = map[string]bool{}
+				}
+				values, ok := value.([]interface{})
+				if !ok {
+					values = []interface{}{value}
+				}
+				for _, v := range values {
+					if str, ok := v.(string); ok {
+						used[key][str] = true
+					}
+				}
+			}
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, c := range constraints {
+		var missing []string
+		for _, v := range c.Enum {
+			if !used[c.Key()][v] {
+				missing = append(missing, v)
+			}
+		}
+		if len(missing) > 0 {
+			l.add(UncoveredEnumValue, c.Key(), "no vector uses "+strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
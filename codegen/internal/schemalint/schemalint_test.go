@@ -0,0 +1,92 @@
+package schemalint
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+const schema = `{
+	"title": "Test Schema v0.1",
+	"properties": { "Statement": { "$ref": "#/$defs/Statement" } },
+	"$defs": {
+		"Statement": {
+			"type": "object",
+			"description": "A read.",
+			"additionalProperties": false,
+			"properties": {
+				"where": { "$ref": "#/$defs/Filter", "description": "Rows to read" },
+				"kind": { "enum": ["some", "every"], "description": "Include kind" },
+				"group": {
+					"type": "object",
+					"description": "Grouping",
+					"properties": { "keys": { "type": "array", "items": { "type": "string" }, "description": "Keys" } }
+				},
+				"row": { "type": "object", "description": "Row values" }
+			}
+		},
+		"Filter": {
+			"type": "object",
+			"description": "A predicate.",
+			"additionalProperties": false,
+			"properties": {
+				"not": { "$ref": "#/$defs/Filter", "description": "Negation" },
+				"op": { "oneOf": [{ "enum": ["eq", "in", "ne"] }, { "type": "string", "pattern": "^custom:.+$" }] }
+			}
+		},
+		"Orphan": {
+			"type": "object",
+			"additionalProperties": false,
+			"properties": { "self": { "$ref": "#/$defs/Orphan", "description": "Itself" } }
+		}
+	}
+}`
+
+func TestLint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.json")
+	if err := os.WriteFile(path, []byte(schema), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vectors := `[{ "name": "v", "shape": { "kind": "some", "where": { "op": "eq", "not": { "op": "in" } } } }]`
+	if err := os.WriteFile(filepath.Join(dir, "query-shapes.json"), []byte(vectors), 0644); err != nil {
+		t.Fatal(err)
+	}
+	s, err := parser.Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	problems, err := Lint(s, Options{VectorsDir: dir})
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	want := []Problem{
+		{MissingDescription, "Filter.op", "property has no description"},
+		{MissingDescription, "Orphan", "definition has no description"},
+		{UncoveredEnumValue, "Statement.kind", "no vector uses every"},
+		{UncoveredEnumValue, "Filter.op", "no vector uses ne"},
+		// Its own $ref does not count
+		{UnreferencedDef, "Orphan", "no $ref points to it"},
+		{AdditionalProperties, "Statement.group", "unknown properties are allowed implicitly"},
+		{AdditionalProperties, "Statement.row", "map values are unconstrained implicitly"},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("Lint() =\n%v\nwant\n%v", problems, want)
+	}
+
+	problems, err = Lint(s, Options{Skip: []string{MissingDescription, AdditionalProperties}})
+	if err != nil {
+		t.Fatalf("Lint() error = %v", err)
+	}
+	if want := want[4:5]; !reflect.DeepEqual(problems, want) {
+		t.Errorf("Lint() skipping checks, without vectors =\n%v\nwant\n%v", problems, want)
+	}
+
+	if _, err := Lint(s, Options{Skip: []string{"spelling"}}); err == nil {
+		t.Error("Lint() with an unknown check succeeded")
+	}
+}
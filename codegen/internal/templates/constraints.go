@@ -0,0 +1,174 @@
+package templates
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// GoConstraints renders constraints.go for the Go testkit: the enums and
+// patterns of the schema, which the validators check values against instead
+// of keeping their own lists.
+func GoConstraints(s *parser.Schema) ([]byte, error) {
+	constraints, err := s.Constraints()
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `// Code generated by codegen from schema/%s. DO NOT EDIT.
+
+package tests
+
+import "regexp"
+
+// specEnums lists the values the schema allows for each enum property, by
+// "<Definition>.<property>".
+var specEnums = map[string][]string{
+`, filepath.Base(s.Path))
+	for _, c := range constraints {
+		if len(c.Enum) == 0 {
+			continue
+		}
+		values := make([]string, len(c.Enum))
+		for i, v := range c.Enum {
+			values[i] = fmt.Sprintf("%q", v)
+		}
+		fmt.Fprintf(&b, "%q: {%s},\n", c.Key(), strings.Join(values, ", "))
+	}
+	b.WriteString(`}
+
+// specPatterns are the patterns of string properties. A property with an
+// enum as well allows values matching either.
+var specPatterns = map[string]*regexp.Regexp{
+`)
+	for _, c := range constraints {
+		if c.Pattern != "" {
+			fmt.Fprintf(&b, "%q: regexp.MustCompile(`%s`),\n", c.Key(), c.Pattern)
+		}
+	}
+	b.WriteString(`}
+
+// specDiagnostics lists the properties annotated x-diagnostic, by
+// definition. They describe the emitter rather than the read, so
+// canonicalization leaves them out of shape IDs.
+var specDiagnostics = map[string][]string{
+`)
+	diagnostics := parser.Diagnostics(constraints)
+	for _, name := range diagnosticDefinitions(constraints) {
+		props := make([]string, len(diagnostics[name]))
+		for i, p := range diagnostics[name] {
+			props[i] = fmt.Sprintf("%q", p)
+		}
+		fmt.Fprintf(&b, "%q: {%s},\n", name, strings.Join(props, ", "))
+	}
+	b.WriteString("}\n")
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format Go constraints: %w", err)
+	}
+	return src, nil
+}
+
+// diagnosticDefinitions returns the definitions with diagnostic properties
+// in schema order.
+func diagnosticDefinitions(constraints []parser.Constraint) []string {
+	var names []string
+	for _, c := range constraints {
+		if c.Diagnostic && (len(names) == 0 || names[len(names)-1] != c.Definition) {
+			names = append(names, c.Definition)
+		}
+	}
+	return names
+}
+
+// WriteGoConstraints generates constraints.go in dir.
+func WriteGoConstraints(dir string, s *parser.Schema) error {
+	src, err := GoConstraints(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "constraints.go"), src, 0644)
+}
+
+// WriteTypeScriptConstraints generates constraints.ts for the TypeScript
+// validators: the enums and patterns of the schema, the properties strict
+// mode allows, and the diagnostic properties canonicalization leaves out.
+func WriteTypeScriptConstraints(dir string, s *parser.Schema) error {
+	constraints, err := s.Constraints()
+	if err != nil {
+		return err
+	}
+
+	var enums, patterns, keys, diagnostics strings.Builder
+	var definitions []string
+	props := map[string][]string{}
+	for _, c := range constraints {
+		if len(c.Enum) > 0 {
+			values := make([]string, len(c.Enum))
+			for i, v := range c.Enum {
+				values[i] = tsString(v)
+			}
+			fmt.Fprintf(&enums, "  %s: [%s],\n", tsString(c.Key()), strings.Join(values, ", "))
+		}
+		if c.Pattern != "" {
+			fmt.Fprintf(&patterns, "  %s: /%s/,\n", tsString(c.Key()), strings.ReplaceAll(c.Pattern, "/", `\/`))
+		}
+		if _, ok := props[c.Definition]; !ok {
+			definitions = append(definitions, c.Definition)
+			props[c.Definition] = []string{}
+		}
+		if !c.Deprecated {
+			props[c.Definition] = append(props[c.Definition], tsString(c.Property))
+		}
+	}
+	for _, name := range definitions {
+		fmt.Fprintf(&keys, "  %s: [%s],\n", tsString(name), strings.Join(props[name], ", "))
+	}
+	byDefinition := parser.Diagnostics(constraints)
+	for _, name := range diagnosticDefinitions(constraints) {
+		quoted := make([]string, len(byDefinition[name]))
+		for i, p := range byDefinition[name] {
+			quoted[i] = tsString(p)
+		}
+		fmt.Fprintf(&diagnostics, "  %s: [%s],\n", tsString(name), strings.Join(quoted, ", "))
+	}
+
+	content := fmt.Sprintf(`/**
+ * Constraints of IncludeKit Universal Format v%s for the validators
+ * Auto-generated from schema/%s
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+/** Values the schema allows for each enum property, by "<Definition>.<property>" */
+export const SPEC_ENUMS: Record<string, readonly string[]> = {
+%s};
+
+/** Patterns of string properties; a property with an enum as well allows values matching either */
+export const SPEC_PATTERNS: Record<string, RegExp> = {
+%s};
+
+/** Properties of each definition but the deprecated ones, which is all strict mode allows */
+export const SPEC_KEYS: Record<string, readonly string[]> = {
+%s};
+
+/** Properties annotated x-diagnostic, by definition, which canonicalization leaves out of shape IDs */
+export const SPEC_DIAGNOSTICS: Record<string, readonly string[]> = {
+%s};
+
+/** Whether the schema allows value for an enum or pattern property */
+export function specAllows(key: string, value: unknown): boolean {
+  if (typeof value !== 'string') {
+    return false;
+  }
+  return (SPEC_ENUMS[key]?.includes(value) ?? false) || (SPEC_PATTERNS[key]?.test(value) ?? false);
+}
+`, s.Version, filepath.Base(s.Path), enums.String(), patterns.String(), keys.String(), diagnostics.String())
+
+	return os.WriteFile(filepath.Join(dir, "constraints.ts"), []byte(content), 0644)
+}
@@ -0,0 +1,311 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// docOperator is what the reference says about one operator. The schema
+// only lists operator names, so their semantics are recorded here; Docs
+// fails when an operator in the schema has no entry.
+type docOperator struct {
+	value   string // what the condition's value must be
+	meaning string
+}
+
+var docOperators = map[string]docOperator{
+	"eq":           {"any value", "Field equals the value"},
+	"ne":           {"any value", "Field does not equal the value"},
+	"in":           {"array", "Field equals one of the values"},
+	"notIn":        {"array", "Field equals none of the values"},
+	"isNull":       {"boolean or null", "Field is null; with `false`, field is not null"},
+	"gt":           {"number or string", "Field is greater than the value"},
+	"gte":          {"number or string", "Field is greater than or equal to the value"},
+	"lt":           {"number or string", "Field is less than the value"},
+	"lte":          {"number or string", "Field is less than or equal to the value"},
+	"between":      {"`[lo, hi]` array", "Field is within the inclusive range"},
+	"contains":     {"string", "Field contains the substring"},
+	"startsWith":   {"string", "Field starts with the string"},
+	"endsWith":     {"string", "Field ends with the string"},
+	"like":         {"string", "Field matches the SQL LIKE pattern (`%`, `_`)"},
+	"ilike":        {"string", "Field matches the LIKE pattern, ignoring case"},
+	"regex":        {"string", "Field matches the regular expression"},
+	"has":          {"any value", "Array field contains the value"},
+	"hasSome":      {"array", "Array field contains at least one of the values"},
+	"hasEvery":     {"array", "Array field contains all of the values"},
+	"jsonContains": {"any value", "JSON field contains the value, as PostgreSQL's `@>` does"},
+	"lenEq":        {"number", "Length of the string or array field equals the value"},
+	"lenGt":        {"number", "Length of the string or array field is greater than the value"},
+	"lenLt":        {"number", "Length of the string or array field is less than the value"},
+	"exists":       {"boolean or null", "Field is present; with `false`, field is absent"},
+	"inQuery":      {"none; takes `subquery`", "Field equals a value the subquery selects"},
+	"existsQuery":  {"none; takes `subquery`", "As inQuery, but under `not` also keeps rows whose field is null, as `NOT EXISTS` does"},
+	"withinRadius": {"GeoShape circle", "GeoPoint field is within the circle's radius of its center, by great-circle distance"},
+	"intersects":   {"GeoShape", "GeoPoint or GeoShape field touches or overlaps the shape"},
+	"bbox":         {"`[southwest, northeast]` GeoPoints", "GeoPoint field is inside the box, which crosses the antimeridian when southwest lies east of northeast"},
+	"jsonHasKey":   {"string", "JSON object field has the key, as PostgreSQL's `?` does"},
+	"jsonType":     {"`object`, `array`, `string`, `number`, `boolean` or `null`", "JSON field is present and of the type, as PostgreSQL's `jsonb_typeof` names it"},
+}
+
+// docExampleLimit caps the length of an example, so examples stay readable
+// when a smaller one exists.
+const docExampleLimit = 320
+
+// Docs renders REFERENCE.md, the field-by-field reference of the schema:
+// a table of every definition's properties with their types, constraints
+// and descriptions, the operators and what they mean, and examples taken
+// from the conformance vectors in vectorsDir, which may be empty.
+func Docs(s *parser.Schema, vectorsDir string) ([]byte, error) {
+	constraints, err := s.Constraints()
+	if err != nil {
+		return nil, err
+	}
+	byKey := map[string]parser.Constraint{}
+	var definitions []string
+	for _, c := range constraints {
+		if _, ok := byKey[c.Definition+"."]; !ok {
+			byKey[c.Definition+"."] = parser.Constraint{}
+			definitions = append(definitions, c.Definition)
+		}
+		byKey[c.Key()] = c
+	}
+	var ops []string
+	for _, c := range constraints {
+		if c.Key() == "Condition.op" {
+			ops = c.Enum
+		}
+	}
+	for _, op := range ops {
+		if _, ok := docOperators[op]; !ok {
+			return nil, fmt.Errorf("operator %s has no entry in docOperators", op)
+		}
+	}
+
+	ex := &docExamples{best: map[string]docExample{}, ops: map[string]docExample{}}
+	if vectorsDir != "" {
+		err := parser.ReadVectors(vectorsDir, func(file, vector, def string, doc interface{}) {
+			s.Walk(def, doc, func(def string, obj map[string]interface{}) {
+				ex.add(file, vector, def, obj)
+			})
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `# IncludeKit Universal Format v%s — Schema Reference
+
+<!-- Code generated by codegen from schema/%s. DO NOT EDIT. -->
+
+Every type and field of [`+"`%s`"+`](%s), generated by `+"`codegen -lang docs`"+`. For when and why to use each one, see [README.md](README.md).
+
+## Contents
+
+`, s.Version, filepath.Base(s.Path), filepath.Base(s.Path), filepath.Base(s.Path))
+	for _, name := range definitions {
+		fmt.Fprintf(&b, "- [%s](#%s)\n", name, docAnchor(name))
+	}
+	if len(ops) > 0 {
+		b.WriteString("- [Operators](#operators)\n")
+	}
+
+	for _, name := range definitions {
+		schema := s.Definitions[name]
+		pointer := "/$defs/" + name
+		if i := strings.Index(name, "."); i >= 0 {
+			schema = s.Definitions[name[:i]]
+			pointer = "/$defs/" + name[:i]
+			for _, prop := range strings.Split(name[i+1:], ".") {
+				props, _ := schema.(map[string]interface{})["properties"].(map[string]interface{})
+				schema = props[prop]
+				pointer += "/properties/" + prop
+			}
+		}
+		def, _ := schema.(map[string]interface{})
+
+		fmt.Fprintf(&b, "\n## %s\n\n", name)
+		if desc, ok := def["description"].(string); ok {
+			b.WriteString(desc + "\n\n")
+		}
+		b.WriteString("| Field | Type | Required | Constraints | Description |\n|---|---|---|---|---|\n")
+		props, _ := def["properties"].(map[string]interface{})
+		for _, prop := range s.Keys(pointer+"/properties", props) {
+			p, _ := props[prop].(map[string]interface{})
+			c := byKey[name+"."+prop]
+			required := ""
+			if c.Required {
+				required = "yes"
+			}
+			desc, _ := p["description"].(string)
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s |\n", prop, docTableCell(docType(name+"."+prop, p)), required, docTableCell(docConstraints(c)), docTableCell(desc))
+		}
+		if example, ok := ex.best[name]; ok {
+			fmt.Fprintf(&b, "\nExample, from `%s` vector `%s`:\n\n```json\n%s\n```\n", example.file, example.name, example.json)
+		}
+	}
+
+	if len(ops) > 0 {
+		b.WriteString("\n## Operators\n\nValues of `Condition.op`. Custom operators match `")
+		b.WriteString(byKey["Condition.op"].Pattern)
+		b.WriteString("`; validators check their value once they are registered (Go `tests.RegisterOperator`, TypeScript `registerOperator`), and engines that do not know one invalidate conservatively.\n\n")
+		b.WriteString("| Operator | Value | Meaning | Example |\n|---|---|---|---|\n")
+		for _, op := range ops {
+			d := docOperators[op]
+			example := ""
+			if e, ok := ex.ops[op]; ok {
+				example = "`" + e.json + "`"
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s | %s |\n", op, d.value, d.meaning, docTableCell(example))
+		}
+	}
+
+	return []byte(b.String()), nil
+}
+
+// WriteDocs generates REFERENCE.md in dir.
+func WriteDocs(dir string, s *parser.Schema, vectorsDir string) error {
+	content, err := Docs(s, vectorsDir)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "REFERENCE.md"), content, 0644)
+}
+
+// docType describes the type of a property, linking to definitions.
+func docType(key string, p map[string]interface{}) string {
+	if key == "Condition.op" {
+		return "[operator](#operators)"
+	}
+	if ref, ok := p["$ref"].(string); ok {
+		name := strings.TrimPrefix(ref, "#/$defs/")
+		return fmt.Sprintf("[%s](#%s)", name, docAnchor(name))
+	}
+	if enum, ok := p["enum"].([]interface{}); ok {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = fmt.Sprintf("`%q`", v)
+		}
+		return strings.Join(values, " | ")
+	}
+	for _, k := range []string{"oneOf", "anyOf"} {
+		if variants, ok := p[k].([]interface{}); ok {
+			types := make([]string, len(variants))
+			for i, v := range variants {
+				variant, _ := v.(map[string]interface{})
+				types[i] = docType("", variant)
+			}
+			return strings.Join(types, " or ")
+		}
+	}
+	switch p["type"] {
+	case nil:
+		return "any"
+	case "array":
+		items, _ := p["items"].(map[string]interface{})
+		return docType("", items) + "[]"
+	case "object":
+		if _, ok := p["properties"]; ok {
+			return fmt.Sprintf("[object](#%s)", docAnchor(key))
+		}
+		values, ok := p["additionalProperties"].(map[string]interface{})
+		if !ok {
+			return "object"
+		}
+		return "map of " + docType("", values)
+	}
+	return fmt.Sprint(p["type"])
+}
+
+func docConstraints(c parser.Constraint) string {
+	var parts []string
+	if c.Deprecated {
+		parts = append(parts, "deprecated")
+	}
+	if c.Diagnostic {
+		parts = append(parts, "diagnostic")
+	}
+	if c.MinLength > 0 {
+		parts = append(parts, fmt.Sprintf("min length %d", c.MinLength))
+	}
+	if c.Minimum != nil {
+		parts = append(parts, "≥ "+strconv.FormatFloat(*c.Minimum, 'f', -1, 64))
+	}
+	if c.Maximum != nil {
+		parts = append(parts, "≤ "+strconv.FormatFloat(*c.Maximum, 'f', -1, 64))
+	}
+	if c.Pattern != "" && c.Definition+"."+c.Property != "Condition.op" {
+		parts = append(parts, "pattern `"+c.Pattern+"`")
+	}
+	if c.Format != "" {
+		parts = append(parts, c.Format)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// docAnchor is the anchor GitHub gives a heading.
+func docAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r == ' ':
+			b.WriteRune('-')
+		case r == '-' || r == '_' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9':
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func docTableCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
+
+type docExample struct {
+	file, name string
+	props      int
+	json       string
+}
+
+// better prefers examples that show more properties, then shorter ones,
+// within docExampleLimit.
+func (e docExample) better(than docExample) bool {
+	if (len(e.json) <= docExampleLimit) != (len(than.json) <= docExampleLimit) {
+		return len(e.json) <= docExampleLimit
+	}
+	if e.props != than.props {
+		return e.props > than.props
+	}
+	return len(e.json) < len(than.json)
+}
+
+// docExamples collects, for every definition and operator, the best value
+// of it found in the vectors.
+type docExamples struct {
+	best map[string]docExample
+	ops  map[string]docExample
+}
+
+// add records obj, a value of def, as an example.
+func (d *docExamples) add(file, vector, def string, obj map[string]interface{}) {
+	indented, _ := json.MarshalIndent(obj, "", "  ")
+	e := docExample{file: file, name: vector, props: len(obj), json: string(indented)}
+	if old, ok := d.best[def]; !ok || e.better(old) {
+		d.best[def] = e
+	}
+	if def == "Condition" {
+		if op, ok := obj["op"].(string); ok {
+			compact, _ := json.Marshal(obj)
+			e := docExample{file: file, name: vector, json: string(compact)}
+			if old, ok := d.ops[op]; !ok || e.better(old) {
+				d.ops[op] = e
+			}
+		}
+	}
+}
@@ -1,13 +1,534 @@
 package templates
 
 import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
 	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
 )
 
-// WriteGoTypes generates Go type definitions from schema
-// This is a placeholder for future implementation
+// goType records what the schema cannot express about one Go type: its
+// name, doc comment and field idioms. def is a $defs name, or
+// "<Def>/<property>" for an object nested in a definition.
+type goType struct {
+	def    string
+	name   string
+	doc    []string
+	fields map[string]goField
+}
+
+// goField overrides how one schema property is rendered.
+type goField struct {
+	comment string   // trailing line comment; enums default to their values
+	above   []string // comment lines above the field
+	gap     bool     // blank line before the field
+	plain   bool     // optional slice without the pointer, where nil and empty mean the same
+	skip    bool     // not mirrored in Go (deprecated aliases)
+}
+
+// goTypes lists the Go types in file order. Definitions missing here are
+// rendered after them with default naming, so a schema addition shows up as
+// drift instead of being dropped.
+var goTypes = []goType{
+	{
+		def:  "Statement",
+		name: "Statement",
+		doc:  []string{"Statement is the normalized, language-agnostic description of a read"},
+		fields: map[string]goField{
+			"includes":     {plain: true},
+			"spec_version": {comment: "spec version the SDK emitted; absent means 0.1.0"},
+			"orm_version":  {comment: "diagnostic only"},
+			"tenant":       {comment: "tenant the read is scoped to; part of the shape ID"},
+			"policy":       {comment: "row-level visibility predicate; ANDed with the root where"},
+		},
+	},
+	{
+		def:  "Query",
+		name: "Query",
+		fields: map[string]goField{
+			"model":        {comment: `target relation name (e.g., "posts", "author")`},
+			"aggregations": {gap: true, comment: "aggregate projections, typically with Statement.GroupBy"},
+			"joins":        {comment: "relations joined into the result rows"},
+			"windows":      {comment: "window function columns"},
+			"distinct_on":  {comment: "one row per distinct combination, picked by its own order"},
+			"deleted":      {comment: `soft-deleted rows to read: "include" or "only"; live rows when nil`},
+		},
+	},
+	{
+		def:  "Aggregate",
+		name: "Aggregate",
+		doc:  []string{"Aggregate is a typed aggregate projection such as COUNT(*) or SUM(views)."},
+		fields: map[string]goField{
+			"field": {comment: "nil only for count, meaning COUNT(*)"},
+			"alias": {comment: `result column; defaults to "<func>" or "<func>_<field>"`},
+		},
+	},
+	{
+		def:  "Join",
+		name: "Join",
+		doc: []string{
+			"Join joins a relation of the query's model into its result rows. Unlike an",
+			"Include, which loads related records as a nested result, a join flattens",
+			"them into the parent rows; an inner join also drops parents without a match.",
+		},
+		fields: map[string]goField{
+			"on":     {comment: "conditions on the joined model's fields"},
+			"fields": {comment: "joined fields to project; nil projects none"},
+		},
+	},
+	{
+		def:  "Window",
+		name: "Window",
+		doc: []string{
+			"Window is a window function column such as",
+			"ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC).",
+		},
+		fields: map[string]goField{
+			"field": {comment: "sum, avg, min and max; nil for count means COUNT(*)"},
+			"frame": {comment: "nil uses the SQL default frame"},
+		},
+	},
+	{
+		def:  "WindowFrame",
+		name: "WindowFrame",
+		doc: []string{
+			"WindowFrame bounds the rows an aggregate window function covers, as",
+			"offsets from the current row.",
+		},
+		fields: map[string]goField{
+			"start": {comment: "negative precedes the current row; nil is unbounded preceding"},
+			"end":   {comment: "nil is unbounded following"},
+		},
+	},
+	{
+		def:  "DistinctOn",
+		name: "DistinctOn",
+		doc: []string{
+			"DistinctOn keeps the first row, in OrderBy order, of each combination of",
+			"Fields. OrderBy starts with one entry for each of Fields. It is separate",
+			"from Query.OrderBy, which sorts the kept rows, so the row kept need not",
+			"be the first in listing order; Query.Distinct picks by Query.OrderBy.",
+		},
+	},
+	{
+		def:  "Include",
+		name: "Include",
+		doc: []string{
+			"Include defines nested relation loading and optional relation-based filtering.",
+			"When Kind is nil, this loads the relation data.",
+			"When Kind is set, this filters the parent records based on the relation.",
+			"When Fields is nil/empty and Kind is set, this filters without loading data.",
+		},
+		fields: map[string]goField{
+			"kind":     {comment: `"some" | "every" | "none" | "count" - filters parent by relation`},
+			"includes": {plain: true, comment: "nested includes"},
+			"count":    {comment: `set exactly when Kind is "count"`},
+		},
+	},
+	{
+		def:  "RelationCount",
+		name: "RelationCount",
+		doc: []string{
+			"RelationCount filters parents by how many relation rows match the",
+			"include's where, as in users with more than 5 posts.",
+		},
+		fields: map[string]goField{
+			"op": {comment: `"eq" | "ne" | "gt" | "gte" | "lt" | "lte"`},
+		},
+	},
+	{
+		def:  "Filter",
+		name: "Filter",
+		doc:  []string{"Filter composes predicates with boolean logic"},
+	},
+	{
+		def:  "Condition",
+		name: "Condition",
+		doc:  []string{"Condition is a leaf-level predicate"},
+		fields: map[string]goField{
+			"field_path": {plain: true},
+			"subquery":   {comment: "inQuery and existsQuery only"},
+			"path":       {skip: true},
+		},
+	},
+	{
+		def:  "GeoPoint",
+		name: "GeoPoint",
+		doc:  []string{"GeoPoint is a WGS 84 location, the value geospatial operators compare"},
+	},
+	{
+		def:  "GeoShape",
+		name: "GeoShape",
+		doc: []string{
+			"GeoShape is a circle, with Center and Radius, or a polygon. It is the value",
+			"of withinRadius (a circle) and intersects conditions.",
+		},
+		fields: map[string]goField{
+			"radius":  {comment: "meters"},
+			"polygon": {plain: true, comment: "implicitly closed"},
+		},
+	},
+	{
+		def:  "OrderBy",
+		name: "OrderBy",
+		doc:  []string{"OrderBy defines field ordering"},
+		fields: map[string]goField{
+			"descending":     {comment: "true = DESCENDING, false = ASCENDING"},
+			"nulls_first":    {comment: "true = NULLS FIRST, false = NULLS LAST"},
+			"case_sensitive": {comment: "true = case-sensitive, false = case-insensitive"},
+		},
+	},
+	{
+		def:  "Pagination",
+		name: "Pagination",
+		doc: []string{
+			"Pagination defines cursor-based pagination parameters.",
+			"Uses opaque cursors (base64-encoded JSON) for SDK abstraction.",
+			"Forward pagination: use First + After",
+			"Backward pagination: use Last + Before",
+		},
+		fields: map[string]goField{
+			"first":  {comment: "Forward limit"},
+			"last":   {comment: "Backward limit"},
+			"after":  {comment: "Opaque cursor to start after (forward)"},
+			"before": {comment: "Opaque cursor to start before (backward)"},
+		},
+	},
+	{
+		def:  "Mutation",
+		name: "Mutation",
+		doc:  []string{"Mutation describes writes that could affect reads"},
+		fields: map[string]goField{
+			"tenant": {comment: "tenant whose rows are written; any tenant's when nil"},
+		},
+	},
+	{
+		def:  "Transaction",
+		name: "Transaction",
+		doc: []string{
+			"Transaction groups the mutations of one source commit. Engines apply them",
+			"atomically, and reject a Seq at or below the last one they applied.",
+		},
+		fields: map[string]goField{
+			"seq":          {comment: "strictly increasing per source"},
+			"committed_at": {comment: "RFC 3339 instant"},
+		},
+	},
+	{
+		def:  "Change",
+		name: "Change",
+		doc:  []string{"Change represents a single mutation operation (insert/update/delete)"},
+		fields: map[string]goField{
+			"sets": {plain: true},
+		},
+	},
+	{
+		def:  "Dependencies",
+		name: "Dependencies",
+		doc:  []string{"Dependencies tracks what a read depends on (engine output)"},
+		fields: map[string]goField{
+			"spec_version": {comment: "format version; absent means 0.1.0"},
+			"shape_id":     {gap: true},
+			"records":      {comment: "record IDs by model; see RecordID"},
+			"includes":     {comment: "includes with Kind set"},
+			"ttl": {
+				above: []string{
+					"TTL and ValidUntil bound staleness for sources whose changes never",
+					"arrive as Mutations (external APIs, views).",
+				},
+				comment: "seconds after registration",
+			},
+			"valid_until":       {comment: "RFC 3339 instant"},
+			"window_fields":     {plain: true, comment: "fields the root query's windows depend on"},
+			"soft_delete_field": {comment: "root model's soft-delete field, unless the root query reads every row"},
+			"tenant":            {comment: "tenant of the statement; other tenants' mutations never invalidate"},
+			"policy":            {comment: "policy of the statement, tracked apart from filters"},
+		},
+	},
+	{
+		def:  "PaginationBoundary",
+		name: "PaginationBoundary",
+		doc:  []string{"PaginationBoundary tracks the last included row for paginated queries"},
+		fields: map[string]goField{
+			"row":    {above: []string{"Field values of the last included row"}},
+			"cursor": {above: []string{"Cursor identifies the stable pagination cursor"}},
+		},
+	},
+	{
+		def:  "Dependencies/group_by",
+		name: "GroupByKV",
+		doc:  []string{"GroupByKV tracks group-by dimensions"},
+	},
+	{
+		def:  "KV",
+		name: "KV",
+	},
+}
+
+// goInitialisms are wire-name segments written in upper case (tx_id -> TxID).
+var goInitialisms = map[string]bool{
+	"id": true, "ttl": true, "orm": true, "sdk": true, "url": true, "json": true,
+}
+
+// GoTypes renders pkgs/go/types/types.go from the schema. The output is
+// gofmt'd so it can be compared byte for byte with the file on disk.
+func GoTypes(s *parser.Schema) ([]byte, error) {
+	gen := &goTypesGen{schema: s, names: map[string]string{}, overlay: map[string]goType{}}
+	for _, t := range goTypes {
+		if _, err := gen.definition(t.def); err != nil {
+			return nil, fmt.Errorf("goTypes: %w", err)
+		}
+		gen.names[t.def] = t.name
+		gen.overlay[t.def] = t
+		gen.order = append(gen.order, t.def)
+	}
+	for _, name := range s.Keys("/$defs", s.Definitions) {
+		if _, ok := gen.names[name]; !ok {
+			gen.names[name] = name
+			gen.order = append(gen.order, name)
+		}
+	}
+
+	var b strings.Builder
+	if s.Suffix != "" {
+		// An older spec version generated beside the current types
+		fmt.Fprintf(&b, `// Code generated by codegen from schema/%s. DO NOT EDIT.
+
+// Package %s provides type definitions for IncludeKit Universal Format
+// v%s, beside package types for the current version, so callers can
+// migrate between spec versions incrementally.
+package %s
+`, filepath.Base(s.Path), GoPackage(s), s.SpecVersion(), GoPackage(s))
+	} else {
+		fmt.Fprintf(&b, `// Package types provides type definitions for IncludeKit Universal Format v%s
+// This is a PRODUCTION package - types and zero-dependency helpers only, no validation or hashing.
+//
+// IMPORTANT: These types are HAND-WRITTEN to preserve idiomatic Go patterns like
+// pointer-to-slice for optionals. codegen renders the same file from
+// schema/%s and "codegen -lang go -check" fails when the two drift,
+// so update this file whenever the schema changes.
+package types
+`, s.Version, filepath.Base(s.Path))
+	}
+
+	// Nested objects without an overlay entry are appended to order while
+	// rendering, so iterate by index
+	for i := 0; i < len(gen.order); i++ {
+		if err := gen.render(&b, gen.order[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format Go types: %w", err)
+	}
+	return src, nil
+}
+
+// WriteGoTypes generates types.go in dir.
 func WriteGoTypes(dir string, s *parser.Schema) error {
-	// Future: Generate Go types using text/template
-	// For now, Go types are hand-maintained
+	src, err := GoTypes(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "types.go"), src, 0644)
+}
+
+type goTypesGen struct {
+	schema  *parser.Schema
+	names   map[string]string // def -> Go type name
+	overlay map[string]goType
+	order   []string
+}
+
+// definition resolves a $defs name or "<Def>/<property>" to its schema
+// object and JSON pointer.
+func (g *goTypesGen) definition(def string) (map[string]interface{}, error) {
+	parent, prop, nested := strings.Cut(def, "/")
+	obj, ok := g.schema.Definitions[parent].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("definition %s is not in the schema", parent)
+	}
+	if !nested {
+		return obj, nil
+	}
+	props, _ := obj["properties"].(map[string]interface{})
+	if obj, ok = props[prop].(map[string]interface{}); !ok {
+		return nil, fmt.Errorf("property %s is not in the schema", def)
+	}
+	return obj, nil
+}
+
+func (g *goTypesGen) pointer(def string) string {
+	parent, prop, nested := strings.Cut(def, "/")
+	if nested {
+		return "/$defs/" + parent + "/properties/" + prop
+	}
+	return "/$defs/" + parent
+}
+
+func (g *goTypesGen) render(b *strings.Builder, def string) error {
+	obj, err := g.definition(def)
+	if err != nil {
+		return err
+	}
+	t, ok := g.overlay[def]
+	if !ok {
+		t = goType{def: def, name: g.names[def]}
+		if desc, ok := obj["description"].(string); ok {
+			t.doc = []string{desc}
+		}
+	}
+
+	props, _ := obj["properties"].(map[string]interface{})
+	for wire := range t.fields {
+		if _, ok := props[wire]; !ok {
+			return fmt.Errorf("goTypes: %s.%s is not in the schema", def, wire)
+		}
+	}
+	required := map[string]bool{}
+	if req, ok := obj["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	b.WriteString("\n")
+	for _, line := range t.doc {
+		fmt.Fprintf(b, "// %s\n", line)
+	}
+	fmt.Fprintf(b, "type %s struct {\n", t.name)
+	first := true
+	for _, wire := range g.schema.Keys(g.pointer(def)+"/properties", props) {
+		f := t.fields[wire]
+		if f.skip {
+			continue
+		}
+		prop, _ := props[wire].(map[string]interface{})
+		typ, err := g.fieldType(def, wire, prop, required[wire], f.plain)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", def, wire, err)
+		}
+
+		if f.gap && !first {
+			b.WriteString("\n")
+		}
+		first = false
+		for _, line := range f.above {
+			fmt.Fprintf(b, "\t// %s\n", line)
+		}
+		tag := wire
+		if !required[wire] {
+			tag += ",omitempty"
+		}
+		fmt.Fprintf(b, "\t%s %s `json:%q`", goFieldName(wire), typ, tag)
+		comment := f.comment
+		if enum, ok := prop["enum"].([]interface{}); ok && comment == "" {
+			values := make([]string, len(enum))
+			for i, v := range enum {
+				values[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+			}
+			comment = strings.Join(values, " | ")
+		}
+		if comment != "" {
+			fmt.Fprintf(b, " // %s", comment)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("}\n")
 	return nil
 }
+
+// fieldType maps a property to its Go type. Optional scalars, references and
+// slices are pointers so that absent and zero stay distinct; maps and
+// untyped values are already nilable and never are.
+func (g *goTypesGen) fieldType(def, wire string, prop map[string]interface{}, required, plain bool) (string, error) {
+	typ, nilable, err := g.valueType(def+"/"+wire, prop)
+	if err != nil {
+		return "", err
+	}
+	if required || nilable || (plain && strings.HasPrefix(typ, "[]")) {
+		return typ, nil
+	}
+	return "*" + typ, nil
+}
+
+// valueType returns the Go type for a schema value and whether it is
+// nilable without a pointer.
+func (g *goTypesGen) valueType(def string, prop map[string]interface{}) (string, bool, error) {
+	if prop == nil {
+		return "any", true, nil
+	}
+	if ref, ok := prop["$ref"].(string); ok {
+		name, ok := g.names[strings.TrimPrefix(ref, "#/$defs/")]
+		if !ok {
+			return "", false, fmt.Errorf("unknown reference %s", ref)
+		}
+		return name, false, nil
+	}
+	if _, ok := prop["enum"]; ok {
+		return "string", false, nil
+	}
+	if _, ok := prop["oneOf"]; ok {
+		return "string", false, nil
+	}
+
+	switch prop["type"] {
+	case nil:
+		return "any", true, nil
+	case "string":
+		return "string", false, nil
+	case "integer":
+		return "int", false, nil
+	case "number":
+		return "float64", false, nil
+	case "boolean":
+		return "bool", false, nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		elem, _, err := g.valueType(def, items)
+		if err != nil {
+			return "", false, err
+		}
+		return "[]" + elem, false, nil
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			name, ok := g.names[def]
+			if !ok {
+				parent, prop, _ := strings.Cut(def, "/")
+				name = parent + goFieldName(prop)
+				g.names[def] = name
+				g.order = append(g.order, def)
+			}
+			return name, false, nil
+		}
+		values, _ := prop["additionalProperties"].(map[string]interface{})
+		elem, _, err := g.valueType(def, values)
+		if err != nil {
+			return "", false, err
+		}
+		return "map[string]" + elem, true, nil
+	}
+	return "", false, fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// goFieldName converts a wire name to an exported Go name, upper-casing
+// initialisms.
+func goFieldName(wire string) string {
+	var b strings.Builder
+	for _, part := range strings.Split(wire, "_") {
+		if part == "" {
+			continue
+		}
+		if goInitialisms[part] {
+			b.WriteString(strings.ToUpper(part))
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return b.String()
+}
@@ -0,0 +1,270 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// WriteKotlinModels generates kotlinx.serialization data classes for every
+// schema definition. Properties are camelCase Kotlin names with a
+// @SerialName for the wire name, and optional properties default to null,
+// which the default Json configuration omits when encoding. Untyped
+// properties are JsonElement, integers are Long and date-times are strings.
+func WriteKotlinModels(dir string, s *parser.Schema) error {
+	gen := &kotlinModels{schema: s}
+	for _, name := range s.Keys("/$defs", s.Definitions) {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("definition %s is not an object", name)
+		}
+		if err := gen.class(name, "/$defs/"+name, def); err != nil {
+			return err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `/**
+ * IncludeKit Universal Format v%s
+ * Auto-generated from schema/%s
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+package %s
+
+import kotlinx.serialization.SerialName
+import kotlinx.serialization.Serializable
+`, s.Version, filepath.Base(s.Path), KotlinPackage(s))
+	if gen.jsonElement {
+		b.WriteString("import kotlinx.serialization.json.JsonElement\n")
+	}
+	for _, decl := range gen.decls {
+		b.WriteString("\n")
+		b.WriteString(decl)
+	}
+
+	return os.WriteFile(filepath.Join(dir, "Models.kt"), []byte(b.String()), 0644)
+}
+
+// WriteKotlinProject generates the Gradle build of the models library.
+func WriteKotlinProject(dir string, s *parser.Schema) error {
+	build := fmt.Sprintf(`plugins {
+    kotlin("jvm") version "2.0.21"
+    kotlin("plugin.serialization") version "2.0.21"
+}
+
+group = "dev.includekit"
+version = %q
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    api("org.jetbrains.kotlinx:kotlinx-serialization-json:1.7.3")
+}
+
+kotlin {
+    jvmToolchain(17)
+}
+`, s.SpecVersion())
+	if err := os.WriteFile(filepath.Join(dir, "build.gradle.kts"), []byte(build), 0644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "settings.gradle.kts"), []byte(fmt.Sprintf("rootProject.name = %q\n", Project(s, "includekit-spec"))), 0644)
+}
+
+type kotlinModels struct {
+	schema      *parser.Schema
+	decls       []string
+	jsonElement bool
+}
+
+// class renders a definition as a data class. Enums and nested object
+// definitions are rendered first, named <Class><Property>.
+func (g *kotlinModels) class(name, pointer string, def map[string]interface{}) error {
+	props, _ := def["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := def["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var b strings.Builder
+	if desc, ok := def["description"].(string); ok {
+		b.WriteString(kotlinDoc("", desc))
+	}
+	fmt.Fprintf(&b, "@Serializable\ndata class %s(\n", name)
+
+	propsPointer := pointer + "/properties"
+	for _, wire := range g.schema.Keys(propsPointer, props) {
+		prop, _ := props[wire].(map[string]interface{})
+		typ, err := g.fieldType(name+pascalCase(wire), propsPointer+"/"+wire, prop)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, wire, err)
+		}
+		if !required[wire] {
+			typ += "? = null"
+		}
+		if desc, ok := prop["description"].(string); ok {
+			b.WriteString(kotlinDoc("    ", desc))
+		}
+		if deprecated, _ := prop["deprecated"].(bool); deprecated {
+			b.WriteString("    @Deprecated(\"Deprecated in the schema\")\n")
+		}
+		fmt.Fprintf(&b, "    @SerialName(%q) val %s: %s,\n", wire, kotlinName(camelCase(wire)), typ)
+	}
+	b.WriteString(")\n")
+
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+// fieldType returns the Kotlin type of a property.
+func (g *kotlinModels) fieldType(nestedName, pointer string, prop map[string]interface{}) (string, error) {
+	if prop == nil {
+		g.jsonElement = true
+		return "JsonElement", nil
+	}
+	if ref, ok := prop["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/"), nil
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		return nestedName, g.enum(nestedName, enum)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants, ok := prop[key].([]interface{}); ok {
+			// Unions of strings, such as an enum or a pattern, are plain
+			// strings; anything else is left untyped
+			if stringVariants(variants) {
+				return "String", nil
+			}
+			g.jsonElement = true
+			return "JsonElement", nil
+		}
+	}
+
+	switch prop["type"] {
+	case nil:
+		g.jsonElement = true
+		return "JsonElement", nil
+	case "string":
+		return "String", nil
+	case "integer":
+		return "Long", nil
+	case "number":
+		return "Double", nil
+	case "boolean":
+		return "Boolean", nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		typ, err := g.fieldType(nestedName, pointer+"/items", items)
+		if err != nil {
+			return "", err
+		}
+		return "List<" + typ + ">", nil
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			return nestedName, g.class(nestedName, pointer, prop)
+		}
+		values, _ := prop["additionalProperties"].(map[string]interface{})
+		typ, err := g.fieldType(nestedName, pointer+"/additionalProperties", values)
+		if err != nil {
+			return "", err
+		}
+		return "Map<String, " + typ + ">", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// enum renders a string enum as an enum class with a @SerialName per value.
+func (g *kotlinModels) enum(name string, enum []interface{}) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "@Serializable\nenum class %s {\n", name)
+	for _, v := range enum {
+		value, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("enum value %v is not a string", v)
+		}
+		fmt.Fprintf(&b, "    @SerialName(%q) %s,\n", value, screamingSnakeCase(value))
+	}
+	b.WriteString("}\n")
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+func kotlinDoc(indent, s string) string {
+	return indent + "/** " + strings.ReplaceAll(strings.ReplaceAll(s, "*/", "*&#47;"), "\n", "\n"+indent+" * ") + " */\n"
+}
+
+var kotlinKeywords = map[string]bool{
+	"as": true, "break": true, "class": true, "continue": true, "do": true,
+	"else": true, "false": true, "for": true, "fun": true, "if": true, "in": true,
+	"interface": true, "is": true, "null": true, "object": true, "package": true,
+	"return": true, "super": true, "this": true, "throw": true, "true": true,
+	"try": true, "typealias": true, "typeof": true, "val": true, "var": true,
+	"when": true, "while": true,
+}
+
+// kotlinName quotes hard keywords in backticks.
+func kotlinName(name string) string {
+	if kotlinKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
+
+// stringVariants reports whether every variant of a union is a string.
+func stringVariants(variants []interface{}) bool {
+	for _, v := range variants {
+		variant, _ := v.(map[string]interface{})
+		if variant["type"] == "string" {
+			continue
+		}
+		enum, ok := variant["enum"].([]interface{})
+		if !ok {
+			return false
+		}
+		for _, e := range enum {
+			if _, ok := e.(string); !ok {
+				return false
+			}
+		}
+	}
+	return len(variants) > 0
+}
+
+// camelCase converts a snake_case wire name to camelCase.
+func camelCase(s string) string {
+	p := pascalCase(s)
+	if p == "" {
+		return p
+	}
+	r := []rune(p)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// screamingSnakeCase converts an enum value such as "notIn" to NOT_IN.
+func screamingSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case r == '-' || r == '.' || r == ' ':
+			r = '_'
+		case unicode.IsUpper(r) && i > 0:
+			b.WriteByte('_')
+		}
+		b.WriteRune(unicode.ToUpper(r))
+	}
+	name := b.String()
+	if name == "" || unicode.IsDigit(rune(name[0])) {
+		name = "V_" + name
+	}
+	return name
+}
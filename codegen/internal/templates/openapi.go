@@ -0,0 +1,322 @@
+package templates
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// WriteOpenAPI generates openapi.json, an OpenAPI 3.1 document of the Engine
+// HTTP contract served by the Go httpserver package: a path for every route,
+// and a component schema for every schema definition plus the engine API
+// messages.
+//
+// OpenAPI 3.1 schemas are JSON Schema, so definitions are copied as written,
+// in schema order, with references rewritten from #/$defs to
+// #/components/schemas.
+func WriteOpenAPI(dir string, s *parser.Schema) error {
+	var defs bytes.Buffer
+	for i, name := range s.Keys("/$defs", s.Definitions) {
+		if i > 0 {
+			defs.WriteByte(',')
+		}
+		if err := writeOpenAPIValue(&defs, s, "/$defs/"+name, "", name); err != nil {
+			return err
+		}
+		defs.WriteByte(':')
+		if err := writeOpenAPIValue(&defs, s, "/$defs/"+name, "", s.Definitions[name]); err != nil {
+			return fmt.Errorf("definition %s: %w", name, err)
+		}
+	}
+
+	info, err := json.Marshal(struct {
+		Title       string `json:"title"`
+		Version     string `json:"version"`
+		Description string `json:"description"`
+	}{
+		Title:       "IncludeKit Engine API",
+		Version:     s.Version,
+		Description: "Auto-generated from schema/" + filepath.Base(s.Path) + ". DO NOT EDIT. Responses are canonical JSON (JCS), so they can be compared byte for byte.",
+	})
+	if err != nil {
+		return err
+	}
+
+	doc := fmt.Sprintf(`{"openapi":"3.1.0","info":%s,"paths":%s,"components":{"schemas":{%s,%s},"responses":%s}}`,
+		info, openAPIPaths, defs.Bytes(), openAPIEngineSchemas, openAPIResponses)
+	var out bytes.Buffer
+	if err := json.Indent(&out, []byte(doc), "", "  "); err != nil {
+		return fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	out.WriteByte('\n')
+
+	return os.WriteFile(filepath.Join(dir, "openapi.json"), out.Bytes(), 0644)
+}
+
+// writeOpenAPIValue writes v, found at pointer in the schema, as compact
+// JSON with object keys in schema order. key is the key v is found under,
+// so $ref values can be rewritten.
+func writeOpenAPIValue(b *bytes.Buffer, s *parser.Schema, pointer, key string, v interface{}) error {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		b.WriteByte('{')
+		for i, k := range s.Keys(pointer, val) {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeOpenAPIValue(b, s, pointer, "", k); err != nil {
+				return err
+			}
+			b.WriteByte(':')
+			if err := writeOpenAPIValue(b, s, pointer+"/"+escapeJSONPointer(k), k, val[k]); err != nil {
+				return err
+			}
+		}
+		b.WriteByte('}')
+		return nil
+	case []interface{}:
+		b.WriteByte('[')
+		for i, elem := range val {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			if err := writeOpenAPIValue(b, s, fmt.Sprintf("%s/%d", pointer, i), "", elem); err != nil {
+				return err
+			}
+		}
+		b.WriteByte(']')
+		return nil
+	case string:
+		if key == "$ref" {
+			val = strings.Replace(val, "#/$defs/", "#/components/schemas/", 1)
+		}
+		v = val
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	b.Write(data)
+	return nil
+}
+
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// openAPIPaths describes the routes of the Go httpserver package.
+const openAPIPaths = `{
+  "/shape-id": {"post": {
+    "operationId": "computeShapeId",
+    "summary": "Compute the shape ID of a statement",
+    "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Statement"}}}},
+    "responses": {
+      "200": {"description": "The shape ID", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ShapeIDResponse"}}}},
+      "400": {"$ref": "#/components/responses/BadRequest"},
+      "413": {"$ref": "#/components/responses/TooLarge"},
+      "503": {"$ref": "#/components/responses/Unavailable"}
+    }
+  }},
+  "/add-query": {"post": {
+    "operationId": "addQuery",
+    "summary": "Register a query and record its dependencies",
+    "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/AddQueryRequest"}}}},
+    "responses": {
+      "200": {"description": "The shape ID and recorded dependencies", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/AddQueryResponse"}}}},
+      "400": {"$ref": "#/components/responses/BadRequest"},
+      "413": {"$ref": "#/components/responses/TooLarge"},
+      "503": {"$ref": "#/components/responses/Unavailable"}
+    }
+  }},
+  "/invalidate": {"post": {
+    "operationId": "invalidate",
+    "summary": "Evict the shapes a mutation invalidates",
+    "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Mutation"}}}},
+    "responses": {
+      "200": {"description": "The evicted shape IDs", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/InvalidateResponse"}}}},
+      "400": {"$ref": "#/components/responses/BadRequest"},
+      "413": {"$ref": "#/components/responses/TooLarge"},
+      "503": {"$ref": "#/components/responses/Unavailable"}
+    }
+  }},
+  "/invalidate-transaction": {"post": {
+    "operationId": "invalidateTransaction",
+    "summary": "Evict the shapes a committed transaction invalidates",
+    "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Transaction"}}}},
+    "responses": {
+      "200": {"description": "The evicted shape IDs", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/InvalidateResponse"}}}},
+      "400": {"$ref": "#/components/responses/BadRequest"},
+      "413": {"$ref": "#/components/responses/TooLarge"},
+      "503": {"$ref": "#/components/responses/Unavailable"}
+    }
+  }},
+  "/explain": {"post": {
+    "operationId": "explainInvalidation",
+    "summary": "Explain why a mutation would invalidate a registered shape",
+    "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ExplainRequest"}}}},
+    "responses": {
+      "200": {"description": "The reasons, if any", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/ExplainResponse"}}}},
+      "400": {"$ref": "#/components/responses/BadRequest"},
+      "413": {"$ref": "#/components/responses/TooLarge"},
+      "503": {"$ref": "#/components/responses/Unavailable"}
+    }
+  }},
+  "/schema": {"post": {
+    "operationId": "setSchema",
+    "summary": "Set the app schema resolving relations and primary keys",
+    "requestBody": {"required": true, "content": {"application/json": {"schema": {"$ref": "#/components/schemas/AppSchema"}}}},
+    "responses": {
+      "204": {"description": "The schema was set"},
+      "400": {"$ref": "#/components/responses/BadRequest"},
+      "413": {"$ref": "#/components/responses/TooLarge"},
+      "503": {"$ref": "#/components/responses/Unavailable"}
+    }
+  }},
+  "/reset": {"post": {
+    "operationId": "reset",
+    "summary": "Forget every registered shape",
+    "responses": {
+      "204": {"description": "The engine was reset"}
+    }
+  }},
+  "/version": {"get": {
+    "operationId": "getVersion",
+    "summary": "Get the engine version",
+    "responses": {
+      "200": {"description": "The engine version", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/VersionInfo"}}}}
+    }
+  }}
+}`
+
+// openAPIEngineSchemas are the engine API messages, which are
+// engine-specific and not in the universal format; they mirror the Go
+// mock package.
+const openAPIEngineSchemas = `
+  "AppSchema": {
+    "type": "object",
+    "properties": {
+      "version": {"type": "integer"},
+      "models": {"type": "array", "items": {"$ref": "#/components/schemas/Model"}}
+    },
+    "required": ["version", "models"]
+  },
+  "Model": {
+    "type": "object",
+    "properties": {
+      "name": {"type": "string"},
+      "id": {"$ref": "#/components/schemas/IDConfig"},
+      "fields": {"type": "array", "items": {"type": "string"}, "description": "Optional; used for result shape inference"},
+      "relations": {"type": "array", "items": {"$ref": "#/components/schemas/Relation"}},
+      "soft_delete": {"type": "string", "description": "Field marking rows soft-deleted while non-null"}
+    },
+    "required": ["name", "id"]
+  },
+  "IDConfig": {
+    "type": "object",
+    "properties": {
+      "kind": {"type": "string"},
+      "fields": {"type": "array", "items": {"type": "string"}, "description": "Primary key fields in key order; omitted means \"id\""}
+    },
+    "required": ["kind"]
+  },
+  "Relation": {
+    "type": "object",
+    "properties": {
+      "name": {"type": "string"},
+      "target": {"type": "string"},
+      "kind": {"enum": ["one", "many"]},
+      "fields": {"type": "array", "items": {"type": "string"}, "description": "Join fields on the owning model; omitted for the conventional keys"},
+      "references": {"type": "array", "items": {"type": "string"}, "description": "Matching fields on the target"}
+    },
+    "required": ["name", "target", "kind"]
+  },
+  "AddQueryRequest": {
+    "type": "object",
+    "properties": {
+      "shape": {"$ref": "#/components/schemas/Statement"},
+      "result_hint": {"type": "object", "additionalProperties": {"type": "array"}, "description": "Result rows by model, root rows in page order"},
+      "ttl": {"type": "integer", "minimum": 0, "description": "Seconds until the shape expires"},
+      "valid_until": {"type": "string", "format": "date-time", "description": "RFC 3339 time the shape expires at; the earlier of ttl and valid_until wins"}
+    },
+    "required": ["shape"]
+  },
+  "AddQueryResponse": {
+    "type": "object",
+    "properties": {
+      "shape_id": {"type": "string", "pattern": "^s_[0-9a-f]{64}$"},
+      "dependencies": {"$ref": "#/components/schemas/Dependencies"}
+    },
+    "required": ["shape_id", "dependencies"]
+  },
+  "ShapeIDResponse": {
+    "type": "object",
+    "properties": {
+      "shape_id": {"type": "string", "pattern": "^s_[0-9a-f]{64}$"}
+    },
+    "required": ["shape_id"]
+  },
+  "InvalidateResponse": {
+    "type": "object",
+    "properties": {
+      "evict": {"type": "array", "items": {"type": "string"}, "description": "Sorted shape IDs to evict"}
+    },
+    "required": ["evict"]
+  },
+  "ExplainRequest": {
+    "type": "object",
+    "properties": {
+      "mutation": {"$ref": "#/components/schemas/Mutation"},
+      "shape_id": {"type": "string"}
+    },
+    "required": ["mutation", "shape_id"]
+  },
+  "ExplainResponse": {
+    "type": "object",
+    "properties": {
+      "invalidate": {"type": "boolean"},
+      "reasons": {"type": "array", "items": {"type": "string"}, "description": "Distinct reason codes"},
+      "details": {"type": "array", "items": {"$ref": "#/components/schemas/ExplainReason"}, "description": "One entry for each change and reason, in change order"}
+    },
+    "required": ["invalidate", "reasons", "details"]
+  },
+  "ExplainReason": {
+    "type": "object",
+    "properties": {
+      "change_index": {"type": "integer"},
+      "kind": {"enum": ["record_membership", "filter_dependency", "relation_dependency", "pagination_boundary", "group_by"]},
+      "model": {"type": "string"},
+      "filter_path": {"type": "string", "description": "The dependency that matched, e.g. filters[0] or last_row"},
+      "matched_record_ids": {"type": "array", "items": {"type": "string"}}
+    },
+    "required": ["change_index", "kind", "model"]
+  },
+  "VersionInfo": {
+    "type": "object",
+    "properties": {
+      "core": {"type": "string"},
+      "contract": {"type": "string"},
+      "abi": {"type": "string"}
+    },
+    "required": ["core", "contract", "abi"]
+  },
+  "Error": {
+    "type": "object",
+    "properties": {
+      "error": {"type": "string"},
+      "code": {"type": "string", "description": "Validator error code, e.g. IK1001_EMPTY_MODEL"}
+    },
+    "required": ["error"]
+  }`
+
+// openAPIResponses are the error responses shared by the routes.
+const openAPIResponses = `{
+  "BadRequest": {"description": "The body does not decode, is over a nesting or size limit, or the engine rejects it", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+  "TooLarge": {"description": "The body exceeds the size limit", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}},
+  "Unavailable": {"description": "A fault was injected into the mock engine", "content": {"application/json": {"schema": {"$ref": "#/components/schemas/Error"}}}}
+}`
@@ -0,0 +1,540 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// protoPackage is the proto package of the generated file, and
+// protoGoPackage the Go package protoc-gen-go writes it to unless
+// overridden with --go_opt=M.
+const (
+	protoPackage   = "includekit.v0"
+	protoGoPackage = "github.com/bold-minds/includekit-spec/proto/includekit/v0;includekitv0"
+)
+
+// WriteProto generates engine.proto: a message for every schema
+// definition, messages for the engine API and an Engine service with one
+// RPC per method of the Go mock's Engine interface.
+//
+// Field names are the wire names, so protojson with UseProtoNames reads and
+// writes the JSON format. Field numbers follow schema property order, which
+// keeps them stable as long as new properties are appended. Integers map to
+// int32 because protojson writes int64 as JSON strings; values of any type
+// map to google.protobuf.Value, and maps with array values to ListValue.
+//
+// Filter keeps and, or, not and conditions as separate fields rather than a
+// oneof, since a filter may combine them. proto3 cannot tell an empty list
+// or map from an absent one, so protojson omits empty ones: a reader must
+// treat an absent required list, such as Dependencies.includes, as empty.
+func WriteProto(dir string, s *parser.Schema) error {
+	gen := &protoMessages{schema: s}
+	for _, name := range s.Keys("/$defs", s.Definitions) {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("definition %s is not an object", name)
+		}
+		if err := gen.message(name, "/$defs/"+name, def); err != nil {
+			return err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `// IncludeKit Universal Format v%s
+// Auto-generated from schema/%s
+// DO NOT EDIT - This file is automatically generated
+
+syntax = "proto3";
+
+package %s;
+
+import "google/protobuf/empty.proto";
+import "google/protobuf/struct.proto";
+
+option go_package = %q;
+`, s.Version, filepath.Base(s.Path), protoPackage, protoGoPackage)
+
+	b.WriteString("\n// Universal format\n")
+	for _, msg := range gen.messages {
+		b.WriteString("\n")
+		b.WriteString(msg)
+	}
+	b.WriteString("\n// Engine API (engine-specific, not in the universal format)\n")
+	b.WriteString(protoEngine)
+
+	return os.WriteFile(filepath.Join(dir, "engine.proto"), []byte(b.String()), 0644)
+}
+
+// WriteProtoServer generates engine_server.go, a gRPC server adapter that
+// serves any mock.Engine. It compiles alongside the protoc-gen-go and
+// protoc-gen-go-grpc output for engine.proto.
+func WriteProtoServer(dir string, s *parser.Schema) error {
+	return os.WriteFile(filepath.Join(dir, "engine_server.go"), []byte(protoServer), 0644)
+}
+
+type protoMessages struct {
+	schema   *parser.Schema
+	messages []string
+}
+
+// message renders a definition as a message. Nested object definitions are
+// rendered first, as <Message><Property> messages.
+func (g *protoMessages) message(name, pointer string, def map[string]interface{}) error {
+	props, _ := def["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := def["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var b strings.Builder
+	if desc, ok := def["description"].(string); ok {
+		b.WriteString(protoComment("", desc))
+	}
+	fmt.Fprintf(&b, "message %s {\n", name)
+
+	propsPointer := pointer + "/properties"
+	for i, wire := range g.schema.Keys(propsPointer, props) {
+		prop, _ := props[wire].(map[string]interface{})
+		typ, note, err := g.fieldType(name+pascalCase(wire), propsPointer+"/"+wire, prop)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, wire, err)
+		}
+
+		if desc, ok := prop["description"].(string); ok {
+			b.WriteString(protoComment("  ", desc))
+		}
+		// scalars need presence to tell an absent field from its zero value
+		if protoScalars[typ] && !required[wire] {
+			typ = "optional " + typ
+		}
+		field := fmt.Sprintf("  %s %s = %d", typ, wire, i+1)
+		if deprecated, _ := prop["deprecated"].(bool); deprecated {
+			field += " [deprecated = true]"
+		}
+		field += ";"
+		if note != "" {
+			field += " // " + note
+		}
+		b.WriteString(field + "\n")
+	}
+	b.WriteString("}\n")
+
+	g.messages = append(g.messages, b.String())
+	return nil
+}
+
+var protoScalars = map[string]bool{"string": true, "int32": true, "double": true, "bool": true}
+
+// fieldType returns the proto type of a property, and a trailing comment
+// for what the type cannot express, such as enum values.
+func (g *protoMessages) fieldType(nestedName, pointer string, prop map[string]interface{}) (string, string, error) {
+	if ref, ok := prop["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/"), "", nil
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		return "string", protoEnum(enum), nil
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		variants, ok := prop[key].([]interface{})
+		if !ok {
+			continue
+		}
+		// variants that are all strings (an enum or a pattern) stay a string
+		var notes []string
+		for _, v := range variants {
+			variant, _ := v.(map[string]interface{})
+			if enum, ok := variant["enum"].([]interface{}); ok {
+				notes = append(notes, protoEnum(enum))
+			} else if variant["type"] == "string" {
+				if p, ok := variant["pattern"].(string); ok {
+					notes = append(notes, p)
+				}
+			} else {
+				return "google.protobuf.Value", "", nil
+			}
+		}
+		return "string", strings.Join(notes, " | "), nil
+	}
+
+	switch prop["type"] {
+	case nil:
+		return "google.protobuf.Value", "", nil
+	case "string":
+		return "string", "", nil
+	case "integer":
+		return "int32", "", nil
+	case "number":
+		return "double", "", nil
+	case "boolean":
+		return "bool", "", nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		typ, note, err := g.elementType(nestedName, pointer+"/items", items)
+		if err != nil {
+			return "", "", err
+		}
+		return "repeated " + typ, note, nil
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			return nestedName, "", g.message(nestedName, pointer, prop)
+		}
+		values, ok := prop["additionalProperties"].(map[string]interface{})
+		if !ok || len(values) == 0 {
+			return "google.protobuf.Struct", "", nil
+		}
+		typ, note, err := g.elementType(nestedName, pointer+"/additionalProperties", values)
+		if err != nil {
+			return "", "", err
+		}
+		return "map<string, " + typ + ">", note, nil
+	}
+	return "", "", fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// elementType is fieldType for array items and map values, which cannot be
+// repeated themselves.
+func (g *protoMessages) elementType(nestedName, pointer string, prop map[string]interface{}) (string, string, error) {
+	if prop == nil {
+		return "google.protobuf.Value", "", nil
+	}
+	typ, note, err := g.fieldType(nestedName, pointer, prop)
+	if err != nil {
+		return "", "", err
+	}
+	if strings.HasPrefix(typ, "repeated ") {
+		return "google.protobuf.ListValue", "", nil
+	}
+	if strings.HasPrefix(typ, "map<") {
+		return "google.protobuf.Struct", "", nil
+	}
+	return typ, note, nil
+}
+
+func protoEnum(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+	}
+	return strings.Join(values, " | ")
+}
+
+func protoComment(indent, s string) string {
+	return indent + "// " + strings.ReplaceAll(s, "\n", "\n"+indent+"// ") + "\n"
+}
+
+// protoEngine mirrors the request and response types of the Go mock's
+// Engine interface (pkgs/go/tests/mock/interface.go).
+const protoEngine = `
+service EngineService {
+  rpc SetSchema(AppSchema) returns (google.protobuf.Empty);
+  rpc ComputeShapeID(Statement) returns (ShapeIDResponse);
+  rpc AddQuery(AddQueryRequest) returns (AddQueryResponse);
+  rpc Invalidate(Mutation) returns (InvalidateResponse);
+  rpc InvalidateTransaction(Transaction) returns (InvalidateResponse);
+  rpc EvictExpired(google.protobuf.Empty) returns (InvalidateResponse);
+  rpc BeginTx(TxRequest) returns (google.protobuf.Empty);
+  rpc StageChange(StageChangeRequest) returns (google.protobuf.Empty);
+  rpc CommitTx(TxRequest) returns (InvalidateResponse);
+  rpc RollbackTx(TxRequest) returns (google.protobuf.Empty);
+  rpc ExplainInvalidation(ExplainRequest) returns (ExplainResponse);
+  rpc Reset(google.protobuf.Empty) returns (google.protobuf.Empty);
+  rpc GetVersion(google.protobuf.Empty) returns (VersionInfo);
+}
+
+message AppSchema {
+  int32 version = 1;
+  repeated Model models = 2;
+}
+
+message Model {
+  string name = 1;
+  IDConfig id = 2;
+  // Optional; used for result shape inference
+  repeated string fields = 3;
+  repeated Relation relations = 4;
+  // Field marking rows soft-deleted while non-null
+  optional string soft_delete = 5;
+}
+
+message IDConfig {
+  string kind = 1;
+  // Primary key fields in key order; omitted means "id"
+  repeated string fields = 2;
+}
+
+message Relation {
+  string name = 1;
+  string target = 2;
+  string kind = 3; // "one" | "many"
+  // Join fields on the owning model; omitted for the conventional keys
+  repeated string fields = 4;
+  // Matching fields on the target
+  repeated string references = 5;
+}
+
+message AddQueryRequest {
+  Statement shape = 1;
+  // Result rows by model
+  map<string, google.protobuf.ListValue> result_hint = 2;
+  // Seconds until the shape expires
+  optional int32 ttl = 3;
+  // RFC 3339 time the shape expires at; the earlier of ttl and valid_until wins
+  optional string valid_until = 4;
+}
+
+message AddQueryResponse {
+  string shape_id = 1;
+  Dependencies dependencies = 2;
+}
+
+message ShapeIDResponse {
+  string shape_id = 1;
+}
+
+message InvalidateResponse {
+  repeated string evict = 1;
+}
+
+message TxRequest {
+  string tx_id = 1;
+}
+
+message StageChangeRequest {
+  string tx_id = 1;
+  Change change = 2;
+}
+
+message ExplainRequest {
+  Mutation mutation = 1;
+  string shape_id = 2;
+}
+
+message ExplainResponse {
+  bool invalidate = 1;
+  repeated string reasons = 2;
+  repeated ExplainReason details = 3;
+}
+
+message ExplainReason {
+  int32 change_index = 1;
+  string kind = 2; // "record_membership" | "filter_dependency" | "relation_dependency" | "pagination_boundary" | "group_by"
+  string model = 3;
+  // Dependency that matched, e.g. "filters[0]", "includes[1]" or "last_row"
+  string filter_path = 4;
+  repeated string matched_record_ids = 5;
+}
+
+message VersionInfo {
+  string core = 1;
+  string contract = 2;
+  string abi = 3;
+}
+`
+
+// protoServer bridges messages to the mock types through the JSON format,
+// which both sides already agree on, instead of copying field by field.
+const protoServer = `// Code generated by includekit codegen. DO NOT EDIT.
+
+package includekitv0
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// EngineServer serves an Engine over gRPC.
+//
+// Engine errors are returned as InvalidArgument, except faults injected by
+// the mock, which are Unavailable so clients exercise their retry paths.
+type EngineServer struct {
+	UnimplementedEngineServiceServer
+	Engine mock.Engine
+}
+
+// NewEngineServer returns an EngineServer for engine.
+func NewEngineServer(engine mock.Engine) *EngineServer {
+	return &EngineServer{Engine: engine}
+}
+
+// Register registers an EngineServer for engine with s.
+func Register(s grpc.ServiceRegistrar, engine mock.Engine) {
+	RegisterEngineServiceServer(s, NewEngineServer(engine))
+}
+
+func (s *EngineServer) SetSchema(ctx context.Context, req *AppSchema) (*emptypb.Empty, error) {
+	var schema mock.AppSchema
+	if err := fromProto(req, &schema); err != nil {
+		return nil, err
+	}
+	if err := s.Engine.SetSchema(schema); err != nil {
+		return nil, engineError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *EngineServer) ComputeShapeID(ctx context.Context, req *Statement) (*ShapeIDResponse, error) {
+	var stmt types.Statement
+	if err := fromProto(req, &stmt); err != nil {
+		return nil, err
+	}
+	resp, err := s.Engine.ComputeShapeID(stmt)
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &ShapeIDResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) AddQuery(ctx context.Context, req *AddQueryRequest) (*AddQueryResponse, error) {
+	var request mock.AddQueryRequest
+	if err := fromProto(req, &request); err != nil {
+		return nil, err
+	}
+	resp, err := s.Engine.AddQuery(request)
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &AddQueryResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) Invalidate(ctx context.Context, req *Mutation) (*InvalidateResponse, error) {
+	var mutation types.Mutation
+	if err := fromProto(req, &mutation); err != nil {
+		return nil, err
+	}
+	resp, err := s.Engine.Invalidate(mutation)
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &InvalidateResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) InvalidateTransaction(ctx context.Context, req *Transaction) (*InvalidateResponse, error) {
+	var tx types.Transaction
+	if err := fromProto(req, &tx); err != nil {
+		return nil, err
+	}
+	resp, err := s.Engine.InvalidateTransaction(tx)
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &InvalidateResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) EvictExpired(ctx context.Context, req *emptypb.Empty) (*InvalidateResponse, error) {
+	resp, err := s.Engine.EvictExpired()
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &InvalidateResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) BeginTx(ctx context.Context, req *TxRequest) (*emptypb.Empty, error) {
+	if err := s.Engine.BeginTx(req.GetTxId()); err != nil {
+		return nil, engineError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *EngineServer) StageChange(ctx context.Context, req *StageChangeRequest) (*emptypb.Empty, error) {
+	var change types.Change
+	if err := fromProto(req.GetChange(), &change); err != nil {
+		return nil, err
+	}
+	if err := s.Engine.StageChange(req.GetTxId(), change); err != nil {
+		return nil, engineError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *EngineServer) CommitTx(ctx context.Context, req *TxRequest) (*InvalidateResponse, error) {
+	resp, err := s.Engine.CommitTx(req.GetTxId())
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &InvalidateResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) RollbackTx(ctx context.Context, req *TxRequest) (*emptypb.Empty, error) {
+	if err := s.Engine.RollbackTx(req.GetTxId()); err != nil {
+		return nil, engineError(err)
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *EngineServer) ExplainInvalidation(ctx context.Context, req *ExplainRequest) (*ExplainResponse, error) {
+	var request mock.ExplainRequest
+	if err := fromProto(req, &request); err != nil {
+		return nil, err
+	}
+	resp, err := s.Engine.ExplainInvalidation(request)
+	if err != nil {
+		return nil, engineError(err)
+	}
+	out := &ExplainResponse{}
+	return out, toProto(resp, out)
+}
+
+func (s *EngineServer) Reset(ctx context.Context, req *emptypb.Empty) (*emptypb.Empty, error) {
+	s.Engine.Reset()
+	return &emptypb.Empty{}, nil
+}
+
+func (s *EngineServer) GetVersion(ctx context.Context, req *emptypb.Empty) (*VersionInfo, error) {
+	out := &VersionInfo{}
+	return out, toProto(s.Engine.GetVersion(), out)
+}
+
+// fromProto decodes m into v through the JSON format.
+func fromProto(m proto.Message, v any) error {
+	data, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(m)
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "includekit: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return status.Errorf(codes.InvalidArgument, "includekit: %v", err)
+	}
+	return nil
+}
+
+// toProto encodes v into m through the JSON format.
+func toProto(v any, m proto.Message) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return status.Errorf(codes.Internal, "includekit: %v", err)
+	}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, m); err != nil {
+		return status.Errorf(codes.Internal, "includekit: %v", err)
+	}
+	return nil
+}
+
+func engineError(err error) error {
+	if errors.Is(err, mock.ErrInjected) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.InvalidArgument, err.Error())
+}
+`
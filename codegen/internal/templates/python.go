@@ -0,0 +1,334 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// WritePythonModels generates Pydantic v2 models for every schema
+// definition. Attributes are snake_case Python names with an alias for the
+// wire name, so models validate from and dump to the JSON format with
+// model_dump(by_alias=True, exclude_none=True).
+func WritePythonModels(dir string, s *parser.Schema) error {
+	gen, err := newPythonModels(s)
+	if err != nil {
+		return err
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `"""
+IncludeKit Universal Format v%s
+Auto-generated from schema/%s
+DO NOT EDIT - This file is automatically generated
+"""
+
+from __future__ import annotations
+
+from typing import Annotated, Any, Dict, List, Literal, Optional, Union
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class SpecModel(BaseModel):
+    """Base for all models: unknown fields are rejected, and fields can be
+    populated by attribute or wire name."""
+
+    model_config = ConfigDict(extra="forbid", populate_by_name=True)
+`, s.Version, filepath.Base(s.Path))
+
+	for _, class := range gen.classes {
+		b.WriteString("\n\n")
+		b.WriteString(class)
+	}
+
+	b.WriteString("\n\n")
+	for _, name := range gen.names {
+		fmt.Fprintf(&b, "%s.model_rebuild()\n", name)
+	}
+
+	return os.WriteFile(filepath.Join(dir, "models.py"), []byte(b.String()), 0644)
+}
+
+// WritePythonInit generates the package __init__.py re-exporting the models.
+func WritePythonInit(dir string, s *parser.Schema) error {
+	gen, err := newPythonModels(s)
+	if err != nil {
+		return err
+	}
+	names := append([]string{"SpecModel"}, gen.names...)
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `"""IncludeKit Universal Format models for Python."""
+
+from .models import (
+`)
+	for _, name := range names {
+		fmt.Fprintf(&b, "    %s,\n", name)
+	}
+	b.WriteString(")\nfrom .version import CONTRACT_VERSION, SCHEMA_ID, SPEC_VERSION\n\n__all__ = [\n    \"CONTRACT_VERSION\",\n    \"SCHEMA_ID\",\n    \"SPEC_VERSION\",\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "    %q,\n", name)
+	}
+	b.WriteString("]\n")
+
+	if err := os.WriteFile(filepath.Join(dir, "__init__.py"), []byte(b.String()), 0644); err != nil {
+		return err
+	}
+	// PEP 561 marker so type checkers use the inline annotations
+	return os.WriteFile(filepath.Join(dir, "py.typed"), nil, 0644)
+}
+
+// WritePythonProject generates the pyproject.toml skeleton.
+func WritePythonProject(dir string, s *parser.Schema) error {
+	content := fmt.Sprintf(`[build-system]
+requires = ["hatchling"]
+build-backend = "hatchling.build"
+
+[project]
+name = %q
+version = %q
+description = "IncludeKit Universal Format models"
+requires-python = ">=3.9"
+license = "MIT"
+dependencies = ["pydantic>=2.7,<3"]
+
+[project.urls]
+Repository = "https://github.com/bold-minds/includekit-spec"
+
+[tool.hatch.build.targets.wheel]
+packages = [%q]
+`, Project(s, "includekit-spec"), s.SpecVersion(), PythonPackage(s))
+
+	return os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte(content), 0644)
+}
+
+type pythonModels struct {
+	schema  *parser.Schema
+	classes []string
+	names   []string
+}
+
+func newPythonModels(s *parser.Schema) (*pythonModels, error) {
+	gen := &pythonModels{schema: s}
+	for _, name := range s.Keys("/$defs", s.Definitions) {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("definition %s is not an object", name)
+		}
+		if err := gen.class(name, "/$defs/"+name, def); err != nil {
+			return nil, err
+		}
+	}
+	return gen, nil
+}
+
+// class renders a definition as a model class. Nested object definitions
+// are rendered first, as <Class><Property> classes.
+func (g *pythonModels) class(name, pointer string, def map[string]interface{}) error {
+	props, _ := def["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := def["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "class %s(SpecModel):\n", name)
+	if desc, ok := def["description"].(string); ok {
+		fmt.Fprintf(&b, "    %s\n\n", pythonDocstring(desc))
+	}
+	if len(props) == 0 {
+		b.WriteString("    pass\n")
+	}
+
+	propsPointer := pointer + "/properties"
+	for _, wire := range g.schema.Keys(propsPointer, props) {
+		prop, _ := props[wire].(map[string]interface{})
+		typ, args, err := g.fieldType(name+pascalCase(wire), propsPointer+"/"+wire, prop)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, wire, err)
+		}
+
+		fieldArgs := []string{fmt.Sprintf("alias=%q", wire)}
+		if !required[wire] {
+			typ = "Optional[" + typ + "]"
+			fieldArgs = append([]string{"default=None"}, fieldArgs...)
+		}
+		fieldArgs = append(fieldArgs, args...)
+		if desc, ok := prop["description"].(string); ok {
+			fieldArgs = append(fieldArgs, "description="+strconv.Quote(desc))
+		}
+		if deprecated, _ := prop["deprecated"].(bool); deprecated {
+			fieldArgs = append(fieldArgs, "deprecated=True")
+		}
+		fmt.Fprintf(&b, "    %s: %s = Field(%s)\n", pythonName(wire), typ, strings.Join(fieldArgs, ", "))
+	}
+
+	g.classes = append(g.classes, b.String())
+	g.names = append(g.names, name)
+	return nil
+}
+
+// fieldType returns the Python type of a property and the Field arguments
+// for its constraints.
+func (g *pythonModels) fieldType(nestedName, pointer string, prop map[string]interface{}) (string, []string, error) {
+	if ref, ok := prop["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/"), nil, nil
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		return pythonLiteral(enum), nil, nil
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants, ok := prop[key].([]interface{}); ok {
+			types := make([]string, len(variants))
+			for i, v := range variants {
+				variant, _ := v.(map[string]interface{})
+				typ, err := g.nestedType(nestedName, fmt.Sprintf("%s/%s/%d", pointer, key, i), variant)
+				if err != nil {
+					return "", nil, err
+				}
+				types[i] = typ
+			}
+			return "Union[" + strings.Join(types, ", ") + "]", nil, nil
+		}
+	}
+
+	switch prop["type"] {
+	case nil:
+		return "Any", nil, nil
+	case "string":
+		var args []string
+		if n, ok := prop["minLength"].(float64); ok {
+			args = append(args, fmt.Sprintf("min_length=%d", int(n)))
+		}
+		if n, ok := prop["maxLength"].(float64); ok {
+			args = append(args, fmt.Sprintf("max_length=%d", int(n)))
+		}
+		if p, ok := prop["pattern"].(string); ok {
+			args = append(args, "pattern="+strconv.Quote(p))
+		}
+		return "str", args, nil
+	case "integer", "number":
+		typ := "int"
+		if prop["type"] == "number" {
+			typ = "float"
+		}
+		var args []string
+		if n, ok := prop["minimum"].(float64); ok {
+			args = append(args, "ge="+strconv.FormatFloat(n, 'f', -1, 64))
+		}
+		if n, ok := prop["maximum"].(float64); ok {
+			args = append(args, "le="+strconv.FormatFloat(n, 'f', -1, 64))
+		}
+		return typ, args, nil
+	case "boolean":
+		return "bool", nil, nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		typ, err := g.nestedType(nestedName, pointer+"/items", items)
+		if err != nil {
+			return "", nil, err
+		}
+		return "List[" + typ + "]", nil, nil
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			return nestedName, nil, g.class(nestedName, pointer, prop)
+		}
+		values, ok := prop["additionalProperties"].(map[string]interface{})
+		if !ok {
+			return "Dict[str, Any]", nil, nil
+		}
+		typ, err := g.nestedType(nestedName, pointer+"/additionalProperties", values)
+		if err != nil {
+			return "", nil, err
+		}
+		return "Dict[str, " + typ + "]", nil, nil
+	}
+	return "", nil, fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// nestedType is fieldType for types inside another type, where constraints
+// must be attached with Annotated.
+func (g *pythonModels) nestedType(nestedName, pointer string, prop map[string]interface{}) (string, error) {
+	if prop == nil {
+		return "Any", nil
+	}
+	typ, args, err := g.fieldType(nestedName, pointer, prop)
+	if err != nil || len(args) == 0 {
+		return typ, err
+	}
+	return fmt.Sprintf("Annotated[%s, Field(%s)]", typ, strings.Join(args, ", ")), nil
+}
+
+func pythonLiteral(enum []interface{}) string {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		values[i] = strconv.Quote(fmt.Sprint(v))
+	}
+	return "Literal[" + strings.Join(values, ", ") + "]"
+}
+
+func pythonDocstring(s string) string {
+	return `"""` + strings.ReplaceAll(s, `"""`, `\"\"\"`) + `"""`
+}
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true,
+	"def": true, "del": true, "elif": true, "else": true, "except": true,
+	"finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true,
+	"not": true, "or": true, "pass": true, "raise": true, "return": true,
+	"try": true, "while": true, "with": true, "yield": true,
+}
+
+// pythonName converts a wire name to a snake_case attribute name, adding a
+// trailing underscore to keywords (PEP 8).
+func pythonName(wire string) string {
+	name := snakeCase(wire)
+	if pythonKeywords[name] {
+		name += "_"
+	}
+	return name
+}
+
+// snakeCase converts a camelCase wire name to snake_case.
+func snakeCase(wire string) string {
+	var b strings.Builder
+	for i, r := range wire {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+func pascalCase(s string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range s {
+		if r == '_' || r == '-' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
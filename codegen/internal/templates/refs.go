@@ -0,0 +1,43 @@
+package templates
+
+import "strings"
+
+// inlineRefs maps each definition to the definitions its properties refer
+// to directly, not through arrays or dictionaries.
+func inlineRefs(defs map[string]map[string]interface{}) map[string][]string {
+	refs := map[string][]string{}
+	for name, def := range defs {
+		props, _ := def["properties"].(map[string]interface{})
+		for _, p := range props {
+			prop, _ := p.(map[string]interface{})
+			if ref, ok := prop["$ref"].(string); ok {
+				refs[name] = append(refs[name], strings.TrimPrefix(ref, "#/$defs/"))
+			}
+		}
+	}
+	return refs
+}
+
+// reachesInline reports whether a value of definition from can contain a
+// value of definition to inline, following refs. Languages that store
+// structs by value must box such properties, as in Filter.not.
+func reachesInline(refs map[string][]string, from, to string) bool {
+	seen := map[string]bool{}
+	var visit func(name string) bool
+	visit = func(name string) bool {
+		if name == to {
+			return true
+		}
+		if seen[name] {
+			return false
+		}
+		seen[name] = true
+		for _, ref := range refs[name] {
+			if visit(ref) {
+				return true
+			}
+		}
+		return false
+	}
+	return visit(from)
+}
@@ -0,0 +1,395 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// rustTypeNames renames generated types whose <Struct><Property> name
+// reads poorly.
+var rustTypeNames = map[string]string{
+	"ConditionOp": "Operator",
+}
+
+// WriteRustModels generates serde structs for every schema definition as
+// lib.rs. Fields are the snake_case wire names, optional fields are Options
+// skipped when None, and structs reject unknown fields when the schema
+// does. Untyped properties are serde_json::Value, maps are BTreeMaps so
+// they serialize in key order, and date-times are strings.
+//
+// A union of a string enum and a "^<prefix>.+$" pattern, as Condition.op
+// is, becomes an enum with a variant holding the name after the prefix,
+// e.g. Operator::Custom("geo".into()) for "custom:geo".
+func WriteRustModels(dir string, s *parser.Schema) error {
+	gen := &rustModels{schema: s}
+	names := s.Keys("/$defs", s.Definitions)
+	defs := make(map[string]map[string]interface{}, len(names))
+	for _, name := range names {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("definition %s is not an object", name)
+		}
+		defs[name] = def
+	}
+	gen.refs = inlineRefs(defs)
+	for _, name := range names {
+		if err := gen.structType(name, "/$defs/"+name, defs[name]); err != nil {
+			return err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `//! IncludeKit Universal Format v%s
+//!
+//! Auto-generated from schema/%s
+//! DO NOT EDIT - This file is automatically generated
+`, s.Version, filepath.Base(s.Path))
+	var std []string
+	if gen.btreeMap {
+		std = append(std, "use std::collections::BTreeMap;\n")
+	}
+	serde := "use serde::{Deserialize, Serialize};\n"
+	if gen.prefixed {
+		std = append(std, "use std::fmt;\n", "use std::str::FromStr;\n")
+		serde = "use serde::{de, Deserialize, Deserializer, Serialize, Serializer};\n"
+	}
+	if len(std) > 0 {
+		b.WriteString("\n" + strings.Join(std, ""))
+	}
+	b.WriteString("\n" + serde)
+	b.WriteString("\nmod version;\n\npub use version::{CONTRACT_VERSION, SCHEMA_ID, SPEC_VERSION};\n")
+	for _, decl := range gen.decls {
+		b.WriteString("\n")
+		b.WriteString(decl)
+	}
+
+	return os.WriteFile(filepath.Join(dir, "lib.rs"), []byte(b.String()), 0644)
+}
+
+// WriteRustCrate generates the Cargo manifest of the models crate.
+func WriteRustCrate(dir string, s *parser.Schema) error {
+	content := fmt.Sprintf(`[package]
+name = %q
+version = %q
+edition = "2021"
+description = "IncludeKit Universal Format types"
+license = "Apache-2.0"
+repository = "https://github.com/bold-minds/includekit-spec"
+
+[dependencies]
+serde = { version = "1", features = ["derive"] }
+serde_json = "1"
+`, Project(s, "includekit-spec"), s.SpecVersion())
+
+	return os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte(content), 0644)
+}
+
+type rustModels struct {
+	schema   *parser.Schema
+	refs     map[string][]string
+	decls    []string
+	btreeMap bool
+	prefixed bool
+}
+
+// structType renders a definition as a struct. Enums and nested object
+// definitions are rendered first, named <Struct><Property>.
+func (g *rustModels) structType(name, pointer string, def map[string]interface{}) error {
+	props, _ := def["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := def["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var fields strings.Builder
+	propsPointer := pointer + "/properties"
+	for _, wire := range g.schema.Keys(propsPointer, props) {
+		prop, _ := props[wire].(map[string]interface{})
+		typ, err := g.fieldType(name+pascalCase(wire), propsPointer+"/"+wire, prop)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, wire, err)
+		}
+		if ref, ok := prop["$ref"].(string); ok && reachesInline(g.refs, strings.TrimPrefix(ref, "#/$defs/"), name) {
+			typ = "Box<" + typ + ">"
+		}
+
+		if desc, ok := prop["description"].(string); ok {
+			fields.WriteString(rustDoc("    ", desc))
+		}
+		if deprecated, _ := prop["deprecated"].(bool); deprecated {
+			fields.WriteString("    ///\n    /// Deprecated in the schema.\n")
+		}
+		field := rustFieldName(wire)
+		if strings.TrimPrefix(field, "r#") != wire {
+			fmt.Fprintf(&fields, "    #[serde(rename = %q)]\n", wire)
+		}
+		if !required[wire] {
+			typ = "Option<" + typ + ">"
+			fields.WriteString("    #[serde(skip_serializing_if = \"Option::is_none\")]\n")
+		}
+		fmt.Fprintf(&fields, "    pub %s: %s,\n", field, typ)
+	}
+
+	var b strings.Builder
+	if desc, ok := def["description"].(string); ok {
+		b.WriteString(rustDoc("", desc))
+	}
+	// Structs with only optional fields can be built with ..Default::default()
+	if len(required) == 0 {
+		b.WriteString("#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]\n")
+	} else {
+		b.WriteString("#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]\n")
+	}
+	if def["additionalProperties"] == false {
+		b.WriteString("#[serde(deny_unknown_fields)]\n")
+	}
+	fmt.Fprintf(&b, "pub struct %s {\n%s}\n", name, fields.String())
+
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+// fieldType returns the Rust type of a property, without Option.
+func (g *rustModels) fieldType(nestedName, pointer string, prop map[string]interface{}) (string, error) {
+	if prop == nil {
+		return "serde_json::Value", nil
+	}
+	if ref, ok := prop["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/"), nil
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		name := rustTypeName(nestedName)
+		return name, g.enum(name, enum)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants, ok := prop[key].([]interface{}); ok {
+			if enum, prefix, ok := prefixedEnum(variants); ok {
+				name := rustTypeName(nestedName)
+				return name, g.prefixedEnum(name, enum, prefix)
+			}
+			if stringVariants(variants) {
+				return "String", nil
+			}
+			return "serde_json::Value", nil
+		}
+	}
+
+	switch prop["type"] {
+	case nil:
+		return "serde_json::Value", nil
+	case "string":
+		return "String", nil
+	case "integer":
+		if n, ok := prop["minimum"].(float64); ok && n >= 0 {
+			return "u64", nil
+		}
+		return "i64", nil
+	case "number":
+		return "f64", nil
+	case "boolean":
+		return "bool", nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		typ, err := g.fieldType(nestedName, pointer+"/items", items)
+		if err != nil {
+			return "", err
+		}
+		return "Vec<" + typ + ">", nil
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			return nestedName, g.structType(nestedName, pointer, prop)
+		}
+		values, _ := prop["additionalProperties"].(map[string]interface{})
+		typ, err := g.fieldType(nestedName, pointer+"/additionalProperties", values)
+		if err != nil {
+			return "", err
+		}
+		g.btreeMap = true
+		return "BTreeMap<String, " + typ + ">", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// enum renders a string enum with a serde rename per variant.
+func (g *rustModels) enum(name string, enum []interface{}) error {
+	values, err := stringEnum(enum)
+	if err != nil {
+		return err
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]\npub enum %s {\n", name)
+	for _, v := range values {
+		fmt.Fprintf(&b, "    #[serde(rename = %q)]\n    %s,\n", v, rustVariant(v))
+	}
+	b.WriteString("}\n")
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+// prefixedEnum renders an enum whose values are the enum values plus any
+// name after prefix. It converts to and from its wire string with Display
+// and FromStr, which serde uses.
+func (g *rustModels) prefixedEnum(name string, enum []interface{}, prefix string) error {
+	values, err := stringEnum(enum)
+	if err != nil {
+		return err
+	}
+	g.prefixed = true
+	variant := rustVariant(strings.TrimRight(prefix, ":-_."))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "/// One of the values below, or %s<name>\n", prefix)
+	fmt.Fprintf(&b, "#[derive(Debug, Clone, PartialEq, Eq, Hash)]\npub enum %s {\n", name)
+	for _, v := range values {
+		fmt.Fprintf(&b, "    %s,\n", rustVariant(v))
+	}
+	fmt.Fprintf(&b, "    /// %s<name>, holding the name\n    %s(String),\n}\n", prefix, variant)
+
+	fmt.Fprintf(&b, "\nimpl %s {\n    /// The values other than %s<name>\n    pub const ALL: &'static [%s] = &[\n", name, prefix, name)
+	for _, v := range values {
+		fmt.Fprintf(&b, "        %s::%s,\n", name, rustVariant(v))
+	}
+	b.WriteString("    ];\n}\n")
+
+	fmt.Fprintf(&b, "\nimpl fmt::Display for %s {\n    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {\n        match self {\n", name)
+	for _, v := range values {
+		fmt.Fprintf(&b, "            %s::%s => f.write_str(%q),\n", name, rustVariant(v), v)
+	}
+	fmt.Fprintf(&b, "            %s::%s(name) => write!(f, \"%s{}\", name),\n        }\n    }\n}\n", name, variant, prefix)
+
+	errName := "Parse" + name + "Error"
+	fmt.Fprintf(&b, `
+/// Error returned when a string is not a valid %[1]s
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct %[2]s(pub String);
+
+impl fmt::Display for %[2]s {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "unknown %[3]s {:?}", self.0)
+    }
+}
+
+impl std::error::Error for %[2]s {}
+
+impl FromStr for %[1]s {
+    type Err = %[2]s;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        Ok(match s {
+`, name, errName, strings.ToLower(name))
+	for _, v := range values {
+		fmt.Fprintf(&b, "            %q => %s::%s,\n", v, name, rustVariant(v))
+	}
+	fmt.Fprintf(&b, `            _ => match s.strip_prefix(%[3]q) {
+                Some(name) if !name.is_empty() => %[1]s::%[4]s(name.to_string()),
+                _ => return Err(%[2]s(s.to_string())),
+            },
+        })
+    }
+}
+
+impl Serialize for %[1]s {
+    fn serialize<S: Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+        serializer.collect_str(self)
+    }
+}
+
+impl<'de> Deserialize<'de> for %[1]s {
+    fn deserialize<D: Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+        let s = String::deserialize(deserializer)?;
+        s.parse().map_err(de::Error::custom)
+    }
+}
+`, name, errName, prefix, variant)
+
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+// prefixedEnum reports whether a union is a string enum plus a pattern
+// "^<prefix>.+$", returning the enum and the prefix.
+func prefixedEnum(variants []interface{}) ([]interface{}, string, bool) {
+	if len(variants) != 2 {
+		return nil, "", false
+	}
+	var enum []interface{}
+	var prefix string
+	for _, v := range variants {
+		variant, _ := v.(map[string]interface{})
+		if e, ok := variant["enum"].([]interface{}); ok {
+			enum = e
+			continue
+		}
+		p, _ := variant["pattern"].(string)
+		if variant["type"] != "string" || !strings.HasPrefix(p, "^") || !strings.HasSuffix(p, ".+$") {
+			return nil, "", false
+		}
+		prefix = strings.TrimSuffix(strings.TrimPrefix(p, "^"), ".+$")
+		if prefix == "" || strings.ContainsAny(prefix, `\.*+?()[]{}|^$`) {
+			return nil, "", false
+		}
+	}
+	return enum, prefix, enum != nil && prefix != ""
+}
+
+func stringEnum(enum []interface{}) ([]string, error) {
+	values := make([]string, len(enum))
+	for i, v := range enum {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("enum value %v is not a string", v)
+		}
+		values[i] = s
+	}
+	return values, nil
+}
+
+func rustTypeName(name string) string {
+	if renamed, ok := rustTypeNames[name]; ok {
+		return renamed
+	}
+	return name
+}
+
+// rustVariant converts an enum value such as "notIn" to NotIn.
+func rustVariant(value string) string {
+	p := pascalCase(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(value))
+	if p == "" || unicode.IsDigit(rune(p[0])) {
+		p = "V" + p
+	}
+	return p
+}
+
+var rustKeywords = map[string]bool{
+	"as": true, "async": true, "await": true, "break": true, "const": true,
+	"continue": true, "crate": true, "dyn": true, "else": true, "enum": true,
+	"extern": true, "false": true, "fn": true, "for": true, "if": true,
+	"impl": true, "in": true, "let": true, "loop": true, "match": true,
+	"mod": true, "move": true, "mut": true, "pub": true, "ref": true,
+	"return": true, "static": true, "struct": true, "trait": true, "true": true,
+	"type": true, "unsafe": true, "use": true, "where": true, "while": true,
+	"abstract": true, "become": true, "box": true, "do": true, "final": true,
+	"gen": true, "macro": true, "override": true, "priv": true, "try": true,
+	"typeof": true, "unsized": true, "virtual": true, "yield": true,
+}
+
+// rustFieldName converts a wire name to a snake_case field name, as a raw
+// identifier when it is a keyword.
+func rustFieldName(wire string) string {
+	name := snakeCase(wire)
+	if rustKeywords[name] {
+		return "r#" + name
+	}
+	return name
+}
+
+func rustDoc(indent, s string) string {
+	return indent + "/// " + strings.ReplaceAll(s, "\n", "\n"+indent+"/// ") + "\n"
+}
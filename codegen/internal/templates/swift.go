@@ -0,0 +1,371 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// WriteSwiftModels generates Codable structs for every schema definition.
+// Properties are camelCase Swift names with CodingKeys for the wire names,
+// and optional properties are omitted when nil. Untyped properties are
+// JSONValue and date-times are strings.
+//
+// A struct cannot hold a value of its own type inline, so a property that
+// leads back to its struct without passing through an array or dictionary,
+// such as Filter.not, is stored boxed in an Indirect behind a computed
+// property of the plain type.
+func WriteSwiftModels(dir string, s *parser.Schema) error {
+	gen := &swiftModels{schema: s}
+	names := s.Keys("/$defs", s.Definitions)
+	defs := make(map[string]map[string]interface{}, len(names))
+	for _, name := range names {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("definition %s is not an object", name)
+		}
+		defs[name] = def
+	}
+	gen.refs = inlineRefs(defs)
+	for _, name := range names {
+		if err := gen.structType(name, "/$defs/"+name, defs[name]); err != nil {
+			return err
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `// IncludeKit Universal Format v%s
+// Auto-generated from schema/%s
+// DO NOT EDIT - This file is automatically generated
+`, s.Version, filepath.Base(s.Path))
+	if gen.jsonValue {
+		b.WriteString(swiftJSONValue)
+	}
+	if gen.indirect {
+		b.WriteString(swiftIndirect)
+	}
+	for _, decl := range gen.decls {
+		b.WriteString("\n")
+		b.WriteString(decl)
+	}
+
+	return os.WriteFile(filepath.Join(dir, "Models.swift"), []byte(b.String()), 0644)
+}
+
+// WriteSwiftPackage generates the Swift package manifest of the models
+// library.
+func WriteSwiftPackage(dir string, s *parser.Schema) error {
+	module := SwiftModule(s)
+	content := fmt.Sprintf(`// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: %[1]q,
+    platforms: [.iOS(.v13), .macOS(.v10_15), .tvOS(.v13), .watchOS(.v6)],
+    products: [
+        .library(name: %[1]q, targets: [%[1]q]),
+    ],
+    targets: [
+        .target(name: %[1]q),
+    ]
+)
+`, module)
+	return os.WriteFile(filepath.Join(dir, "Package.swift"), []byte(content), 0644)
+}
+
+const swiftJSONValue = `
+/// Any JSON value, for properties the schema leaves untyped
+public enum JSONValue: Codable, Equatable, Sendable {
+    case null
+    case bool(Bool)
+    case int(Int)
+    case double(Double)
+    case string(String)
+    case array([JSONValue])
+    case object([String: JSONValue])
+
+    public init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+        if container.decodeNil() {
+            self = .null
+        } else if let value = try? container.decode(Bool.self) {
+            self = .bool(value)
+        } else if let value = try? container.decode(Int.self) {
+            self = .int(value)
+        } else if let value = try? container.decode(Double.self) {
+            self = .double(value)
+        } else if let value = try? container.decode(String.self) {
+            self = .string(value)
+        } else if let value = try? container.decode([JSONValue].self) {
+            self = .array(value)
+        } else {
+            self = .object(try container.decode([String: JSONValue].self))
+        }
+    }
+
+    public func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+        switch self {
+        case .null: try container.encodeNil()
+        case .bool(let value): try container.encode(value)
+        case .int(let value): try container.encode(value)
+        case .double(let value): try container.encode(value)
+        case .string(let value): try container.encode(value)
+        case .array(let value): try container.encode(value)
+        case .object(let value): try container.encode(value)
+        }
+    }
+}
+`
+
+const swiftIndirect = `
+/// Boxes a property whose type contains its own struct, which a struct
+/// cannot hold inline. It encodes as the boxed value.
+final class Indirect<Value: Codable & Equatable & Sendable>: Codable, Equatable, Sendable {
+    let value: Value
+
+    init(_ value: Value) {
+        self.value = value
+    }
+
+    init(from decoder: Decoder) throws {
+        value = try Value(from: decoder)
+    }
+
+    func encode(to encoder: Encoder) throws {
+        try value.encode(to: encoder)
+    }
+
+    static func == (lhs: Indirect, rhs: Indirect) -> Bool {
+        lhs.value == rhs.value
+    }
+}
+`
+
+type swiftModels struct {
+	schema    *parser.Schema
+	refs      map[string][]string
+	decls     []string
+	jsonValue bool
+	indirect  bool
+}
+
+type swiftProperty struct {
+	wire, name, typ string
+	required, boxed bool
+}
+
+// structType renders a definition as a struct with a public memberwise
+// initializer. Enums and nested object definitions are rendered first,
+// named <Struct><Property>.
+func (g *swiftModels) structType(name, pointer string, def map[string]interface{}) error {
+	props, _ := def["properties"].(map[string]interface{})
+	required := map[string]bool{}
+	if req, ok := def["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var b strings.Builder
+	if desc, ok := def["description"].(string); ok {
+		b.WriteString(swiftDoc("", desc))
+	}
+	fmt.Fprintf(&b, "public struct %s: Codable, Equatable, Sendable {\n", name)
+
+	var fields []swiftProperty
+	propsPointer := pointer + "/properties"
+	for _, wire := range g.schema.Keys(propsPointer, props) {
+		prop, _ := props[wire].(map[string]interface{})
+		typ, err := g.fieldType(name+pascalCase(wire), propsPointer+"/"+wire, prop)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", name, wire, err)
+		}
+		f := swiftProperty{wire: wire, name: camelCase(wire), typ: typ, required: required[wire]}
+		if ref, ok := prop["$ref"].(string); ok && reachesInline(g.refs, strings.TrimPrefix(ref, "#/$defs/"), name) {
+			f.boxed = true
+			g.indirect = true
+		}
+		fields = append(fields, f)
+
+		if desc, ok := prop["description"].(string); ok {
+			b.WriteString(swiftDoc("    ", desc))
+		}
+		if deprecated, _ := prop["deprecated"].(bool); deprecated {
+			b.WriteString("    /// - Note: Deprecated in the schema.\n")
+		}
+		optional := ""
+		if !f.required {
+			optional = "?"
+		}
+		if f.boxed {
+			get := "_" + f.name + ".value"
+			set := "Indirect(newValue)"
+			if !f.required {
+				get = "_" + f.name + "?.value"
+				set = "newValue.map(Indirect.init)"
+			}
+			fmt.Fprintf(&b, "    public var %s: %s%s {\n        get { %s }\n        set { _%s = %s }\n    }\n", swiftName(f.name), typ, optional, get, f.name, set)
+			continue
+		}
+		fmt.Fprintf(&b, "    public var %s: %s%s\n", swiftName(f.name), typ, optional)
+	}
+	for _, f := range fields {
+		if f.boxed {
+			optional := ""
+			if !f.required {
+				optional = "?"
+			}
+			fmt.Fprintf(&b, "    private var _%s: Indirect<%s>%s\n", f.name, f.typ, optional)
+		}
+	}
+
+	// Memberwise initializer; the synthesized one is internal
+	params := make([]string, len(fields))
+	for i, f := range fields {
+		params[i] = swiftName(f.name) + ": " + f.typ
+		if !f.required {
+			params[i] += "? = nil"
+		}
+	}
+	fmt.Fprintf(&b, "\n    public init(%s) {\n", strings.Join(params, ", "))
+	for _, f := range fields {
+		switch {
+		case f.boxed && f.required:
+			fmt.Fprintf(&b, "        self._%s = Indirect(%s)\n", f.name, swiftName(f.name))
+		case f.boxed:
+			fmt.Fprintf(&b, "        self._%s = %s.map(Indirect.init)\n", f.name, swiftName(f.name))
+		default:
+			fmt.Fprintf(&b, "        self.%s = %s\n", swiftName(f.name), swiftName(f.name))
+		}
+	}
+	b.WriteString("    }\n")
+
+	if len(fields) > 0 {
+		b.WriteString("\n    enum CodingKeys: String, CodingKey {\n")
+		for _, f := range fields {
+			key, same := swiftName(f.name), f.name == f.wire
+			if f.boxed {
+				key, same = "_"+f.name, false
+			}
+			if same {
+				fmt.Fprintf(&b, "        case %s\n", key)
+			} else {
+				fmt.Fprintf(&b, "        case %s = %q\n", key, f.wire)
+			}
+		}
+		b.WriteString("    }\n")
+	}
+	b.WriteString("}\n")
+
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+// fieldType returns the Swift type of a property, without the optional
+// marker.
+func (g *swiftModels) fieldType(nestedName, pointer string, prop map[string]interface{}) (string, error) {
+	if prop == nil {
+		g.jsonValue = true
+		return "JSONValue", nil
+	}
+	if ref, ok := prop["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/"), nil
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		return nestedName, g.enum(nestedName, enum)
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if variants, ok := prop[key].([]interface{}); ok {
+			// Unions of strings, such as an enum or a pattern, are plain
+			// strings; anything else is left untyped
+			if stringVariants(variants) {
+				return "String", nil
+			}
+			g.jsonValue = true
+			return "JSONValue", nil
+		}
+	}
+
+	switch prop["type"] {
+	case nil:
+		g.jsonValue = true
+		return "JSONValue", nil
+	case "string":
+		return "String", nil
+	case "integer":
+		return "Int", nil
+	case "number":
+		return "Double", nil
+	case "boolean":
+		return "Bool", nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		typ, err := g.fieldType(nestedName, pointer+"/items", items)
+		if err != nil {
+			return "", err
+		}
+		return "[" + typ + "]", nil
+	case "object":
+		if _, ok := prop["properties"]; ok {
+			return nestedName, g.structType(nestedName, pointer, prop)
+		}
+		values, _ := prop["additionalProperties"].(map[string]interface{})
+		typ, err := g.fieldType(nestedName, pointer+"/additionalProperties", values)
+		if err != nil {
+			return "", err
+		}
+		return "[String: " + typ + "]", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// enum renders a string enum as a String-backed enum.
+func (g *swiftModels) enum(name string, enum []interface{}) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "public enum %s: String, Codable, CaseIterable, Sendable {\n", name)
+	for _, v := range enum {
+		value, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("enum value %v is not a string", v)
+		}
+		c := camelCase(strings.NewReplacer("-", "_", ".", "_", " ", "_").Replace(value))
+		if c == value {
+			fmt.Fprintf(&b, "    case %s\n", swiftName(c))
+		} else {
+			fmt.Fprintf(&b, "    case %s = %q\n", swiftName(c), value)
+		}
+	}
+	b.WriteString("}\n")
+	g.decls = append(g.decls, b.String())
+	return nil
+}
+
+func swiftDoc(indent, s string) string {
+	return indent + "/// " + strings.ReplaceAll(s, "\n", "\n"+indent+"/// ") + "\n"
+}
+
+var swiftKeywords = map[string]bool{
+	"Any": true, "Self": true, "as": true, "associatedtype": true, "break": true,
+	"case": true, "catch": true, "class": true, "continue": true, "default": true,
+	"defer": true, "deinit": true, "do": true, "else": true, "enum": true,
+	"extension": true, "fallthrough": true, "false": true, "fileprivate": true,
+	"for": true, "func": true, "guard": true, "if": true, "import": true, "in": true,
+	"init": true, "inout": true, "internal": true, "is": true, "let": true,
+	"nil": true, "operator": true, "private": true, "protocol": true,
+	"public": true, "repeat": true, "rethrows": true, "return": true, "self": true,
+	"static": true, "struct": true, "subscript": true, "super": true,
+	"switch": true, "throw": true, "throws": true, "true": true, "try": true,
+	"typealias": true, "var": true, "where": true, "while": true,
+}
+
+// swiftName quotes keywords in backticks.
+func swiftName(name string) string {
+	if swiftKeywords[name] {
+		return "`" + name + "`"
+	}
+	return name
+}
@@ -25,164 +25,934 @@ func WriteTypeScriptValidators(dir, schemaPath string) error {
 import type {`, schemaFile) + `
   Statement,
   Mutation,
+  Transaction,
   Dependencies,
+  Query,
   Filter,
   Condition,
   OrderBy,
+  Aggregate,
+  Join,
+  Window,
+  DistinctOn,
 } from '@includekit/spec';
+import { SPEC_ENUMS, SPEC_KEYS, specAllows } from './constraints.js';
+import { SPEC_VERSION } from './version.js';
+
+/**
+ * Stable, machine-readable identifiers for the constraint a ValidationError
+ * reports, so SDKs can branch on error categories or localize messages.
+ * Messages and paths may differ between implementations, codes may not (see
+ * tools/tests/vectors/invalid-shapes.json). 10xx cover statements and
+ * filters, 11xx mutations, 12xx dependencies, 13xx strict mode and limits,
+ * and 14xx resolution against an application schema
+ */
+export const ErrorCodes = {
+  InvalidJSON: 'IK1000_INVALID_JSON',
+  EmptyModel: 'IK1001_EMPTY_MODEL',
+  EmptyField: 'IK1002_EMPTY_FIELD',
+  InvalidType: 'IK1003_INVALID_TYPE',
+  Required: 'IK1004_REQUIRED',
+  InvalidOperator: 'IK1005_INVALID_OPERATOR',
+  InvalidValue: 'IK1006_INVALID_VALUE',
+  OutOfRange: 'IK1007_OUT_OF_RANGE',
+  InvalidAggregate: 'IK1008_INVALID_AGGREGATE',
+  InvalidKind: 'IK1009_INVALID_KIND',
+  MixedPagination: 'IK1010_MIXED_PAGINATION',
+  InvalidJoin: 'IK1011_INVALID_JOIN',
+  InvalidSubquery: 'IK1012_INVALID_SUBQUERY',
+  InvalidFieldPath: 'IK1013_INVALID_FIELD_PATH',
+  InvalidWindow: 'IK1014_INVALID_WINDOW',
+  InvalidDistinct: 'IK1015_INVALID_DISTINCT',
+  InvalidDeleted: 'IK1016_INVALID_DELETED',
+  InvalidAction: 'IK1101_INVALID_ACTION',
+  MissingSet: 'IK1102_MISSING_SET',
+  UnexpectedSet: 'IK1103_UNEXPECTED_SET',
+  MissingWhere: 'IK1104_MISSING_WHERE',
+  UnexpectedWhere: 'IK1105_UNEXPECTED_WHERE',
+  InvalidShapeId: 'IK1201_INVALID_SHAPE_ID',
+  InvalidSpecVersion: 'IK1202_INVALID_SPEC_VERSION',
+  InvalidDateTime: 'IK1203_INVALID_DATE_TIME',
+  UnknownField: 'IK1301_UNKNOWN_FIELD',
+  CustomOperator: 'IK1302_CUSTOM_OPERATOR',
+  LimitExceeded: 'IK1303_LIMIT_EXCEEDED',
+  UnknownModel: 'IK1401_UNKNOWN_MODEL',
+  UnknownRelation: 'IK1402_UNKNOWN_RELATION',
+  UnresolvedField: 'IK1403_UNRESOLVED_FIELD',
+} as const;
+
+export type ErrorCode = (typeof ErrorCodes)[keyof typeof ErrorCodes];
 
 export class ValidationError extends Error {
-  constructor(message: string, public path: string = '') {
+  constructor(message: string, public path: string = '', public code?: ErrorCode) {
     super(message);
     this.name = 'ValidationError';
   }
 }
 
+// A declared spec version must be X.Y.Z and no newer than SPEC_VERSION, so
+// fields of a future version are not silently misread
+function validateSpecVersion(version: unknown, kind: string, path: string): void {
+  if (typeof version !== 'string' || !/^\d+\.\d+\.\d+$/.test(version)) {
+    throw new ValidationError(` + "`" + `${kind}.spec_version must be X.Y.Z` + "`" + `, path, ErrorCodes.InvalidSpecVersion);
+  }
+  const declared = version.split('.').map(Number);
+  const supported = SPEC_VERSION.split('.').map(Number);
+  for (let i = 0; i < 3; i++) {
+    if (declared[i] !== supported[i]) {
+      if (declared[i] > supported[i]) {
+        throw new ValidationError(` + "`" + `${kind}.spec_version ${version} is newer than supported ${SPEC_VERSION}` + "`" + `, path, ErrorCodes.InvalidSpecVersion);
+      }
+      return;
+    }
+  }
+}
+
+// Missing members are Required; present ones of the wrong type InvalidType
+function requiredOrType(value: unknown): ErrorCode {
+  return value === undefined || value === null ? ErrorCodes.Required : ErrorCodes.InvalidType;
+}
+
+export interface ValidateOptions {
+  /**
+   * Restrict input to the pure spec subset for byte-level cross-language
+   * determinism: reject custom: operators (the escape hatch for raw,
+   * ORM-specific expressions) and unknown, extension or deprecated fields
+   */
+  strict?: boolean;
+}
+
+function assertSpecKeys(obj: any, kind: string, path: string): void {
+  if (typeof obj !== 'object' || obj === null) {
+    return;
+  }
+  for (const key of Object.keys(obj)) {
+    if (!SPEC_KEYS[kind].includes(key)) {
+      throw new ValidationError(` + "`" + `Unknown field ${key} is not allowed in strict mode` + "`" + `, ` + "`" + `${path}.${key}` + "`" + `, ErrorCodes.UnknownField);
+    }
+  }
+}
+
+function assertStrictFilter(filter: any, path: string): void {
+  assertSpecKeys(filter, 'Filter', path);
+  filter?.and?.forEach?.((f: any, i: number) => assertStrictFilter(f, ` + "`" + `${path}.and[${i}]` + "`" + `));
+  filter?.or?.forEach?.((f: any, i: number) => assertStrictFilter(f, ` + "`" + `${path}.or[${i}]` + "`" + `));
+  if (filter?.not) {
+    assertStrictFilter(filter.not, ` + "`" + `${path}.not` + "`" + `);
+  }
+  filter?.conditions?.forEach?.((c: any, i: number) => {
+    const condPath = ` + "`" + `${path}.conditions[${i}]` + "`" + `;
+    assertSpecKeys(c, 'Condition', condPath);
+    if (typeof c?.op === 'string' && c.op.startsWith('custom:')) {
+      throw new ValidationError(` + "`" + `Custom operator ${c.op} is not allowed in strict mode` + "`" + `, ` + "`" + `${condPath}.op` + "`" + `, ErrorCodes.CustomOperator);
+    }
+    if (c?.subquery) {
+      assertStrictQuery(c.subquery, ` + "`" + `${condPath}.subquery` + "`" + `);
+    }
+  });
+}
+
+function assertStrictQuery(query: any, path: string): void {
+  assertSpecKeys(query, 'Query', path);
+  if (query?.where) {
+    assertStrictFilter(query.where, ` + "`" + `${path}.where` + "`" + `);
+  }
+  query?.order_by?.forEach?.((o: any, i: number) => assertSpecKeys(o, 'OrderBy', ` + "`" + `${path}.order_by[${i}]` + "`" + `));
+  if (query?.distinct_on) {
+    assertSpecKeys(query.distinct_on, 'DistinctOn', ` + "`" + `${path}.distinct_on` + "`" + `);
+    query.distinct_on.order_by?.forEach?.((o: any, i: number) => assertSpecKeys(o, 'OrderBy', ` + "`" + `${path}.distinct_on.order_by[${i}]` + "`" + `));
+  }
+  query?.aggregations?.forEach?.((a: any, i: number) => assertSpecKeys(a, 'Aggregate', ` + "`" + `${path}.aggregations[${i}]` + "`" + `));
+  query?.windows?.forEach?.((w: any, i: number) => {
+    const windowPath = ` + "`" + `${path}.windows[${i}]` + "`" + `;
+    assertSpecKeys(w, 'Window', windowPath);
+    w?.order_by?.forEach?.((o: any, j: number) => assertSpecKeys(o, 'OrderBy', ` + "`" + `${windowPath}.order_by[${j}]` + "`" + `));
+    if (w?.frame) {
+      assertSpecKeys(w.frame, 'WindowFrame', ` + "`" + `${windowPath}.frame` + "`" + `);
+    }
+  });
+  query?.joins?.forEach?.((j: any, i: number) => {
+    const joinPath = ` + "`" + `${path}.joins[${i}]` + "`" + `;
+    assertSpecKeys(j, 'Join', joinPath);
+    if (j?.on) {
+      assertStrictFilter(j.on, ` + "`" + `${joinPath}.on` + "`" + `);
+    }
+  });
+}
+
+function assertStrictIncludes(includes: any, path: string): void {
+  includes?.forEach?.((inc: any, i: number) => {
+    const incPath = ` + "`" + `${path}.includes[${i}]` + "`" + `;
+    assertSpecKeys(inc, 'Include', incPath);
+    if (inc?.query) {
+      assertStrictQuery(inc.query, ` + "`" + `${incPath}.query` + "`" + `);
+    }
+    assertStrictIncludes(inc?.includes, incPath);
+  });
+}
+
+function assertStrictStatement(statement: any): void {
+  assertSpecKeys(statement, 'Statement', 'statement');
+  if (statement.query) {
+    assertStrictQuery(statement.query, 'statement.query');
+  }
+  assertSpecKeys(statement.pagination, 'Pagination', 'statement.pagination');
+  if (statement.having) {
+    assertStrictFilter(statement.having, 'statement.having');
+  }
+  if (statement.policy) {
+    assertStrictFilter(statement.policy, 'statement.policy');
+  }
+  assertStrictIncludes(statement.includes, 'statement');
+}
+
+function assertStrictMutation(mutation: any): void {
+  assertSpecKeys(mutation, 'Mutation', 'mutation');
+  mutation.changes.forEach((change: any, i: number) => {
+    const changePath = ` + "`" + `mutation.changes[${i}]` + "`" + `;
+    assertSpecKeys(change, 'Change', changePath);
+    change.sets?.forEach?.((kv: any, j: number) => assertSpecKeys(kv, 'KV', ` + "`" + `${changePath}.sets[${j}]` + "`" + `));
+    if (change.where) {
+      assertStrictFilter(change.where, ` + "`" + `${changePath}.where` + "`" + `);
+    }
+  });
+}
+
+function assertStrictDependencies(deps: any): void {
+  assertSpecKeys(deps, 'Dependencies', 'dependencies');
+  deps.filters.forEach((f: any, i: number) => assertStrictFilter(f, ` + "`" + `dependencies.filters[${i}]` + "`" + `));
+  if (deps.policy) {
+    assertStrictFilter(deps.policy, 'dependencies.policy');
+  }
+  assertStrictIncludes(deps.includes, 'dependencies');
+  if (deps.last_row) {
+    assertSpecKeys(deps.last_row, 'PaginationBoundary', 'dependencies.last_row');
+    deps.last_row.order_by?.forEach?.((o: any, i: number) => assertSpecKeys(o, 'OrderBy', ` + "`" + `dependencies.last_row.order_by[${i}]` + "`" + `));
+    assertSpecKeys(deps.last_row.cursor, 'KV', 'dependencies.last_row.cursor');
+  }
+  assertSpecKeys(deps.group_by, 'Dependencies.group_by', 'dependencies.group_by');
+}
+
+/**
+ * Value shape a custom operator takes: none (null or absent), one (a single
+ * value, not an array), pair (a two-element array, like between) or list (an
+ * array, or null while unbound, like in)
+ */
+export type OperatorArity = 'none' | 'one' | 'pair' | 'list';
+
+/** A registered custom operator, as listed by operators() */
+export interface OperatorSpec {
+  name: string;
+  arity: OperatorArity;
+}
+
+const customOperators = new Map<string, { arity: OperatorArity; validate?: (value: unknown) => void }>();
+
+/**
+ * Declare a custom operator, such as 'custom:fts', so validators check the
+ * values it is given: their shape against arity, then validate (which throws
+ * to reject a value) if given. Conditions using operators that were never
+ * registered are accepted as before. Mirrors Go's tests.RegisterOperator;
+ * "codegen operators" generates these calls from the Go registry.
+ */
+export function registerOperator(name: string, arity: OperatorArity, validate?: (value: unknown) => void): void {
+  if (!name.startsWith('custom:') || name.length === 'custom:'.length) {
+    throw new Error(` + "`" + `Operator ${name} must be named custom:<name>` + "`" + `);
+  }
+  if (!['none', 'one', 'pair', 'list'].includes(arity)) {
+    throw new Error(` + "`" + `Operator ${name}: unknown arity ${arity}` + "`" + `);
+  }
+  if (customOperators.has(name)) {
+    throw new Error(` + "`" + `Operator ${name} is already registered` + "`" + `);
+  }
+  customOperators.set(name, { arity, validate });
+}
+
+/** The registered custom operators sorted by name */
+export function operators(): OperatorSpec[] {
+  return [...customOperators.entries()]
+    .map(([name, op]) => ({ name, arity: op.arity }))
+    .sort((a, b) => (a.name < b.name ? -1 : a.name > b.name ? 1 : 0));
+}
+
+function validateCustomOperator(condition: any, path: string): void {
+  const op = customOperators.get(condition.op);
+  if (!op) {
+    return;
+  }
+  const value = condition.value ?? null;
+  let shapeErr = '';
+  switch (op.arity) {
+    case 'none':
+      if (value !== null) {
+        shapeErr = 'takes no value';
+      }
+      break;
+    case 'one':
+      if (Array.isArray(value)) {
+        shapeErr = 'requires a single value, not an array';
+      }
+      break;
+    case 'pair':
+      if (!Array.isArray(value) || value.length !== 2) {
+        shapeErr = 'requires a two-element array value';
+      }
+      break;
+    case 'list':
+      if (!Array.isArray(value) && value !== null) {
+        shapeErr = 'requires an array value';
+      }
+      break;
+  }
+  if (shapeErr) {
+    throw new ValidationError(` + "`" + `${condition.op} ${shapeErr}` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+  }
+  try {
+    op.validate?.(value);
+  } catch (err) {
+    throw new ValidationError(` + "`" + `${condition.op}: ${(err as Error).message}` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+  }
+}
+
+// The values jsonType takes, as PostgreSQL's jsonb_typeof names them
+const JSON_TYPES = ['object', 'array', 'string', 'number', 'boolean', 'null'];
+
+// Geospatial values: a GeoPoint is {lat, lng}, a GeoShape exactly one of a
+// circle {center, radius} or a polygon of at least 3 GeoPoints. Messages
+// match Go's tests.ValidateQueryShape.
+function geoUnknownKey(obj: any, name: string, allowed: string[]): string {
+  const unknown = Object.keys(obj).filter((k) => !allowed.includes(k)).sort();
+  return unknown.length > 0 ? ` + "`" + `name has unknown property "${unknown[0]}"` + "`" + ` : '';
+}
+
+function geoPointError(v: any): string {
+  if (typeof v !== 'object' || v === null || Array.isArray(v)) {
+    return 'GeoPoint must be an object with lat and lng';
+  }
+  const unknown = geoUnknownKey(v, 'GeoPoint', ['lat', 'lng']);
+  if (unknown) {
+    return unknown;
+  }
+  if (typeof v.lat !== 'number' || v.lat < -90 || v.lat > 90) {
+    return 'lat must be a number from -90 to 90';
+  }
+  if (typeof v.lng !== 'number' || v.lng < -180 || v.lng > 180) {
+    return 'lng must be a number from -180 to 180';
+  }
+  return '';
+}
+
+function geoShapeError(v: any, circleOnly: boolean): string {
+  if (typeof v !== 'object' || v === null || Array.isArray(v)) {
+    return 'GeoShape must be an object';
+  }
+  const unknown = geoUnknownKey(v, 'GeoShape', ['center', 'radius', 'polygon']);
+  if (unknown) {
+    return unknown;
+  }
+  const hasCenter = 'center' in v;
+  const hasRadius = 'radius' in v;
+  if ('polygon' in v) {
+    if (hasCenter || hasRadius) {
+      return 'GeoShape must be a circle or a polygon, not both';
+    }
+    if (!Array.isArray(v.polygon) || v.polygon.length < 3) {
+      return 'polygon must be an array of at least 3 GeoPoints';
+    }
+    for (let i = 0; i < v.polygon.length; i++) {
+      const err = geoPointError(v.polygon[i]);
+      if (err) {
+        return ` + "`" + `polygon[${i}]: ${err}` + "`" + `;
+      }
+    }
+    return circleOnly ? 'GeoShape must be a circle' : '';
+  }
+  if (!hasCenter || !hasRadius) {
+    return 'GeoShape circle requires center and radius';
+  }
+  const err = geoPointError(v.center);
+  if (err) {
+    return ` + "`" + `center: ${err}` + "`" + `;
+  }
+  if (typeof v.radius !== 'number' || v.radius < 0) {
+    return 'radius must be a non-negative number of meters';
+  }
+  return '';
+}
+
+function geoValueError(op: string, v: any): string {
+  if (op !== 'bbox') {
+    return geoShapeError(v, op === 'withinRadius');
+  }
+  if (!Array.isArray(v) || v.length !== 2) {
+    return 'bbox requires a [southwest, northeast] array value';
+  }
+  const sw = geoPointError(v[0]);
+  if (sw) {
+    return ` + "`" + `southwest: ${sw}` + "`" + `;
+  }
+  const ne = geoPointError(v[1]);
+  if (ne) {
+    return ` + "`" + `northeast: ${ne}` + "`" + `;
+  }
+  if (v[0].lat > v[1].lat) {
+    return 'bbox southwest must not lie north of northeast';
+  }
+  return '';
+}
+
 function validateCondition(condition: any, path: string = 'condition'): asserts condition is Condition {
   if (typeof condition !== 'object' || condition === null) {
-    throw new ValidationError('Condition must be an object', path);
+    throw new ValidationError('Condition must be an object', path, ErrorCodes.InvalidType);
   }
   if (typeof condition.field !== 'string' || condition.field.length === 0) {
-    throw new ValidationError('Condition.field must be a non-empty string', ` + "`${path}.field`" + `);
+    throw new ValidationError('Condition.field must be a non-empty string', ` + "`" + `${path}.field` + "`" + `, ErrorCodes.EmptyField);
   }
   if (typeof condition.op !== 'string') {
-    throw new ValidationError('Condition.op must be a string', ` + "`${path}.op`" + `);
+    throw new ValidationError('Condition.op must be a string', ` + "`" + `${path}.op` + "`" + `, ErrorCodes.InvalidOperator);
   }
 
-  const validOps = [
-    'eq', 'ne', 'in', 'notIn', 'isNull',
-    'gt', 'gte', 'lt', 'lte', 'between',
-    'contains', 'startsWith', 'endsWith',
-    'like', 'ilike', 'regex',
-    'has', 'hasSome', 'hasEvery', 'jsonContains',
-    'lenEq', 'lenGt', 'lenLt', 'exists'
-  ];
-
-  const isCustomOp = condition.op.startsWith('custom:');
-  if (!validOps.includes(condition.op) && !isCustomOp) {
-    throw new ValidationError(` + "`Invalid operator: ${condition.op}`" + `, ` + "`${path}.op`" + `);
+  // Built-in operators are the enum of Condition.op; custom ones match its pattern
+  const isCustomOp = !SPEC_ENUMS['Condition.op'].includes(condition.op);
+  if (!specAllows('Condition.op', condition.op)) {
+    throw new ValidationError(` + "`" + `Invalid operator: ${condition.op}` + "`" + `, ` + "`" + `${path}.op` + "`" + `, ErrorCodes.InvalidOperator);
   }
   
-  // value can be any JSON value - no type validation needed
+  if (condition.field_path !== undefined) {
+    if (!Array.isArray(condition.field_path)) {
+      throw new ValidationError('Condition.field_path must be an array', ` + "`" + `${path}.field_path` + "`" + `, ErrorCodes.InvalidType);
+    }
+    condition.field_path.forEach((segment: any, i: number) => {
+      if (typeof segment !== 'string' || segment.length === 0) {
+        throw new ValidationError('field_path segment must be non-empty', ` + "`" + `${path}.field_path[${i}]` + "`" + `, ErrorCodes.EmptyField);
+      }
+    });
+  }
+
+  // Operators that need a particular value shape; other values can be any JSON value
+  const value = condition.value;
+  switch (condition.op) {
+    case 'between':
+      if (!Array.isArray(value) || value.length !== 2) {
+        throw new ValidationError('between requires a [lo, hi] array value', ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+      }
+      break;
+    case 'in':
+    case 'notIn':
+      // null while the values are unbound, as in split parts
+      if (!Array.isArray(value) && value !== undefined && value !== null) {
+        throw new ValidationError(` + "`" + `${condition.op} requires an array value` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+      }
+      break;
+    case 'isNull':
+    case 'exists':
+      if (typeof value !== 'boolean' && value !== undefined && value !== null) {
+        throw new ValidationError(` + "`" + `${condition.op} requires a boolean or null value` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+      }
+      break;
+    case 'jsonHasKey':
+      if (typeof value !== 'string') {
+        throw new ValidationError('jsonHasKey requires a string value', ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+      }
+      break;
+    case 'jsonType':
+      if (!JSON_TYPES.includes(value)) {
+        throw new ValidationError(` + "`" + `jsonType requires one of ${JSON_TYPES.join(', ')}` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+      }
+      break;
+    case 'withinRadius':
+    case 'intersects':
+    case 'bbox': {
+      const err = geoValueError(condition.op, value);
+      if (err) {
+        throw new ValidationError(` + "`" + `${condition.op}: ${err}` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+      }
+      break;
+    }
+  }
+  if (isCustomOp) {
+    validateCustomOperator(condition, path);
+  }
+
+  validateSubquery(condition, path);
+}
+
+// inQuery and existsQuery take a subquery selecting exactly one field; no
+// other operator takes one
+function validateSubquery(condition: any, path: string): void {
+  const sub = condition.subquery;
+  if (condition.op !== 'inQuery' && condition.op !== 'existsQuery') {
+    if (sub !== undefined && sub !== null) {
+      throw new ValidationError(` + "`" + `${condition.op} does not take a subquery` + "`" + `, ` + "`" + `${path}.subquery` + "`" + `, ErrorCodes.InvalidSubquery);
+    }
+    return;
+  }
+  if (condition.value !== undefined && condition.value !== null) {
+    throw new ValidationError(` + "`" + `${condition.op} takes a subquery, not a value` + "`" + `, ` + "`" + `${path}.value` + "`" + `, ErrorCodes.InvalidValue);
+  }
+  if (sub === undefined || sub === null) {
+    throw new ValidationError(` + "`" + `${condition.op} requires a subquery` + "`" + `, ` + "`" + `${path}.subquery` + "`" + `, ErrorCodes.Required);
+  }
+  validateQuery(sub, ` + "`" + `${path}.subquery` + "`" + `);
+
+  let columns = (sub.fields?.length ?? 0) + (sub.aggregations?.length ?? 0) + (sub.windows?.length ?? 0);
+  sub.joins?.forEach?.((j: any) => { columns += j?.fields?.length ?? 0; });
+  if (sub.fields?.length !== 1 || columns !== 1) {
+    throw new ValidationError('subquery must select exactly one field', ` + "`" + `${path}.subquery.fields` + "`" + `, ErrorCodes.InvalidSubquery);
+  }
 }
 
 function validateFilter(filter: any, path: string = 'filter'): asserts filter is Filter {
   if (typeof filter !== 'object' || filter === null) {
-    throw new ValidationError('Filter must be an object', path);
+    throw new ValidationError('Filter must be an object', path, ErrorCodes.InvalidType);
   }
 
   if (filter.and && Array.isArray(filter.and)) {
-    filter.and.forEach((f: any, i: number) => validateFilter(f, ` + "`${path}.and[${i}]`" + `));
+    filter.and.forEach((f: any, i: number) => validateFilter(f, ` + "`" + `${path}.and[${i}]` + "`" + `));
   }
   if (filter.or && Array.isArray(filter.or)) {
-    filter.or.forEach((f: any, i: number) => validateFilter(f, ` + "`${path}.or[${i}]`" + `));
+    filter.or.forEach((f: any, i: number) => validateFilter(f, ` + "`" + `${path}.or[${i}]` + "`" + `));
   }
   if (filter.not) {
-    validateFilter(filter.not, ` + "`${path}.not`" + `);
+    validateFilter(filter.not, ` + "`" + `${path}.not` + "`" + `);
   }
   if (filter.conditions && Array.isArray(filter.conditions)) {
-    filter.conditions.forEach((c: any, i: number) => validateCondition(c, ` + "`${path}.conditions[${i}]`" + `));
+    filter.conditions.forEach((c: any, i: number) => validateCondition(c, ` + "`" + `${path}.conditions[${i}]` + "`" + `));
   }
 }
 
 function validateOrderBy(orderBy: any, path: string = 'orderBy'): asserts orderBy is OrderBy {
   if (typeof orderBy !== 'object' || orderBy === null) {
-    throw new ValidationError('OrderBy must be an object', path);
+    throw new ValidationError('OrderBy must be an object', path, ErrorCodes.InvalidType);
   }
   if (typeof orderBy.field !== 'string' || orderBy.field.length === 0) {
-    throw new ValidationError('OrderBy.field must be a non-empty string', ` + "`${path}.field`" + `);
+    throw new ValidationError('OrderBy.field must be a non-empty string', ` + "`" + `${path}.field` + "`" + `, ErrorCodes.EmptyField);
   }
   // descending, nulls_first, case_sensitive are all booleans - no validation needed beyond type
 }
 
-export function validateStatement(statement: any): asserts statement is Statement {
-  if (typeof statement !== 'object' || statement === null) {
-    throw new ValidationError('Statement must be an object', 'statement');
+function validateAggregate(aggregate: any, path: string = 'aggregate'): asserts aggregate is Aggregate {
+  if (typeof aggregate !== 'object' || aggregate === null) {
+    throw new ValidationError('Aggregate must be an object', path, ErrorCodes.InvalidType);
+  }
+  if (!specAllows('Aggregate.func', aggregate.func)) {
+    throw new ValidationError(` + "`" + `Invalid aggregate func: ${aggregate.func}` + "`" + `, ` + "`" + `${path}.func` + "`" + `, ErrorCodes.InvalidAggregate);
+  }
+  if (aggregate.field === undefined && aggregate.func !== 'count') {
+    throw new ValidationError(` + "`" + `Aggregate ${aggregate.func} requires a field` + "`" + `, ` + "`" + `${path}.field` + "`" + `, ErrorCodes.InvalidAggregate);
+  }
+  if (aggregate.field !== undefined && (typeof aggregate.field !== 'string' || aggregate.field.length === 0)) {
+    throw new ValidationError('Aggregate.field must be a non-empty string', ` + "`" + `${path}.field` + "`" + `, ErrorCodes.EmptyField);
+  }
+  if (aggregate.alias !== undefined && (typeof aggregate.alias !== 'string' || aggregate.alias.length === 0)) {
+    throw new ValidationError('Aggregate.alias must be a non-empty string', ` + "`" + `${path}.alias` + "`" + `, ErrorCodes.EmptyField);
   }
+}
 
-  if (statement.query) {
-    if (typeof statement.query !== 'object' || statement.query === null) {
-      throw new ValidationError('Statement.query must be an object', 'statement.query');
+const RANKING_FUNCS = new Set(['row_number', 'rank', 'dense_rank']);
+
+function validateWindow(window: any, path: string = 'window'): asserts window is Window {
+  if (typeof window !== 'object' || window === null) {
+    throw new ValidationError('Window must be an object', path, ErrorCodes.InvalidType);
+  }
+  if (!specAllows('Window.func', window.func)) {
+    throw new ValidationError(` + "`" + `Invalid window func: ${window.func}` + "`" + `, ` + "`" + `${path}.func` + "`" + `, ErrorCodes.InvalidWindow);
+  }
+  const ranking = RANKING_FUNCS.has(window.func);
+  if (ranking && window.field !== undefined) {
+    throw new ValidationError(` + "`" + `Window ${window.func} takes no field` + "`" + `, ` + "`" + `${path}.field` + "`" + `, ErrorCodes.InvalidWindow);
+  }
+  if (ranking && !(window.order_by?.length > 0)) {
+    throw new ValidationError(` + "`" + `Window ${window.func} requires order_by` + "`" + `, ` + "`" + `${path}.order_by` + "`" + `, ErrorCodes.InvalidWindow);
+  }
+  if (ranking && window.frame !== undefined) {
+    throw new ValidationError(` + "`" + `Window ${window.func} takes no frame` + "`" + `, ` + "`" + `${path}.frame` + "`" + `, ErrorCodes.InvalidWindow);
+  }
+  if (!ranking && window.field === undefined && window.func !== 'count') {
+    throw new ValidationError(` + "`" + `Window ${window.func} requires a field` + "`" + `, ` + "`" + `${path}.field` + "`" + `, ErrorCodes.InvalidWindow);
+  }
+  if (window.field !== undefined && (typeof window.field !== 'string' || window.field.length === 0)) {
+    throw new ValidationError('Window.field must be a non-empty string', ` + "`" + `${path}.field` + "`" + `, ErrorCodes.EmptyField);
+  }
+  if (typeof window.alias !== 'string' || window.alias.length === 0) {
+    throw new ValidationError('Window.alias must be a non-empty string', ` + "`" + `${path}.alias` + "`" + `, ErrorCodes.EmptyField);
+  }
+  window.partition_by?.forEach?.((f: any, i: number) => {
+    if (typeof f !== 'string' || f.length === 0) {
+      throw new ValidationError('partition_by field must be a non-empty string', ` + "`" + `${path}.partition_by[${i}]` + "`" + `, ErrorCodes.EmptyField);
     }
-    if (typeof statement.query.model !== 'string' || statement.query.model.length === 0) {
-      throw new ValidationError('Statement.query.model must be a non-empty string', 'statement.query.model');
+  });
+  window.order_by?.forEach?.((o: any, i: number) => validateOrderBy(o, ` + "`" + `${path}.order_by[${i}]` + "`" + `));
+
+  const frame = window.frame;
+  if (frame !== undefined) {
+    if (!specAllows('WindowFrame.unit', frame?.unit)) {
+      throw new ValidationError(` + "`" + `Invalid window frame unit: ${frame?.unit}` + "`" + `, ` + "`" + `${path}.frame.unit` + "`" + `, ErrorCodes.InvalidWindow);
     }
-    if (statement.query.where) {
-      validateFilter(statement.query.where, 'statement.query.where');
+    if (frame.start !== undefined && frame.end !== undefined && frame.start > frame.end) {
+      throw new ValidationError('Window frame start must not follow its end', ` + "`" + `${path}.frame` + "`" + `, ErrorCodes.OutOfRange);
     }
-    if (statement.query.order_by && Array.isArray(statement.query.order_by)) {
-      statement.query.order_by.forEach((o: any, i: number) => validateOrderBy(o, ` + "`statement.query.order_by[${i}]`" + `));
+    // A range offset is a difference of the sort key, so there must be
+    // exactly one
+    const offset = (frame.start ?? 0) !== 0 || (frame.end ?? 0) !== 0;
+    if (frame.unit === 'range' && offset && window.order_by?.length !== 1) {
+      throw new ValidationError('Range frame offsets require exactly one order_by field', ` + "`" + `${path}.frame` + "`" + `, ErrorCodes.InvalidWindow);
     }
-    if (statement.query.limit !== undefined && (typeof statement.query.limit !== 'number' || !Number.isInteger(statement.query.limit))) {
-      throw new ValidationError('Statement.query.limit must be an integer', 'statement.query.limit');
+  }
+}
+
+function validateDistinctOn(distinctOn: any, path: string = 'distinct_on'): asserts distinctOn is DistinctOn {
+  if (typeof distinctOn !== 'object' || distinctOn === null) {
+    throw new ValidationError('DistinctOn must be an object', path, ErrorCodes.InvalidType);
+  }
+  const fields: any[] = Array.isArray(distinctOn.fields) ? distinctOn.fields : [];
+  if (fields.length === 0) {
+    throw new ValidationError('distinct_on requires fields', ` + "`" + `${path}.fields` + "`" + `, ErrorCodes.Required);
+  }
+  fields.forEach((f: any, i: number) => {
+    if (typeof f !== 'string' || f.length === 0) {
+      throw new ValidationError('distinct_on field must be a non-empty string', ` + "`" + `${path}.fields[${i}]` + "`" + `, ErrorCodes.EmptyField);
     }
-    if (statement.query.offset !== undefined && (typeof statement.query.offset !== 'number' || !Number.isInteger(statement.query.offset))) {
-      throw new ValidationError('Statement.query.offset must be an integer', 'statement.query.offset');
+    if (fields.indexOf(f) !== i) {
+      throw new ValidationError(` + "`" + `Duplicate distinct_on field: ${f}` + "`" + `, ` + "`" + `${path}.fields[${i}]` + "`" + `, ErrorCodes.InvalidDistinct);
     }
+  });
+  const orderBy: any[] = Array.isArray(distinctOn.order_by) ? distinctOn.order_by : [];
+  orderBy.forEach((o: any, i: number) => validateOrderBy(o, ` + "`" + `${path}.order_by[${i}]` + "`" + `));
+
+  // The order_by must sort by the fields first, in any order, so that it
+  // only ranks the rows sharing them
+  const prefix = new Set(orderBy.slice(0, fields.length).map((o: any) => o.field));
+  if (orderBy.length < fields.length || prefix.size !== fields.length || !fields.every((f: any) => prefix.has(f))) {
+    throw new ValidationError('distinct_on order_by must start with its fields', ` + "`" + `${path}.order_by` + "`" + `, ErrorCodes.InvalidDistinct);
   }
+}
+
+function validateJoin(join: any, path: string = 'join'): asserts join is Join {
+  if (typeof join !== 'object' || join === null) {
+    throw new ValidationError('Join must be an object', path, ErrorCodes.InvalidType);
+  }
+  if (typeof join.relation !== 'string' || join.relation.length === 0) {
+    throw new ValidationError('Join.relation must be a non-empty string', ` + "`" + `${path}.relation` + "`" + `, ErrorCodes.InvalidJoin);
+  }
+  if (!specAllows('Join.kind', join.kind)) {
+    throw new ValidationError(` + "`" + `Invalid join kind: ${join.kind}` + "`" + `, ` + "`" + `${path}.kind` + "`" + `, ErrorCodes.InvalidJoin);
+  }
+  if (join.on) {
+    validateFilter(join.on, ` + "`" + `${path}.on` + "`" + `);
+  }
+  if (join.fields !== undefined) {
+    if (!Array.isArray(join.fields)) {
+      throw new ValidationError('Join.fields must be an array', ` + "`" + `${path}.fields` + "`" + `, ErrorCodes.InvalidType);
+    }
+    join.fields.forEach((f: any, i: number) => {
+      if (typeof f !== 'string' || f.length === 0) {
+        throw new ValidationError('Join field must be a non-empty string', ` + "`" + `${path}.fields[${i}]` + "`" + `, ErrorCodes.EmptyField);
+      }
+    });
+  }
+}
+
+function validateQuery(query: any, path: string): asserts query is Query {
+  if (typeof query !== 'object' || query === null) {
+    throw new ValidationError('Query must be an object', path, ErrorCodes.InvalidType);
+  }
+  if (typeof query.model !== 'string' || query.model.length === 0) {
+    throw new ValidationError('Query.model must be a non-empty string', ` + "`" + `${path}.model` + "`" + `, ErrorCodes.EmptyModel);
+  }
+  if (query.where) {
+    validateFilter(query.where, ` + "`" + `${path}.where` + "`" + `);
+  }
+  if (query.order_by && Array.isArray(query.order_by)) {
+    query.order_by.forEach((o: any, i: number) => validateOrderBy(o, ` + "`" + `${path}.order_by[${i}]` + "`" + `));
+  }
+  if (query.limit !== undefined && (typeof query.limit !== 'number' || !Number.isInteger(query.limit))) {
+    throw new ValidationError('Query.limit must be an integer', ` + "`" + `${path}.limit` + "`" + `, ErrorCodes.InvalidType);
+  }
+  if (query.offset !== undefined && (typeof query.offset !== 'number' || !Number.isInteger(query.offset))) {
+    throw new ValidationError('Query.offset must be an integer', ` + "`" + `${path}.offset` + "`" + `, ErrorCodes.InvalidType);
+  }
+  if (query.limit < 0) {
+    throw new ValidationError('Query.limit must be non-negative', ` + "`" + `${path}.limit` + "`" + `, ErrorCodes.OutOfRange);
+  }
+  if (query.offset < 0) {
+    throw new ValidationError('Query.offset must be non-negative', ` + "`" + `${path}.offset` + "`" + `, ErrorCodes.OutOfRange);
+  }
+  if (query.distinct_on !== undefined) {
+    if (query.distinct !== undefined) {
+      throw new ValidationError('distinct_on cannot be combined with distinct', ` + "`" + `${path}.distinct_on` + "`" + `, ErrorCodes.InvalidDistinct);
+    }
+    validateDistinctOn(query.distinct_on, ` + "`" + `${path}.distinct_on` + "`" + `);
+  }
+  if (query.deleted !== undefined && !specAllows('Query.deleted', query.deleted)) {
+    throw new ValidationError(` + "`" + `Invalid deleted mode: ${query.deleted}` + "`" + `, ` + "`" + `${path}.deleted` + "`" + `, ErrorCodes.InvalidDeleted);
+  }
+  const columns = new Set<string>();
+  if (query.aggregations && Array.isArray(query.aggregations)) {
+    query.aggregations.forEach((a: any, i: number) => {
+      const aggPath = ` + "`" + `${path}.aggregations[${i}]` + "`" + `;
+      validateAggregate(a, aggPath);
+      const column = aggregateColumn(a);
+      if (columns.has(column)) {
+        throw new ValidationError(` + "`" + `Duplicate aggregate column: ${column}` + "`" + `, aggPath, ErrorCodes.InvalidAggregate);
+      }
+      columns.add(column);
+    });
+  }
+  if (query.joins && Array.isArray(query.joins)) {
+    const relations = new Set<string>();
+    query.joins.forEach((j: any, i: number) => {
+      const joinPath = ` + "`" + `${path}.joins[${i}]` + "`" + `;
+      validateJoin(j, joinPath);
+      if (relations.has(j.relation)) {
+        throw new ValidationError(` + "`" + `Duplicate join of relation: ${j.relation}` + "`" + `, joinPath, ErrorCodes.InvalidJoin);
+      }
+      relations.add(j.relation);
+    });
+  }
+  if (query.windows && Array.isArray(query.windows)) {
+    query.windows.forEach((w: any, i: number) => {
+      const windowPath = ` + "`" + `${path}.windows[${i}]` + "`" + `;
+      validateWindow(w, windowPath);
+      if (columns.has(w.alias)) {
+        throw new ValidationError(` + "`" + `Duplicate window column: ${w.alias}` + "`" + `, windowPath, ErrorCodes.InvalidWindow);
+      }
+      columns.add(w.alias);
+    });
+  }
+}
+
+// Aggregates are numbers, not JSON, so having conditions on their result
+// columns take no field_path
+function validateHavingFieldPaths(filter: any, columns: Set<string>, path: string): void {
+  filter.and?.forEach?.((f: any, i: number) => validateHavingFieldPaths(f, columns, ` + "`" + `${path}.and[${i}]` + "`" + `));
+  filter.or?.forEach?.((f: any, i: number) => validateHavingFieldPaths(f, columns, ` + "`" + `${path}.or[${i}]` + "`" + `));
+  if (filter.not) {
+    validateHavingFieldPaths(filter.not, columns, ` + "`" + `${path}.not` + "`" + `);
+  }
+  filter.conditions?.forEach?.((c: any, i: number) => {
+    if (c.field_path?.length > 0 && columns.has(c.field)) {
+      throw new ValidationError(` + "`" + `field_path cannot descend into aggregate column ${c.field}` + "`" + `, ` + "`" + `${path}.conditions[${i}].field_path` + "`" + `, ErrorCodes.InvalidFieldPath);
+    }
+  });
+}
+
+function validateIncludes(includes: any, path: string): void {
+  includes?.forEach?.((inc: any, i: number) => {
+    const incPath = ` + "`" + `${path}.includes[${i}]` + "`" + `;
+    if (inc?.query) {
+      validateQuery(inc.query, ` + "`" + `${incPath}.query` + "`" + `);
+    }
+    if (inc?.kind !== undefined && !specAllows('Include.kind', inc.kind)) {
+      throw new ValidationError(` + "`" + `Invalid include kind: ${inc.kind}` + "`" + `, ` + "`" + `${incPath}.kind` + "`" + `, ErrorCodes.InvalidKind);
+    }
+    // A count goes with kind count, and only with it
+    if (inc?.kind === 'count' && inc.count === undefined) {
+      throw new ValidationError('Include kind count requires count', ` + "`" + `${incPath}.count` + "`" + `, ErrorCodes.Required);
+    }
+    if (inc?.kind !== 'count' && inc?.count !== undefined) {
+      throw new ValidationError('Include.count requires kind count', ` + "`" + `${incPath}.kind` + "`" + `, ErrorCodes.InvalidKind);
+    }
+    if (inc?.count !== undefined) {
+      if (!specAllows('RelationCount.op', inc.count?.op)) {
+        throw new ValidationError(` + "`" + `Invalid count op: ${inc.count?.op}` + "`" + `, ` + "`" + `${incPath}.count.op` + "`" + `, ErrorCodes.InvalidOperator);
+      }
+      if (!Number.isInteger(inc.count.value)) {
+        throw new ValidationError('Include.count.value must be an integer', ` + "`" + `${incPath}.count.value` + "`" + `, ErrorCodes.InvalidType);
+      }
+      if (inc.count.value < 0) {
+        throw new ValidationError('Include.count.value must be non-negative', ` + "`" + `${incPath}.count.value` + "`" + `, ErrorCodes.OutOfRange);
+      }
+    }
+    validateIncludes(inc?.includes, incPath);
+  });
+}
+
+function aggregateColumn(aggregate: Aggregate): string {
+  if (aggregate.alias) {
+    return aggregate.alias;
+  }
+  return aggregate.field ? ` + "`" + `${aggregate.func}_${aggregate.field}` + "`" + ` : aggregate.func;
+}
+
+export function validateStatement(statement: any, options: ValidateOptions = {}): asserts statement is Statement {
+  if (typeof statement !== 'object' || statement === null) {
+    throw new ValidationError('Statement must be an object', 'statement', ErrorCodes.InvalidType);
+  }
+
+  if (statement.spec_version !== undefined) {
+    validateSpecVersion(statement.spec_version, 'Statement', 'statement.spec_version');
+  }
+  if (statement.tenant !== undefined && (typeof statement.tenant !== 'string' || statement.tenant.length === 0)) {
+    throw new ValidationError('Statement.tenant must be a non-empty string', 'statement.tenant', ErrorCodes.EmptyField);
+  }
+
+  if (statement.query) {
+    validateQuery(statement.query, 'statement.query');
+  }
+
+  if (statement.having) {
+    validateFilter(statement.having, 'statement.having');
+    const columns = new Set<string>((statement.query?.aggregations ?? []).map(aggregateColumn));
+    validateHavingFieldPaths(statement.having, columns, 'statement.having');
+  }
+
+  if (statement.policy) {
+    validateFilter(statement.policy, 'statement.policy');
+  }
+
+  validateIncludes(statement.includes, 'statement');
 
   if (statement.pagination) {
     if (typeof statement.pagination !== 'object' || statement.pagination === null) {
-      throw new ValidationError('Statement.pagination must be an object', 'statement.pagination');
+      throw new ValidationError('Statement.pagination must be an object', 'statement.pagination', ErrorCodes.InvalidType);
     }
     const hasForward = statement.pagination.first !== undefined || statement.pagination.after !== undefined;
     const hasBackward = statement.pagination.last !== undefined || statement.pagination.before !== undefined;
     if (hasForward && hasBackward) {
-      throw new ValidationError('Cannot mix forward and backward pagination', 'statement.pagination');
+      throw new ValidationError('Cannot mix forward and backward pagination', 'statement.pagination', ErrorCodes.MixedPagination);
     }
+    for (const key of ['first', 'last']) {
+      const n = statement.pagination[key];
+      if (n !== undefined && (!Number.isInteger(n) || n <= 0)) {
+        throw new ValidationError(` + "`" + `Statement.pagination.${key} must be a positive integer` + "`" + `, ` + "`" + `statement.pagination.${key}` + "`" + `, ErrorCodes.OutOfRange);
+      }
+    }
+  }
+
+  if (options.strict) {
+    assertStrictStatement(statement);
   }
 }
 
-export function validateMutation(mutation: any): asserts mutation is Mutation {
+export function validateMutation(mutation: any, options: ValidateOptions = {}): asserts mutation is Mutation {
   if (typeof mutation !== 'object' || mutation === null) {
-    throw new ValidationError('Mutation must be an object', 'mutation');
+    throw new ValidationError('Mutation must be an object', 'mutation', ErrorCodes.InvalidType);
   }
   if (!Array.isArray(mutation.changes)) {
-    throw new ValidationError('Mutation.changes must be an array', 'mutation.changes');
+    throw new ValidationError('Mutation.changes must be an array', 'mutation.changes', requiredOrType(mutation.changes));
+  }
+  if (mutation.tenant !== undefined && (typeof mutation.tenant !== 'string' || mutation.tenant.length === 0)) {
+    throw new ValidationError('Mutation.tenant must be a non-empty string', 'mutation.tenant', ErrorCodes.EmptyField);
   }
 
   mutation.changes.forEach((change: any, i: number) => {
     if (typeof change !== 'object' || change === null) {
-      throw new ValidationError(` + "`Change must be an object`" + `, ` + "`mutation.changes[${i}]`" + `);
+      throw new ValidationError(` + "`" + `Change must be an object` + "`" + `, ` + "`" + `mutation.changes[${i}]` + "`" + `, ErrorCodes.InvalidType);
     }
-    if (!['insert', 'update', 'delete'].includes(change.action)) {
-      throw new ValidationError(` + "`Invalid change action: must be insert, update, or delete`" + `, ` + "`mutation.changes[${i}].action`" + `);
+    if (!specAllows('Change.action', change.action)) {
+      throw new ValidationError(` + "`" + `Invalid change action: must be insert, update, or delete` + "`" + `, ` + "`" + `mutation.changes[${i}].action` + "`" + `, ErrorCodes.InvalidAction);
     }
     if (typeof change.model !== 'string' || change.model.length === 0) {
-      throw new ValidationError('Change.model must be a non-empty string', ` + "`mutation.changes[${i}].model`" + `);
+      throw new ValidationError('Change.model must be a non-empty string', ` + "`" + `mutation.changes[${i}].model` + "`" + `, ErrorCodes.EmptyModel);
     }
     
     // Validate based on action
-    if (change.action === 'insert' && (!Array.isArray(change.set) || change.set.length === 0)) {
-      throw new ValidationError('Insert requires non-empty set', ` + "`mutation.changes[${i}].set`" + `);
+    const hasSets = Array.isArray(change.sets) && change.sets.length > 0;
+    if (change.action === 'insert' && !hasSets) {
+      throw new ValidationError('Insert requires non-empty set', ` + "`" + `mutation.changes[${i}].set` + "`" + `, ErrorCodes.MissingSet);
     }
-    if (change.action === 'update' && (!Array.isArray(change.set) || change.set.length === 0)) {
-      throw new ValidationError('Update requires non-empty set', ` + "`mutation.changes[${i}].set`" + `);
+    if (change.action === 'insert' && change.where) {
+      throw new ValidationError('Insert cannot have where clause', ` + "`" + `mutation.changes[${i}].where` + "`" + `, ErrorCodes.UnexpectedWhere);
+    }
+    if (change.action === 'update' && !hasSets) {
+      throw new ValidationError('Update requires non-empty set', ` + "`" + `mutation.changes[${i}].set` + "`" + `, ErrorCodes.MissingSet);
     }
     if (change.action === 'update' && !change.where) {
-      throw new ValidationError('Update requires where clause', ` + "`mutation.changes[${i}].where`" + `);
+      throw new ValidationError('Update requires where clause', ` + "`" + `mutation.changes[${i}].where` + "`" + `, ErrorCodes.MissingWhere);
+    }
+    if (change.action === 'delete' && hasSets) {
+      throw new ValidationError('Delete cannot have set clause', ` + "`" + `mutation.changes[${i}].set` + "`" + `, ErrorCodes.UnexpectedSet);
     }
     if (change.action === 'delete' && !change.where) {
-      throw new ValidationError('Delete requires where clause', ` + "`mutation.changes[${i}].where`" + `);
+      throw new ValidationError('Delete requires where clause', ` + "`" + `mutation.changes[${i}].where` + "`" + `, ErrorCodes.MissingWhere);
+    }
+    change.sets?.forEach?.((kv: any, j: number) => {
+      if (typeof kv?.field !== 'string' || kv.field.length === 0) {
+        throw new ValidationError('Set clause field must be a non-empty string', ` + "`" + `mutation.changes[${i}].sets[${j}].field` + "`" + `, ErrorCodes.EmptyField);
+      }
+    });
+    if (change.where) {
+      validateFilter(change.where, ` + "`" + `mutation.changes[${i}].where` + "`" + `);
     }
   });
+
+  if (options.strict) {
+    assertStrictMutation(mutation);
+  }
 }
 
-export function validateDependencies(deps: any): asserts deps is Dependencies {
+export function validateTransaction(tx: any, options: ValidateOptions = {}): asserts tx is Transaction {
+  if (typeof tx !== 'object' || tx === null) {
+    throw new ValidationError('Transaction must be an object', 'transaction', ErrorCodes.InvalidType);
+  }
+  if (!Number.isInteger(tx.seq)) {
+    throw new ValidationError('Transaction.seq must be an integer', 'transaction.seq', requiredOrType(tx.seq));
+  }
+  if (tx.seq < 0) {
+    throw new ValidationError('Transaction.seq must be non-negative', 'transaction.seq', ErrorCodes.OutOfRange);
+  }
+  if (tx.committed_at !== undefined && (typeof tx.committed_at !== 'string' || Number.isNaN(Date.parse(tx.committed_at)))) {
+    throw new ValidationError('Transaction.committed_at must be an RFC 3339 date-time', 'transaction.committed_at', ErrorCodes.InvalidDateTime);
+  }
+  if (!Array.isArray(tx.mutations)) {
+    throw new ValidationError('Transaction.mutations must be an array', 'transaction.mutations', requiredOrType(tx.mutations));
+  }
+  if (options.strict) {
+    assertSpecKeys(tx, 'Transaction', 'transaction');
+  }
+
+  // Validate each mutation on its own, then fix the path prefix so errors
+  // point into the transaction
+  tx.mutations.forEach((mutation: any, i: number) => {
+    try {
+      validateMutation(mutation, options);
+    } catch (err) {
+      if (err instanceof ValidationError) {
+        err.path = ` + "`" + `transaction.mutations[${i}]` + "`" + ` + err.path.slice('mutation'.length);
+      }
+      throw err;
+    }
+  });
+}
+
+export function validateDependencies(deps: any, options: ValidateOptions = {}): asserts deps is Dependencies {
   if (typeof deps !== 'object' || deps === null) {
-    throw new ValidationError('Dependencies must be an object', 'dependencies');
+    throw new ValidationError('Dependencies must be an object', 'dependencies', ErrorCodes.InvalidType);
+  }
+  if (deps.spec_version !== undefined) {
+    validateSpecVersion(deps.spec_version, 'Dependencies', 'dependencies.spec_version');
   }
   if (typeof deps.shape_id !== 'string' || !/^s_[0-9a-f]{64}$/.test(deps.shape_id)) {
-    throw new ValidationError('Dependencies.shape_id must match pattern ^s_[0-9a-f]{64}$', 'dependencies.shape_id');
+    throw new ValidationError('Dependencies.shape_id must match pattern ^s_[0-9a-f]{64}$', 'dependencies.shape_id', ErrorCodes.InvalidShapeId);
   }
   if (typeof deps.records !== 'object' || deps.records === null) {
-    throw new ValidationError('Dependencies.records must be an object', 'dependencies.records');
+    throw new ValidationError('Dependencies.records must be an object', 'dependencies.records', requiredOrType(deps.records));
   }
   if (!Array.isArray(deps.filters)) {
-    throw new ValidationError('Dependencies.filters must be an array', 'dependencies.filters');
+    throw new ValidationError('Dependencies.filters must be an array', 'dependencies.filters', requiredOrType(deps.filters));
   }
   if (!Array.isArray(deps.includes)) {
-    throw new ValidationError('Dependencies.includes must be an array', 'dependencies.includes');
+    throw new ValidationError('Dependencies.includes must be an array', 'dependencies.includes', requiredOrType(deps.includes));
+  }
+  if (deps.ttl !== undefined && (!Number.isInteger(deps.ttl) || deps.ttl < 0)) {
+    throw new ValidationError('Dependencies.ttl must be a non-negative integer', 'dependencies.ttl', ErrorCodes.OutOfRange);
+  }
+  if (deps.valid_until !== undefined && (typeof deps.valid_until !== 'string' || Number.isNaN(Date.parse(deps.valid_until)))) {
+    throw new ValidationError('Dependencies.valid_until must be an RFC 3339 date-time', 'dependencies.valid_until', ErrorCodes.InvalidDateTime);
+  }
+  if (deps.soft_delete_field !== undefined && (typeof deps.soft_delete_field !== 'string' || deps.soft_delete_field.length === 0)) {
+    throw new ValidationError('Dependencies.soft_delete_field must be a non-empty string', 'dependencies.soft_delete_field', ErrorCodes.EmptyField);
+  }
+  if (deps.tenant !== undefined && (typeof deps.tenant !== 'string' || deps.tenant.length === 0)) {
+    throw new ValidationError('Dependencies.tenant must be a non-empty string', 'dependencies.tenant', ErrorCodes.EmptyField);
+  }
+  if (deps.policy) {
+    validateFilter(deps.policy, 'dependencies.policy');
+  }
+
+  if (options.strict) {
+    assertStrictDependencies(deps);
   }
 }
 `
@@ -196,31 +966,78 @@ func WriteTypeScriptCanonicalize(dir string) error {
  * RFC 8785: https://tools.ietf.org/html/rfc8785
  */
 
+import { SPEC_DIAGNOSTICS } from './constraints.js';
+
 export function canonicalize(obj: any): string {
-  return JSON.stringify(obj, canonicalReplacer);
+  return serialize(obj) ?? 'null';
 }
 
-function canonicalReplacer(_key: string, value: any): any {
-  if (value && typeof value === 'object' && !Array.isArray(value)) {
-    // Sort object keys
-    const sorted: Record<string, any> = {};
-    Object.keys(value)
-      .sort()
-      .forEach((k) => {
-        sorted[k] = value[k];
-      });
-    return sorted;
+// serialize writes a value the way RFC 8785 requires. It cannot rely on
+// JSON.stringify for objects: JavaScript enumerates integer-like keys such
+// as "1" before all others, whatever order they were added in.
+function serialize(value: any): string | undefined {
+  if (value !== null && typeof value?.toJSON === 'function') {
+    value = value.toJSON();
+  }
+  if (value === undefined || typeof value === 'function' || typeof value === 'symbol') {
+    return undefined;
   }
-  return value;
+  if (typeof value === 'number' && !Number.isFinite(value)) {
+    throw new Error(` + "`Cannot canonicalize non-finite number ${value}`" + `);
+  }
+  if (value === null || typeof value !== 'object') {
+    // JSON.stringify already formats numbers as ECMAScript does (-0 as 0)
+    // and escapes strings as RFC 8785 requires
+    return JSON.stringify(value);
+  }
+  if (Array.isArray(value)) {
+    return '[' + value.map((v) => serialize(v) ?? 'null').join(',') + ']';
+  }
+  // Array.prototype.sort compares UTF-16 code units, the order RFC 8785 uses
+  const members: string[] = [];
+  for (const key of Object.keys(value).sort()) {
+    const member = serialize(value[key]);
+    if (member !== undefined) {
+      members.push(JSON.stringify(key) + ':' + member);
+    }
+  }
+  return '{' + members.join(',') + '}';
 }
 
 export function canonicalizeQueryShape(shape: any): string {
-  // Remove diagnostic fields before canonicalization
+  // Remove the fields the schema marks x-diagnostic, the spec version among
+  // them, before canonicalization
   const cleaned = JSON.parse(JSON.stringify(shape));
-  delete cleaned.orm_version;
-  delete cleaned.sdk_version;
+  for (const field of SPEC_DIAGNOSTICS['Statement'] ?? []) {
+    delete cleaned[field];
+  }
+  // The policy is ANDed into the root where, so it is hashed as part of what
+  // the statement reads
+  if (cleaned?.policy != null && cleaned.query) {
+    cleaned.query.where = cleaned.query.where === undefined
+      ? cleaned.policy
+      : { and: [cleaned.query.where, cleaned.policy] };
+    delete cleaned.policy;
+  }
   return canonicalize(cleaned);
 }
+
+/**
+ * Canonicalize a mutation event so replays of the same event can be
+ * recognised. Every field is kept: tx_id tells apart identical writes from
+ * different transactions, and change order is significant.
+ */
+export function canonicalizeMutation(mutation: any): string {
+  return canonicalize(mutation);
+}
+
+/**
+ * Canonicalize a Dependencies document so its checksum is the same on every
+ * transport. Every field is kept.
+ */
+export function canonicalizeDependencies(deps: any): string {
+  return canonicalize(deps);
+}
 `
 
 	return os.WriteFile(filepath.Join(dir, "canonicalize.ts"), []byte(content), 0644)
@@ -232,17 +1049,146 @@ func WriteTypeScriptShapeId(dir string) error {
  */
 
 import { createHash } from 'crypto';
-import { canonicalizeQueryShape } from './canonicalize.js';
+import { canonicalizeMutation, canonicalizeQueryShape } from './canonicalize.js';
+
+/**
+ * How shape IDs are derived from canonical JSON: prefix, then the lowercase
+ * hex digest under algorithm (a node:crypto hash name), then "_v<version>"
+ * for versions after the first. A spec revision that rolls the hash adds a
+ * config with a new version, so its IDs never collide with existing cache
+ * keys and parseShapeId can tell them apart.
+ */
+export interface ShapeIdConfig {
+  algorithm: string;
+  prefix: string;
+  version: number;
+}
+
+/**
+ * The shape IDs of this spec version: "s_" followed by the hex SHA-256, with
+ * no version suffix. The schema's shape_id pattern admits only these.
+ */
+export const SHAPE_ID_V1: ShapeIdConfig = { algorithm: 'sha256', prefix: 's_', version: 1 };
 
-export function computeShapeId(canonicalJson: string): string {
-  const hash = createHash('sha256').update(canonicalJson, 'utf8').digest('hex');
-  return 's_' + hash;
+export function computeShapeId(canonicalJson: string, config: ShapeIdConfig = SHAPE_ID_V1): string {
+  const hash = createHash(config.algorithm).update(canonicalJson, 'utf8').digest('hex');
+  return config.prefix + hash + versionSuffix(config);
+}
+
+function versionSuffix(config: ShapeIdConfig): string {
+  return config.version > 1 ? ` + "`" + `_v${config.version}` + "`" + ` : '';
+}
+
+/**
+ * Tell which of configs produced id, and its hex digest. Recognizes
+ * SHAPE_ID_V1 IDs unless configs says otherwise. Throws if id matches none of
+ * them.
+ */
+export function parseShapeId(id: string, configs: ShapeIdConfig[] = [SHAPE_ID_V1]): { config: ShapeIdConfig; digest: string } {
+  for (const config of configs) {
+    const suffix = versionSuffix(config);
+    if (!id.startsWith(config.prefix) || !id.endsWith(suffix) || id.length < config.prefix.length + suffix.length) {
+      continue;
+    }
+    const digest = id.slice(config.prefix.length, id.length - suffix.length);
+    const size = createHash(config.algorithm).digest().length;
+    if (digest.length === 2 * size && /^[0-9a-f]*$/.test(digest)) {
+      return { config, digest };
+    }
+  }
+  throw new Error(` + "`" + `Shape ID ${id} matches no known shape ID version` + "`" + `);
 }
 
 export function computeQueryShapeId(shape: any): string {
   const canonical = canonicalizeQueryShape(shape);
   return computeShapeId(canonical);
 }
+
+/**
+ * Compute a mutationId ("m_" + hex SHA-256 of the canonical mutation) that
+ * engines can use to deduplicate replayed events.
+ */
+export function computeMutationId(mutation: any): string {
+  const hash = createHash('sha256').update(canonicalizeMutation(mutation), 'utf8').digest('hex');
+  return 'm_' + hash;
+}
+
+/**
+ * Compute a shapeId that ignores pagination cursors (after/before), so every
+ * page of one query shares a shape family. Page sizes (first/last) are kept.
+ */
+export function computePageInvariantShapeId(shape: any): string {
+  if (!shape || !shape.pagination) {
+    return computeQueryShapeId(shape);
+  }
+  const { after: _after, before: _before, ...pagination } = shape.pagination;
+  const invariant = { ...shape, pagination };
+  if (pagination.first === undefined && pagination.last === undefined) {
+    delete invariant.pagination;
+  }
+  return computeQueryShapeId(invariant);
+}
+
+/**
+ * Compute a shapeId that ignores literal values: every condition value, in
+ * where, having, join, policy and include filters and in subqueries, becomes
+ * a placeholder naming its JSON type ("<string>", "<number>", "<boolean>",
+ * "<array>" or "<object>"). Absent and null values stay absent.
+ */
+export function computeStructuralShapeId(shape: any): string {
+  const structural = shape ? JSON.parse(JSON.stringify(shape)) : shape;
+  structuralizeQuery(structural?.query);
+  structuralizeFilter(structural?.having);
+  structuralizeFilter(structural?.policy);
+  structuralizeIncludes(structural?.includes);
+  return computeQueryShapeId(structural);
+}
+
+function structuralizeQuery(query: any): void {
+  structuralizeFilter(query?.where);
+  for (const join of query?.joins || []) {
+    structuralizeFilter(join?.on);
+  }
+}
+
+function structuralizeIncludes(includes: any): void {
+  for (const include of includes || []) {
+    structuralizeQuery(include?.query);
+    structuralizeIncludes(include?.includes);
+  }
+}
+
+function structuralizeFilter(filter: any): void {
+  if (!filter) {
+    return;
+  }
+  for (const f of [...(filter.and || []), ...(filter.or || []), filter.not]) {
+    structuralizeFilter(f);
+  }
+  for (const condition of filter.conditions || []) {
+    if (condition.value === undefined || condition.value === null) {
+      delete condition.value;
+    } else {
+      condition.value = typePlaceholder(condition.value);
+    }
+    structuralizeQuery(condition.subquery);
+  }
+}
+
+function typePlaceholder(value: unknown): string {
+  if (Array.isArray(value)) {
+    return '<array>';
+  }
+  switch (typeof value) {
+    case 'string':
+      return '<string>';
+    case 'boolean':
+      return '<boolean>';
+    case 'object':
+      return '<object>';
+  }
+  return '<number>';
+}
 `
 
 	return os.WriteFile(filepath.Join(dir, "shapeId.ts"), []byte(content), 0644)
@@ -252,7 +1198,46 @@ func WriteTypeScriptIndex(dir string) error {
 	content := `export * from './validators.js';
 export * from './canonicalize.js';
 export * from './shapeId.js';
+export * from './recordId.js';
+export * from './boundary.js';
+export * from './groupBy.js';
+export * from './windows.js';
+export * from './softDelete.js';
+export * from './tenant.js';
+export * from './policy.js';
+export * from './schemas.js';
+export * from './version.js';
 `
 
 	return os.WriteFile(filepath.Join(dir, "index.ts"), []byte(content), 0644)
 }
+
+// Operator is a custom operator declared in a registry file, as marshaled
+// from the Go testkit's tests.Operators().
+type Operator struct {
+	Name  string `json:"name"`
+	Arity string `json:"arity"`
+}
+
+// WriteTypeScriptOperators writes operators.ts, which registers each custom
+// operator with the TypeScript validators when imported.
+func WriteTypeScriptOperators(dir string, ops []Operator) error {
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	var b strings.Builder
+	b.WriteString(`/**
+ * Custom operators for IncludeKit Universal Format validators
+ * Auto-generated by "codegen operators" from the Go registry - do not edit
+ * Import once at startup so validateStatement checks their values
+ */
+
+import { registerOperator } from './validators.js';
+`)
+	if len(ops) > 0 {
+		b.WriteString("\n")
+	}
+	for _, op := range ops {
+		fmt.Fprintf(&b, "registerOperator('%s', '%s');\n", quote.Replace(op.Name), quote.Replace(op.Arity))
+	}
+
+	return os.WriteFile(filepath.Join(dir, "operators.ts"), []byte(b.String()), 0644)
+}
@@ -0,0 +1,31 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteTypeScriptOperators(t *testing.T) {
+	dir := t.TempDir()
+	ops := []Operator{{Name: "custom:fts", Arity: "one"}, {Name: "custom:o'neil", Arity: "none"}}
+	if err := WriteTypeScriptOperators(dir, ops); err != nil {
+		t.Fatalf("WriteTypeScriptOperators failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "operators.ts"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(data)
+
+	for _, want := range []string{
+		"import { registerOperator } from './validators.js';",
+		"registerOperator('custom:fts', 'one');",
+		`registerOperator('custom:o\'neil', 'none');`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("operators.ts missing %q:\n%s", want, out)
+		}
+	}
+}
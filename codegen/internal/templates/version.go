@@ -0,0 +1,209 @@
+package templates
+
+import (
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// versions are the constants every package exports so callers can tell
+// which schema it implements: the spec version, the schema's $id and the
+// version of the Engine contract. The contract is versioned with the spec,
+// so engines report the spec version as their contract version.
+type versions struct {
+	spec, schemaID, contract string
+}
+
+func schemaVersions(s *parser.Schema) versions {
+	v := s.SpecVersion()
+	return versions{spec: v, schemaID: s.ID, contract: v}
+}
+
+// Package names of a spec version generated beside a newer one carry its
+// Schema.Suffix, so both can be installed and imported side by side.
+
+// GoPackage is the name of the Go types package, e.g. types or typesv0_1_0.
+func GoPackage(s *parser.Schema) string {
+	return "types" + s.Suffix
+}
+
+// Project is the name of a package project or its directory, with the
+// suffix in dashes, e.g. includekit-spec or includekit-spec-v0-1-0.
+func Project(s *parser.Schema, name string) string {
+	if s.Suffix == "" {
+		return name
+	}
+	return name + "-" + strings.ReplaceAll(s.Suffix, "_", "-")
+}
+
+// PythonPackage is the name of the Python package, e.g. includekit_spec
+// or includekit_spec_v0_1_0.
+func PythonPackage(s *parser.Schema) string {
+	if s.Suffix == "" {
+		return "includekit_spec"
+	}
+	return "includekit_spec_" + s.Suffix
+}
+
+// KotlinPackage is the name of the Kotlin package, e.g.
+// dev.includekit.spec or dev.includekit.spec.v0_1_0.
+func KotlinPackage(s *parser.Schema) string {
+	if s.Suffix == "" {
+		return "dev.includekit.spec"
+	}
+	return "dev.includekit.spec." + s.Suffix
+}
+
+// SwiftModule is the name of the Swift module, e.g. IncludeKitSpec or
+// IncludeKitSpecV0_1_0.
+func SwiftModule(s *parser.Schema) string {
+	if s.Suffix == "" {
+		return "IncludeKitSpec"
+	}
+	return "IncludeKitSpecV" + strings.TrimPrefix(s.Suffix, "v")
+}
+
+// GoVersion renders version.go for the Go types package.
+func GoVersion(s *parser.Schema) ([]byte, error) {
+	v := schemaVersions(s)
+	src, err := format.Source([]byte(fmt.Sprintf(`// Code generated by codegen from schema/%s. DO NOT EDIT.
+
+package `+GoPackage(s)+`
+
+const (
+	// SpecVersion is the Universal Format version these types implement.
+	// Engines stamp it into Dependencies.SpecVersion so stored documents can
+	// be upgraded when the format evolves.
+	SpecVersion = %q
+
+	// SchemaID is the $id of the JSON Schema these types are generated from.
+	SchemaID = %q
+
+	// ContractVersion is the version of the Engine contract, which engines
+	// report as VersionInfo.Contract.
+	ContractVersion = %q
+)
+`, filepath.Base(s.Path), v.spec, v.schemaID, v.contract)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to format Go version: %w", err)
+	}
+	return src, nil
+}
+
+// WriteGoVersion generates version.go in dir.
+func WriteGoVersion(dir string, s *parser.Schema) error {
+	src, err := GoVersion(s)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "version.go"), src, 0644)
+}
+
+// WriteTypeScriptVersion generates version.ts for the TypeScript testkit.
+func WriteTypeScriptVersion(dir string, s *parser.Schema) error {
+	v := schemaVersions(s)
+	content := fmt.Sprintf(`/**
+ * Version of IncludeKit Universal Format v%s
+ * Auto-generated from schema/%s
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+/** Universal Format version this package implements, stamped into Dependencies.spec_version */
+export const SPEC_VERSION = %s;
+
+/** $id of the JSON Schema this package is generated from */
+export const SCHEMA_ID = %s;
+
+/** Version of the Engine contract, which engines report as VersionInfo.contract */
+export const CONTRACT_VERSION = %s;
+`, s.Version, filepath.Base(s.Path), tsString(v.spec), tsString(v.schemaID), tsString(v.contract))
+	return os.WriteFile(filepath.Join(dir, "version.ts"), []byte(content), 0644)
+}
+
+// WritePythonVersion generates version.py for the Python package.
+func WritePythonVersion(dir string, s *parser.Schema) error {
+	v := schemaVersions(s)
+	content := fmt.Sprintf(`"""Version of IncludeKit Universal Format v%s.
+
+Auto-generated from schema/%s
+DO NOT EDIT - This file is automatically generated
+"""
+
+SPEC_VERSION = %q
+"""Universal Format version this package implements, stamped into Dependencies.spec_version"""
+
+SCHEMA_ID = %q
+"""$id of the JSON Schema this package is generated from"""
+
+CONTRACT_VERSION = %q
+"""Version of the Engine contract, which engines report as VersionInfo.contract"""
+`, s.Version, filepath.Base(s.Path), v.spec, v.schemaID, v.contract)
+	return os.WriteFile(filepath.Join(dir, "version.py"), []byte(content), 0644)
+}
+
+// WriteKotlinVersion generates Version.kt for the Kotlin models library.
+func WriteKotlinVersion(dir string, s *parser.Schema) error {
+	v := schemaVersions(s)
+	content := fmt.Sprintf(`/**
+ * Version of IncludeKit Universal Format v%s
+ * Auto-generated from schema/%s
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+package %s
+
+/** Universal Format version this library implements, stamped into Dependencies.specVersion */
+const val SPEC_VERSION = %q
+
+/** $id of the JSON Schema this library is generated from */
+const val SCHEMA_ID = %q
+
+/** Version of the Engine contract, which engines report as VersionInfo.contract */
+const val CONTRACT_VERSION = %q
+`, s.Version, filepath.Base(s.Path), KotlinPackage(s), v.spec, v.schemaID, v.contract)
+	return os.WriteFile(filepath.Join(dir, "Version.kt"), []byte(content), 0644)
+}
+
+// WriteSwiftVersion generates Version.swift for the Swift package.
+func WriteSwiftVersion(dir string, s *parser.Schema) error {
+	v := schemaVersions(s)
+	content := fmt.Sprintf(`// Version of IncludeKit Universal Format v%s
+// Auto-generated from schema/%s
+// DO NOT EDIT - This file is automatically generated
+
+/// Universal Format version this package implements, stamped into Dependencies.specVersion
+public let specVersion = %q
+
+/// $id of the JSON Schema this package is generated from
+public let schemaID = %q
+
+/// Version of the Engine contract, which engines report as VersionInfo.contract
+public let contractVersion = %q
+`, s.Version, filepath.Base(s.Path), v.spec, v.schemaID, v.contract)
+	return os.WriteFile(filepath.Join(dir, "Version.swift"), []byte(content), 0644)
+}
+
+// WriteRustVersion generates version.rs for the Rust crate, which lib.rs
+// re-exports.
+func WriteRustVersion(dir string, s *parser.Schema) error {
+	v := schemaVersions(s)
+	content := fmt.Sprintf(`//! Version of IncludeKit Universal Format v%s
+//!
+//! Auto-generated from schema/%s
+//! DO NOT EDIT - This file is automatically generated
+
+/// Universal Format version this crate implements, stamped into Dependencies::spec_version
+pub const SPEC_VERSION: &str = %q;
+
+/// $id of the JSON Schema this crate is generated from
+pub const SCHEMA_ID: &str = %q;
+
+/// Version of the Engine contract, which engines report as VersionInfo.contract
+pub const CONTRACT_VERSION: &str = %q;
+`, s.Version, filepath.Base(s.Path), v.spec, v.schemaID, v.contract)
+	return os.WriteFile(filepath.Join(dir, "version.rs"), []byte(content), 0644)
+}
@@ -0,0 +1,37 @@
+package templates
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+func TestGoVersion(t *testing.T) {
+	// The title carries major.minor only; a patch release is named after
+	// its full version
+	s := &parser.Schema{Path: "schema/v0-1-2.json", ID: "https://example.com/v0-1-2.json", Version: "0.1"}
+	src, err := GoVersion(s)
+	if err != nil {
+		t.Fatalf("GoVersion failed: %v", err)
+	}
+	for _, want := range []string{
+		"// Code generated by codegen from schema/v0-1-2.json. DO NOT EDIT.\n",
+		"\tSpecVersion = \"0.1.2\"\n",
+		"\tSchemaID = \"https://example.com/v0-1-2.json\"\n",
+		"\tContractVersion = \"0.1.2\"\n",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("version.go missing %q:\n%s", want, src)
+		}
+	}
+
+	s.Path = "schema.json"
+	src, err = GoVersion(s)
+	if err != nil {
+		t.Fatalf("GoVersion failed: %v", err)
+	}
+	if !strings.Contains(string(src), "\tSpecVersion = \"0.1.0\"\n") {
+		t.Errorf("version.go without a versioned file name:\n%s", src)
+	}
+}
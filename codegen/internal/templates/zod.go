@@ -0,0 +1,194 @@
+package templates
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// WriteTypeScriptZod generates schemas.ts: a Zod schema for every schema
+// definition, named <Definition>Schema and typed as the matching type from
+// @includekit/spec. Unlike validators.ts it is derived from the schema, so
+// it checks exactly what the schema says: types, required and unknown
+// properties, enums, patterns and bounds, but none of the rules the schema
+// cannot express, such as operator arity.
+//
+// Every schema is lazy, so definitions can refer to each other in any order
+// and recursively.
+func WriteTypeScriptZod(dir string, s *parser.Schema) error {
+	gen := &zodSchemas{schema: s}
+	names := s.Keys("/$defs", s.Definitions)
+
+	var defs strings.Builder
+	for _, name := range names {
+		def, ok := s.Definitions[name].(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("definition %s is not an object", name)
+		}
+		expr, err := gen.zodType("/$defs/"+name, def, "")
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		defs.WriteString("\n")
+		if desc, ok := def["description"].(string); ok {
+			defs.WriteString("/** " + strings.ReplaceAll(desc, "\n", "\n * ") + " */\n")
+		}
+		fmt.Fprintf(&defs, "export const %sSchema: z.ZodType<%s> = z.lazy(() =>\n  %s\n);\n", name, name, expr)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `/**
+ * Zod schemas for IncludeKit Universal Format v%s
+ * Auto-generated from schema/%s
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+import { z } from 'zod';
+import type {
+%s} from '@includekit/spec';
+`, s.Version, filepath.Base(s.Path), "  "+strings.Join(names, ",\n  ")+",\n")
+	if gen.jsonValue {
+		b.WriteString(`
+// A required property of any type: present, but any JSON value
+const jsonValue = z.custom<{} | null>((value) => value !== undefined);
+`)
+	}
+	b.WriteString(defs.String())
+
+	return os.WriteFile(filepath.Join(dir, "schemas.ts"), []byte(b.String()), 0644)
+}
+
+type zodSchemas struct {
+	schema    *parser.Schema
+	jsonValue bool
+}
+
+// zodType returns the Zod expression for the schema at pointer, indented
+// for nesting under indent.
+func (g *zodSchemas) zodType(pointer string, prop map[string]interface{}, indent string) (string, error) {
+	if ref, ok := prop["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, "#/$defs/") + "Schema", nil
+	}
+	if enum, ok := prop["enum"].([]interface{}); ok {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			s, ok := v.(string)
+			if !ok {
+				return "", fmt.Errorf("enum value %v is not a string", v)
+			}
+			values[i] = tsString(s)
+		}
+		return "z.enum([" + strings.Join(values, ", ") + "])", nil
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		variants, ok := prop[key].([]interface{})
+		if !ok {
+			continue
+		}
+		exprs := make([]string, len(variants))
+		for i, v := range variants {
+			variant, _ := v.(map[string]interface{})
+			expr, err := g.zodType(fmt.Sprintf("%s/%s/%d", pointer, key, i), variant, indent)
+			if err != nil {
+				return "", err
+			}
+			exprs[i] = expr
+		}
+		if len(exprs) == 1 {
+			return exprs[0], nil
+		}
+		return "z.union([" + strings.Join(exprs, ", ") + "])", nil
+	}
+
+	switch prop["type"] {
+	case nil:
+		return "z.unknown()", nil
+	case "string":
+		expr := "z.string()"
+		if n, ok := prop["minLength"].(float64); ok {
+			expr += fmt.Sprintf(".min(%v)", n)
+		}
+		if p, ok := prop["pattern"].(string); ok {
+			expr += ".regex(/" + strings.ReplaceAll(p, "/", `\/`) + "/)"
+		}
+		if prop["format"] == "date-time" {
+			expr += ".datetime({ offset: true })"
+		}
+		return expr, nil
+	case "integer", "number":
+		expr := "z.number()"
+		if prop["type"] == "integer" {
+			expr += ".int()"
+		}
+		if n, ok := prop["minimum"].(float64); ok {
+			expr += fmt.Sprintf(".min(%v)", n)
+		}
+		if n, ok := prop["maximum"].(float64); ok {
+			expr += fmt.Sprintf(".max(%v)", n)
+		}
+		return expr, nil
+	case "boolean":
+		return "z.boolean()", nil
+	case "array":
+		items, _ := prop["items"].(map[string]interface{})
+		expr, err := g.zodType(pointer+"/items", items, indent)
+		if err != nil {
+			return "", err
+		}
+		return "z.array(" + expr + ")", nil
+	case "object":
+		if props, ok := prop["properties"].(map[string]interface{}); ok {
+			return g.zodObject(pointer, prop, props, indent)
+		}
+		values, _ := prop["additionalProperties"].(map[string]interface{})
+		expr, err := g.zodType(pointer+"/additionalProperties", values, indent)
+		if err != nil {
+			return "", err
+		}
+		return "z.record(z.string(), " + expr + ")", nil
+	}
+	return "", fmt.Errorf("unsupported schema type %v", prop["type"])
+}
+
+// zodObject renders an object with properties. Unknown properties are
+// rejected when the schema disallows additional properties.
+func (g *zodSchemas) zodObject(pointer string, prop, props map[string]interface{}, indent string) (string, error) {
+	required := map[string]bool{}
+	if req, ok := prop["required"].([]interface{}); ok {
+		for _, r := range req {
+			required[fmt.Sprint(r)] = true
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("z.object({\n")
+	propsPointer := pointer + "/properties"
+	for _, name := range g.schema.Keys(propsPointer, props) {
+		p, _ := props[name].(map[string]interface{})
+		expr, err := g.zodType(propsPointer+"/"+name, p, indent+"  ")
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		switch {
+		case !required[name]:
+			expr += ".optional()"
+		case expr == "z.unknown()":
+			// z.unknown() accepts a missing property
+			expr = "jsonValue"
+			g.jsonValue = true
+		}
+		fmt.Fprintf(&b, "%s    %s: %s,\n", indent, name, expr)
+	}
+	b.WriteString(indent + "  })")
+	if prop["additionalProperties"] == false {
+		b.WriteString(".strict()")
+	}
+	return b.String(), nil
+}
+
+func tsString(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
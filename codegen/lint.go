@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+	"github.com/bold-minds/includekit-spec/codegen/internal/schemalint"
+)
+
+// runLint implements "codegen lint". It prints every problem found in the
+// schema and returns 1 when there are any, 2 on usage or parse errors.
+func runLint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ContinueOnError)
+	schemaPath := fs.String("schema", "schema/v0-1-0.json", "Path to JSON Schema")
+	vectorsDir := fs.String("vectors", "", "Conformance vectors directory enum values must be covered by (default tools/tests/vectors beside the schema's directory)")
+	skip := fs.String("skip", "", "Comma-separated checks not to run ("+strings.Join(schemalint.Checks, ",")+")")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: codegen lint [-schema path] [-vectors dir] [-skip checks]")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return 2
+	}
+
+	s, err := parser.Parse(*schemaPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to parse %s: %v\n", *schemaPath, err)
+		return 2
+	}
+	opts := schemalint.Options{VectorsDir: *vectorsDir}
+	if opts.VectorsDir == "" {
+		opts.VectorsDir = filepath.Join(filepath.Dir(s.Path), "..", "tools", "tests", "vectors")
+	}
+	if *skip != "" {
+		opts.Skip = strings.Split(*skip, ",")
+	}
+
+	problems, err := schemalint.Lint(s, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to lint %s: %v\n", *schemaPath, err)
+		return 2
+	}
+	if len(problems) == 0 {
+		fmt.Println("✓ No schema problems")
+		return 0
+	}
+	for _, p := range problems {
+		fmt.Println(p)
+	}
+	fmt.Fprintf(os.Stderr, "❌ %d schema problems\n", len(problems))
+	return 1
+}
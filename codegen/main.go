@@ -1,63 +1,163 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/bold-minds/includekit-spec/codegen/internal/generators"
 	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
 )
 
 func main() {
-	languages := flag.String("lang", "all", "Languages to generate (all,ts,go,java,dotnet,python,php)")
-	schemaPath := flag.String("schema", "schema/v0-1-0.json", "Path to JSON Schema")
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		os.Exit(runDiff(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "operators" {
+		os.Exit(runOperators(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "lint" {
+		os.Exit(runLint(os.Args[2:]))
+	}
+
+	languages := flag.String("lang", "all", "Languages to generate (all,ts,go,java,dotnet,python,kotlin,swift,rust,proto,openapi,docs,php)")
+	schemaPath := flag.String("schema", "schema/v0-1-0.json", "Path to JSON Schema, or a directory of versioned schemas to take the newest from")
+	specVersion := flag.String("spec-version", "", "Spec version to generate from the schema's directory; older versions get version-suffixed packages")
 	outputDir := flag.String("output", "pkgs", "Output directory")
 	verbose := flag.Bool("v", false, "Verbose output")
+	check := flag.Bool("check", false, "Verify checked-in output matches the schema instead of writing it")
+	parallel := flag.Int("j", runtime.NumCPU(), "Number of languages to generate at once")
+	watch := flag.Bool("watch", false, "Regenerate whenever the schema changes, until interrupted")
+	debounce := flag.Duration("debounce", 200*time.Millisecond, "With -watch, how long the schema must be unchanged before regenerating")
 
 	flag.Parse()
 
+	if *watch {
+		if *check {
+			fmt.Fprintln(os.Stderr, "❌ -watch and -check cannot be combined")
+			os.Exit(2)
+		}
+		if *specVersion != "" {
+			fmt.Fprintln(os.Stderr, "❌ -watch and -spec-version cannot be combined")
+			os.Exit(2)
+		}
+		if info, err := os.Stat(*schemaPath); err == nil && info.IsDir() {
+			fmt.Fprintln(os.Stderr, "❌ -watch needs a schema file, not a directory")
+			os.Exit(2)
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		w := &watcher{
+			schemaPath: *schemaPath,
+			outputDir:  *outputDir,
+			langs:      parseLangs(*languages),
+			parallel:   *parallel,
+			interval:   100 * time.Millisecond,
+			debounce:   *debounce,
+			out:        os.Stdout,
+		}
+		if err := w.run(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "❌ Failed to watch schema: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Println("📦 Generating code from schema...")
 
 	// Parse schema
-	s, err := parser.Parse(*schemaPath)
+	s, err := loadSchema(*schemaPath, *specVersion)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "❌ Failed to parse schema: %v\n", err)
 		os.Exit(1)
 	}
 
 	if *verbose {
-		fmt.Printf("Parsed schema: %s (v%s)\n", s.Title, s.Version)
+		fmt.Printf("Parsed schema: %s (v%s)\n", s.Title, s.SpecVersion())
+		if s.Suffix != "" {
+			fmt.Printf("Generating version-suffixed packages (%s)\n", s.Suffix)
+		}
 	}
 
 	// Determine which languages to generate
 	langs := parseLangs(*languages)
+	if s.Suffix != "" && *languages == "all" {
+		langs = suffixedLangs
+	}
 
-	// Generate for each language
-	for _, lang := range langs {
-		gen := generators.Get(lang)
-		if gen == nil {
-			fmt.Fprintf(os.Stderr, "❌ Unknown language: %s\n", lang)
+	results := generators.Run(s, langs, *outputDir, *parallel, *check)
+	printSummary(os.Stdout, results, *check)
+	for _, r := range results {
+		if r.Err != nil {
 			os.Exit(1)
 		}
+	}
 
-		fmt.Printf("Generating %s...\n", gen.Language())
+	if *check {
+		fmt.Println("✓ Code is up to date!")
+	} else {
+		fmt.Println("✓ Code generation complete!")
+	}
+}
 
-		if err := gen.Generate(s, *outputDir); err != nil {
-			fmt.Fprintf(os.Stderr, "❌ Failed to generate %s: %v\n", lang, err)
-			os.Exit(1)
+// printSummary prints a table of every language's outcome and time,
+// followed by the error of each one that failed.
+func printSummary(w io.Writer, results []generators.Result, check bool) {
+	ok := "✓ generated"
+	if check {
+		ok = "✓ up to date"
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "LANGUAGE\tRESULT\tTIME")
+	for _, r := range results {
+		result := ok
+		if r.Err != nil {
+			result = "❌ failed"
 		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Language, result, r.Duration.Round(time.Millisecond))
+	}
+	tw.Flush()
 
-		fmt.Printf("✓ Generated %s\n", gen.Language())
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(w, "\n❌ %s: %v\n", r.Language, r.Err)
+		}
 	}
+}
+
+// suffixedLangs are the languages with version-suffixed packages, which
+// "all" means for an older spec version.
+var suffixedLangs = []string{"go", "python", "kotlin", "swift", "rust"}
 
-	fmt.Println("✓ Code generation complete!")
+// loadSchema parses the schema at path. With a spec version, or when path
+// is a directory, it picks that version, or the newest, from the versioned
+// schemas in the directory (the schema's own directory for a file).
+func loadSchema(path, version string) (*parser.Schema, error) {
+	dir := path
+	if info, err := os.Stat(path); err != nil || !info.IsDir() {
+		if version == "" {
+			return parser.Parse(path)
+		}
+		dir = filepath.Dir(path)
+	}
+	schemas, err := parser.ParseDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Select(schemas, version)
 }
 
 func parseLangs(input string) []string {
 	if input == "all" {
-		return []string{"typescript", "go"}
+		return []string{"typescript", "go", "python", "kotlin", "swift", "rust", "proto", "openapi", "docs"}
 	}
 	return strings.Split(input, ",")
 }
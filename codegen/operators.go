@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/templates"
+)
+
+// runOperators implements "codegen operators registry.json". It reads the
+// custom operators registered with the Go testkit, as marshaled from
+// tests.Operators(), and writes operators.ts registering them with the
+// TypeScript validators. It returns 1 on an invalid registry, 2 on usage
+// errors.
+func runOperators(args []string) int {
+	fs := flag.NewFlagSet("operators", flag.ContinueOnError)
+	outputDir := fs.String("o", "pkgs/ts/tests/src", "Directory to write operators.ts to")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: codegen operators [-o dir] registry.json")
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	data, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to read %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	var ops []templates.Operator
+	if err := json.Unmarshal(data, &ops); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to parse %s: %v\n", fs.Arg(0), err)
+		return 1
+	}
+	for _, op := range ops {
+		if !strings.HasPrefix(op.Name, "custom:") || len(op.Name) == len("custom:") {
+			fmt.Fprintf(os.Stderr, "❌ Operator %q must be named custom:<name>\n", op.Name)
+			return 1
+		}
+		switch op.Arity {
+		case "none", "one", "pair", "list":
+		default:
+			fmt.Fprintf(os.Stderr, "❌ Operator %s: unknown arity %q\n", op.Name, op.Arity)
+			return 1
+		}
+	}
+
+	if err := templates.WriteTypeScriptOperators(*outputDir, ops); err != nil {
+		fmt.Fprintf(os.Stderr, "❌ Failed to write operators.ts: %v\n", err)
+		return 1
+	}
+	fmt.Printf("✓ Wrote %d custom operators\n", len(ops))
+	return 0
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/codegen/internal/generators"
+	"github.com/bold-minds/includekit-spec/codegen/internal/parser"
+)
+
+// watcher implements "codegen -watch". It polls the schema's directory,
+// which needs no platform file-notification API, and regenerates once the
+// directory has been quiet for the debounce period, so an editor's burst
+// of writes triggers one run. Changes that leave the schema's content as
+// it was, such as edits to other files in the directory, regenerate
+// nothing.
+type watcher struct {
+	schemaPath string
+	outputDir  string
+	langs      []string
+	parallel   int           // languages generated at once
+	interval   time.Duration // how often the directory is polled
+	debounce   time.Duration // quiet period after the last change
+	out        io.Writer
+
+	generated [sha256.Size]byte // schema content last generated from
+}
+
+// run generates once, then regenerates on changes until ctx is done.
+// Generation errors are reported and the watch continues, so a schema
+// saved mid-edit does not end it.
+func (w *watcher) run(ctx context.Context) error {
+	dir := filepath.Dir(w.schemaPath)
+	last, err := snapshot(dir)
+	if err != nil {
+		return err
+	}
+	w.regenerate()
+	fmt.Fprintf(w.out, "👀 Watching %s for changes (Ctrl-C to stop)...\n", dir)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	var changed time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			current, err := snapshot(dir)
+			if err != nil {
+				fmt.Fprintf(w.out, "❌ Failed to read %s: %v\n", dir, err)
+				continue
+			}
+			if !sameSnapshot(last, current) {
+				last, changed = current, now
+				continue
+			}
+			if !changed.IsZero() && now.Sub(changed) >= w.debounce {
+				changed = time.Time{}
+				w.regenerate()
+			}
+		}
+	}
+}
+
+// regenerate generates every language from the schema when its content
+// differs from the last generation, printing each one's time.
+func (w *watcher) regenerate() {
+	data, err := os.ReadFile(w.schemaPath)
+	if err != nil {
+		fmt.Fprintf(w.out, "❌ Failed to read schema: %v\n", err)
+		return
+	}
+	sum := sha256.Sum256(data)
+	if sum == w.generated {
+		return
+	}
+
+	fmt.Fprintf(w.out, "📦 Generating code from %s...\n", w.schemaPath)
+	start := time.Now()
+	s, err := parser.Parse(w.schemaPath)
+	if err != nil {
+		fmt.Fprintf(w.out, "❌ Failed to parse schema: %v\n", err)
+		return
+	}
+	results := generators.Run(s, w.langs, w.outputDir, w.parallel, false)
+	printSummary(w.out, results, false)
+	// A failed language is retried on the next change even if the schema
+	// is saved unchanged
+	failed := false
+	for _, r := range results {
+		failed = failed || r.Err != nil
+	}
+	if !failed {
+		w.generated = sum
+	}
+	fmt.Fprintf(w.out, "✓ Regenerated in %s\n", time.Since(start).Round(time.Millisecond))
+}
+
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// snapshot records the size and modification time of every file in dir.
+func snapshot(dir string) (map[string]fileState, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]fileState, len(entries))
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			// Removed since ReadDir; the next poll sees it gone
+			continue
+		}
+		if !info.IsDir() {
+			files[e.Name()] = fileState{size: info.Size(), modTime: info.ModTime()}
+		}
+	}
+	return files, nil
+}
+
+func sameSnapshot(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, state := range a {
+		if other, ok := b[name]; !ok || other.size != state.size || !other.modTime.Equal(state.modTime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer collects watcher output written from its goroutine.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema", "schema.json")
+	if err := os.MkdirAll(filepath.Dir(schemaPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	schema := func(prop string) string {
+		return `{"title": "Test Schema v0.1", "$defs": {"Query": {"type": "object", "properties": {"` + prop + `": {"type": "string"}}}}}`
+	}
+	write(schemaPath, schema("model"))
+
+	out := &syncBuffer{}
+	w := &watcher{
+		schemaPath: schemaPath,
+		outputDir:  filepath.Join(dir, "out"),
+		langs:      []string{"python"},
+		interval:   5 * time.Millisecond,
+		debounce:   50 * time.Millisecond,
+		out:        out,
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.run(ctx) }()
+	defer func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("run() error = %v", err)
+		}
+	}()
+
+	models := filepath.Join(dir, "out", "python", "includekit_spec", "models.py")
+	waitFor := func(what string, ok func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !ok() {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s; output:\n%s", what, out)
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+	generated := func(text string) func() bool {
+		return func() bool {
+			data, _ := os.ReadFile(models)
+			return strings.Contains(string(data), text)
+		}
+	}
+	runs := func() int { return strings.Count(out.String(), "✓ Regenerated in") }
+
+	waitFor("initial generation", generated("model: Optional[str]"))
+	waitFor("watching", func() bool { return strings.Contains(out.String(), "👀 Watching") })
+
+	// A burst of saves regenerates once, from the last one
+	for _, prop := range []string{"a", "b", "limit"} {
+		write(schemaPath, schema(prop))
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitFor("regeneration", generated("limit: Optional[str]"))
+	time.Sleep(100 * time.Millisecond)
+	if got := runs(); got != 2 {
+		t.Errorf("%d generations, want 2:\n%s", got, out)
+	}
+	if !strings.Contains(out.String(), "Python    ✓ generated  ") {
+		t.Errorf("no per-language timing:\n%s", out)
+	}
+
+	// Other files in the directory and an unchanged schema regenerate nothing
+	write(filepath.Join(dir, "schema", "README.md"), "notes")
+	write(schemaPath, schema("limit"))
+	time.Sleep(150 * time.Millisecond)
+	if got := runs(); got != 2 {
+		t.Errorf("%d generations after unrelated changes, want 2:\n%s", got, out)
+	}
+
+	// An invalid schema is reported and the watch continues
+	write(schemaPath, "{")
+	waitFor("parse error", func() bool { return strings.Contains(out.String(), "❌ Failed to parse schema") })
+	write(schemaPath, schema("offset"))
+	waitFor("recovery", generated("offset: Optional[str]"))
+}
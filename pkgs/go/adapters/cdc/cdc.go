@@ -0,0 +1,294 @@
+// Package cdc translates change data capture events into Mutations, so
+// invalidation can be driven straight from a replication stream.
+//
+// Events follow the Debezium envelope: before and after row images, an op of
+// "c" (create), "u" (update), "d" (delete) or "r" (snapshot read), and the
+// source table. Other WAL decoders can fill an Event directly.
+//
+//	{"before": {"id": 7, "title": "Old"}, "after": {"id": 7, "title": "New"},
+//	 "source": {"table": "posts"}, "op": "u"}
+//	-> {"model": "posts", "action": "update",
+//	    "sets": [{"field": "title", "value": "New"}],
+//	    "where": {"conditions": [{"field": "id", "op": "eq", "value": 7}]}}
+package cdc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/bold-minds/includekit-spec/go/diff"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// UnavailableValue is the placeholder Debezium writes for column values it
+// did not capture, such as unchanged TOASTed columns in PostgreSQL. Columns
+// holding it are left out of Sets.
+const UnavailableValue = "__debezium_unavailable_value"
+
+// Event is a change event payload.
+type Event struct {
+	Before      map[string]any `json:"before"`
+	After       map[string]any `json:"after"`
+	Source      Source         `json:"source"`
+	Op          string         `json:"op"` // "c" | "u" | "d" | "r"
+	Transaction *Transaction   `json:"transaction,omitempty"`
+}
+
+// Source identifies where an event was captured.
+type Source struct {
+	Table  string `json:"table"`
+	Schema string `json:"schema,omitempty"`
+	DB     string `json:"db,omitempty"`
+	TxID   any    `json:"txId,omitempty"` // PostgreSQL transaction ID
+}
+
+// Transaction is the transaction metadata Debezium adds when
+// provide.transaction.metadata is enabled.
+type Transaction struct {
+	ID string `json:"id"`
+}
+
+// Decode parses a change event, with or without the JSON converter's
+// schema/payload wrapper. Numbers are coerced with types.Value: integral
+// numbers that fit an int64 decode as int64, other numbers as float64.
+//
+// A tombstone (a null value, sent after deletes for log compaction) decodes
+// to an Event with no Op, which Change skips.
+func Decode(data []byte) (Event, error) {
+	var raw any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&raw); err != nil {
+		return Event{}, fmt.Errorf("cdc: invalid event: %w", err)
+	}
+	if raw == nil {
+		return Event{}, nil
+	}
+	obj, ok := raw.(map[string]any)
+	if !ok {
+		return Event{}, fmt.Errorf("cdc: event must be a JSON object")
+	}
+	if payload, wrapped := obj["payload"]; wrapped {
+		if _, hasSchema := obj["schema"]; hasSchema {
+			if payload == nil {
+				return Event{}, nil
+			}
+			if obj, ok = payload.(map[string]any); !ok {
+				return Event{}, fmt.Errorf("cdc: event payload must be a JSON object")
+			}
+		}
+	}
+
+	var e Event
+	var err error
+	if e.Before, err = image(obj, "before"); err != nil {
+		return Event{}, err
+	}
+	if e.After, err = image(obj, "after"); err != nil {
+		return Event{}, err
+	}
+	e.Op, _ = obj["op"].(string)
+
+	meta, err := json.Marshal(map[string]any{"source": obj["source"], "transaction": obj["transaction"]})
+	if err != nil {
+		return Event{}, fmt.Errorf("cdc: %w", err)
+	}
+	if err := json.Unmarshal(meta, &e); err != nil {
+		return Event{}, fmt.Errorf("cdc: invalid event metadata: %w", err)
+	}
+	if source, ok := obj["source"].(map[string]any); ok {
+		if n, ok := source["txId"].(json.Number); ok {
+			if e.Source.TxID, err = types.Value(n); err != nil {
+				return Event{}, fmt.Errorf("cdc: invalid txId: %w", err)
+			}
+		}
+	}
+	return e, nil
+}
+
+// image returns the row image under key, or nil when it is null or absent.
+func image(obj map[string]any, key string) (map[string]any, error) {
+	switch v := obj[key].(type) {
+	case nil:
+		return nil, nil
+	case map[string]any:
+		row, err := types.Value(v)
+		if err != nil {
+			return nil, fmt.Errorf("cdc: invalid %s image: %w", key, err)
+		}
+		return row.(map[string]any), nil
+	}
+	return nil, fmt.Errorf("cdc: %s must be a JSON object", key)
+}
+
+// Config maps tables to models and names their primary keys.
+type Config struct {
+	// PrimaryKeys lists the key columns of each table (default: "id").
+	PrimaryKeys map[string][]string
+	// Models renames tables to model names (default: the table name).
+	Models map[string]string
+}
+
+// Change translates an event into a Change with diff.Differ, keyed by the
+// table's primary key:
+//   - "c" becomes an insert setting every column of the after image
+//   - "u" becomes an update setting the columns that changed (every non-key
+//     column when there is no before image), where the before primary key
+//     matches
+//   - "d" becomes a delete where the before primary key matches
+//
+// Columns holding UnavailableValue in the after image are left alone.
+//
+// It reports false for events that change nothing: snapshot reads,
+// tombstones and updates that leave every column as it was.
+//
+// Returns an error for other ops, events with no table, and images missing
+// a primary key column.
+func (c Config) Change(e Event) (types.Change, bool, error) {
+	switch e.Op {
+	case "", "r":
+		return types.Change{}, false, nil
+	case "c", "u", "d":
+	default:
+		return types.Change{}, false, fmt.Errorf("cdc: op %q is not supported", e.Op)
+	}
+	if e.Source.Table == "" {
+		return types.Change{}, false, fmt.Errorf("cdc: event has no source table")
+	}
+
+	table, model := e.Source.Table, c.model(e.Source.Table)
+	var keys []string
+	var before, after map[string]any
+	switch e.Op {
+	case "c":
+		if e.After == nil {
+			return types.Change{}, false, fmt.Errorf("cdc: create on %s has no after image", table)
+		}
+		after = captured(e.After)
+
+	case "u":
+		if e.After == nil {
+			return types.Change{}, false, fmt.Errorf("cdc: update on %s has no after image", table)
+		}
+		before, after = e.Before, captured(e.After)
+		row := before
+		if row == nil {
+			row = e.After
+		}
+		var err error
+		if keys, err = c.keys(table, row); err != nil {
+			return types.Change{}, false, err
+		}
+		if before == nil {
+			// Without a before image only the key is known not to change.
+			before = map[string]any{}
+			for _, key := range keys {
+				before[key] = e.After[key]
+			}
+		}
+
+	case "d":
+		if e.Before == nil {
+			return types.Change{}, false, fmt.Errorf("cdc: delete on %s has no before image", table)
+		}
+		before = e.Before
+		var err error
+		if keys, err = c.keys(table, before); err != nil {
+			return types.Change{}, false, err
+		}
+	}
+
+	differ := diff.Differ{Keys: map[string][]string{model: keys}}
+	changes := differ.Changes(model, before, after)
+	if len(changes) == 0 {
+		return types.Change{}, false, nil
+	}
+	return changes[0], true, nil
+}
+
+// Mutations translates a stream of events into Mutations, one for each run
+// of consecutive events in the same transaction. Events without a
+// transaction each become a Mutation of their own, and events that change
+// nothing are dropped.
+func (c Config) Mutations(events []Event) ([]types.Mutation, error) {
+	mutations := []types.Mutation{}
+	lastTx := ""
+	for i, e := range events {
+		change, ok, err := c.Change(e)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: %w", i, err)
+		}
+		if !ok {
+			continue
+		}
+
+		txID := e.txID()
+		if txID != "" && txID == lastTx {
+			last := &mutations[len(mutations)-1]
+			last.Changes = append(last.Changes, change)
+			continue
+		}
+		m := types.Mutation{Changes: []types.Change{change}}
+		if txID != "" {
+			m.TxID = &txID
+		}
+		mutations = append(mutations, m)
+		lastTx = txID
+	}
+	return mutations, nil
+}
+
+// txID returns the event's transaction ID, preferring transaction metadata
+// over the source.
+func (e Event) txID() string {
+	if e.Transaction != nil && e.Transaction.ID != "" {
+		return e.Transaction.ID
+	}
+	switch id := e.Source.TxID.(type) {
+	case nil:
+		return ""
+	case string:
+		return id
+	case int64:
+		return strconv.FormatInt(id, 10)
+	}
+	return fmt.Sprint(e.Source.TxID)
+}
+
+func (c Config) model(table string) string {
+	if model, ok := c.Models[table]; ok {
+		return model
+	}
+	return table
+}
+
+// keys returns the primary key columns of table, checking row holds them
+// all, where diff.Differ would fall back to matching every column.
+func (c Config) keys(table string, row map[string]any) ([]string, error) {
+	keys, ok := c.PrimaryKeys[table]
+	if !ok {
+		keys = []string{"id"}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("cdc: table %s has no primary key columns", table)
+	}
+	for _, key := range keys {
+		if value, ok := row[key]; !ok || value == nil || value == UnavailableValue {
+			return nil, fmt.Errorf("cdc: %s image is missing primary key column %q", table, key)
+		}
+	}
+	return keys, nil
+}
+
+// captured returns row without the columns Debezium did not capture.
+func captured(row map[string]any) map[string]any {
+	out := make(map[string]any, len(row))
+	for field, value := range row {
+		if value != UnavailableValue {
+			out[field] = value
+		}
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+package cdc_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/adapters/cdc"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestDecode(t *testing.T) {
+	wrapped := `{"schema": {"type": "struct"}, "payload": {
+		"before": null,
+		"after": {"id": 9007199254740993, "title": "Hello", "score": 1.5, "tags": ["a"]},
+		"source": {"table": "posts", "schema": "public", "txId": 571},
+		"op": "c", "ts_ms": 1700000000000}}`
+
+	e, err := cdc.Decode([]byte(wrapped))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if e.Op != "c" || e.Source.Table != "posts" || e.Source.Schema != "public" || e.Source.TxID != int64(571) {
+		t.Errorf("Decode() = %+v", e)
+	}
+	if e.Before != nil {
+		t.Errorf("Before = %v, want nil", e.Before)
+	}
+	want := map[string]any{"id": int64(9007199254740993), "title": "Hello", "score": 1.5, "tags": []any{"a"}}
+	if !reflect.DeepEqual(e.After, want) {
+		t.Errorf("After = %#v, want %#v", e.After, want)
+	}
+
+	bare := `{"before": {"id": 1}, "after": null, "source": {"table": "posts"}, "op": "d", "transaction": {"id": "571:53195832"}}`
+	e, err = cdc.Decode([]byte(bare))
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if e.Op != "d" || e.Transaction == nil || e.Transaction.ID != "571:53195832" || e.After != nil {
+		t.Errorf("Decode() = %+v", e)
+	}
+
+	for _, tombstone := range []string{`null`, `{"schema": null, "payload": null}`} {
+		e, err := cdc.Decode([]byte(tombstone))
+		if err != nil || e.Op != "" {
+			t.Errorf("Decode(%s) = %+v, %v, want empty event", tombstone, e, err)
+		}
+	}
+	for _, bad := range []string{`[1]`, `{"before": 1}`, `{"op": "c"`} {
+		if _, err := cdc.Decode([]byte(bad)); err == nil {
+			t.Errorf("Decode(%s) should fail", bad)
+		}
+	}
+}
+
+func TestChange(t *testing.T) {
+	config := cdc.Config{
+		PrimaryKeys: map[string][]string{"memberships": {"org_id", "user_id"}},
+		Models:      map[string]string{"blog_posts": "posts"},
+	}
+
+	tcs := []struct {
+		name  string
+		event cdc.Event
+		want  types.Change
+	}{
+		{
+			name:  "create",
+			event: cdc.Event{Op: "c", Source: cdc.Source{Table: "blog_posts"}, After: map[string]any{"title": "Hi", "id": int64(1)}},
+			want:  types.Change{Model: "posts", Action: "insert", Sets: []types.KV{{Field: "id", Value: int64(1)}, {Field: "title", Value: "Hi"}}},
+		},
+		{
+			name: "update sets changed columns",
+			event: cdc.Event{Op: "u", Source: cdc.Source{Table: "blog_posts"},
+				Before: map[string]any{"id": int64(1), "title": "Hi", "body": "x"},
+				After:  map[string]any{"id": int64(1), "title": "Hello", "body": cdc.UnavailableValue},
+			},
+			want: types.Change{Model: "posts", Action: "update",
+				Sets:  []types.KV{{Field: "title", Value: "Hello"}},
+				Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", int64(1))}},
+			},
+		},
+		{
+			name:  "update without before image",
+			event: cdc.Event{Op: "u", Source: cdc.Source{Table: "posts"}, After: map[string]any{"id": "p1", "title": "Hello"}},
+			want: types.Change{Model: "posts", Action: "update",
+				Sets:  []types.KV{{Field: "title", Value: "Hello"}},
+				Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}},
+			},
+		},
+		{
+			name: "update of the key matches the old key",
+			event: cdc.Event{Op: "u", Source: cdc.Source{Table: "memberships"},
+				Before: map[string]any{"org_id": "o1", "user_id": "u1", "role": "admin"},
+				After:  map[string]any{"org_id": "o2", "user_id": "u1", "role": "admin"},
+			},
+			want: types.Change{Model: "memberships", Action: "update",
+				Sets:  []types.KV{{Field: "org_id", Value: "o2"}},
+				Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("org_id", "o1"), types.Eq("user_id", "u1")}},
+			},
+		},
+		{
+			name:  "delete",
+			event: cdc.Event{Op: "d", Source: cdc.Source{Table: "posts"}, Before: map[string]any{"id": int64(3), "title": "Bye"}},
+			want:  types.Change{Model: "posts", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", int64(3))}}},
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := config.Change(tt.event)
+			if err != nil || !ok {
+				t.Fatalf("Change() = %v, %v", ok, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := tests.Canonicalize(got)
+				wantJSON, _ := tests.Canonicalize(tt.want)
+				t.Errorf("Change() = %s, want %s", gotJSON, wantJSON)
+			}
+			mutation := types.Mutation{Changes: []types.Change{got}}
+			if err := tests.ValidateMutationEvent(&mutation); err != nil {
+				t.Errorf("invalid mutation: %v", err)
+			}
+		})
+	}
+}
+
+func TestChangeSkips(t *testing.T) {
+	row := map[string]any{"id": int64(1), "title": "Hi"}
+	for _, e := range []cdc.Event{
+		{Op: "r", Source: cdc.Source{Table: "posts"}, After: row},
+		{},
+		{Op: "u", Source: cdc.Source{Table: "posts"}, Before: row, After: row},
+	} {
+		if _, ok, err := (cdc.Config{}).Change(e); ok || err != nil {
+			t.Errorf("Change(%+v) = %v, %v, want skipped", e, ok, err)
+		}
+	}
+}
+
+func TestChangeErrors(t *testing.T) {
+	for _, e := range []cdc.Event{
+		{Op: "t", Source: cdc.Source{Table: "posts"}},
+		{Op: "c", After: map[string]any{"id": 1}},
+		{Op: "c", Source: cdc.Source{Table: "posts"}},
+		{Op: "d", Source: cdc.Source{Table: "posts"}},
+		{Op: "d", Source: cdc.Source{Table: "posts"}, Before: map[string]any{"title": "no key"}},
+		{Op: "u", Source: cdc.Source{Table: "posts"}, Before: map[string]any{"id": cdc.UnavailableValue}, After: map[string]any{"id": 1, "title": "x"}},
+	} {
+		if _, _, err := (cdc.Config{}).Change(e); err == nil {
+			t.Errorf("Change(%+v) should fail", e)
+		}
+	}
+}
+
+func TestMutations(t *testing.T) {
+	tx := func(id string) *cdc.Transaction { return &cdc.Transaction{ID: id} }
+	events := []cdc.Event{
+		{Op: "c", Source: cdc.Source{Table: "posts"}, After: map[string]any{"id": int64(1)}, Transaction: tx("t1")},
+		{Op: "r", Source: cdc.Source{Table: "posts"}, After: map[string]any{"id": int64(5)}, Transaction: tx("t1")},
+		{Op: "d", Source: cdc.Source{Table: "posts"}, Before: map[string]any{"id": int64(2)}, Transaction: tx("t1")},
+		{Op: "c", Source: cdc.Source{Table: "posts", TxID: int64(42)}, After: map[string]any{"id": int64(3)}},
+		{Op: "c", Source: cdc.Source{Table: "posts"}, After: map[string]any{"id": int64(4)}},
+		{Op: "c", Source: cdc.Source{Table: "posts"}, After: map[string]any{"id": int64(5)}},
+	}
+
+	mutations, err := (cdc.Config{}).Mutations(events)
+	if err != nil {
+		t.Fatalf("Mutations failed: %v", err)
+	}
+	if len(mutations) != 4 {
+		t.Fatalf("got %d mutations, want 4", len(mutations))
+	}
+	if id, _ := mutations[0].GetTxID(); id != "t1" || len(mutations[0].Changes) != 2 {
+		t.Errorf("mutations[0] = tx %q with %d changes, want t1 with 2", id, len(mutations[0].Changes))
+	}
+	if id, _ := mutations[1].GetTxID(); id != "42" {
+		t.Errorf("mutations[1] tx = %q, want 42", id)
+	}
+	if _, ok := mutations[2].GetTxID(); ok || len(mutations[3].Changes) != 1 {
+		t.Errorf("events without a transaction should not be grouped: %+v", mutations[2:])
+	}
+
+	if _, err := (cdc.Config{}).Mutations([]cdc.Event{{Op: "x", Source: cdc.Source{Table: "posts"}}}); err == nil {
+		t.Error("Mutations should fail on an invalid event")
+	}
+}
@@ -0,0 +1,310 @@
+// Package builders provides a fluent API for constructing IncludeKit
+// Universal Format statements without manual pointer plumbing:
+//
+//	stmt := builders.NewStatement("posts").
+//	    Where(types.Eq("status", "published")).
+//	    OrderBy("createdAt", builders.Desc).
+//	    Limit(10).
+//	    Paginate(builders.First(20)).
+//	    Build()
+//
+// Builders are mutable and not safe for concurrent use. Build returns a copy,
+// so later calls on the builder never change previously built values.
+package builders
+
+import "github.com/bold-minds/includekit-spec/go/types"
+
+// Direction is the sort direction passed to OrderBy.
+type Direction int
+
+const (
+	// Asc sorts ascending. It leaves OrderBy.Descending unset, the default.
+	Asc Direction = iota
+	// Desc sorts descending.
+	Desc
+)
+
+// PageOption configures the pagination set by Paginate.
+type PageOption func(*types.Pagination)
+
+// First sets the forward page size.
+func First(n int) PageOption {
+	return func(p *types.Pagination) { p.First = &n }
+}
+
+// Last sets the backward page size.
+func Last(n int) PageOption {
+	return func(p *types.Pagination) { p.Last = &n }
+}
+
+// After sets the forward cursor.
+func After(cursor string) PageOption {
+	return func(p *types.Pagination) { p.After = &cursor }
+}
+
+// Before sets the backward cursor.
+func Before(cursor string) PageOption {
+	return func(p *types.Pagination) { p.Before = &cursor }
+}
+
+// And combines filters with AND.
+func And(filters ...types.Filter) types.Filter {
+	return types.Filter{And: &filters}
+}
+
+// Or combines filters with OR.
+func Or(filters ...types.Filter) types.Filter {
+	return types.Filter{Or: &filters}
+}
+
+// Not negates a filter.
+func Not(filter types.Filter) types.Filter {
+	return types.Filter{Not: &filter}
+}
+
+// Conditions wraps conditions in a filter, for use with And, Or and Not.
+func Conditions(conds ...types.Condition) types.Filter {
+	return types.Filter{Conditions: &conds}
+}
+
+// StatementBuilder builds a Statement.
+type StatementBuilder struct {
+	stmt *types.Statement
+}
+
+// NewStatement starts a statement reading model.
+func NewStatement(model string) *StatementBuilder {
+	return &StatementBuilder{stmt: types.NewStatement(types.WithModel(model))}
+}
+
+// Fields sets the selected fields.
+func (b *StatementBuilder) Fields(fields ...string) *StatementBuilder {
+	types.WithFields(fields...)(b.stmt)
+	return b
+}
+
+// Where ANDs conditions into the top level of the query filter.
+func (b *StatementBuilder) Where(conds ...types.Condition) *StatementBuilder {
+	types.WithConditions(conds...)(b.stmt)
+	return b
+}
+
+// WhereFilter sets the query filter, replacing any previous filter.
+func (b *StatementBuilder) WhereFilter(filter types.Filter) *StatementBuilder {
+	types.WithWhere(filter)(b.stmt)
+	return b
+}
+
+// OrderBy appends an ordering.
+func (b *StatementBuilder) OrderBy(field string, dir Direction) *StatementBuilder {
+	types.WithOrderBy(orderBy(field, dir))(b.stmt)
+	return b
+}
+
+// Limit sets the query limit.
+func (b *StatementBuilder) Limit(n int) *StatementBuilder {
+	types.WithLimit(n)(b.stmt)
+	return b
+}
+
+// Offset sets the query offset.
+func (b *StatementBuilder) Offset(n int) *StatementBuilder {
+	types.WithOffset(n)(b.stmt)
+	return b
+}
+
+// Distinct sets the distinct fields.
+func (b *StatementBuilder) Distinct(fields ...string) *StatementBuilder {
+	types.WithDistinct(fields...)(b.stmt)
+	return b
+}
+
+// DistinctOn keeps one row per combination of fields, the first in
+// tieBreak order, e.g. the latest post of each author:
+// DistinctOn([]string{"authorId"}, types.OrderBy{Field: "createdAt", Descending: &desc}).
+func (b *StatementBuilder) DistinctOn(fields []string, tieBreak ...types.OrderBy) *StatementBuilder {
+	types.WithDistinctOn(fields, tieBreak...)(b.stmt)
+	return b
+}
+
+// IncludeDeleted reads soft-deleted rows alongside live ones.
+func (b *StatementBuilder) IncludeDeleted() *StatementBuilder {
+	types.WithIncludeDeleted()(b.stmt)
+	return b
+}
+
+// OnlyDeleted reads only soft-deleted rows, as a trash view does.
+func (b *StatementBuilder) OnlyDeleted() *StatementBuilder {
+	types.WithOnlyDeleted()(b.stmt)
+	return b
+}
+
+// Aggregate appends aggregate projections, e.g. types.Count("").As("n").
+func (b *StatementBuilder) Aggregate(aggs ...types.Aggregate) *StatementBuilder {
+	types.WithAggregations(aggs...)(b.stmt)
+	return b
+}
+
+// Join appends joins, e.g. types.InnerJoin("author", types.Eq("active", true)).
+func (b *StatementBuilder) Join(joins ...types.Join) *StatementBuilder {
+	types.WithJoins(joins...)(b.stmt)
+	return b
+}
+
+// Window appends window function columns, e.g.
+// types.RowNumber("rank").PartitionedBy("authorId").
+func (b *StatementBuilder) Window(windows ...types.Window) *StatementBuilder {
+	types.WithWindows(windows...)(b.stmt)
+	return b
+}
+
+// Paginate sets the pagination, replacing any previous pagination.
+func (b *StatementBuilder) Paginate(opts ...PageOption) *StatementBuilder {
+	var p types.Pagination
+	for _, opt := range opts {
+		opt(&p)
+	}
+	types.WithPagination(p)(b.stmt)
+	return b
+}
+
+// GroupBy sets the group-by fields.
+func (b *StatementBuilder) GroupBy(fields ...string) *StatementBuilder {
+	types.WithGroupBy(fields...)(b.stmt)
+	return b
+}
+
+// Having sets the having filter to the given conditions.
+func (b *StatementBuilder) Having(conds ...types.Condition) *StatementBuilder {
+	types.WithHaving(Conditions(conds...))(b.stmt)
+	return b
+}
+
+// Policy sets the row-level visibility predicate to the given conditions.
+func (b *StatementBuilder) Policy(conds ...types.Condition) *StatementBuilder {
+	types.WithPolicy(Conditions(conds...))(b.stmt)
+	return b
+}
+
+// Tenant scopes the statement to a tenant.
+func (b *StatementBuilder) Tenant(tenant string) *StatementBuilder {
+	types.WithTenant(tenant)(b.stmt)
+	return b
+}
+
+// Include appends a relation include.
+func (b *StatementBuilder) Include(include *IncludeBuilder) *StatementBuilder {
+	types.WithInclude(include.Build())(b.stmt)
+	return b
+}
+
+// Build returns a copy of the statement built so far.
+func (b *StatementBuilder) Build() *types.Statement {
+	stmt := *b.stmt
+	if stmt.Query != nil {
+		q := *stmt.Query
+		if q.Where != nil {
+			where := *q.Where
+			q.Where = &where
+		}
+		stmt.Query = &q
+	}
+	if stmt.Pagination != nil {
+		p := *stmt.Pagination
+		stmt.Pagination = &p
+	}
+	stmt.Includes = append([]types.Include(nil), stmt.Includes...)
+	return &stmt
+}
+
+// IncludeBuilder builds an Include.
+type IncludeBuilder struct {
+	include types.Include
+	query   *StatementBuilder
+}
+
+// NewInclude starts an include of relation. Without Some, Every or None it
+// loads the relation data.
+func NewInclude(relation string) *IncludeBuilder {
+	return &IncludeBuilder{query: NewStatement(relation)}
+}
+
+// Fields sets the loaded relation fields.
+func (b *IncludeBuilder) Fields(fields ...string) *IncludeBuilder {
+	b.query.Fields(fields...)
+	return b
+}
+
+// Where ANDs conditions into the relation filter.
+func (b *IncludeBuilder) Where(conds ...types.Condition) *IncludeBuilder {
+	b.query.Where(conds...)
+	return b
+}
+
+// WhereFilter sets the relation filter, replacing any previous filter.
+func (b *IncludeBuilder) WhereFilter(filter types.Filter) *IncludeBuilder {
+	b.query.WhereFilter(filter)
+	return b
+}
+
+// OrderBy appends an ordering of the relation rows.
+func (b *IncludeBuilder) OrderBy(field string, dir Direction) *IncludeBuilder {
+	b.query.OrderBy(field, dir)
+	return b
+}
+
+// Limit sets the relation row limit.
+func (b *IncludeBuilder) Limit(n int) *IncludeBuilder {
+	b.query.Limit(n)
+	return b
+}
+
+// Offset sets the relation row offset.
+func (b *IncludeBuilder) Offset(n int) *IncludeBuilder {
+	b.query.Offset(n)
+	return b
+}
+
+// Some filters parents to those with at least one matching relation row.
+func (b *IncludeBuilder) Some() *IncludeBuilder { return b.kind("some") }
+
+// Every filters parents to those whose relation rows all match.
+func (b *IncludeBuilder) Every() *IncludeBuilder { return b.kind("every") }
+
+// None filters parents to those with no matching relation row.
+func (b *IncludeBuilder) None() *IncludeBuilder { return b.kind("none") }
+
+// Count filters parents by the number of matching relation rows, compared
+// with op ("eq", "ne", "gt", "gte", "lt" or "lte") against n.
+func (b *IncludeBuilder) Count(op string, n int) *IncludeBuilder {
+	b.include.Count = &types.RelationCount{Op: op, Value: n}
+	return b.kind("count")
+}
+
+// Include appends a nested include.
+func (b *IncludeBuilder) Include(include *IncludeBuilder) *IncludeBuilder {
+	b.include.Includes = append(b.include.Includes, include.Build())
+	return b
+}
+
+// Build returns a copy of the include built so far.
+func (b *IncludeBuilder) Build() types.Include {
+	include := b.include
+	include.Query = b.query.Build().Query
+	include.Includes = append([]types.Include(nil), include.Includes...)
+	return include
+}
+
+func (b *IncludeBuilder) kind(kind string) *IncludeBuilder {
+	b.include.Kind = &kind
+	return b
+}
+
+func orderBy(field string, dir Direction) types.OrderBy {
+	ob := types.OrderBy{Field: field}
+	if dir == Desc {
+		desc := true
+		ob.Descending = &desc
+	}
+	return ob
+}
@@ -0,0 +1,207 @@
+package builders_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/builders"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestStatementBuilder(t *testing.T) {
+	got := builders.NewStatement("posts").
+		Fields("id", "title").
+		Where(types.Eq("status", "published")).
+		Where(types.Gt("views", 100)).
+		OrderBy("createdAt", builders.Desc).
+		OrderBy("id", builders.Asc).
+		Limit(10).
+		Paginate(builders.First(20), builders.After("eyJpZCI6MX0=")).
+		Include(builders.NewInclude("comments").
+			Where(types.Eq("approved", true)).
+			Limit(5).
+			Include(builders.NewInclude("author").Fields("name"))).
+		Include(builders.NewInclude("tags").Where(types.Eq("name", "go")).Some()).
+		Build()
+
+	desc := true
+	limit, commentLimit, first := 10, 5, 20
+	after := "eyJpZCI6MX0="
+	some := "some"
+	want := &types.Statement{
+		Query: &types.Query{
+			Model:  "posts",
+			Fields: &[]string{"id", "title"},
+			Where: &types.Filter{Conditions: &[]types.Condition{
+				types.Eq("status", "published"),
+				types.Gt("views", 100),
+			}},
+			OrderBy: &[]types.OrderBy{{Field: "createdAt", Descending: &desc}, {Field: "id"}},
+			Limit:   &limit,
+		},
+		Pagination: &types.Pagination{First: &first, After: &after},
+		Includes: []types.Include{
+			{
+				Query: &types.Query{
+					Model: "comments",
+					Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("approved", true)}},
+					Limit: &commentLimit,
+				},
+				Includes: []types.Include{{Query: &types.Query{Model: "author", Fields: &[]string{"name"}}}},
+			},
+			{
+				Query: &types.Query{
+					Model: "tags",
+					Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("name", "go")}},
+				},
+				Kind: &some,
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("Build() =\n  %s\nwant\n  %s", gotJSON, wantJSON)
+	}
+	if err := tests.ValidateQueryShape(got); err != nil {
+		t.Errorf("built statement is invalid: %v", err)
+	}
+}
+
+func TestBuildReturnsIndependentCopies(t *testing.T) {
+	b := builders.NewStatement("posts").Where(types.Eq("a", 1)).Limit(1)
+	first := b.Build()
+	b.Where(types.Eq("b", 2)).Limit(2).Paginate(builders.Last(5))
+
+	if n := len(first.Query.Where.GetConditions()); n != 1 {
+		t.Errorf("first build has %d conditions, want 1", n)
+	}
+	if *first.Query.Limit != 1 || first.Pagination != nil {
+		t.Errorf("first build changed: %+v", first.Query)
+	}
+}
+
+func TestIncludeCount(t *testing.T) {
+	got := builders.NewInclude("posts").Where(types.Eq("published", true)).Count("gt", 5).Build()
+
+	count := "count"
+	want := types.Include{
+		Query: &types.Query{
+			Model: "posts",
+			Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("published", true)}},
+		},
+		Kind:  &count,
+		Count: &types.RelationCount{Op: "gt", Value: 5},
+	}
+	if !got.Equal(&want) {
+		t.Errorf("Count built %+v, want %+v", got, want)
+	}
+}
+
+func TestWindow(t *testing.T) {
+	rank := types.RowNumber("rank").PartitionedBy("authorId").OrderedBy(types.OrderBy{Field: "views"})
+	running := types.WindowOf(types.Sum("views"), "running_views")
+	got := builders.NewStatement("posts").Window(rank).Window(running).Build()
+
+	want := types.Statement{Query: &types.Query{Model: "posts", Windows: &[]types.Window{rank, running}}}
+	if !got.Equal(&want) {
+		t.Errorf("Window built %+v, want %+v", got, want)
+	}
+}
+
+func TestDistinctOn(t *testing.T) {
+	desc := true
+	latest := types.OrderBy{Field: "createdAt", Descending: &desc}
+	got := builders.NewStatement("posts").DistinctOn([]string{"authorId"}, latest).Build()
+
+	want := types.Statement{Query: &types.Query{Model: "posts", DistinctOn: &types.DistinctOn{
+		Fields:  []string{"authorId"},
+		OrderBy: []types.OrderBy{{Field: "authorId"}, latest},
+	}}}
+	if !got.Equal(&want) {
+		t.Errorf("DistinctOn built %+v, want %+v", got, want)
+	}
+}
+
+func TestDeleted(t *testing.T) {
+	for _, tt := range []struct {
+		b    *builders.StatementBuilder
+		want string
+	}{
+		{builders.NewStatement("posts").IncludeDeleted(), "include"},
+		{builders.NewStatement("posts").OnlyDeleted(), "only"},
+	} {
+		got := tt.b.Build()
+		if deleted, ok := got.Query.GetDeleted(); !ok || deleted != tt.want {
+			t.Errorf("Deleted = %q, want %q", deleted, tt.want)
+		}
+		if err := tests.ValidateQueryShape(got); err != nil {
+			t.Errorf("built statement is invalid: %v", err)
+		}
+	}
+}
+
+func TestTenant(t *testing.T) {
+	got := builders.NewStatement("posts").Tenant("acme").Build()
+	if tenant, ok := got.GetTenant(); !ok || tenant != "acme" {
+		t.Errorf("Tenant = %q, want %q", tenant, "acme")
+	}
+	if err := tests.ValidateQueryShape(got); err != nil {
+		t.Errorf("built statement is invalid: %v", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	got := builders.NewStatement("posts").
+		Where(types.Eq("published", true)).
+		Policy(types.Eq("orgId", "o1")).
+		Build()
+	want := &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}}
+	if !got.GetPolicy().Equal(want) {
+		t.Errorf("Policy = %+v, want %+v", got.GetPolicy(), want)
+	}
+	if (*got.Query.Where.Conditions)[0].Field != "published" {
+		t.Errorf("Policy should leave where alone, got %+v", got.Query.Where)
+	}
+	if err := tests.ValidateQueryShape(got); err != nil {
+		t.Errorf("built statement is invalid: %v", err)
+	}
+}
+
+func TestFilterHelpers(t *testing.T) {
+	got := builders.NewStatement("posts").
+		WhereFilter(builders.Or(
+			builders.Conditions(types.Eq("status", "draft")),
+			builders.Not(builders.Conditions(types.IsNull("publishedAt"))),
+		)).
+		GroupBy("authorId").
+		Having(types.Gt("count", 1)).
+		Build()
+
+	if err := tests.ValidateQueryShape(got); err != nil {
+		t.Fatalf("built statement is invalid: %v", err)
+	}
+	if or := got.Query.Where.GetOr(); len(or) != 2 || or[1].Not == nil {
+		t.Errorf("unexpected where: %+v", got.Query.Where)
+	}
+	if got.Having == nil || len(got.Having.GetConditions()) != 1 {
+		t.Errorf("unexpected having: %+v", got.Having)
+	}
+}
+
+func ExampleNewStatement() {
+	stmt := builders.NewStatement("posts").
+		Where(types.Eq("status", "published")).
+		OrderBy("createdAt", builders.Desc).
+		Limit(10).
+		Paginate(builders.First(20)).
+		Build()
+
+	data, _ := json.Marshal(stmt)
+	fmt.Println(string(data))
+	// Output: {"query":{"model":"posts","where":{"conditions":[{"field":"status","op":"eq","value":"published"}]},"order_by":[{"field":"createdAt","descending":true}],"limit":10},"pagination":{"first":20}}
+}
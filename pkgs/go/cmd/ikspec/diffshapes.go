@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// shapeDiff is the output of diff-shapes with -format json.
+type shapeDiff struct {
+	A              shapeIDs          `json:"a"`
+	B              shapeIDs          `json:"b"`
+	Same           bool              `json:"same"`
+	SameStructure  bool              `json:"same_structure"`
+	Equivalent     bool              `json:"equivalent"`
+	Differences    []tests.ShapeDiff `json:"differences"`
+	EquivalentDiff string            `json:"equivalent_diff,omitempty"`
+}
+
+type shapeIDs struct {
+	Input             string `json:"input"`
+	ShapeID           string `json:"shape_id"`
+	StructuralShapeID string `json:"structural_shape_id"`
+}
+
+func runDiffShapes(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ikspec diff-shapes", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	strict := fs.Bool("strict", false, "Reject input outside the pure spec subset")
+	format := fs.String("format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if fs.NArg() != 2 {
+		fmt.Fprintln(stderr, "ikspec: diff-shapes needs two statement files")
+		return 2
+	}
+	if fs.Arg(0) == "-" && fs.Arg(1) == "-" {
+		fmt.Fprintln(stderr, "ikspec: only one statement can be read from stdin")
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "ikspec: unknown format %q\n", *format)
+		return 2
+	}
+
+	var opts []tests.ValidateOption
+	if *strict {
+		opts = append(opts, tests.Strict())
+	}
+	var stmts [2]*types.Statement
+	var ids [2]shapeIDs
+	for i := range stmts {
+		doc, res := load(fs.Arg(i), "statement", stdin, opts)
+		if doc == nil {
+			printLoadError(stdout, stderr, *format, res)
+			return 2
+		}
+		stmts[i] = doc.(*types.Statement)
+		ids[i].Input = fs.Arg(i)
+		var err error
+		if ids[i].ShapeID, err = tests.ComputeQueryShapeID(stmts[i]); err == nil {
+			ids[i].StructuralShapeID, err = tests.ComputeStructuralShapeID(stmts[i])
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", fs.Arg(i), err)
+			return 2
+		}
+	}
+
+	diffs, err := tests.DiffShapes(stmts[0], stmts[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "ikspec: %v\n", err)
+		return 2
+	}
+	if diffs == nil {
+		diffs = []tests.ShapeDiff{}
+	}
+	out := shapeDiff{
+		A:             ids[0],
+		B:             ids[1],
+		Same:          ids[0].ShapeID == ids[1].ShapeID,
+		SameStructure: ids[0].StructuralShapeID == ids[1].StructuralShapeID,
+		Differences:   diffs,
+	}
+	out.Equivalent, out.EquivalentDiff = tests.Equivalent(stmts[0], stmts[1])
+
+	if *format == "json" {
+		data, _ := json.Marshal(out)
+		fmt.Fprintf(stdout, "%s\n", data)
+	} else {
+		printShapeDiff(stdout, out)
+	}
+	if !out.Same {
+		return 1
+	}
+	return 0
+}
+
+// printShapeDiff prints both shape IDs, every difference between the
+// canonical forms and, when the shapes differ, hints at the kind of
+// difference.
+func printShapeDiff(w io.Writer, d shapeDiff) {
+	fmt.Fprintf(w, "a: %s %s\nb: %s %s\n", d.A.ShapeID, d.A.Input, d.B.ShapeID, d.B.Input)
+	if d.Same {
+		fmt.Fprintln(w, "same shape ID")
+		return
+	}
+
+	fmt.Fprintln(w)
+	for _, diff := range d.Differences {
+		fmt.Fprintln(w, diff)
+	}
+	switch {
+	case d.Equivalent:
+		fmt.Fprintln(w, "\nEquivalent reads: the statements differ only in the order of conditions,")
+		fmt.Fprintln(w, "includes, joins or field lists, all of which are hashed as written.")
+	case d.SameStructure:
+		fmt.Fprintln(w, "\nSame structural shape ID: the statements differ only in condition values.")
+	}
+}
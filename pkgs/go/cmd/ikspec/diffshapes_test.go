@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestDiffShapes(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.json", `{"query":{"model":"Post","where":{"conditions":[{"field":"views","op":"gt","value":10}]},"limit":20}}`)
+	b := writeFile(t, dir, "b.json", `{"query":{"model":"Post","where":{"conditions":[{"field":"views","op":"gt","value":"10"}]},"limit":20}}`)
+
+	status, out, errOut := runIkspec(t, "", "diff-shapes", a, b)
+	if status != 1 {
+		t.Errorf("status = %d, want 1 (%s)", status, errOut)
+	}
+	for _, want := range []string{
+		"a: s_",
+		"b: s_",
+		`query.where.conditions[0].value: 10 != "10"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output lacks %q:\n%s", want, out)
+		}
+	}
+
+	status, out, _ = runIkspec(t, "", "diff-shapes", "-format", "json", a, b)
+	var got shapeDiff
+	if err := json.Unmarshal([]byte(out), &got); err != nil || status != 1 {
+		t.Fatalf("diff-shapes -format json = %d %q: %v", status, out, err)
+	}
+	if got.Same || got.Equivalent || len(got.Differences) != 1 || got.Differences[0].Path != "query.where.conditions[0].value" {
+		t.Errorf("diff = %+v", got)
+	}
+
+	status, out, _ = runIkspec(t, "", "diff-shapes", a, a)
+	if status != 0 || !strings.HasSuffix(out, "same shape ID\n") {
+		t.Errorf("diff-shapes of a file with itself = %d %q", status, out)
+	}
+}
+
+func TestDiffShapesHints(t *testing.T) {
+	dir := t.TempDir()
+	ab := writeFile(t, dir, "ab.json", `{"query":{"model":"Post","where":{"conditions":[{"field":"a","op":"eq","value":1},{"field":"b","op":"eq","value":2}]}}}`)
+	ba := writeFile(t, dir, "ba.json", `{"query":{"model":"Post","where":{"conditions":[{"field":"b","op":"eq","value":2},{"field":"a","op":"eq","value":1}]}}}`)
+	ab2 := writeFile(t, dir, "ab2.json", `{"query":{"model":"Post","where":{"conditions":[{"field":"a","op":"eq","value":3},{"field":"b","op":"eq","value":4}]}}}`)
+
+	_, out, _ := runIkspec(t, "", "diff-shapes", ab, ba)
+	if !strings.Contains(out, "Equivalent reads") {
+		t.Errorf("commuted conditions:\n%s", out)
+	}
+	_, out, _ = runIkspec(t, "", "diff-shapes", ab, ab2)
+	if !strings.Contains(out, "Same structural shape ID") {
+		t.Errorf("changed values:\n%s", out)
+	}
+}
+
+func TestDiffShapesErrors(t *testing.T) {
+	dir := t.TempDir()
+	a := writeFile(t, dir, "a.json", `{"query":{"model":"Post"}}`)
+	bad := writeFile(t, dir, "bad.json", `{"query":{"model":""}}`)
+
+	for _, args := range [][]string{
+		{"diff-shapes", a},
+		{"diff-shapes", "-", "-"},
+		{"diff-shapes", "-format", "yaml", a, a},
+		{"diff-shapes", a, bad},
+	} {
+		if status, _, _ := runIkspec(t, "", args...); status != 2 {
+			t.Errorf("ikspec %v: status = %d, want 2", args, status)
+		}
+	}
+}
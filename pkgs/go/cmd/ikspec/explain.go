@@ -0,0 +1,135 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/invalidate"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// explanation is the output of explain with -format json.
+type explanation struct {
+	Invalidate bool                `json:"invalidate"`
+	Reasons    []invalidate.Reason `json:"reasons"`
+}
+
+// ruleText says in words what a change did to fire each rule.
+var ruleText = map[invalidate.Rule]string{
+	invalidate.RuleRecordMembership:   "may select or set a tracked record",
+	invalidate.RuleFilterBounds:       "may move a row into, out of or within a filter",
+	invalidate.RuleRelationBounds:     "may change the rows of an include",
+	invalidate.RulePaginationBoundary: "may add, remove or move a row at or before the page's last row",
+	invalidate.RuleGroupBy:            "may add a group, move a row between groups or empty a group",
+}
+
+func runExplain(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("ikspec explain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	depsPath := fs.String("deps", "", "Dependencies file (required; - for stdin)")
+	mutationPath := fs.String("mutation", "", "Mutation file (required; - for stdin)")
+	model := fs.String("model", "", "Root model of the shape; empty applies the root-model rules to every tracked model")
+	schemaPath := fs.String("schema", "", "App schema file resolving relations and primary keys")
+	strict := fs.Bool("strict", false, "Reject input outside the pure spec subset")
+	format := fs.String("format", "text", "Output format: text or json")
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	if *depsPath == "" || *mutationPath == "" {
+		fmt.Fprintln(stderr, "ikspec: explain needs -deps and -mutation")
+		return 2
+	}
+	if *depsPath == "-" && *mutationPath == "-" {
+		fmt.Fprintln(stderr, "ikspec: only one of -deps and -mutation can read stdin")
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "ikspec: unknown format %q\n", *format)
+		return 2
+	}
+
+	var opts []tests.ValidateOption
+	if *strict {
+		opts = append(opts, tests.Strict())
+	}
+	var explainOpts []invalidate.Option
+	if *schemaPath != "" {
+		data, err := readInput(*schemaPath, stdin)
+		if err != nil {
+			fmt.Fprintf(stderr, "ikspec: %v\n", err)
+			return 1
+		}
+		var schema mock.AppSchema
+		if err := json.Unmarshal(data, &schema); err != nil {
+			fmt.Fprintf(stderr, "ikspec: %s: %v\n", *schemaPath, err)
+			return 1
+		}
+		explainOpts = append(explainOpts, invalidate.WithSchema(schema))
+	}
+
+	deps, res := load(*depsPath, "dependencies", stdin, opts)
+	if deps == nil {
+		return printLoadError(stdout, stderr, *format, res)
+	}
+	m, res := load(*mutationPath, "mutation", stdin, opts)
+	if m == nil {
+		return printLoadError(stdout, stderr, *format, res)
+	}
+
+	mutation := *m.(*types.Mutation)
+	shape := invalidate.Shape{Model: *model, Dependencies: *deps.(*types.Dependencies)}
+	reasons := invalidate.Explain(shape, mutation, explainOpts...)
+	if reasons == nil {
+		reasons = []invalidate.Reason{}
+	}
+
+	if *format == "json" {
+		data, _ := json.Marshal(explanation{Invalidate: len(reasons) > 0, Reasons: reasons})
+		fmt.Fprintf(stdout, "%s\n", data)
+		return 0
+	}
+	printExplanation(stdout, mutation, reasons)
+	return 0
+}
+
+func printLoadError(stdout, stderr io.Writer, format string, res result) int {
+	if format == "json" {
+		data, _ := json.Marshal(res)
+		fmt.Fprintf(stdout, "%s\n", data)
+	} else {
+		printErrors(stderr, res)
+	}
+	return 1
+}
+
+// printExplanation prints whether the mutation invalidates the shape and,
+// for each rule that fires, the change that fires it and why.
+func printExplanation(w io.Writer, m types.Mutation, reasons []invalidate.Reason) {
+	if len(reasons) == 0 {
+		fmt.Fprintln(w, "invalidates: no\n  no rule fires for any change")
+		return
+	}
+
+	fmt.Fprintln(w, "invalidates: yes")
+	for _, r := range reasons {
+		change := m.Changes[r.ChangeIndex]
+		line := fmt.Sprintf("  change %d (%s %s): %s", r.ChangeIndex, change.Action, change.Model, r.Rule)
+		if r.Path != "" {
+			line += " at " + r.Path
+		}
+		line += ": " + ruleText[r.Rule]
+		if len(r.RecordIDs) > 0 {
+			line += " (" + strings.Join(r.RecordIDs, ", ") + ")"
+		}
+		fmt.Fprintln(w, line)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests/invalidate"
+)
+
+const explainDeps = `{
+	"shape_id": "s_0000000000000000000000000000000000000000000000000000000000000000",
+	"records": {"Post": ["p1", "p2"]},
+	"filters": [{"conditions": [{"field": "published", "op": "eq", "value": true}]}],
+	"includes": []
+}`
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestExplain(t *testing.T) {
+	dir := t.TempDir()
+	deps := writeFile(t, dir, "deps.json", explainDeps)
+	update := `{"changes":[{"model":"Post","action":"update","sets":[{"field":"title","value":"x"}],"where":{"conditions":[{"field":"id","op":"eq","value":"p1"}]}}]}`
+
+	status, out, errOut := runIkspec(t, update, "explain", "-deps", deps, "-mutation", "-", "-model", "Post")
+	want := "invalidates: yes\n  change 0 (update Post): record_membership: may select or set a tracked record (p1)\n"
+	if status != 0 || out != want {
+		t.Errorf("explain = %d %q %q, want %q", status, out, errOut, want)
+	}
+
+	status, out, _ = runIkspec(t, update, "explain", "-deps", deps, "-mutation", "-", "-format", "json")
+	var got explanation
+	if err := json.Unmarshal([]byte(out), &got); err != nil || status != 0 {
+		t.Fatalf("explain -format json = %d %q: %v", status, out, err)
+	}
+	if !got.Invalidate || len(got.Reasons) != 1 || got.Reasons[0].Rule != invalidate.RuleRecordMembership {
+		t.Errorf("explanation = %+v", got)
+	}
+
+	other := `{"changes":[{"model":"User","action":"insert","sets":[{"field":"id","value":"u1"}]}]}`
+	status, out, _ = runIkspec(t, other, "explain", "-deps", deps, "-mutation", "-", "-model", "Post", "-format", "json")
+	if status != 0 || out != `{"invalidate":false,"reasons":[]}`+"\n" {
+		t.Errorf("explain of an unrelated change = %d %q", status, out)
+	}
+	status, out, _ = runIkspec(t, other, "explain", "-deps", deps, "-mutation", "-", "-model", "Post")
+	if status != 0 || !strings.HasPrefix(out, "invalidates: no\n") {
+		t.Errorf("explain of an unrelated change = %d %q", status, out)
+	}
+}
+
+func TestExplainWithSchema(t *testing.T) {
+	dir := t.TempDir()
+	deps := writeFile(t, dir, "deps.json", `{"shape_id":"s_`+strings.Repeat("0", 64)+`","records":{"OrderLine":["[\"o1\",1]"]},"filters":[],"includes":[]}`)
+	schema := writeFile(t, dir, "schema.json", `{"version":1,"models":[{"name":"OrderLine","id":{"kind":"string","fields":["orderId","lineNo"]}}]}`)
+	mutation := writeFile(t, dir, "mutation.json", `{"changes":[{"model":"OrderLine","action":"delete","where":{"conditions":[{"field":"orderId","op":"eq","value":"o1"},{"field":"lineNo","op":"eq","value":2}]}}]}`)
+
+	// Without the schema the key is "id", so the where cannot rule the
+	// tracked line out; with it, the where pins another line.
+	_, withoutSchema, _ := runIkspec(t, "", "explain", "-deps", deps, "-mutation", mutation, "-model", "OrderLine")
+	_, withSchema, _ := runIkspec(t, "", "explain", "-deps", deps, "-mutation", mutation, "-model", "OrderLine", "-schema", schema)
+	if !strings.Contains(withoutSchema, "record_membership") {
+		t.Errorf("without a schema: %q", withoutSchema)
+	}
+	if strings.Contains(withSchema, "record_membership") {
+		t.Errorf("with a schema: %q", withSchema)
+	}
+}
+
+func TestExplainErrors(t *testing.T) {
+	dir := t.TempDir()
+	deps := writeFile(t, dir, "deps.json", explainDeps)
+	mutation := writeFile(t, dir, "mutation.json", `{"changes":[{"model":"Post","action":"upsert"}]}`)
+
+	for _, args := range [][]string{
+		{"explain"},
+		{"explain", "-deps", deps},
+		{"explain", "-deps", "-", "-mutation", "-"},
+		{"explain", "-deps", deps, "-mutation", mutation, "-format", "yaml"},
+	} {
+		if status, _, _ := runIkspec(t, "", args...); status != 2 {
+			t.Errorf("ikspec %v: status = %d, want 2", args, status)
+		}
+	}
+
+	status, _, errOut := runIkspec(t, "", "explain", "-deps", deps, "-mutation", mutation)
+	if status != 1 || !strings.HasPrefix(errOut, mutation+": IK") {
+		t.Errorf("explain of an invalid mutation = %d %q", status, errOut)
+	}
+}
@@ -0,0 +1,316 @@
+// Command ikspec validates Universal Format documents, prints their
+// canonical JSON and computes their shape or mutation IDs:
+//
+//	ikspec validate [-kind k] [-strict] [-format text|json] [file ...]
+//	ikspec canonicalize [-kind k] [-strict] [-format text|json] [file ...]
+//	ikspec shape-id [-kind k] [-strict] [-format text|json] [file ...]
+//	ikspec explain -deps file -mutation file [-model m] [-schema file] [-strict] [-format text|json]
+//	ikspec diff-shapes [-strict] [-format text|json] a.json b.json
+//
+// Each file holds one JSON document; with no files, or "-", ikspec reads
+// standard input. The kind is statement, mutation, transaction or
+// dependencies; by default it is guessed from the document's top-level
+// fields. shape-id prints the shape ID of a statement and the mutation ID of
+// a mutation. explain runs the reference invalidation algorithm (see
+// package invalidate) and tells whether, and by which rules, a mutation
+// invalidates the shape that recorded the dependencies. diff-shapes lists
+// the differences between the canonical forms of two statements, which is
+// why their shape IDs differ. With -format json, ikspec prints one JSON
+// object per document, explanation or diff for machine consumption.
+//
+// ikspec exits 1 if any document is invalid and 2 on a usage error, except
+// diff-shapes, which exits 1 if the shape IDs differ and 2 on an invalid
+// statement, like diff(1).
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+const usage = `usage: ikspec <command> [flags] [file ...]
+
+Commands:
+  validate      check documents against the Universal Format
+  canonicalize  print the canonical (JCS) JSON of documents
+  shape-id      print the shape ID of statements or mutation ID of mutations
+  explain       tell whether and why a mutation invalidates a shape's dependencies
+  diff-shapes   show why two statements have different shape IDs
+
+Run "ikspec <command> -h" for the command's flags.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// result is the outcome for one document, printed as a line of text or, with
+// -format json, as a JSON object.
+type result struct {
+	Input      string        `json:"input"`
+	Kind       string        `json:"kind"`
+	Valid      bool          `json:"valid"`
+	Errors     []errorResult `json:"errors,omitempty"`
+	Canonical  string        `json:"canonical,omitempty"`
+	ShapeID    string        `json:"shape_id,omitempty"`
+	MutationID string        `json:"mutation_id,omitempty"`
+}
+
+type errorResult struct {
+	Code    tests.ErrorCode `json:"code,omitempty"`
+	Message string          `json:"message"`
+	Path    string          `json:"path,omitempty"`
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	command := args[0]
+	switch command {
+	case "validate", "canonicalize", "shape-id":
+	case "explain":
+		return runExplain(args[1:], stdin, stdout, stderr)
+	case "diff-shapes":
+		return runDiffShapes(args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "ikspec: unknown command %q\n\n%s", command, usage)
+		return 2
+	}
+
+	fs := flag.NewFlagSet("ikspec "+command, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	kind := fs.String("kind", "auto", "Document kind: statement, mutation, transaction, dependencies or auto")
+	strict := fs.Bool("strict", false, "Reject input outside the pure spec subset")
+	format := fs.String("format", "text", "Output format: text or json")
+	if err := fs.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+	switch *kind {
+	case "auto", "statement", "mutation", "transaction", "dependencies":
+	default:
+		fmt.Fprintf(stderr, "ikspec: unknown kind %q\n", *kind)
+		return 2
+	}
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(stderr, "ikspec: unknown format %q\n", *format)
+		return 2
+	}
+
+	var opts []tests.ValidateOption
+	if *strict {
+		opts = append(opts, tests.Strict())
+	}
+
+	inputs := fs.Args()
+	if len(inputs) == 0 {
+		inputs = []string{"-"}
+	}
+	status := 0
+	for _, input := range inputs {
+		res := process(command, input, *kind, stdin, opts)
+		if !res.Valid {
+			status = 1
+		}
+		if *format == "json" {
+			data, _ := json.Marshal(res)
+			fmt.Fprintf(stdout, "%s\n", data)
+		} else {
+			printText(stdout, stderr, command, res, len(inputs) > 1)
+		}
+	}
+	return status
+}
+
+func process(command, input, kind string, stdin io.Reader, opts []tests.ValidateOption) result {
+	doc, res := load(input, kind, stdin, opts)
+	if doc == nil {
+		return res
+	}
+
+	var err error
+	switch command {
+	case "canonicalize":
+		res.Canonical, err = canonicalize(doc)
+	case "shape-id":
+		switch d := doc.(type) {
+		case *types.Statement:
+			res.ShapeID, err = tests.ComputeQueryShapeID(d)
+		case *types.Mutation:
+			res.MutationID, err = tests.ComputeMutationID(d)
+		default:
+			err = fmt.Errorf("shape-id needs a statement or mutation, not a %s", res.Kind)
+		}
+	}
+	if err != nil {
+		res.Errors = []errorResult{{Message: err.Error()}}
+		return res
+	}
+	res.Valid = true
+	return res
+}
+
+// load reads and decodes the document of kind in input. It returns a nil
+// document and the errors in the result if the document is invalid.
+func load(input, kind string, stdin io.Reader, opts []tests.ValidateOption) (any, result) {
+	res := result{Input: input, Kind: kind}
+	data, err := readInput(input, stdin)
+	if err != nil {
+		res.Errors = []errorResult{{Message: err.Error()}}
+		return nil, res
+	}
+	if res.Kind == "auto" {
+		res.Kind = detectKind(data)
+	}
+
+	doc, err := decode(res.Kind, data, opts)
+	if err != nil {
+		res.Errors = validationErrors(res.Kind, data, opts, err)
+		return nil, res
+	}
+	return doc, res
+}
+
+func readInput(input string, stdin io.Reader) ([]byte, error) {
+	if input == "-" {
+		return io.ReadAll(stdin)
+	}
+	return os.ReadFile(input)
+}
+
+// detectKind guesses the kind of a document from its top-level fields,
+// falling back to statement.
+func detectKind(data []byte) string {
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return "statement"
+	}
+	switch {
+	case fields["changes"] != nil:
+		return "mutation"
+	case fields["mutations"] != nil:
+		return "transaction"
+	case fields["shape_id"] != nil, fields["records"] != nil:
+		return "dependencies"
+	}
+	return "statement"
+}
+
+func decode(kind string, data []byte, opts []tests.ValidateOption) (any, error) {
+	r := bytes.NewReader(data)
+	switch kind {
+	case "mutation":
+		return tests.DecodeMutation(r, opts...)
+	case "transaction":
+		return tests.DecodeTransaction(r, opts...)
+	case "dependencies":
+		return tests.DecodeDependencies(r, opts...)
+	}
+	return tests.DecodeStatement(r, opts...)
+}
+
+// validationErrors lists every violation in a document that failed to
+// decode, or just err when the document is not well-formed.
+func validationErrors(kind string, data []byte, opts []tests.ValidateOption, err error) []errorResult {
+	var all tests.ValidationErrors
+	switch kind {
+	case "statement":
+		var stmt types.Statement
+		if json.Unmarshal(data, &stmt) == nil {
+			all = tests.ValidateQueryShapeAll(&stmt, opts...)
+		}
+	case "mutation":
+		var m types.Mutation
+		if json.Unmarshal(data, &m) == nil {
+			all = tests.ValidateMutationEventAll(&m, opts...)
+		}
+	case "transaction":
+		var tx types.Transaction
+		if json.Unmarshal(data, &tx) == nil {
+			all = tests.ValidateTransactionAll(&tx, opts...)
+		}
+	case "dependencies":
+		var deps types.Dependencies
+		if json.Unmarshal(data, &deps) == nil {
+			all = tests.ValidateDependenciesAll(&deps, opts...)
+		}
+	}
+	if len(all) == 0 {
+		var verr *tests.ValidationError
+		if errors.As(err, &verr) {
+			all = tests.ValidationErrors{*verr}
+		} else {
+			return []errorResult{{Message: err.Error()}}
+		}
+	}
+
+	out := make([]errorResult, len(all))
+	for i, e := range all {
+		out[i] = errorResult{Code: e.Code, Message: e.Message, Path: e.Path}
+	}
+	return out
+}
+
+func canonicalize(doc any) (string, error) {
+	switch d := doc.(type) {
+	case *types.Statement:
+		return tests.CanonicalizeQueryShape(d)
+	case *types.Mutation:
+		return tests.CanonicalizeMutation(d)
+	case *types.Dependencies:
+		return tests.CanonicalizeDependencies(d)
+	}
+	return tests.Canonicalize(doc)
+}
+
+// printText prints a result as text: the canonical JSON or ID on stdout,
+// prefixed with the input when there are several, and errors on stderr.
+func printText(stdout, stderr io.Writer, command string, res result, prefix bool) {
+	if !res.Valid {
+		printErrors(stderr, res)
+		return
+	}
+
+	var out string
+	switch command {
+	case "validate":
+		fmt.Fprintf(stdout, "%s: valid %s\n", res.Input, res.Kind)
+		return
+	case "canonicalize":
+		out = res.Canonical
+	case "shape-id":
+		out = res.ShapeID + res.MutationID
+	}
+	if prefix {
+		out = res.Input + ": " + out
+	}
+	fmt.Fprintln(stdout, out)
+}
+
+func printErrors(w io.Writer, res result) {
+	for _, e := range res.Errors {
+		msg := e.Message
+		if e.Path != "" {
+			msg += " at " + e.Path
+		}
+		if e.Code != "" {
+			msg = string(e.Code) + ": " + msg
+		}
+		fmt.Fprintf(w, "%s: %s\n", res.Input, msg)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func runIkspec(t *testing.T, stdin string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	status := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return status, stdout.String(), stderr.String()
+}
+
+func TestShapeIDMatchesVectors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "tools", "tests", "vectors", "query-shapes.json"))
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+	var vectors []struct {
+		Name              string          `json:"name"`
+		Shape             json.RawMessage `json:"shape"`
+		ExpectedCanonical string          `json:"expectedCanonical"`
+		ExpectedShapeID   string          `json:"expectedShapeId"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			status, out, errOut := runIkspec(t, string(v.Shape), "canonicalize")
+			if status != 0 || out != v.ExpectedCanonical+"\n" {
+				t.Errorf("canonicalize = %d %q %q, want %s", status, out, errOut, v.ExpectedCanonical)
+			}
+			status, out, errOut = runIkspec(t, string(v.Shape), "shape-id")
+			if status != 0 || out != v.ExpectedShapeID+"\n" {
+				t.Errorf("shape-id = %d %q %q, want %s", status, out, errOut, v.ExpectedShapeID)
+			}
+		})
+	}
+}
+
+func TestMutationID(t *testing.T) {
+	mutation := `{"changes":[{"model":"Post","action":"delete","where":{"conditions":[{"field":"id","op":"eq","value":"p1"}]}}]}`
+	status, out, _ := runIkspec(t, mutation, "shape-id", "-format", "json")
+	if status != 0 {
+		t.Fatalf("status = %d", status)
+	}
+	var res result
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("output %q is not JSON: %v", out, err)
+	}
+	if res.Kind != "mutation" || !res.Valid || !strings.HasPrefix(res.MutationID, "m_") || res.ShapeID != "" {
+		t.Errorf("result = %+v", res)
+	}
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+	stmt := `{"query":{"model":"","limit":-1}}`
+	status, out, _ := runIkspec(t, stmt, "validate", "-format", "json")
+	if status != 1 {
+		t.Errorf("status = %d, want 1", status)
+	}
+	var res result
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("output %q is not JSON: %v", out, err)
+	}
+	if res.Valid || len(res.Errors) != 2 {
+		t.Fatalf("result = %+v, want two errors", res)
+	}
+	for _, e := range res.Errors {
+		if e.Code == "" || e.Path == "" {
+			t.Errorf("error %+v lacks a code or path", e)
+		}
+	}
+
+	status, out, errOut := runIkspec(t, stmt, "validate")
+	if status != 1 || out != "" || strings.Count(errOut, "\n") != 2 || !strings.HasPrefix(errOut, "-: IK") {
+		t.Errorf("validate = %d %q %q", status, out, errOut)
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	good := filepath.Join(dir, "good.json")
+	strictBad := filepath.Join(dir, "extra.json")
+	os.WriteFile(good, []byte(`{"query":{"model":"Post"}}`), 0o644)
+	os.WriteFile(strictBad, []byte(`{"query":{"model":"Post","extra":1}}`), 0o644)
+
+	status, out, errOut := runIkspec(t, "", "validate", good, strictBad)
+	if status != 0 || out != good+": valid statement\n"+strictBad+": valid statement\n" {
+		t.Errorf("validate = %d %q %q", status, out, errOut)
+	}
+
+	status, out, errOut = runIkspec(t, "", "validate", "-strict", good, strictBad)
+	if status != 1 || out != good+": valid statement\n" || !strings.Contains(errOut, strictBad+": ") {
+		t.Errorf("validate -strict = %d %q %q", status, out, errOut)
+	}
+
+	status, _, errOut = runIkspec(t, "", "validate", filepath.Join(dir, "missing.json"))
+	if status != 1 || !strings.Contains(errOut, "missing.json") {
+		t.Errorf("validate of a missing file = %d %q", status, errOut)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	for _, args := range [][]string{
+		nil,
+		{"frobnicate"},
+		{"validate", "-kind", "query"},
+		{"validate", "-format", "yaml"},
+		{"validate", "-nope"},
+	} {
+		if status, _, _ := runIkspec(t, "", args...); status != 2 {
+			t.Errorf("ikspec %v: status = %d, want 2", args, status)
+		}
+	}
+
+	if status, _, _ := runIkspec(t, `{"shape_id":"s_`+strings.Repeat("0", 64)+`","records":{},"filters":[],"includes":[]}`, "shape-id"); status != 1 {
+		t.Errorf("shape-id of dependencies: status = %d, want 1", status)
+	}
+}
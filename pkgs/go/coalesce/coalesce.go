@@ -0,0 +1,197 @@
+// Package coalesce merges redundant changes within one IncludeKit Mutation,
+// reducing invalidation work for ORMs that emit a change per field write.
+package coalesce
+
+import (
+	"encoding/json"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Coalescer identifies rows by a key field and merges changes to the same row.
+//
+// A change is keyed when it is an insert that sets the key field, or an update
+// or delete whose where clause is exactly "<key> eq <value>". Unkeyed changes
+// are passed through untouched.
+type Coalescer struct {
+	// Key is the row key field for models not listed in Models.
+	// An empty Key leaves unlisted models uncoalesced.
+	Key string
+	// Models overrides the key field per model. An empty value disables
+	// coalescing for that model.
+	Models map[string]string
+}
+
+// KeyFor returns the key field for a model, or "" if it is not coalesced.
+func (c Coalescer) KeyFor(model string) string {
+	if key, ok := c.Models[model]; ok {
+		return key
+	}
+	return c.Key
+}
+
+// Mutation coalesces m keying every model by "id".
+//
+// See Coalescer.Mutation for details.
+func Mutation(m types.Mutation) types.Mutation {
+	return Coalescer{Key: "id"}.Mutation(m)
+}
+
+// Mutation returns a copy of m with redundant changes to the same row merged,
+// keeping each merged change at the position of the row's first change:
+//
+//   - insert then update: a single insert with the update's sets applied
+//   - update then update: a single update with both sets, later values winning
+//   - update then delete: a single delete
+//   - insert then delete: both dropped, as the row never existed outside the
+//     transaction
+//   - delete then delete: a single delete
+//
+// Any other sequence (e.g., delete then insert) is kept as is. An update that
+// sets the key field changes the row identity, so it is never merged and ends
+// tracking of the old key. The input mutation is not modified.
+func (c Coalescer) Mutation(m types.Mutation) types.Mutation {
+	type slot struct {
+		change  types.Change
+		dropped bool
+	}
+
+	slots := make([]slot, 0, len(m.Changes))
+	rows := map[string]int{} // row key -> index into slots
+
+	for _, change := range m.Changes {
+		key := c.KeyFor(change.Model)
+		row, ok := rowKey(change, key)
+		if !ok {
+			if change.Action == "update" && setsField(change.Sets, key) {
+				forgetModel(rows, change.Model)
+			}
+			slots = append(slots, slot{change: change})
+			continue
+		}
+
+		i, seen := rows[row]
+		if seen {
+			prev := &slots[i].change
+			switch {
+			case prev.Action == "insert" && change.Action == "update",
+				prev.Action == "update" && change.Action == "update":
+				prev.Sets = mergeSets(prev.Sets, change.Sets)
+				continue
+			case prev.Action == "insert" && change.Action == "delete":
+				slots[i].dropped = true
+				delete(rows, row)
+				continue
+			case prev.Action == "update" && change.Action == "delete":
+				*prev = change
+				continue
+			case prev.Action == "delete" && change.Action == "delete":
+				continue
+			}
+		}
+
+		rows[row] = len(slots)
+		slots = append(slots, slot{change: change})
+	}
+
+	out := m
+	out.Changes = make([]types.Change, 0, len(slots))
+	for _, s := range slots {
+		if !s.dropped {
+			out.Changes = append(out.Changes, s.change)
+		}
+	}
+	return out
+}
+
+// rowKey returns a key identifying the row a change targets, or false if the
+// change is not keyed by the key field.
+func rowKey(change types.Change, key string) (string, bool) {
+	if key == "" {
+		return "", false
+	}
+
+	var value any
+	switch change.Action {
+	case "insert":
+		kv, ok := findSet(change.Sets, key)
+		if !ok {
+			return "", false
+		}
+		value = kv.Value
+	case "update", "delete":
+		if change.Action == "update" && setsField(change.Sets, key) {
+			return "", false
+		}
+		v, ok := keyCondition(change.Where, key)
+		if !ok {
+			return "", false
+		}
+		value = v
+	default:
+		return "", false
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", false
+	}
+	return change.Model + "\x00" + string(encoded), true
+}
+
+// keyCondition returns the value of a where clause that is exactly
+// "<key> eq <value>".
+func keyCondition(where *types.Filter, key string) (any, bool) {
+	if where == nil || where.And != nil || where.Or != nil || where.Not != nil ||
+		where.Conditions == nil || len(*where.Conditions) != 1 {
+		return nil, false
+	}
+	cond := (*where.Conditions)[0]
+	if cond.Field != key || cond.Op != "eq" || len(cond.FieldPath) > 0 {
+		return nil, false
+	}
+	return cond.Value, true
+}
+
+func findSet(sets []types.KV, field string) (types.KV, bool) {
+	for _, kv := range sets {
+		if kv.Field == field {
+			return kv, true
+		}
+	}
+	return types.KV{}, false
+}
+
+func setsField(sets []types.KV, field string) bool {
+	_, ok := findSet(sets, field)
+	return field != "" && ok
+}
+
+// mergeSets applies later sets over earlier ones, keeping first-seen order.
+func mergeSets(earlier, later []types.KV) []types.KV {
+	merged := make([]types.KV, len(earlier), len(earlier)+len(later))
+	copy(merged, earlier)
+	for _, kv := range later {
+		replaced := false
+		for i := range merged {
+			if merged[i].Field == kv.Field {
+				merged[i].Value = kv.Value
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			merged = append(merged, kv)
+		}
+	}
+	return merged
+}
+
+func forgetModel(rows map[string]int, model string) {
+	prefix := model + "\x00"
+	for row := range rows {
+		if len(row) > len(prefix) && row[:len(prefix)] == prefix {
+			delete(rows, row)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package coalesce_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/coalesce"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func insert(model string, sets ...types.KV) types.Change {
+	return types.Change{Model: model, Action: "insert", Sets: sets}
+}
+
+func update(model string, id any, sets ...types.KV) types.Change {
+	where := eqFilter("id", id)
+	return types.Change{Model: model, Action: "update", Sets: sets, Where: &where}
+}
+
+func del(model string, id any) types.Change {
+	where := eqFilter("id", id)
+	return types.Change{Model: model, Action: "delete", Where: &where}
+}
+
+func eqFilter(field string, v any) types.Filter {
+	return types.Filter{Conditions: &[]types.Condition{types.Eq(field, v)}}
+}
+
+func kv(field string, value any) types.KV {
+	return types.KV{Field: field, Value: value}
+}
+
+func TestMutation(t *testing.T) {
+	broad := eqFilter("status", "draft")
+	unkeyed := types.Change{Model: "posts", Action: "update", Sets: []types.KV{kv("status", "archived")}, Where: &broad}
+
+	tcs := []struct {
+		name string
+		in   []types.Change
+		want []types.Change
+	}{
+		{
+			name: "insert then update",
+			in:   []types.Change{insert("posts", kv("id", "1"), kv("title", "a")), update("posts", "1", kv("title", "b"), kv("views", 0))},
+			want: []types.Change{insert("posts", kv("id", "1"), kv("title", "b"), kv("views", 0))},
+		},
+		{
+			name: "update then update",
+			in:   []types.Change{update("posts", "1", kv("title", "a")), update("posts", "1", kv("title", "b"))},
+			want: []types.Change{update("posts", "1", kv("title", "b"))},
+		},
+		{
+			name: "update then delete",
+			in:   []types.Change{update("posts", "1", kv("title", "a")), insert("users", kv("id", "9")), del("posts", "1")},
+			want: []types.Change{del("posts", "1"), insert("users", kv("id", "9"))},
+		},
+		{
+			name: "insert then delete cancels",
+			in:   []types.Change{insert("posts", kv("id", "1")), update("posts", "1", kv("title", "a")), del("posts", "1")},
+			want: []types.Change{},
+		},
+		{
+			name: "delete then insert is kept",
+			in:   []types.Change{del("posts", "1"), insert("posts", kv("id", "1"))},
+			want: []types.Change{del("posts", "1"), insert("posts", kv("id", "1"))},
+		},
+		{
+			name: "different rows and models are independent",
+			in:   []types.Change{update("posts", "1", kv("a", 1)), update("posts", "2", kv("a", 2)), update("users", "1", kv("a", 3))},
+			want: []types.Change{update("posts", "1", kv("a", 1)), update("posts", "2", kv("a", 2)), update("users", "1", kv("a", 3))},
+		},
+		{
+			name: "unkeyed changes pass through",
+			in:   []types.Change{unkeyed, update("posts", "1", kv("a", 1)), unkeyed},
+			want: []types.Change{unkeyed, update("posts", "1", kv("a", 1)), unkeyed},
+		},
+		{
+			name: "key change ends tracking",
+			in:   []types.Change{update("posts", "1", kv("a", 1)), update("posts", "1", kv("id", "2")), update("posts", "1", kv("a", 2))},
+			want: []types.Change{update("posts", "1", kv("a", 1)), update("posts", "1", kv("id", "2")), update("posts", "1", kv("a", 2))},
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			txID := "tx_1"
+			in := types.Mutation{TxID: &txID, Changes: tt.in}
+			got := coalesce.Mutation(in)
+			if !reflect.DeepEqual(got.Changes, tt.want) {
+				t.Errorf("Changes =\n  %+v\nwant\n  %+v", got.Changes, tt.want)
+			}
+			if got.TxID != in.TxID {
+				t.Error("TxID not preserved")
+			}
+		})
+	}
+}
+
+func TestMutationDoesNotModifyInput(t *testing.T) {
+	in := types.Mutation{Changes: []types.Change{
+		insert("posts", kv("id", "1"), kv("title", "a")),
+		update("posts", "1", kv("title", "b")),
+	}}
+	coalesce.Mutation(in)
+	if in.Changes[0].Sets[1].Value != "a" || len(in.Changes) != 2 {
+		t.Errorf("input mutated: %+v", in.Changes)
+	}
+}
+
+func TestCoalescerKeyFor(t *testing.T) {
+	c := coalesce.Coalescer{Key: "id", Models: map[string]string{"tags": "slug", "logs": ""}}
+	in := types.Mutation{Changes: []types.Change{
+		insert("tags", kv("slug", "go")),
+		del("tags", "go"), // keyed by id, so not the same row
+		insert("logs", kv("id", "1")),
+		del("logs", "1"),
+	}}
+	if got := c.Mutation(in); len(got.Changes) != 4 {
+		t.Errorf("expected no coalescing, got %+v", got.Changes)
+	}
+}
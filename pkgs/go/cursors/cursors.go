@@ -0,0 +1,276 @@
+// Package cursors encodes and decodes the opaque cursors carried in
+// Pagination.After and Pagination.Before of the IncludeKit Universal Format.
+//
+// A cursor is the standard (padded) base64 encoding of a JSON object mapping
+// each orderBy field to its value in the boundary row, with keys in orderBy
+// order so the cursor records the sort it was built for:
+//
+//	orderBy [{createdAt desc}, {id}]
+//	{"createdAt":"2024-01-15T10:30:00Z","id":"post_123"}
+//	-> "eyJjcmVhdGVkQXQiOiIyMDI0LTAxLTE1VDEwOjMwOjAwWiIsImlkIjoicG9zdF8xMjMifQ=="
+//
+// Cursors come back from clients, so Decode treats them as untrusted: it
+// bounds their size, rejects anything but that exact form, and reports every
+// problem as a *CursorError.
+package cursors
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// MaxCursorBytes bounds the encoded length of a cursor accepted by Decode.
+const MaxCursorBytes = 4096
+
+var (
+	// ErrMalformed is matched by CursorErrors for cursors that are not a
+	// valid encoding.
+	ErrMalformed = errors.New("malformed cursor")
+	// ErrMismatch is matched by CursorErrors for well-formed cursors that
+	// were not built for the given orderBy.
+	ErrMismatch = errors.New("cursor does not match orderBy")
+)
+
+// CursorError reports why a cursor was rejected.
+type CursorError struct {
+	Reason string // "too_large" | "encoding" | "payload" | "duplicate_field" | "missing_field" | "extra_field" | "field_order"
+	Field  string // the offending field, when there is one
+	Detail string
+}
+
+func (e *CursorError) Error() string {
+	msg := "cursors: " + strings.ReplaceAll(e.Reason, "_", " ")
+	if e.Field != "" {
+		msg += fmt.Sprintf(" %q", e.Field)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+	return msg
+}
+
+// Unwrap makes errors.Is match ErrMalformed or ErrMismatch.
+func (e *CursorError) Unwrap() error {
+	switch e.Reason {
+	case "missing_field", "extra_field", "field_order":
+		return ErrMismatch
+	}
+	return ErrMalformed
+}
+
+// Field is one orderBy field of a cursor and its boundary value.
+type Field struct {
+	Name  string
+	Value any
+}
+
+// Cursor is a decoded cursor, with fields in encoded order.
+type Cursor []Field
+
+// Encode builds the cursor for row under orderBy. Values are coerced with
+// types.Value, so time.Time, []byte and TextMarshalers encode the way they
+// do in conditions.
+//
+// Returns an error if orderBy is empty or repeats a field, or row is missing
+// an orderBy field or holds a value with no JSON form.
+func Encode(orderBy []types.OrderBy, row map[string]any) (string, error) {
+	if len(orderBy) == 0 {
+		return "", fmt.Errorf("cursors: orderBy is required to build a cursor")
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	seen := make(map[string]bool, len(orderBy))
+
+	buf.WriteByte('{')
+	for i, ob := range orderBy {
+		if seen[ob.Field] {
+			return "", fmt.Errorf("cursors: orderBy repeats field %q", ob.Field)
+		}
+		seen[ob.Field] = true
+
+		value, ok := row[ob.Field]
+		if !ok {
+			return "", fmt.Errorf("cursors: row is missing orderBy field %q", ob.Field)
+		}
+		coerced, err := types.Value(value)
+		if err != nil {
+			return "", fmt.Errorf("cursors: field %q: %w", ob.Field, err)
+		}
+
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		// Encoder.Encode appends a newline, which JSON allows between tokens
+		if err := enc.Encode(ob.Field); err != nil {
+			return "", fmt.Errorf("cursors: %w", err)
+		}
+		buf.Truncate(buf.Len() - 1)
+		buf.WriteByte(':')
+		if err := enc.Encode(coerced); err != nil {
+			return "", fmt.Errorf("cursors: field %q: %w", ob.Field, err)
+		}
+		buf.Truncate(buf.Len() - 1)
+	}
+	buf.WriteByte('}')
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// WithKey returns orderBy followed, ascending, by each key field it does not
+// already sort on. A cursor built for the result identifies exactly one row
+// even when the sort ties, including under a composite primary key.
+func WithKey(orderBy []types.OrderBy, key ...string) []types.OrderBy {
+	out := append([]types.OrderBy{}, orderBy...)
+	for _, field := range key {
+		sorted := false
+		for _, ob := range out {
+			sorted = sorted || ob.Field == field
+		}
+		if !sorted {
+			out = append(out, types.OrderBy{Field: field})
+		}
+	}
+	return out
+}
+
+// Decode parses a cursor without checking it against an orderBy. Integral
+// numbers that fit an int64 decode as int64, other numbers as float64.
+func Decode(cursor string) (Cursor, error) {
+	if len(cursor) > MaxCursorBytes {
+		return nil, &CursorError{Reason: "too_large", Detail: fmt.Sprintf("%d bytes exceeds %d", len(cursor), MaxCursorBytes)}
+	}
+	data, err := base64.StdEncoding.Strict().DecodeString(cursor)
+	if err != nil {
+		return nil, &CursorError{Reason: "encoding", Detail: err.Error()}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, &CursorError{Reason: "payload", Detail: "not a JSON object"}
+	}
+
+	var c Cursor
+	seen := map[string]bool{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, &CursorError{Reason: "payload", Detail: err.Error()}
+		}
+		name, ok := tok.(string)
+		if !ok {
+			return nil, &CursorError{Reason: "payload", Detail: fmt.Sprintf("field name %v is not a string", tok)}
+		}
+		if seen[name] {
+			return nil, &CursorError{Reason: "duplicate_field", Field: name}
+		}
+		seen[name] = true
+
+		var raw any
+		if err := dec.Decode(&raw); err != nil {
+			return nil, &CursorError{Reason: "payload", Field: name, Detail: err.Error()}
+		}
+		value, err := fromJSON(raw)
+		if err != nil {
+			return nil, &CursorError{Reason: "payload", Field: name, Detail: err.Error()}
+		}
+		c = append(c, Field{Name: name, Value: value})
+	}
+	if _, err := dec.Token(); err != nil {
+		return nil, &CursorError{Reason: "payload", Detail: err.Error()}
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, &CursorError{Reason: "payload", Detail: "trailing data after object"}
+	}
+	if len(c) == 0 {
+		return nil, &CursorError{Reason: "payload", Detail: "no fields"}
+	}
+	return c, nil
+}
+
+// DecodeValues decodes a cursor and returns its values in orderBy order. See
+// Cursor.Values.
+func DecodeValues(orderBy []types.OrderBy, cursor string) ([]any, error) {
+	c, err := Decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return c.Values(orderBy)
+}
+
+// Values returns the cursor's values in orderBy order. The cursor must hold
+// exactly the orderBy fields, in the same order; anything else means it was
+// built for a different sort and would page from the wrong boundary.
+func (c Cursor) Values(orderBy []types.OrderBy) ([]any, error) {
+	index := make(map[string]int, len(c))
+	for i, f := range c {
+		index[f.Name] = i
+	}
+
+	values := make([]any, len(orderBy))
+	for i, ob := range orderBy {
+		j, ok := index[ob.Field]
+		if !ok {
+			return nil, &CursorError{Reason: "missing_field", Field: ob.Field}
+		}
+		if j != i {
+			return nil, &CursorError{Reason: "field_order", Field: ob.Field, Detail: fmt.Sprintf("at position %d, want %d", j, i)}
+		}
+		values[i] = c[j].Value
+	}
+	if len(c) > len(orderBy) {
+		return nil, &CursorError{Reason: "extra_field", Field: c[len(orderBy)].Name}
+	}
+	return values, nil
+}
+
+// Map returns the cursor's fields as a map.
+func (c Cursor) Map() map[string]any {
+	m := make(map[string]any, len(c))
+	for _, f := range c {
+		m[f.Name] = f.Value
+	}
+	return m
+}
+
+// fromJSON replaces json.Numbers with int64 or float64.
+func fromJSON(v any) (any, error) {
+	switch val := v.(type) {
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := val.Float64()
+		if err != nil || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("number %s out of range", val)
+		}
+		return f, nil
+	case []any:
+		for i, elem := range val {
+			converted, err := fromJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = converted
+		}
+	case map[string]any:
+		for k, elem := range val {
+			converted, err := fromJSON(elem)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = converted
+		}
+	}
+	return v, nil
+}
@@ -0,0 +1,252 @@
+package cursors_test
+
+import (
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/cursors"
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func encoded(payload string) string {
+	return base64.StdEncoding.EncodeToString([]byte(payload))
+}
+
+func TestEncodeDecode(t *testing.T) {
+	orderBy := []types.OrderBy{{Field: "createdAt", Descending: boolPtr(true)}, {Field: "id"}}
+	row := map[string]any{
+		"id":        int64(1) << 60,
+		"title":     "ignored",
+		"createdAt": time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	cursor, err := cursors.Encode(orderBy, row)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if want := encoded(`{"createdAt":"2024-01-15T10:30:00Z","id":1152921504606846976}`); cursor != want {
+		t.Errorf("Encode() = %s, want %s", cursor, want)
+	}
+
+	values, err := cursors.DecodeValues(orderBy, cursor)
+	if err != nil {
+		t.Fatalf("DecodeValues failed: %v", err)
+	}
+	if want := []any{"2024-01-15T10:30:00Z", int64(1) << 60}; !reflect.DeepEqual(values, want) {
+		t.Errorf("DecodeValues() = %#v, want %#v", values, want)
+	}
+}
+
+func TestEncodeKeepsOrderByOrder(t *testing.T) {
+	orderBy := []types.OrderBy{{Field: "score"}, {Field: "id"}}
+	row := map[string]any{"id": "a<b", "score": 2.5}
+
+	cursor, err := cursors.Encode(orderBy, row)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if want := encoded(`{"score":2.5,"id":"a<b"}`); cursor != want {
+		t.Errorf("Encode() = %s, want %s", cursor, want)
+	}
+
+	c, err := cursors.Decode(cursor)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	want := cursors.Cursor{{Name: "score", Value: 2.5}, {Name: "id", Value: "a<b"}}
+	if !reflect.DeepEqual(c, want) {
+		t.Errorf("Decode() = %#v, want %#v", c, want)
+	}
+	if m := c.Map(); m["id"] != "a<b" || m["score"] != 2.5 {
+		t.Errorf("Map() = %v", m)
+	}
+}
+
+func TestEncodeErrors(t *testing.T) {
+	if _, err := cursors.Encode(nil, map[string]any{"id": 1}); err == nil {
+		t.Error("Expected error for empty orderBy")
+	}
+	if _, err := cursors.Encode([]types.OrderBy{{Field: "id"}, {Field: "id"}}, map[string]any{"id": 1}); err == nil {
+		t.Error("Expected error for repeated orderBy field")
+	}
+	if _, err := cursors.Encode([]types.OrderBy{{Field: "id"}}, map[string]any{}); err == nil {
+		t.Error("Expected error for missing field")
+	}
+}
+
+func TestDecodeRejectsMalformed(t *testing.T) {
+	tcs := []struct {
+		name   string
+		cursor string
+		reason string
+	}{
+		{"too large", strings.Repeat("A", cursors.MaxCursorBytes+4), "too_large"},
+		{"not base64", "not base64!", "encoding"},
+		{"unpadded", strings.TrimRight(encoded(`{"id":1}`), "="), "encoding"},
+		{"null", encoded(`null`), "payload"},
+		{"array", encoded(`[1]`), "payload"},
+		{"empty object", encoded(`{}`), "payload"},
+		{"trailing data", encoded(`{"id":1}{}`), "payload"},
+		{"truncated", encoded(`{"id":1`), "payload"},
+		{"number out of range", encoded(`{"id":1e999}`), "payload"},
+		{"duplicate field", encoded(`{"id":1,"id":2}`), "duplicate_field"},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cursors.Decode(tt.cursor)
+			var cerr *cursors.CursorError
+			if !errors.As(err, &cerr) {
+				t.Fatalf("Decode() error = %v, want *CursorError", err)
+			}
+			if cerr.Reason != tt.reason {
+				t.Errorf("Reason = %s, want %s (%v)", cerr.Reason, tt.reason, err)
+			}
+			if !errors.Is(err, cursors.ErrMalformed) {
+				t.Errorf("errors.Is(%v, ErrMalformed) = false", err)
+			}
+		})
+	}
+}
+
+func TestValuesEnforcesOrderBy(t *testing.T) {
+	orderBy := []types.OrderBy{{Field: "createdAt"}, {Field: "id"}}
+
+	tcs := []struct {
+		name    string
+		payload string
+		reason  string
+		field   string
+	}{
+		{"missing field", `{"createdAt":"2024-01-15"}`, "missing_field", "id"},
+		{"extra field", `{"createdAt":"2024-01-15","id":1,"title":"x"}`, "extra_field", "title"},
+		{"wrong order", `{"id":1,"createdAt":"2024-01-15"}`, "field_order", "createdAt"},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := cursors.DecodeValues(orderBy, encoded(tt.payload))
+			var cerr *cursors.CursorError
+			if !errors.As(err, &cerr) {
+				t.Fatalf("DecodeValues() error = %v, want *CursorError", err)
+			}
+			if cerr.Reason != tt.reason || cerr.Field != tt.field {
+				t.Errorf("got %s %q, want %s %q", cerr.Reason, cerr.Field, tt.reason, tt.field)
+			}
+			if !errors.Is(err, cursors.ErrMismatch) || errors.Is(err, cursors.ErrMalformed) {
+				t.Errorf("%v should match ErrMismatch only", err)
+			}
+		})
+	}
+}
+
+func TestWithKey(t *testing.T) {
+	orderBy := []types.OrderBy{{Field: "createdAt", Descending: boolPtr(true)}, {Field: "lineNo"}}
+	got := cursors.WithKey(orderBy, "orderId", "lineNo")
+	want := []types.OrderBy{{Field: "createdAt", Descending: boolPtr(true)}, {Field: "lineNo"}, {Field: "orderId"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("WithKey() = %+v, want %+v", got, want)
+	}
+	if len(orderBy) != 2 {
+		t.Error("WithKey must not modify its orderBy")
+	}
+
+	row := map[string]any{"createdAt": "2024-01-15", "orderId": "o1", "lineNo": 2}
+	cursor, err := cursors.Encode(got, row)
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if want := encoded(`{"createdAt":"2024-01-15","lineNo":2,"orderId":"o1"}`); cursor != want {
+		t.Errorf("Encode() = %s, want %s", cursor, want)
+	}
+}
+
+func TestDecodesNextPageCursors(t *testing.T) {
+	// Keys follow orderBy, not their sorted order
+	orderBy := []types.OrderBy{{Field: "id"}, {Field: "createdAt"}}
+	stmt := types.NewStatement(
+		types.WithModel("posts"),
+		types.WithOrderBy(orderBy...),
+		types.WithPagination(types.Pagination{First: intPtr(10)}),
+	)
+	next, err := pagination.NextPage(stmt, map[string]any{"createdAt": "2024-01-15", "id": 7})
+	if err != nil {
+		t.Fatalf("NextPage failed: %v", err)
+	}
+
+	values, err := cursors.DecodeValues(orderBy, *next.Pagination.After)
+	if err != nil {
+		t.Fatalf("DecodeValues failed: %v", err)
+	}
+	if want := []any{int64(7), "2024-01-15"}; !reflect.DeepEqual(values, want) {
+		t.Errorf("DecodeValues() = %#v, want %#v", values, want)
+	}
+}
+
+// FuzzDecode checks that Decode never panics on client input, rejects only
+// with a *CursorError, and that a cursor it accepts re-encodes to a cursor
+// with the same fields that encodes the same way again. Values compare by
+// encoding, since 1.0 re-encodes as 1.
+func FuzzDecode(f *testing.F) {
+	for _, payload := range []string{
+		`{"createdAt":"2024-01-15T10:30:00Z","id":"post_123"}`,
+		`{"id":1152921504606846976,"score":1.5,"tags":["a",{"b":null}]}`,
+		`{"id":1,"id":2}`,
+		`{1:2}`,
+		`{"id":1e999}`,
+		`{"id":1} {}`,
+		`{"id":1.0}`,
+		`{"id":`,
+		`{}`,
+		`[]`,
+	} {
+		f.Add(encoded(payload))
+	}
+	f.Add("not base64!")
+
+	f.Fuzz(func(t *testing.T, cursor string) {
+		c, err := cursors.Decode(cursor)
+		if err != nil {
+			var cerr *cursors.CursorError
+			if !errors.As(err, &cerr) {
+				t.Fatalf("Decode(%q) error %v is not a *CursorError", cursor, err)
+			}
+			return
+		}
+
+		orderBy := make([]types.OrderBy, len(c))
+		for i, field := range c {
+			orderBy[i] = types.OrderBy{Field: field.Name}
+		}
+		reencoded, err := cursors.Encode(orderBy, c.Map())
+		if err != nil {
+			t.Fatalf("Encode(%v) failed: %v", c, err)
+		}
+		again, err := cursors.Decode(reencoded)
+		if err != nil {
+			t.Fatalf("Decode(%q) failed: %v", reencoded, err)
+		}
+		if len(again) != len(c) {
+			t.Fatalf("cursor %q decodes to %v, re-encoded to %v", cursor, c, again)
+		}
+		for i := range c {
+			if again[i].Name != c[i].Name {
+				t.Fatalf("cursor %q decodes to %v, re-encoded to %v", cursor, c, again)
+			}
+		}
+		if twice, err := cursors.Encode(orderBy, again.Map()); err != nil || twice != reencoded {
+			t.Errorf("cursor %q re-encodes to %q, then to %q (%v)", cursor, reencoded, twice, err)
+		}
+	})
+}
+
+func boolPtr(b bool) *bool { return &b }
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,160 @@
+// Package diff derives IncludeKit Mutation changes from before/after row
+// snapshots, for ORM hooks and CDC consumers that only see old and new images.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Differ derives changes for rows identified by a key field.
+type Differ struct {
+	// Key is the row key field for models not listed in Models.
+	Key string
+	// Models overrides the key field per model. An empty value means the
+	// model has no key, and rows are matched on every before-image field.
+	Models map[string]string
+	// Keys gives models composite keys, taking precedence over Key and
+	// Models: rows are matched on every listed field.
+	Keys map[string][]string
+	// NullMissing sets before-image fields missing from the after image to
+	// nil in updates. Leave it off for partial after images, such as ORM
+	// hooks that report only the fields written.
+	NullMissing bool
+}
+
+// KeyFor returns the key field for a model, or "" if it has none.
+func (d Differ) KeyFor(model string) string {
+	if key, ok := d.Models[model]; ok {
+		return key
+	}
+	return d.Key
+}
+
+// keysFor returns the key fields for a model, or nil if it has none.
+func (d Differ) keysFor(model string) []string {
+	if keys, ok := d.Keys[model]; ok {
+		return keys
+	}
+	if key := d.KeyFor(model); key != "" {
+		return []string{key}
+	}
+	return nil
+}
+
+// Changes derives changes for a row keyed by "id".
+//
+// See Differ.Changes for details.
+func Changes(model string, before, after map[string]any) []types.Change {
+	return Differ{Key: "id"}.Changes(model, before, after)
+}
+
+// Changes returns the minimal changes turning the before image of a row into
+// the after image:
+//
+//   - before nil: an insert setting every after field
+//   - after nil: a delete of the before row
+//   - both set: an update setting only the after fields whose values differ
+//     (and, with NullMissing, fields missing from after to nil), or no
+//     change if none differ
+//
+// Updates and deletes match the row on "<key> eq <before key value>" for each
+// key field, in key order. When the model has no key or the before image
+// lacks one of its fields, they match on every before-image field instead.
+// Sets and those conditions are sorted by field.
+//
+// Values are coerced with types.Value; values it rejects are kept as is.
+// Returns nil if both images are nil.
+func (d Differ) Changes(model string, before, after map[string]any) []types.Change {
+	switch {
+	case before == nil && after == nil:
+		return nil
+	case before == nil:
+		return []types.Change{{Model: model, Action: "insert", Sets: sets(after, nil)}}
+	case after == nil:
+		return []types.Change{{Model: model, Action: "delete", Where: d.where(model, before)}}
+	}
+
+	changed := sets(after, before)
+	if d.NullMissing {
+		for field := range before {
+			if _, ok := after[field]; !ok {
+				changed = append(changed, types.KV{Field: field, Value: nil})
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+	sort.Slice(changed, func(i, j int) bool { return changed[i].Field < changed[j].Field })
+
+	return []types.Change{{Model: model, Action: "update", Sets: changed, Where: d.where(model, before)}}
+}
+
+// where matches the before row by key, or by every field without one.
+func (d Differ) where(model string, before map[string]any) *types.Filter {
+	var conds []types.Condition
+	for _, key := range d.keysFor(model) {
+		v, ok := before[key]
+		if !ok {
+			conds = nil
+			break
+		}
+		conds = append(conds, types.Eq(key, coerce(v)))
+	}
+	if conds == nil {
+		for _, field := range sortedKeys(before) {
+			if v := coerce(before[field]); v == nil {
+				conds = append(conds, types.IsNull(field))
+			} else {
+				conds = append(conds, types.Eq(field, v))
+			}
+		}
+	}
+	return &types.Filter{Conditions: &conds}
+}
+
+// sets returns the row fields, skipping those equal in base (if non-nil).
+func sets(row, base map[string]any) []types.KV {
+	kvs := []types.KV{}
+	for _, field := range sortedKeys(row) {
+		value := coerce(row[field])
+		if base != nil {
+			if old, ok := base[field]; ok && equal(coerce(old), value) {
+				continue
+			}
+		}
+		kvs = append(kvs, types.KV{Field: field, Value: value})
+	}
+	return kvs
+}
+
+func coerce(v any) any {
+	if c, err := types.Value(v); err == nil {
+		return c
+	}
+	return v
+}
+
+// equal compares JSON encodings, so 1 and 1.0 are the same value.
+func equal(a, b any) bool {
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return bytes.Equal(ja, jb)
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,126 @@
+package diff_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/diff"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func whereEq(field string, v any) *types.Filter {
+	return &types.Filter{Conditions: &[]types.Condition{types.Eq(field, v)}}
+}
+
+func TestChanges(t *testing.T) {
+	at := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tcs := []struct {
+		name          string
+		before, after map[string]any
+		want          []types.Change
+	}{
+		{name: "no images"},
+		{
+			name:  "insert",
+			after: map[string]any{"id": "1", "title": "a", "created": at},
+			want: []types.Change{{Model: "posts", Action: "insert", Sets: []types.KV{
+				{Field: "created", Value: "2024-01-15T10:30:00Z"},
+				{Field: "id", Value: "1"},
+				{Field: "title", Value: "a"},
+			}}},
+		},
+		{
+			name:   "delete",
+			before: map[string]any{"id": "1", "title": "a"},
+			want:   []types.Change{{Model: "posts", Action: "delete", Where: whereEq("id", "1")}},
+		},
+		{
+			name:   "update sets only changed fields",
+			before: map[string]any{"id": "1", "title": "a", "views": 1, "draft": true},
+			after:  map[string]any{"id": "1", "title": "b", "views": 1.0},
+			want: []types.Change{{Model: "posts", Action: "update", Where: whereEq("id", "1"), Sets: []types.KV{
+				{Field: "title", Value: "b"},
+			}}},
+		},
+		{
+			name:   "key change matches the old key",
+			before: map[string]any{"id": "1"},
+			after:  map[string]any{"id": "2"},
+			want: []types.Change{{Model: "posts", Action: "update", Where: whereEq("id", "1"), Sets: []types.KV{
+				{Field: "id", Value: "2"},
+			}}},
+		},
+		{
+			name:   "unchanged row",
+			before: map[string]any{"id": "1", "title": "a"},
+			after:  map[string]any{"id": "1", "title": "a"},
+		},
+		{
+			name:   "missing key matches every field",
+			before: map[string]any{"slug": "a", "parent": nil},
+			want: []types.Change{{Model: "posts", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{
+				types.IsNull("parent"),
+				types.Eq("slug", "a"),
+			}}}},
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got := diff.Changes("posts", tt.before, tt.after)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Changes() =\n  %+v\nwant\n  %+v", got, tt.want)
+			}
+			if len(got) > 0 {
+				if err := tests.ValidateMutationEvent(&types.Mutation{Changes: got}); err != nil {
+					t.Errorf("derived mutation is invalid: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestDifferNullMissing(t *testing.T) {
+	before := map[string]any{"id": "1", "title": "a", "draft": true}
+	after := map[string]any{"id": "1", "title": "a"}
+
+	if got := diff.Changes("posts", before, after); got != nil {
+		t.Errorf("missing fields should not change by default, got %+v", got)
+	}
+
+	got := diff.Differ{Key: "id", NullMissing: true}.Changes("posts", before, after)
+	want := []types.Change{{Model: "posts", Action: "update", Where: whereEq("id", "1"), Sets: []types.KV{
+		{Field: "draft", Value: nil},
+	}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Changes() = %+v, want %+v", got, want)
+	}
+}
+
+func TestDifferKeyFor(t *testing.T) {
+	d := diff.Differ{Key: "id", Models: map[string]string{"tags": "slug"}}
+	got := d.Changes("tags", map[string]any{"id": "1", "slug": "go"}, nil)
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Where, whereEq("slug", "go")) {
+		t.Errorf("expected delete keyed by slug, got %+v", got)
+	}
+}
+
+func TestDifferCompositeKeys(t *testing.T) {
+	d := diff.Differ{Key: "id", Keys: map[string][]string{"memberships": {"org_id", "user_id"}}}
+	before := map[string]any{"org_id": "o1", "user_id": "u1", "role": "admin"}
+	got := d.Changes("memberships", before, nil)
+	want := &types.Filter{Conditions: &[]types.Condition{types.Eq("org_id", "o1"), types.Eq("user_id", "u1")}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Where, want) {
+		t.Errorf("expected delete keyed by org_id and user_id, got %+v", got)
+	}
+
+	delete(before, "user_id")
+	got = d.Changes("memberships", before, nil)
+	want = &types.Filter{Conditions: &[]types.Condition{types.Eq("org_id", "o1"), types.Eq("role", "admin")}}
+	if len(got) != 1 || !reflect.DeepEqual(got[0].Where, want) {
+		t.Errorf("expected delete matching every field, got %+v", got)
+	}
+}
@@ -0,0 +1,152 @@
+// Package infer predicts the result structure of IncludeKit Universal Format
+// Statements, so SDK code generators can emit typed result structs for a
+// given Statement.
+package infer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Schema describes the models a statement reads. mock.AppSchema implements it.
+type Schema interface {
+	// ModelFields returns the fields of a model, or nil if they are unknown.
+	ModelFields(model string) []string
+	// RelationTarget resolves a relation of a model to its target model and
+	// whether it is to-many.
+	RelationTarget(model, relation string) (target string, many bool, ok bool)
+}
+
+// Shape describes the rows produced for one model in a result.
+type Shape struct {
+	// Model is the model the rows belong to.
+	Model string
+	// Many is true when the result is a list of rows (the root and to-many
+	// relations) and false for to-one relations.
+	Many bool
+	// Fields lists the columns of each row, in selection order.
+	Fields []Field
+	// AllFields is true when no fields were selected and the schema does not
+	// declare the model fields, so every column may be present.
+	AllFields bool
+	// Relations lists the loaded relations nested in each row.
+	Relations []Relation
+}
+
+// Field is one result column.
+type Field struct {
+	// Name is the column name in the result row (the alias for aggregates).
+	Name string
+	// Aggregate is the aggregate function ("count", "sum", "avg", "min",
+	// "max"), or "" for plain fields.
+	Aggregate string
+	// Source is the aggregated expression (e.g., "*" or "views"), or the
+	// field name for plain fields.
+	Source string
+}
+
+// Relation is a loaded relation nested in a result row.
+type Relation struct {
+	Name  string
+	Shape *Shape
+}
+
+var aggregateRe = regexp.MustCompile(`(?i)^(count|sum|avg|min|max)\(\s*([^)]*?)\s*\)(?:\s+as\s+([A-Za-z_][A-Za-z0-9_]*))?$`)
+
+// ResultShape predicts the nested result structure of stmt.
+//
+// Includes with Kind set only filter their parent and are not part of the
+// result unless they select fields. Returns an error if the statement has
+// no query or an include names a relation the schema does not know.
+func ResultShape(stmt *types.Statement, schema Schema) (*Shape, error) {
+	if stmt == nil || stmt.Query == nil {
+		return nil, fmt.Errorf("infer: statement has no query")
+	}
+
+	root := &Shape{Model: stmt.Query.Model, Many: true}
+	root.Fields, root.AllFields = fields(stmt.Query, schema.ModelFields(root.Model))
+
+	if err := addRelations(root, stmt.Includes, schema, "statement"); err != nil {
+		return nil, err
+	}
+	return root, nil
+}
+
+func addRelations(parent *Shape, includes []types.Include, schema Schema, path string) error {
+	for i, include := range includes {
+		includePath := fmt.Sprintf("%s.includes[%d]", path, i)
+		if include.Query == nil {
+			return fmt.Errorf("infer: %s: include has no query", includePath)
+		}
+		if include.Kind != nil && len(include.Query.GetFields()) == 0 {
+			continue
+		}
+
+		name := include.Query.Model
+		target, many, ok := schema.RelationTarget(parent.Model, name)
+		if !ok {
+			return fmt.Errorf("infer: %s: unknown relation %s on model %s", includePath, name, parent.Model)
+		}
+
+		shape := &Shape{Model: target, Many: many}
+		shape.Fields, shape.AllFields = fields(include.Query, schema.ModelFields(target))
+		if err := addRelations(shape, include.Includes, schema, includePath); err != nil {
+			return err
+		}
+		parent.Relations = append(parent.Relations, Relation{Name: name, Shape: shape})
+	}
+	return nil
+}
+
+func fields(q *types.Query, declared []string) ([]Field, bool) {
+	selected := q.GetFields()
+	aggregations := q.GetAggregations()
+	if len(selected) == 0 && len(aggregations) == 0 {
+		selected = declared
+	}
+	if len(selected) == 0 && len(aggregations) == 0 {
+		return nil, true
+	}
+
+	out := make([]Field, 0, len(selected)+len(aggregations))
+	for _, f := range selected {
+		out = append(out, parseField(f))
+	}
+	for i := range aggregations {
+		out = append(out, aggregateField(&aggregations[i]))
+	}
+	return out, false
+}
+
+// aggregateField describes a typed aggregation, named by its result column.
+func aggregateField(a *types.Aggregate) Field {
+	source, ok := a.GetField()
+	if !ok {
+		source = "*"
+	}
+	return Field{Name: a.Column(), Aggregate: a.Func, Source: source}
+}
+
+// parseField recognizes aggregate selections such as "COUNT(*) as count".
+// Aggregates without an alias are named "<fn>" for "*" and "<fn>_<arg>"
+// otherwise.
+func parseField(f string) Field {
+	m := aggregateRe.FindStringSubmatch(strings.TrimSpace(f))
+	if m == nil {
+		return Field{Name: f, Source: f}
+	}
+
+	fn := strings.ToLower(m[1])
+	source := m[2]
+	name := m[3]
+	if name == "" {
+		name = fn
+		if source != "*" && source != "" {
+			name = fn + "_" + source
+		}
+	}
+	return Field{Name: name, Aggregate: fn, Source: source}
+}
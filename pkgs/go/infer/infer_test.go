@@ -0,0 +1,125 @@
+package infer_test
+
+import (
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/infer"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+var schema = mock.AppSchema{
+	Version: 1,
+	Models: []mock.Model{
+		{Name: "User", Fields: []string{"id", "name"}, Relations: []mock.Relation{
+			{Name: "posts", Target: "Post", Kind: "many"},
+		}},
+		{Name: "Post", Relations: []mock.Relation{
+			{Name: "author", Target: "User", Kind: "one"},
+		}},
+	},
+}
+
+func TestResultShape(t *testing.T) {
+	some := "some"
+	stmt := &types.Statement{
+		Query: &types.Query{Model: "User"},
+		Includes: []types.Include{
+			{
+				Query:    &types.Query{Model: "posts", Fields: &[]string{"id", "title"}},
+				Includes: []types.Include{{Query: &types.Query{Model: "author"}}},
+			},
+			{Kind: &some, Query: &types.Query{Model: "posts"}},
+		},
+	}
+
+	shape, err := infer.ResultShape(stmt, schema)
+	if err != nil {
+		t.Fatalf("ResultShape failed: %v", err)
+	}
+
+	if !shape.Many || len(shape.Fields) != 2 || shape.Fields[1].Name != "name" {
+		t.Errorf("Unexpected root shape: %+v", shape)
+	}
+	if len(shape.Relations) != 1 {
+		t.Fatalf("Relation filters should not appear in the result, got %d relations", len(shape.Relations))
+	}
+
+	posts := shape.Relations[0].Shape
+	if posts.Model != "Post" || !posts.Many || posts.Fields[1].Name != "title" {
+		t.Errorf("Unexpected posts shape: %+v", posts)
+	}
+
+	author := posts.Relations[0].Shape
+	if author.Model != "User" || author.Many {
+		t.Errorf("author should be a single User, got %+v", author)
+	}
+}
+
+func TestResultShapeAggregates(t *testing.T) {
+	stmt := &types.Statement{
+		Query:   &types.Query{Model: "Post", Fields: &[]string{"authorId", "COUNT(*) as count", "max(views)"}},
+		GroupBy: &[]string{"authorId"},
+	}
+
+	shape, err := infer.ResultShape(stmt, schema)
+	if err != nil {
+		t.Fatalf("ResultShape failed: %v", err)
+	}
+
+	want := []infer.Field{
+		{Name: "authorId", Source: "authorId"},
+		{Name: "count", Aggregate: "count", Source: "*"},
+		{Name: "max_views", Aggregate: "max", Source: "views"},
+	}
+	for i, w := range want {
+		if shape.Fields[i] != w {
+			t.Errorf("Fields[%d] = %+v, want %+v", i, shape.Fields[i], w)
+		}
+	}
+}
+
+func TestResultShapeTypedAggregations(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{
+			Model:        "Post",
+			Fields:       &[]string{"authorId"},
+			Aggregations: &[]types.Aggregate{types.Count(""), types.Sum("views").As("totalViews")},
+		},
+		GroupBy: &[]string{"authorId"},
+	}
+
+	shape, err := infer.ResultShape(stmt, schema)
+	if err != nil {
+		t.Fatalf("ResultShape failed: %v", err)
+	}
+
+	want := []infer.Field{
+		{Name: "authorId", Source: "authorId"},
+		{Name: "count", Aggregate: "count", Source: "*"},
+		{Name: "totalViews", Aggregate: "sum", Source: "views"},
+	}
+	if len(shape.Fields) != len(want) {
+		t.Fatalf("Fields = %+v, want %+v", shape.Fields, want)
+	}
+	for i, w := range want {
+		if shape.Fields[i] != w {
+			t.Errorf("Fields[%d] = %+v, want %+v", i, shape.Fields[i], w)
+		}
+	}
+}
+
+func TestResultShapeUnknown(t *testing.T) {
+	shape, err := infer.ResultShape(&types.Statement{Query: &types.Query{Model: "Post"}}, schema)
+	if err != nil || !shape.AllFields {
+		t.Errorf("Undeclared fields should set AllFields, got %+v (%v)", shape, err)
+	}
+
+	_, err = infer.ResultShape(&types.Statement{
+		Query:    &types.Query{Model: "Post"},
+		Includes: []types.Include{{Query: &types.Query{Model: "tags"}}},
+	}, schema)
+	if err == nil {
+		t.Error("Expected unknown relation error")
+	}
+}
@@ -0,0 +1,322 @@
+// Package limits provides a resource limit guard for untrusted IncludeKit
+// Universal Format input.
+//
+// Every ingress point (the tests validators and Decode functions, and the
+// httpserver) checks input with the same Guard, so all of them apply
+// identical DoS protections and fail with the same *LimitError.
+package limits
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// ErrLimitExceeded is matched by every LimitError via errors.Is.
+var ErrLimitExceeded = errors.New("limit exceeded")
+
+// LimitError reports which limit was exceeded and where.
+type LimitError struct {
+	Limit string // "payload_bytes" | "json_depth" | "nodes" | "depth" | "includes"
+	Max   int
+	Path  string
+}
+
+func (e *LimitError) Error() string {
+	if e.Path != "" {
+		return fmt.Sprintf("%s limit of %d exceeded at %s", e.Limit, e.Max, e.Path)
+	}
+	return fmt.Sprintf("%s limit of %d exceeded", e.Limit, e.Max)
+}
+
+// Unwrap makes errors.Is(err, ErrLimitExceeded) true.
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// Guard bounds the size and shape of untrusted input. A zero field means no
+// limit for that dimension.
+type Guard struct {
+	// MaxPayloadBytes bounds the encoded JSON size of a document.
+	MaxPayloadBytes int
+	// MaxJSONDepth bounds the nesting of JSON objects and arrays in a
+	// document, checked by Reader before it is decoded.
+	MaxJSONDepth int
+	// MaxNodes bounds the total number of queries, filters, conditions,
+	// includes, orderBy entries and changes in a document.
+	MaxNodes int
+	// MaxDepth bounds the nesting depth of filters (and/or/not, and condition
+	// subqueries) and of includes, counting the top level as 1.
+	MaxDepth int
+	// MaxIncludes bounds the total number of includes in a statement.
+	MaxIncludes int
+}
+
+// Default is a conservative guard suitable for engine servers.
+var Default = Guard{
+	MaxPayloadBytes: 1 << 20,
+	MaxJSONDepth:    256,
+	MaxNodes:        10000,
+	MaxDepth:        32,
+	MaxIncludes:     64,
+}
+
+// CheckPayload checks the encoded size of a document.
+func (g Guard) CheckPayload(n int) error {
+	if g.MaxPayloadBytes > 0 && n > g.MaxPayloadBytes {
+		return &LimitError{Limit: "payload_bytes", Max: g.MaxPayloadBytes}
+	}
+	return nil
+}
+
+// Reader returns a reader passing JSON through from r that fails with a
+// *LimitError at path once more than MaxPayloadBytes have been read or
+// objects and arrays nest deeper than MaxJSONDepth, so a hostile document is
+// rejected before it is buffered or decoded in full.
+func (g Guard) Reader(r io.Reader, path string) *Reader {
+	return &Reader{r: r, guard: g, path: path}
+}
+
+// Reader is the io.Reader returned by Guard.Reader.
+type Reader struct {
+	r     io.Reader
+	guard Guard
+	path  string
+
+	n, depth         int
+	inString, escape bool
+	err              error
+}
+
+// Err returns the *LimitError the reader failed with, or nil.
+func (l *Reader) Err() error {
+	return l.err
+}
+
+func (l *Reader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+	n, err := l.r.Read(p)
+	for i, b := range p[:n] {
+		if max := l.guard.MaxPayloadBytes; max > 0 && l.n+i >= max {
+			l.err = &LimitError{Limit: "payload_bytes", Max: max, Path: l.path}
+			return i, l.err
+		}
+		if l.scan(b) {
+			l.err = &LimitError{Limit: "json_depth", Max: l.guard.MaxJSONDepth, Path: l.path}
+			return i, l.err
+		}
+	}
+	l.n += n
+	return n, err
+}
+
+// scan tracks the nesting depth through b, reporting whether it exceeds
+// MaxJSONDepth. Brackets inside strings do not count.
+func (l *Reader) scan(b byte) bool {
+	switch {
+	case l.escape:
+		l.escape = false
+	case l.inString:
+		switch b {
+		case '\\':
+			l.escape = true
+		case '"':
+			l.inString = false
+		}
+	case b == '"':
+		l.inString = true
+	case b == '{' || b == '[':
+		l.depth++
+		return l.guard.MaxJSONDepth > 0 && l.depth > l.guard.MaxJSONDepth
+	case b == '}' || b == ']':
+		l.depth--
+	}
+	return false
+}
+
+// CheckStatement checks node count, depth and include count of a statement.
+// Traversal stops at the first exceeded limit, so oversized input is never
+// walked in full.
+func (g Guard) CheckStatement(stmt *types.Statement) error {
+	if stmt == nil {
+		return nil
+	}
+	c := counter{guard: g}
+	if err := c.query(stmt.Query, "statement.query", 1); err != nil {
+		return err
+	}
+	if err := c.filter(stmt.Having, "statement.having", 1); err != nil {
+		return err
+	}
+	if err := c.filter(stmt.Policy, "statement.policy", 1); err != nil {
+		return err
+	}
+	return c.includes(stmt.Includes, "statement", 1)
+}
+
+// CheckMutation checks node count and filter depth of a mutation.
+func (g Guard) CheckMutation(m *types.Mutation) error {
+	c := counter{guard: g}
+	return c.mutation(m, "mutation")
+}
+
+// CheckTransaction checks node count and filter depth of a transaction,
+// counting the changes of all its mutations together.
+func (g Guard) CheckTransaction(tx *types.Transaction) error {
+	if tx == nil {
+		return nil
+	}
+	c := counter{guard: g}
+	for i := range tx.Mutations {
+		if err := c.mutation(&tx.Mutations[i], fmt.Sprintf("transaction.mutations[%d]", i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckDependencies checks node count, depth and include count of
+// dependencies.
+func (g Guard) CheckDependencies(deps *types.Dependencies) error {
+	if deps == nil {
+		return nil
+	}
+	c := counter{guard: g}
+	for i := range deps.Filters {
+		if err := c.filter(&deps.Filters[i], fmt.Sprintf("dependencies.filters[%d]", i), 1); err != nil {
+			return err
+		}
+	}
+	if err := c.filter(deps.Policy, "dependencies.policy", 1); err != nil {
+		return err
+	}
+	return c.includes(deps.Includes, "dependencies", 1)
+}
+
+type counter struct {
+	guard    Guard
+	nodes    int
+	includeN int
+}
+
+func (c *counter) node(path string) error {
+	c.nodes++
+	if c.guard.MaxNodes > 0 && c.nodes > c.guard.MaxNodes {
+		return &LimitError{Limit: "nodes", Max: c.guard.MaxNodes, Path: path}
+	}
+	return nil
+}
+
+func (c *counter) mutation(m *types.Mutation, path string) error {
+	if m == nil {
+		return nil
+	}
+	for i := range m.Changes {
+		changePath := fmt.Sprintf("%s.changes[%d]", path, i)
+		if err := c.node(changePath); err != nil {
+			return err
+		}
+		if err := c.filter(m.Changes[i].Where, changePath+".where", 1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *counter) depth(depth int, path string) error {
+	if c.guard.MaxDepth > 0 && depth > c.guard.MaxDepth {
+		return &LimitError{Limit: "depth", Max: c.guard.MaxDepth, Path: path}
+	}
+	return nil
+}
+
+// query counts q, whose where filter starts at depth.
+func (c *counter) query(q *types.Query, path string, depth int) error {
+	if q == nil {
+		return nil
+	}
+	if err := c.node(path); err != nil {
+		return err
+	}
+	if err := c.filter(q.Where, path+".where", depth); err != nil {
+		return err
+	}
+	for i, j := range q.GetJoins() {
+		if err := c.filter(j.On, fmt.Sprintf("%s.joins[%d].on", path, i), depth); err != nil {
+			return err
+		}
+	}
+	for i := range q.GetOrderBy() {
+		if err := c.node(fmt.Sprintf("%s.order_by[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *counter) filter(f *types.Filter, path string, depth int) error {
+	if f == nil {
+		return nil
+	}
+	if err := c.depth(depth, path); err != nil {
+		return err
+	}
+	if err := c.node(path); err != nil {
+		return err
+	}
+	for i := range f.GetAnd() {
+		if err := c.filter(&(*f.And)[i], fmt.Sprintf("%s.and[%d]", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	for i := range f.GetOr() {
+		if err := c.filter(&(*f.Or)[i], fmt.Sprintf("%s.or[%d]", path, i), depth+1); err != nil {
+			return err
+		}
+	}
+	if err := c.filter(f.Not, path+".not", depth+1); err != nil {
+		return err
+	}
+	for i := range f.GetConditions() {
+		condPath := fmt.Sprintf("%s.conditions[%d]", path, i)
+		if err := c.node(condPath); err != nil {
+			return err
+		}
+		if sub := (*f.Conditions)[i].Subquery; sub != nil {
+			if err := c.depth(depth+1, condPath+".subquery"); err != nil {
+				return err
+			}
+			if err := c.query(sub, condPath+".subquery", depth+1); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *counter) includes(includes []types.Include, path string, depth int) error {
+	for i := range includes {
+		includePath := fmt.Sprintf("%s.includes[%d]", path, i)
+		if err := c.depth(depth, includePath); err != nil {
+			return err
+		}
+		c.includeN++
+		if c.guard.MaxIncludes > 0 && c.includeN > c.guard.MaxIncludes {
+			return &LimitError{Limit: "includes", Max: c.guard.MaxIncludes, Path: includePath}
+		}
+		if err := c.node(includePath); err != nil {
+			return err
+		}
+		if err := c.query(includes[i].Query, includePath+".query", 1); err != nil {
+			return err
+		}
+		if err := c.includes(includes[i].Includes, includePath, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package limits_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/limits"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func nestedFilter(depth int) *types.Filter {
+	f := &types.Filter{Conditions: &[]types.Condition{types.Eq("a", 1)}}
+	for i := 1; i < depth; i++ {
+		f = &types.Filter{Not: f}
+	}
+	return f
+}
+
+func TestCheckStatement(t *testing.T) {
+	tcs := []struct {
+		name  string
+		guard limits.Guard
+		stmt  *types.Statement
+		limit string
+		path  string
+	}{
+		{
+			name:  "within limits",
+			guard: limits.Default,
+			stmt:  types.NewStatement(types.WithModel("posts"), types.WithWhere(*nestedFilter(5))),
+		},
+		{
+			name:  "filter depth",
+			guard: limits.Guard{MaxDepth: 3},
+			stmt:  types.NewStatement(types.WithModel("posts"), types.WithWhere(*nestedFilter(4))),
+			limit: "depth",
+			path:  "statement.query.where.not.not.not",
+		},
+		{
+			name:  "subquery depth",
+			guard: limits.Guard{MaxDepth: 2},
+			stmt: types.NewStatement(types.WithModel("posts"), types.WithConditions(
+				types.InQuery("authorId", types.Query{Model: "users", Fields: &[]string{"id"}, Where: &types.Filter{
+					Conditions: &[]types.Condition{types.InQuery("orgId", types.Query{Model: "orgs", Fields: &[]string{"id"}})},
+				}}),
+			)),
+			limit: "depth",
+			path:  "statement.query.where.conditions[0].subquery.where.conditions[0].subquery",
+		},
+		{
+			name:  "node count",
+			guard: limits.Guard{MaxNodes: 3},
+			stmt: types.NewStatement(types.WithModel("posts"),
+				types.WithConditions(types.Eq("a", 1), types.Eq("b", 2), types.Eq("c", 3))),
+			limit: "nodes",
+			path:  "statement.query.where.conditions[1]",
+		},
+		{
+			name:  "include count",
+			guard: limits.Guard{MaxIncludes: 1},
+			stmt: types.NewStatement(types.WithModel("users"),
+				types.WithInclude(types.Include{Query: &types.Query{Model: "posts"}}),
+				types.WithInclude(types.Include{Query: &types.Query{Model: "comments"}})),
+			limit: "includes",
+			path:  "statement.includes[1]",
+		},
+		{
+			name:  "include depth",
+			guard: limits.Guard{MaxDepth: 1},
+			stmt: types.NewStatement(types.WithModel("users"),
+				types.WithInclude(types.Include{
+					Query:    &types.Query{Model: "posts"},
+					Includes: []types.Include{{Query: &types.Query{Model: "comments"}}},
+				})),
+			limit: "depth",
+			path:  "statement.includes[0].includes[0]",
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.guard.CheckStatement(tt.stmt)
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatalf("Unexpected error: %v", err)
+				}
+				return
+			}
+			var lerr *limits.LimitError
+			if !errors.As(err, &lerr) {
+				t.Fatalf("Expected LimitError, got %v", err)
+			}
+			if lerr.Limit != tt.limit || lerr.Path != tt.path {
+				t.Errorf("got %s at %s, want %s at %s", lerr.Limit, lerr.Path, tt.limit, tt.path)
+			}
+			if !errors.Is(err, limits.ErrLimitExceeded) {
+				t.Error("LimitError should match ErrLimitExceeded")
+			}
+		})
+	}
+}
+
+func TestCheckPayloadAndMutation(t *testing.T) {
+	g := limits.Guard{MaxPayloadBytes: 10, MaxDepth: 2}
+	if err := g.CheckPayload(11); err == nil {
+		t.Error("Expected payload limit error")
+	}
+	if err := g.CheckPayload(10); err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	m := &types.Mutation{Changes: []types.Change{{Model: "posts", Action: "delete", Where: nestedFilter(3)}}}
+	if err := g.CheckMutation(m); !errors.Is(err, limits.ErrLimitExceeded) {
+		t.Errorf("Expected depth limit error, got %v", err)
+	}
+}
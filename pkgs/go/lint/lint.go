@@ -0,0 +1,128 @@
+// Package lint flags risky Statement shapes of the IncludeKit Universal
+// Format, so SDKs can surface caching anti-patterns at development time.
+//
+// Lint warnings are advisory: a statement with warnings is still valid.
+package lint
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/walk"
+)
+
+// Warning codes
+const (
+	CodeUnbounded      = "unbounded"       // no limit and no pagination
+	CodeLargeInList    = "large-in-list"   // in/notIn list above MaxInList values
+	CodeRegexUnindexed = "regex-unindexed" // regex on a field that does not look indexed
+	CodeDeepIncludes   = "deep-includes"   // includes nested deeper than MaxIncludeDepth
+	CodeNoTiebreaker   = "no-tiebreaker"   // orderBy without a unique tiebreaker field
+)
+
+// Warning describes one risky construct and how to fix it.
+type Warning struct {
+	Code    string
+	Path    string
+	Message string
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("%s: %s at %s", w.Code, w.Message, w.Path)
+}
+
+// Linter holds lint thresholds. The zero value uses the defaults.
+type Linter struct {
+	// MaxInList is the largest in/notIn list accepted without a warning (default 100).
+	MaxInList int
+	// MaxIncludeDepth is the deepest include nesting accepted without a warning (default 3).
+	MaxIncludeDepth int
+	// IsIndexed reports whether a field is indexed. Defaults to a heuristic
+	// that treats "id" and fields ending in "Id" or "_id" as indexed.
+	IsIndexed func(field string) bool
+	// IsUnique reports whether a field is unique and can break orderBy ties.
+	// Defaults to treating only "id" as unique.
+	IsUnique func(field string) bool
+}
+
+// Statement lints a statement with the default thresholds.
+func Statement(stmt *types.Statement) []Warning {
+	return Linter{}.Statement(stmt)
+}
+
+// Statement lints a statement and returns its warnings in traversal order.
+func (l Linter) Statement(stmt *types.Statement) []Warning {
+	if stmt == nil {
+		return nil
+	}
+	l = l.withDefaults()
+	var warnings []Warning
+	add := func(code, path, msg string) {
+		warnings = append(warnings, Warning{Code: code, Path: path, Message: msg})
+	}
+
+	if stmt.Query != nil && stmt.Query.Limit == nil && stmt.Pagination == nil {
+		add(CodeUnbounded, "statement.query", "query has no limit and no pagination; add a limit or paginate")
+	}
+
+	if orderBy := stmt.GetOrderBy(); len(orderBy) > 0 && !l.hasTiebreaker(orderBy) {
+		add(CodeNoTiebreaker, "statement.query.order_by",
+			"order_by has no unique tiebreaker; append a unique field (e.g., id) for stable pages")
+	}
+
+	_ = walk.Walk(stmt, walk.Visitor{
+		Condition: func(path string, c *types.Condition) error {
+			switch c.Op {
+			case "in", "notIn":
+				if list, ok := c.Value.([]any); ok && len(list) > l.MaxInList {
+					add(CodeLargeInList, path, fmt.Sprintf(
+						"%s list has %d values (max %d); consider a join or a range condition", c.Op, len(list), l.MaxInList))
+				}
+			case "regex":
+				if !l.IsIndexed(c.Field) {
+					add(CodeRegexUnindexed, path, fmt.Sprintf(
+						"regex on %s likely scans the table; prefer startsWith or an indexed field", c.Field))
+				}
+			}
+			return nil
+		},
+		Include: func(path string, _ *types.Include) error {
+			if depth := strings.Count(path, "includes["); depth > l.MaxIncludeDepth {
+				add(CodeDeepIncludes, path, fmt.Sprintf(
+					"include depth %d exceeds %d; split the statement or flatten the relations", depth, l.MaxIncludeDepth))
+				return walk.SkipChildren
+			}
+			return nil
+		},
+	})
+
+	return warnings
+}
+
+func (l Linter) withDefaults() Linter {
+	if l.MaxInList <= 0 {
+		l.MaxInList = 100
+	}
+	if l.MaxIncludeDepth <= 0 {
+		l.MaxIncludeDepth = 3
+	}
+	if l.IsIndexed == nil {
+		l.IsIndexed = func(field string) bool {
+			return field == "id" || strings.HasSuffix(field, "Id") || strings.HasSuffix(field, "_id")
+		}
+	}
+	if l.IsUnique == nil {
+		l.IsUnique = func(field string) bool { return field == "id" }
+	}
+	return l
+}
+
+func (l Linter) hasTiebreaker(orderBy []types.OrderBy) bool {
+	for _, ob := range orderBy {
+		if l.IsUnique(ob.Field) {
+			return true
+		}
+	}
+	return false
+}
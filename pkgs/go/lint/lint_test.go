@@ -0,0 +1,84 @@
+package lint_test
+
+import (
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/lint"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func codes(warnings []lint.Warning) []string {
+	out := make([]string, len(warnings))
+	for i, w := range warnings {
+		out[i] = w.Code
+	}
+	return out
+}
+
+func TestStatementClean(t *testing.T) {
+	stmt := types.NewStatement(
+		types.WithModel("posts"),
+		types.WithOrderBy(types.OrderBy{Field: "createdAt"}, types.OrderBy{Field: "id"}),
+		types.WithPagination(types.Pagination{First: intPtr(20)}),
+		types.WithConditions(types.In("authorId", "a", "b")),
+	)
+
+	if warnings := lint.Statement(stmt); len(warnings) != 0 {
+		t.Errorf("Expected no warnings, got %v", warnings)
+	}
+}
+
+func TestStatementWarnings(t *testing.T) {
+	ids := make([]any, 101)
+	for i := range ids {
+		ids[i] = i
+	}
+	stmt := types.NewStatement(
+		types.WithModel("posts"),
+		types.WithOrderBy(types.OrderBy{Field: "createdAt"}),
+		types.WithConditions(
+			types.In("authorId", ids...),
+			types.Condition{Field: "body", Op: "regex", Value: ".*foo"},
+		),
+		types.WithInclude(types.Include{
+			Query: &types.Query{Model: "a"},
+			Includes: []types.Include{{
+				Query: &types.Query{Model: "b"},
+				Includes: []types.Include{{
+					Query:    &types.Query{Model: "c"},
+					Includes: []types.Include{{Query: &types.Query{Model: "d"}}},
+				}},
+			}},
+		}),
+	)
+
+	got := codes(lint.Statement(stmt))
+	want := []string{lint.CodeUnbounded, lint.CodeNoTiebreaker, lint.CodeLargeInList, lint.CodeRegexUnindexed, lint.CodeDeepIncludes}
+	if len(got) != len(want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("warning[%d] = %s, want %s", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLinterThresholds(t *testing.T) {
+	l := lint.Linter{MaxInList: 1, IsUnique: func(field string) bool { return field == "slug" }}
+	stmt := types.NewStatement(
+		types.WithModel("posts"),
+		types.WithLimit(10),
+		types.WithOrderBy(types.OrderBy{Field: "slug"}),
+		types.WithConditions(types.In("status", "a", "b")),
+	)
+
+	got := codes(l.Statement(stmt))
+	if len(got) != 1 || got[0] != lint.CodeLargeInList {
+		t.Errorf("Expected only large-in-list, got %v", got)
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
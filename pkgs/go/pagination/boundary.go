@@ -0,0 +1,155 @@
+package pagination
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Boundary builds the PaginationBoundary of a page whose last row is lastRow:
+// the orderBy and the row's orderBy values, coerced with types.Value.
+//
+// Only a full page has a boundary; a page shorter than its size holds every
+// row after its start, so engines must leave Dependencies.LastRow unset.
+// Returns an error if orderBy is empty, the row lacks an orderBy field, or a
+// value cannot be coerced.
+func Boundary(orderBy []types.OrderBy, lastRow map[string]any) (*types.PaginationBoundary, error) {
+	if len(orderBy) == 0 {
+		return nil, fmt.Errorf("pagination: orderBy is required to build a boundary")
+	}
+
+	row := make(map[string]any, len(orderBy))
+	for _, ob := range orderBy {
+		value, ok := lastRow[ob.Field]
+		if !ok {
+			return nil, fmt.Errorf("pagination: row is missing orderBy field %q", ob.Field)
+		}
+		coerced, err := types.Value(value)
+		if err != nil {
+			return nil, fmt.Errorf("pagination: field %q: %w", ob.Field, err)
+		}
+		row[ob.Field] = coerced
+	}
+	return &types.PaginationBoundary{OrderBy: append([]types.OrderBy(nil), orderBy...), Row: row}, nil
+}
+
+// CompareBoundary places row relative to the last row of a page under the
+// boundary's orderBy: -1 if it sorts before, 0 if it ties on every orderBy
+// field, 1 if it sorts after. Values compare as JSON values, case-folded
+// where an orderBy is case-insensitive. As in SQL, nulls sort as if larger
+// than any value (last ascending, first descending) unless NullsFirst says
+// otherwise.
+//
+// Reports false if row lacks an orderBy field the comparison reaches, or
+// holds a value that does not compare with the boundary's (e.g. a string
+// against a number).
+func CompareBoundary(boundary *types.PaginationBoundary, row map[string]any) (int, bool) {
+	for _, ob := range boundary.OrderBy {
+		raw, ok := row[ob.Field]
+		if !ok {
+			return 0, false
+		}
+		a, errA := jsonValue(raw)
+		b, errB := jsonValue(boundary.Row[ob.Field])
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+
+		if a == nil || b == nil {
+			if a == nil && b == nil {
+				continue
+			}
+			nullsFirst := ob.IsDescending()
+			if nf, ok := ob.GetNullsFirst(); ok {
+				nullsFirst = nf
+			}
+			if (a == nil) == nullsFirst {
+				return -1, true
+			}
+			return 1, true
+		}
+
+		if cs, ok := ob.GetCaseSensitive(); ok && !cs {
+			a, b = foldCase(a), foldCase(b)
+		}
+		cmp, ok := compareValues(a, b)
+		if !ok {
+			return 0, false
+		}
+		if ob.IsDescending() {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp, true
+		}
+	}
+	return 0, true
+}
+
+// compareValues compares two numbers, strings or booleans. It reports false
+// if the values are of different kinds.
+func compareValues(a, b any) (int, bool) {
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av, bv), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av == bv:
+			return 0, true
+		case bv:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+func foldCase(v any) any {
+	if s, ok := v.(string); ok {
+		return strings.ToLower(s)
+	}
+	return v
+}
+
+// jsonValue normalizes v to the value it decodes to from JSON, after
+// coercing it with types.Value.
+func jsonValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	coerced, err := types.Value(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(coerced)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,48 @@
+package pagination_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestBoundary(t *testing.T) {
+	desc := true
+	orderBy := []types.OrderBy{{Field: "createdAt", Descending: &desc}, {Field: "id"}}
+	lastRow := map[string]any{
+		"id":        "post_123",
+		"title":     "ignored",
+		"createdAt": time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	boundary, err := pagination.Boundary(orderBy, lastRow)
+	if err != nil {
+		t.Fatalf("Boundary failed: %v", err)
+	}
+	if want := map[string]any{"createdAt": "2024-01-15T10:30:00Z", "id": "post_123"}; !reflect.DeepEqual(boundary.Row, want) {
+		t.Errorf("Row = %v, want %v", boundary.Row, want)
+	}
+	if !reflect.DeepEqual(boundary.OrderBy, orderBy) {
+		t.Errorf("OrderBy = %v, want %v", boundary.OrderBy, orderBy)
+	}
+
+	newer := map[string]any{"createdAt": time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), "id": "post_999"}
+	if cmp, ok := pagination.CompareBoundary(boundary, newer); !ok || cmp != -1 {
+		t.Errorf("CompareBoundary() = %d, %v; want -1, true", cmp, ok)
+	}
+	if cmp, ok := pagination.CompareBoundary(boundary, lastRow); !ok || cmp != 0 {
+		t.Errorf("CompareBoundary(lastRow) = %d, %v; want 0, true", cmp, ok)
+	}
+}
+
+func TestBoundaryErrors(t *testing.T) {
+	if _, err := pagination.Boundary(nil, map[string]any{"id": 1}); err == nil {
+		t.Error("Expected error for empty orderBy")
+	}
+	if _, err := pagination.Boundary([]types.OrderBy{{Field: "id"}}, map[string]any{}); err == nil {
+		t.Error("Expected error for missing field")
+	}
+}
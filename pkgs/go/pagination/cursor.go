@@ -0,0 +1,44 @@
+// Package pagination provides cursor and boundary helpers for cursor-based
+// pagination in the IncludeKit Universal Format.
+//
+// Cursors are opaque to clients. SDKs build them as the cursors package
+// does: the standard (padded) base64 encoding of a JSON object that maps each
+// orderBy field to its value in the boundary row, with keys in orderBy order.
+package pagination
+
+import (
+	"github.com/bold-minds/includekit-spec/go/cursors"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// CursorFromRow builds the cursor for a result row from the orderBy fields,
+// with cursors.Encode, so cursors.DecodeValues accepts it.
+//
+// Returns an error if orderBy is empty or repeats a field, the row lacks an
+// orderBy field, or a value cannot be encoded.
+func CursorFromRow(orderBy []types.OrderBy, row map[string]any) (string, error) {
+	return cursors.Encode(orderBy, row)
+}
+
+// ParseCursor decodes a cursor into its field values with cursors.Decode,
+// so integers stay exact int64s. It does not check the cursor was built for
+// a sort; CursorValues does.
+//
+// Returns a *cursors.CursorError if the cursor is not a strict, padded
+// base64-encoded JSON object within cursors.MaxCursorBytes.
+func ParseCursor(cursor string) (map[string]any, error) {
+	c, err := cursors.Decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+	return c.Map(), nil
+}
+
+// CursorValues decodes a cursor and returns its values in orderBy order, as
+// cursors.DecodeValues does.
+//
+// Returns a *cursors.CursorError if the cursor is invalid or does not hold
+// exactly the orderBy fields, in order.
+func CursorValues(orderBy []types.OrderBy, cursor string) ([]any, error) {
+	return cursors.DecodeValues(orderBy, cursor)
+}
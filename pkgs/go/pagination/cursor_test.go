@@ -0,0 +1,162 @@
+package pagination_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/cursors"
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestCursorFromRow(t *testing.T) {
+	orderBy := []types.OrderBy{{Field: "createdAt"}, {Field: "id"}}
+	row := map[string]any{
+		"id":        "post_123",
+		"title":     "ignored",
+		"createdAt": time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC),
+	}
+
+	cursor, err := pagination.CursorFromRow(orderBy, row)
+	if err != nil {
+		t.Fatalf("CursorFromRow failed: %v", err)
+	}
+
+	want := "eyJjcmVhdGVkQXQiOiIyMDI0LTAxLTE1VDEwOjMwOjAwWiIsImlkIjoicG9zdF8xMjMifQ=="
+	if cursor != want {
+		t.Errorf("CursorFromRow() = %s, want %s", cursor, want)
+	}
+
+	values, err := pagination.CursorValues(orderBy, cursor)
+	if err != nil {
+		t.Fatalf("CursorValues failed: %v", err)
+	}
+	if values[0] != "2024-01-15T10:30:00Z" || values[1] != "post_123" {
+		t.Errorf("Unexpected cursor values: %v", values)
+	}
+}
+
+func TestCursorFromRowErrors(t *testing.T) {
+	if _, err := pagination.CursorFromRow(nil, map[string]any{"id": 1}); err == nil {
+		t.Error("Expected error for empty orderBy")
+	}
+	if _, err := pagination.CursorFromRow([]types.OrderBy{{Field: "id"}}, map[string]any{}); err == nil {
+		t.Error("Expected error for missing field")
+	}
+}
+
+func TestParseCursorErrors(t *testing.T) {
+	for _, cursor := range []string{
+		"not base64!",
+		"bnVsbA==",    // null
+		"WzFd",        // [1]
+		"eyJpZCI6MX0", // {"id":1} without padding
+	} {
+		if _, err := pagination.ParseCursor(cursor); err == nil {
+			t.Errorf("ParseCursor(%q) should fail", cursor)
+		}
+	}
+}
+
+// TestCursorKeepsLargeIntegers checks that an int64 past 2^53 survives a
+// page round trip, where a float64 would round it to a neighbouring row.
+func TestCursorKeepsLargeIntegers(t *testing.T) {
+	const id = int64(1)<<53 + 1
+	stmt := types.NewStatement(types.WithModel("posts"), types.WithOrderBy(types.OrderBy{Field: "id"}), types.WithPagination(types.Pagination{First: intPtr(10)}))
+	next, err := pagination.NextPage(stmt, map[string]any{"id": id})
+	if err != nil {
+		t.Fatalf("NextPage failed: %v", err)
+	}
+	values, err := pagination.CursorValues(next.GetOrderBy(), *next.Pagination.After)
+	if err != nil {
+		t.Fatalf("CursorValues failed: %v", err)
+	}
+	if values[0] != id {
+		t.Errorf("CursorValues() = %#v, want %d", values, id)
+	}
+	fields, err := pagination.ParseCursor(*next.Pagination.After)
+	if err != nil || fields["id"] != id {
+		t.Errorf("ParseCursor() = %#v (%v), want id %d", fields, err, id)
+	}
+}
+
+// TestCursorsMatchVectors checks that cursors in the shared vectors follow
+// the documented convention.
+func TestCursorsMatchVectors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "tools", "tests", "vectors", "query-shapes.json"))
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors []struct {
+		Name  string          `json:"name"`
+		Shape types.Statement `json:"shape"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	checked := 0
+	for _, v := range vectors {
+		after, ok := v.Shape.GetPagination().GetAfter()
+		if !ok {
+			continue
+		}
+		decoded, err := cursors.Decode(after)
+		if err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+			continue
+		}
+		// Rebuilding the cursor from its own fields, in order, must be lossless
+		var orderBy []types.OrderBy
+		for _, f := range decoded {
+			orderBy = append(orderBy, types.OrderBy{Field: f.Name})
+		}
+		rebuilt, err := pagination.CursorFromRow(orderBy, decoded.Map())
+		if err != nil {
+			t.Errorf("%s: %v", v.Name, err)
+		}
+		if rebuilt != after {
+			t.Errorf("%s: rebuilt cursor %s, want %s", v.Name, rebuilt, after)
+		}
+		checked++
+	}
+	if checked == 0 {
+		t.Error("Expected at least one vector with a cursor")
+	}
+}
+
+// FuzzDecodeCursor checks that ParseCursor never panics on client input, and
+// that a cursor it accepts re-encodes to the same field values. Values
+// compare by encoding, since 1.0 re-encodes as 1.
+func FuzzDecodeCursor(f *testing.F) {
+	f.Add("eyJjcmVhdGVkQXQiOiIyMDI0LTAxLTE1VDEwOjMwOjAwWiIsImlkIjoicG9zdF8xMjMifQ==")
+
+	f.Fuzz(func(t *testing.T, cursor string) {
+		fields, err := pagination.ParseCursor(cursor)
+		if err != nil || len(fields) == 0 {
+			return
+		}
+
+		orderBy := make([]types.OrderBy, 0, len(fields))
+		for field := range fields {
+			orderBy = append(orderBy, types.OrderBy{Field: field})
+		}
+		reencoded, err := pagination.CursorFromRow(orderBy, fields)
+		if err != nil {
+			t.Fatalf("CursorFromRow(%v) failed: %v", fields, err)
+		}
+		again, err := pagination.ParseCursor(reencoded)
+		if err != nil {
+			t.Fatalf("ParseCursor(%q) failed: %v", reencoded, err)
+		}
+		want, _ := json.Marshal(fields)
+		got, _ := json.Marshal(again)
+		if string(got) != string(want) {
+			t.Errorf("cursor %q decodes to %v, re-encoded to %v", cursor, fields, again)
+		}
+	})
+}
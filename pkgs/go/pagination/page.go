@@ -0,0 +1,58 @@
+package pagination
+
+import (
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// NextPage returns a copy of stmt that fetches the page after lastRow: the
+// cursor is built from the query orderBy and the page size is carried over
+// from First (or Last, when paging backward until now).
+//
+// Only the pagination is replaced; the copy shares the query and other nested
+// values with stmt. Returns an error if the statement has no orderBy or no
+// page size, or the cursor cannot be built.
+func NextPage(stmt *types.Statement, lastRow map[string]any) (*types.Statement, error) {
+	size, cursor, err := pageArgs(stmt, lastRow)
+	if err != nil {
+		return nil, err
+	}
+
+	next := *stmt
+	next.Pagination = &types.Pagination{First: &size, After: &cursor}
+	return &next, nil
+}
+
+// PrevPage returns a copy of stmt that fetches the page before firstRow,
+// mirroring NextPage with Last and Before.
+func PrevPage(stmt *types.Statement, firstRow map[string]any) (*types.Statement, error) {
+	size, cursor, err := pageArgs(stmt, firstRow)
+	if err != nil {
+		return nil, err
+	}
+
+	prev := *stmt
+	prev.Pagination = &types.Pagination{Last: &size, Before: &cursor}
+	return &prev, nil
+}
+
+func pageArgs(stmt *types.Statement, row map[string]any) (int, string, error) {
+	if stmt == nil {
+		return 0, "", fmt.Errorf("pagination: statement is nil")
+	}
+
+	size, ok := stmt.GetPagination().GetFirst()
+	if !ok {
+		size, ok = stmt.GetPagination().GetLast()
+	}
+	if !ok {
+		return 0, "", fmt.Errorf("pagination: statement has no page size (first or last)")
+	}
+
+	cursor, err := CursorFromRow(stmt.GetOrderBy(), row)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, cursor, nil
+}
@@ -0,0 +1,66 @@
+package pagination_test
+
+import (
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func firstPage() *types.Statement {
+	return types.NewStatement(
+		types.WithModel("posts"),
+		types.WithOrderBy(types.OrderBy{Field: "id"}),
+		types.WithPagination(types.Pagination{First: intPtr(20)}),
+	)
+}
+
+func TestNextPage(t *testing.T) {
+	stmt := firstPage()
+
+	next, err := pagination.NextPage(stmt, map[string]any{"id": "post_123"})
+	if err != nil {
+		t.Fatalf("NextPage failed: %v", err)
+	}
+
+	if first, _ := next.Pagination.GetFirst(); first != 20 {
+		t.Errorf("Expected first=20, got %d", first)
+	}
+	if after, _ := next.Pagination.GetAfter(); after != "eyJpZCI6InBvc3RfMTIzIn0=" {
+		t.Errorf("Unexpected after cursor: %s", after)
+	}
+	if stmt.Pagination.After != nil {
+		t.Error("NextPage should not modify the original statement")
+	}
+}
+
+func TestPrevPage(t *testing.T) {
+	next, _ := pagination.NextPage(firstPage(), map[string]any{"id": "post_123"})
+
+	prev, err := pagination.PrevPage(next, map[string]any{"id": "post_101"})
+	if err != nil {
+		t.Fatalf("PrevPage failed: %v", err)
+	}
+
+	if prev.Pagination.First != nil || prev.Pagination.After != nil {
+		t.Error("PrevPage should drop forward pagination")
+	}
+	if last, _ := prev.Pagination.GetLast(); last != 20 {
+		t.Errorf("Expected last=20, got %d", last)
+	}
+	values, err := pagination.CursorValues(prev.GetOrderBy(), *prev.Pagination.Before)
+	if err != nil || values[0] != "post_101" {
+		t.Errorf("Unexpected before cursor values: %v (%v)", values, err)
+	}
+}
+
+func TestNextPageRequiresPageSize(t *testing.T) {
+	stmt := types.NewStatement(types.WithModel("posts"), types.WithOrderBy(types.OrderBy{Field: "id"}))
+	if _, err := pagination.NextPage(stmt, map[string]any{"id": "1"}); err == nil {
+		t.Error("Expected error without page size")
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
@@ -0,0 +1,220 @@
+// Package rewrite provides a rule-based rewrite pipeline for Filters of the
+// IncludeKit Universal Format.
+//
+// Adapters use it to lower ORM-specific predicates into spec operators before
+// computing shape IDs or rendering queries. Built-in rules translate custom:
+// operators and push NOT inward; adapters add their own with Rule or
+// ConditionRule.
+package rewrite
+
+import (
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// MaxPasses bounds the number of rewrite passes before Rewrite gives up.
+const MaxPasses = 64
+
+// Rule rewrites a single filter node. It returns the replacement node and
+// whether anything changed. Rules must not modify their argument in place.
+type Rule func(f types.Filter) (types.Filter, bool)
+
+// Rewrite applies the rules to every node of the filter, children first,
+// repeating until a full pass changes nothing. The input filter is not
+// modified.
+//
+// Returns an error if the rules have not converged after MaxPasses passes.
+func Rewrite(f *types.Filter, rules ...Rule) (*types.Filter, error) {
+	if f == nil {
+		return nil, nil
+	}
+
+	current := *f
+	for pass := 0; pass < MaxPasses; pass++ {
+		next, changed := rewriteNode(current, rules)
+		if !changed {
+			return &next, nil
+		}
+		current = next
+	}
+
+	return nil, fmt.Errorf("rewrite: rules did not converge after %d passes", MaxPasses)
+}
+
+func rewriteNode(f types.Filter, rules []Rule) (types.Filter, bool) {
+	changed := false
+
+	if f.And != nil {
+		and, c := rewriteList(*f.And, rules)
+		f.And = &and
+		changed = changed || c
+	}
+	if f.Or != nil {
+		or, c := rewriteList(*f.Or, rules)
+		f.Or = &or
+		changed = changed || c
+	}
+	if f.Not != nil {
+		not, c := rewriteNode(*f.Not, rules)
+		f.Not = &not
+		changed = changed || c
+	}
+
+	for _, rule := range rules {
+		if next, c := rule(f); c {
+			f = next
+			changed = true
+		}
+	}
+
+	return f, changed
+}
+
+func rewriteList(filters []types.Filter, rules []Rule) ([]types.Filter, bool) {
+	out := make([]types.Filter, len(filters))
+	changed := false
+	for i, f := range filters {
+		var c bool
+		out[i], c = rewriteNode(f, rules)
+		changed = changed || c
+	}
+	return out, changed
+}
+
+// ConditionRule lifts a per-condition rewrite into a Rule. fn returns the
+// replacement condition and whether it changed.
+func ConditionRule(fn func(c types.Condition) (types.Condition, bool)) Rule {
+	return func(f types.Filter) (types.Filter, bool) {
+		if f.Conditions == nil {
+			return f, false
+		}
+		out := make([]types.Condition, len(*f.Conditions))
+		changed := false
+		for i, c := range *f.Conditions {
+			var ok bool
+			out[i], ok = fn(c)
+			changed = changed || ok
+		}
+		if !changed {
+			return f, false
+		}
+		f.Conditions = &out
+		return f, true
+	}
+}
+
+// TranslateCustomOps returns a Rule that replaces custom: operators with the
+// standard operators they map to, e.g. {"custom:iequals": "ilike"}.
+// Unmapped custom operators are left unchanged.
+func TranslateCustomOps(ops map[string]string) Rule {
+	return ConditionRule(func(c types.Condition) (types.Condition, bool) {
+		op, ok := ops[c.Op]
+		if !ok || op == c.Op {
+			return c, false
+		}
+		c.Op = op
+		return c, true
+	})
+}
+
+// negatedOps maps each operator to its negation under SQL WHERE semantics,
+// where rows with a null field match neither the operator nor its negation.
+var negatedOps = map[string]string{
+	"eq": "ne", "ne": "eq",
+	"in": "notIn", "notIn": "in",
+	"gt": "lte", "lte": "gt",
+	"lt": "gte", "gte": "lt",
+}
+
+// PushNotInward is a Rule that moves NOT towards the leaves: it removes
+// double negation, applies De Morgan's laws, and negates single conditions
+// whose operator has a negated counterpart (eq/ne, in/notIn, gt/lte, lt/gte).
+//
+// A filter node ANDs its and, or, not and conditions parts together, so a
+// negated node with several parts becomes an OR of the negated parts.
+func PushNotInward(f types.Filter) (types.Filter, bool) {
+	if f.Not == nil {
+		return f, false
+	}
+
+	negated, ok := negate(*f.Not)
+	if !ok {
+		return f, false
+	}
+
+	rest := f
+	rest.Not = nil
+	if isEmpty(rest) {
+		return negated, true
+	}
+	and := append(append([]types.Filter{}, rest.GetAnd()...), negated)
+	rest.And = &and
+	return rest, true
+}
+
+// negate returns a filter equivalent to NOT f with the negation pushed one
+// level down, or false if it cannot be pushed further.
+func negate(f types.Filter) (types.Filter, bool) {
+	parts := conjuncts(f)
+	switch len(parts) {
+	case 0:
+		return f, false
+	case 1:
+		part := parts[0]
+		switch {
+		case part.Not != nil:
+			return *part.Not, true
+		case part.Or != nil:
+			return types.Filter{And: wrapNot(*part.Or)}, true
+		case part.And != nil:
+			return types.Filter{Or: wrapNot(*part.And)}, true
+		default:
+			c := (*part.Conditions)[0]
+			op, ok := negatedOps[c.Op]
+			if !ok {
+				return f, false
+			}
+			c.Op = op
+			return types.Filter{Conditions: &[]types.Condition{c}}, true
+		}
+	default:
+		return types.Filter{Or: wrapNot(parts)}, true
+	}
+}
+
+// conjuncts splits a filter node into the parts it ANDs together, each as a
+// single-part filter.
+func conjuncts(f types.Filter) []types.Filter {
+	var parts []types.Filter
+	if f.And != nil {
+		if len(*f.And) == 1 {
+			parts = append(parts, conjuncts((*f.And)[0])...)
+		} else {
+			parts = append(parts, types.Filter{And: f.And})
+		}
+	}
+	if f.Or != nil {
+		parts = append(parts, types.Filter{Or: f.Or})
+	}
+	if f.Not != nil {
+		parts = append(parts, types.Filter{Not: f.Not})
+	}
+	for _, c := range f.GetConditions() {
+		parts = append(parts, types.Filter{Conditions: &[]types.Condition{c}})
+	}
+	return parts
+}
+
+func wrapNot(filters []types.Filter) *[]types.Filter {
+	out := make([]types.Filter, len(filters))
+	for i := range filters {
+		f := filters[i]
+		out[i] = types.Filter{Not: &f}
+	}
+	return &out
+}
+
+func isEmpty(f types.Filter) bool {
+	return f.And == nil && f.Or == nil && f.Not == nil && f.Conditions == nil
+}
@@ -0,0 +1,112 @@
+package rewrite_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/rewrite"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	return string(data)
+}
+
+func TestTranslateCustomOps(t *testing.T) {
+	f := &types.Filter{
+		Or: &[]types.Filter{
+			{Conditions: &[]types.Condition{{Field: "email", Op: "custom:iequals", Value: "A@B.C"}}},
+			{Conditions: &[]types.Condition{{Field: "tags", Op: "custom:unknown", Value: "x"}}},
+		},
+	}
+
+	got, err := rewrite.Rewrite(f, rewrite.TranslateCustomOps(map[string]string{"custom:iequals": "ilike"}))
+	if err != nil {
+		t.Fatalf("Rewrite failed: %v", err)
+	}
+
+	if op := (*(*got.Or)[0].Conditions)[0].Op; op != "ilike" {
+		t.Errorf("Expected ilike, got %s", op)
+	}
+	if op := (*(*got.Or)[1].Conditions)[0].Op; op != "custom:unknown" {
+		t.Errorf("Unmapped op should be unchanged, got %s", op)
+	}
+	if op := (*(*f.Or)[0].Conditions)[0].Op; op != "custom:iequals" {
+		t.Error("Rewrite should not modify its input")
+	}
+}
+
+func TestPushNotInward(t *testing.T) {
+	tcs := []struct {
+		name string
+		in   types.Filter
+		want string
+	}{
+		{
+			name: "negates single condition",
+			in:   types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{types.Eq("status", "draft")}}},
+			want: `{"conditions":[{"field":"status","op":"ne","value":"draft"}]}`,
+		},
+		{
+			name: "double negation",
+			in:   types.Filter{Not: &types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{types.IsNull("deletedAt")}}}},
+			want: `{"conditions":[{"field":"deletedAt","op":"isNull"}]}`,
+		},
+		{
+			name: "de morgan over implicit and",
+			in: types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{
+				types.Gt("views", 100), types.In("status", "a", "b"),
+			}}},
+			want: `{"or":[{"conditions":[{"field":"views","op":"lte","value":100}]},{"conditions":[{"field":"status","op":"notIn","value":["a","b"]}]}]}`,
+		},
+		{
+			name: "de morgan over or",
+			in: types.Filter{Not: &types.Filter{Or: &[]types.Filter{
+				{Conditions: &[]types.Condition{types.Lt("age", 18)}},
+				{Conditions: &[]types.Condition{types.IsNull("email")}},
+			}}},
+			want: `{"and":[{"conditions":[{"field":"age","op":"gte","value":18}]},{"not":{"conditions":[{"field":"email","op":"isNull"}]}}]}`,
+		},
+		{
+			name: "keeps sibling conditions",
+			in: types.Filter{
+				Conditions: &[]types.Condition{types.Eq("published", true)},
+				Not:        &types.Filter{Conditions: &[]types.Condition{types.Eq("featured", true)}},
+			},
+			want: `{"and":[{"conditions":[{"field":"featured","op":"ne","value":true}]}],"conditions":[{"field":"published","op":"eq","value":true}]}`,
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rewrite.Rewrite(&tt.in, rewrite.PushNotInward)
+			if err != nil {
+				t.Fatalf("Rewrite failed: %v", err)
+			}
+			if s := mustJSON(t, got); s != tt.want {
+				t.Errorf("got  %s\nwant %s", s, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteDetectsNonConvergence(t *testing.T) {
+	flip := rewrite.ConditionRule(func(c types.Condition) (types.Condition, bool) {
+		if c.Op == "eq" {
+			c.Op = "ne"
+		} else {
+			c.Op = "eq"
+		}
+		return c, true
+	})
+
+	_, err := rewrite.Rewrite(&types.Filter{Conditions: &[]types.Condition{types.Eq("a", 1)}}, flip)
+	if err == nil {
+		t.Error("Expected non-convergence error")
+	}
+}
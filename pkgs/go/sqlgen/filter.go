@@ -0,0 +1,448 @@
+package sqlgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// filter renders f as a predicate, or "" when it matches every row. Fields
+// found in aliases render as the mapped expression.
+func (r *renderer) filter(f *types.Filter, aliases map[string]string) (string, error) {
+	if f == nil {
+		return "", nil
+	}
+
+	var parts []string
+	for i := range f.GetConditions() {
+		sql, err := r.condition(&(*f.Conditions)[i], aliases)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, sql)
+	}
+	for i := range f.GetAnd() {
+		sql, err := r.filter(&(*f.And)[i], aliases)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, sql)
+	}
+	if branches := f.GetOr(); len(branches) > 0 {
+		mark := len(r.args)
+		sqls := make([]string, len(branches))
+		for i := range branches {
+			sql, err := r.filter(&branches[i], aliases)
+			if err != nil {
+				return "", err
+			}
+			sqls[i] = sql
+		}
+		sql := or(sqls)
+		if sql == "" {
+			// the disjunction matches every row; drop its arguments
+			r.args = r.args[:mark]
+		}
+		parts = append(parts, sql)
+	}
+	if f.Not != nil {
+		sql, err := r.filter(f.Not, aliases)
+		if err != nil {
+			return "", err
+		}
+		if sql == "" {
+			parts = append(parts, "1 = 0")
+		} else {
+			parts = append(parts, "NOT ("+sql+")")
+		}
+	}
+	return and(parts), nil
+}
+
+// and joins predicates, skipping those that match every row.
+func and(parts []string) string {
+	kept := parts[:0:0]
+	for _, p := range parts {
+		if p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, " AND ")
+}
+
+// or joins predicates in parentheses, so the result nests inside and. Any
+// predicate that matches every row makes the whole disjunction match.
+func or(parts []string) string {
+	for _, p := range parts {
+		if p == "" {
+			return ""
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+// condition renders one predicate. Semantics follow the spec:
+//   - contains, startsWith and endsWith match literally with LIKE, so
+//     their case sensitivity follows the column collation in MySQL and
+//     SQLite
+//   - ilike lowers both sides outside PostgreSQL
+//   - regex needs a REGEXP function registered in SQLite
+//   - has, hasSome and hasEvery test PostgreSQL arrays and JSON arrays
+//     elsewhere; jsonContains is unsupported in SQLite
+//   - lenEq, lenGt and lenLt compare string lengths
+//   - exists is true for plain columns, and tests the path of a field_path
+//   - inQuery and existsQuery render their subquery as a nested SELECT;
+//     existsQuery also excludes nulls on both sides, so that under not it
+//     keeps rows where the field is null, as NOT EXISTS does
+//
+// custom: operators, jsonHasKey and jsonType, and the geospatial operators,
+// which need a spatial extension such as PostGIS, are unsupported.
+func (r *renderer) condition(c *types.Condition, aliases map[string]string) (string, error) {
+	if c.Op == "exists" {
+		sql, err := r.exists(c)
+		if err != nil {
+			return "", err
+		}
+		if b, ok := c.Value.(bool); ok && !b {
+			return "NOT (" + sql + ")", nil
+		}
+		return sql, nil
+	}
+
+	col, err := r.column(c, aliases)
+	if err != nil {
+		return "", err
+	}
+
+	switch c.Op {
+	case "eq", "ne", "gt", "gte", "lt", "lte":
+		p, err := r.arg(c.Value)
+		if err != nil {
+			return "", err
+		}
+		return col + " " + comparisons[c.Op] + " " + p, nil
+
+	case "in", "notIn":
+		list, _, err := r.list(c)
+		if err != nil {
+			return "", err
+		}
+		if len(list) == 0 {
+			if c.Op == "in" {
+				return "1 = 0", nil
+			}
+			return col + " IS NOT NULL", nil
+		}
+		op := " IN ("
+		if c.Op == "notIn" {
+			op = " NOT IN ("
+		}
+		return col + op + strings.Join(list, ", ") + ")", nil
+
+	case "isNull":
+		if b, ok := c.Value.(bool); ok && !b {
+			return col + " IS NOT NULL", nil
+		}
+		return col + " IS NULL", nil
+
+	case "between":
+		list, _, err := r.list(c)
+		if err != nil {
+			return "", err
+		}
+		if len(list) != 2 {
+			return "", fmt.Errorf("sqlgen: between on %q requires a [lo, hi] value", c.Field)
+		}
+		return col + " BETWEEN " + list[0] + " AND " + list[1], nil
+
+	case "contains", "startsWith", "endsWith":
+		s, ok := c.Value.(string)
+		if !ok {
+			return "", fmt.Errorf("sqlgen: %s on %q requires a string value", c.Op, c.Field)
+		}
+		s = likeEscaper.Replace(s)
+		switch c.Op {
+		case "contains":
+			s = "%" + s + "%"
+		case "startsWith":
+			s += "%"
+		default:
+			s = "%" + s
+		}
+		p, err := r.arg(s)
+		if err != nil {
+			return "", err
+		}
+		return col + " LIKE " + p + " ESCAPE '!'", nil
+
+	case "like", "ilike":
+		p, err := r.arg(c.Value)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case c.Op == "like":
+			return col + " LIKE " + p, nil
+		case r.dialect == Postgres:
+			return col + " ILIKE " + p, nil
+		}
+		return "LOWER(" + col + ") LIKE LOWER(" + p + ")", nil
+
+	case "regex":
+		p, err := r.arg(c.Value)
+		if err != nil {
+			return "", err
+		}
+		if r.dialect == Postgres {
+			return col + " ~ " + p, nil
+		}
+		return col + " REGEXP " + p, nil
+
+	case "has", "hasSome", "hasEvery":
+		return r.arrayCondition(c, col)
+
+	case "inQuery", "existsQuery":
+		return r.subqueryCondition(c, col, aliases)
+
+	case "jsonContains":
+		data, err := json.Marshal(c.Value)
+		if err != nil {
+			return "", fmt.Errorf("sqlgen: jsonContains on %q: %w", c.Field, err)
+		}
+		p, err := r.arg(string(data))
+		if err != nil {
+			return "", err
+		}
+		switch r.dialect {
+		case Postgres:
+			return col + " @> CAST(" + p + " AS jsonb)", nil
+		case MySQL:
+			return "JSON_CONTAINS(" + col + ", " + p + ")", nil
+		}
+		return "", fmt.Errorf("sqlgen: operator jsonContains is %w in %s", ErrUnsupported, r.dialect)
+
+	case "lenEq", "lenGt", "lenLt":
+		p, err := r.arg(c.Value)
+		if err != nil {
+			return "", err
+		}
+		length := "CHAR_LENGTH(" + col + ")"
+		if r.dialect == SQLite {
+			length = "LENGTH(" + col + ")"
+		}
+		return length + " " + comparisons[c.Op] + " " + p, nil
+	}
+	return "", fmt.Errorf("sqlgen: operator %s is %w", c.Op, ErrUnsupported)
+}
+
+var comparisons = map[string]string{
+	"eq": "=", "ne": "<>", "gt": ">", "gte": ">=", "lt": "<", "lte": "<=",
+	"lenEq": "=", "lenGt": ">", "lenLt": "<",
+}
+
+// likeEscaper escapes LIKE wildcards for ESCAPE '!'.
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// list renders the elements of an array value as placeholders, and returns
+// the elements.
+func (r *renderer) list(c *types.Condition) ([]string, []any, error) {
+	value, err := types.Value(c.Value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("sqlgen: %w", err)
+	}
+	items, ok := value.([]any)
+	if !ok {
+		return nil, nil, fmt.Errorf("sqlgen: %s on %q requires an array value", c.Op, c.Field)
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		if out[i], err = r.arg(item); err != nil {
+			return nil, nil, err
+		}
+	}
+	return out, items, nil
+}
+
+func (r *renderer) arrayCondition(c *types.Condition, col string) (string, error) {
+	if c.Op == "has" {
+		p, err := r.arg(c.Value)
+		if err != nil {
+			return "", err
+		}
+		switch r.dialect {
+		case Postgres:
+			return p + " = ANY(" + col + ")", nil
+		case MySQL:
+			return "JSON_CONTAINS(" + col + ", JSON_ARRAY(" + p + "))", nil
+		}
+		return "EXISTS (SELECT 1 FROM json_each(" + col + ") WHERE value = " + p + ")", nil
+	}
+
+	list, items, err := r.list(c)
+	if err != nil {
+		return "", err
+	}
+	if len(list) == 0 {
+		// nothing is some of no values, and everything has all of them
+		if c.Op == "hasSome" {
+			return "1 = 0", nil
+		}
+		return "", nil
+	}
+	values := strings.Join(list, ", ")
+
+	switch r.dialect {
+	case Postgres:
+		if c.Op == "hasSome" {
+			return col + " && ARRAY[" + values + "]", nil
+		}
+		return col + " @> ARRAY[" + values + "]", nil
+	case MySQL:
+		if c.Op == "hasSome" {
+			return "JSON_OVERLAPS(" + col + ", JSON_ARRAY(" + values + "))", nil
+		}
+		return "JSON_CONTAINS(" + col + ", JSON_ARRAY(" + values + "))", nil
+	}
+	if c.Op == "hasSome" {
+		return "EXISTS (SELECT 1 FROM json_each(" + col + ") WHERE value IN (" + values + "))", nil
+	}
+	distinct := map[string]bool{}
+	for _, item := range items {
+		key, _ := json.Marshal(item)
+		distinct[string(key)] = true
+	}
+	return fmt.Sprintf("(SELECT COUNT(DISTINCT value) FROM json_each(%s) WHERE value IN (%s)) = %d", col, values, len(distinct)), nil
+}
+
+// subqueryCondition renders col IN (SELECT ...). The subquery is wrapped in
+// a derived table where needed: to drop nulls for existsQuery, and because
+// MySQL rejects LIMIT directly inside IN.
+func (r *renderer) subqueryCondition(c *types.Condition, col string, aliases map[string]string) (string, error) {
+	sub := c.Subquery
+	if sub == nil {
+		return "", fmt.Errorf("sqlgen: %s on %q requires a subquery", c.Op, c.Field)
+	}
+	fields := sub.GetFields()
+	if len(fields) != 1 || len(sub.GetAggregations()) > 0 {
+		return "", fmt.Errorf("sqlgen: subquery of %s on %q must select exactly one field", c.Op, c.Field)
+	}
+	if len(sub.GetJoins()) > 0 {
+		return "", fmt.Errorf("sqlgen: joins are %w", ErrUnsupported)
+	}
+	// existsQuery names the column twice; render it again so a field_path
+	// gets its own arguments, in text order
+	inCol := col
+	if c.Op == "existsQuery" {
+		var err error
+		if inCol, err = r.column(c, aliases); err != nil {
+			return "", err
+		}
+	}
+	sql, _, err := r.selectQuery(&types.Statement{Query: sub})
+	if err != nil {
+		return "", err
+	}
+
+	derived := r.ident(subqueryAlias)
+	selected := derived + "." + r.ident(fields[0])
+	if c.Op == "existsQuery" {
+		return fmt.Sprintf("%s IS NOT NULL AND %s IN (SELECT %s FROM (%s) AS %s WHERE %s IS NOT NULL)",
+			col, inCol, selected, sql, derived, selected), nil
+	}
+	_, hasLimit := sub.GetLimit()
+	_, hasOffset := sub.GetOffset()
+	if r.dialect == MySQL && (hasLimit || hasOffset) {
+		return fmt.Sprintf("%s IN (SELECT %s FROM (%s) AS %s)", col, selected, sql, derived), nil
+	}
+	return col + " IN (" + sql + ")", nil
+}
+
+// column renders the condition's field: an aggregate expression from
+// aliases, a JSON extraction for a field_path, or the quoted column.
+func (r *renderer) column(c *types.Condition, aliases map[string]string) (string, error) {
+	if len(c.FieldPath) == 0 {
+		if expr, ok := aliases[c.Field]; ok {
+			return expr, nil
+		}
+		return r.ident(c.Field), nil
+	}
+
+	col := r.ident(c.Field)
+	if r.dialect == Postgres {
+		segments := make([]string, len(c.FieldPath))
+		for i, s := range c.FieldPath {
+			p, err := r.arg(s)
+			if err != nil {
+				return "", err
+			}
+			segments[i] = p
+		}
+		return col + " #>> ARRAY[" + strings.Join(segments, ", ") + "]", nil
+	}
+
+	p, err := r.arg(jsonPath(c.FieldPath))
+	if err != nil {
+		return "", err
+	}
+	if r.dialect == MySQL {
+		return "JSON_UNQUOTE(JSON_EXTRACT(" + col + ", " + p + "))", nil
+	}
+	return "json_extract(" + col + ", " + p + ")", nil
+}
+
+// exists tests whether the condition's field_path is present.
+func (r *renderer) exists(c *types.Condition) (string, error) {
+	if len(c.FieldPath) == 0 {
+		// columns always exist
+		return "1 = 1", nil
+	}
+
+	col := r.ident(c.Field)
+	switch r.dialect {
+	case Postgres:
+		segments := make([]string, len(c.FieldPath))
+		for i, s := range c.FieldPath {
+			p, err := r.arg(s)
+			if err != nil {
+				return "", err
+			}
+			segments[i] = p
+		}
+		return col + " #> ARRAY[" + strings.Join(segments, ", ") + "] IS NOT NULL", nil
+	case MySQL:
+		p, err := r.arg(jsonPath(c.FieldPath))
+		if err != nil {
+			return "", err
+		}
+		return "JSON_CONTAINS_PATH(" + col + ", 'one', " + p + ")", nil
+	}
+	p, err := r.arg(jsonPath(c.FieldPath))
+	if err != nil {
+		return "", err
+	}
+	return "json_type(" + col + ", " + p + ") IS NOT NULL", nil
+}
+
+// jsonPath builds a MySQL/SQLite JSON path, reading segments in plain
+// decimal ("0", "12") as array indexes and all others, "01" among them, as
+// object keys.
+func jsonPath(segments []string) string {
+	var b strings.Builder
+	b.WriteString("$")
+	for _, s := range segments {
+		if n, err := strconv.Atoi(s); err == nil && n >= 0 && strconv.Itoa(n) == s {
+			fmt.Fprintf(&b, "[%d]", n)
+			continue
+		}
+		b.WriteString(`."`)
+		b.WriteString(strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s))
+		b.WriteString(`"`)
+	}
+	return b.String()
+}
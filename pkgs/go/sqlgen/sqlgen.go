@@ -0,0 +1,459 @@
+// Package sqlgen renders Statements as parameterized SQL for PostgreSQL,
+// MySQL and SQLite. It is a reference translation for adapter authors and a
+// way to run test vectors against real databases, not a query builder: the
+// model names the table, fields name columns, and every value is passed as a
+// positional argument.
+//
+// Rendering covers the query (fields, where, order_by, limit, offset,
+// distinct, distinct_on, aggregations, windows), the policy, ANDed with the
+// where, group_by, having and cursor pagination. Windows are not combined
+// with distinct or grouping, whose rows they would have to be computed over.
+// Includes that load relations are left to the caller, who renders their
+// queries separately; includes with a kind filter the parent rows through a
+// join the statement does not describe, so they are rejected, as are joins,
+// whose join keys only the application schema knows. For the same reason
+// soft deletes are not applied: rows are read whatever their soft-delete
+// field holds, and queries setting deleted are rejected. A statement's
+// tenant is not rendered either: tenant.Scoper scopes the where clauses
+// themselves.
+package sqlgen
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/cursors"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Dialect selects the SQL flavor to render.
+type Dialect string
+
+const (
+	Postgres Dialect = "postgres"
+	MySQL    Dialect = "mysql"
+	SQLite   Dialect = "sqlite"
+)
+
+// ErrUnsupported is matched by errors for statements, operators or options
+// that have no translation in the dialect.
+var ErrUnsupported = errors.New("not supported")
+
+// rowNumber names the column distinct adds to pick the first row of each
+// group.
+const rowNumber = "_ik_row"
+
+// subqueryAlias names the derived table wrapping a condition subquery.
+const subqueryAlias = "_ik_sub"
+
+// Query is rendered SQL with its positional arguments.
+type Query struct {
+	SQL  string
+	Args []any
+
+	// Reversed is set for backward pagination (Last): rows come back in
+	// reverse order_by order, and the caller reverses them.
+	Reversed bool
+}
+
+// Render translates stmt into a SELECT for dialect.
+//
+// Semantics follow the spec rather than the database defaults where the two
+// differ: nulls sort as larger than any value unless nulls_first says
+// otherwise, and distinct keeps the first row, in order_by order, of each
+// combination of the distinct fields (distinct_on, in its own order). Cursor
+// pagination becomes a keyset predicate on the order_by fields ANDed to the
+// where filter, or applied to the kept rows with distinct, and first or
+// last becomes the LIMIT (the smaller one, with the query limit). Cursor
+// values must be non-null, and rows with nulls in the order_by fields fall
+// outside keyset pages.
+//
+// Returns an error if the statement has no query, uses something listed as
+// unsupported above or in the operator docs, or carries a malformed cursor.
+func Render(stmt *types.Statement, dialect Dialect) (Query, error) {
+	switch dialect {
+	case Postgres, MySQL, SQLite:
+	default:
+		return Query{}, fmt.Errorf("sqlgen: unknown dialect %q", dialect)
+	}
+	if stmt == nil || stmt.Query == nil {
+		return Query{}, fmt.Errorf("sqlgen: statement has no query")
+	}
+	if len(stmt.Query.GetJoins()) > 0 {
+		return Query{}, fmt.Errorf("sqlgen: joins are %w", ErrUnsupported)
+	}
+	for _, inc := range stmt.Includes {
+		if inc.IsRelationFilter() {
+			return Query{}, fmt.Errorf("sqlgen: include kind filters are %w", ErrUnsupported)
+		}
+	}
+
+	r := &renderer{dialect: dialect}
+	sql, reversed, err := r.selectQuery(stmt)
+	if err != nil {
+		return Query{}, err
+	}
+	return Query{SQL: sql, Args: r.args, Reversed: reversed}, nil
+}
+
+// renderer accumulates arguments while a statement is rendered.
+type renderer struct {
+	dialect Dialect
+	args    []any
+}
+
+// arg records v and returns its placeholder.
+func (r *renderer) arg(v any) (string, error) {
+	coerced, err := types.Value(v)
+	if err != nil {
+		return "", fmt.Errorf("sqlgen: %w", err)
+	}
+	r.args = append(r.args, coerced)
+	if r.dialect == Postgres {
+		return fmt.Sprintf("$%d", len(r.args)), nil
+	}
+	return "?", nil
+}
+
+// ident quotes a table or column name.
+func (r *renderer) ident(name string) string {
+	if r.dialect == MySQL {
+		return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	}
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+func (r *renderer) selectQuery(stmt *types.Statement) (string, bool, error) {
+	q := stmt.Query
+	if _, ok := q.GetDeleted(); ok {
+		return "", false, fmt.Errorf("sqlgen: deleted is %w", ErrUnsupported)
+	}
+	columns, err := r.columns(stmt)
+	if err != nil {
+		return "", false, err
+	}
+
+	orderBy := q.GetOrderBy()
+	limit, hasLimit := q.GetLimit()
+	reversed := false
+
+	whereSQL, err := r.filter(q.Where, nil)
+	if err != nil {
+		return "", false, err
+	}
+	policySQL, err := r.filter(stmt.Policy, nil)
+	if err != nil {
+		return "", false, err
+	}
+	whereSQL = and([]string{whereSQL, policySQL})
+	var keysets []string
+	if p := stmt.Pagination; p != nil {
+		for _, bound := range []struct {
+			cursor *string
+			before bool
+		}{{p.After, false}, {p.Before, true}} {
+			if bound.cursor == nil {
+				continue
+			}
+			keyset, err := r.keyset(orderBy, *bound.cursor, bound.before)
+			if err != nil {
+				return "", false, err
+			}
+			keysets = append(keysets, keyset)
+		}
+
+		size, ok := p.GetFirst()
+		if last, isLast := p.GetLast(); isLast {
+			size, ok = last, true
+			reversed = true
+		}
+		if ok && (!hasLimit || size < limit) {
+			limit, hasLimit = size, true
+		}
+	}
+	orderSQL := r.orderBy(orderBy, reversed)
+
+	// distinct keeps the first row in order_by order, distinct_on the first
+	// in its own order; cursors then page through the kept rows
+	var b strings.Builder
+	distinct, distinctOrder := q.GetDistinct(), orderBy
+	if d := q.DistinctOn; d != nil {
+		distinct, distinctOrder = d.Fields, d.OrderBy
+	}
+	if len(distinct) > 0 {
+		if len(q.GetAggregations()) > 0 || stmt.GroupBy != nil {
+			return "", false, fmt.Errorf("sqlgen: distinct with grouping is %w", ErrUnsupported)
+		}
+		if len(q.GetFields()) == 0 {
+			return "", false, fmt.Errorf("sqlgen: distinct without fields is %w", ErrUnsupported)
+		}
+		partition := make([]string, len(distinct))
+		for i, f := range distinct {
+			partition[i] = r.ident(f)
+		}
+		inner := "ROW_NUMBER() OVER (PARTITION BY " + strings.Join(partition, ", ")
+		if innerOrder := r.orderBy(distinctOrder, false); innerOrder != "" {
+			inner += " ORDER BY " + innerOrder
+		}
+		inner += ") AS " + r.ident(rowNumber)
+
+		table := r.ident(q.Model)
+		fmt.Fprintf(&b, "SELECT %s FROM (SELECT %s.*, %s FROM %s", columns, table, inner, table)
+		if whereSQL != "" {
+			b.WriteString(" WHERE " + whereSQL)
+		}
+		fmt.Fprintf(&b, ") AS %s WHERE %s", table, and(append([]string{r.ident(rowNumber) + " = 1"}, keysets...)))
+	} else {
+		fmt.Fprintf(&b, "SELECT %s FROM %s", columns, r.ident(q.Model))
+		if whereSQL = and(append([]string{whereSQL}, keysets...)); whereSQL != "" {
+			b.WriteString(" WHERE " + whereSQL)
+		}
+	}
+
+	if groupBy := stmt.GetGroupBy(); len(groupBy) > 0 {
+		cols := make([]string, len(groupBy))
+		for i, f := range groupBy {
+			cols[i] = r.ident(f)
+		}
+		b.WriteString(" GROUP BY " + strings.Join(cols, ", "))
+	}
+	if stmt.Having != nil {
+		havingSQL, err := r.filter(stmt.Having, r.aggregateColumns(q))
+		if err != nil {
+			return "", false, err
+		}
+		if havingSQL != "" {
+			b.WriteString(" HAVING " + havingSQL)
+		}
+	}
+
+	if orderSQL != "" {
+		b.WriteString(" ORDER BY " + orderSQL)
+	}
+	offset, hasOffset := q.GetOffset()
+	switch {
+	case hasLimit:
+		fmt.Fprintf(&b, " LIMIT %d", limit)
+	case hasOffset && r.dialect == MySQL:
+		// MySQL has no OFFSET without LIMIT
+		b.WriteString(" LIMIT 18446744073709551615")
+	case hasOffset && r.dialect == SQLite:
+		b.WriteString(" LIMIT -1")
+	}
+	if hasOffset {
+		fmt.Fprintf(&b, " OFFSET %d", offset)
+	}
+	return b.String(), reversed, nil
+}
+
+// columns renders the select list: the fields, or the group_by fields when
+// only aggregations are selected, followed by the aggregations and the
+// windows. Windows without fields follow every column of the table.
+func (r *renderer) columns(stmt *types.Statement) (string, error) {
+	q := stmt.Query
+	fields := q.GetFields()
+	aggregations := q.GetAggregations()
+	windows := q.GetWindows()
+	if len(windows) > 0 && (len(aggregations) > 0 || stmt.GroupBy != nil || len(q.GetDistinct()) > 0 || q.DistinctOn != nil) {
+		return "", fmt.Errorf("sqlgen: windows with distinct or grouping are %w", ErrUnsupported)
+	}
+	if len(fields) == 0 && len(aggregations) > 0 {
+		fields = stmt.GetGroupBy()
+	}
+
+	var cols []string
+	for _, f := range fields {
+		cols = append(cols, r.ident(f))
+	}
+	if len(cols) == 0 && len(windows) > 0 {
+		cols = append(cols, r.ident(q.Model)+".*")
+	}
+	for i := range aggregations {
+		expr, err := r.aggregate(&aggregations[i])
+		if err != nil {
+			return "", err
+		}
+		cols = append(cols, expr+" AS "+r.ident(aggregations[i].Column()))
+	}
+	for i := range windows {
+		expr, err := r.window(&windows[i])
+		if err != nil {
+			return "", err
+		}
+		cols = append(cols, expr+" AS "+r.ident(windows[i].Alias))
+	}
+	if len(cols) == 0 {
+		return "*", nil
+	}
+	return strings.Join(cols, ", "), nil
+}
+
+func (r *renderer) aggregate(a *types.Aggregate) (string, error) {
+	switch a.Func {
+	case "count", "sum", "avg", "min", "max":
+	default:
+		return "", fmt.Errorf("sqlgen: aggregate function %q is %w", a.Func, ErrUnsupported)
+	}
+	field, ok := a.GetField()
+	if !ok {
+		if a.Func != "count" {
+			return "", fmt.Errorf("sqlgen: aggregate %s requires a field", a.Func)
+		}
+		return "COUNT(*)", nil
+	}
+	return strings.ToUpper(a.Func) + "(" + r.ident(field) + ")", nil
+}
+
+// window renders a window function call with its OVER clause.
+func (r *renderer) window(w *types.Window) (string, error) {
+	var call string
+	switch w.Func {
+	case "row_number", "rank", "dense_rank":
+		call = strings.ToUpper(w.Func) + "()"
+	default:
+		var err error
+		if call, err = r.aggregate(&types.Aggregate{Func: w.Func, Field: w.Field}); err != nil {
+			return "", err
+		}
+	}
+
+	var over []string
+	if partitionBy := w.GetPartitionBy(); len(partitionBy) > 0 {
+		cols := make([]string, len(partitionBy))
+		for i, f := range partitionBy {
+			cols[i] = r.ident(f)
+		}
+		over = append(over, "PARTITION BY "+strings.Join(cols, ", "))
+	}
+	if orderSQL := r.orderBy(w.GetOrderBy(), false); orderSQL != "" {
+		over = append(over, "ORDER BY "+orderSQL)
+	}
+	if f := w.Frame; f != nil {
+		unit := "ROWS"
+		if f.Unit == "range" {
+			unit = "RANGE"
+		}
+		over = append(over, fmt.Sprintf("%s BETWEEN %s AND %s", unit, frameBound(f.Start, "UNBOUNDED PRECEDING"), frameBound(f.End, "UNBOUNDED FOLLOWING")))
+	}
+	return call + " OVER (" + strings.Join(over, " ") + ")", nil
+}
+
+// frameBound renders a frame offset, or unbounded when there is none.
+func frameBound(offset *int, unbounded string) string {
+	switch {
+	case offset == nil:
+		return unbounded
+	case *offset < 0:
+		return fmt.Sprintf("%d PRECEDING", -*offset)
+	case *offset > 0:
+		return fmt.Sprintf("%d FOLLOWING", *offset)
+	}
+	return "CURRENT ROW"
+}
+
+// aggregateColumns maps aggregate result columns to their expressions, so
+// having can refer to them where the database does not accept aliases.
+func (r *renderer) aggregateColumns(q *types.Query) map[string]string {
+	aggregations := q.GetAggregations()
+	columns := make(map[string]string, len(aggregations))
+	for i := range aggregations {
+		if expr, err := r.aggregate(&aggregations[i]); err == nil {
+			columns[aggregations[i].Column()] = expr
+		}
+	}
+	return columns
+}
+
+// orderBy renders the ORDER BY list, reversed for backward pagination.
+// Null placement is spelled out wherever the dialect default differs from
+// the spec.
+func (r *renderer) orderBy(orderBy []types.OrderBy, reversed bool) string {
+	terms := make([]string, 0, len(orderBy))
+	for i := range orderBy {
+		ob := &orderBy[i]
+		col := r.ident(ob.Field)
+		if cs, ok := ob.GetCaseSensitive(); ok && !cs {
+			col = "LOWER(" + col + ")"
+		}
+
+		desc := ob.IsDescending()
+		nullsFirst := desc
+		if nf, ok := ob.GetNullsFirst(); ok {
+			nullsFirst = nf
+		}
+		if reversed {
+			desc, nullsFirst = !desc, !nullsFirst
+		}
+		// PostgreSQL sorts nulls as larger than any value, MySQL and SQLite
+		// as smaller
+		defaultNullsFirst := desc
+		if r.dialect != Postgres {
+			defaultNullsFirst = !desc
+		}
+
+		dir := ""
+		if desc {
+			dir = " DESC"
+		}
+		switch {
+		case nullsFirst == defaultNullsFirst:
+			terms = append(terms, col+dir)
+		case r.dialect == MySQL:
+			nulls := "ASC"
+			if nullsFirst {
+				nulls = "DESC"
+			}
+			terms = append(terms, col+" IS NULL "+nulls, col+dir)
+		case nullsFirst:
+			terms = append(terms, col+dir+" NULLS FIRST")
+		default:
+			terms = append(terms, col+dir+" NULLS LAST")
+		}
+	}
+	return strings.Join(terms, ", ")
+}
+
+// keyset renders the predicate selecting rows strictly after (or before)
+// the cursor in orderBy order:
+//
+//	a > va OR (a = va AND b > vb) OR ...
+func (r *renderer) keyset(orderBy []types.OrderBy, cursor string, before bool) (string, error) {
+	if len(orderBy) == 0 {
+		return "", fmt.Errorf("sqlgen: cursor pagination requires order_by")
+	}
+	values, err := cursors.DecodeValues(orderBy, cursor)
+	if err != nil {
+		return "", fmt.Errorf("sqlgen: %w", err)
+	}
+
+	branches := make([]string, len(orderBy))
+	for i, ob := range orderBy {
+		if values[i] == nil {
+			return "", fmt.Errorf("sqlgen: cursor value for %q is null", ob.Field)
+		}
+		terms := make([]string, i+1)
+		for j := 0; j <= i; j++ {
+			op := "="
+			if j == i {
+				op = ">"
+				if orderBy[j].IsDescending() != before {
+					op = "<"
+				}
+			}
+			col, placeholder := r.ident(orderBy[j].Field), ""
+			if placeholder, err = r.arg(values[j]); err != nil {
+				return "", err
+			}
+			if cs, ok := orderBy[j].GetCaseSensitive(); ok && !cs {
+				col, placeholder = "LOWER("+col+")", "LOWER("+placeholder+")"
+			}
+			terms[j] = col + " " + op + " " + placeholder
+		}
+		branches[i] = and(terms)
+		if i > 0 {
+			branches[i] = "(" + branches[i] + ")"
+		}
+	}
+	return or(branches), nil
+}
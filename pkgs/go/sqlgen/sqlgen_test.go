@@ -0,0 +1,340 @@
+package sqlgen_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/sqlgen"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+type rendered struct {
+	sql  string
+	args []any
+}
+
+func TestRender(t *testing.T) {
+	desc := true
+	limit, offset := 10, 20
+	minusTwo, zero := -2, 0
+
+	tcs := []struct {
+		name string
+		stmt *types.Statement
+		want map[sqlgen.Dialect]rendered
+	}{
+		{
+			name: "minimal",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts"}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts"`, nil},
+				sqlgen.MySQL:    {"SELECT * FROM `posts`", nil},
+				sqlgen.SQLite:   {`SELECT * FROM "posts"`, nil},
+			},
+		},
+		{
+			name: "where, order, limit and offset",
+			stmt: &types.Statement{Query: &types.Query{
+				Model:   "posts",
+				Fields:  &[]string{"id", "title"},
+				Where:   &types.Filter{Conditions: &[]types.Condition{types.Eq("published", true), types.In("status", "a", "b")}},
+				OrderBy: &[]types.OrderBy{{Field: "createdAt", Descending: &desc}, {Field: "id"}},
+				Limit:   &limit,
+				Offset:  &offset,
+			}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT "id", "title" FROM "posts" WHERE "published" = $1 AND "status" IN ($2, $3) ORDER BY "createdAt" DESC, "id" LIMIT 10 OFFSET 20`, []any{true, "a", "b"}},
+				sqlgen.MySQL:    {"SELECT `id`, `title` FROM `posts` WHERE `published` = ? AND `status` IN (?, ?) ORDER BY `createdAt` IS NULL DESC, `createdAt` DESC, `id` IS NULL ASC, `id` LIMIT 10 OFFSET 20", []any{true, "a", "b"}},
+				sqlgen.SQLite:   {`SELECT "id", "title" FROM "posts" WHERE "published" = ? AND "status" IN (?, ?) ORDER BY "createdAt" DESC NULLS FIRST, "id" NULLS LAST LIMIT 10 OFFSET 20`, []any{true, "a", "b"}},
+			},
+		},
+		{
+			name: "boolean logic",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{
+				Conditions: &[]types.Condition{types.Gt("views", 10)},
+				Or:         &[]types.Filter{conds(types.Eq("featured", true)), conds(types.IsNull("deletedAt"), types.Contains("title", "50%_off"))},
+				Not:        &types.Filter{Conditions: &[]types.Condition{types.Ne("authorId", "u1")}},
+			}}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts" WHERE "views" > $1 AND ("featured" = $2 OR "deletedAt" IS NULL AND "title" LIKE $3 ESCAPE '!') AND NOT ("authorId" <> $4)`, []any{10, true, "%50!%!_off%", "u1"}},
+			},
+		},
+		{
+			name: "group by and having on aggregates",
+			stmt: &types.Statement{
+				Query:   &types.Query{Model: "posts", Aggregations: &[]types.Aggregate{types.Count(""), types.Sum("views").As("totalViews")}},
+				GroupBy: &[]string{"authorId"},
+				Having:  &types.Filter{Conditions: &[]types.Condition{types.Gte("totalViews", 1000), types.Gt("count", 5)}},
+			},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT "authorId", COUNT(*) AS "count", SUM("views") AS "totalViews" FROM "posts" GROUP BY "authorId" HAVING SUM("views") >= $1 AND COUNT(*) > $2`, []any{1000, 5}},
+				sqlgen.MySQL:    {"SELECT `authorId`, COUNT(*) AS `count`, SUM(`views`) AS `totalViews` FROM `posts` GROUP BY `authorId` HAVING SUM(`views`) >= ? AND COUNT(*) > ?", []any{1000, 5}},
+			},
+		},
+		{
+			name: "distinct keeps the first row",
+			stmt: &types.Statement{Query: &types.Query{
+				Model:    "posts",
+				Fields:   &[]string{"id", "title"},
+				Distinct: &[]string{"authorId"},
+				OrderBy:  &[]types.OrderBy{{Field: "id", Descending: &desc}},
+			}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT "id", "title" FROM (SELECT "posts".*, ROW_NUMBER() OVER (PARTITION BY "authorId" ORDER BY "id" DESC) AS "_ik_row" FROM "posts") AS "posts" WHERE "_ik_row" = 1 ORDER BY "id" DESC`, nil},
+			},
+		},
+		{
+			name: "distinct_on keeps a row per author",
+			stmt: &types.Statement{Query: &types.Query{
+				Model:      "posts",
+				Fields:     &[]string{"id", "title"},
+				DistinctOn: &types.DistinctOn{Fields: []string{"authorId"}, OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "views", Descending: &desc}}},
+				OrderBy:    &[]types.OrderBy{{Field: "id"}},
+			}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT "id", "title" FROM (SELECT "posts".*, ROW_NUMBER() OVER (PARTITION BY "authorId" ORDER BY "authorId", "views" DESC) AS "_ik_row" FROM "posts") AS "posts" WHERE "_ik_row" = 1 ORDER BY "id"`, nil},
+			},
+		},
+		{
+			name: "field path keys",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{
+				{Field: "meta", FieldPath: []string{"2024", "01", "+1"}, Op: "eq", Value: "x"},
+			}}}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.SQLite: {`SELECT * FROM "posts" WHERE json_extract("meta", ?) = ?`, []any{`$[2024]."01"."+1"`, "x"}},
+			},
+		},
+		{
+			name: "operators",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{
+				{Field: "tags", Op: "hasEvery", Value: []string{"go", "sql", "go"}},
+				{Field: "title", Op: "ilike", Value: "%go%"},
+				{Field: "meta", FieldPath: []string{"seo", "0"}, Op: "eq", Value: "x"},
+				{Field: "meta", FieldPath: []string{"draft"}, Op: "exists", Value: false},
+				{Field: "views", Op: "between", Value: []int{1, 9}},
+			}}}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts" WHERE "tags" @> ARRAY[$1, $2, $3] AND "title" ILIKE $4 AND "meta" #>> ARRAY[$5, $6] = $7 AND NOT ("meta" #> ARRAY[$8] IS NOT NULL) AND "views" BETWEEN $9 AND $10`,
+					[]any{"go", "sql", "go", "%go%", "seo", "0", "x", "draft", 1, 9}},
+				sqlgen.MySQL: {"SELECT * FROM `posts` WHERE JSON_CONTAINS(`tags`, JSON_ARRAY(?, ?, ?)) AND LOWER(`title`) LIKE LOWER(?) AND JSON_UNQUOTE(JSON_EXTRACT(`meta`, ?)) = ? AND NOT (JSON_CONTAINS_PATH(`meta`, 'one', ?)) AND `views` BETWEEN ? AND ?",
+					[]any{"go", "sql", "go", "%go%", `$."seo"[0]`, "x", `$."draft"`, 1, 9}},
+				sqlgen.SQLite: {`SELECT * FROM "posts" WHERE (SELECT COUNT(DISTINCT value) FROM json_each("tags") WHERE value IN (?, ?, ?)) = 2 AND LOWER("title") LIKE LOWER(?) AND json_extract("meta", ?) = ? AND NOT (json_type("meta", ?) IS NOT NULL) AND "views" BETWEEN ? AND ?`,
+					[]any{"go", "sql", "go", "%go%", `$."seo"[0]`, "x", `$."draft"`, 1, 9}},
+			},
+		},
+		{
+			name: "empty lists and branches",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{
+				Conditions: &[]types.Condition{{Field: "id", Op: "in", Value: []string{}}},
+				Or:         &[]types.Filter{conds(types.Eq("a", 1)), {}},
+			}}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts" WHERE 1 = 0`, nil},
+			},
+		},
+		{
+			name: "subqueries",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{
+				Conditions: &[]types.Condition{
+					types.InQuery("authorId", types.Query{Model: "users", Fields: &[]string{"id"}, Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("active", true)}}, Limit: &limit}),
+				},
+				Not: &types.Filter{Conditions: &[]types.Condition{types.ExistsQuery("categoryId", types.Query{Model: "categories", Fields: &[]string{"id"}})}},
+			}}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts" WHERE "authorId" IN (SELECT "id" FROM "users" WHERE "active" = $1 LIMIT 10) AND NOT ("categoryId" IS NOT NULL AND "categoryId" IN (SELECT "_ik_sub"."id" FROM (SELECT "id" FROM "categories") AS "_ik_sub" WHERE "_ik_sub"."id" IS NOT NULL))`, []any{true}},
+				sqlgen.MySQL:    {"SELECT * FROM `posts` WHERE `authorId` IN (SELECT `_ik_sub`.`id` FROM (SELECT `id` FROM `users` WHERE `active` = ? LIMIT 10) AS `_ik_sub`) AND NOT (`categoryId` IS NOT NULL AND `categoryId` IN (SELECT `_ik_sub`.`id` FROM (SELECT `id` FROM `categories`) AS `_ik_sub` WHERE `_ik_sub`.`id` IS NOT NULL))", []any{true}},
+			},
+		},
+		{
+			name: "windows",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Windows: &[]types.Window{
+				types.RowNumber("rank").PartitionedBy("authorId").OrderedBy(types.OrderBy{Field: "views", Descending: &desc}),
+				types.WindowOf(types.Sum("views"), "recent_views").OrderedBy(types.OrderBy{Field: "createdAt"}).Within(types.WindowFrame{Unit: "rows", Start: &minusTwo, End: &zero}),
+			}}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT "posts".*, ROW_NUMBER() OVER (PARTITION BY "authorId" ORDER BY "views" DESC) AS "rank", SUM("views") OVER (ORDER BY "createdAt" ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS "recent_views" FROM "posts"`, nil},
+				sqlgen.SQLite:   {`SELECT "posts".*, ROW_NUMBER() OVER (PARTITION BY "authorId" ORDER BY "views" DESC NULLS FIRST) AS "rank", SUM("views") OVER (ORDER BY "createdAt" NULLS LAST ROWS BETWEEN 2 PRECEDING AND CURRENT ROW) AS "recent_views" FROM "posts"`, nil},
+			},
+		},
+		{
+			name: "policy",
+			stmt: &types.Statement{
+				Query:  &types.Query{Model: "posts", Where: &types.Filter{Or: &[]types.Filter{conds(types.Eq("featured", true)), conds(types.Gt("views", 10))}}},
+				Policy: &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+			},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts" WHERE ("featured" = $1 OR "views" > $2) AND "orgId" = $3`, []any{true, 10, "o1"}},
+				sqlgen.MySQL:    {"SELECT * FROM `posts` WHERE (`featured` = ? OR `views` > ?) AND `orgId` = ?", []any{true, 10, "o1"}},
+			},
+		},
+		{
+			name: "offset without limit",
+			stmt: &types.Statement{Query: &types.Query{Model: "posts", Offset: &offset}},
+			want: map[sqlgen.Dialect]rendered{
+				sqlgen.Postgres: {`SELECT * FROM "posts" OFFSET 20`, nil},
+				sqlgen.MySQL:    {"SELECT * FROM `posts` LIMIT 18446744073709551615 OFFSET 20", nil},
+				sqlgen.SQLite:   {`SELECT * FROM "posts" LIMIT -1 OFFSET 20`, nil},
+			},
+		},
+	}
+
+	for _, tt := range tcs {
+		for dialect, want := range tt.want {
+			t.Run(tt.name+"/"+string(dialect), func(t *testing.T) {
+				got, err := sqlgen.Render(tt.stmt, dialect)
+				if err != nil {
+					t.Fatalf("Render failed: %v", err)
+				}
+				if got.SQL != want.sql {
+					t.Errorf("SQL =\n%s\nwant\n%s", got.SQL, want.sql)
+				}
+				if (len(got.Args) > 0 || len(want.args) > 0) && !reflect.DeepEqual(got.Args, want.args) {
+					t.Errorf("Args = %#v, want %#v", got.Args, want.args)
+				}
+			})
+		}
+	}
+}
+
+func TestRenderCursorPagination(t *testing.T) {
+	desc, insensitive := true, false
+	orderBy := []types.OrderBy{{Field: "createdAt", Descending: &desc}, {Field: "title", CaseSensitive: &insensitive}}
+	cursor, err := pagination.CursorFromRow(orderBy, map[string]any{"createdAt": "2024-01-15", "title": "Go"})
+	if err != nil {
+		t.Fatalf("CursorFromRow failed: %v", err)
+	}
+	first, last, limit := 20, 5, 10
+
+	stmt := &types.Statement{
+		Query:      &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("published", true)}}, OrderBy: &orderBy, Limit: &limit},
+		Pagination: &types.Pagination{First: &first, After: &cursor},
+	}
+	got, err := sqlgen.Render(stmt, sqlgen.Postgres)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want := `SELECT * FROM "posts" WHERE "published" = $1 AND ("createdAt" < $2 OR ("createdAt" = $3 AND LOWER("title") > LOWER($4))) ORDER BY "createdAt" DESC, LOWER("title") LIMIT 10`
+	if got.SQL != want || got.Reversed {
+		t.Errorf("forward page =\n%s (reversed %v)\nwant\n%s", got.SQL, got.Reversed, want)
+	}
+	if wantArgs := []any{true, "2024-01-15", "2024-01-15", "Go"}; !reflect.DeepEqual(got.Args, wantArgs) {
+		t.Errorf("Args = %#v, want %#v", got.Args, wantArgs)
+	}
+
+	stmt.Query.Where, stmt.Query.Limit = nil, nil
+	stmt.Pagination = &types.Pagination{Last: &last, Before: &cursor}
+	got, err = sqlgen.Render(stmt, sqlgen.SQLite)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want = `SELECT * FROM "posts" WHERE ("createdAt" > ? OR ("createdAt" = ? AND LOWER("title") < LOWER(?))) ORDER BY "createdAt" NULLS LAST, LOWER("title") DESC NULLS FIRST LIMIT 5`
+	if got.SQL != want || !got.Reversed {
+		t.Errorf("backward page =\n%s (reversed %v)\nwant\n%s", got.SQL, got.Reversed, want)
+	}
+
+	// The keyset pages the rows distinct keeps, not those it picks from.
+	stmt.Query.Fields, stmt.Query.Distinct = &[]string{"id", "title"}, &[]string{"authorId"}
+	stmt.Pagination = &types.Pagination{First: &first, After: &cursor}
+	got, err = sqlgen.Render(stmt, sqlgen.Postgres)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	want = `SELECT "id", "title" FROM (SELECT "posts".*, ROW_NUMBER() OVER (PARTITION BY "authorId" ORDER BY "createdAt" DESC, LOWER("title")) AS "_ik_row" FROM "posts") AS "posts" WHERE "_ik_row" = 1 AND ("createdAt" < $1 OR ("createdAt" = $2 AND LOWER("title") > LOWER($3))) ORDER BY "createdAt" DESC, LOWER("title") LIMIT 20`
+	if got.SQL != want || got.Reversed {
+		t.Errorf("distinct page =\n%s (reversed %v)\nwant\n%s", got.SQL, got.Reversed, want)
+	}
+
+	// Integers past 2^53 are bound exactly, not rounded through float64.
+	byID := []types.OrderBy{{Field: "id"}}
+	const id = int64(1)<<53 + 1
+	idCursor, err := pagination.CursorFromRow(byID, map[string]any{"id": id})
+	if err != nil {
+		t.Fatalf("CursorFromRow failed: %v", err)
+	}
+	got, err = sqlgen.Render(&types.Statement{
+		Query:      &types.Query{Model: "posts", OrderBy: &byID},
+		Pagination: &types.Pagination{First: &first, After: &idCursor},
+	}, sqlgen.Postgres)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if wantArgs := []any{id}; !reflect.DeepEqual(got.Args, wantArgs) {
+		t.Errorf("Args = %#v, want %#v", got.Args, wantArgs)
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	some, only := "some", "only"
+	bad := "bm90IGpzb24="
+	extra := "eyJpZCI6MSwieCI6Mn0=" // {"id":1,"x":2}
+	tcs := []struct {
+		name        string
+		stmt        *types.Statement
+		dialect     sqlgen.Dialect
+		unsupported bool
+	}{
+		{"no query", &types.Statement{}, sqlgen.Postgres, false},
+		{"unknown dialect", &types.Statement{Query: &types.Query{Model: "posts"}}, "oracle", false},
+		{"relation filter", &types.Statement{Query: &types.Query{Model: "users"}, Includes: []types.Include{{Query: &types.Query{Model: "posts"}, Kind: &some}}}, sqlgen.Postgres, true},
+		{"join", &types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{types.InnerJoin("author")}}}, sqlgen.Postgres, true},
+		{"deleted", &types.Statement{Query: &types.Query{Model: "posts", Deleted: &only}}, sqlgen.Postgres, true},
+		{"subquery selecting two fields", &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.InQuery("a", types.Query{Model: "users", Fields: &[]string{"id", "name"}})}}}}, sqlgen.Postgres, false},
+		{"custom operator", &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "a", Op: "custom:near", Value: 1}}}}}, sqlgen.Postgres, true},
+		{"geo operator", &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.WithinRadius("a", types.GeoPoint{}, 10)}}}}, sqlgen.Postgres, true},
+		{"jsonType", &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "a", Op: "jsonType", Value: "object"}}}}}, sqlgen.Postgres, true},
+		{"jsonContains in sqlite", &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "a", Op: "jsonContains", Value: map[string]any{}}}}}}, sqlgen.SQLite, true},
+		{"window with grouping", &types.Statement{Query: &types.Query{Model: "posts", Windows: &[]types.Window{types.RowNumber("n").OrderedBy(types.OrderBy{Field: "id"})}}, GroupBy: &[]string{"authorId"}}, sqlgen.Postgres, true},
+		{"cursor without order", &types.Statement{Query: &types.Query{Model: "posts"}, Pagination: &types.Pagination{After: &bad}}, sqlgen.Postgres, false},
+		{"malformed cursor", &types.Statement{Query: &types.Query{Model: "posts", OrderBy: &[]types.OrderBy{{Field: "id"}}}, Pagination: &types.Pagination{After: &bad}}, sqlgen.Postgres, false},
+		{"cursor for another order", &types.Statement{Query: &types.Query{Model: "posts", OrderBy: &[]types.OrderBy{{Field: "id"}}}, Pagination: &types.Pagination{After: &extra}}, sqlgen.Postgres, false},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := sqlgen.Render(tt.stmt, tt.dialect)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if errors.Is(err, sqlgen.ErrUnsupported) != tt.unsupported {
+				t.Errorf("errors.Is(%v, ErrUnsupported) = %v, want %v", err, !tt.unsupported, tt.unsupported)
+			}
+		})
+	}
+}
+
+func TestRenderVectors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "tools", "tests", "vectors", "query-shapes.json"))
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+	var vectors []struct {
+		Name  string          `json:"name"`
+		Shape types.Statement `json:"shape"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	rendered := 0
+	for _, v := range vectors {
+		for _, dialect := range []sqlgen.Dialect{sqlgen.Postgres, sqlgen.MySQL, sqlgen.SQLite} {
+			if _, err := sqlgen.Render(&v.Shape, dialect); err == nil {
+				rendered++
+			} else {
+				t.Logf("%s (%s): %v", v.Name, dialect, err)
+			}
+		}
+	}
+	if rendered < len(vectors)*3/2 {
+		t.Errorf("only %d of %d vector renderings succeeded", rendered, len(vectors)*3)
+	}
+}
+
+func conds(cs ...types.Condition) types.Filter {
+	return types.Filter{Conditions: &cs}
+}
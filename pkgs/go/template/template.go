@@ -0,0 +1,173 @@
+// Package template supports Statement templates: statements whose condition
+// values contain named placeholders such as "{{userId}}", bound to concrete
+// values per request.
+//
+// Templates let a family of shapes be declared statically (e.g., in a config
+// file) and instantiated with Bind. A placeholder must be the whole value of
+// a condition, or a whole element of an array value:
+//
+//	{"field": "authorId", "op": "eq", "value": "{{userId}}"}
+//	{"field": "status", "op": "in", "value": ["{{primary}}", "archived"]}
+package template
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/walk"
+)
+
+var placeholderRe = regexp.MustCompile(`^\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}$`)
+
+// Template is a Statement with named placeholders in its condition values.
+type Template struct {
+	stmt   types.Statement
+	params []string
+}
+
+// New creates a template from a statement. The statement is copied, so later
+// changes to stmt do not affect the template.
+//
+// Returns an error if the statement cannot be copied.
+func New(stmt *types.Statement) (*Template, error) {
+	cp, err := clone(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	_ = walk.Walk(cp, walk.Visitor{
+		Condition: func(_ string, c *types.Condition) error {
+			forEachPlaceholder(c.Value, func(name string) { seen[name] = true })
+			return nil
+		},
+	})
+
+	params := make([]string, 0, len(seen))
+	for name := range seen {
+		params = append(params, name)
+	}
+	sort.Strings(params)
+
+	return &Template{stmt: *cp, params: params}, nil
+}
+
+// Parse creates a template from statement JSON.
+func Parse(data []byte) (*Template, error) {
+	var stmt types.Statement
+	if err := json.Unmarshal(data, &stmt); err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return New(&stmt)
+}
+
+// Params returns the sorted placeholder names used by the template.
+func (t *Template) Params() []string {
+	return append([]string{}, t.params...)
+}
+
+// Bind returns a concrete statement with every placeholder replaced by its
+// parameter value, coerced with types.Value. Extra parameters are ignored.
+//
+// Returns an error naming the first missing parameter, or a parameter whose
+// value cannot be coerced.
+func (t *Template) Bind(params map[string]any) (*types.Statement, error) {
+	for _, name := range t.params {
+		if _, ok := params[name]; !ok {
+			return nil, fmt.Errorf("template: missing parameter %q", name)
+		}
+	}
+
+	stmt, err := clone(&t.stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = walk.Walk(stmt, walk.Visitor{
+		Condition: func(path string, c *types.Condition) error {
+			bound, err := bindValue(c.Value, params)
+			if err != nil {
+				return fmt.Errorf("template: %s: %w", path, err)
+			}
+			c.Value = bound
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stmt, nil
+}
+
+// Bind is a shorthand for New followed by Template.Bind.
+func Bind(stmt *types.Statement, params map[string]any) (*types.Statement, error) {
+	t, err := New(stmt)
+	if err != nil {
+		return nil, err
+	}
+	return t.Bind(params)
+}
+
+func bindValue(v any, params map[string]any) (any, error) {
+	switch val := v.(type) {
+	case string:
+		name, ok := placeholderName(val)
+		if !ok {
+			return val, nil
+		}
+		return types.Value(params[name])
+	case []any:
+		out := make([]any, len(val))
+		for i, elem := range val {
+			bound, err := bindValue(elem, params)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = bound
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+func forEachPlaceholder(v any, fn func(name string)) {
+	switch val := v.(type) {
+	case string:
+		if name, ok := placeholderName(val); ok {
+			fn(name)
+		}
+	case []any:
+		for _, elem := range val {
+			forEachPlaceholder(elem, fn)
+		}
+	}
+}
+
+func placeholderName(s string) (string, bool) {
+	m := placeholderRe.FindStringSubmatch(s)
+	if m == nil {
+		return "", false
+	}
+	return m[1], true
+}
+
+// clone deep-copies a statement through its JSON form, which is the form
+// templates are declared in.
+func clone(stmt *types.Statement) (*types.Statement, error) {
+	if stmt == nil {
+		return nil, fmt.Errorf("template: statement is nil")
+	}
+	data, err := json.Marshal(stmt)
+	if err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	var cp types.Statement
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("template: %w", err)
+	}
+	return &cp, nil
+}
@@ -0,0 +1,65 @@
+package template_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/template"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+const postsByAuthor = `{
+	"query": {
+		"model": "posts",
+		"where": {"conditions": [
+			{"field": "authorId", "op": "eq", "value": "{{userId}}"},
+			{"field": "status", "op": "in", "value": ["{{ status }}", "archived"]}
+		]}
+	},
+	"includes": [{"query": {"model": "comments", "where": {"conditions": [
+		{"field": "authorId", "op": "ne", "value": "{{userId}}"}
+	]}}}]
+}`
+
+func TestParseAndBind(t *testing.T) {
+	tmpl, err := template.Parse([]byte(postsByAuthor))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	params := tmpl.Params()
+	if len(params) != 2 || params[0] != "status" || params[1] != "userId" {
+		t.Errorf("Unexpected params: %v", params)
+	}
+
+	stmt, err := tmpl.Bind(map[string]any{"userId": "u_1", "status": "published"})
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+
+	data, _ := json.Marshal(stmt.Query.Where)
+	want := `{"conditions":[{"field":"authorId","op":"eq","value":"u_1"},{"field":"status","op":"in","value":["published","archived"]}]}`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+	if v := (*stmt.Includes[0].Query.Where.Conditions)[0].Value; v != "u_1" {
+		t.Errorf("Expected include placeholder bound, got %v", v)
+	}
+
+	// The template stays reusable
+	again, err := tmpl.Bind(map[string]any{"userId": "u_2", "status": "draft"})
+	if err != nil {
+		t.Fatalf("Bind failed: %v", err)
+	}
+	if v := (*again.Query.Where.Conditions)[0].Value; v != "u_2" {
+		t.Errorf("Expected u_2, got %v", v)
+	}
+}
+
+func TestBindMissingParam(t *testing.T) {
+	stmt := types.NewStatement(types.WithModel("posts"), types.WithConditions(types.Eq("authorId", "{{userId}}")))
+
+	if _, err := template.Bind(stmt, map[string]any{}); err == nil {
+		t.Error("Expected missing parameter error")
+	}
+}
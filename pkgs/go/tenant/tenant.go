@@ -0,0 +1,231 @@
+// Package tenant injects tenant scoping into Statements and Mutations of the
+// IncludeKit Universal Format, so multi-tenant services enforce scoping once
+// at the spec layer instead of in every adapter.
+package tenant
+
+import (
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Scoper injects a "<field> eq <tenant>" condition into every query and
+// change it scopes.
+//
+// Queries are matched by Query.Model, which for includes is the relation
+// name (e.g., "posts"), joins by Join.Relation, and changes by Change.Model.
+type Scoper struct {
+	// Field is the tenant field for models not listed in Models.
+	// An empty Field leaves unlisted models unscoped.
+	Field string
+	// Models overrides the tenant field per model. An empty value disables
+	// scoping for that model (e.g., shared lookup tables).
+	Models map[string]string
+}
+
+// FieldFor returns the tenant field for a model, or "" if it is not scoped.
+func (s Scoper) FieldFor(model string) string {
+	if field, ok := s.Models[model]; ok {
+		return field
+	}
+	return s.Field
+}
+
+// Statement returns a copy of stmt with the tenant condition ANDed into the
+// where clause of the root query and of every include query, nested includes
+// included, into the on filter of every join, and into the where of every
+// condition subquery, in having and the policy too. Its Tenant is set to the
+// tenant as RecordID writes a key value, so each tenant's read has a shape ID
+// of its own. The input statement is not modified.
+func (s Scoper) Statement(stmt *types.Statement, tenant any) (*types.Statement, error) {
+	if stmt == nil {
+		return nil, fmt.Errorf("tenant: statement is nil")
+	}
+	value, id, err := tenantValue(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *stmt
+	scoped.Query = s.scopeQuery(stmt.Query, value)
+	scoped.Includes = s.scopeIncludes(stmt.Includes, value)
+	scoped.Having = s.scopeFilter(stmt.Having, value)
+	scoped.Policy = s.scopeFilter(stmt.Policy, value)
+	scoped.Tenant = &id
+	return &scoped, nil
+}
+
+// Mutation returns a copy of m scoped to the tenant: updates and deletes get
+// the tenant condition ANDed into their where clause, and inserts get the
+// tenant field set. Condition subqueries are scoped as in Statement, and
+// Tenant is set as there, so the mutation invalidates only the tenant's
+// shapes. The input mutation is not modified.
+//
+// Returns an error if an insert sets the tenant field to another tenant, or
+// an update sets the tenant field at all (moving rows across tenants).
+func (s Scoper) Mutation(m *types.Mutation, tenant any) (*types.Mutation, error) {
+	if m == nil {
+		return nil, fmt.Errorf("tenant: mutation is nil")
+	}
+	value, id, err := tenantValue(tenant)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := *m
+	scoped.Tenant = &id
+	scoped.Changes = make([]types.Change, len(m.Changes))
+	for i, change := range m.Changes {
+		change.Where = s.scopeFilter(change.Where, value)
+		field := s.FieldFor(change.Model)
+		if field == "" {
+			scoped.Changes[i] = change
+			continue
+		}
+
+		for _, kv := range change.Sets {
+			if kv.Field != field {
+				continue
+			}
+			if change.Action != "insert" || !sameTenant(kv.Value, value, id) {
+				return nil, fmt.Errorf("tenant: changes[%d] %s sets tenant field %s", i, change.Action, field)
+			}
+		}
+
+		switch change.Action {
+		case "insert":
+			if !setsField(change.Sets, field) {
+				change.Sets = append(append([]types.KV{}, change.Sets...), types.KV{Field: field, Value: value})
+			}
+		default:
+			change.Where = withCondition(change.Where, types.Condition{Field: field, Op: "eq", Value: value})
+		}
+		scoped.Changes[i] = change
+	}
+	return &scoped, nil
+}
+
+// tenantValue returns the tenant coerced as a condition value and as the
+// Tenant of scoped documents. Returns an error unless it is a string, number
+// or boolean.
+func tenantValue(tenant any) (any, string, error) {
+	value, err := types.Value(tenant)
+	if err != nil {
+		return nil, "", fmt.Errorf("tenant: %w", err)
+	}
+	id, err := types.RecordID(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("tenant: must be a string, number or boolean, got %T", tenant)
+	}
+	return value, id, nil
+}
+
+// sameTenant reports whether v names the tenant with the given value and ID
+// from tenantValue, so that 7, int64(7) and 7.0 all match. A string never
+// matches a number or boolean of the same text.
+func sameTenant(v, value any, id string) bool {
+	other, otherID, err := tenantValue(v)
+	if err != nil {
+		return false
+	}
+	_, isString := value.(string)
+	_, otherIsString := other.(string)
+	return isString == otherIsString && otherID == id
+}
+
+func (s Scoper) scopeQuery(q *types.Query, value any) *types.Query {
+	if q == nil {
+		return nil
+	}
+	scoped := *q
+	scoped.Where = s.scopeFilter(q.Where, value)
+	if field := s.FieldFor(q.Model); field != "" {
+		scoped.Where = withCondition(scoped.Where, types.Condition{Field: field, Op: "eq", Value: value})
+	}
+	scoped.Joins = s.scopeJoins(q.Joins, value)
+	return &scoped
+}
+
+// scopeJoins returns a copy of joins with the tenant condition of each
+// joined relation ANDed into its on filter, which tests the joined model's
+// fields.
+func (s Scoper) scopeJoins(joins *[]types.Join, value any) *[]types.Join {
+	if joins == nil {
+		return nil
+	}
+	scoped := make([]types.Join, len(*joins))
+	for i, join := range *joins {
+		join.On = s.scopeFilter(join.On, value)
+		if field := s.FieldFor(join.Relation); field != "" {
+			join.On = withCondition(join.On, types.Condition{Field: field, Op: "eq", Value: value})
+		}
+		scoped[i] = join
+	}
+	return &scoped
+}
+
+// scopeFilter returns a copy of f with the subquery of every condition
+// scoped, so inQuery and existsQuery cannot read across tenants.
+func (s Scoper) scopeFilter(f *types.Filter, value any) *types.Filter {
+	if f == nil {
+		return nil
+	}
+	scoped := *f
+	scoped.And = s.scopeFilters(f.And, value)
+	scoped.Or = s.scopeFilters(f.Or, value)
+	scoped.Not = s.scopeFilter(f.Not, value)
+	if f.Conditions != nil {
+		conds := make([]types.Condition, len(*f.Conditions))
+		for i, c := range *f.Conditions {
+			c.Subquery = s.scopeQuery(c.Subquery, value)
+			conds[i] = c
+		}
+		scoped.Conditions = &conds
+	}
+	return &scoped
+}
+
+func (s Scoper) scopeFilters(filters *[]types.Filter, value any) *[]types.Filter {
+	if filters == nil {
+		return nil
+	}
+	scoped := make([]types.Filter, len(*filters))
+	for i := range *filters {
+		scoped[i] = *s.scopeFilter(&(*filters)[i], value)
+	}
+	return &scoped
+}
+
+func (s Scoper) scopeIncludes(includes []types.Include, value any) []types.Include {
+	if includes == nil {
+		return nil
+	}
+	scoped := make([]types.Include, len(includes))
+	for i, include := range includes {
+		include.Query = s.scopeQuery(include.Query, value)
+		include.Includes = s.scopeIncludes(include.Includes, value)
+		scoped[i] = include
+	}
+	return scoped
+}
+
+// withCondition returns a copy of where with cond appended to its top-level
+// conditions, which are ANDed with the rest of the filter.
+func withCondition(where *types.Filter, cond types.Condition) *types.Filter {
+	var scoped types.Filter
+	if where != nil {
+		scoped = *where
+	}
+	conds := append(append([]types.Condition{}, scoped.GetConditions()...), cond)
+	scoped.Conditions = &conds
+	return &scoped
+}
+
+func setsField(sets []types.KV, field string) bool {
+	for _, kv := range sets {
+		if kv.Field == field {
+			return true
+		}
+	}
+	return false
+}
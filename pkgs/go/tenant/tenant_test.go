@@ -0,0 +1,195 @@
+package tenant_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tenant"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+var scoper = tenant.Scoper{
+	Field:  "tenant_id",
+	Models: map[string]string{"countries": "", "orgs": "id"},
+}
+
+func TestScopeStatement(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{Model: "posts", Where: &types.Filter{
+			Conditions: &[]types.Condition{types.Eq("published", true)},
+		}},
+		Includes: []types.Include{
+			{
+				Query:    &types.Query{Model: "comments"},
+				Includes: []types.Include{{Query: &types.Query{Model: "countries"}}},
+			},
+		},
+	}
+
+	scoped, err := scoper.Statement(stmt, "t_1")
+	if err != nil {
+		t.Fatalf("Statement failed: %v", err)
+	}
+
+	data, _ := json.Marshal(scoped)
+	want := `{"query":{"model":"posts","where":{"conditions":[{"field":"published","op":"eq","value":true},{"field":"tenant_id","op":"eq","value":"t_1"}]}},` +
+		`"includes":[{"query":{"model":"comments","where":{"conditions":[{"field":"tenant_id","op":"eq","value":"t_1"}]}},"includes":[{"query":{"model":"countries"}}]}],"tenant":"t_1"}`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+
+	if len(*stmt.Query.Where.Conditions) != 1 || stmt.Includes[0].Query.Where != nil {
+		t.Error("Statement should not modify its input")
+	}
+}
+
+func TestScopeSubqueries(t *testing.T) {
+	stmt := &types.Statement{Query: &types.Query{Model: "countries", Where: &types.Filter{
+		Not: &types.Filter{Conditions: &[]types.Condition{types.InQuery("id", types.Query{Model: "posts", Fields: &[]string{"countryId"}})}},
+	}}}
+
+	scoped, err := scoper.Statement(stmt, "t_1")
+	if err != nil {
+		t.Fatalf("Statement failed: %v", err)
+	}
+
+	data, _ := json.Marshal(scoped)
+	want := `{"query":{"model":"countries","where":{"not":{"conditions":[{"field":"id","op":"inQuery",` +
+		`"subquery":{"model":"posts","fields":["countryId"],"where":{"conditions":[{"field":"tenant_id","op":"eq","value":"t_1"}]}}}]}}},"tenant":"t_1"}`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+
+	if (*stmt.Query.Where.Not.Conditions)[0].Subquery.Where != nil {
+		t.Error("Statement should not modify its input")
+	}
+}
+
+func TestScopeJoins(t *testing.T) {
+	author := types.InnerJoin("author", types.InQuery("id", types.Query{Model: "posts", Fields: &[]string{"authorId"}}))
+	stmt := &types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{author, types.LeftJoin("countries")}}}
+
+	scoped, err := scoper.Statement(stmt, "t_1")
+	if err != nil {
+		t.Fatalf("Statement failed: %v", err)
+	}
+
+	data, _ := json.Marshal(scoped.Query.Joins)
+	want := `[{"relation":"author","kind":"inner","on":{"conditions":[{"field":"id","op":"inQuery",` +
+		`"subquery":{"model":"posts","fields":["authorId"],"where":{"conditions":[{"field":"tenant_id","op":"eq","value":"t_1"}]}}},` +
+		`{"field":"tenant_id","op":"eq","value":"t_1"}]}},{"relation":"countries","kind":"left"}]`
+	if string(data) != want {
+		t.Errorf("got  %s\nwant %s", data, want)
+	}
+
+	if on := (*stmt.Query.Joins)[0].On; len(*on.Conditions) != 1 || (*on.Conditions)[0].Subquery.Where != nil {
+		t.Error("Statement should not modify its input")
+	}
+}
+
+func TestScopeHavingAndPolicySubqueries(t *testing.T) {
+	subquery := func() *types.Filter {
+		return &types.Filter{Conditions: &[]types.Condition{types.InQuery("authorId", types.Query{Model: "orgs", Fields: &[]string{"ownerId"}})}}
+	}
+	stmt := &types.Statement{
+		Query:   &types.Query{Model: "countries"},
+		GroupBy: &[]string{"authorId"},
+		Having:  subquery(),
+		Policy:  subquery(),
+	}
+
+	scoped, err := scoper.Statement(stmt, "t_1")
+	if err != nil {
+		t.Fatalf("Statement failed: %v", err)
+	}
+
+	want := `{"conditions":[{"field":"authorId","op":"inQuery","subquery":{"model":"orgs","fields":["ownerId"],` +
+		`"where":{"conditions":[{"field":"id","op":"eq","value":"t_1"}]}}}]}`
+	for name, f := range map[string]*types.Filter{"having": scoped.Having, "policy": scoped.Policy} {
+		if data, _ := json.Marshal(f); string(data) != want {
+			t.Errorf("%s = %s\nwant %s", name, data, want)
+		}
+	}
+
+	if (*stmt.Having.Conditions)[0].Subquery.Where != nil || (*stmt.Policy.Conditions)[0].Subquery.Where != nil {
+		t.Error("Statement should not modify its input")
+	}
+}
+
+func TestScopeMutation(t *testing.T) {
+	m := &types.Mutation{Changes: []types.Change{
+		{Model: "posts", Action: "insert", Sets: []types.KV{{Field: "id", Value: "p1"}}},
+		{Model: "orgs", Action: "update", Sets: []types.KV{{Field: "name", Value: "x"}}, Where: &types.Filter{}},
+		{Model: "countries", Action: "delete", Where: &types.Filter{}},
+	}}
+
+	scoped, err := scoper.Mutation(m, "t_1")
+	if err != nil {
+		t.Fatalf("Mutation failed: %v", err)
+	}
+
+	if sets := scoped.Changes[0].Sets; len(sets) != 2 || sets[1].Field != "tenant_id" {
+		t.Errorf("Insert should set tenant_id, got %v", sets)
+	}
+	if conds := scoped.Changes[1].Where.GetConditions(); len(conds) != 1 || conds[0].Field != "id" {
+		t.Errorf("Update should be scoped by id, got %v", conds)
+	}
+	if scoped.Changes[2].Where.Conditions != nil {
+		t.Error("Unscoped model should be unchanged")
+	}
+	if tenant, ok := scoped.GetTenant(); !ok || tenant != "t_1" {
+		t.Errorf("Tenant = %q, want t_1", tenant)
+	}
+	if len(m.Changes[0].Sets) != 1 || m.Tenant != nil {
+		t.Error("Mutation should not modify its input")
+	}
+}
+
+func TestScopeTenantForms(t *testing.T) {
+	stmt := &types.Statement{Query: &types.Query{Model: "posts"}}
+	scoped, err := scoper.Statement(stmt, 42)
+	if err != nil {
+		t.Fatalf("Statement failed: %v", err)
+	}
+	if tenant, _ := scoped.GetTenant(); tenant != "42" {
+		t.Errorf("Tenant = %q, want 42", tenant)
+	}
+	if _, err := scoper.Statement(stmt, []string{"t_1"}); err == nil {
+		t.Error("Expected error for a tenant that is not a scalar")
+	}
+}
+
+func TestScopeMutationInsertMatchesNumericTenant(t *testing.T) {
+	// An int tenant matches the float64 a JSON-decoded insert carries
+	for _, value := range []any{float64(42), int64(42), json.Number("42")} {
+		m := &types.Mutation{Changes: []types.Change{
+			{Model: "posts", Action: "insert", Sets: []types.KV{{Field: "tenant_id", Value: value}}},
+		}}
+		scoped, err := scoper.Mutation(m, 42)
+		if err != nil {
+			t.Errorf("Mutation with tenant_id %v (%T) failed: %v", value, value, err)
+			continue
+		}
+		if sets := scoped.Changes[0].Sets; len(sets) != 1 {
+			t.Errorf("Insert should keep its tenant_id, got %v", sets)
+		}
+	}
+
+	m := &types.Mutation{Changes: []types.Change{
+		{Model: "posts", Action: "insert", Sets: []types.KV{{Field: "tenant_id", Value: "42"}}},
+	}}
+	if _, err := scoper.Mutation(m, 42); err == nil {
+		t.Error("Expected error for a string tenant_id under a numeric tenant")
+	}
+}
+
+func TestScopeMutationRejectsCrossTenantWrites(t *testing.T) {
+	for _, change := range []types.Change{
+		{Model: "posts", Action: "insert", Sets: []types.KV{{Field: "tenant_id", Value: "t_2"}}},
+		{Model: "posts", Action: "update", Sets: []types.KV{{Field: "tenant_id", Value: "t_1"}}, Where: &types.Filter{}},
+	} {
+		if _, err := scoper.Mutation(&types.Mutation{Changes: []types.Change{change}}, "t_1"); err == nil {
+			t.Errorf("Expected error for %s setting tenant field", change.Action)
+		}
+	}
+}
@@ -3,8 +3,16 @@
 package tests
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
 	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
 
 	"github.com/bold-minds/includekit-spec/go/types"
 )
@@ -12,83 +20,271 @@ import (
 // Canonicalize returns the JCS (RFC 8785) canonical JSON string representation
 // of the given object.
 //
-// It recursively sorts all object keys in lexicographic order and marshals
-// the result to JSON. This ensures deterministic output for hashing.
+// The object is marshaled to JSON and re-serialized so that:
+//   - object keys are sorted by their UTF-16 code units
+//   - numbers are IEEE 754 doubles written the way ECMAScript's
+//     Number.prototype.toString writes them (so -0 becomes 0, 1E2 becomes
+//     100 and 1e-7 stays 1e-7)
+//   - strings escape only '"', '\\' and control characters, using the short
+//     forms \b, \f, \n, \r and \t where they exist
 //
-// Note: This is a simplified implementation focused on deterministic key ordering
-// for consistent shapeId generation. It does NOT implement the full RFC 8785
-// specification (e.g., number normalization). For shapeId computation purposes,
-// key ordering is sufficient to ensure determinism.
+// This makes the output, and so the shape ID, byte-identical across
+// languages.
 //
-// Returns an error if the object cannot be marshaled to JSON.
+// Returns an error if the object cannot be marshaled to JSON or contains a
+// number outside the double range.
 func Canonicalize(obj interface{}) (string, error) {
 	if obj == nil {
 		return "null", nil
 	}
-	normalized := canonicalizeValue(obj)
-	data, err := json.Marshal(normalized)
+	data, err := json.Marshal(obj)
 	if err != nil {
 		return "", err
 	}
-	return string(data), nil
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	if err := writeCanonical(&buf, v); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
 }
 
-// CanonicalizeQueryShape removes diagnostic fields and canonicalizes
+// CanonicalizeQueryShape canonicalizes a statement. It writes the statement's
+// fields directly rather than round-tripping it through maps, so its output
+// matches Canonicalize at a fraction of the allocations. The diagnostic
+// fields, SpecVersion among them, are left out, and the policy is ANDed into
+// the root where, as they are for shape IDs.
 func CanonicalizeQueryShape(shape *types.Statement) (string, error) {
-	// Make a copy and remove diagnostic fields
-	data, err := json.Marshal(shape)
-	if err != nil {
+	var buf strings.Builder
+	if _, err := writeCanonicalTyped(&buf, withPolicyMerged(withoutDiagnostics(shape))); err != nil {
 		return "", err
 	}
+	return buf.String(), nil
+}
 
-	var m map[string]interface{}
-	if err := json.Unmarshal(data, &m); err != nil {
-		return "", err
+// statementDiagnostics are the indices of the Statement fields whose JSON
+// names specDiagnostics lists.
+var statementDiagnostics = diagnosticFields(reflect.TypeOf(types.Statement{}), specDiagnostics["Statement"])
+
+// diagnosticFields returns the indices of the fields of struct type t named
+// names in JSON. It panics if one has no field, so a schema change the types
+// have not caught up with fails at init rather than hashing the field.
+func diagnosticFields(t reflect.Type, names []string) []int {
+	var indices []int
+	for _, name := range names {
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			tag, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if tag == name {
+				indices = append(indices, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			panic(fmt.Sprintf("tests: diagnostic property %s.%s has no field", t.Name(), name))
+		}
 	}
+	return indices
+}
 
-	delete(m, "orm")
-	delete(m, "adapterVersion")
+// withoutDiagnostics returns stmt, or a shallow copy of it without the
+// diagnostic fields when it has any set.
+func withoutDiagnostics(stmt *types.Statement) *types.Statement {
+	if stmt == nil {
+		return stmt
+	}
+	v := reflect.ValueOf(stmt).Elem()
+	for i, index := range statementDiagnostics {
+		if v.Field(index).IsZero() {
+			continue
+		}
+		s := *stmt
+		copied := reflect.ValueOf(&s).Elem()
+		for _, index := range statementDiagnostics[i:] {
+			copied.Field(index).SetZero()
+		}
+		return &s
+	}
+	return stmt
+}
 
-	return Canonicalize(m)
+// withPolicyMerged returns stmt, or a shallow copy of it with the policy
+// ANDed into the root where when it has both. A policy is hashed as part of
+// what the statement reads; Dependencies.Policy is what keeps it apart.
+func withPolicyMerged(stmt *types.Statement) *types.Statement {
+	if stmt == nil || stmt.Policy == nil || stmt.Query == nil {
+		return stmt
+	}
+	s, q := *stmt, *stmt.Query
+	if q.Where == nil {
+		q.Where = s.Policy
+	} else {
+		q.Where = &types.Filter{And: &[]types.Filter{*q.Where, *s.Policy}}
+	}
+	s.Query, s.Policy = &q, nil
+	return &s
 }
 
-func canonicalizeValue(v interface{}) interface{} {
-	if v == nil {
-		return nil
+// CanonicalizeMutation canonicalizes a mutation event so replays of the same
+// event can be recognised. Every field is kept: tx_id tells apart identical
+// writes from different transactions, and change order is significant.
+func CanonicalizeMutation(m *types.Mutation) (string, error) {
+	var buf strings.Builder
+	if _, err := writeCanonicalTyped(&buf, m); err != nil {
+		return "", err
 	}
+	return buf.String(), nil
+}
+
+// CanonicalizeDependencies canonicalizes a Dependencies document so its
+// checksum is the same on every transport. Every field is kept.
+func CanonicalizeDependencies(deps *types.Dependencies) (string, error) {
+	return Canonicalize(deps)
+}
+
+// canonicalWriter is what the canonical serializers write to: a
+// strings.Builder, or a bufio.Writer in front of a hash.
+type canonicalWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+	WriteRune(r rune) (int, error)
+}
 
+func writeCanonical(buf canonicalWriter, v interface{}) error {
 	switch val := v.(type) {
-	case map[string]interface{}:
-		// Handle nil map
-		if val == nil {
-			return nil
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		if val {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+	case json.Number:
+		num, err := canonicalNumber(val)
+		if err != nil {
+			return err
 		}
-		// Sort keys lexicographically
+		buf.WriteString(num)
+	case string:
+		writeCanonicalString(buf, val)
+	case []interface{}:
+		buf.WriteByte('[')
+		for i, elem := range val {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeCanonical(buf, elem); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case map[string]interface{}:
 		keys := make([]string, 0, len(val))
 		for k := range val {
 			keys = append(keys, k)
 		}
-		sort.Strings(keys)
+		sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i], keys[j]) })
 
-		sorted := make(map[string]interface{}, len(val))
-		for _, k := range keys {
-			sorted[k] = canonicalizeValue(val[k])
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeCanonicalString(buf, k)
+			buf.WriteByte(':')
+			if err := writeCanonical(buf, val[k]); err != nil {
+				return err
+			}
 		}
-		return sorted
+		buf.WriteByte('}')
+	default:
+		return fmt.Errorf("canonicalize: unexpected JSON value %T", v)
+	}
+	return nil
+}
 
-	case []interface{}:
-		// Handle nil slice
-		if val == nil {
-			return nil
+// canonicalNumber formats a JSON number as ECMAScript does (RFC 8785
+// section 3.2.2.3). encoding/json already follows the ES6 algorithm for
+// float64 apart from negative zero.
+func canonicalNumber(n json.Number) (string, error) {
+	f, err := strconv.ParseFloat(string(n), 64)
+	if err != nil {
+		return "", fmt.Errorf("canonicalize: number %s is not a finite double", n)
+	}
+	return canonicalFloat(f), nil
+}
+
+// canonicalFloat formats a finite double as ECMAScript does.
+func canonicalFloat(f float64) string {
+	if f == 0 {
+		return "0"
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs < 1e-6 || abs >= 1e21 {
+		format = 'e'
+	}
+	s := strconv.FormatFloat(f, format, -1, 64)
+	if format == 'e' {
+		// Go pads the exponent to two digits; ES does not (1e-07 -> 1e-7)
+		n := len(s)
+		if n >= 4 && s[n-4] == 'e' && s[n-3] == '-' && s[n-2] == '0' {
+			s = s[:n-2] + s[n-1:]
 		}
-		// Recursively canonicalize array elements
-		result := make([]interface{}, len(val))
-		for i, elem := range val {
-			result[i] = canonicalizeValue(elem)
+	}
+	return s
+}
+
+// writeCanonicalString writes s as a JSON string, escaping only what RFC
+// 8785 requires. Unlike encoding/json it leaves <, >, & and U+2028/U+2029
+// as they are.
+func writeCanonicalString(buf canonicalWriter, s string) {
+	buf.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			buf.WriteString(`\"`)
+		case '\\':
+			buf.WriteString(`\\`)
+		case '\b':
+			buf.WriteString(`\b`)
+		case '\f':
+			buf.WriteString(`\f`)
+		case '\n':
+			buf.WriteString(`\n`)
+		case '\r':
+			buf.WriteString(`\r`)
+		case '\t':
+			buf.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				fmt.Fprintf(buf, `\u%04x`, r)
+			} else {
+				buf.WriteRune(r)
+			}
 		}
-		return result
+	}
+	buf.WriteByte('"')
+}
 
-	default:
-		return v
+// lessUTF16 orders strings by their UTF-16 code units, as RFC 8785 requires
+// for object keys. This differs from Go's byte order for characters above
+// U+FFFF, which sort before U+E000-U+FFFF in UTF-16.
+func lessUTF16(a, b string) bool {
+	ua, ub := utf16.Encode([]rune(a)), utf16.Encode([]rune(b))
+	for i := 0; i < len(ua) && i < len(ub); i++ {
+		if ua[i] != ub[i] {
+			return ua[i] < ub[i]
+		}
 	}
+	return len(ua) < len(ub)
 }
@@ -0,0 +1,290 @@
+package tests
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// WriteCanonical streams the canonical JSON of obj to w: the same bytes
+// Canonicalize returns, written as obj is walked instead of being marshaled,
+// decoded into maps and serialized again. Statements and mutations are
+// written by encoders that know their fields; other types are walked with
+// reflection. Shape and mutation IDs hash through
+// it, so large statements never build their canonical string.
+//
+// Values that marshal themselves (json.Marshaler or encoding.TextMarshaler,
+// such as time.Time in a condition value) and types encoding/json treats
+// specially (embedded structs, ",string" fields, non-string map keys) are
+// marshaled with encoding/json and canonicalized on their own.
+//
+// Returns an error under the same conditions as Canonicalize, or if writing
+// to w fails.
+func WriteCanonical(w io.Writer, obj interface{}) error {
+	bw := bufio.NewWriter(w)
+	ok, err := writeCanonicalTyped(bw, obj)
+	if !ok {
+		err = writeCanonicalValue(bw, reflect.ValueOf(obj))
+	}
+	if err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	jsonNumberType    = reflect.TypeOf(json.Number(""))
+)
+
+func writeCanonicalValue(w canonicalWriter, v reflect.Value) error {
+	if !v.IsValid() {
+		_, err := w.WriteString("null")
+		return err
+	}
+	t := v.Type()
+	if t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface &&
+		(marshalsItself(t) || v.CanAddr() && marshalsItself(reflect.PointerTo(t))) {
+		return writeMarshaled(w, v)
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			_, err := w.WriteString("null")
+			return err
+		}
+		if t.Kind() == reflect.Pointer && marshalsItself(t) {
+			return writeMarshaled(w, v)
+		}
+		return writeCanonicalValue(w, v.Elem())
+	case reflect.Bool:
+		_, err := w.WriteString(strconv.FormatBool(v.Bool()))
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		_, err := w.WriteString(canonicalFloat(float64(v.Int())))
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		_, err := w.WriteString(canonicalFloat(float64(v.Uint())))
+		return err
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return fmt.Errorf("canonicalize: unsupported value %v", f)
+		}
+		if t.Kind() == reflect.Float32 {
+			// encoding/json writes the shortest float32 form, which then
+			// parses as a double
+			f, _ = strconv.ParseFloat(strconv.FormatFloat(f, 'g', -1, 32), 64)
+		}
+		_, err := w.WriteString(canonicalFloat(f))
+		return err
+	case reflect.String:
+		if t == jsonNumberType {
+			num, err := canonicalNumber(json.Number(v.String()))
+			if err != nil {
+				return err
+			}
+			_, err = w.WriteString(num)
+			return err
+		}
+		writeCanonicalString(w, v.String())
+		return nil
+	case reflect.Slice:
+		if v.IsNil() {
+			_, err := w.WriteString("null")
+			return err
+		}
+		if t.Elem().Kind() == reflect.Uint8 && !marshalsItself(t.Elem()) && !marshalsItself(reflect.PointerTo(t.Elem())) {
+			writeCanonicalString(w, base64.StdEncoding.EncodeToString(v.Bytes()))
+			return nil
+		}
+		return writeCanonicalArray(w, v)
+	case reflect.Array:
+		return writeCanonicalArray(w, v)
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return writeMarshaled(w, v)
+		}
+		if v.IsNil() {
+			_, err := w.WriteString("null")
+			return err
+		}
+		return writeCanonicalMap(w, v)
+	case reflect.Struct:
+		fields, ok := canonicalFieldsOf(t)
+		if !ok {
+			return writeMarshaled(w, v)
+		}
+		return writeCanonicalStruct(w, v, fields)
+	}
+	return fmt.Errorf("canonicalize: unsupported type %s", t)
+}
+
+func marshalsItself(t reflect.Type) bool {
+	return t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType)
+}
+
+// writeMarshaled canonicalizes v the way Canonicalize does: through
+// encoding/json and back.
+func writeMarshaled(w canonicalWriter, v reflect.Value) error {
+	obj := v.Interface()
+	if v.CanAddr() && marshalsItself(reflect.PointerTo(v.Type())) {
+		// encoding/json calls pointer-receiver methods on addressable values
+		obj = v.Addr().Interface()
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return err
+	}
+	return writeCanonical(w, decoded)
+}
+
+func writeCanonicalArray(w canonicalWriter, v reflect.Value) error {
+	w.WriteByte('[')
+	for i := 0; i < v.Len(); i++ {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := writeCanonicalValue(w, v.Index(i)); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte(']')
+}
+
+func writeCanonicalMap(w canonicalWriter, v reflect.Value) error {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(i, j int) bool { return lessUTF16(keys[i].String(), keys[j].String()) })
+
+	w.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		writeCanonicalString(w, k.String())
+		w.WriteByte(':')
+		if err := writeCanonicalValue(w, v.MapIndex(k)); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+func writeCanonicalStruct(w canonicalWriter, v reflect.Value, fields []canonicalField) error {
+	w.WriteByte('{')
+	first := true
+	for _, f := range fields {
+		fv := v.Field(f.index)
+		if f.omitEmpty && isEmptyValue(fv) {
+			continue
+		}
+		if !first {
+			w.WriteByte(',')
+		}
+		first = false
+		writeCanonicalString(w, f.name)
+		w.WriteByte(':')
+		if err := writeCanonicalValue(w, fv); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('}')
+}
+
+// canonicalField is a struct field as encoding/json names it.
+type canonicalField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+var canonicalFields sync.Map // reflect.Type -> []canonicalField, or nil if unsupported
+
+// canonicalFieldsOf returns t's JSON fields in canonical key order. Reports
+// false for structs encoding/json treats specially, which are marshaled
+// instead.
+func canonicalFieldsOf(t reflect.Type) ([]canonicalField, bool) {
+	if cached, ok := canonicalFields.Load(t); ok {
+		fields := cached.([]canonicalField)
+		return fields, fields != nil
+	}
+
+	var fields []canonicalField
+	supported := true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			supported = false
+			break
+		}
+		if !f.IsExported() {
+			continue
+		}
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if name == "" {
+			name = f.Name
+		}
+		field := canonicalField{name: name, index: i}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "omitempty":
+				field.omitEmpty = true
+			case "string":
+				supported = false
+			}
+		}
+		fields = append(fields, field)
+	}
+	if !supported {
+		canonicalFields.Store(t, []canonicalField(nil))
+		return nil, false
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return lessUTF16(fields[i].name, fields[j].name) })
+	if fields == nil {
+		fields = []canonicalField{}
+	}
+	canonicalFields.Store(t, fields)
+	return fields, true
+}
+
+// isEmptyValue reports whether omitempty drops v, as in encoding/json.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
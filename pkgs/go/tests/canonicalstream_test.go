@@ -0,0 +1,163 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+type upper string
+
+func (u *upper) MarshalJSON() ([]byte, error) {
+	return json.Marshal(strings.ToUpper(string(*u)))
+}
+
+type embedded struct {
+	types.KV
+	Extra int `json:"extra,omitempty"`
+}
+
+type tagged struct {
+	N      int    `json:"n,string"`
+	Hidden string `json:"-"`
+	Plain  string
+	skip   string
+}
+
+func TestWriteCanonicalMatchesCanonicalize(t *testing.T) {
+	loud := upper("loud")
+	when := time.Date(2024, 1, 15, 10, 30, 0, 0, time.FixedZone("", 3600))
+
+	tcs := []struct {
+		name string
+		obj  any
+	}{
+		{"nil", nil},
+		{"nil statement", (*types.Statement)(nil)},
+		{"statement", largeStatement(3)},
+		{"mutation", &types.Mutation{Changes: []types.Change{{
+			Model: "Post", Action: "update",
+			Sets:  []types.KV{{Field: "views", Value: int64(1) << 60}},
+			Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}},
+		}}}},
+		{"numbers", []any{0, -0.0, 1e21, 1e-7, 123.456, float32(0.1), uint64(math.MaxUint64), json.Number("1E2"), int8(-3)}},
+		{"strings", []any{"tab\there", "quote\"back\\slash", "\x01\x1f", "<a>&</a>", " ", "bad\xffutf8", "😀"}},
+		{"utf16 key order", map[string]any{"\uE000": 1, "\U0001F600": 2, "a": 3, "": 4}},
+		{"time", types.Condition{Field: "createdAt", Op: "gt", Value: when}},
+		{"bytes", []byte("binary\x00data")},
+		{"nil containers", map[string]any{"slice": []string(nil), "map": map[string]int(nil), "ptr": (*int)(nil)}},
+		{"empty containers", map[string]any{"slice": []string{}, "map": map[string]int{}}},
+		{"int keys", map[int]string{2: "b", 10: "a"}},
+		{"embedded struct", embedded{KV: types.KV{Field: "f", Value: 1}}},
+		{"tags", tagged{N: 5, Hidden: "x", Plain: "p", skip: "s"}},
+		{"pointer receiver marshaler", &struct{ U upper }{U: loud}},
+		{"array", [3]bool{true, false, true}},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			want, err := tests.Canonicalize(tt.obj)
+			if err != nil {
+				t.Fatalf("Canonicalize failed: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := tests.WriteCanonical(&buf, tt.obj); err != nil {
+				t.Fatalf("WriteCanonical failed: %v", err)
+			}
+			if buf.String() != want {
+				t.Errorf("WriteCanonical() = %s\nwant %s", buf.String(), want)
+			}
+		})
+	}
+}
+
+func TestWriteCanonicalErrors(t *testing.T) {
+	for _, obj := range []any{
+		types.Condition{Field: "f", Op: "eq", Value: math.NaN()},
+		map[string]any{"inf": math.Inf(1)},
+		map[string]any{"big": json.Number("1e400")},
+		make(chan int),
+	} {
+		if _, err := tests.Canonicalize(obj); err == nil {
+			t.Fatalf("Canonicalize(%v) should fail", obj)
+		}
+		if err := tests.WriteCanonical(&bytes.Buffer{}, obj); err == nil {
+			t.Errorf("WriteCanonical(%v) should fail like Canonicalize", obj)
+		}
+	}
+}
+
+func TestComputeQueryShapeIDStreamsCanonicalJSON(t *testing.T) {
+	stmt := largeStatement(1000)
+	canonical, err := tests.Canonicalize(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tests.ComputeQueryShapeID(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := tests.ComputeShapeID(canonical); got != want {
+		t.Errorf("ComputeQueryShapeID() = %s, want %s", got, want)
+	}
+}
+
+// largeStatement returns a statement with n conditions of mixed value types.
+func largeStatement(n int) *types.Statement {
+	conds := make([]types.Condition, n)
+	for i := range conds {
+		var value any
+		switch i % 4 {
+		case 0:
+			value = i
+		case 1:
+			value = fmt.Sprintf("value-%d", i)
+		case 2:
+			value = []any{i, float64(i) / 3, true}
+		default:
+			value = map[string]any{"k": i, "nested": []string{"a", "b"}}
+		}
+		conds[i] = types.Condition{Field: fmt.Sprintf("field_%d", i), Op: "eq", Value: value}
+	}
+	limit, desc := 50, true
+	return &types.Statement{
+		Query: &types.Query{
+			Model:   "Post",
+			Where:   &types.Filter{Or: &[]types.Filter{{Conditions: &conds}}},
+			OrderBy: &[]types.OrderBy{{Field: "createdAt", Descending: &desc}},
+			Limit:   &limit,
+		},
+		Includes: []types.Include{{Query: &types.Query{Model: "author"}}},
+	}
+}
+
+func BenchmarkComputeQueryShapeID(b *testing.B) {
+	stmt := largeStatement(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := tests.ComputeQueryShapeID(stmt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkComputeQueryShapeIDFromString hashes the string Canonicalize
+// builds, for comparison with the streaming path.
+func BenchmarkComputeQueryShapeIDFromString(b *testing.B) {
+	stmt := largeStatement(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		canonical, err := tests.Canonicalize(stmt)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = tests.ComputeShapeID(canonical)
+	}
+}
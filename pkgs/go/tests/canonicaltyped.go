@@ -0,0 +1,578 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// The encoders below write statements and mutations field by field, with
+// keys already escaped and in UTF-16 order, so canonicalizing them needs no
+// reflection. Only condition and KV values, which may hold anything, go
+// through writeCanonicalValue. They must list every JSON field of the types
+// they encode; the tests compare them against Canonicalize.
+
+// writeCanonicalTyped writes obj with a typed encoder. Reports false for
+// types without one, which use writeCanonicalValue.
+func writeCanonicalTyped(w canonicalWriter, obj interface{}) (bool, error) {
+	switch v := obj.(type) {
+	case *types.Statement:
+		if v == nil {
+			_, err := w.WriteString("null")
+			return true, err
+		}
+		return true, writeCanonicalStatement(w, v)
+	case types.Statement:
+		return true, writeCanonicalStatement(w, &v)
+	case *types.Mutation:
+		if v == nil {
+			_, err := w.WriteString("null")
+			return true, err
+		}
+		return true, writeCanonicalMutation(w, v)
+	case types.Mutation:
+		return true, writeCanonicalMutation(w, &v)
+	}
+	return false, nil
+}
+
+// canonicalObject writes the members of a JSON object, separating them with
+// commas.
+type canonicalObject struct {
+	w     canonicalWriter
+	empty bool
+}
+
+func beginCanonicalObject(w canonicalWriter) canonicalObject {
+	w.WriteByte('{')
+	return canonicalObject{w: w, empty: true}
+}
+
+// key writes a member name, which must be given quoted and followed by a
+// colon.
+func (o *canonicalObject) key(k string) {
+	if !o.empty {
+		o.w.WriteByte(',')
+	}
+	o.empty = false
+	o.w.WriteString(k)
+}
+
+func (o *canonicalObject) end() error {
+	return o.w.WriteByte('}')
+}
+
+func writeCanonicalStatement(w canonicalWriter, s *types.Statement) error {
+	o := beginCanonicalObject(w)
+	if s.GroupBy != nil {
+		o.key(`"group_by":`)
+		writeCanonicalStrings(w, *s.GroupBy)
+	}
+	if s.Having != nil {
+		o.key(`"having":`)
+		if err := writeCanonicalFilter(w, s.Having); err != nil {
+			return err
+		}
+	}
+	if len(s.Includes) > 0 {
+		o.key(`"includes":`)
+		if err := writeCanonicalIncludes(w, s.Includes); err != nil {
+			return err
+		}
+	}
+	if s.ORMVersion != nil {
+		o.key(`"orm_version":`)
+		writeCanonicalString(w, *s.ORMVersion)
+	}
+	if p := s.Pagination; p != nil {
+		o.key(`"pagination":`)
+		writeCanonicalPagination(w, p)
+	}
+	if s.Policy != nil {
+		o.key(`"policy":`)
+		if err := writeCanonicalFilter(w, s.Policy); err != nil {
+			return err
+		}
+	}
+	if s.Query != nil {
+		o.key(`"query":`)
+		if err := writeCanonicalQuery(w, s.Query); err != nil {
+			return err
+		}
+	}
+	if s.SDKVersion != nil {
+		o.key(`"sdk_version":`)
+		writeCanonicalString(w, *s.SDKVersion)
+	}
+	if s.SpecVersion != nil {
+		o.key(`"spec_version":`)
+		writeCanonicalString(w, *s.SpecVersion)
+	}
+	if s.Tenant != nil {
+		o.key(`"tenant":`)
+		writeCanonicalString(w, *s.Tenant)
+	}
+	return o.end()
+}
+
+func writeCanonicalPagination(w canonicalWriter, p *types.Pagination) {
+	o := beginCanonicalObject(w)
+	if p.After != nil {
+		o.key(`"after":`)
+		writeCanonicalString(w, *p.After)
+	}
+	if p.Before != nil {
+		o.key(`"before":`)
+		writeCanonicalString(w, *p.Before)
+	}
+	if p.First != nil {
+		o.key(`"first":`)
+		writeCanonicalInt(w, *p.First)
+	}
+	if p.Last != nil {
+		o.key(`"last":`)
+		writeCanonicalInt(w, *p.Last)
+	}
+	o.end()
+}
+
+func writeCanonicalQuery(w canonicalWriter, q *types.Query) error {
+	o := beginCanonicalObject(w)
+	if q.Aggregations != nil {
+		o.key(`"aggregations":`)
+		writeCanonicalAggregates(w, *q.Aggregations)
+	}
+	if q.Deleted != nil {
+		o.key(`"deleted":`)
+		writeCanonicalString(w, *q.Deleted)
+	}
+	if q.Distinct != nil {
+		o.key(`"distinct":`)
+		writeCanonicalStrings(w, *q.Distinct)
+	}
+	if d := q.DistinctOn; d != nil {
+		o.key(`"distinct_on":`)
+		do := beginCanonicalObject(w)
+		do.key(`"fields":`)
+		writeCanonicalStrings(w, d.Fields)
+		do.key(`"order_by":`)
+		writeCanonicalOrderBy(w, d.OrderBy)
+		do.end()
+	}
+	if q.Fields != nil {
+		o.key(`"fields":`)
+		writeCanonicalStrings(w, *q.Fields)
+	}
+	if q.Joins != nil {
+		o.key(`"joins":`)
+		if err := writeCanonicalJoins(w, *q.Joins); err != nil {
+			return err
+		}
+	}
+	if q.Limit != nil {
+		o.key(`"limit":`)
+		writeCanonicalInt(w, *q.Limit)
+	}
+	o.key(`"model":`)
+	writeCanonicalString(w, q.Model)
+	if q.Offset != nil {
+		o.key(`"offset":`)
+		writeCanonicalInt(w, *q.Offset)
+	}
+	if q.OrderBy != nil {
+		o.key(`"order_by":`)
+		writeCanonicalOrderBy(w, *q.OrderBy)
+	}
+	if q.Where != nil {
+		o.key(`"where":`)
+		if err := writeCanonicalFilter(w, q.Where); err != nil {
+			return err
+		}
+	}
+	if q.Windows != nil {
+		o.key(`"windows":`)
+		writeCanonicalWindows(w, *q.Windows)
+	}
+	return o.end()
+}
+
+func writeCanonicalWindows(w canonicalWriter, windows []types.Window) {
+	if windows == nil {
+		w.WriteString("null")
+		return
+	}
+	w.WriteByte('[')
+	for i := range windows {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		win := &windows[i]
+		o := beginCanonicalObject(w)
+		o.key(`"alias":`)
+		writeCanonicalString(w, win.Alias)
+		if win.Field != nil {
+			o.key(`"field":`)
+			writeCanonicalString(w, *win.Field)
+		}
+		if f := win.Frame; f != nil {
+			o.key(`"frame":`)
+			fo := beginCanonicalObject(w)
+			if f.End != nil {
+				fo.key(`"end":`)
+				writeCanonicalInt(w, *f.End)
+			}
+			if f.Start != nil {
+				fo.key(`"start":`)
+				writeCanonicalInt(w, *f.Start)
+			}
+			fo.key(`"unit":`)
+			writeCanonicalString(w, f.Unit)
+			fo.end()
+		}
+		o.key(`"func":`)
+		writeCanonicalString(w, win.Func)
+		if win.OrderBy != nil {
+			o.key(`"order_by":`)
+			writeCanonicalOrderBy(w, *win.OrderBy)
+		}
+		if win.PartitionBy != nil {
+			o.key(`"partition_by":`)
+			writeCanonicalStrings(w, *win.PartitionBy)
+		}
+		o.end()
+	}
+	w.WriteByte(']')
+}
+
+func writeCanonicalAggregates(w canonicalWriter, aggs []types.Aggregate) {
+	if aggs == nil {
+		w.WriteString("null")
+		return
+	}
+	w.WriteByte('[')
+	for i := range aggs {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		a := &aggs[i]
+		o := beginCanonicalObject(w)
+		if a.Alias != nil {
+			o.key(`"alias":`)
+			writeCanonicalString(w, *a.Alias)
+		}
+		if a.Field != nil {
+			o.key(`"field":`)
+			writeCanonicalString(w, *a.Field)
+		}
+		o.key(`"func":`)
+		writeCanonicalString(w, a.Func)
+		o.end()
+	}
+	w.WriteByte(']')
+}
+
+func writeCanonicalJoins(w canonicalWriter, joins []types.Join) error {
+	if joins == nil {
+		_, err := w.WriteString("null")
+		return err
+	}
+	w.WriteByte('[')
+	for i := range joins {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		j := &joins[i]
+		o := beginCanonicalObject(w)
+		if j.Fields != nil {
+			o.key(`"fields":`)
+			writeCanonicalStrings(w, *j.Fields)
+		}
+		o.key(`"kind":`)
+		writeCanonicalString(w, j.Kind)
+		if j.On != nil {
+			o.key(`"on":`)
+			if err := writeCanonicalFilter(w, j.On); err != nil {
+				return err
+			}
+		}
+		o.key(`"relation":`)
+		writeCanonicalString(w, j.Relation)
+		o.end()
+	}
+	return w.WriteByte(']')
+}
+
+func writeCanonicalOrderBy(w canonicalWriter, orderBy []types.OrderBy) {
+	if orderBy == nil {
+		w.WriteString("null")
+		return
+	}
+	w.WriteByte('[')
+	for i := range orderBy {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		ob := &orderBy[i]
+		o := beginCanonicalObject(w)
+		if ob.CaseSensitive != nil {
+			o.key(`"case_sensitive":`)
+			w.WriteString(strconv.FormatBool(*ob.CaseSensitive))
+		}
+		if ob.Descending != nil {
+			o.key(`"descending":`)
+			w.WriteString(strconv.FormatBool(*ob.Descending))
+		}
+		o.key(`"field":`)
+		writeCanonicalString(w, ob.Field)
+		if ob.NullsFirst != nil {
+			o.key(`"nulls_first":`)
+			w.WriteString(strconv.FormatBool(*ob.NullsFirst))
+		}
+		o.end()
+	}
+	w.WriteByte(']')
+}
+
+func writeCanonicalIncludes(w canonicalWriter, includes []types.Include) error {
+	if includes == nil {
+		_, err := w.WriteString("null")
+		return err
+	}
+	w.WriteByte('[')
+	for i := range includes {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		inc := &includes[i]
+		o := beginCanonicalObject(w)
+		if inc.Count != nil {
+			o.key(`"count":`)
+			c := beginCanonicalObject(w)
+			c.key(`"op":`)
+			writeCanonicalString(w, inc.Count.Op)
+			c.key(`"value":`)
+			writeCanonicalInt(w, inc.Count.Value)
+			c.end()
+		}
+		if len(inc.Includes) > 0 {
+			o.key(`"includes":`)
+			if err := writeCanonicalIncludes(w, inc.Includes); err != nil {
+				return err
+			}
+		}
+		if inc.Kind != nil {
+			o.key(`"kind":`)
+			writeCanonicalString(w, *inc.Kind)
+		}
+		if inc.Query != nil {
+			o.key(`"query":`)
+			if err := writeCanonicalQuery(w, inc.Query); err != nil {
+				return err
+			}
+		}
+		o.end()
+	}
+	return w.WriteByte(']')
+}
+
+func writeCanonicalFilter(w canonicalWriter, f *types.Filter) error {
+	o := beginCanonicalObject(w)
+	if f.And != nil {
+		o.key(`"and":`)
+		if err := writeCanonicalFilters(w, *f.And); err != nil {
+			return err
+		}
+	}
+	if f.Conditions != nil {
+		o.key(`"conditions":`)
+		if err := writeCanonicalConditions(w, *f.Conditions); err != nil {
+			return err
+		}
+	}
+	if f.Not != nil {
+		o.key(`"not":`)
+		if err := writeCanonicalFilter(w, f.Not); err != nil {
+			return err
+		}
+	}
+	if f.Or != nil {
+		o.key(`"or":`)
+		if err := writeCanonicalFilters(w, *f.Or); err != nil {
+			return err
+		}
+	}
+	return o.end()
+}
+
+func writeCanonicalFilters(w canonicalWriter, filters []types.Filter) error {
+	if filters == nil {
+		_, err := w.WriteString("null")
+		return err
+	}
+	w.WriteByte('[')
+	for i := range filters {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		if err := writeCanonicalFilter(w, &filters[i]); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte(']')
+}
+
+func writeCanonicalConditions(w canonicalWriter, conds []types.Condition) error {
+	if conds == nil {
+		_, err := w.WriteString("null")
+		return err
+	}
+	w.WriteByte('[')
+	for i := range conds {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		c := &conds[i]
+		o := beginCanonicalObject(w)
+		o.key(`"field":`)
+		writeCanonicalString(w, c.Field)
+		if len(c.FieldPath) > 0 {
+			o.key(`"field_path":`)
+			writeCanonicalStrings(w, c.FieldPath)
+		}
+		o.key(`"op":`)
+		writeCanonicalString(w, c.Op)
+		if c.Subquery != nil {
+			o.key(`"subquery":`)
+			if err := writeCanonicalQuery(w, c.Subquery); err != nil {
+				return err
+			}
+		}
+		if c.Value != nil {
+			o.key(`"value":`)
+			if err := writeCanonicalAny(w, c.Value); err != nil {
+				return err
+			}
+		}
+		o.end()
+	}
+	return w.WriteByte(']')
+}
+
+func writeCanonicalMutation(w canonicalWriter, m *types.Mutation) error {
+	o := beginCanonicalObject(w)
+	o.key(`"changes":`)
+	if m.Changes == nil {
+		w.WriteString("null")
+	} else {
+		w.WriteByte('[')
+		for i := range m.Changes {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			if err := writeCanonicalChange(w, &m.Changes[i]); err != nil {
+				return err
+			}
+		}
+		w.WriteByte(']')
+	}
+	if m.Tenant != nil {
+		o.key(`"tenant":`)
+		writeCanonicalString(w, *m.Tenant)
+	}
+	if m.TxID != nil {
+		o.key(`"tx_id":`)
+		writeCanonicalString(w, *m.TxID)
+	}
+	return o.end()
+}
+
+func writeCanonicalChange(w canonicalWriter, ch *types.Change) error {
+	o := beginCanonicalObject(w)
+	o.key(`"action":`)
+	writeCanonicalString(w, ch.Action)
+	o.key(`"model":`)
+	writeCanonicalString(w, ch.Model)
+	if len(ch.Sets) > 0 {
+		o.key(`"sets":`)
+		w.WriteByte('[')
+		for i := range ch.Sets {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			kv := beginCanonicalObject(w)
+			kv.key(`"field":`)
+			writeCanonicalString(w, ch.Sets[i].Field)
+			kv.key(`"value":`)
+			if err := writeCanonicalAny(w, ch.Sets[i].Value); err != nil {
+				return err
+			}
+			kv.end()
+		}
+		w.WriteByte(']')
+	}
+	if ch.Where != nil {
+		o.key(`"where":`)
+		if err := writeCanonicalFilter(w, ch.Where); err != nil {
+			return err
+		}
+	}
+	return o.end()
+}
+
+func writeCanonicalStrings(w canonicalWriter, ss []string) {
+	if ss == nil {
+		w.WriteString("null")
+		return
+	}
+	w.WriteByte('[')
+	for i, s := range ss {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		writeCanonicalString(w, s)
+	}
+	w.WriteByte(']')
+}
+
+// writeCanonicalInt writes n as the double it becomes in JSON.
+func writeCanonicalInt(w canonicalWriter, n int) {
+	const maxExact = 1 << 53
+	if n > -maxExact && n < maxExact {
+		w.WriteString(strconv.Itoa(n))
+		return
+	}
+	w.WriteString(canonicalFloat(float64(n)))
+}
+
+// writeCanonicalAny writes a condition or KV value. The usual scalars are
+// written directly; anything else goes through writeCanonicalValue.
+func writeCanonicalAny(w canonicalWriter, v any) error {
+	switch val := v.(type) {
+	case nil:
+		w.WriteString("null")
+	case string:
+		writeCanonicalString(w, val)
+	case bool:
+		w.WriteString(strconv.FormatBool(val))
+	case int:
+		writeCanonicalInt(w, val)
+	case float64:
+		if math.IsNaN(val) || math.IsInf(val, 0) {
+			return fmt.Errorf("canonicalize: unsupported value %v", val)
+		}
+		w.WriteString(canonicalFloat(val))
+	case json.Number:
+		num, err := canonicalNumber(val)
+		if err != nil {
+			return err
+		}
+		w.WriteString(num)
+	default:
+		return writeCanonicalValue(w, reflect.ValueOf(v))
+	}
+	return nil
+}
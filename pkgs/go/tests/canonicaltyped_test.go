@@ -0,0 +1,227 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// fullStatement sets every field of a statement and of the types it holds.
+func fullStatement() *types.Statement {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+	yes, no := true, false
+	filter := &types.Filter{
+		And: &[]types.Filter{{Conditions: &[]types.Condition{types.Eq("a", 1.5)}}},
+		Or:  &[]types.Filter{{Not: &types.Filter{Conditions: &[]types.Condition{}}}},
+		Not: &types.Filter{Conditions: &[]types.Condition{{
+			Field: "meta", FieldPath: []string{"tags", "0"}, Op: "eq", Value: map[string]any{"z": nil, "a": []any{"x"}},
+		}}},
+		Conditions: &[]types.Condition{
+			{Field: "authorId", Op: "inQuery", Subquery: &types.Query{Model: "users", Fields: &[]string{"id"}}},
+			{Field: "big", Op: "gt", Value: 1 << 60},
+			{Field: "flag", Op: "eq", Value: false},
+			{Field: "n", Op: "eq", Value: json.Number("1.0E2")},
+		},
+	}
+	return &types.Statement{
+		Query: &types.Query{
+			Model:        "posts",
+			Fields:       &[]string{"id", "title"},
+			Where:        filter,
+			OrderBy:      &[]types.OrderBy{{Field: "createdAt", Descending: &yes, NullsFirst: &no, CaseSensitive: &yes}},
+			Limit:        num(10),
+			Offset:       num(20),
+			Distinct:     &[]string{},
+			Aggregations: &[]types.Aggregate{{Func: "count"}, {Func: "sum", Field: str("views"), Alias: str("total")}},
+			Joins:        &[]types.Join{{Relation: "author", Kind: "inner", On: filter, Fields: &[]string{"name"}}},
+			Windows: &[]types.Window{{
+				Func:        "sum",
+				Field:       str("views"),
+				PartitionBy: &[]string{"authorId"},
+				OrderBy:     &[]types.OrderBy{{Field: "createdAt", Descending: &yes}},
+				Frame:       &types.WindowFrame{Unit: "rows", Start: num(-2), End: num(0)},
+				Alias:       "running",
+			}},
+			DistinctOn: &types.DistinctOn{Fields: []string{"authorId"}, OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "views", Descending: &no}}},
+			Deleted:    str("include"),
+		},
+		Pagination: &types.Pagination{First: num(5), Last: num(6), After: str("a"), Before: str("b")},
+		GroupBy:    &[]string{"status"},
+		Having:     &types.Filter{Conditions: &[]types.Condition{types.Gt("count", 1)}},
+		Includes: []types.Include{{
+			Query:    &types.Query{Model: "comments", OrderBy: &[]types.OrderBy{}},
+			Kind:     str("some"),
+			Includes: []types.Include{{Query: &types.Query{Model: "author"}}},
+		}, {
+			Query: &types.Query{Model: "posts"},
+			Kind:  str("count"),
+			Count: &types.RelationCount{Op: "gt", Value: 5},
+		}},
+		ORMVersion:  str("prisma@5"),
+		SDKVersion:  str("0.1.0"),
+		SpecVersion: str("0.1.0"),
+		Tenant:      str("acme"),
+		Policy:      &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+	}
+}
+
+func TestCanonicalizeQueryShapeCoversAllFields(t *testing.T) {
+	// The typed encoders list fields by hand; a field added to one of these
+	// types must be added to its encoder and to fullStatement.
+	for typ, n := range map[reflect.Type]int{
+		reflect.TypeOf(types.Statement{}):     10,
+		reflect.TypeOf(types.Query{}):         12,
+		reflect.TypeOf(types.Aggregate{}):     3,
+		reflect.TypeOf(types.Join{}):          4,
+		reflect.TypeOf(types.Window{}):        6,
+		reflect.TypeOf(types.WindowFrame{}):   3,
+		reflect.TypeOf(types.DistinctOn{}):    2,
+		reflect.TypeOf(types.Include{}):       4,
+		reflect.TypeOf(types.RelationCount{}): 2,
+		reflect.TypeOf(types.Filter{}):        4,
+		reflect.TypeOf(types.Condition{}):     5,
+		reflect.TypeOf(types.OrderBy{}):       4,
+		reflect.TypeOf(types.Pagination{}):    4,
+		reflect.TypeOf(types.Mutation{}):      3,
+		reflect.TypeOf(types.Change{}):        4,
+		reflect.TypeOf(types.KV{}):            2,
+	} {
+		if typ.NumField() != n {
+			t.Errorf("%s has %d fields, the canonical encoder knows %d", typ, typ.NumField(), n)
+		}
+	}
+
+	stmt := fullStatement()
+	want, err := tests.Canonicalize(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := tests.CanonicalizeQueryShape(stmt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var streamed strings.Builder
+	if err := tests.WriteCanonical(&streamed, stmt); err != nil {
+		t.Fatal(err)
+	}
+	if streamed.String() != want {
+		t.Errorf("WriteCanonical() = %s\nwant %s", streamed.String(), want)
+	}
+	// Shapes leave out the diagnostic fields and AND the policy into the
+	// root where
+	shape := stmt.Clone()
+	shape.ORMVersion, shape.SDKVersion, shape.SpecVersion = nil, nil, nil
+	shape.Query.Where = &types.Filter{And: &[]types.Filter{*stmt.Query.Where, *stmt.Policy}}
+	shape.Policy = nil
+	if want, err = tests.Canonicalize(shape); err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("CanonicalizeQueryShape() = %s\nwant %s", got, want)
+	}
+
+	m := &types.Mutation{TxID: new(string), Tenant: stmt.Tenant, Changes: []types.Change{
+		{Model: "Post", Action: "update", Sets: []types.KV{{Field: "title", Value: "x"}, {Field: "n", Value: nil}}, Where: stmt.Query.Where},
+		{Model: "Post", Action: "delete"},
+	}}
+	for _, m := range []*types.Mutation{m, {}} {
+		want, err := tests.Canonicalize(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := tests.CanonicalizeMutation(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("CanonicalizeMutation() = %s\nwant %s", got, want)
+		}
+	}
+}
+
+func TestComputeQueryShapeIDAllocations(t *testing.T) {
+	// Hashing allocates the hash, its buffer and the ID, however large the
+	// statement; scalar condition values cost nothing.
+	allocs := func(n int) float64 {
+		conds := make([]types.Condition, n)
+		for i := range conds {
+			conds[i] = types.Eq("title", "hello")
+			if i%2 == 1 {
+				conds[i] = types.Eq("id", 42)
+			}
+		}
+		stmt := fullStatement()
+		stmt.Query.Where = &types.Filter{And: &[]types.Filter{{Conditions: &conds}}}
+		stmt.Query.Joins = nil
+		return testing.AllocsPerRun(20, func() {
+			if _, err := tests.ComputeQueryShapeID(stmt); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+	if small, large := allocs(1), allocs(1000); large != small {
+		t.Errorf("ComputeQueryShapeID made %v allocations for 1000 conditions, %v for 1", large, small)
+	}
+}
+
+func FuzzCanonicalizeQueryShape(f *testing.F) {
+	f.Add([]byte(`{"query":{"model":"posts","fields":["id"],"where":{"and":[{"conditions":[{"field":"n","op":"gt","value":1.5}]}],"not":{"conditions":[]}},"order_by":[{"field":"id","descending":true}],"limit":10,"aggregations":[{"func":"count"}],"joins":[{"relation":"author","kind":"inner"}]},"pagination":{"first":5,"after":"a"},"group_by":["status"]}`))
+	f.Add([]byte(`{"query":{"model":"posts","where":{"conditions":[{"field":"t","op":"eq","value":"é😀"}]}}}`))
+	f.Add([]byte(`{"query":{"model":"p","order_by":null,"limit":-0},"includes":[]}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var stmt types.Statement
+		if json.Unmarshal(data, &stmt) != nil {
+			return
+		}
+		want, wantErr := tests.Canonicalize(&stmt)
+		got, err := tests.CanonicalizeQueryShape(&stmt)
+		if (err != nil) != (wantErr != nil) || got != want {
+			t.Errorf("CanonicalizeQueryShape() = %s, %v\nCanonicalize() = %s, %v", got, err, want, wantErr)
+		}
+	})
+}
+
+func FuzzCanonicalizeMutation(f *testing.F) {
+	f.Add([]byte(`{"tx_id":"t1","changes":[{"model":"Post","action":"update","sets":[{"field":"n","value":1e21}],"where":{"conditions":[{"field":"id","op":"eq","value":"p1"}]}}]}`))
+	f.Add([]byte(`{"changes":null}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		var m types.Mutation
+		if json.Unmarshal(data, &m) != nil {
+			return
+		}
+		want, wantErr := tests.Canonicalize(&m)
+		got, err := tests.CanonicalizeMutation(&m)
+		if (err != nil) != (wantErr != nil) || got != want {
+			t.Errorf("CanonicalizeMutation() = %s, %v\nCanonicalize() = %s, %v", got, err, want, wantErr)
+		}
+	})
+}
+
+func BenchmarkCanonicalizeQueryShape(b *testing.B) {
+	stmt := largeStatement(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := tests.CanonicalizeQueryShape(stmt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkCanonicalizeQueryShapeGeneric canonicalizes the same statement
+// through the marshal, decode and re-serialize path, for comparison.
+func BenchmarkCanonicalizeQueryShapeGeneric(b *testing.B) {
+	stmt := largeStatement(5000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := tests.Canonicalize(stmt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tests
+
+// ErrorCode is a stable, machine-readable identifier for the constraint a
+// ValidationError reports, so SDKs can branch on the category of an error
+// or localize its message. Messages and paths may differ between
+// implementations, but every implementation rejects a document with the same
+// code (see tools/tests/vectors/invalid-shapes.json).
+//
+// Codes are IK followed by four digits and a name. The digits never change
+// meaning and are never reused: 10xx cover statements and filters, 11xx
+// mutations, 12xx dependencies, 13xx strict mode and limits, and 14xx
+// resolution against an application schema.
+type ErrorCode string
+
+// Error codes reported by the validators, the Decode functions, Split and
+// schema resolution (ValidateFieldPath, mock.ResolveIncludes).
+const (
+	CodeInvalidJSON      ErrorCode = "IK1000_INVALID_JSON"       // the document does not decode
+	CodeEmptyModel       ErrorCode = "IK1001_EMPTY_MODEL"        // a query or change has an empty model
+	CodeEmptyField       ErrorCode = "IK1002_EMPTY_FIELD"        // a field name or path segment is empty
+	CodeInvalidType      ErrorCode = "IK1003_INVALID_TYPE"       // a value has the wrong JSON type
+	CodeRequired         ErrorCode = "IK1004_REQUIRED"           // a required member is missing
+	CodeInvalidOperator  ErrorCode = "IK1005_INVALID_OPERATOR"   // a condition has an unknown operator
+	CodeInvalidValue     ErrorCode = "IK1006_INVALID_VALUE"      // a condition value has the wrong shape for its operator
+	CodeOutOfRange       ErrorCode = "IK1007_OUT_OF_RANGE"       // a limit, offset, first, last, ttl or count is out of range
+	CodeInvalidAggregate ErrorCode = "IK1008_INVALID_AGGREGATE"  // an aggregation is malformed or duplicates a column
+	CodeInvalidKind      ErrorCode = "IK1009_INVALID_KIND"       // an include kind is unknown, or count is set without kind count
+	CodeMixedPagination  ErrorCode = "IK1010_MIXED_PAGINATION"   // forward and backward pagination are combined
+	CodeInvalidJoin      ErrorCode = "IK1011_INVALID_JOIN"       // a join has no relation, a kind other than inner or left, or repeats a relation
+	CodeInvalidSubquery  ErrorCode = "IK1012_INVALID_SUBQUERY"   // a subquery selects other than one field, or its operator takes none
+	CodeInvalidFieldPath ErrorCode = "IK1013_INVALID_FIELD_PATH" // a field_path descends into an aggregate result, which holds no JSON
+	CodeInvalidWindow    ErrorCode = "IK1014_INVALID_WINDOW"     // a window has an unknown func or frame unit, lacks what its func needs, or repeats a column
+	CodeInvalidDistinct  ErrorCode = "IK1015_INVALID_DISTINCT"   // distinct_on repeats a field, its order_by does not start with its fields, or distinct is also set
+	CodeInvalidDeleted   ErrorCode = "IK1016_INVALID_DELETED"    // a query's deleted is other than include or only
+
+	CodeInvalidAction   ErrorCode = "IK1101_INVALID_ACTION"   // a change action is not insert, update or delete
+	CodeMissingSet      ErrorCode = "IK1102_MISSING_SET"      // an insert or update has no sets
+	CodeUnexpectedSet   ErrorCode = "IK1103_UNEXPECTED_SET"   // a delete has sets
+	CodeMissingWhere    ErrorCode = "IK1104_MISSING_WHERE"    // an update or delete has no where
+	CodeUnexpectedWhere ErrorCode = "IK1105_UNEXPECTED_WHERE" // an insert has a where
+
+	CodeInvalidShapeID     ErrorCode = "IK1201_INVALID_SHAPE_ID"     // a shape ID does not match ^s_[0-9a-f]{64}$
+	CodeInvalidSpecVersion ErrorCode = "IK1202_INVALID_SPEC_VERSION" // a spec version is malformed or newer than supported
+	CodeInvalidDateTime    ErrorCode = "IK1203_INVALID_DATE_TIME"    // a timestamp is not RFC 3339
+
+	CodeUnknownField   ErrorCode = "IK1301_UNKNOWN_FIELD"   // strict mode: an unknown or extension field
+	CodeCustomOperator ErrorCode = "IK1302_CUSTOM_OPERATOR" // strict mode: a custom: operator
+	CodeLimitExceeded  ErrorCode = "IK1303_LIMIT_EXCEEDED"  // the document is over a limit of its limits.Guard
+
+	CodeUnknownModel    ErrorCode = "IK1401_UNKNOWN_MODEL"    // a model is not in the schema
+	CodeUnknownRelation ErrorCode = "IK1402_UNKNOWN_RELATION" // an include names no relation of its parent model
+	CodeUnresolvedField ErrorCode = "IK1403_UNRESOLVED_FIELD" // a field or field_path does not resolve against the schema
+)
@@ -0,0 +1,423 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// ConformanceTarget is an implementation of the Universal Format under test
+// by RunConformance. Documents reach it as the JSON text of the vectors, so
+// implementations in other languages can be driven through a subprocess or
+// FFI. Vectors that need a nil callback are skipped.
+type ConformanceTarget struct {
+	// VectorsDir holds the vector files. If empty, RunConformance looks for
+	// tools/tests/vectors in the working directory and its parents, which
+	// finds them from anywhere in a checkout of the spec repository.
+	VectorsDir string
+
+	// Canonicalize returns the JCS canonical form of a JSON document.
+	Canonicalize func(doc []byte) (string, error)
+	// ComputeShapeID returns the shape ID of a statement document.
+	ComputeShapeID func(statement []byte) (string, error)
+	// ComputeStructuralShapeID returns the structural shape ID of a statement
+	// document (see ComputeStructuralShapeID).
+	ComputeStructuralShapeID func(statement []byte) (string, error)
+	// ComputeMutationID returns the mutation ID of a mutation document.
+	ComputeMutationID func(mutation []byte) (string, error)
+	// Validate decodes and validates a document of kind "statement",
+	// "mutation", "transaction" or "dependencies", with the Strict rules if
+	// strict is set. It returns nil for a valid document, else an error that
+	// errors.As resolves to a *ValidationError carrying the ErrorCode.
+	Validate func(kind string, doc []byte, strict bool) error
+	// RecordID encodes a record key as RecordID does in types.
+	RecordID func(key []any) (string, error)
+	// Cursor builds the cursor of a row under an orderBy, a JSON array of
+	// OrderBy, with keys in orderBy order (see cursors.Encode).
+	Cursor func(orderBy, row []byte) (string, error)
+	// CrossesBoundary reports whether a change must invalidate the page whose
+	// last row is lastRow, a PaginationBoundary (see
+	// invalidate.CrossesBoundary).
+	CrossesBoundary func(lastRow, change []byte) (bool, error)
+}
+
+// RunConformance runs the shared test vectors against target as subtests of
+// t, one per vector file and vector, so each failure names the vector it
+// came from:
+//
+//	func TestConformance(t *testing.T) {
+//	    tests.RunConformance(t, tests.ConformanceTarget{
+//	        Canonicalize:   myimpl.Canonicalize,
+//	        ComputeShapeID: myimpl.ShapeID,
+//	        Validate:       myimpl.Validate,
+//	    })
+//	}
+//
+// It fails t if the vector files cannot be found or read.
+func RunConformance(t *testing.T, target ConformanceTarget) {
+	t.Helper()
+	dir := target.VectorsDir
+	if dir == "" {
+		var err error
+		if dir, err = findVectorsDir(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r := conformanceRunner{target: target, dir: dir}
+
+	t.Run("query-shapes", r.queryShapes)
+	t.Run("mutations", r.mutations)
+	t.Run("dependencies", r.dependencies)
+	t.Run("invalid-shapes", r.invalidShapes)
+	t.Run("jcs", r.jcs)
+	t.Run("record-ids", r.recordIDs)
+	t.Run("pagination-cursors", r.paginationCursors)
+}
+
+// findVectorsDir looks for tools/tests/vectors from the working directory
+// up.
+func findVectorsDir() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		candidate := filepath.Join(dir, "tools", "tests", "vectors")
+		if info, err := os.Stat(candidate); err == nil && info.IsDir() {
+			return candidate, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", errors.New("conformance: tools/tests/vectors not found; set ConformanceTarget.VectorsDir")
+		}
+		dir = parent
+	}
+}
+
+type conformanceRunner struct {
+	target ConformanceTarget
+	dir    string
+}
+
+func (r conformanceRunner) load(t *testing.T, file string, v any) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(r.dir, file))
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Failed to parse %s: %v", file, err)
+	}
+}
+
+// check calls fn on doc, if the target supplies it, and compares the result
+// with want.
+func check(t *testing.T, what string, fn func([]byte) (string, error), doc []byte, want string) {
+	t.Helper()
+	if fn == nil {
+		return
+	}
+	got, err := fn(doc)
+	if err != nil {
+		t.Errorf("%s failed: %v", what, err)
+		return
+	}
+	if got != want {
+		t.Errorf("%s mismatch:\n  got:  %s\n  want: %s", what, got, want)
+	}
+}
+
+// withoutDiagnosticKeys returns doc, an object of the definition def,
+// without the properties specDiagnostics lists for def.
+func withoutDiagnosticKeys(t *testing.T, def string, doc json.RawMessage) json.RawMessage {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &obj); err != nil {
+		t.Fatalf("Failed to decode %s: %v", def, err)
+	}
+	for _, field := range specDiagnostics[def] {
+		delete(obj, field)
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Failed to encode %s: %v", def, err)
+	}
+	return out
+}
+
+// withPolicyMergedKeys returns the statement doc with its policy ANDed into
+// the root where, as shapes hash it.
+func withPolicyMergedKeys(t *testing.T, doc json.RawMessage) json.RawMessage {
+	t.Helper()
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(doc, &obj); err != nil {
+		t.Fatalf("Failed to decode Statement: %v", err)
+	}
+	policy, ok := obj["policy"]
+	if !ok || string(policy) == "null" {
+		return doc
+	}
+	var query map[string]json.RawMessage
+	if err := json.Unmarshal(obj["query"], &query); err != nil || query == nil {
+		return doc
+	}
+	if where, ok := query["where"]; ok {
+		query["where"] = json.RawMessage(`{"and":[` + string(where) + `,` + string(policy) + `]}`)
+	} else {
+		query["where"] = policy
+	}
+	delete(obj, "policy")
+	var err error
+	if obj["query"], err = json.Marshal(query); err != nil {
+		t.Fatalf("Failed to encode Statement: %v", err)
+	}
+	out, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("Failed to encode Statement: %v", err)
+	}
+	return out
+}
+
+func (r conformanceRunner) validate(t *testing.T, kind string, doc []byte) {
+	t.Helper()
+	if r.target.Validate == nil {
+		return
+	}
+	if err := r.target.Validate(kind, doc, false); err != nil {
+		t.Errorf("Validation failed: %v", err)
+	}
+}
+
+func (r conformanceRunner) queryShapes(t *testing.T) {
+	var vectors []struct {
+		Name                      string          `json:"name"`
+		Shape                     json.RawMessage `json:"shape"`
+		ExpectedCanonical         string          `json:"expectedCanonical"`
+		ExpectedShapeID           string          `json:"expectedShapeId"`
+		ExpectedStructuralShapeID string          `json:"expectedStructuralShapeId"`
+	}
+	r.load(t, "query-shapes.json", &vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			r.validate(t, "statement", v.Shape)
+			// expectedCanonical is the shape's, which leaves out the
+			// diagnostic fields and ANDs the policy into the root where;
+			// the shape IDs are computed from the statement as sent
+			check(t, "Canonical JSON", r.target.Canonicalize, withPolicyMergedKeys(t, withoutDiagnosticKeys(t, "Statement", v.Shape)), v.ExpectedCanonical)
+			check(t, "Shape ID", r.target.ComputeShapeID, v.Shape, v.ExpectedShapeID)
+			check(t, "Structural shape ID", r.target.ComputeStructuralShapeID, v.Shape, v.ExpectedStructuralShapeID)
+		})
+	}
+}
+
+func (r conformanceRunner) mutations(t *testing.T) {
+	var vectors []struct {
+		Name               string          `json:"name"`
+		Mutation           json.RawMessage `json:"mutation"`
+		ExpectedCanonical  string          `json:"expectedCanonical"`
+		ExpectedMutationID string          `json:"expectedMutationId"`
+	}
+	r.load(t, "mutations.json", &vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			r.validate(t, "mutation", v.Mutation)
+			check(t, "Canonical JSON", r.target.Canonicalize, v.Mutation, v.ExpectedCanonical)
+			check(t, "Mutation ID", r.target.ComputeMutationID, v.Mutation, v.ExpectedMutationID)
+		})
+	}
+}
+
+func (r conformanceRunner) dependencies(t *testing.T) {
+	var vectors []struct {
+		Name              string          `json:"name"`
+		Shape             json.RawMessage `json:"shape"`
+		Dependencies      json.RawMessage `json:"dependencies"`
+		ExpectedCanonical string          `json:"expectedCanonical"`
+	}
+	r.load(t, "dependencies.json", &vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			r.validate(t, "dependencies", v.Dependencies)
+			if len(v.Shape) > 0 && string(v.Shape) != "null" {
+				var deps struct {
+					ShapeID string `json:"shape_id"`
+				}
+				if err := json.Unmarshal(v.Dependencies, &deps); err != nil {
+					t.Fatalf("Failed to parse dependencies: %v", err)
+				}
+				check(t, "Shape ID", r.target.ComputeShapeID, v.Shape, deps.ShapeID)
+			}
+			check(t, "Canonical JSON", r.target.Canonicalize, v.Dependencies, v.ExpectedCanonical)
+		})
+	}
+}
+
+func (r conformanceRunner) invalidShapes(t *testing.T) {
+	var vectors []struct {
+		Name         string          `json:"name"`
+		Kind         string          `json:"kind"`
+		Strict       bool            `json:"strict"`
+		Input        json.RawMessage `json:"input"`
+		ExpectedCode ErrorCode       `json:"expectedCode"`
+	}
+	r.load(t, "invalid-shapes.json", &vectors)
+	if r.target.Validate == nil {
+		t.Skip("target does not validate")
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			err := r.target.Validate(v.Kind, v.Input, v.Strict)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("expected a ValidationError with code %s, got: %v", v.ExpectedCode, err)
+			}
+			if verr.Code != v.ExpectedCode {
+				t.Errorf("code = %s, want %s (%v)", verr.Code, v.ExpectedCode, err)
+			}
+
+			// Strict vectors are otherwise valid.
+			if v.Strict {
+				if err := r.target.Validate(v.Kind, v.Input, false); err != nil {
+					t.Errorf("expected the vector to pass without Strict, got: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func (r conformanceRunner) jcs(t *testing.T) {
+	var vectors struct {
+		Canonicalize []struct {
+			Name     string `json:"name"`
+			Input    string `json:"input"`
+			Expected string `json:"expected"`
+		} `json:"canonicalize"`
+		Numbers []struct {
+			IEEE     string `json:"ieee"`
+			Expected string `json:"expected"`
+			Error    bool   `json:"error"`
+		} `json:"numbers"`
+	}
+	r.load(t, "jcs.json", &vectors)
+	if r.target.Canonicalize == nil {
+		t.Skip("target does not canonicalize")
+	}
+
+	for _, v := range vectors.Canonicalize {
+		t.Run(v.Name, func(t *testing.T) {
+			check(t, "Canonical JSON", r.target.Canonicalize, []byte(v.Input), v.Expected)
+		})
+	}
+
+	// Numbers are given as the shortest JSON text of their double. NaN and
+	// the infinities have none, so their vectors only apply to
+	// implementations canonicalizing native values.
+	for _, n := range vectors.Numbers {
+		t.Run("number-"+n.IEEE, func(t *testing.T) {
+			bits, err := strconv.ParseUint(n.IEEE, 16, 64)
+			if err != nil {
+				t.Fatalf("Bad IEEE hex: %v", err)
+			}
+			f := math.Float64frombits(bits)
+			if n.Error || math.IsNaN(f) || math.IsInf(f, 0) {
+				t.Skip("not representable in JSON")
+			}
+			check(t, "Canonical JSON", r.target.Canonicalize, []byte(strconv.FormatFloat(f, 'g', -1, 64)), n.Expected)
+		})
+	}
+}
+
+func (r conformanceRunner) recordIDs(t *testing.T) {
+	var vectors struct {
+		RecordIDs []struct {
+			Name     string `json:"name"`
+			Key      []any  `json:"key"`
+			Expected string `json:"expected"`
+			Error    bool   `json:"error"`
+		} `json:"recordIds"`
+	}
+	r.load(t, "record-ids.json", &vectors)
+	if r.target.RecordID == nil {
+		t.Skip("target does not encode record IDs")
+	}
+
+	for _, v := range vectors.RecordIDs {
+		t.Run(v.Name, func(t *testing.T) {
+			id, err := r.target.RecordID(v.Key)
+			if v.Error {
+				if err == nil {
+					t.Errorf("RecordID should reject %v, got %s", v.Key, id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("RecordID failed: %v", err)
+			}
+			if id != v.Expected {
+				t.Errorf("got %s, want %s", id, v.Expected)
+			}
+		})
+	}
+}
+
+func (r conformanceRunner) paginationCursors(t *testing.T) {
+	var vectors []struct {
+		Name         string          `json:"name"`
+		Row          json.RawMessage `json:"row"`
+		Dependencies json.RawMessage `json:"dependencies"`
+		Expected     string          `json:"expectedCursor"`
+		Changes      []struct {
+			Name       string          `json:"name"`
+			Change     json.RawMessage `json:"change"`
+			Invalidate bool            `json:"invalidate"`
+		} `json:"changes"`
+	}
+	r.load(t, "pagination-cursors.json", &vectors)
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			r.validate(t, "dependencies", v.Dependencies)
+			var deps struct {
+				LastRow json.RawMessage `json:"last_row"`
+			}
+			if err := json.Unmarshal(v.Dependencies, &deps); err != nil {
+				t.Fatalf("Failed to parse dependencies: %v", err)
+			}
+			var lastRow struct {
+				OrderBy json.RawMessage `json:"order_by"`
+			}
+			if err := json.Unmarshal(deps.LastRow, &lastRow); err != nil {
+				t.Fatalf("Failed to parse last_row: %v", err)
+			}
+
+			var cursor func([]byte) (string, error)
+			if r.target.Cursor != nil {
+				cursor = func(row []byte) (string, error) { return r.target.Cursor(lastRow.OrderBy, row) }
+			}
+			check(t, "Cursor", cursor, v.Row, v.Expected)
+
+			if r.target.CrossesBoundary == nil {
+				return
+			}
+			for _, c := range v.Changes {
+				t.Run(c.Name, func(t *testing.T) {
+					got, err := r.target.CrossesBoundary(deps.LastRow, c.Change)
+					if err != nil {
+						t.Fatalf("CrossesBoundary failed: %v", err)
+					}
+					if got != c.Invalidate {
+						t.Errorf("CrossesBoundary() = %v, want %v", got, c.Invalidate)
+					}
+				})
+			}
+		})
+	}
+}
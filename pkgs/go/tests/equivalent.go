@@ -0,0 +1,302 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Equivalent reports whether two statements describe the same read, and so
+// would share a cache entry once normalized. When they don't, the
+// explanation names the first differing path of the normalized forms, e.g.
+// "query.where.conditions[0].value: 3 != 4".
+//
+// Before comparing, both statements are normalized:
+//   - where and having filters as by NormalizeFilter, then the children of
+//     every AND/OR are sorted
+//   - includes, joins (by relation), windows (by alias), fields, distinct
+//     and group_by are sorted, and so are window partition_by fields; join
+//     fields and on filters are normalized like the query's
+//   - distinct_on fields are sorted, and so are the order_by entries naming
+//     them, whose directions cannot change which row is kept
+//   - condition subqueries are normalized like the outer query
+//   - the policy is ANDed into the root where, as it is for shape IDs
+//   - diagnostic fields (orm_version, sdk_version) and spec_version are
+//     ignored
+//
+// Order-sensitive parts (order_by, field_path, condition values) are
+// compared as written.
+func Equivalent(a, b *types.Statement) (bool, string) {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return true, ""
+		}
+		return false, "one statement is nil"
+	}
+
+	na, err := normalizedShape(a)
+	if err != nil {
+		return false, fmt.Sprintf("cannot normalize a: %v", err)
+	}
+	nb, err := normalizedShape(b)
+	if err != nil {
+		return false, fmt.Sprintf("cannot normalize b: %v", err)
+	}
+
+	if diff := firstDifference("", na, nb); diff != "" {
+		return false, diff
+	}
+	return true, ""
+}
+
+// normalizedShape returns the generic JSON form of the normalized statement.
+func normalizedShape(stmt *types.Statement) (interface{}, error) {
+	s := *withPolicyMerged(withoutDiagnostics(stmt))
+	s.Query = equivalentQuery(s.Query)
+	s.Having = equivalentFilter(s.Having)
+	s.Policy = equivalentFilter(s.Policy)
+	s.GroupBy = sortedStrings(s.GroupBy)
+	s.Includes = equivalentIncludes(s.Includes)
+
+	canonical, err := Canonicalize(&s)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(strings.NewReader(canonical))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func equivalentQuery(q *types.Query) *types.Query {
+	if q == nil {
+		return nil
+	}
+	out := *q
+	out.Where = equivalentFilter(q.Where)
+	out.Fields = sortedStrings(q.Fields)
+	out.Distinct = sortedStrings(q.Distinct)
+	out.Joins = equivalentJoins(q.Joins)
+	out.Windows = equivalentWindows(q.Windows)
+	out.DistinctOn = equivalentDistinctOn(q.DistinctOn)
+	return &out
+}
+
+// equivalentDistinctOn sorts the fields and, when order_by starts with them,
+// replaces those entries by the sorted fields: the rows sharing the fields
+// tie under any order of them, so only the later entries pick the kept row.
+func equivalentDistinctOn(d *types.DistinctOn) *types.DistinctOn {
+	if d == nil {
+		return nil
+	}
+	out := types.DistinctOn{Fields: slices.Clone(d.Fields), OrderBy: slices.Clone(d.OrderBy)}
+	sort.Strings(out.Fields)
+	if len(out.Fields) > len(out.OrderBy) {
+		return &out
+	}
+	prefix := make([]string, len(out.Fields))
+	for i := range prefix {
+		prefix[i] = out.OrderBy[i].Field
+	}
+	sort.Strings(prefix)
+	if slices.Equal(prefix, out.Fields) {
+		for i, f := range prefix {
+			out.OrderBy[i] = types.OrderBy{Field: f}
+		}
+	}
+	return &out
+}
+
+func equivalentWindows(windows *[]types.Window) *[]types.Window {
+	if windows == nil {
+		return nil
+	}
+	out := make([]types.Window, len(*windows))
+	for i, w := range *windows {
+		w.PartitionBy = sortedStrings(w.PartitionBy)
+		out[i] = w
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Alias < out[j].Alias
+	})
+	return &out
+}
+
+func equivalentJoins(joins *[]types.Join) *[]types.Join {
+	if joins == nil {
+		return nil
+	}
+	out := make([]types.Join, len(*joins))
+	for i, j := range *joins {
+		out[i] = types.Join{
+			Relation: j.Relation,
+			Kind:     j.Kind,
+			On:       equivalentFilter(j.On),
+			Fields:   sortedStrings(j.Fields),
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Relation < out[j].Relation
+	})
+	return &out
+}
+
+func equivalentIncludes(includes []types.Include) []types.Include {
+	if includes == nil {
+		return nil
+	}
+	out := make([]types.Include, len(includes))
+	for i, inc := range includes {
+		out[i] = types.Include{
+			Query:    equivalentQuery(inc.Query),
+			Kind:     inc.Kind,
+			Includes: equivalentIncludes(inc.Includes),
+			Count:    inc.Count,
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return sortKey(out[i]) < sortKey(out[j])
+	})
+	return out
+}
+
+func sortedStrings(s *[]string) *[]string {
+	if s == nil {
+		return nil
+	}
+	out := append([]string(nil), *s...)
+	sort.Strings(out)
+	return &out
+}
+
+// equivalentFilter returns the sorted normal form of f, or nil when f
+// matches every row.
+func equivalentFilter(f *types.Filter) *types.Filter {
+	if f == nil {
+		return nil
+	}
+	n := simplify(toBoolNode(equivalentSubqueries(*f)), true)
+	if isTrue(n) {
+		return nil
+	}
+	out := fromBoolNode(n)
+	return &out
+}
+
+// equivalentSubqueries returns a copy of f with the subquery of every
+// condition normalized, so conditions sort and deduplicate on the
+// normalized form.
+func equivalentSubqueries(f types.Filter) types.Filter {
+	var out types.Filter
+	if f.Not != nil {
+		not := equivalentSubqueries(*f.Not)
+		out.Not = &not
+	}
+	if f.Conditions != nil {
+		conds := make([]types.Condition, len(*f.Conditions))
+		for i, c := range *f.Conditions {
+			c.Subquery = equivalentQuery(c.Subquery)
+			conds[i] = c
+		}
+		out.Conditions = &conds
+	}
+	out.And = equivalentSubqueriesEach(f.And)
+	out.Or = equivalentSubqueriesEach(f.Or)
+	return out
+}
+
+func equivalentSubqueriesEach(filters *[]types.Filter) *[]types.Filter {
+	if filters == nil {
+		return nil
+	}
+	out := make([]types.Filter, len(*filters))
+	for i, f := range *filters {
+		out[i] = equivalentSubqueries(f)
+	}
+	return &out
+}
+
+// firstDifference walks two generic JSON values in key order and describes
+// the first place they differ, or returns "" when they are equal.
+func firstDifference(path string, a, b interface{}) string {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			x, inA := av[k]
+			y, inB := bv[k]
+			switch {
+			case !inA:
+				return fmt.Sprintf("%s: (absent) != %s", joinPath(path, k), describeValue(y))
+			case !inB:
+				return fmt.Sprintf("%s: %s != (absent)", joinPath(path, k), describeValue(x))
+			}
+			if diff := firstDifference(joinPath(path, k), x, y); diff != "" {
+				return diff
+			}
+		}
+		return ""
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) && i < len(bv); i++ {
+			if diff := firstDifference(fmt.Sprintf("%s[%d]", path, i), av[i], bv[i]); diff != "" {
+				return diff
+			}
+		}
+		if len(av) != len(bv) {
+			return fmt.Sprintf("%s: %d elements != %d elements", displayPath(path), len(av), len(bv))
+		}
+		return ""
+	}
+
+	ja, jb := describeValue(a), describeValue(b)
+	if ja == jb {
+		return ""
+	}
+	return fmt.Sprintf("%s: %s != %s", displayPath(path), ja, jb)
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayPath(path string) string {
+	if path == "" {
+		return "statement"
+	}
+	return path
+}
+
+func describeValue(v interface{}) string {
+	s, err := Canonicalize(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return s
+}
@@ -0,0 +1,192 @@
+package tests_test
+
+import (
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func where(f types.Filter) *types.Statement {
+	return &types.Statement{Query: &types.Query{Model: "Post", Where: &f}}
+}
+
+func conds(cs ...types.Condition) types.Filter {
+	return types.Filter{Conditions: &cs}
+}
+
+func TestEquivalent(t *testing.T) {
+	a := types.Eq("status", "published")
+	b := types.Gt("views", 10)
+	c := types.IsNull("deletedAt")
+	sdk := "1.2.3"
+	desc := true
+
+	tcs := []struct {
+		name  string
+		x, y  *types.Statement
+		equal bool
+		why   string
+	}{
+		{
+			name:  "commuted conditions",
+			x:     where(conds(a, b)),
+			y:     where(conds(b, a)),
+			equal: true,
+		},
+		{
+			name:  "nested single-element and",
+			x:     where(types.Filter{And: &[]types.Filter{{And: &[]types.Filter{conds(a)}}}}),
+			y:     where(conds(a)),
+			equal: true,
+		},
+		{
+			name:  "nested and is flattened",
+			x:     where(types.Filter{And: &[]types.Filter{conds(a), {And: &[]types.Filter{conds(b), conds(c)}}}}),
+			y:     where(conds(c, b, a)),
+			equal: true,
+		},
+		{
+			name:  "commuted or branches",
+			x:     where(types.Filter{Or: &[]types.Filter{conds(a), conds(b)}}),
+			y:     where(types.Filter{Or: &[]types.Filter{conds(b), {Or: &[]types.Filter{conds(a)}}}}),
+			equal: true,
+		},
+		{
+			name:  "double negation",
+			x:     where(types.Filter{Not: &types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{a}}}}),
+			y:     where(conds(a)),
+			equal: true,
+		},
+		{
+			name:  "duplicates and empty branches",
+			x:     where(types.Filter{Conditions: &[]types.Condition{a, a}, And: &[]types.Filter{{}}, Or: &[]types.Filter{}}),
+			y:     where(conds(a)),
+			equal: true,
+		},
+		{
+			name:  "empty filter matches everything",
+			x:     where(types.Filter{And: &[]types.Filter{{}}}),
+			y:     &types.Statement{Query: &types.Query{Model: "Post"}},
+			equal: true,
+		},
+		{
+			name:  "fields and includes are sets, diagnostics ignored",
+			x:     &types.Statement{Query: &types.Query{Model: "Post", Fields: &[]string{"id", "title"}}, Includes: []types.Include{{Query: &types.Query{Model: "author"}}, {Query: &types.Query{Model: "comments"}}}},
+			y:     &types.Statement{Query: &types.Query{Model: "Post", Fields: &[]string{"title", "id"}}, Includes: []types.Include{{Query: &types.Query{Model: "comments"}}, {Query: &types.Query{Model: "author"}}}, SDKVersion: &sdk},
+			equal: true,
+		},
+		{
+			name: "and is not or",
+			x:    where(conds(a, b)),
+			y:    where(types.Filter{Or: &[]types.Filter{conds(a), conds(b)}}),
+			why:  `query.where.conditions: [{"field":"status","op":"eq","value":"published"},{"field":"views","op":"gt","value":10}] != (absent)`,
+		},
+		{
+			name: "different value",
+			x:    where(conds(types.Gt("views", 3))),
+			y:    where(conds(types.Gt("views", 4))),
+			why:  "query.where.conditions[0].value: 3 != 4",
+		},
+		{
+			name: "order_by is ordered",
+			x:    &types.Statement{Query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "a"}, {Field: "b"}}}},
+			y:    &types.Statement{Query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "b"}, {Field: "a"}}}},
+			why:  `query.order_by[0].field: "a" != "b"`,
+		},
+		{
+			name: "missing limit",
+			x:    &types.Statement{Query: &types.Query{Model: "Post", Limit: intPtr(10)}},
+			y:    &types.Statement{Query: &types.Query{Model: "Post"}},
+			why:  "query.limit: 10 != (absent)",
+		},
+		{
+			name: "commuted joins",
+			x: &types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{
+				types.InnerJoin("author", a, b).Select("name", "email"), types.LeftJoin("category"),
+			}}},
+			y: &types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{
+				types.LeftJoin("category"), types.InnerJoin("author", b, a).Select("email", "name"),
+			}}},
+			equal: true,
+		},
+		{
+			name: "join kind",
+			x:    &types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{types.InnerJoin("author")}}},
+			y:    &types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{types.LeftJoin("author")}}},
+			why:  `query.joins[0].kind: "inner" != "left"`,
+		},
+		{
+			name: "distinct_on prefix order and direction",
+			x: &types.Statement{Query: &types.Query{Model: "posts", DistinctOn: &types.DistinctOn{
+				Fields:  []string{"authorId", "status"},
+				OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "status", Descending: &desc}, {Field: "views", Descending: &desc}},
+			}}},
+			y: &types.Statement{Query: &types.Query{Model: "posts", DistinctOn: &types.DistinctOn{
+				Fields:  []string{"status", "authorId"},
+				OrderBy: []types.OrderBy{{Field: "status"}, {Field: "authorId"}, {Field: "views", Descending: &desc}},
+			}}},
+			equal: true,
+		},
+		{
+			name: "distinct_on tie-break direction",
+			x: &types.Statement{Query: &types.Query{Model: "posts", DistinctOn: &types.DistinctOn{
+				Fields:  []string{"authorId"},
+				OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "views", Descending: &desc}},
+			}}},
+			y: &types.Statement{Query: &types.Query{Model: "posts", DistinctOn: &types.DistinctOn{
+				Fields:  []string{"authorId"},
+				OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "views"}},
+			}}},
+			why: "query.distinct_on.order_by[1].descending: true != (absent)",
+		},
+		{
+			name: "subqueries are normalized",
+			x: where(conds(types.InQuery("authorId", types.Query{
+				Model: "User", Fields: &[]string{"id"}, Where: &types.Filter{Conditions: &[]types.Condition{a, b}},
+			}))),
+			y: where(conds(types.InQuery("authorId", types.Query{
+				Model: "User", Fields: &[]string{"id"}, Where: &types.Filter{Conditions: &[]types.Condition{b, a}},
+			}))),
+			equal: true,
+		},
+		{
+			name:  "both nil",
+			equal: true,
+		},
+		{
+			name: "one nil",
+			x:    where(conds(a)),
+			why:  "one statement is nil",
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			equal, why := tests.Equivalent(tt.x, tt.y)
+			if equal != tt.equal {
+				t.Fatalf("Equivalent() = %v (%s), want %v", equal, why, tt.equal)
+			}
+			if tt.equal {
+				if why != "" {
+					t.Errorf("explanation = %q, want none", why)
+				}
+				return
+			}
+			if why != tt.why {
+				t.Errorf("explanation = %q, want %q", why, tt.why)
+			}
+		})
+	}
+}
+
+func TestEquivalentIsSymmetric(t *testing.T) {
+	x := where(types.Filter{Not: &types.Filter{Or: &[]types.Filter{conds(types.Eq("a", 1)), conds(types.Eq("b", 2))}}})
+	y := where(types.Filter{Not: &types.Filter{Or: &[]types.Filter{conds(types.Eq("b", 2)), conds(types.Eq("a", 1))}}})
+	if ok, why := tests.Equivalent(x, y); !ok {
+		t.Errorf("Equivalent(x, y) = false: %s", why)
+	}
+	if ok, why := tests.Equivalent(y, x); !ok {
+		t.Errorf("Equivalent(y, x) = false: %s", why)
+	}
+}
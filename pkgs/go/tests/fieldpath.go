@@ -0,0 +1,165 @@
+package tests
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// FieldSchema declares the type of a field so that Condition.FieldPath can be
+// checked against it.
+//
+// Type is one of "string", "number", "boolean", "object", "array" or "any".
+// Object fields list their nested fields in Fields; a nil Fields map declares
+// an open object (e.g., a JSON column) whose keys are not checked. Array
+// fields describe their elements in Items.
+type FieldSchema struct {
+	Type   string
+	Fields map[string]FieldSchema
+	Items  *FieldSchema
+}
+
+// ValidateFieldPath checks that field and the nested fieldPath segments
+// resolve against the declared model fields.
+//
+// Object segments must name a declared nested field (unless the object is
+// open), array segments must be non-negative integer indexes, and scalar
+// fields cannot be descended into. Fields of type "any" accept any path of
+// non-empty segments.
+func ValidateFieldPath(field string, fieldPath []string, fields map[string]FieldSchema) error {
+	schema, ok := fields[field]
+	if !ok {
+		return &ValidationError{Code: CodeUnresolvedField, Message: fmt.Sprintf("unknown field: %s", field), Path: "field"}
+	}
+
+	for i, segment := range fieldPath {
+		if segment == "" {
+			return &ValidationError{Code: CodeEmptyField, Message: "field_path segment must be non-empty", Path: fmt.Sprintf("field_path[%d]", i)}
+		}
+	}
+
+	for i, segment := range fieldPath {
+		path := fmt.Sprintf("field_path[%d]", i)
+		switch schema.Type {
+		case "any":
+			return nil
+		case "object":
+			if schema.Fields == nil {
+				return nil
+			}
+			next, ok := schema.Fields[segment]
+			if !ok {
+				return &ValidationError{Code: CodeUnresolvedField, Message: fmt.Sprintf("unknown nested field: %s", segment), Path: path}
+			}
+			schema = next
+		case "array":
+			if _, ok := arrayIndex(segment); !ok {
+				return &ValidationError{Code: CodeUnresolvedField, Message: fmt.Sprintf("array index must be a non-negative integer, got: %s", segment), Path: path}
+			}
+			if schema.Items == nil {
+				return nil
+			}
+			schema = *schema.Items
+		default:
+			return &ValidationError{
+				Code:    CodeUnresolvedField,
+				Message: fmt.Sprintf("cannot descend into %s field", schema.Type),
+				Path:    path,
+			}
+		}
+	}
+
+	return nil
+}
+
+// ValidateFilterFieldPaths checks the field and field_path of every
+// condition in the filter against the declared model fields.
+func ValidateFilterFieldPaths(filter *types.Filter, fields map[string]FieldSchema) error {
+	return validateFilterFieldPaths(filter, fields, "filter")
+}
+
+func validateFilterFieldPaths(filter *types.Filter, fields map[string]FieldSchema, path string) error {
+	if filter == nil {
+		return nil
+	}
+
+	if filter.And != nil {
+		for i, f := range *filter.And {
+			if err := validateFilterFieldPaths(&f, fields, fmt.Sprintf("%s.and[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	if filter.Or != nil {
+		for i, f := range *filter.Or {
+			if err := validateFilterFieldPaths(&f, fields, fmt.Sprintf("%s.or[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	if err := validateFilterFieldPaths(filter.Not, fields, path+".not"); err != nil {
+		return err
+	}
+	if filter.Conditions != nil {
+		for i, c := range *filter.Conditions {
+			if err := ValidateFieldPath(c.Field, c.FieldPath, fields); err != nil {
+				verr := err.(*ValidationError)
+				verr.Path = fmt.Sprintf("%s.conditions[%d].%s", path, i, verr.Path)
+				return verr
+			}
+		}
+	}
+
+	return nil
+}
+
+// ResolveFieldPath extracts the value of field along fieldPath from a result
+// row, with the semantics of Condition.FieldPath: each segment names a key of
+// an object, or indexes an array when it is a non-negative integer in plain
+// decimal ("0", "12"; not "01", "+1" or "-1"). Which applies depends on the
+// value reached, as with PostgreSQL's #> operator. An empty fieldPath
+// resolves field itself.
+//
+// Returns false if any segment is missing or cannot be descended into.
+func ResolveFieldPath(row map[string]any, field string, fieldPath []string) (any, bool) {
+	value, ok := row[field]
+	if !ok {
+		return nil, false
+	}
+
+	for _, segment := range fieldPath {
+		switch v := value.(type) {
+		case map[string]any:
+			value, ok = v[segment]
+			if !ok {
+				return nil, false
+			}
+		case []any:
+			idx, ok := arrayIndex(segment)
+			if !ok || idx >= len(v) {
+				return nil, false
+			}
+			value = v[idx]
+		default:
+			return nil, false
+		}
+	}
+
+	return value, true
+}
+
+// arrayIndex parses a field_path segment as an array index: a non-negative
+// integer in plain decimal, without sign or leading zeros.
+func arrayIndex(segment string) (int, bool) {
+	if segment == "" || len(segment) > 1 && segment[0] == '0' {
+		return 0, false
+	}
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return 0, false
+		}
+	}
+	idx, err := strconv.Atoi(segment)
+	return idx, err == nil
+}
@@ -0,0 +1,145 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+var userFields = map[string]tests.FieldSchema{
+	"id": {Type: "string"},
+	"address": {Type: "object", Fields: map[string]tests.FieldSchema{
+		"city": {Type: "string"},
+		"geo":  {Type: "object", Fields: map[string]tests.FieldSchema{"lat": {Type: "number"}}},
+	}},
+	"tags":     {Type: "array", Items: &tests.FieldSchema{Type: "string"}},
+	"metadata": {Type: "object"},
+	"payload":  {Type: "any"},
+}
+
+func TestValidateFieldPath(t *testing.T) {
+	tcs := []struct {
+		name      string
+		field     string
+		fieldPath []string
+		errMsg    string
+	}{
+		{name: "plain field", field: "id"},
+		{name: "nested object", field: "address", fieldPath: []string{"geo", "lat"}},
+		{name: "array index", field: "tags", fieldPath: []string{"0"}},
+		{name: "open object", field: "metadata", fieldPath: []string{"anything", "goes"}},
+		{name: "unknown field", field: "missing", errMsg: "unknown field"},
+		{name: "unknown nested field", field: "address", fieldPath: []string{"zip"}, errMsg: "unknown nested field"},
+		{name: "non-integer index", field: "tags", fieldPath: []string{"first"}, errMsg: "array index"},
+		{name: "zero-padded index", field: "tags", fieldPath: []string{"01"}, errMsg: "array index"},
+		{name: "descend into scalar", field: "id", fieldPath: []string{"x"}, errMsg: "cannot descend into string"},
+		{name: "empty segment", field: "address", fieldPath: []string{""}, errMsg: "must be non-empty"},
+		{name: "empty segment under open object", field: "metadata", fieldPath: []string{"a", ""}, errMsg: "must be non-empty"},
+		{name: "empty segment under any", field: "payload", fieldPath: []string{"a", ""}, errMsg: "must be non-empty"},
+		{name: "any field", field: "payload", fieldPath: []string{"a", "0", "b"}},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tests.ValidateFieldPath(tt.field, tt.fieldPath, userFields)
+			if (err != nil) != (tt.errMsg != "") {
+				t.Fatalf("ValidateFieldPath() error = %v, want %q", err, tt.errMsg)
+			}
+			if err != nil && !contains(err.Error(), tt.errMsg) {
+				t.Errorf("ValidateFieldPath() error = %v, want error containing %v", err, tt.errMsg)
+			}
+			if verr, ok := err.(*tests.ValidationError); ok && verr.Code == "" {
+				t.Errorf("ValidateFieldPath() error = %v has no code", err)
+			}
+		})
+	}
+}
+
+func TestValidateFilterFieldPaths(t *testing.T) {
+	filter := &types.Filter{
+		Or: &[]types.Filter{
+			{Conditions: &[]types.Condition{{Field: "address", FieldPath: []string{"city"}, Op: "eq", Value: "Oslo"}}},
+			{Conditions: &[]types.Condition{{Field: "address", FieldPath: []string{"country"}, Op: "eq", Value: "NO"}}},
+		},
+	}
+
+	err := tests.ValidateFilterFieldPaths(filter, userFields)
+	if err == nil {
+		t.Fatal("Expected unknown nested field error")
+	}
+	if !contains(err.Error(), "filter.or[1].conditions[0].field_path[0]") {
+		t.Errorf("Unexpected error path: %v", err)
+	}
+}
+
+func TestResolveFieldPath(t *testing.T) {
+	row := map[string]any{
+		"address": map[string]any{"city": "Oslo"},
+		"tags":    []any{"a", "b"},
+	}
+
+	if v, ok := tests.ResolveFieldPath(row, "address", []string{"city"}); !ok || v != "Oslo" {
+		t.Errorf("Expected Oslo, got %v (%v)", v, ok)
+	}
+	if v, ok := tests.ResolveFieldPath(row, "tags", []string{"1"}); !ok || v != "b" {
+		t.Errorf("Expected b, got %v (%v)", v, ok)
+	}
+	if _, ok := tests.ResolveFieldPath(row, "tags", []string{"5"}); ok {
+		t.Error("Out of range index should not resolve")
+	}
+	if _, ok := tests.ResolveFieldPath(row, "address", []string{"city", "name"}); ok {
+		t.Error("Scalar should not be descended into")
+	}
+	for _, segment := range []string{"01", "+1", "-0", " 1"} {
+		if _, ok := tests.ResolveFieldPath(row, "tags", []string{segment}); ok {
+			t.Errorf("Index %q should not resolve", segment)
+		}
+	}
+	numbered := map[string]any{"years": map[string]any{"2024": "leap", "01": "padded"}}
+	if v, ok := tests.ResolveFieldPath(numbered, "years", []string{"2024"}); !ok || v != "leap" {
+		t.Errorf("Expected leap, got %v (%v)", v, ok)
+	}
+	if v, ok := tests.ResolveFieldPath(numbered, "years", []string{"01"}); !ok || v != "padded" {
+		t.Errorf("Expected padded, got %v (%v)", v, ok)
+	}
+}
+
+func TestValidateQueryShapeRejectsEmptyFieldPathSegment(t *testing.T) {
+	err := tests.ValidateQueryShape(&types.Statement{
+		Query: &types.Query{
+			Model: "User",
+			Where: &types.Filter{Conditions: &[]types.Condition{
+				{Field: "address", FieldPath: []string{"city", ""}, Op: "eq", Value: "Oslo"},
+			}},
+		},
+	})
+	if err == nil || !contains(err.Error(), "field_path[1]") {
+		t.Errorf("Expected field_path error, got %v", err)
+	}
+}
+
+func TestValidateQueryShapeRejectsFieldPathOnAggregate(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{
+			Model:        "Post",
+			Aggregations: &[]types.Aggregate{types.Count(""), types.Sum("views").As("total")},
+		},
+		GroupBy: &[]string{"meta"},
+		Having: &types.Filter{Or: &[]types.Filter{
+			{Conditions: &[]types.Condition{{Field: "meta", FieldPath: []string{"lang"}, Op: "eq", Value: "en"}}},
+			{Conditions: &[]types.Condition{{Field: "total", FieldPath: []string{"value"}, Op: "gt", Value: 10}}},
+		}},
+	}
+	err := tests.ValidateQueryShape(stmt)
+	var verr *tests.ValidationError
+	if !errors.As(err, &verr) || verr.Code != tests.CodeInvalidFieldPath || verr.Path != "statement.having.or[1].conditions[0].field_path" {
+		t.Errorf("ValidateQueryShape() = %v, want an aggregate field_path error", err)
+	}
+
+	(*(*stmt.Having.Or)[1].Conditions)[0].FieldPath = nil
+	if err := tests.ValidateQueryShape(stmt); err != nil {
+		t.Errorf("ValidateQueryShape() = %v, want nil", err)
+	}
+}
@@ -0,0 +1,538 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// ErrNotEvaluable is matched by FilterMatches errors for conditions a row
+// alone cannot decide: inQuery and existsQuery subqueries and custom:
+// operators.
+var ErrNotEvaluable = errors.New("condition cannot be evaluated against a row")
+
+// maxPinnedRows bounds the rows FilterIntersectsChange expands in lists
+// into; past it, further fields are left unpinned.
+const maxPinnedRows = 64
+
+// FilterMatches evaluates filter against a row, with the semantics of every
+// spec operator. Values are compared as JSON values, so 7 and 7.0 are equal
+// and time.Time matches its RFC 3339 form. As in SQL, a null field matches
+// no comparison; only isNull and exists test for it. A field_path that does
+// not resolve (see ResolveFieldPath) is missing, as an absent field is, and
+// jsonType never matches it. Geospatial operators measure great-circle
+// distance for withinRadius and treat polygon edges and bbox sides as
+// straight in latitude and longitude.
+//
+// Returns an error wrapping ErrNotEvaluable for subqueries and custom:
+// operators, and an error for condition values an operator cannot take
+// (e.g., in without an array, an invalid regex).
+func FilterMatches(filter types.Filter, row map[string]any) (bool, error) {
+	result, err := evaluator{}.filter(&filter, row)
+	return result == yes, err
+}
+
+// FilterIntersectsChange reports whether change may touch a row filter
+// selects, before or after the change: an insert whose row may satisfy
+// filter, a delete whose where may select rows that do, or an update whose
+// where may select such rows or whose values may move rows into filter.
+//
+// Only what the change pins is known: the values an insert or update sets
+// and those its where requires with eq and in. Conditions on anything else,
+// and those FilterMatches cannot evaluate, may match, so false means the
+// change is disjoint from filter.
+func FilterIntersectsChange(filter types.Filter, change types.Change) bool {
+	e := evaluator{partial: true}
+	sets := make(map[string]any, len(change.Sets))
+	for _, kv := range change.Sets {
+		sets[kv.Field] = kv.Value
+	}
+	if change.Action == "insert" {
+		result, _ := e.filter(&filter, sets)
+		return result != no
+	}
+
+	for _, row := range pinnedRows(change.Where) {
+		if result, _ := e.filter(&filter, row); result != no {
+			return true
+		}
+		if change.Action == "delete" {
+			continue
+		}
+		for k, v := range sets {
+			row[k] = v
+		}
+		if result, _ := e.filter(&filter, row); result != no {
+			return true
+		}
+	}
+	return false
+}
+
+// tri is a three-valued truth value, ordered so that and is min and or is
+// max.
+type tri int8
+
+const (
+	no tri = iota
+	maybe
+	yes
+)
+
+func and(a, b tri) tri {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func or(a, b tri) tri {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func not(a tri) tri {
+	return yes - a
+}
+
+func truth(b bool) tri {
+	if b {
+		return yes
+	}
+	return no
+}
+
+// evaluator evaluates filters against complete rows or, when partial,
+// against rows that hold only the fields known so far: conditions on other
+// fields, and those it cannot evaluate, are maybe rather than errors.
+type evaluator struct {
+	partial bool
+}
+
+func (e evaluator) filter(f *types.Filter, row map[string]any) (tri, error) {
+	if f == nil {
+		return yes, nil
+	}
+	result := yes
+	for i := range f.GetConditions() {
+		t, err := e.condition(&(*f.Conditions)[i], row)
+		if result = and(result, t); err != nil || result == no {
+			return no, err
+		}
+	}
+	for i := range f.GetAnd() {
+		t, err := e.filter(&(*f.And)[i], row)
+		if result = and(result, t); err != nil || result == no {
+			return no, err
+		}
+	}
+	if branches := f.GetOr(); len(branches) > 0 {
+		some := no
+		for i := 0; i < len(branches) && some != yes; i++ {
+			t, err := e.filter(&branches[i], row)
+			if err != nil {
+				return no, err
+			}
+			some = or(some, t)
+		}
+		if result = and(result, some); result == no {
+			return no, nil
+		}
+	}
+	if f.Not != nil {
+		t, err := e.filter(f.Not, row)
+		if err != nil {
+			return no, err
+		}
+		result = and(result, not(t))
+	}
+	return result, nil
+}
+
+func (e evaluator) condition(c *types.Condition, row map[string]any) (tri, error) {
+	raw, exists := ResolveFieldPath(row, c.Field, c.FieldPath)
+	result, err := e.decide(c, raw, exists)
+	if err != nil && e.partial {
+		return maybe, nil
+	}
+	if !exists && e.partial {
+		return maybe, nil
+	}
+	return result, err
+}
+
+func (e evaluator) decide(c *types.Condition, raw any, exists bool) (tri, error) {
+	if c.Subquery != nil || c.Op == "inQuery" || c.Op == "existsQuery" || strings.HasPrefix(c.Op, "custom:") {
+		return no, fmt.Errorf("%s on %s: %w", c.Op, c.Field, ErrNotEvaluable)
+	}
+	value, err := jsonValue(raw)
+	if err != nil {
+		return no, err
+	}
+	want, err := jsonValue(c.Value)
+	if err != nil {
+		return no, err
+	}
+
+	switch c.Op {
+	case "eq", "ne":
+		if value == nil {
+			return no, nil
+		}
+		eq := e.equal(value, want)
+		if c.Op == "ne" {
+			return not(eq), nil
+		}
+		return eq, nil
+	case "in", "notIn":
+		list, ok := want.([]any)
+		if !ok {
+			return no, fmt.Errorf("%s requires an array value", c.Op)
+		}
+		if value == nil {
+			return no, nil
+		}
+		found := no
+		for _, w := range list {
+			found = or(found, e.equal(value, w))
+		}
+		if c.Op == "notIn" {
+			return not(found), nil
+		}
+		return found, nil
+	case "isNull":
+		if b, ok := want.(bool); ok && !b {
+			return truth(value != nil), nil
+		}
+		return truth(value == nil), nil
+	case "exists":
+		if b, ok := want.(bool); ok && !b {
+			return truth(!exists), nil
+		}
+		return truth(exists), nil
+	case "gt", "gte", "lt", "lte":
+		cmp, ok := compareValues(value, want)
+		if !ok {
+			return no, nil
+		}
+		switch c.Op {
+		case "gt":
+			return truth(cmp > 0), nil
+		case "gte":
+			return truth(cmp >= 0), nil
+		case "lt":
+			return truth(cmp < 0), nil
+		}
+		return truth(cmp <= 0), nil
+	case "between":
+		bounds, ok := want.([]any)
+		if !ok || len(bounds) != 2 {
+			return no, fmt.Errorf("between requires a [lo, hi] value")
+		}
+		lo, okLo := compareValues(value, bounds[0])
+		hi, okHi := compareValues(value, bounds[1])
+		return truth(okLo && okHi && lo >= 0 && hi <= 0), nil
+	case "contains", "startsWith", "endsWith":
+		s, ok1 := value.(string)
+		sub, ok2 := want.(string)
+		if !ok1 || !ok2 {
+			return no, nil
+		}
+		switch c.Op {
+		case "contains":
+			return truth(strings.Contains(s, sub)), nil
+		case "startsWith":
+			return truth(strings.HasPrefix(s, sub)), nil
+		}
+		return truth(strings.HasSuffix(s, sub)), nil
+	case "like", "ilike", "regex":
+		s, ok1 := value.(string)
+		pattern, ok2 := want.(string)
+		if !ok1 || !ok2 {
+			return no, nil
+		}
+		if c.Op != "regex" {
+			pattern = likePattern(pattern, c.Op == "ilike")
+		}
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return no, fmt.Errorf("invalid %s pattern: %w", c.Op, err)
+		}
+		return truth(re.MatchString(s)), nil
+	case "has", "hasSome", "hasEvery":
+		list, ok := value.([]any)
+		if !ok {
+			return no, nil
+		}
+		if c.Op == "has" {
+			return e.contains(list, want), nil
+		}
+		wants, ok := want.([]any)
+		if !ok {
+			return no, fmt.Errorf("%s requires an array value", c.Op)
+		}
+		result := truth(c.Op == "hasEvery")
+		for _, w := range wants {
+			if c.Op == "hasSome" {
+				result = or(result, e.contains(list, w))
+			} else {
+				result = and(result, e.contains(list, w))
+			}
+		}
+		return result, nil
+	case "jsonContains":
+		return truth(jsonContains(value, want)), nil
+	case "jsonHasKey":
+		key, ok := want.(string)
+		if !ok {
+			return no, fmt.Errorf("jsonHasKey requires a string value")
+		}
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return no, nil
+		}
+		_, has := obj[key]
+		return truth(has), nil
+	case "jsonType":
+		name, ok := want.(string)
+		if !ok || !slices.Contains(jsonTypes, name) {
+			return no, fmt.Errorf("jsonType requires one of %s", strings.Join(jsonTypes, ", "))
+		}
+		return truth(exists && jsonTypeOf(value) == name), nil
+	case "withinRadius", "intersects", "bbox":
+		return decideGeo(c.Op, value, want)
+	case "lenEq", "lenGt", "lenLt":
+		n, ok := want.(float64)
+		if !ok {
+			return no, fmt.Errorf("%s requires a number value", c.Op)
+		}
+		var length int
+		switch v := value.(type) {
+		case string:
+			length = len([]rune(v))
+		case []any:
+			length = len(v)
+		default:
+			return no, nil
+		}
+		switch c.Op {
+		case "lenEq":
+			return truth(float64(length) == n), nil
+		case "lenGt":
+			return truth(float64(length) > n), nil
+		}
+		return truth(float64(length) < n), nil
+	}
+	return no, fmt.Errorf("operator %s is not supported", c.Op)
+}
+
+// equal compares two JSON values. Against partial rows, values of different
+// kinds compare as maybe, since an engine may coerce one into the other.
+func (e evaluator) equal(a, b any) tri {
+	if reflect.DeepEqual(a, b) {
+		return yes
+	}
+	if e.partial && reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return maybe
+	}
+	return no
+}
+
+func (e evaluator) contains(list []any, v any) tri {
+	found := no
+	for _, item := range list {
+		found = or(found, e.equal(item, v))
+	}
+	return found
+}
+
+// jsonTypes are the values jsonType takes, as PostgreSQL's jsonb_typeof
+// names them.
+var jsonTypes = []string{"object", "array", "string", "number", "boolean", "null"}
+
+// jsonTypeOf returns the jsonTypes name of a JSON value.
+func jsonTypeOf(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
+
+// jsonContains reports whether value contains want as PostgreSQL's @> does:
+// objects contain the keys of want with contained values, arrays contain
+// an element containing each element of want, and scalars are equal.
+func jsonContains(value, want any) bool {
+	switch w := want.(type) {
+	case map[string]any:
+		v, ok := value.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, wv := range w {
+			if vv, ok := v[k]; !ok || !jsonContains(vv, wv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		v, ok := value.([]any)
+		if !ok {
+			return false
+		}
+		for _, wv := range w {
+			found := false
+			for _, vv := range v {
+				found = found || jsonContains(vv, wv)
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(value, want)
+}
+
+// pinnedRows returns partial rows covering every row where can select, from
+// the eq and in conditions it requires, expanding in lists into one row per
+// value. A where that pins nothing gives one empty row.
+func pinnedRows(where *types.Filter) []map[string]any {
+	rows := []map[string]any{{}}
+	var pin func(f *types.Filter)
+	pin = func(f *types.Filter) {
+		if f == nil {
+			return
+		}
+		for _, c := range f.GetConditions() {
+			if len(c.FieldPath) > 0 || c.Subquery != nil || (c.Op != "eq" && c.Op != "in") {
+				continue
+			}
+			if _, pinned := rows[0][c.Field]; pinned {
+				continue
+			}
+			values := []any{c.Value}
+			if c.Op == "in" {
+				list, err := types.Value(c.Value)
+				items, ok := list.([]any)
+				if err != nil || !ok || len(items) == 0 || len(rows)*len(items) > maxPinnedRows {
+					continue
+				}
+				values = items
+			}
+			var next []map[string]any
+			for _, row := range rows {
+				for _, v := range values {
+					pinned := make(map[string]any, len(row)+1)
+					for k, rv := range row {
+						pinned[k] = rv
+					}
+					pinned[c.Field] = v
+					next = append(next, pinned)
+				}
+			}
+			rows = next
+		}
+		for i := range f.GetAnd() {
+			pin(&(*f.And)[i])
+		}
+	}
+	pin(where)
+	return rows
+}
+
+// compareValues compares two numbers, strings or booleans. It reports false
+// if the values are null or of different kinds.
+func compareValues(a, b any) (int, bool) {
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av, bv), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av == bv:
+			return 0, true
+		case bv:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// likePattern translates a SQL LIKE pattern into an anchored regexp.
+func likePattern(pattern string, insensitive bool) string {
+	var b strings.Builder
+	if insensitive {
+		b.WriteString("(?i)")
+	}
+	b.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '%':
+			b.WriteString(".*")
+		case '_':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return b.String()
+}
+
+// jsonValue normalizes v to the value it decodes to from JSON, after
+// coercing it with types.Value.
+func jsonValue(v any) (any, error) {
+	if v == nil {
+		return nil, nil
+	}
+	coerced, err := types.Value(v)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(coerced)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,284 @@
+package tests_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func conditions(cs ...types.Condition) types.Filter {
+	return types.Filter{Conditions: &cs}
+}
+
+func TestFilterMatches(t *testing.T) {
+	row := map[string]any{
+		"id":        int64(7),
+		"title":     "Hello World",
+		"score":     4.5,
+		"deletedAt": nil,
+		"tags":      []any{"go", "sql"},
+		"meta":      map[string]any{"lang": "en", "labels": []any{"a", "b"}},
+		"createdAt": time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC),
+	}
+
+	tcs := []struct {
+		name string
+		cond types.Condition
+		want bool
+	}{
+		{"eq number", types.Condition{Field: "id", Op: "eq", Value: 7.0}, true},
+		{"eq time", types.Condition{Field: "createdAt", Op: "eq", Value: "2024-01-15T00:00:00Z"}, true},
+		{"eq null", types.Condition{Field: "deletedAt", Op: "eq", Value: nil}, false},
+		{"ne", types.Condition{Field: "title", Op: "ne", Value: "x"}, true},
+		{"ne null", types.Condition{Field: "deletedAt", Op: "ne", Value: "x"}, false},
+		{"in", types.Condition{Field: "id", Op: "in", Value: []any{1, 7}}, true},
+		{"notIn", types.Condition{Field: "id", Op: "notIn", Value: []any{1, 7}}, false},
+		{"isNull", types.Condition{Field: "deletedAt", Op: "isNull", Value: true}, true},
+		{"isNull missing", types.Condition{Field: "missing", Op: "isNull", Value: true}, true},
+		{"not isNull", types.Condition{Field: "title", Op: "isNull", Value: false}, true},
+		{"exists", types.Condition{Field: "deletedAt", Op: "exists", Value: true}, true},
+		{"exists missing", types.Condition{Field: "missing", Op: "exists", Value: false}, true},
+		{"gt", types.Condition{Field: "score", Op: "gt", Value: 4}, true},
+		{"lte", types.Condition{Field: "score", Op: "lte", Value: 4}, false},
+		{"gt mismatched", types.Condition{Field: "title", Op: "gt", Value: 4}, false},
+		{"between", types.Condition{Field: "score", Op: "between", Value: []any{4, 5}}, true},
+		{"contains", types.Condition{Field: "title", Op: "contains", Value: "lo Wo"}, true},
+		{"startsWith", types.Condition{Field: "title", Op: "startsWith", Value: "World"}, false},
+		{"endsWith", types.Condition{Field: "title", Op: "endsWith", Value: "World"}, true},
+		{"like", types.Condition{Field: "title", Op: "like", Value: "H_llo%"}, true},
+		{"like case", types.Condition{Field: "title", Op: "like", Value: "hello%"}, false},
+		{"ilike", types.Condition{Field: "title", Op: "ilike", Value: "hello%"}, true},
+		{"regex", types.Condition{Field: "title", Op: "regex", Value: "^H.*d$"}, true},
+		{"has", types.Condition{Field: "tags", Op: "has", Value: "go"}, true},
+		{"hasSome", types.Condition{Field: "tags", Op: "hasSome", Value: []any{"rust", "sql"}}, true},
+		{"hasEvery", types.Condition{Field: "tags", Op: "hasEvery", Value: []any{"go", "rust"}}, false},
+		{"jsonContains", types.Condition{Field: "meta", Op: "jsonContains", Value: map[string]any{"labels": []any{"b"}}}, true},
+		{"jsonContains mismatch", types.Condition{Field: "meta", Op: "jsonContains", Value: map[string]any{"lang": "fr"}}, false},
+		{"lenEq", types.Condition{Field: "tags", Op: "lenEq", Value: 2}, true},
+		{"lenGt", types.Condition{Field: "title", Op: "lenGt", Value: 20}, false},
+		{"lenLt", types.Condition{Field: "title", Op: "lenLt", Value: 20}, true},
+		{"field path", types.Condition{Field: "meta", FieldPath: []string{"labels", "1"}, Op: "eq", Value: "b"}, true},
+		{"field path zero-padded index", types.Condition{Field: "meta", FieldPath: []string{"labels", "01"}, Op: "eq", Value: "b"}, false},
+		{"field path missing", types.Condition{Field: "meta", FieldPath: []string{"region"}, Op: "isNull", Value: true}, true},
+		{"jsonHasKey", types.Condition{Field: "meta", Op: "jsonHasKey", Value: "lang"}, true},
+		{"jsonHasKey missing", types.Condition{Field: "meta", Op: "jsonHasKey", Value: "region"}, false},
+		{"jsonHasKey not an object", types.Condition{Field: "tags", Op: "jsonHasKey", Value: "0"}, false},
+		{"jsonType", types.Condition{Field: "meta", FieldPath: []string{"labels"}, Op: "jsonType", Value: "array"}, true},
+		{"jsonType number", types.Condition{Field: "score", Op: "jsonType", Value: "number"}, true},
+		{"jsonType null", types.Condition{Field: "deletedAt", Op: "jsonType", Value: "null"}, true},
+		{"jsonType missing", types.Condition{Field: "meta", FieldPath: []string{"region"}, Op: "jsonType", Value: "null"}, false},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tests.FilterMatches(conditions(tt.cond), row)
+			if err != nil {
+				t.Fatalf("FilterMatches failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FilterMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterMatchesGeo(t *testing.T) {
+	paris := types.GeoPoint{Lat: 48.8566, Lng: 2.3522}
+	louvre := types.GeoPoint{Lat: 48.8606, Lng: 2.3376}
+	square := types.Polygon(
+		types.GeoPoint{Lat: 48.8, Lng: 2.3}, types.GeoPoint{Lat: 48.9, Lng: 2.3},
+		types.GeoPoint{Lat: 48.9, Lng: 2.4}, types.GeoPoint{Lat: 48.8, Lng: 2.4},
+	)
+	row := map[string]any{
+		"location": paris,
+		"fiji":     types.GeoPoint{Lat: -17.7, Lng: 179.9},
+		"area":     square,
+		"title":    "x",
+	}
+
+	tcs := []struct {
+		name string
+		cond types.Condition
+		want bool
+	}{
+		{"withinRadius", types.WithinRadius("location", louvre, 1200), true},
+		{"withinRadius outside", types.WithinRadius("location", louvre, 1000), false},
+		{"withinRadius not a point", types.WithinRadius("title", louvre, 1e7), false},
+		{"bbox", types.BBox("location", types.GeoPoint{Lat: 48, Lng: 2}, types.GeoPoint{Lat: 49, Lng: 3}), true},
+		{"bbox outside", types.BBox("location", types.GeoPoint{Lat: 40, Lng: -5}, types.GeoPoint{Lat: 48, Lng: 3}), false},
+		{"bbox antimeridian", types.BBox("fiji", types.GeoPoint{Lat: -20, Lng: 179}, types.GeoPoint{Lat: -15, Lng: -179}), true},
+		{"bbox not antimeridian", types.BBox("location", types.GeoPoint{Lat: 48, Lng: 179}, types.GeoPoint{Lat: 49, Lng: -179}), false},
+		{"intersects point in polygon", types.Intersects("location", square), true},
+		{"intersects point on edge", types.Intersects("location", types.Polygon(paris, types.GeoPoint{Lat: 50, Lng: 2}, types.GeoPoint{Lat: 50, Lng: 3})), true},
+		{"intersects point in circle", types.Intersects("location", types.Circle(louvre, 1200)), true},
+		{"intersects circle near edge", types.Intersects("area", types.Circle(types.GeoPoint{Lat: 48.85, Lng: 2.401}, 100)), true},
+		{"intersects circle apart", types.Intersects("area", types.Circle(types.GeoPoint{Lat: 48.85, Lng: 2.41}, 100)), false},
+		{"intersects polygons crossing", types.Intersects("area", types.Polygon(
+			types.GeoPoint{Lat: 48.85, Lng: 2.35}, types.GeoPoint{Lat: 49, Lng: 2.35}, types.GeoPoint{Lat: 49, Lng: 2.5},
+		)), true},
+		{"intersects polygon inside", types.Intersects("area", types.Polygon(
+			types.GeoPoint{Lat: 48.84, Lng: 2.34}, types.GeoPoint{Lat: 48.86, Lng: 2.34}, types.GeoPoint{Lat: 48.86, Lng: 2.36},
+		)), true},
+		{"intersects polygons apart", types.Intersects("area", types.Polygon(
+			types.GeoPoint{Lat: 49, Lng: 3}, types.GeoPoint{Lat: 50, Lng: 3}, types.GeoPoint{Lat: 50, Lng: 4},
+		)), false},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tests.FilterMatches(conditions(tt.cond), row)
+			if err != nil {
+				t.Fatalf("FilterMatches failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FilterMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := tests.FilterMatches(conditions(types.Intersects("location", types.Polygon(paris))), row); err == nil {
+		t.Error("FilterMatches() with a two-vertex polygon: expected error")
+	}
+}
+
+func TestFilterMatchesCombinators(t *testing.T) {
+	row := map[string]any{"status": "draft", "views": 10}
+	filter := types.Filter{
+		Or: &[]types.Filter{
+			conditions(types.Condition{Field: "status", Op: "eq", Value: "published"}),
+			conditions(types.Condition{Field: "views", Op: "gte", Value: 10}),
+		},
+		Not: &types.Filter{Conditions: &[]types.Condition{{Field: "status", Op: "eq", Value: "archived"}}},
+	}
+	if ok, err := tests.FilterMatches(filter, row); err != nil || !ok {
+		t.Errorf("FilterMatches() = %v, %v; want true", ok, err)
+	}
+	row["status"] = "archived"
+	if ok, err := tests.FilterMatches(filter, row); err != nil || ok {
+		t.Errorf("FilterMatches() = %v, %v; want false", ok, err)
+	}
+	if ok, err := tests.FilterMatches(types.Filter{}, row); err != nil || !ok {
+		t.Errorf("empty filter: FilterMatches() = %v, %v; want true", ok, err)
+	}
+}
+
+func TestFilterMatchesErrors(t *testing.T) {
+	row := map[string]any{"id": 1, "title": "x"}
+
+	tcs := []struct {
+		name         string
+		cond         types.Condition
+		notEvaluable bool
+	}{
+		{"custom operator", types.Condition{Field: "title", Op: "custom:fts", Value: "x"}, true},
+		{"subquery", types.Condition{Field: "id", Op: "inQuery", Subquery: &types.Query{Model: "post"}}, true},
+		{"in without array", types.Condition{Field: "id", Op: "in", Value: 1}, false},
+		{"between without bounds", types.Condition{Field: "id", Op: "between", Value: []any{1}}, false},
+		{"invalid regex", types.Condition{Field: "title", Op: "regex", Value: "("}, false},
+		{"unknown json type", types.Condition{Field: "title", Op: "jsonType", Value: "text"}, false},
+		{"unknown operator", types.Condition{Field: "id", Op: "near", Value: 1}, false},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tests.FilterMatches(conditions(tt.cond), row)
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if errors.Is(err, tests.ErrNotEvaluable) != tt.notEvaluable {
+				t.Errorf("errors.Is(%v, ErrNotEvaluable) = %v, want %v", err, !tt.notEvaluable, tt.notEvaluable)
+			}
+		})
+	}
+
+	// A condition already ruled out is not evaluated
+	filter := conditions(types.Condition{Field: "id", Op: "eq", Value: 2}, types.Condition{Field: "title", Op: "custom:fts", Value: "x"})
+	if ok, err := tests.FilterMatches(filter, row); err != nil || ok {
+		t.Errorf("FilterMatches() = %v, %v; want false", ok, err)
+	}
+}
+
+func TestFilterIntersectsChange(t *testing.T) {
+	published := conditions(types.Condition{Field: "status", Op: "eq", Value: "published"})
+
+	tcs := []struct {
+		name   string
+		filter types.Filter
+		change types.Change
+		want   bool
+	}{
+		{
+			name:   "insert within",
+			filter: published,
+			change: types.Change{Model: "post", Action: "insert", Sets: []types.KV{{Field: "status", Value: "published"}}},
+			want:   true,
+		},
+		{
+			name:   "insert outside",
+			filter: published,
+			change: types.Change{Model: "post", Action: "insert", Sets: []types.KV{{Field: "status", Value: "draft"}}},
+			want:   false,
+		},
+		{
+			name:   "insert without the field",
+			filter: published,
+			change: types.Change{Model: "post", Action: "insert", Sets: []types.KV{{Field: "title", Value: "x"}}},
+			want:   true,
+		},
+		{
+			name:   "delete pinned outside",
+			filter: published,
+			change: types.Change{Model: "post", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "status", Op: "in", Value: []any{"draft", "archived"}}}}},
+			want:   false,
+		},
+		{
+			name:   "delete unpinned",
+			filter: published,
+			change: types.Change{Model: "post", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "id", Op: "eq", Value: 1}}}},
+			want:   true,
+		},
+		{
+			name:   "update moves rows in",
+			filter: published,
+			change: types.Change{
+				Model: "post", Action: "update",
+				Where: &types.Filter{Conditions: &[]types.Condition{{Field: "status", Op: "eq", Value: "draft"}}},
+				Sets:  []types.KV{{Field: "status", Value: "published"}},
+			},
+			want: true,
+		},
+		{
+			name:   "update stays outside",
+			filter: published,
+			change: types.Change{
+				Model: "post", Action: "update",
+				Where: &types.Filter{Conditions: &[]types.Condition{{Field: "status", Op: "eq", Value: "draft"}}},
+				Sets:  []types.KV{{Field: "status", Value: "archived"}},
+			},
+			want: false,
+		},
+		{
+			name:   "insert outside radius",
+			filter: conditions(types.WithinRadius("location", types.GeoPoint{Lat: 48.8566, Lng: 2.3522}, 1000)),
+			change: types.Change{Model: "post", Action: "insert", Sets: []types.KV{{Field: "location", Value: types.GeoPoint{Lat: 51.5072, Lng: -0.1276}}}},
+			want:   false,
+		},
+		{
+			name:   "custom operator may match",
+			filter: conditions(types.Condition{Field: "status", Op: "custom:fts", Value: "x"}),
+			change: types.Change{Model: "post", Action: "insert", Sets: []types.KV{{Field: "status", Value: "draft"}}},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tests.FilterIntersectsChange(tt.filter, tt.change); got != tt.want {
+				t.Errorf("FilterIntersectsChange() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
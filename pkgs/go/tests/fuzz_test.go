@@ -0,0 +1,94 @@
+package tests_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// The seed corpora in testdata/fuzz hold the inputs of the shared test
+// vectors. "go test" runs them as regression tests; "go test -fuzz" grows
+// them.
+
+// FuzzCanonicalize checks that canonicalization is idempotent: canonical
+// JSON parses back to a value with the same canonical JSON.
+func FuzzCanonicalize(f *testing.F) {
+	f.Add([]byte(`{"b":[1e21,-0,0.1],"a":{"é":" "}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v, err := decodeJSON(data)
+		if err != nil {
+			return
+		}
+		canonical, err := tests.Canonicalize(v)
+		if err != nil {
+			return
+		}
+		reparsed, err := decodeJSON([]byte(canonical))
+		if err != nil {
+			t.Fatalf("canonical JSON %s does not parse: %v", canonical, err)
+		}
+		again, err := tests.Canonicalize(reparsed)
+		if err != nil {
+			t.Fatalf("Canonicalize(%s) failed: %v", canonical, err)
+		}
+		if again != canonical {
+			t.Errorf("Canonicalize is not idempotent:\n%s\n%s", canonical, again)
+		}
+	})
+}
+
+// FuzzValidateStatement checks that decoding and validating never panic, and
+// that a valid statement keeps its shape ID when it is serialized and
+// decoded again, as it is when sent between SDKs and engines.
+func FuzzValidateStatement(f *testing.F) {
+	f.Add([]byte(`{"query":{"model":"posts","where":{"conditions":[{"field":"id","op":"in","value":[1,"a",null]}]}}}`))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, opts := range [][]tests.ValidateOption{nil, {tests.Strict()}} {
+			var stmt types.Statement
+			if json.Unmarshal(data, &stmt) == nil {
+				tests.ValidateQueryShapeAll(&stmt, opts...)
+			}
+
+			decoded, err := tests.DecodeStatement(bytes.NewReader(data), opts...)
+			if err != nil {
+				continue
+			}
+			shapeID, err := tests.ComputeQueryShapeID(decoded)
+			if err != nil {
+				continue
+			}
+
+			canonical, err := tests.CanonicalizeQueryShape(decoded)
+			if err != nil {
+				t.Fatalf("CanonicalizeQueryShape failed after ComputeQueryShapeID: %v", err)
+			}
+			redecoded, err := tests.DecodeStatement(strings.NewReader(canonical), opts...)
+			if err != nil {
+				t.Fatalf("canonical statement %s no longer decodes: %v", canonical, err)
+			}
+			again, err := tests.ComputeQueryShapeID(redecoded)
+			if err != nil {
+				t.Fatalf("ComputeQueryShapeID(%s) failed: %v", canonical, err)
+			}
+			if again != shapeID {
+				t.Errorf("shape ID changed after re-parsing %s: %s, was %s", canonical, again, shapeID)
+			}
+		}
+	})
+}
+
+func decodeJSON(data []byte) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
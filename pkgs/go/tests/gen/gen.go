@@ -0,0 +1,536 @@
+// Package gen generates random valid Statements, Mutations and Dependencies
+// of the IncludeKit Universal Format, so SDK authors can property-test their
+// ORM translation layers against the spec without hand-writing fixtures:
+//
+//	g := gen.New(rand.New(rand.NewSource(seed)), gen.Config{Schema: schema})
+//	for i := 0; i < 1000; i++ {
+//	    stmt := g.Statement()
+//	    // translate stmt to the ORM's query and back, and compare
+//	}
+//
+// Everything generated passes the tests validators, in strict mode too, and
+// names only the models, fields and relations of the schema, so it resolves
+// with mock.ResolveIncludes. The default nesting also stays within
+// limits.Default; a larger Config.MaxDepth may not. The Statement,
+// Mutation and Dependencies types plug the generator into testing/quick
+// using DefaultSchema.
+//
+// This is a TESTKIT package - for testing and development only.
+package gen
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// DefaultSchema is a small blog schema, used when Config.Schema has no
+// models.
+var DefaultSchema = mock.AppSchema{
+	Version: 1,
+	Models: []mock.Model{
+		{
+			Name:   "users",
+			ID:     mock.IDConfig{Kind: "string"},
+			Fields: []string{"id", "name", "email", "age", "active"},
+			Relations: []mock.Relation{
+				{Name: "posts", Target: "posts", Kind: "many", Fields: []string{"id"}, References: []string{"authorId"}},
+			},
+		},
+		{
+			Name:   "posts",
+			ID:     mock.IDConfig{Kind: "string"},
+			Fields: []string{"id", "title", "status", "views", "authorId", "createdAt", "tags"},
+			Relations: []mock.Relation{
+				{Name: "author", Target: "users", Kind: "one", Fields: []string{"authorId"}, References: []string{"id"}},
+				{Name: "comments", Target: "comments", Kind: "many", Fields: []string{"id"}, References: []string{"postId"}},
+			},
+		},
+		{
+			Name:   "comments",
+			ID:     mock.IDConfig{Kind: "string"},
+			Fields: []string{"id", "body", "postId", "authorId", "createdAt"},
+			Relations: []mock.Relation{
+				{Name: "post", Target: "posts", Kind: "one", Fields: []string{"postId"}, References: []string{"id"}},
+			},
+		},
+	},
+}
+
+// Config bounds what a Generator produces. Zero fields take their defaults.
+type Config struct {
+	Schema        mock.AppSchema // models, fields and relations to draw from (default: DefaultSchema)
+	MaxDepth      int            // nesting of filters, includes and subqueries (default: 3)
+	MaxConditions int            // conditions per filter (default: 3)
+	MaxIncludes   int            // includes per statement or include (default: 2)
+}
+
+// Generator produces random documents from a source of randomness. The same
+// seed and Config always produce the same documents. A Generator is not
+// safe for concurrent use.
+type Generator struct {
+	r   *rand.Rand
+	cfg Config
+}
+
+// New returns a Generator drawing from r.
+func New(r *rand.Rand, cfg Config) *Generator {
+	if len(cfg.Schema.Models) == 0 {
+		cfg.Schema = DefaultSchema
+	}
+	if cfg.MaxDepth <= 0 {
+		cfg.MaxDepth = 3
+	}
+	if cfg.MaxConditions <= 0 {
+		cfg.MaxConditions = 3
+	}
+	if cfg.MaxIncludes <= 0 {
+		cfg.MaxIncludes = 2
+	}
+	return &Generator{r: r, cfg: cfg}
+}
+
+// filterOps are the operators conditions are drawn from. Subquery operators
+// are added separately, while depth allows.
+var filterOps = []string{
+	"eq", "ne", "in", "notIn", "isNull", "gt", "gte", "lt", "lte", "between",
+	"contains", "startsWith", "endsWith", "like", "ilike", "regex",
+	"has", "hasSome", "hasEvery", "jsonContains", "lenEq", "lenGt", "lenLt", "exists",
+	"withinRadius", "intersects", "bbox", "jsonHasKey", "jsonType",
+}
+
+// Statement returns a random valid statement over a model of the schema.
+func (g *Generator) Statement() *types.Statement {
+	model := g.model()
+	stmt := &types.Statement{Query: g.query(model, model.Name, g.cfg.MaxDepth)}
+
+	if g.chance(4) {
+		g.groupBy(stmt, model)
+	}
+	if stmt.Query.OrderBy != nil && g.chance(3) {
+		stmt.Pagination = g.pagination(*stmt.Query.OrderBy)
+	}
+	stmt.Includes = g.includes(model, g.cfg.MaxDepth-1)
+	if g.chance(6) {
+		stmt.Tenant = g.tenant()
+	}
+	if g.chance(6) {
+		stmt.Policy = g.filter(model, 1)
+	}
+	return stmt
+}
+
+// Mutation returns a random valid mutation of one to three changes.
+func (g *Generator) Mutation() *types.Mutation {
+	m := &types.Mutation{Changes: make([]types.Change, 1+g.r.Intn(3))}
+	for i := range m.Changes {
+		m.Changes[i] = g.change()
+	}
+	if g.chance(2) {
+		txID := fmt.Sprintf("tx_%d", g.r.Intn(1000))
+		m.TxID = &txID
+	}
+	if g.chance(6) {
+		m.Tenant = g.tenant()
+	}
+	return m
+}
+
+// Dependencies returns valid dependencies of a random statement, as an
+// engine might report them.
+func (g *Generator) Dependencies() *types.Dependencies {
+	stmt := g.Statement()
+	shapeID, err := tests.ComputeQueryShapeID(stmt)
+	if err != nil {
+		// generated values always canonicalize
+		panic(fmt.Sprintf("gen: %v", err))
+	}
+
+	version := types.SpecVersion
+	deps := &types.Dependencies{
+		SpecVersion: &version,
+		ShapeID:     shapeID,
+		Records:     map[string][]string{},
+		Filters:     []types.Filter{},
+		Includes:    []types.Include{},
+	}
+	root := stmt.Query.Model
+	for i := g.r.Intn(4); i > 0; i-- {
+		deps.Records[root] = append(deps.Records[root], g.recordID(root))
+	}
+	if stmt.Query.Where != nil {
+		deps.Filters = append(deps.Filters, *stmt.Query.Where)
+	}
+	for _, inc := range stmt.Includes {
+		if inc.Kind != nil {
+			deps.Includes = append(deps.Includes, inc)
+		}
+	}
+	if g.chance(3) {
+		ttl := g.r.Intn(3600)
+		deps.TTL = &ttl
+	}
+	deps.Tenant = stmt.Tenant
+	deps.Policy = stmt.Policy
+	return deps
+}
+
+func (g *Generator) tenant() *string {
+	tenant := fmt.Sprintf("t_%d", g.r.Intn(10))
+	return &tenant
+}
+
+func (g *Generator) query(model *mock.Model, name string, depth int) *types.Query {
+	q := &types.Query{Model: name}
+	if g.chance(2) {
+		fields := g.subset(g.fieldNames(model), 1)
+		q.Fields = &fields
+	}
+	if g.chance(4) {
+		distinct := g.subset(g.fieldNames(model), 1)
+		q.Distinct = &distinct
+	} else if g.chance(6) {
+		q.DistinctOn = g.distinctOn(model)
+	}
+	if g.chance(3) {
+		q.Where = g.filter(model, depth)
+	}
+	if g.chance(2) {
+		orderBy := make([]types.OrderBy, 0, 2)
+		for _, field := range g.subset(g.fieldNames(model), 1) {
+			ob := types.OrderBy{Field: field}
+			if g.chance(2) {
+				desc := g.chance(2)
+				ob.Descending = &desc
+			}
+			if g.chance(4) {
+				nullsFirst := g.chance(2)
+				ob.NullsFirst = &nullsFirst
+			}
+			orderBy = append(orderBy, ob)
+		}
+		q.OrderBy = &orderBy
+	}
+	if g.chance(3) {
+		limit := 1 + g.r.Intn(100)
+		q.Limit = &limit
+	}
+	if g.chance(5) {
+		offset := g.r.Intn(100)
+		q.Offset = &offset
+	}
+	if len(model.Relations) > 0 && g.chance(5) {
+		joins := g.joins(model, depth)
+		q.Joins = &joins
+	}
+	if g.chance(6) {
+		windows := g.windows(model)
+		q.Windows = &windows
+	}
+	if g.chance(8) {
+		deleted := g.pick("include", "only")
+		q.Deleted = &deleted
+	}
+	return q
+}
+
+// distinctOn returns a DistinctOn whose order_by sorts by its fields, in
+// their order, and then by a random field.
+func (g *Generator) distinctOn(model *mock.Model) *types.DistinctOn {
+	fields := g.fieldNames(model)
+	d := &types.DistinctOn{Fields: g.subset(fields, 1)}
+	for _, field := range d.Fields {
+		d.OrderBy = append(d.OrderBy, types.OrderBy{Field: field})
+	}
+	desc := g.chance(2)
+	d.OrderBy = append(d.OrderBy, types.OrderBy{Field: fields[g.r.Intn(len(fields))], Descending: &desc})
+	return d
+}
+
+func (g *Generator) windows(model *mock.Model) []types.Window {
+	fields := g.fieldNames(model)
+	windows := make([]types.Window, 1+g.r.Intn(2))
+	for i := range windows {
+		alias := fmt.Sprintf("w%d", i)
+		order := types.OrderBy{Field: fields[g.r.Intn(len(fields))]}
+		if g.chance(2) {
+			windows[i] = types.Window{Func: g.pick("row_number", "rank", "dense_rank"), Alias: alias}.OrderedBy(order)
+		} else {
+			windows[i] = types.WindowOf(types.Aggregate{Func: g.pick("count", "sum", "avg", "min", "max"), Field: &fields[g.r.Intn(len(fields))]}, alias)
+			if g.chance(2) {
+				start, end := -g.r.Intn(5), g.r.Intn(5)
+				windows[i] = windows[i].OrderedBy(order).Within(types.WindowFrame{Unit: "rows", Start: &start, End: &end})
+			}
+		}
+		if g.chance(2) {
+			windows[i] = windows[i].PartitionedBy(g.subset(fields, 1)...)
+		}
+	}
+	return windows
+}
+
+func (g *Generator) joins(model *mock.Model, depth int) []types.Join {
+	var joins []types.Join
+	for _, i := range g.r.Perm(len(model.Relations))[:1+g.r.Intn(len(model.Relations))] {
+		rel := model.Relations[i]
+		target := g.target(rel)
+		join := types.Join{Relation: rel.Name, Kind: g.pick("inner", "left")}
+		if g.chance(2) {
+			fields := g.subset(g.fieldNames(target), 1)
+			join.Fields = &fields
+		}
+		if g.chance(3) {
+			join.On = g.filter(target, depth-1)
+		}
+		joins = append(joins, join)
+	}
+	return joins
+}
+
+func (g *Generator) filter(model *mock.Model, depth int) *types.Filter {
+	f := &types.Filter{}
+	conds := make([]types.Condition, 1+g.r.Intn(g.cfg.MaxConditions))
+	for i := range conds {
+		conds[i] = g.condition(model, depth)
+	}
+	f.Conditions = &conds
+	if depth > 1 {
+		switch g.r.Intn(4) {
+		case 0:
+			and := []types.Filter{*g.filter(model, depth-1)}
+			f.And = &and
+		case 1:
+			or := []types.Filter{*g.filter(model, depth-1), *g.filter(model, depth-1)}
+			f.Or = &or
+		case 2:
+			f.Not = g.filter(model, depth-1)
+		}
+	}
+	return f
+}
+
+func (g *Generator) condition(model *mock.Model, depth int) types.Condition {
+	fields := g.fieldNames(model)
+	cond := types.Condition{Field: fields[g.r.Intn(len(fields))]}
+	if depth > 1 && g.chance(8) {
+		target := g.model()
+		cond.Op = g.pick("inQuery", "existsQuery")
+		sub := g.query(target, target.Name, depth-1)
+		sub.Aggregations, sub.Joins, sub.Windows = nil, nil, nil
+		key := g.fieldNames(target)[0]
+		sub.Fields = &[]string{key}
+		cond.Subquery = sub
+		return cond
+	}
+
+	cond.Op = filterOps[g.r.Intn(len(filterOps))]
+	switch cond.Op {
+	case "in", "notIn", "hasSome", "hasEvery":
+		cond.Value = g.list(1 + g.r.Intn(3))
+	case "between":
+		lo := g.r.Intn(100)
+		cond.Value = []any{lo, lo + g.r.Intn(100)}
+	case "isNull", "exists":
+		cond.Value = g.chance(2)
+	case "contains", "startsWith", "endsWith", "like", "ilike", "regex":
+		cond.Value = g.word()
+	case "lenEq", "lenGt", "lenLt":
+		cond.Value = g.r.Intn(10)
+	case "jsonContains":
+		cond.Value = map[string]any{g.word(): g.scalar()}
+	case "jsonHasKey":
+		cond.Value = g.word()
+	case "jsonType":
+		cond.Value = g.pick("object", "array", "string", "number", "boolean", "null")
+	case "withinRadius":
+		cond.Value, _ = types.Value(types.Circle(g.geoPoint(), float64(g.r.Intn(10000))))
+	case "intersects":
+		shape := types.Circle(g.geoPoint(), float64(g.r.Intn(10000)))
+		if g.chance(2) {
+			shape = types.Polygon(g.geoPoint(), g.geoPoint(), g.geoPoint())
+		}
+		cond.Value, _ = types.Value(shape)
+	case "bbox":
+		sw, ne := g.geoPoint(), g.geoPoint()
+		if sw.Lat > ne.Lat {
+			sw.Lat, ne.Lat = ne.Lat, sw.Lat
+		}
+		cond.Value, _ = types.Value([]any{sw, ne})
+	default:
+		cond.Value = g.scalar()
+	}
+	if (cond.Op == "jsonContains" || cond.Op == "jsonHasKey" || cond.Op == "jsonType") && g.chance(2) {
+		cond.FieldPath = []string{g.pick("meta", "tags", "0")}
+	}
+	return cond
+}
+
+func (g *Generator) groupBy(stmt *types.Statement, model *mock.Model) {
+	fields := g.fieldNames(model)
+	groupBy := g.subset(fields, 1)
+	stmt.GroupBy = &groupBy
+	stmt.Query.Fields = &groupBy
+
+	aggs := []types.Aggregate{{Func: "count"}}
+	for _, fn := range g.subset([]string{"sum", "avg", "min", "max"}, 0) {
+		field := fields[g.r.Intn(len(fields))]
+		aggs = append(aggs, types.Aggregate{Func: fn, Field: &field})
+	}
+	stmt.Query.Aggregations = &aggs
+	stmt.Query.Joins, stmt.Query.Windows = nil, nil
+	if g.chance(2) {
+		stmt.Having = &types.Filter{Conditions: &[]types.Condition{types.Gt("count", 1+g.r.Intn(10))}}
+	}
+}
+
+func (g *Generator) pagination(orderBy []types.OrderBy) *types.Pagination {
+	size := 1 + g.r.Intn(50)
+	var cursor *string
+	if g.chance(2) {
+		row := map[string]any{}
+		for _, ob := range orderBy {
+			row[ob.Field] = g.scalar()
+		}
+		if c, err := pagination.CursorFromRow(orderBy, row); err == nil {
+			cursor = &c
+		}
+	}
+	if g.chance(2) {
+		return &types.Pagination{First: &size, After: cursor}
+	}
+	return &types.Pagination{Last: &size, Before: cursor}
+}
+
+func (g *Generator) includes(model *mock.Model, depth int) []types.Include {
+	if depth <= 0 || len(model.Relations) == 0 || !g.chance(2) {
+		return nil
+	}
+	n := 1 + g.r.Intn(min(g.cfg.MaxIncludes, len(model.Relations)))
+	includes := make([]types.Include, 0, n)
+	for _, i := range g.r.Perm(len(model.Relations))[:n] {
+		rel := model.Relations[i]
+		target := g.target(rel)
+		inc := types.Include{Query: g.query(target, rel.Name, depth)}
+		inc.Query.Joins = nil
+		if g.chance(3) {
+			kind := g.pick("some", "every", "none", "count")
+			inc.Kind = &kind
+			if kind == "count" {
+				inc.Count = &types.RelationCount{Op: g.pick("eq", "ne", "gt", "gte", "lt", "lte"), Value: g.r.Intn(4)}
+			}
+		}
+		inc.Includes = g.includes(target, depth-1)
+		includes = append(includes, inc)
+	}
+	return includes
+}
+
+func (g *Generator) change() types.Change {
+	model := g.model()
+	ch := types.Change{Model: model.Name, Action: g.pick("insert", "update", "delete")}
+	if ch.Action != "delete" {
+		for _, field := range g.subset(g.fieldNames(model), 1) {
+			ch.Sets = append(ch.Sets, types.KV{Field: field, Value: g.scalar()})
+		}
+	}
+	if ch.Action != "insert" {
+		key := g.fieldNames(model)[0]
+		if g.chance(2) {
+			ch.Where = &types.Filter{Conditions: &[]types.Condition{types.Eq(key, g.recordID(model.Name))}}
+		} else {
+			ch.Where = g.filter(model, 2)
+		}
+	}
+	return ch
+}
+
+func (g *Generator) model() *mock.Model {
+	return &g.cfg.Schema.Models[g.r.Intn(len(g.cfg.Schema.Models))]
+}
+
+// target returns the model a relation leads to, or a model with only the
+// conventional key when the schema does not declare it.
+func (g *Generator) target(rel mock.Relation) *mock.Model {
+	for i := range g.cfg.Schema.Models {
+		if g.cfg.Schema.Models[i].Name == rel.Target {
+			return &g.cfg.Schema.Models[i]
+		}
+	}
+	return &mock.Model{Name: rel.Target}
+}
+
+// fieldNames returns the declared fields of a model, or its key fields.
+func (g *Generator) fieldNames(model *mock.Model) []string {
+	if len(model.Fields) > 0 {
+		return model.Fields
+	}
+	return g.cfg.Schema.KeyFields(model.Name)
+}
+
+// subset returns a random subset of values, in their order, with at least
+// atLeast of them.
+func (g *Generator) subset(values []string, atLeast int) []string {
+	out := []string{}
+	for _, v := range values {
+		if g.chance(2) {
+			out = append(out, v)
+		}
+	}
+	for _, v := range values {
+		if len(out) >= atLeast {
+			break
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+func (g *Generator) recordID(model string) string {
+	return fmt.Sprintf("%s_%d", model, g.r.Intn(1000))
+}
+
+func (g *Generator) list(n int) []any {
+	values := make([]any, n)
+	for i := range values {
+		values[i] = g.scalar()
+	}
+	return values
+}
+
+// geoPoint returns a GeoPoint on whole degrees.
+func (g *Generator) geoPoint() types.GeoPoint {
+	return types.GeoPoint{Lat: float64(g.r.Intn(181) - 90), Lng: float64(g.r.Intn(361) - 180)}
+}
+
+// scalar returns a string, integer, boolean or null. Integers stay within
+// the range doubles represent exactly, so values survive JSON round trips.
+func (g *Generator) scalar() any {
+	switch g.r.Intn(6) {
+	case 0, 1:
+		return g.word()
+	case 2, 3:
+		return g.r.Intn(1 << 20)
+	case 4:
+		return g.chance(2)
+	}
+	return nil
+}
+
+var words = []string{"alpha", "beta", "gamma", "draft", "published", "archived", "é", "日本", "😀", "a\"b", "line\nbreak", ""}
+
+func (g *Generator) word() string {
+	return words[g.r.Intn(len(words))]
+}
+
+func (g *Generator) pick(options ...string) string {
+	return options[g.r.Intn(len(options))]
+}
+
+// chance reports true one time in n.
+func (g *Generator) chance(n int) bool {
+	return g.r.Intn(n) == 0
+}
@@ -0,0 +1,87 @@
+package gen_test
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/gen"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+)
+
+func TestGeneratedDocumentsAreValid(t *testing.T) {
+	g := gen.New(rand.New(rand.NewSource(1)), gen.Config{})
+	strict := []tests.ValidateOption{tests.Strict()}
+	for i := 0; i < 500; i++ {
+		stmt := g.Statement()
+		if err := tests.ValidateQueryShape(stmt, strict...); err != nil {
+			t.Fatalf("statement %d is invalid: %v", i, err)
+		}
+		if _, err := mock.ResolveIncludes(gen.DefaultSchema, stmt); err != nil {
+			t.Fatalf("statement %d does not resolve against the schema: %v", i, err)
+		}
+		if _, err := tests.ComputeQueryShapeID(stmt); err != nil {
+			t.Fatalf("statement %d has no shape ID: %v", i, err)
+		}
+		if err := tests.ValidateMutationEvent(g.Mutation(), strict...); err != nil {
+			t.Fatalf("mutation %d is invalid: %v", i, err)
+		}
+		if err := tests.ValidateDependencies(g.Dependencies(), strict...); err != nil {
+			t.Fatalf("dependencies %d are invalid: %v", i, err)
+		}
+	}
+}
+
+func TestGeneratorIsDeterministic(t *testing.T) {
+	a := gen.New(rand.New(rand.NewSource(7)), gen.Config{})
+	b := gen.New(rand.New(rand.NewSource(7)), gen.Config{})
+	for i := 0; i < 50; i++ {
+		if sa, sb := a.Statement(), b.Statement(); !reflect.DeepEqual(sa, sb) {
+			t.Fatalf("statement %d differs for the same seed", i)
+		}
+	}
+}
+
+func TestGeneratorCoversTheFormat(t *testing.T) {
+	g := gen.New(rand.New(rand.NewSource(3)), gen.Config{})
+	seen := map[string]bool{}
+	for i := 0; i < 500; i++ {
+		stmt := g.Statement()
+		seen["includes"] = seen["includes"] || len(stmt.Includes) > 0
+		seen["pagination"] = seen["pagination"] || stmt.Pagination != nil
+		seen["group_by"] = seen["group_by"] || stmt.GroupBy != nil
+		seen["having"] = seen["having"] || stmt.Having != nil
+		seen["tenant"] = seen["tenant"] || stmt.Tenant != nil
+		seen["policy"] = seen["policy"] || stmt.Policy != nil
+		q := stmt.Query
+		seen["joins"] = seen["joins"] || q.Joins != nil
+		seen["distinct"] = seen["distinct"] || q.Distinct != nil
+		seen["distinct_on"] = seen["distinct_on"] || q.DistinctOn != nil
+		seen["deleted"] = seen["deleted"] || q.Deleted != nil
+		if q.Where != nil {
+			seen["or"] = seen["or"] || q.Where.Or != nil
+			seen["not"] = seen["not"] || q.Where.Not != nil
+			for _, c := range *q.Where.Conditions {
+				seen["subquery"] = seen["subquery"] || c.Subquery != nil
+			}
+		}
+	}
+	for _, feature := range []string{"includes", "pagination", "group_by", "having", "joins", "distinct", "distinct_on", "deleted", "tenant", "policy", "or", "not", "subquery"} {
+		if !seen[feature] {
+			t.Errorf("500 statements never used %s", feature)
+		}
+	}
+}
+
+func TestQuickCheck(t *testing.T) {
+	valid := func(s gen.Statement, m gen.Mutation, d gen.Dependencies) bool {
+		return tests.ValidateQueryShape(s.Statement) == nil &&
+			tests.ValidateMutationEvent(m.Mutation) == nil &&
+			tests.ValidateDependencies(d.Dependencies) == nil
+	}
+	if err := quick.Check(valid, nil); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,43 @@
+package gen
+
+import (
+	"math/rand"
+	"reflect"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Statement is a random valid statement over DefaultSchema when generated by
+// testing/quick:
+//
+//	quick.Check(func(s gen.Statement) bool { return roundTrips(s.Statement) }, nil)
+type Statement struct{ *types.Statement }
+
+// Generate implements quick.Generator.
+func (Statement) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Statement{quickGenerator(r, size).Statement()})
+}
+
+// Mutation is a random valid mutation over DefaultSchema when generated by
+// testing/quick.
+type Mutation struct{ *types.Mutation }
+
+// Generate implements quick.Generator.
+func (Mutation) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Mutation{quickGenerator(r, size).Mutation()})
+}
+
+// Dependencies are random valid dependencies over DefaultSchema when
+// generated by testing/quick.
+type Dependencies struct{ *types.Dependencies }
+
+// Generate implements quick.Generator.
+func (Dependencies) Generate(r *rand.Rand, size int) reflect.Value {
+	return reflect.ValueOf(Dependencies{quickGenerator(r, size).Dependencies()})
+}
+
+// quickGenerator scales nesting with the size testing/quick asks for: its
+// default size of 50 gives the default depth of 3.
+func quickGenerator(r *rand.Rand, size int) *Generator {
+	return New(r, Config{MaxDepth: 1 + size/25})
+}
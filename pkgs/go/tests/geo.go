@@ -0,0 +1,292 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// earthRadius is the mean radius of the earth in meters, as used by the
+// haversine distance between GeoPoints.
+const earthRadius = 6371008.8
+
+// geoPoint is a parsed GeoPoint, in degrees.
+type geoPoint struct {
+	lat, lng float64
+}
+
+// geoShape is a parsed GeoShape: a circle when polygon is nil, a polygon
+// otherwise. A GeoPoint field is a circle of radius 0.
+type geoShape struct {
+	center  geoPoint
+	radius  float64
+	polygon []geoPoint
+}
+
+// parseGeoPoint parses a JSON value as a GeoPoint.
+func parseGeoPoint(v any) (geoPoint, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return geoPoint{}, errors.New("GeoPoint must be an object with lat and lng")
+	}
+	if err := geoKeys(obj, "GeoPoint", "lat", "lng"); err != nil {
+		return geoPoint{}, err
+	}
+	lat, ok := obj["lat"].(float64)
+	if !ok || lat < -90 || lat > 90 {
+		return geoPoint{}, errors.New("lat must be a number from -90 to 90")
+	}
+	lng, ok := obj["lng"].(float64)
+	if !ok || lng < -180 || lng > 180 {
+		return geoPoint{}, errors.New("lng must be a number from -180 to 180")
+	}
+	return geoPoint{lat: lat, lng: lng}, nil
+}
+
+// parseGeoShape parses a JSON value as a GeoShape, which must be exactly one
+// of a circle (center and radius) or a polygon.
+func parseGeoShape(v any) (geoShape, error) {
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return geoShape{}, errors.New("GeoShape must be an object")
+	}
+	if err := geoKeys(obj, "GeoShape", "center", "radius", "polygon"); err != nil {
+		return geoShape{}, err
+	}
+	_, hasCenter := obj["center"]
+	_, hasRadius := obj["radius"]
+	vertices, hasPolygon := obj["polygon"]
+	switch {
+	case hasPolygon && (hasCenter || hasRadius):
+		return geoShape{}, errors.New("GeoShape must be a circle or a polygon, not both")
+	case hasPolygon:
+		list, ok := vertices.([]any)
+		if !ok || len(list) < 3 {
+			return geoShape{}, errors.New("polygon must be an array of at least 3 GeoPoints")
+		}
+		polygon := make([]geoPoint, len(list))
+		for i, vertex := range list {
+			p, err := parseGeoPoint(vertex)
+			if err != nil {
+				return geoShape{}, fmt.Errorf("polygon[%d]: %w", i, err)
+			}
+			polygon[i] = p
+		}
+		return geoShape{polygon: polygon}, nil
+	case !hasCenter || !hasRadius:
+		return geoShape{}, errors.New("GeoShape circle requires center and radius")
+	}
+	center, err := parseGeoPoint(obj["center"])
+	if err != nil {
+		return geoShape{}, fmt.Errorf("center: %w", err)
+	}
+	radius, ok := obj["radius"].(float64)
+	if !ok || radius < 0 {
+		return geoShape{}, errors.New("radius must be a non-negative number of meters")
+	}
+	return geoShape{center: center, radius: radius}, nil
+}
+
+// parseGeoBox parses the [southwest, northeast] value of bbox. The box
+// crosses the antimeridian when southwest lies east of northeast.
+func parseGeoBox(v any) (sw, ne geoPoint, err error) {
+	list, ok := v.([]any)
+	if !ok || len(list) != 2 {
+		return sw, ne, errors.New("bbox requires a [southwest, northeast] array value")
+	}
+	if sw, err = parseGeoPoint(list[0]); err != nil {
+		return sw, ne, fmt.Errorf("southwest: %w", err)
+	}
+	if ne, err = parseGeoPoint(list[1]); err != nil {
+		return sw, ne, fmt.Errorf("northeast: %w", err)
+	}
+	if sw.lat > ne.lat {
+		return sw, ne, errors.New("bbox southwest must not lie north of northeast")
+	}
+	return sw, ne, nil
+}
+
+// geoKeys rejects keys of obj other than allowed, reporting the first in
+// sorted order so that errors are deterministic.
+func geoKeys(obj map[string]any, name string, allowed ...string) error {
+	var unknown []string
+	for k := range obj {
+		known := false
+		for _, a := range allowed {
+			known = known || k == a
+		}
+		if !known {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return fmt.Errorf("%s has unknown property %q", name, unknown[0])
+}
+
+// validateGeoValue checks the value of a geospatial operator: withinRadius
+// takes a circle, intersects any GeoShape, and bbox a [southwest, northeast]
+// pair of GeoPoints.
+func validateGeoValue(op string, value any) error {
+	switch op {
+	case "withinRadius":
+		shape, err := parseGeoShape(value)
+		if err == nil && shape.polygon != nil {
+			err = errors.New("GeoShape must be a circle")
+		}
+		return err
+	case "intersects":
+		_, err := parseGeoShape(value)
+		return err
+	}
+	_, _, err := parseGeoBox(value)
+	return err
+}
+
+// decideGeo evaluates a geospatial operator against a field value, both
+// JSON values. A field that is not a GeoPoint (or, for intersects, a
+// GeoShape) matches nothing, as a comparison across types does.
+func decideGeo(op string, value, want any) (tri, error) {
+	if err := validateGeoValue(op, want); err != nil {
+		return no, fmt.Errorf("%s: %w", op, err)
+	}
+	var field geoShape
+	if p, err := parseGeoPoint(value); err == nil {
+		field = geoShape{center: p}
+	} else if s, err := parseGeoShape(value); err == nil && op == "intersects" {
+		field = s
+	} else {
+		return no, nil
+	}
+
+	switch op {
+	case "withinRadius":
+		shape, _ := parseGeoShape(want)
+		return truth(haversine(field.center, shape.center) <= shape.radius), nil
+	case "intersects":
+		shape, _ := parseGeoShape(want)
+		return truth(shapesIntersect(field, shape)), nil
+	}
+	sw, ne, _ := parseGeoBox(want)
+	p := field.center
+	if p.lat < sw.lat || p.lat > ne.lat {
+		return no, nil
+	}
+	if sw.lng <= ne.lng {
+		return truth(p.lng >= sw.lng && p.lng <= ne.lng), nil
+	}
+	return truth(p.lng >= sw.lng || p.lng <= ne.lng), nil
+}
+
+// haversine returns the great-circle distance between a and b in meters.
+func haversine(a, b geoPoint) float64 {
+	const rad = math.Pi / 180
+	dLat := (b.lat - a.lat) * rad
+	dLng := (b.lng - a.lng) * rad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a.lat*rad)*math.Cos(b.lat*rad)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// shapesIntersect reports whether two shapes touch or overlap.
+func shapesIntersect(a, b geoShape) bool {
+	switch {
+	case a.polygon == nil && b.polygon == nil:
+		return haversine(a.center, b.center) <= a.radius+b.radius
+	case a.polygon == nil:
+		return circleMeetsPolygon(a, b.polygon)
+	case b.polygon == nil:
+		return circleMeetsPolygon(b, a.polygon)
+	}
+	for i := range a.polygon {
+		for j := range b.polygon {
+			if segmentsCross(edge(a.polygon, i), edge(b.polygon, j)) {
+				return true
+			}
+		}
+	}
+	return inPolygon(a.polygon[0], b.polygon) || inPolygon(b.polygon[0], a.polygon)
+}
+
+// circleMeetsPolygon reports whether circle lies in polygon or within its
+// radius of an edge. Edge distances use an equirectangular projection about
+// the center, which is exact enough at the scale of a query radius.
+func circleMeetsPolygon(circle geoShape, polygon []geoPoint) bool {
+	if inPolygon(circle.center, polygon) {
+		return true
+	}
+	for i := range polygon {
+		if segmentDistance(circle.center, edge(polygon, i)) <= circle.radius {
+			return true
+		}
+	}
+	return false
+}
+
+// edge returns the i-th edge of the implicitly closed polygon.
+func edge(polygon []geoPoint, i int) [2]geoPoint {
+	return [2]geoPoint{polygon[i], polygon[(i+1)%len(polygon)]}
+}
+
+// inPolygon reports whether p lies inside polygon or on its boundary, by
+// ray casting in latitude and longitude.
+func inPolygon(p geoPoint, polygon []geoPoint) bool {
+	inside := false
+	for i := range polygon {
+		e := edge(polygon, i)
+		if orientation(e[0], e[1], p) == 0 && onSegment(p, e) {
+			return true
+		}
+		a, b := e[0], e[1]
+		if (a.lat > p.lat) != (b.lat > p.lat) &&
+			p.lng < a.lng+(p.lat-a.lat)*(b.lng-a.lng)/(b.lat-a.lat) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// segmentsCross reports whether two edges touch or cross in latitude and
+// longitude.
+func segmentsCross(s, t [2]geoPoint) bool {
+	d1 := orientation(t[0], t[1], s[0])
+	d2 := orientation(t[0], t[1], s[1])
+	d3 := orientation(s[0], s[1], t[0])
+	d4 := orientation(s[0], s[1], t[1])
+	if d1*d2 < 0 && d3*d4 < 0 {
+		return true
+	}
+	return d1 == 0 && onSegment(s[0], t) || d2 == 0 && onSegment(s[1], t) ||
+		d3 == 0 && onSegment(t[0], s) || d4 == 0 && onSegment(t[1], s)
+}
+
+func orientation(a, b, c geoPoint) float64 {
+	return (b.lng-a.lng)*(c.lat-a.lat) - (b.lat-a.lat)*(c.lng-a.lng)
+}
+
+// onSegment reports whether p, collinear with s, lies within it.
+func onSegment(p geoPoint, s [2]geoPoint) bool {
+	return p.lat >= math.Min(s[0].lat, s[1].lat) && p.lat <= math.Max(s[0].lat, s[1].lat) &&
+		p.lng >= math.Min(s[0].lng, s[1].lng) && p.lng <= math.Max(s[0].lng, s[1].lng)
+}
+
+// segmentDistance returns the distance in meters from p to the edge s, in
+// an equirectangular projection about p.
+func segmentDistance(p geoPoint, s [2]geoPoint) float64 {
+	const rad = math.Pi / 180
+	scale := math.Cos(p.lat * rad)
+	project := func(q geoPoint) (x, y float64) {
+		return (q.lng - p.lng) * rad * scale * earthRadius, (q.lat - p.lat) * rad * earthRadius
+	}
+	ax, ay := project(s[0])
+	bx, by := project(s[1])
+	dx, dy := bx-ax, by-ay
+	t := 0.0
+	if l := dx*dx + dy*dy; l > 0 {
+		t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/l))
+	}
+	return math.Hypot(ax+t*dx, ay+t*dy)
+}
@@ -3,25 +3,130 @@
 package tests_test
 
 import (
+	"bytes"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/bold-minds/includekit-spec/go/cursors"
+	"github.com/bold-minds/includekit-spec/go/pagination"
 	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/invalidate"
 	"github.com/bold-minds/includekit-spec/go/types"
 )
 
+// reference drives this package's implementation through the shared
+// vectors, as third-party implementations are driven through theirs.
+var reference = tests.ConformanceTarget{
+	Canonicalize: func(doc []byte) (string, error) {
+		return tests.Canonicalize(json.RawMessage(doc))
+	},
+	ComputeShapeID: func(statement []byte) (string, error) {
+		var stmt types.Statement
+		if err := json.Unmarshal(statement, &stmt); err != nil {
+			return "", err
+		}
+		return tests.ComputeQueryShapeID(&stmt)
+	},
+	ComputeStructuralShapeID: func(statement []byte) (string, error) {
+		var stmt types.Statement
+		if err := json.Unmarshal(statement, &stmt); err != nil {
+			return "", err
+		}
+		return tests.ComputeStructuralShapeID(&stmt)
+	},
+	ComputeMutationID: func(mutation []byte) (string, error) {
+		var m types.Mutation
+		if err := json.Unmarshal(mutation, &m); err != nil {
+			return "", err
+		}
+		return tests.ComputeMutationID(&m)
+	},
+	Validate: func(kind string, doc []byte, strict bool) error {
+		var opts []tests.ValidateOption
+		if strict {
+			opts = append(opts, tests.Strict())
+		}
+		var err error
+		switch kind {
+		case "statement":
+			_, err = tests.DecodeStatement(bytes.NewReader(doc), opts...)
+		case "mutation":
+			_, err = tests.DecodeMutation(bytes.NewReader(doc), opts...)
+		case "transaction":
+			_, err = tests.DecodeTransaction(bytes.NewReader(doc), opts...)
+		case "dependencies":
+			_, err = tests.DecodeDependencies(bytes.NewReader(doc), opts...)
+		default:
+			err = fmt.Errorf("unknown vector kind: %s", kind)
+		}
+		return err
+	},
+	RecordID: func(key []any) (string, error) {
+		return types.RecordID(key...)
+	},
+	Cursor: func(orderBy, row []byte) (string, error) {
+		ob, r, err := decodeCursorArgs(orderBy, row)
+		if err != nil {
+			return "", err
+		}
+		return cursors.Encode(ob, r)
+	},
+	CrossesBoundary: func(lastRow, change []byte) (bool, error) {
+		var lr types.PaginationBoundary
+		if err := json.Unmarshal(lastRow, &lr); err != nil {
+			return false, err
+		}
+		var c types.Change
+		if err := json.Unmarshal(change, &c); err != nil {
+			return false, err
+		}
+		return invalidate.CrossesBoundary(&lr, c), nil
+	},
+}
+
+func decodeCursorArgs(orderBy, row []byte) ([]types.OrderBy, map[string]any, error) {
+	var ob []types.OrderBy
+	if err := json.Unmarshal(orderBy, &ob); err != nil {
+		return nil, nil, err
+	}
+	var r map[string]any
+	if err := json.Unmarshal(row, &r); err != nil {
+		return nil, nil, err
+	}
+	return ob, r, nil
+}
+
+// The deprecated names of the production types must stay aliases, so code
+// written against them keeps compiling against the testkit.
+var (
+	_ func(*types.QueryShape, ...tests.ValidateOption) error    = tests.ValidateQueryShape
+	_ func(*types.QueryShape) (string, error)                   = tests.ComputeQueryShapeID
+	_ func(*types.MutationEvent, ...tests.ValidateOption) error = tests.ValidateMutationEvent
+	_ func(*types.MutationEvent) (string, error)                = tests.ComputeMutationID
+)
+
+func TestConformance(t *testing.T) {
+	tests.RunConformance(t, reference)
+}
+
 type Vector struct {
-	Name              string          `json:"name"`
-	Shape             types.Statement `json:"shape"`
-	ExpectedCanonical string          `json:"expectedCanonical"`
-	ExpectedShapeID   string          `json:"expectedShapeId"`
+	Name                      string          `json:"name"`
+	Shape                     types.Statement `json:"shape"`
+	ExpectedCanonical         string          `json:"expectedCanonical"`
+	ExpectedShapeID           string          `json:"expectedShapeId"`
+	ExpectedStructuralShapeID string          `json:"expectedStructuralShapeId"`
 }
 
+// TestConformanceQueryShapes checks the typed encoder, which the vectors
+// only reach through JSON in TestConformance.
 func TestConformanceQueryShapes(t *testing.T) {
-	// Load shared test vectors from tools directory
 	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "query-shapes.json")
 	data, err := os.ReadFile(vectorsPath)
 	if err != nil {
@@ -35,49 +140,275 @@ func TestConformanceQueryShapes(t *testing.T) {
 
 	for _, v := range vectors {
 		t.Run(v.Name, func(t *testing.T) {
-			// Validate
-			if err := tests.ValidateQueryShape(&v.Shape); err != nil {
-				t.Errorf("Validation failed: %v", err)
+			canonical, err := tests.CanonicalizeQueryShape(&v.Shape)
+			if err != nil {
+				t.Fatalf("Canonicalization failed: %v", err)
+			}
+			if canonical != v.ExpectedCanonical {
+				t.Errorf("Canonical JSON mismatch:\n  got:  %s\n  want: %s", canonical, v.ExpectedCanonical)
 			}
 
-			// Canonicalize
-			canonical, err := tests.CanonicalizeQueryShape(&v.Shape)
+			shapeID := tests.ComputeShapeID(canonical)
+			if !strings.HasPrefix(shapeID, "s_") || len(shapeID) != 66 {
+				t.Errorf("ShapeID should be s_ + 64 hex chars, got: %s", shapeID)
+			}
+		})
+	}
+}
+
+type MutationVector struct {
+	Name               string         `json:"name"`
+	Mutation           types.Mutation `json:"mutation"`
+	ExpectedCanonical  string         `json:"expectedCanonical"`
+	ExpectedMutationID string         `json:"expectedMutationId"`
+}
+
+func TestConformanceMutations(t *testing.T) {
+	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "mutations.json")
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors []MutationVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			canonical, err := tests.CanonicalizeMutation(&v.Mutation)
+			if err != nil {
+				t.Fatalf("Canonicalization failed: %v", err)
+			}
+			if canonical != v.ExpectedCanonical {
+				t.Errorf("Canonical JSON mismatch:\n  got:  %s\n  want: %s", canonical, v.ExpectedCanonical)
+			}
+
+			id, err := tests.ComputeMutationID(&v.Mutation)
 			if err != nil {
-				t.Errorf("Canonicalization failed: %v", err)
+				t.Fatalf("ComputeMutationID failed: %v", err)
+			}
+			if !strings.HasPrefix(id, "m_") || len(id) != 66 {
+				t.Errorf("MutationID should be m_ + 64 hex chars, got: %s", id)
 			}
+		})
+	}
+}
+
+type DependenciesVector struct {
+	Name              string             `json:"name"`
+	Dependencies      types.Dependencies `json:"dependencies"`
+	ExpectedCanonical string             `json:"expectedCanonical"`
+}
 
-			// CRITICAL: Compare against expected canonical JSON
+func TestConformanceDependencies(t *testing.T) {
+	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "dependencies.json")
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors []DependenciesVector
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			canonical, err := tests.CanonicalizeDependencies(&v.Dependencies)
+			if err != nil {
+				t.Fatalf("Canonicalization failed: %v", err)
+			}
 			if canonical != v.ExpectedCanonical {
-				t.Errorf("Canonical JSON mismatch for %s:\n  got:  %s\n  want: %s",
-					v.Name, canonical, v.ExpectedCanonical)
+				t.Errorf("Canonical JSON mismatch:\n  got:  %s\n  want: %s", canonical, v.ExpectedCanonical)
 			}
+		})
+	}
+}
 
-			// Compute shapeId
-			shapeID := tests.ComputeShapeID(canonical)
+// TestConformanceJCSNumbers canonicalizes the number vectors as float64s,
+// including those without a JSON form that TestConformance skips.
+func TestConformanceJCSNumbers(t *testing.T) {
+	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "jcs.json")
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors struct {
+		Numbers []struct {
+			IEEE     string `json:"ieee"`
+			Expected string `json:"expected"`
+			Error    bool   `json:"error"`
+		} `json:"numbers"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, n := range vectors.Numbers {
+		t.Run("number-"+n.IEEE, func(t *testing.T) {
+			bits, err := hex.DecodeString(n.IEEE)
+			if err != nil {
+				t.Fatalf("Bad IEEE hex: %v", err)
+			}
+			var u uint64
+			for _, b := range bits {
+				u = u<<8 | uint64(b)
+			}
 
-			// CRITICAL: Compare against expected shapeId
-			if shapeID != v.ExpectedShapeID {
-				t.Errorf("ShapeID mismatch for %s:\n  got:  %s\n  want: %s",
-					v.Name, shapeID, v.ExpectedShapeID)
+			canonical, err := tests.Canonicalize(math.Float64frombits(u))
+			if n.Error {
+				if err == nil {
+					t.Errorf("Canonicalize should reject %s, got %s", n.IEEE, canonical)
+				}
+				return
 			}
+			if err != nil {
+				t.Fatalf("Canonicalization failed: %v", err)
+			}
+			if canonical != n.Expected {
+				t.Errorf("got %s, want %s", canonical, n.Expected)
+			}
+		})
+	}
+}
+
+type RecordIDVectors struct {
+	RecordIDs []struct {
+		Name     string `json:"name"`
+		Key      []any  `json:"key"`
+		Expected string `json:"expected"`
+		Error    bool   `json:"error"`
+	} `json:"recordIds"`
+}
+
+// TestConformanceRecordIDs checks that RecordIDParts splits the vector IDs
+// back into their parts.
+func TestConformanceRecordIDs(t *testing.T) {
+	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "record-ids.json")
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors RecordIDVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, v := range vectors.RecordIDs {
+		t.Run(v.Name, func(t *testing.T) {
+			if v.Error {
+				return
+			}
+			id, err := types.RecordID(v.Key...)
+			if err != nil {
+				t.Fatalf("RecordID failed: %v", err)
+			}
+
+			parts, err := types.RecordIDParts(id, len(v.Key))
+			if err != nil {
+				t.Fatalf("RecordIDParts failed: %v", err)
+			}
+			for i, part := range parts {
+				if want, _ := types.RecordID(v.Key[i]); part != want {
+					t.Errorf("part %d = %s, want %s", i, part, want)
+				}
+			}
+		})
+	}
+}
+
+type PaginationBoundaryVectors struct {
+	Boundaries []struct {
+		Name     string                   `json:"name"`
+		LastRow  types.PaginationBoundary `json:"lastRow"`
+		Row      map[string]any           `json:"row"`
+		Expected string                   `json:"expected"`
+	} `json:"boundaries"`
+	Changes []struct {
+		Name       string                   `json:"name"`
+		LastRow    types.PaginationBoundary `json:"lastRow"`
+		Change     types.Change             `json:"change"`
+		Invalidate bool                     `json:"invalidate"`
+	} `json:"changes"`
+}
+
+func TestConformancePaginationBoundaries(t *testing.T) {
+	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "pagination-boundaries.json")
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors PaginationBoundaryVectors
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
 
-			// Basic format checks
-			if !strings.HasPrefix(shapeID, "s_") {
-				t.Errorf("ShapeID should start with s_, got: %s", shapeID)
+	places := map[int]string{-1: "before", 0: "at", 1: "after"}
+	for _, v := range vectors.Boundaries {
+		t.Run(v.Name, func(t *testing.T) {
+			got := "unknown"
+			if cmp, ok := pagination.CompareBoundary(&v.LastRow, v.Row); ok {
+				got = places[cmp]
 			}
-			if len(shapeID) != 66 {
-				t.Errorf("ShapeID should be 66 chars, got: %d", len(shapeID))
+			if got != v.Expected {
+				t.Errorf("got %s, want %s", got, v.Expected)
 			}
+		})
+	}
+	for _, v := range vectors.Changes {
+		t.Run(v.Name, func(t *testing.T) {
+			if got := invalidate.CrossesBoundary(&v.LastRow, v.Change); got != v.Invalidate {
+				t.Errorf("CrossesBoundary() = %v, want %v", got, v.Invalidate)
+			}
+		})
+	}
+}
 
-			// Verify determinism
-			canonical2, _ := tests.CanonicalizeQueryShape(&v.Shape)
-			if canonical != canonical2 {
-				t.Error("Canonicalization should be deterministic")
+// TestConformancePaginationCursors checks that pagination.Boundary builds the
+// vectors' last_row and that both cursors decode back to the boundary.
+func TestConformancePaginationCursors(t *testing.T) {
+	vectorsPath := filepath.Join("..", "..", "..", "tools", "tests", "vectors", "pagination-cursors.json")
+	data, err := os.ReadFile(vectorsPath)
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+
+	var vectors []struct {
+		Name         string             `json:"name"`
+		Row          map[string]any     `json:"row"`
+		Dependencies types.Dependencies `json:"dependencies"`
+		Cursor       string             `json:"expectedCursor"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			want := v.Dependencies.LastRow
+			boundary, err := pagination.Boundary(want.OrderBy, v.Row)
+			if err != nil {
+				t.Fatalf("Boundary failed: %v", err)
+			}
+			if !reflect.DeepEqual(boundary, want) {
+				t.Errorf("Boundary() = %+v, want %+v", boundary, want)
 			}
 
-			shapeID2 := tests.ComputeShapeID(canonical2)
-			if shapeID != shapeID2 {
-				t.Error("ShapeID should be deterministic")
+			values, err := cursors.DecodeValues(want.OrderBy, v.Cursor)
+			if err != nil {
+				t.Fatalf("DecodeValues failed: %v", err)
+			}
+			row := make(map[string]any, len(values))
+			for i, ob := range want.OrderBy {
+				row[ob.Field] = values[i]
+			}
+			if cmp, ok := pagination.CompareBoundary(want, row); !ok || cmp != 0 {
+				t.Errorf("cursor values %v do not tie with the boundary", values)
 			}
 		})
 	}
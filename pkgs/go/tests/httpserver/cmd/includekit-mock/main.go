@@ -0,0 +1,32 @@
+// Command includekit-mock serves the mock engine over HTTP for conformance
+// tests in other languages:
+//
+//	go run github.com/bold-minds/includekit-spec/go/tests/httpserver/cmd/includekit-mock -addr :8787
+package main
+
+import (
+	"flag"
+	"log"
+	"net"
+	"net/http"
+
+	"github.com/bold-minds/includekit-spec/go/tests/httpserver"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+)
+
+func main() {
+	addr := flag.String("addr", "127.0.0.1:8787", "Address to listen on")
+	strict := flag.Bool("strict", false, "Reject input outside the pure spec subset")
+	idField := flag.String("id-field", "id", "Record ID field of models the schema gives no key")
+	flag.Parse()
+
+	engine := mock.NewMockEngine(mock.MockEngineConfig{Strict: *strict, IDField: *idField})
+	server := httpserver.New(engine, httpserver.Config{Strict: *strict})
+
+	ln, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("includekit mock engine listening on http://%s", ln.Addr())
+	log.Fatal(http.Serve(ln, server))
+}
@@ -0,0 +1,202 @@
+// Package httpserver serves an Engine over HTTP, so SDKs in other languages
+// can run conformance tests against the mock engine over the network.
+//
+// Requests and responses use the wire format; responses are canonical JSON
+// (JCS), so they can be compared byte for byte:
+//
+//	POST /shape-id                Statement        -> ShapeIDResponse
+//	POST /add-query               AddQueryRequest  -> AddQueryResponse
+//	POST /invalidate              Mutation         -> InvalidateResponse
+//	POST /invalidate-transaction  Transaction      -> InvalidateResponse
+//	POST /explain                 ExplainRequest   -> ExplainResponse
+//	POST /schema                  AppSchema        -> 204 No Content
+//	POST /reset                                    -> 204 No Content
+//	GET  /version                                  -> VersionInfo
+//
+// Errors are a JSON object {"error": "..."} with status 400 for bodies that
+// do not decode, that the engine rejects or that are over a limit of the
+// limits.Guard, 413 for bodies over its MaxPayloadBytes and 503 for faults
+// injected into the mock (see mock.Faults). Errors from the validators also
+// carry their tests.ErrorCode as "code", and limit errors carry
+// tests.CodeLimitExceeded as the validators report them.
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/bold-minds/includekit-spec/go/limits"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Config configures the server.
+type Config struct {
+	// Strict rejects request bodies with unknown fields. Pair it with
+	// MockEngineConfig.Strict to reject everything outside the pure spec
+	// subset (see tests.Strict).
+	Strict bool
+	// Limits bounds request bodies and the statements, mutations and
+	// transactions in them (default: limits.Default).
+	Limits *limits.Guard
+}
+
+// Server is an http.Handler serving an Engine.
+type Server struct {
+	engine mock.Engine
+	config Config
+	guard  limits.Guard
+	mux    *http.ServeMux
+}
+
+// New returns a Server for engine.
+func New(engine mock.Engine, config Config) *Server {
+	s := &Server{engine: engine, config: config, guard: limits.Default, mux: http.NewServeMux()}
+	if config.Limits != nil {
+		s.guard = *config.Limits
+	}
+
+	s.mux.HandleFunc("POST /shape-id", func(w http.ResponseWriter, r *http.Request) {
+		var stmt types.Statement
+		if s.decode(w, r, &stmt) {
+			resp, err := s.engine.ComputeShapeID(stmt)
+			s.respond(w, resp, err)
+		}
+	})
+	s.mux.HandleFunc("POST /add-query", func(w http.ResponseWriter, r *http.Request) {
+		var req mock.AddQueryRequest
+		if s.decode(w, r, &req) {
+			resp, err := s.engine.AddQuery(req)
+			s.respond(w, resp, err)
+		}
+	})
+	s.mux.HandleFunc("POST /invalidate", func(w http.ResponseWriter, r *http.Request) {
+		var mutation types.Mutation
+		if s.decode(w, r, &mutation) {
+			resp, err := s.engine.Invalidate(mutation)
+			s.respond(w, resp, err)
+		}
+	})
+	s.mux.HandleFunc("POST /invalidate-transaction", func(w http.ResponseWriter, r *http.Request) {
+		var tx types.Transaction
+		if s.decode(w, r, &tx) {
+			resp, err := s.engine.InvalidateTransaction(tx)
+			s.respond(w, resp, err)
+		}
+	})
+	s.mux.HandleFunc("POST /explain", func(w http.ResponseWriter, r *http.Request) {
+		var req mock.ExplainRequest
+		if s.decode(w, r, &req) {
+			resp, err := s.engine.ExplainInvalidation(req)
+			s.respond(w, resp, err)
+		}
+	})
+	s.mux.HandleFunc("POST /schema", func(w http.ResponseWriter, r *http.Request) {
+		var schema mock.AppSchema
+		if s.decode(w, r, &schema) {
+			s.respond(w, nil, s.engine.SetSchema(schema))
+		}
+	})
+	s.mux.HandleFunc("POST /reset", func(w http.ResponseWriter, r *http.Request) {
+		s.engine.Reset()
+		s.respond(w, nil, nil)
+	})
+	s.mux.HandleFunc("GET /version", func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, s.engine.GetVersion(), nil)
+	})
+	return s
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// decode reads the request body into v and checks it against the guard,
+// writing an error response and returning false if it cannot.
+func (s *Server) decode(w http.ResponseWriter, r *http.Request, v any) bool {
+	data, err := io.ReadAll(s.guard.Reader(r.Body, "request"))
+	if err != nil {
+		status := http.StatusBadRequest
+		var lerr *limits.LimitError
+		if errors.As(err, &lerr) && lerr.Limit == "payload_bytes" {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return false
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if s.config.Strict {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(v); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid JSON: %w", err))
+		return false
+	}
+	if dec.More() {
+		writeError(w, http.StatusBadRequest, errors.New("invalid JSON: unexpected data after the body"))
+		return false
+	}
+
+	switch v := v.(type) {
+	case *types.Statement:
+		err = s.guard.CheckStatement(v)
+	case *mock.AddQueryRequest:
+		err = s.guard.CheckStatement(&v.Shape)
+	case *types.Mutation:
+		err = s.guard.CheckMutation(v)
+	case *types.Transaction:
+		err = s.guard.CheckTransaction(v)
+	case *mock.ExplainRequest:
+		err = s.guard.CheckMutation(&v.Mutation)
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return false
+	}
+	return true
+}
+
+// respond writes resp as canonical JSON, no content when resp is nil, or
+// the engine error.
+func (s *Server) respond(w http.ResponseWriter, resp any, err error) {
+	if err != nil {
+		status := http.StatusBadRequest
+		if errors.Is(err, mock.ErrInjected) {
+			status = http.StatusServiceUnavailable
+		}
+		writeError(w, status, err)
+		return
+	}
+	if resp == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	body, err := tests.Canonicalize(resp)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	io.WriteString(w, body)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := map[string]string{"error": err.Error()}
+	var verr *tests.ValidationError
+	if errors.As(err, &verr) && verr.Code != "" {
+		resp["code"] = string(verr.Code)
+	} else if errors.Is(err, limits.ErrLimitExceeded) {
+		resp["code"] = string(tests.CodeLimitExceeded)
+	}
+	body, _ := json.Marshal(resp)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
@@ -0,0 +1,143 @@
+package httpserver_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/limits"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/httpserver"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+)
+
+func post(t *testing.T, srv *httptest.Server, path, body string) (int, string) {
+	t.Helper()
+	resp, err := http.Post(srv.URL+path, "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data)
+}
+
+func TestServer(t *testing.T) {
+	srv := httptest.NewServer(httpserver.New(mock.NewMockEngine(mock.MockEngineConfig{}), httpserver.Config{}))
+	defer srv.Close()
+
+	stmt := `{"query": {"model": "posts", "where": {"conditions": [{"field": "published", "op": "eq", "value": true}]}}}`
+	parsed, err := tests.DecodeStatement(strings.NewReader(stmt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantID, err := tests.ComputeQueryShapeID(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	status, body := post(t, srv, "/shape-id", stmt)
+	if want := `{"shape_id":"` + wantID + `"}`; status != http.StatusOK || body != want {
+		t.Fatalf("/shape-id = %d %s, want %s", status, body, want)
+	}
+
+	status, body = post(t, srv, "/add-query", `{"shape": `+stmt+`, "result_hint": {"posts": [{"id": "1"}]}}`)
+	var added mock.AddQueryResponse
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &added) != nil || added.ShapeID != wantID {
+		t.Fatalf("/add-query = %d %s", status, body)
+	}
+	if canonical, _ := tests.Canonicalize(added); body != canonical {
+		t.Errorf("/add-query body is not canonical:\n%s\nwant\n%s", body, canonical)
+	}
+
+	mutation := `{"changes": [{"model": "posts", "action": "update", "sets": [{"field": "title", "value": "x"}], "where": {"conditions": [{"field": "id", "op": "eq", "value": "1"}]}}]}`
+	status, body = post(t, srv, "/explain", `{"shape_id": "`+wantID+`", "mutation": `+mutation+`}`)
+	var explained mock.ExplainResponse
+	if status != http.StatusOK || json.Unmarshal([]byte(body), &explained) != nil || !explained.Invalidate {
+		t.Errorf("/explain = %d %s", status, body)
+	}
+
+	status, body = post(t, srv, "/invalidate", mutation)
+	if want := `{"evict":["` + wantID + `"]}`; status != http.StatusOK || body != want {
+		t.Errorf("/invalidate = %d %s, want %s", status, body, want)
+	}
+
+	status, body = post(t, srv, "/invalidate-transaction", `{"seq": 1, "mutations": [`+mutation+`, `+mutation+`]}`)
+	if want := `{"evict":["` + wantID + `"]}`; status != http.StatusOK || body != want {
+		t.Errorf("/invalidate-transaction = %d %s, want %s", status, body, want)
+	}
+	if status, _ = post(t, srv, "/invalidate-transaction", `{"seq": 1, "mutations": []}`); status != http.StatusBadRequest {
+		t.Errorf("/invalidate-transaction replay = %d, want 400", status)
+	}
+
+	status, _ = post(t, srv, "/schema", `{"version": 1, "models": [{"name": "posts", "id": {"kind": "string"}}]}`)
+	if status != http.StatusNoContent {
+		t.Errorf("/schema = %d, want 204", status)
+	}
+	if status, _ = post(t, srv, "/reset", ""); status != http.StatusNoContent {
+		t.Errorf("/reset = %d, want 204", status)
+	}
+
+	resp, err := http.Get(srv.URL + "/version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var version mock.VersionInfo
+	if err := json.NewDecoder(resp.Body).Decode(&version); err != nil || version.Contract == "" {
+		t.Errorf("/version = %+v, %v", version, err)
+	}
+}
+
+func TestServerErrors(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{
+		Strict: true,
+		Faults: &mock.Faults{Failures: []mock.Failure{{Method: "Invalidate", Call: 1}}},
+	})
+	srv := httptest.NewServer(httpserver.New(engine, httpserver.Config{Strict: true, Limits: &limits.Guard{MaxPayloadBytes: 256, MaxDepth: 2}}))
+	defer srv.Close()
+
+	tcs := []struct {
+		name   string
+		path   string
+		body   string
+		status int
+		code   tests.ErrorCode
+	}{
+		{"malformed", "/shape-id", `{"query":`, http.StatusBadRequest, ""},
+		{"trailing data", "/shape-id", `{"query": {"model": "posts"}} {}`, http.StatusBadRequest, ""},
+		{"unknown field", "/shape-id", `{"query": {"model": "posts"}, "extra": 1}`, http.StatusBadRequest, ""},
+		{"rejected by the engine", "/shape-id", `{"query": {"model": ""}}`, http.StatusBadRequest, tests.CodeEmptyModel},
+		{"too large", "/shape-id", `{"query": {"model": "` + strings.Repeat("x", 256) + `"}}`, http.StatusRequestEntityTooLarge, tests.CodeLimitExceeded},
+		{"too deep", "/shape-id", `{"query": {"model": "posts", "where": {"not": {"not": {}}}}}`, http.StatusBadRequest, tests.CodeLimitExceeded},
+		{"too deep in a transaction", "/invalidate-transaction", `{"seq": 1, "mutations": [{"changes": [{"model": "posts", "action": "delete", "where": {"not": {"not": {}}}}]}]}`, http.StatusBadRequest, tests.CodeLimitExceeded},
+		{"injected fault", "/invalidate", `{"changes": [{"model": "posts", "action": "insert"}]}`, http.StatusServiceUnavailable, ""},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, srv, tt.path, tt.body)
+			var e struct{ Error, Code string }
+			if status != tt.status || json.Unmarshal([]byte(body), &e) != nil || e.Error == "" {
+				t.Errorf("%s = %d %s, want %d with an error", tt.path, status, body, tt.status)
+			}
+			if e.Code != string(tt.code) {
+				t.Errorf("%s code = %q, want %q", tt.path, e.Code, tt.code)
+			}
+		})
+	}
+
+	resp, err := http.Get(srv.URL + "/shape-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /shape-id = %d, want 405", resp.StatusCode)
+	}
+}
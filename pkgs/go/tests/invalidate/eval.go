@@ -0,0 +1,181 @@
+package invalidate
+
+import (
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// tri is a three-valued truth value, ordered so that and is min and or is
+// max.
+type tri int8
+
+const (
+	no tri = iota
+	maybe
+	yes
+)
+
+func and(a, b tri) tri {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func or(a, b tri) tri {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func not(a tri) tri {
+	return yes - a
+}
+
+func truth(b bool) tri {
+	if b {
+		return yes
+	}
+	return no
+}
+
+// maxPinnedRows bounds the rows pinnedRows expands in lists into; past it,
+// further fields are left unpinned.
+const maxPinnedRows = 64
+
+// matchKey evaluates where against a tracked record, knowing only its key
+// field values as record ID parts.
+func matchKey(f *types.Filter, key, parts []string) tri {
+	row := make(map[string]any, len(key))
+	for i, field := range key {
+		row[field] = parts[i]
+	}
+	var match func(f *types.Filter) tri
+	match = func(f *types.Filter) tri {
+		if f == nil {
+			return yes
+		}
+		result := yes
+		for _, c := range f.GetConditions() {
+			result = and(result, matchKeyCondition(c, row))
+		}
+		for i := range f.GetAnd() {
+			result = and(result, match(&(*f.And)[i]))
+		}
+		if branches := f.GetOr(); len(branches) > 0 {
+			some := no
+			for i := range branches {
+				some = or(some, match(&branches[i]))
+			}
+			result = and(result, some)
+		}
+		if f.Not != nil {
+			result = and(result, not(match(f.Not)))
+		}
+		return result
+	}
+	return match(f)
+}
+
+// matchKeyCondition compares eq, ne, in and notIn conditions on a key field
+// as record IDs, the strings records are tracked as.
+func matchKeyCondition(c types.Condition, row map[string]any) tri {
+	part, ok := row[c.Field]
+	if !ok || len(c.FieldPath) > 0 || c.Subquery != nil {
+		return maybe
+	}
+	value, err := types.Value(c.Value)
+	if err != nil {
+		return maybe
+	}
+	list, isList := value.([]any)
+	if !isList {
+		list = []any{value}
+	}
+	found := no
+	for _, v := range list {
+		id, err := types.RecordID(v)
+		if err != nil {
+			return maybe
+		}
+		found = or(found, truth(id == part))
+	}
+	switch c.Op {
+	case "eq", "in":
+		return found
+	case "ne", "notIn":
+		return not(found)
+	}
+	return maybe
+}
+
+// pinnedRows returns partial rows covering every row where can select, from
+// the eq and in conditions it requires, expanding in lists into one row per
+// value. A where that pins nothing gives one empty row.
+func pinnedRows(where *types.Filter) []map[string]any {
+	rows := []map[string]any{{}}
+	var pin func(f *types.Filter)
+	pin = func(f *types.Filter) {
+		if f == nil {
+			return
+		}
+		for _, c := range f.GetConditions() {
+			if len(c.FieldPath) > 0 || c.Subquery != nil || (c.Op != "eq" && c.Op != "in") {
+				continue
+			}
+			if _, pinned := rows[0][c.Field]; pinned {
+				continue
+			}
+			value, err := types.Value(c.Value)
+			if err != nil {
+				continue
+			}
+			values := []any{value}
+			if c.Op == "in" {
+				list, ok := value.([]any)
+				if !ok || len(list) == 0 || len(rows)*len(list) > maxPinnedRows {
+					continue
+				}
+				values = list
+			}
+			var next []map[string]any
+			for _, row := range rows {
+				for _, v := range values {
+					next = append(next, overlay(row, map[string]any{c.Field: v}))
+				}
+			}
+			rows = next
+		}
+		for i := range f.GetAnd() {
+			pin(&(*f.And)[i])
+		}
+	}
+	pin(where)
+	return rows
+}
+
+// testsAny reports whether bound tests a field of row, or anything this
+// package cannot attribute to fields.
+func testsAny(bound *types.Filter, row map[string]any) bool {
+	if bound == nil {
+		return false
+	}
+	for _, c := range bound.GetConditions() {
+		if _, ok := row[c.Field]; ok || c.Subquery != nil || strings.HasPrefix(c.Op, "custom:") {
+			return true
+		}
+	}
+	for i := range bound.GetAnd() {
+		if testsAny(&(*bound.And)[i], row) {
+			return true
+		}
+	}
+	for i := range bound.GetOr() {
+		if testsAny(&(*bound.Or)[i], row) {
+			return true
+		}
+	}
+	return testsAny(bound.Not, row)
+}
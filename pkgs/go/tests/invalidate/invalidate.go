@@ -0,0 +1,515 @@
+// Package invalidate is the reference implementation of the conservative
+// invalidation rules of the IncludeKit Universal Format, as pure functions
+// over Dependencies and Mutations. Engines can be conformance-tested against
+// it: an engine may evict more than Evict reports, never less.
+//
+// A mutation of one tenant never invalidates a shape of another: when both
+// Mutation.Tenant and Dependencies.Tenant are set and differ, no rule is
+// checked (see OtherTenant). A change to a model the shape does not read
+// never invalidates it either. The shape reads the root model, the models
+// listed in Dependencies.Records and the targets of its includes. A change to
+// one of them is checked against each rule, and the shape is invalidated if
+// any rule fires:
+//
+//   - record_membership: an update or delete whose where may select a
+//     tracked record, or an insert that sets a tracked key. An update or
+//     delete of an untracked root model always fires, since the rows it
+//     returned are unknown.
+//   - filter_bounds: an insert whose row may satisfy a filter, a delete whose
+//     where may select rows within it, or an update that sets a field the
+//     filter tests on a row that may be within it before or after. A shape
+//     with no filters is unbounded: every insert into a model it tracks, or
+//     into the root model, fires.
+//   - relation_bounds: an insert into an included model whose row may
+//     satisfy the include's where, or an update or delete whose where may
+//     select rows within it (or, for updates, move rows into it).
+//   - pagination_boundary: an insert into the root model of a row that may
+//     sort at or before Dependencies.LastRow, a delete whose where may select
+//     rows at or before it, or an update that sets an order_by field of such
+//     rows or moves rows there (see CrossesBoundary).
+//   - group_by: an insert into the root model of a row that may start a group
+//     missing from Dependencies.GroupBy, an update that sets a group key, or
+//     a delete whose where may select rows of a listed group (see
+//     ChangesGroups).
+//   - window: an update of the root model that sets one of
+//     Dependencies.WindowFields, which may reorder or repartition the rows
+//     window columns are computed over (see ChangesWindows). Inserts and
+//     deletes are left to filter_bounds.
+//   - soft_delete: an update of the root model that sets
+//     Dependencies.SoftDeleteField, soft-deleting or restoring rows as a
+//     delete or insert would (see SoftDeletes), unless its where rules out
+//     the rows of every filter before and after the update. Without the
+//     field in Dependencies, such an update is an ordinary one.
+//   - policy: a change to the root model that may move a row into or out of
+//     Dependencies.Policy, such as an update setting a field the policy
+//     tests (see CrossesPolicy). The policy is tracked apart from filters,
+//     which hold the root query's own where.
+//
+// A changed policy is not a mutation: the engine evicts every shape recorded
+// under the old one (see EvictPolicy) and reads anew under the new one.
+//
+// Bounds are checked with tests.FilterIntersectsChange against what a change
+// tells about a row: the values an insert or update sets, and those its where
+// pins with eq and in. Whatever cannot be decided that way (fields the change
+// does not pin, custom: operators, subqueries) counts as a possible match, so
+// the rules fire.
+package invalidate
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Rule names an invalidation rule.
+type Rule string
+
+const (
+	RuleRecordMembership   Rule = "record_membership"
+	RuleFilterBounds       Rule = "filter_bounds"
+	RuleRelationBounds     Rule = "relation_bounds"
+	RulePaginationBoundary Rule = "pagination_boundary"
+	RuleGroupBy            Rule = "group_by"
+	RuleWindow             Rule = "window"
+	RuleSoftDelete         Rule = "soft_delete"
+	RulePolicy             Rule = "policy"
+)
+
+// Shape is a cached read as the algorithm sees it: the model its root query
+// reads and the Dependencies the engine recorded for it. Model may be empty
+// when unknown; the root-model rules then apply to every tracked model.
+type Shape struct {
+	Model        string
+	Dependencies types.Dependencies
+}
+
+// Reason is one rule firing for one change. Path locates the bound within the
+// Dependencies (e.g. "filters[0]", "includes[0].includes[1]", "last_row",
+// "group_by", "window_fields", "soft_delete_field", "policy"); RecordIDs
+// lists the tracked records a record_membership change may touch: those its
+// where does not rule out by their key values.
+type Reason struct {
+	ChangeIndex int      `json:"change_index"`
+	Rule        Rule     `json:"rule"`
+	Model       string   `json:"model"`
+	Path        string   `json:"path,omitempty"`
+	RecordIDs   []string `json:"record_ids,omitempty"`
+}
+
+// Schema resolves relations and primary keys; mock.AppSchema implements it.
+type Schema interface {
+	RelationTarget(model, relation string) (target string, many bool, ok bool)
+	KeyFields(model string) []string
+}
+
+// Option configures the algorithm.
+type Option func(*options)
+
+type options struct {
+	schema Schema
+}
+
+// WithSchema resolves included relations to their target models and records
+// to their key fields through schema. Without one, an include's relation name
+// stands in for its model and every model is keyed by "id".
+func WithSchema(schema Schema) Option {
+	return func(o *options) { o.schema = schema }
+}
+
+func newOptions(opts []Option) options {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Explain returns every rule each change of m fires for shape, in change
+// order and, per change, in the order of the rules above.
+func Explain(shape Shape, m types.Mutation, opts ...Option) []Reason {
+	if OtherTenant(shape.Dependencies, m) {
+		return nil
+	}
+	o := newOptions(opts)
+	var reasons []Reason
+	for i, change := range m.Changes {
+		reasons = append(reasons, o.explainChange(shape, i, change)...)
+	}
+	return reasons
+}
+
+// Affects reports whether any change of m invalidates shape.
+func Affects(shape Shape, m types.Mutation, opts ...Option) bool {
+	if OtherTenant(shape.Dependencies, m) {
+		return false
+	}
+	o := newOptions(opts)
+	for i, change := range m.Changes {
+		if len(o.explainChange(shape, i, change)) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Evict returns the sorted IDs of the shapes m invalidates.
+func Evict(shapes map[string]Shape, m types.Mutation, opts ...Option) []string {
+	evict := []string{}
+	for id, shape := range shapes {
+		if Affects(shape, m, opts...) {
+			evict = append(evict, id)
+		}
+	}
+	sort.Strings(evict)
+	return evict
+}
+
+// EvictPolicy returns the sorted IDs of the shapes recorded under policy,
+// which an engine evicts when the policy changes, e.g. when a role loses
+// access. Policies are compared with types.Filter.Equal.
+func EvictPolicy(shapes map[string]Shape, policy types.Filter) []string {
+	evict := []string{}
+	for id, shape := range shapes {
+		if shape.Dependencies.Policy.Equal(&policy) {
+			evict = append(evict, id)
+		}
+	}
+	sort.Strings(evict)
+	return evict
+}
+
+// OtherTenant reports whether m writes the rows of a tenant other than the
+// one deps were recorded for. A mutation or shape without a tenant may
+// concern every tenant, so it is never another's.
+func OtherTenant(deps types.Dependencies, m types.Mutation) bool {
+	shapeTenant, scoped := deps.GetTenant()
+	tenant, ok := m.GetTenant()
+	return scoped && ok && tenant != shapeTenant
+}
+
+func (o options) explainChange(shape Shape, index int, change types.Change) []Reason {
+	deps := shape.Dependencies
+	model := change.Model
+	var reasons []Reason
+	fire := func(rule Rule, path string, ids []string) {
+		reasons = append(reasons, Reason{ChangeIndex: index, Rule: rule, Model: model, Path: path, RecordIDs: ids})
+	}
+
+	tracked, isTracked := deps.Records[model]
+	isRoot := model == shape.Model || (shape.Model == "" && isTracked)
+	sets := setRow(change)
+
+	// record_membership
+	if isTracked {
+		if ids, ok := o.touchedRecords(change, tracked); ok {
+			fire(RuleRecordMembership, "", ids)
+		}
+	} else if isRoot && change.Action != "insert" {
+		fire(RuleRecordMembership, "", nil)
+	}
+
+	// filter_bounds
+	if isTracked || isRoot {
+		if len(deps.Filters) == 0 {
+			if change.Action == "insert" {
+				fire(RuleFilterBounds, "filters", nil)
+			}
+		}
+		for i := range deps.Filters {
+			if crosses(&deps.Filters[i], change, sets, false) {
+				fire(RuleFilterBounds, fmt.Sprintf("filters[%d]", i), nil)
+			}
+		}
+	}
+
+	// relation_bounds
+	for _, inc := range o.includedModels(shape) {
+		if inc.model == model && crosses(inc.where, change, sets, true) {
+			fire(RuleRelationBounds, inc.path, nil)
+		}
+	}
+
+	if !isRoot {
+		return reasons
+	}
+
+	// pagination_boundary
+	if lr := deps.LastRow; lr != nil && CrossesBoundary(lr, change) {
+		fire(RulePaginationBoundary, "last_row", nil)
+	}
+
+	// group_by
+	if gb := deps.GroupBy; gb != nil && ChangesGroups(gb, change) {
+		fire(RuleGroupBy, "group_by", nil)
+	}
+
+	// window
+	if ChangesWindows(deps.WindowFields, change) {
+		fire(RuleWindow, "window_fields", nil)
+	}
+
+	// soft_delete
+	if field, ok := deps.GetSoftDeleteField(); ok && SoftDeletes(field, change) && withinFilters(deps.Filters, change) {
+		fire(RuleSoftDelete, "soft_delete_field", nil)
+	}
+
+	// policy
+	if policy := deps.Policy; policy != nil && CrossesPolicy(policy, change) {
+		fire(RulePolicy, "policy", nil)
+	}
+
+	return reasons
+}
+
+// touchedRecords reports whether change may touch a tracked record, and the
+// tracked records it may touch unless an ID does not fit the model's key.
+func (o options) touchedRecords(change types.Change, tracked []string) ([]string, bool) {
+	key := o.keyFields(change.Model)
+	if change.Action == "insert" {
+		id, ok := recordID(setRow(change), key)
+		if !ok {
+			return nil, false
+		}
+		for _, t := range tracked {
+			if t == id {
+				return []string{id}, true
+			}
+		}
+		return nil, false
+	}
+
+	var ids []string
+	for _, id := range tracked {
+		parts, err := types.RecordIDParts(id, len(key))
+		if err != nil {
+			return nil, true
+		}
+		if matchKey(change.Where, key, parts) != no {
+			ids = append(ids, id)
+		}
+	}
+	return ids, len(ids) > 0
+}
+
+func (o options) keyFields(model string) []string {
+	if o.schema != nil {
+		if fields := o.schema.KeyFields(model); len(fields) > 0 {
+			return fields
+		}
+	}
+	return []string{"id"}
+}
+
+// included is a model a shape loads through the include at path, bounded
+// by the include's where.
+type included struct {
+	path  string
+	model string
+	where *types.Filter
+}
+
+func (o options) includedModels(shape Shape) []included {
+	var out []included
+	var visit func(parent string, includes []types.Include, path string)
+	visit = func(parent string, includes []types.Include, path string) {
+		for i, include := range includes {
+			if include.Query == nil {
+				continue
+			}
+			includePath := fmt.Sprintf("%sincludes[%d]", path, i)
+			model := include.Query.Model
+			if o.schema != nil {
+				if target, _, ok := o.schema.RelationTarget(parent, model); ok {
+					model = target
+				}
+			}
+			out = append(out, included{path: includePath, model: model, where: include.Query.Where})
+			visit(model, include.Includes, includePath+".")
+		}
+	}
+	visit(shape.Model, shape.Dependencies.Includes, "")
+	return out
+}
+
+// crosses reports whether a change may move a row into or out of bound, or,
+// with content set, change a row within it.
+func crosses(bound *types.Filter, change types.Change, sets map[string]any, content bool) bool {
+	if change.Action == "update" && !content && !testsAny(bound, sets) {
+		return false
+	}
+	var f types.Filter
+	if bound != nil {
+		f = *bound
+	}
+	return tests.FilterIntersectsChange(f, change)
+}
+
+// CrossesBoundary reports whether change may add a row at or before the
+// last row of a page, remove one, or move one past it: an insert whose row
+// may sort at or before lr, a delete whose where may select such rows, or an
+// update that sets an orderBy field of rows that may sort at or before lr,
+// before or after the update. Rows tying with lr on every orderBy field
+// count, since their place relative to it is unknown. change must be to the
+// model lr pages.
+func CrossesBoundary(lr *types.PaginationBoundary, change types.Change) bool {
+	sets := setRow(change)
+	atOrBefore := func(row map[string]any) bool {
+		cmp, ok := pagination.CompareBoundary(lr, row)
+		return !ok || cmp <= 0
+	}
+	if change.Action == "insert" {
+		return atOrBefore(sets)
+	}
+
+	pins := pinnedRows(change.Where)
+	if change.Action == "delete" {
+		return anyRow(pins, atOrBefore)
+	}
+	sorts := false
+	for _, ob := range lr.OrderBy {
+		if _, ok := sets[ob.Field]; ok {
+			sorts = true
+		}
+	}
+	return sorts && anyRow(pins, func(row map[string]any) bool {
+		return atOrBefore(row) || atOrBefore(overlay(row, sets))
+	})
+}
+
+// ChangesGroups reports whether change may add a group, move a row between
+// groups or empty a listed group: an insert whose row may start a group
+// missing from gb, an update that sets a group key, or a delete whose where
+// may select rows of a listed group. change must be to the grouped model.
+func ChangesGroups(gb *types.GroupByKV, change types.Change) bool {
+	switch change.Action {
+	case "insert":
+		return len(gb.Values) == 0 || tests.FilterIntersectsChange(types.Filter{Not: listedGroups(gb)}, change)
+	case "delete":
+		return len(gb.Values) > 0 && tests.FilterIntersectsChange(*listedGroups(gb), change)
+	}
+	for _, kv := range change.Sets {
+		for _, k := range gb.Keys {
+			if kv.Field == k {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ChangesWindows reports whether change, to the root model of a shape with
+// window columns, is an update setting one of fields, the fields its windows
+// partition by, order by or aggregate.
+func ChangesWindows(fields []string, change types.Change) bool {
+	if change.Action != "update" {
+		return false
+	}
+	for _, kv := range change.Sets {
+		for _, f := range fields {
+			if kv.Field == f {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// SoftDeletes reports whether change, to the root model of a shape that
+// leaves out soft-deleted rows or reads only them, is an update setting
+// field, the model's soft-delete field. Setting it removes rows from the
+// shape or restores them to it, whatever value it sets.
+func SoftDeletes(field string, change types.Change) bool {
+	if change.Action != "update" {
+		return false
+	}
+	for _, kv := range change.Sets {
+		if kv.Field == field {
+			return true
+		}
+	}
+	return false
+}
+
+// CrossesPolicy reports whether change, to the root model of a shape read
+// under policy, may move a row into or out of it: an insert whose row may
+// satisfy it, a delete whose where may select rows within it, or an update
+// that sets a field it tests on rows that may be within it before or after.
+func CrossesPolicy(policy *types.Filter, change types.Change) bool {
+	return crosses(policy, change, setRow(change), false)
+}
+
+// withinFilters reports whether change may touch a row within one of
+// filters, before or after it; every row is within no filters.
+func withinFilters(filters []types.Filter, change types.Change) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, f := range filters {
+		if tests.FilterIntersectsChange(f, change) {
+			return true
+		}
+	}
+	return false
+}
+
+// listedGroups returns the filter selecting the rows of the listed groups.
+func listedGroups(gb *types.GroupByKV) *types.Filter {
+	groups := make([]types.Filter, 0, len(gb.Values))
+	for _, values := range gb.Values {
+		conditions := make([]types.Condition, 0, len(gb.Keys))
+		for _, k := range gb.Keys {
+			if v := values[k]; v != nil {
+				conditions = append(conditions, types.Condition{Field: k, Op: "eq", Value: v})
+			} else {
+				conditions = append(conditions, types.Condition{Field: k, Op: "isNull", Value: true})
+			}
+		}
+		groups = append(groups, types.Filter{Conditions: &conditions})
+	}
+	return &types.Filter{Or: &groups}
+}
+
+// setRow returns the values a change sets, by field.
+func setRow(change types.Change) map[string]any {
+	row := make(map[string]any, len(change.Sets))
+	for _, kv := range change.Sets {
+		row[kv.Field] = kv.Value
+	}
+	return row
+}
+
+func recordID(row map[string]any, key []string) (string, bool) {
+	values := make([]any, len(key))
+	for i, field := range key {
+		v, ok := row[field]
+		if !ok {
+			return "", false
+		}
+		values[i] = v
+	}
+	id, err := types.RecordID(values...)
+	return id, err == nil
+}
+
+func overlay(row, sets map[string]any) map[string]any {
+	out := make(map[string]any, len(row)+len(sets))
+	for k, v := range row {
+		out[k] = v
+	}
+	for k, v := range sets {
+		out[k] = v
+	}
+	return out
+}
+
+func anyRow(rows []map[string]any, pred func(map[string]any) bool) bool {
+	for _, row := range rows {
+		if pred(row) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,517 @@
+package invalidate_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests/invalidate"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+var blogSchema = mock.AppSchema{
+	Version: 1,
+	Models: []mock.Model{
+		{Name: "User", ID: mock.IDConfig{Kind: "string"}, Relations: []mock.Relation{
+			{Name: "posts", Target: "Post", Kind: "many", Fields: []string{"id"}, References: []string{"authorId"}},
+		}},
+		{Name: "Post", ID: mock.IDConfig{Kind: "string"}, Relations: []mock.Relation{
+			{Name: "comments", Target: "Comment", Kind: "many"},
+		}},
+		{Name: "Comment", ID: mock.IDConfig{Kind: "string"}},
+		{Name: "OrderLine", ID: mock.IDConfig{Kind: "composite", Fields: []string{"orderId", "lineNo"}}},
+	},
+}
+
+func where(conds ...types.Condition) *types.Filter {
+	return &types.Filter{Conditions: &conds}
+}
+
+func insert(model string, sets ...types.KV) types.Change {
+	return types.Change{Model: model, Action: "insert", Sets: sets}
+}
+
+func update(model string, w *types.Filter, sets ...types.KV) types.Change {
+	return types.Change{Model: model, Action: "update", Sets: sets, Where: w}
+}
+
+func remove(model string, w *types.Filter) types.Change {
+	return types.Change{Model: model, Action: "delete", Where: w}
+}
+
+func kv(field string, value any) types.KV {
+	return types.KV{Field: field, Value: value}
+}
+
+// rules returns the rules a single change fires for shape, with their paths.
+func rules(shape invalidate.Shape, change types.Change) []string {
+	var out []string
+	for _, r := range invalidate.Explain(shape, types.Mutation{Changes: []types.Change{change}}, invalidate.WithSchema(blogSchema)) {
+		s := string(r.Rule)
+		if r.Path != "" {
+			s += " " + r.Path
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func TestRecordMembership(t *testing.T) {
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records:  map[string][]string{"Post": {"p1", "p2"}},
+		Filters:  []types.Filter{*where(types.Eq("published", true))},
+		Includes: []types.Include{},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		ids    []string
+	}{
+		{"update by id", update("Post", where(types.In("id", "p2", "p9")), kv("title", "x")), []string{"p2"}},
+		{"update of other ids", update("Post", where(types.Eq("id", "p9")), kv("title", "x")), nil},
+		{"delete by other field", remove("Post", where(types.Eq("title", "x"))), []string{"p1", "p2"}},
+		{"delete excluding an id", remove("Post", &types.Filter{Not: where(types.Eq("id", "p1"))}), []string{"p2"}},
+		{"insert of a tracked key", insert("Post", kv("id", "p1")), []string{"p1"}},
+		{"insert of a new key", insert("Post", kv("id", "p3")), nil},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			var ids []string
+			fired := false
+			for _, r := range invalidate.Explain(shape, types.Mutation{Changes: []types.Change{tt.change}}) {
+				if r.Rule == invalidate.RuleRecordMembership {
+					fired, ids = true, r.RecordIDs
+				}
+			}
+			if fired != (tt.ids != nil) || !reflect.DeepEqual(ids, tt.ids) {
+				t.Errorf("record_membership fired %v with %v, want %v", fired, ids, tt.ids)
+			}
+		})
+	}
+
+	// Composite keys compare part by part
+	lines := invalidate.Shape{Model: "OrderLine", Dependencies: types.Dependencies{
+		Records: map[string][]string{"OrderLine": {`["o1",1]`, `["o1",2]`, `["o2",1]`}},
+	}}
+	reasons := invalidate.Explain(lines, types.Mutation{Changes: []types.Change{
+		remove("OrderLine", where(types.Eq("orderId", "o1"), types.Eq("lineNo", 2))),
+	}}, invalidate.WithSchema(blogSchema))
+	if len(reasons) != 1 || !reflect.DeepEqual(reasons[0].RecordIDs, []string{`["o1",2]`}) {
+		t.Errorf("composite delete = %+v", reasons)
+	}
+
+	// An untracked root model cannot tell which rows it returned
+	untracked := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{Records: map[string][]string{}}}
+	if got := rules(untracked, update("Post", where(types.Eq("id", "p1")), kv("title", "x"))); !reflect.DeepEqual(got, []string{"record_membership"}) {
+		t.Errorf("untracked root update fired %v", got)
+	}
+}
+
+func TestFilterBounds(t *testing.T) {
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records: map[string][]string{"Post": {}},
+		Filters: []types.Filter{*where(types.Eq("status", "published"), types.Gte("views", 100))},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		fire   bool
+	}{
+		{"insert inside", insert("Post", kv("id", "p3"), kv("status", "published"), kv("views", 150)), true},
+		{"insert outside", insert("Post", kv("id", "p3"), kv("status", "draft"), kv("views", 150)), false},
+		{"insert below the bound", insert("Post", kv("id", "p3"), kv("status", "published"), kv("views", 10)), false},
+		{"insert with an unset field", insert("Post", kv("id", "p3"), kv("status", "published")), true},
+		{"update crossing the bound", update("Post", where(types.Eq("id", "p1")), kv("views", 101)), true},
+		{"update of an untested field", update("Post", where(types.Eq("id", "p1")), kv("title", "x")), false},
+		{"update of drafts to drafts", update("Post", where(types.Eq("status", "draft")), kv("status", "archived")), false},
+		{"update publishing drafts", update("Post", where(types.Eq("status", "draft")), kv("status", "published")), true},
+		{"delete inside", remove("Post", where(types.Eq("status", "published"))), true},
+		{"delete of other statuses", remove("Post", where(types.In("status", "draft", "archived"))), false},
+		{"delete of anything", remove("Post", nil), true},
+		{"other model", insert("Tag", kv("id", "t1")), false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := false
+			for _, r := range rules(shape, tt.change) {
+				fired = fired || r == "filter_bounds filters[0]"
+			}
+			if fired != tt.fire {
+				t.Errorf("filter_bounds fired %v, want %v", fired, tt.fire)
+			}
+		})
+	}
+
+	custom := shape
+	custom.Dependencies.Filters = []types.Filter{*where(types.Condition{Field: "body", Op: "custom:fts", Value: "go"})}
+	if got := rules(custom, insert("Post", kv("id", "p3"), kv("body", "rust"))); !reflect.DeepEqual(got, []string{"filter_bounds filters[0]"}) {
+		t.Errorf("custom: operators should fire conservatively, got %v", got)
+	}
+
+	unbounded := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{Records: map[string][]string{"Post": {}}}}
+	if got := rules(unbounded, insert("Post", kv("id", "p3"))); !reflect.DeepEqual(got, []string{"filter_bounds filters"}) {
+		t.Errorf("unfiltered insert fired %v", got)
+	}
+}
+
+func TestRelationBounds(t *testing.T) {
+	shape := invalidate.Shape{Model: "User", Dependencies: types.Dependencies{
+		Records: map[string][]string{"User": {"u1"}},
+		Includes: []types.Include{{
+			Query:    &types.Query{Model: "posts", Where: where(types.Eq("published", true))},
+			Includes: []types.Include{{Query: &types.Query{Model: "comments"}}},
+		}},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		want   []string
+	}{
+		{"insert into the include", insert("Post", kv("id", "p1"), kv("published", true)), []string{"relation_bounds includes[0]"}},
+		{"insert outside the include", insert("Post", kv("id", "p1"), kv("published", false)), nil},
+		{"update within the include", update("Post", where(types.Eq("id", "p1")), kv("title", "x")), []string{"relation_bounds includes[0]"}},
+		{"update of unpublished posts", update("Post", where(types.Eq("published", false)), kv("title", "x")), nil},
+		{"delete of a nested include", remove("Comment", where(types.Eq("id", "c1"))), []string{"relation_bounds includes[0].includes[0]"}},
+		{"relation name is not a model", insert("posts", kv("id", "p1")), nil},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules(shape, tt.change); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fired %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRelationCountBounds(t *testing.T) {
+	// Users with more than 5 published posts: any published post may be the
+	// one that crosses the threshold.
+	count := "count"
+	shape := invalidate.Shape{Model: "User", Dependencies: types.Dependencies{
+		Records: map[string][]string{"User": {"u1"}},
+		Includes: []types.Include{{
+			Query: &types.Query{Model: "posts", Where: where(types.Eq("published", true))},
+			Kind:  &count,
+			Count: &types.RelationCount{Op: "gt", Value: 5},
+		}},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		want   []string
+	}{
+		{"insert of the sixth post", insert("Post", kv("id", "p6"), kv("authorId", "u2"), kv("published", true)), []string{"relation_bounds includes[0]"}},
+		{"insert of a draft", insert("Post", kv("id", "p6"), kv("authorId", "u2"), kv("published", false)), nil},
+		{"post moved to another author", update("Post", where(types.Eq("id", "p1")), kv("authorId", "u2")), []string{"relation_bounds includes[0]"}},
+		{"delete of a post", remove("Post", where(types.Eq("id", "p1"))), []string{"relation_bounds includes[0]"}},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules(shape, tt.change); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("fired %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginationBoundary(t *testing.T) {
+	desc := true
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records: map[string][]string{"Post": {"p1", "p2"}},
+		Filters: []types.Filter{},
+		LastRow: &types.PaginationBoundary{
+			OrderBy: []types.OrderBy{{Field: "views", Descending: &desc}, {Field: "id"}},
+			Row:     map[string]any{"views": 100, "id": "p2"},
+		},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		fire   bool
+	}{
+		{"insert before the boundary", insert("Post", kv("id", "p3"), kv("views", 150)), true},
+		{"insert tying before", insert("Post", kv("id", "p0"), kv("views", 100)), true},
+		{"insert after the boundary", insert("Post", kv("id", "p3"), kv("views", 50)), false},
+		{"insert tying after", insert("Post", kv("id", "p3"), kv("views", 100)), false},
+		{"insert without order fields", insert("Post", kv("id", "p3")), true},
+		{"update of an order field", update("Post", where(types.Eq("id", "p9")), kv("views", 1)), true},
+		{"update of another field", update("Post", where(types.Eq("id", "p9")), kv("title", "x")), false},
+		{"delete after the boundary", remove("Post", where(types.Eq("views", 10), types.Eq("id", "p9"))), false},
+		{"delete of unknown rows", remove("Post", where(types.Eq("title", "x"))), true},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := false
+			for _, r := range rules(shape, tt.change) {
+				fired = fired || r == "pagination_boundary last_row"
+			}
+			if fired != tt.fire {
+				t.Errorf("pagination_boundary fired %v, want %v", fired, tt.fire)
+			}
+		})
+	}
+}
+
+func TestGroupBy(t *testing.T) {
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records: map[string][]string{},
+		GroupBy: &types.GroupByKV{Keys: []string{"authorId"}, Values: []map[string]any{{"authorId": "u1"}, {"authorId": "u2"}}},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		fire   bool
+	}{
+		{"insert into a listed group", insert("Post", kv("authorId", "u1")), false},
+		{"insert starting a group", insert("Post", kv("authorId", "u3")), true},
+		{"insert without the key", insert("Post", kv("title", "x")), true},
+		{"update moving groups", update("Post", where(types.Eq("id", "p1")), kv("authorId", "u2")), true},
+		{"update within a group", update("Post", where(types.Eq("id", "p1")), kv("title", "x")), false},
+		{"delete from a listed group", remove("Post", where(types.Eq("authorId", "u1"))), true},
+		{"delete from an unlisted group", remove("Post", where(types.Eq("authorId", "u9"))), false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := false
+			for _, r := range rules(shape, tt.change) {
+				fired = fired || r == "group_by group_by"
+			}
+			if fired != tt.fire {
+				t.Errorf("group_by fired %v, want %v", fired, tt.fire)
+			}
+		})
+	}
+}
+
+func TestWindow(t *testing.T) {
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records:      map[string][]string{"Post": {"p1"}},
+		WindowFields: []string{"authorId", "createdAt"},
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		fire   bool
+	}{
+		{"update moving partitions", update("Post", where(types.Eq("id", "p9")), kv("authorId", "u2")), true},
+		{"update reordering a partition", update("Post", where(types.Eq("id", "p9")), kv("createdAt", "2024-01-01")), true},
+		{"update of another field", update("Post", where(types.Eq("id", "p9")), kv("title", "x")), false},
+		{"update of another model", update("User", where(types.Eq("id", "u1")), kv("authorId", "u2")), false},
+		{"insert", insert("Post", kv("id", "p9"), kv("authorId", "u2")), false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := false
+			for _, r := range rules(shape, tt.change) {
+				fired = fired || r == "window window_fields"
+			}
+			if fired != tt.fire {
+				t.Errorf("window fired %v, want %v", fired, tt.fire)
+			}
+		})
+	}
+}
+
+func TestSoftDelete(t *testing.T) {
+	deletedAt := "deletedAt"
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records:         map[string][]string{"Post": {"p1"}},
+		Filters:         []types.Filter{*where(types.Eq("published", true))},
+		SoftDeleteField: &deletedAt,
+	}}
+	restore := update("Post", where(types.Eq("id", "p9")), kv("deletedAt", nil))
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		fire   bool
+	}{
+		{"soft delete", update("Post", where(types.Eq("id", "p9")), kv("deletedAt", "2024-01-01")), true},
+		{"restore", restore, true},
+		{"soft delete outside the filters", update("Post", where(types.Eq("published", false)), kv("deletedAt", "2024-01-01")), false},
+		{"update of another field", update("Post", where(types.Eq("id", "p9")), kv("title", "x")), false},
+		{"update of another model", update("User", where(types.Eq("id", "u1")), kv("deletedAt", "2024-01-01")), false},
+		{"delete", remove("Post", where(types.Eq("id", "p9"))), false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := false
+			for _, r := range rules(shape, tt.change) {
+				fired = fired || r == "soft_delete soft_delete_field"
+			}
+			if fired != tt.fire {
+				t.Errorf("soft_delete fired %v, want %v", fired, tt.fire)
+			}
+		})
+	}
+
+	// Without the field the restored row, untracked and not tested by the
+	// filter, looks like an irrelevant update
+	shape.Dependencies.SoftDeleteField = nil
+	if got := rules(shape, restore); len(got) > 0 {
+		t.Errorf("restore without soft_delete_field fired %v, want nothing", got)
+	}
+}
+
+func TestTenant(t *testing.T) {
+	acme, globex := "acme", "globex"
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records: map[string][]string{"Post": {"p1"}},
+		Tenant:  &acme,
+	}}
+	changes := []types.Change{update("Post", where(types.Eq("id", "p1")), kv("title", "x"))}
+
+	tcs := []struct {
+		name   string
+		shape  *string
+		tenant *string
+		fire   bool
+	}{
+		{"same tenant", &acme, &acme, true},
+		{"other tenant", &acme, &globex, false},
+		{"mutation of any tenant", &acme, nil, true},
+		{"shape of every tenant", nil, &globex, true},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			shape.Dependencies.Tenant = tt.shape
+			m := types.Mutation{Changes: changes, Tenant: tt.tenant}
+			if got := invalidate.Affects(shape, m); got != tt.fire {
+				t.Errorf("Affects() = %v, want %v", got, tt.fire)
+			}
+			if got := len(invalidate.Explain(shape, m)) > 0; got != tt.fire {
+				t.Errorf("Explain() fired %v, want %v", got, tt.fire)
+			}
+		})
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	shape := invalidate.Shape{Model: "Post", Dependencies: types.Dependencies{
+		Records: map[string][]string{"Post": {"p1"}},
+		Filters: []types.Filter{*where(types.Eq("published", true))},
+		Policy:  where(types.Eq("orgId", "o1")),
+	}}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		fire   bool
+	}{
+		{"update moving a row into the policy", update("Post", where(types.Eq("id", "p9")), kv("orgId", "o1")), true},
+		{"update moving a row out of the policy", update("Post", where(types.Eq("orgId", "o1")), kv("orgId", "o2")), true},
+		{"update between other orgs", update("Post", where(types.Eq("orgId", "o2")), kv("orgId", "o3")), false},
+		{"update of another field", update("Post", where(types.Eq("id", "p9")), kv("title", "x")), false},
+		{"insert within the policy", insert("Post", kv("id", "p9"), kv("orgId", "o1")), true},
+		{"insert outside the policy", insert("Post", kv("id", "p9"), kv("orgId", "o2")), false},
+		{"update of another model", update("User", where(types.Eq("id", "u1")), kv("orgId", "o1")), false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			fired := false
+			for _, r := range rules(shape, tt.change) {
+				fired = fired || r == "policy policy"
+			}
+			if fired != tt.fire {
+				t.Errorf("policy fired %v, want %v", fired, tt.fire)
+			}
+		})
+	}
+
+	// The filters hold only the root query's where, so moving a row between
+	// orgs leaves them be
+	move := update("Post", where(types.Eq("id", "p9")), kv("orgId", "o1"))
+	shape.Dependencies.Policy = nil
+	if got := rules(shape, move); len(got) > 0 {
+		t.Errorf("update without a policy fired %v, want nothing", got)
+	}
+}
+
+func TestEvictPolicy(t *testing.T) {
+	o1, o2 := where(types.Eq("orgId", "o1")), where(types.Eq("orgId", "o2"))
+	shapes := map[string]invalidate.Shape{
+		"s_o1_posts": {Model: "Post", Dependencies: types.Dependencies{Policy: o1}},
+		"s_o1_users": {Model: "User", Dependencies: types.Dependencies{Policy: o1}},
+		"s_o2_posts": {Model: "Post", Dependencies: types.Dependencies{Policy: o2}},
+		"s_posts":    {Model: "Post"},
+	}
+
+	if got, want := invalidate.EvictPolicy(shapes, *o1), []string{"s_o1_posts", "s_o1_users"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EvictPolicy(o1) = %v, want %v", got, want)
+	}
+	if got := invalidate.EvictPolicy(shapes, *where(types.Eq("orgId", "o3"))); len(got) != 0 {
+		t.Errorf("EvictPolicy(o3) = %v, want none", got)
+	}
+}
+
+func TestEvict(t *testing.T) {
+	shapes := map[string]invalidate.Shape{
+		"s_posts": {Model: "Post", Dependencies: types.Dependencies{Records: map[string][]string{"Post": {"p1"}}}},
+		"s_users": {Model: "User", Dependencies: types.Dependencies{Records: map[string][]string{"User": {"u1"}}}},
+		"s_both":  {Model: "User", Dependencies: types.Dependencies{Records: map[string][]string{"User": {"u1"}}, Includes: []types.Include{{Query: &types.Query{Model: "posts"}}}}},
+	}
+	m := types.Mutation{Changes: []types.Change{update("Post", where(types.Eq("id", "p1")), kv("title", "x"))}}
+
+	got := invalidate.Evict(shapes, m, invalidate.WithSchema(blogSchema))
+	if want := []string{"s_both", "s_posts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Evict() = %v, want %v", got, want)
+	}
+}
+
+// TestMockEngineIsSound checks the conservative mock engine against the
+// reference: it may evict more, never less.
+func TestMockEngineIsSound(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	if err := engine.SetSchema(blogSchema); err != nil {
+		t.Fatal(err)
+	}
+
+	statements := []types.Statement{
+		{Query: &types.Query{Model: "Post", Where: where(types.Eq("published", true))}},
+		{Query: &types.Query{Model: "User"}, Includes: []types.Include{{Query: &types.Query{Model: "posts"}}}},
+		{Query: &types.Query{Model: "Comment", Where: where(types.Eq("postId", "p1"))}},
+		{Query: &types.Query{Model: "Post"}, Policy: where(types.Eq("orgId", "o1"))},
+	}
+	shapes := map[string]invalidate.Shape{}
+	for _, stmt := range statements {
+		added, err := engine.AddQuery(mock.AddQueryRequest{
+			Shape:      stmt,
+			ResultHint: map[string][]interface{}{stmt.Query.Model: {map[string]interface{}{"id": "x1"}}},
+		})
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		shapes[added.ShapeID] = invalidate.Shape{Model: stmt.Query.Model, Dependencies: added.Dependencies}
+	}
+
+	mutations := []types.Mutation{
+		{Changes: []types.Change{insert("Post", kv("id", "p9"), kv("published", true))}},
+		{Changes: []types.Change{update("User", where(types.Eq("id", "x1")), kv("name", "Ada"))}},
+		{Changes: []types.Change{remove("Comment", nil)}},
+		{Changes: []types.Change{insert("Tag", kv("id", "t1"))}},
+		{Changes: []types.Change{update("Post", where(types.Eq("id", "p9")), kv("orgId", "o1"))}},
+	}
+	for _, m := range mutations {
+		want := invalidate.Evict(shapes, m, invalidate.WithSchema(blogSchema))
+		got, err := engine.Invalidate(m)
+		if err != nil {
+			t.Fatalf("Invalidate failed: %v", err)
+		}
+		evicted := map[string]bool{}
+		for _, id := range got.Evict {
+			evicted[id] = true
+		}
+		for _, id := range want {
+			if !evicted[id] {
+				t.Errorf("mock engine kept %s, which %s invalidates", id, m.Changes[0].Model)
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package tests
+
+import (
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/limits"
+)
+
+// WithLimits checks documents against guard instead of limits.Default, so
+// gateways validate and decode with the same limits.Guard as the rest of
+// their ingress. The zero Guard disables every limit.
+func WithLimits(guard limits.Guard) ValidateOption {
+	return func(o *validateOptions) { o.guard = guard }
+}
+
+// ErrLimitExceeded is matched by errors.Is for documents over a limit.
+var ErrLimitExceeded = limits.ErrLimitExceeded
+
+// LimitError reports a document over one of its limits. Validators and the
+// Decode functions wrap it in a ValidationError, so errors.As finds it in
+// their results.
+type LimitError = limits.LimitError
+
+// limitExceeded wraps the *LimitError of a guard check in a
+// ValidationError.
+func limitExceeded(err error) *ValidationError {
+	lerr := err.(*LimitError)
+	return &ValidationError{
+		Code:    CodeLimitExceeded,
+		Message: fmt.Sprintf("%s limit of %d exceeded", lerr.Limit, lerr.Max),
+		Path:    lerr.Path,
+		Err:     lerr,
+	}
+}
@@ -0,0 +1,239 @@
+package tests_test
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/limits"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// nestedFilter returns a filter depth levels deep, alternating and, or and
+// not.
+func nestedFilter(depth int) *types.Filter {
+	f := &types.Filter{Conditions: &[]types.Condition{types.Eq("id", 1)}}
+	for i := 1; i < depth; i++ {
+		switch i % 3 {
+		case 0:
+			f = &types.Filter{And: &[]types.Filter{*f}}
+		case 1:
+			f = &types.Filter{Or: &[]types.Filter{*f}}
+		default:
+			f = &types.Filter{Not: f}
+		}
+	}
+	return f
+}
+
+func nestedIncludes(depth int) []types.Include {
+	var includes []types.Include
+	for i := 0; i < depth; i++ {
+		includes = []types.Include{{Query: &types.Query{Model: "posts"}, Includes: includes}}
+	}
+	return includes
+}
+
+// nestedSubqueries returns a where filter with depth nested inQuery
+// subqueries.
+func nestedSubqueries(depth int) *types.Filter {
+	var where *types.Filter
+	for i := 0; i < depth; i++ {
+		sub := types.InQuery("teamId", types.Query{Model: "teams", Fields: &[]string{"id"}, Where: where})
+		where = &types.Filter{Conditions: &[]types.Condition{sub}}
+	}
+	return where
+}
+
+func TestLimits(t *testing.T) {
+	manyConditions := make([]types.Condition, 501)
+	for i := range manyConditions {
+		manyConditions[i] = types.Eq("id", i)
+	}
+	depth := func(max int) tests.ValidateOption { return tests.WithLimits(limits.Guard{MaxDepth: max}) }
+
+	tcs := []struct {
+		name  string
+		err   error
+		limit string
+		path  string
+	}{
+		{
+			name: "filter depth within the limit",
+			err:  tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users", Where: nestedFilter(32)}}),
+		},
+		{
+			name:  "filter depth",
+			err:   tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users", Where: nestedFilter(33)}}),
+			limit: "depth",
+		},
+		{
+			name:  "filter depth path",
+			err:   tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users", Where: nestedFilter(4)}}, depth(3)),
+			limit: "depth",
+			path:  "statement.query.where.and[0].not.or[0]",
+		},
+		{
+			name:  "having depth",
+			err:   tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users"}, Having: nestedFilter(33)}),
+			limit: "depth",
+		},
+		{
+			name: "include depth within the limit",
+			err:  tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users"}, Includes: nestedIncludes(8)}, depth(8)),
+		},
+		{
+			name:  "include depth",
+			err:   tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users"}, Includes: nestedIncludes(9)}, depth(8)),
+			limit: "depth",
+			path:  "statement.includes[0].includes[0].includes[0].includes[0].includes[0].includes[0].includes[0].includes[0].includes[0]",
+		},
+		{
+			name:  "include count",
+			err:   tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users"}, Includes: nestedIncludes(3)}, tests.WithLimits(limits.Guard{MaxIncludes: 2})),
+			limit: "includes",
+		},
+		{
+			name: "nodes counted across filters",
+			err: tests.ValidateQueryShape(&types.Statement{
+				Query:  &types.Query{Model: "users", Where: &types.Filter{Conditions: &manyConditions}},
+				Having: nestedFilter(1),
+			}, tests.WithLimits(limits.Guard{MaxNodes: 504})),
+			limit: "nodes",
+			path:  "statement.having.conditions[0]",
+		},
+		{
+			name:  "changes",
+			err:   tests.ValidateMutationEvent(&types.Mutation{Changes: make([]types.Change, 501)}, tests.WithLimits(limits.Guard{MaxNodes: 500})),
+			limit: "nodes",
+			path:  "mutation.changes[500]",
+		},
+		{
+			name: "changes counted across a transaction",
+			err: tests.ValidateTransaction(&types.Transaction{Mutations: []types.Mutation{
+				{Changes: make([]types.Change, 2)},
+				{Changes: make([]types.Change, 2)},
+			}}, tests.WithLimits(limits.Guard{MaxNodes: 3})),
+			limit: "nodes",
+			path:  "transaction.mutations[1].changes[1]",
+		},
+		{
+			name:  "dependencies",
+			err:   tests.ValidateDependencies(&types.Dependencies{Includes: nestedIncludes(2)}, depth(1)),
+			limit: "depth",
+			path:  "dependencies.includes[0].includes[0]",
+		},
+		{
+			name: "subquery depth within the limit",
+			err:  tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users", Where: nestedSubqueries(2)}}, depth(3)),
+		},
+		{
+			name:  "subquery depth",
+			err:   tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users", Where: nestedSubqueries(3)}}, depth(3)),
+			limit: "depth",
+			path:  "statement.query.where.conditions[0].subquery.where.conditions[0].subquery.where.conditions[0].subquery",
+		},
+		{
+			name: "zero guard disables limits",
+			err:  tests.ValidateQueryShape(&types.Statement{Query: &types.Query{Model: "users", Where: nestedFilter(100)}}, tests.WithLimits(limits.Guard{})),
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.limit == "" {
+				if tt.err != nil {
+					t.Fatalf("unexpected error: %v", tt.err)
+				}
+				return
+			}
+			var lerr *tests.LimitError
+			if !errors.As(tt.err, &lerr) || !errors.Is(tt.err, tests.ErrLimitExceeded) {
+				t.Fatalf("error = %v, want a LimitError", tt.err)
+			}
+			if lerr.Limit != tt.limit || (tt.path != "" && lerr.Path != tt.path) {
+				t.Errorf("LimitError = %+v, want %s at %s", lerr, tt.limit, tt.path)
+			}
+		})
+	}
+}
+
+func TestLimitsStopValidation(t *testing.T) {
+	stmt := &types.Statement{Query: &types.Query{Model: "", Where: nestedFilter(3)}}
+	errs := tests.ValidateQueryShapeAll(stmt, tests.WithLimits(limits.Guard{MaxDepth: 2}))
+	if len(errs) != 1 || !errors.Is(errs, tests.ErrLimitExceeded) {
+		t.Errorf("ValidateQueryShapeAll() = %v, want only the limit error", errs)
+	}
+
+	_, err := tests.DecodeMutation(strings.NewReader(`{"changes": [{"model": "m", "action": "insert", "sets": [{"field": "a", "value": 1}]},
+		{"model": "m", "action": "insert", "sets": [{"field": "a", "value": 2}]}]}`), tests.WithLimits(limits.Guard{MaxNodes: 1}))
+	if !errors.Is(err, tests.ErrLimitExceeded) {
+		t.Errorf("DecodeMutation() error = %v, want ErrLimitExceeded", err)
+	}
+}
+
+func TestDecodeLimits(t *testing.T) {
+	deep := strings.Repeat(`{"not":`, 20) + `{"conditions":[{"field":"id","op":"eq","value":"]]]"}]}` + strings.Repeat("}", 20)
+	doc := `{"query":{"model":"users","where":` + deep + `}}`
+
+	tcs := []struct {
+		name  string
+		guard limits.Guard
+		limit string
+	}{
+		{"within defaults", limits.Default, ""},
+		{"bytes", limits.Guard{MaxPayloadBytes: 64}, "payload_bytes"},
+		{"json depth", limits.Guard{MaxJSONDepth: 10}, "json_depth"},
+		{"disabled", limits.Guard{}, ""},
+		{"exact", limits.Guard{MaxPayloadBytes: len(doc), MaxJSONDepth: 25}, ""},
+		{"one byte short", limits.Guard{MaxPayloadBytes: len(doc) - 1}, "payload_bytes"},
+		{"one level short", limits.Guard{MaxJSONDepth: 24}, "json_depth"},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tests.DecodeStatement(strings.NewReader(doc), tests.WithLimits(tt.guard))
+			if tt.limit == "" {
+				if err != nil {
+					t.Fatalf("DecodeStatement failed: %v", err)
+				}
+				return
+			}
+			var lerr *tests.LimitError
+			if !errors.As(err, &lerr) || lerr.Limit != tt.limit || lerr.Path != "statement" {
+				t.Fatalf("DecodeStatement() error = %v, want %s at statement", err, tt.limit)
+			}
+			var verr *tests.ValidationError
+			if !errors.As(err, &verr) || verr.Code != tests.CodeLimitExceeded {
+				t.Errorf("ValidationError = %v, want code %s", verr, tests.CodeLimitExceeded)
+			}
+		})
+	}
+}
+
+func TestDecodeLimitsStopReading(t *testing.T) {
+	// An endless array is rejected without reading it all.
+	r := io.MultiReader(strings.NewReader(`{"query":{"model":"users","where":`), endless('['))
+	_, err := tests.DecodeStatement(r)
+	if !errors.Is(err, tests.ErrLimitExceeded) {
+		t.Errorf("DecodeStatement() error = %v, want ErrLimitExceeded", err)
+	}
+
+	r = io.MultiReader(strings.NewReader(`{"query":{"model":"`), endless('a'))
+	_, err = tests.DecodeStatement(r)
+	if !errors.Is(err, tests.ErrLimitExceeded) {
+		t.Errorf("DecodeStatement() error = %v, want ErrLimitExceeded", err)
+	}
+}
+
+// endless reads b forever.
+type endless byte
+
+func (e endless) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(e)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,229 @@
+// Package migrate rewrites statements emitted by SDKs built against an older
+// spec version into the format of a newer one, so engines can keep accepting
+// them while SDKs upgrade.
+//
+// Statements declare the version they were emitted with in spec_version,
+// which older SDKs leave out, so callers may pass the version the SDK was
+// built against instead. As in package upgrade, migrations operate on the
+// decoded JSON object, because older statements may carry fields the current
+// types no longer have.
+package migrate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Step migrates a statement from one spec version to the next.
+type Step struct {
+	From  string
+	To    string
+	Apply func(stmt map[string]any) error
+}
+
+// Steps is the built-in migration chain, ordered by From. Versions with no
+// step between them are format-compatible. 0.1.0 is the first spec version,
+// so the chain is empty until a version changes the statement format.
+var Steps = []Step{}
+
+// Migrate rewrites stmt from spec version from to spec version to using
+// Steps.
+//
+// See MigrateWith for details.
+func Migrate(stmt json.RawMessage, from, to string) (json.RawMessage, error) {
+	return MigrateWith(stmt, from, to, Steps)
+}
+
+// MigrateWith rewrites stmt from spec version from to spec version to by
+// applying every step whose From matches the statement's version in turn,
+// stopping at to. An empty from means the statement's spec_version, or 0.1.0
+// when it has none, and an empty to means types.SpecVersion. A declared
+// spec_version is restamped with to. Numbers are preserved exactly; object
+// keys come out sorted.
+//
+// Returns an error if either version is malformed, to is older than from or
+// newer than types.SpecVersion, a step fails, or a statement migrated to
+// types.SpecVersion has fields types.Statement does not know.
+func MigrateWith(stmt json.RawMessage, from, to string, steps []Step) (json.RawMessage, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(stmt))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("migrate: decode statement: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("migrate: statement is null")
+	}
+
+	_, declared := doc["spec_version"]
+	if from == "" && declared {
+		v, ok := doc["spec_version"].(string)
+		if !ok {
+			return nil, fmt.Errorf("migrate: spec_version must be a string")
+		}
+		from = v
+	}
+	if from == "" {
+		from = "0.1.0"
+	}
+	if to == "" {
+		to = types.SpecVersion
+	}
+	cmp, err := types.CompareSpecVersions(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("migrate: cannot migrate from %s back to %s", from, to)
+	}
+	if cmp, _ := types.CompareSpecVersions(to, types.SpecVersion); cmp > 0 {
+		return nil, fmt.Errorf("migrate: spec version %s is newer than supported %s", to, types.SpecVersion)
+	}
+
+	version := from
+	for _, step := range steps {
+		if step.From != version {
+			continue
+		}
+		cmp, err := types.CompareSpecVersions(step.To, to)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %s -> %s: %w", step.From, step.To, err)
+		}
+		if cmp > 0 {
+			break
+		}
+		if err := step.Apply(doc); err != nil {
+			return nil, fmt.Errorf("migrate: %s -> %s: %w", step.From, step.To, err)
+		}
+		version = step.To
+	}
+	if declared {
+		doc["spec_version"] = to
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("migrate: encode statement: %w", err)
+	}
+	if to == types.SpecVersion {
+		var s types.Statement
+		dec := json.NewDecoder(bytes.NewReader(migrated))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&s); err != nil {
+			return nil, fmt.Errorf("migrate: statement at %s: %w", version, err)
+		}
+	}
+	return migrated, nil
+}
+
+// Objects calls fn with every object of the definition def in stmt:
+// "Statement", "Query", "Include", "Filter", "Condition", "OrderBy",
+// "Aggregate", "Join" or "Pagination". It follows the current format's
+// nesting, and calls fn on an object before descending into it, so a step
+// may rename the keys it descends through. Values of the wrong type are
+// skipped.
+func Objects(stmt map[string]any, def string, fn func(obj map[string]any) error) error {
+	w := &walker{def: def, fn: fn}
+	w.statement(stmt)
+	return w.err
+}
+
+// RenameField returns a step function that renames the field old of every
+// object of def to new. Objects that already have new keep it and lose old.
+func RenameField(def, old, new string) func(stmt map[string]any) error {
+	return func(stmt map[string]any) error {
+		return Objects(stmt, def, func(obj map[string]any) error {
+			v, ok := obj[old]
+			if !ok {
+				return nil
+			}
+			delete(obj, old)
+			if _, ok := obj[new]; !ok {
+				obj[new] = v
+			}
+			return nil
+		})
+	}
+}
+
+type walker struct {
+	def string
+	fn  func(map[string]any) error
+	err error
+}
+
+// visit calls fn when obj is an object of the definition being migrated and
+// reports whether the walk should descend into it.
+func (w *walker) visit(def string, v any) (map[string]any, bool) {
+	obj, ok := v.(map[string]any)
+	if !ok || w.err != nil {
+		return nil, false
+	}
+	if def == w.def {
+		if err := w.fn(obj); err != nil {
+			w.err = fmt.Errorf("%s: %w", def, err)
+			return nil, false
+		}
+	}
+	return obj, true
+}
+
+func (w *walker) each(v any, visit func(any)) {
+	items, _ := v.([]any)
+	for _, item := range items {
+		visit(item)
+	}
+}
+
+func (w *walker) statement(v any) {
+	obj, ok := w.visit("Statement", v)
+	if !ok {
+		return
+	}
+	w.query(obj["query"])
+	w.visit("Pagination", obj["pagination"])
+	w.filter(obj["having"])
+	w.each(obj["includes"], w.include)
+}
+
+func (w *walker) query(v any) {
+	obj, ok := w.visit("Query", v)
+	if !ok {
+		return
+	}
+	w.filter(obj["where"])
+	w.each(obj["order_by"], func(v any) { w.visit("OrderBy", v) })
+	w.each(obj["aggregations"], func(v any) { w.visit("Aggregate", v) })
+	w.each(obj["joins"], func(v any) {
+		if join, ok := w.visit("Join", v); ok {
+			w.filter(join["on"])
+		}
+	})
+}
+
+func (w *walker) include(v any) {
+	obj, ok := w.visit("Include", v)
+	if !ok {
+		return
+	}
+	w.query(obj["query"])
+	w.each(obj["includes"], w.include)
+}
+
+func (w *walker) filter(v any) {
+	obj, ok := w.visit("Filter", v)
+	if !ok {
+		return
+	}
+	w.each(obj["and"], w.filter)
+	w.each(obj["or"], w.filter)
+	w.filter(obj["not"])
+	w.each(obj["conditions"], func(v any) {
+		if cond, ok := w.visit("Condition", v); ok {
+			w.query(cond["subquery"])
+		}
+	})
+}
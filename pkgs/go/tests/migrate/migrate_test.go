@@ -0,0 +1,175 @@
+package migrate_test
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/migrate"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestMigrateVectors(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "tools", "tests", "vectors", "migrations.json"))
+	if err != nil {
+		t.Fatalf("Failed to read vectors: %v", err)
+	}
+	var vectors []struct {
+		Name            string          `json:"name"`
+		From            string          `json:"from"`
+		Statement       json.RawMessage `json:"statement"`
+		Expected        json.RawMessage `json:"expected"`
+		ExpectedShapeID string          `json:"expectedShapeId"`
+	}
+	if err := json.Unmarshal(data, &vectors); err != nil {
+		t.Fatalf("Failed to parse vectors: %v", err)
+	}
+
+	for _, v := range vectors {
+		t.Run(v.Name, func(t *testing.T) {
+			migrated, err := migrate.Migrate(v.Statement, v.From, types.SpecVersion)
+			if err != nil {
+				t.Fatalf("Migrate: %v", err)
+			}
+			var got, want any
+			if err := json.Unmarshal(migrated, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(v.Expected, &want); err != nil {
+				t.Fatal(err)
+			}
+			gotCanonical, _ := tests.Canonicalize(got)
+			wantCanonical, _ := tests.Canonicalize(want)
+			if gotCanonical != wantCanonical {
+				t.Errorf("migrated = %s, want %s", gotCanonical, wantCanonical)
+			}
+
+			var stmt types.Statement
+			if err := json.Unmarshal(migrated, &stmt); err != nil {
+				t.Fatal(err)
+			}
+			id, err := tests.ComputeQueryShapeID(&stmt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != v.ExpectedShapeID {
+				t.Errorf("shape ID = %s, want %s", id, v.ExpectedShapeID)
+			}
+		})
+	}
+}
+
+func TestMigrateWithAppliesChain(t *testing.T) {
+	steps := []migrate.Step{
+		{From: "0.0.1", To: "0.0.2", Apply: migrate.RenameField("Query", "orderBy", "order_by")},
+		{From: "0.0.2", To: types.SpecVersion, Apply: func(stmt map[string]any) error {
+			// 0.0.2 filters held conditions under "all"
+			return migrate.Objects(stmt, "Filter", func(f map[string]any) error {
+				if all, ok := f["all"]; ok {
+					f["conditions"] = all
+					delete(f, "all")
+				}
+				return nil
+			})
+		}},
+	}
+
+	legacy := `{"query":{"model":"Post","orderBy":[{"field":"id"}],"limit":10,
+		"where":{"not":{"all":[{"field":"draft","op":"eq","value":true}]}}},
+		"includes":[{"query":{"model":"comments","orderBy":[{"field":"at"}],"where":{"all":[]}}}]}`
+	migrated, err := migrate.MigrateWith(json.RawMessage(legacy), "0.0.1", "", steps)
+	if err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+	want := `{"includes":[{"query":{"model":"comments","order_by":[{"field":"at"}],"where":{"conditions":[]}}}],` +
+		`"query":{"limit":10,"model":"Post","order_by":[{"field":"id"}],"where":{"not":{"conditions":[{"field":"draft","op":"eq","value":true}]}}}}`
+	if string(migrated) != want {
+		t.Errorf("migrated = %s\nwant %s", migrated, want)
+	}
+
+	// Stopping part-way leaves later formats alone
+	migrated, err = migrate.MigrateWith(json.RawMessage(legacy), "0.0.1", "0.0.2", steps)
+	if err != nil {
+		t.Fatalf("MigrateWith to 0.0.2: %v", err)
+	}
+	if !strings.Contains(string(migrated), `"all"`) || strings.Contains(string(migrated), "orderBy") {
+		t.Errorf("migrated to 0.0.2 = %s", migrated)
+	}
+}
+
+func TestRenameFieldKeepsNewValue(t *testing.T) {
+	stmt := map[string]any{"query": map[string]any{"model": "Post", "sort": "a", "order_by": "b"}}
+	if err := migrate.RenameField("Query", "sort", "order_by")(stmt); err != nil {
+		t.Fatal(err)
+	}
+	query := stmt["query"].(map[string]any)
+	if _, ok := query["sort"]; ok || query["order_by"] != "b" {
+		t.Errorf("query = %v, want sort dropped and order_by kept", query)
+	}
+}
+
+func TestMigrateErrors(t *testing.T) {
+	tcs := []struct {
+		name     string
+		stmt     string
+		from, to string
+		steps    []migrate.Step
+		errMsg   string
+	}{
+		{name: "malformed json", stmt: `{`, errMsg: "decode"},
+		{name: "null statement", stmt: `null`, errMsg: "is null"},
+		{name: "malformed version", stmt: `{}`, from: "1.0", errMsg: "must be X.Y.Z"},
+		{name: "backwards", stmt: `{}`, from: types.SpecVersion, to: "0.0.1", errMsg: "back to 0.0.1"},
+		{name: "newer version", stmt: `{}`, to: "99.0.0", errMsg: "newer than supported"},
+		{name: "unmigrated field", stmt: `{"query":{"model":"Post","orderBy":[]}}`, from: "0.0.1", errMsg: "unknown field"},
+		{
+			name:   "failing step",
+			stmt:   `{"query":{"model":"Post"}}`,
+			from:   "0.0.1",
+			steps:  []migrate.Step{{From: "0.0.1", To: types.SpecVersion, Apply: func(map[string]any) error { return errBoom }}},
+			errMsg: "0.0.1 -> " + types.SpecVersion + ": boom",
+		},
+		{
+			name: "failing object",
+			stmt: `{"query":{"model":"Post"}}`,
+			from: "0.0.1",
+			steps: []migrate.Step{{From: "0.0.1", To: types.SpecVersion, Apply: func(stmt map[string]any) error {
+				return migrate.Objects(stmt, "Query", func(map[string]any) error { return errBoom })
+			}}},
+			errMsg: "Query: boom",
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := migrate.MigrateWith(json.RawMessage(tt.stmt), tt.from, tt.to, tt.steps)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+func TestMigrateUsesDeclaredSpecVersion(t *testing.T) {
+	steps := []migrate.Step{{From: "0.0.1", To: types.SpecVersion, Apply: migrate.RenameField("Query", "orderBy", "order_by")}}
+
+	legacy := `{"spec_version":"0.0.1","query":{"model":"Post","orderBy":[{"field":"id"}]}}`
+	migrated, err := migrate.MigrateWith(json.RawMessage(legacy), "", "", steps)
+	if err != nil {
+		t.Fatalf("MigrateWith: %v", err)
+	}
+	want := `{"query":{"model":"Post","order_by":[{"field":"id"}]},"spec_version":"` + types.SpecVersion + `"}`
+	if string(migrated) != want {
+		t.Errorf("migrated = %s, want %s", migrated, want)
+	}
+
+	if _, err := migrate.MigrateWith(json.RawMessage(`{"spec_version":1}`), "", "", steps); err == nil || !strings.Contains(err.Error(), "must be a string") {
+		t.Errorf("non-string spec_version error = %v", err)
+	}
+}
+
+var errBoom = errors.New("boom")
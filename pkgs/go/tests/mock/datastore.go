@@ -0,0 +1,558 @@
+package mock
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/cursors"
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Row is a record of the in-memory datastore. Values are JSON values:
+// numbers are float64, nested objects map[string]interface{} and arrays
+// []interface{}.
+type Row = map[string]interface{}
+
+// SeedData replaces the rows of a model in the in-memory datastore. Rows are
+// copied and normalized to JSON values, so later changes to them do not
+// affect the engine.
+//
+// Once a model is seeded, Invalidate, InvalidateTransaction and CommitTx also
+// apply changes to its rows, so an SDK can test query -> results -> mutation
+// -> eviction end to end with Execute.
+func (m *MockEngine) SeedData(model string, rows []Row) error {
+	normalized, err := normalizeRows(rows)
+	if err != nil {
+		return fmt.Errorf("seed %s: %w", model, err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[model] = normalized
+	return nil
+}
+
+// Execute evaluates a statement against the seeded rows: soft deletes, the
+// where filter, distinct and distinct_on, ordering, offset and limit, cursor
+// pagination, field selection and includes. Loaded relations are attached to
+// each row under the relation name, as a row for "one" relations and a list
+// of rows for "many". Includes with a kind filter the parent rows instead,
+// and are attached only when they select fields.
+//
+// Includes are resolved against the schema set with SetSchema. The policy,
+// if any, is ANDed with the root where. Grouping, having, aggregations, joins
+// and windows are not supported.
+//
+// Returns an error if the statement is invalid or uses an unsupported
+// feature or operator.
+func (m *MockEngine) Execute(stmt types.Statement) ([]Row, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if err := tests.ValidateQueryShape(&stmt); err != nil {
+		return nil, err
+	}
+	if stmt.Query == nil {
+		return nil, fmt.Errorf("execute: query is required")
+	}
+	if stmt.GroupBy != nil || stmt.Having != nil || len(stmt.Query.GetAggregations()) > 0 || len(stmt.Query.GetJoins()) > 0 ||
+		len(stmt.Query.GetWindows()) > 0 {
+		return nil, fmt.Errorf("execute: group_by, having, aggregations, joins and windows are not supported")
+	}
+	if len(stmt.Includes) > 0 {
+		if m.schema == nil {
+			return nil, fmt.Errorf("execute: a schema is required to load includes")
+		}
+		if _, err := ResolveIncludes(*m.schema, &stmt); err != nil {
+			return nil, err
+		}
+	}
+
+	q := stmt.Query
+	if stmt.Policy != nil {
+		scoped := *q
+		scoped.Where = stmt.Policy
+		if q.Where != nil {
+			scoped.Where = &types.Filter{And: &[]types.Filter{*q.Where, *stmt.Policy}}
+		}
+		q = &scoped
+	}
+
+	rows, err := m.selectRows(q.Model, q, stmt.Includes, nil)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Pagination != nil {
+		rows, err = paginate(rows, q.GetOrderBy(), stmt.Pagination)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return m.shapeRows(q.Model, q, stmt.Includes, rows)
+}
+
+// ResultHint turns rows returned by Execute into an AddQueryRequest result
+// hint for the model.
+func ResultHint(model string, rows []Row) map[string][]interface{} {
+	hint := make([]interface{}, len(rows))
+	for i, row := range rows {
+		hint[i] = row
+	}
+	return map[string][]interface{}{model: hint}
+}
+
+// selectRows returns the rows of model matching q and the include kind
+// filters, ordered, de-duplicated and windowed by q. Soft-deleted rows are
+// left out, or kept alone, as q.Deleted says. Rows are shared with the
+// datastore; shapeRows copies them.
+func (m *MockEngine) selectRows(model string, q *types.Query, includes []types.Include, candidates []Row) ([]Row, error) {
+	if candidates == nil {
+		candidates = m.data[model]
+	}
+
+	rows := []Row{}
+	for _, row := range candidates {
+		if !m.reads(model, q, row) {
+			continue
+		}
+		ok, err := matchFilter(row, q.Where)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ok, err = m.matchKinds(model, row, includes)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if ok {
+			rows = append(rows, row)
+		}
+	}
+
+	if d := q.DistinctOn; d != nil {
+		// Keep the first row of each combination in the distinct_on order;
+		// order_by then orders the kept rows
+		sort.SliceStable(rows, func(i, j int) bool { return compareRows(rows[i], rows[j], d.OrderBy) < 0 })
+		rows = distinctRows(rows, d.Fields)
+	}
+	orderBy := q.GetOrderBy()
+	sort.SliceStable(rows, func(i, j int) bool { return compareRows(rows[i], rows[j], orderBy) < 0 })
+
+	if distinct := q.GetDistinct(); len(distinct) > 0 {
+		rows = distinctRows(rows, distinct)
+	}
+	if offset, ok := q.GetOffset(); ok {
+		if offset > len(rows) {
+			offset = len(rows)
+		}
+		rows = rows[offset:]
+	}
+	if limit, ok := q.GetLimit(); ok && limit < len(rows) {
+		rows = rows[:limit]
+	}
+	return rows, nil
+}
+
+// matchKinds applies the some/every/none/count includes of a row.
+func (m *MockEngine) matchKinds(model string, row Row, includes []types.Include) (bool, error) {
+	for i := range includes {
+		include := &includes[i]
+		kind, ok := include.GetKind()
+		if !ok {
+			continue
+		}
+		rel, _ := m.schema.relation(model, include.Query.Model)
+		var related []Row
+		for _, r := range m.related(model, rel, row) {
+			if m.reads(rel.Target, include.Query, r) {
+				related = append(related, r)
+			}
+		}
+
+		matched := 0
+		for _, r := range related {
+			ok, err := matchFilter(r, include.Query.Where)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				matched++
+			}
+		}
+
+		switch kind {
+		case "some":
+			ok = matched > 0
+		case "every":
+			ok = matched == len(related)
+		case "none":
+			ok = matched == 0
+		case "count":
+			ok = include.Count != nil && compareCount(include.Count, matched)
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// reads reports whether q reads row of model as far as soft deletes go: a
+// model whose schema names a soft-delete field has its live rows read, its
+// soft-deleted ones, or both, as q.Deleted says.
+func (m *MockEngine) reads(model string, q *types.Query, row Row) bool {
+	if m.schema == nil {
+		return true
+	}
+	field := m.schema.SoftDeleteField(model)
+	deleted, _ := q.GetDeleted()
+	return field == "" || deleted == "include" || (row[field] != nil) == (deleted == "only")
+}
+
+// compareCount reports whether n matching relation rows pass count.
+func compareCount(count *types.RelationCount, n int) bool {
+	switch count.Op {
+	case "eq":
+		return n == count.Value
+	case "ne":
+		return n != count.Value
+	case "gt":
+		return n > count.Value
+	case "gte":
+		return n >= count.Value
+	case "lt":
+		return n < count.Value
+	case "lte":
+		return n <= count.Value
+	}
+	return false
+}
+
+// shapeRows copies rows, attaches loaded relations and applies the field
+// selection.
+func (m *MockEngine) shapeRows(model string, q *types.Query, includes []types.Include, rows []Row) ([]Row, error) {
+	out := make([]Row, len(rows))
+	for i, row := range rows {
+		shaped := project(row, q.GetFields())
+		for j := range includes {
+			include := &includes[j]
+			if _, ok := include.GetKind(); ok && len(include.Query.GetFields()) == 0 {
+				continue
+			}
+			rel, _ := m.schema.relation(model, include.Query.Model)
+			loaded, err := m.selectRows(rel.Target, include.Query, include.Includes, m.related(model, rel, row))
+			if err != nil {
+				return nil, err
+			}
+			loaded, err = m.shapeRows(rel.Target, include.Query, include.Includes, loaded)
+			if err != nil {
+				return nil, err
+			}
+
+			if rel.Kind == "one" {
+				if len(loaded) > 0 {
+					shaped[rel.Name] = loaded[0]
+				} else {
+					shaped[rel.Name] = nil
+				}
+				continue
+			}
+			list := make([]interface{}, len(loaded))
+			for k, r := range loaded {
+				list[k] = r
+			}
+			shaped[rel.Name] = list
+		}
+		out[i] = shaped
+	}
+	return out, nil
+}
+
+// related returns the target rows joined to row through rel.
+func (m *MockEngine) related(model string, rel Relation, row Row) []Row {
+	parentFields, targetFields := joinFields(model, rel)
+	out := []Row{}
+	for _, target := range m.data[rel.Target] {
+		joined := true
+		for i := range parentFields {
+			pv, tv := row[parentFields[i]], target[targetFields[i]]
+			if pv == nil || tv == nil || !reflect.DeepEqual(pv, tv) {
+				joined = false
+				break
+			}
+		}
+		if joined {
+			out = append(out, target)
+		}
+	}
+	return out
+}
+
+// applyChanges writes changes to seeded models; unseeded models are ignored.
+func (m *MockEngine) applyChanges(changes []types.Change) error {
+	for _, change := range changes {
+		rows, seeded := m.data[change.Model]
+		if !seeded {
+			continue
+		}
+		// An update or delete without a valid where would touch every row
+		if err := tests.ValidateMutationEvent(&types.Mutation{Changes: []types.Change{change}}); err != nil {
+			return err
+		}
+
+		sets := Row{}
+		for _, kv := range change.Sets {
+			sets[kv.Field] = kv.Value
+		}
+		normalized, err := normalizeRows([]Row{sets})
+		if err != nil {
+			return fmt.Errorf("apply %s: %w", change.Model, err)
+		}
+		sets = normalized[0]
+
+		if change.Action == "insert" {
+			m.data[change.Model] = append(rows, sets)
+			continue
+		}
+
+		kept := make([]Row, 0, len(rows))
+		for _, row := range rows {
+			ok, err := matchFilter(row, change.Where)
+			if err != nil {
+				return err
+			}
+			switch {
+			case !ok:
+				kept = append(kept, row)
+			case change.Action == "update":
+				updated := make(Row, len(row))
+				for k, v := range row {
+					updated[k] = v
+				}
+				for k, v := range sets {
+					updated[k] = v
+				}
+				kept = append(kept, updated)
+			}
+		}
+		m.data[change.Model] = kept
+	}
+	return nil
+}
+
+func matchFilter(row Row, f *types.Filter) (bool, error) {
+	if f == nil {
+		return true, nil
+	}
+	ok, err := tests.FilterMatches(*f, row)
+	if err != nil {
+		return false, fmt.Errorf("execute: %w", err)
+	}
+	return ok, nil
+}
+
+// compareRows orders rows by orderBy. As in SQL, nulls sort as if larger
+// than any value (last ascending, first descending) unless NullsFirst says
+// otherwise.
+func compareRows(a, b Row, orderBy []types.OrderBy) int {
+	for _, ob := range orderBy {
+		av, bv := a[ob.Field], b[ob.Field]
+		if av == nil || bv == nil {
+			if av == nil && bv == nil {
+				continue
+			}
+			nullsFirst := ob.IsDescending()
+			if nf, ok := ob.GetNullsFirst(); ok {
+				nullsFirst = nf
+			}
+			if (av == nil) == nullsFirst {
+				return -1
+			}
+			return 1
+		}
+
+		if cs, ok := ob.GetCaseSensitive(); ok && !cs {
+			av, bv = foldCase(av), foldCase(bv)
+		}
+		cmp, _ := compareValues(av, bv)
+		if ob.IsDescending() {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+// compareValues compares two numbers, strings or booleans. It reports false
+// if the values are null or of different kinds.
+func compareValues(a, b interface{}) (int, bool) {
+	switch av := a.(type) {
+	case float64:
+		bv, ok := b.(float64)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av < bv:
+			return -1, true
+		case av > bv:
+			return 1, true
+		}
+		return 0, true
+	case string:
+		bv, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(av, bv), true
+	case bool:
+		bv, ok := b.(bool)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case av == bv:
+			return 0, true
+		case bv:
+			return -1, true
+		}
+		return 1, true
+	}
+	return 0, false
+}
+
+// paginate applies cursor pagination to ordered rows.
+func paginate(rows []Row, orderBy []types.OrderBy, p *types.Pagination) ([]Row, error) {
+	if cursor, ok := p.GetAfter(); ok {
+		boundary, err := cursorRow(orderBy, cursor)
+		if err != nil {
+			return nil, err
+		}
+		rows = filterRows(rows, func(r Row) bool { return compareRows(r, boundary, orderBy) > 0 })
+	}
+	if cursor, ok := p.GetBefore(); ok {
+		boundary, err := cursorRow(orderBy, cursor)
+		if err != nil {
+			return nil, err
+		}
+		rows = filterRows(rows, func(r Row) bool { return compareRows(r, boundary, orderBy) < 0 })
+	}
+	if first, ok := p.GetFirst(); ok && first < len(rows) {
+		rows = rows[:first]
+	}
+	if last, ok := p.GetLast(); ok && last < len(rows) {
+		rows = rows[len(rows)-last:]
+	}
+	return rows, nil
+}
+
+func cursorRow(orderBy []types.OrderBy, cursor string) (Row, error) {
+	values, err := cursors.DecodeValues(orderBy, cursor)
+	if err != nil {
+		return nil, err
+	}
+	row := make(Row, len(orderBy))
+	for i, ob := range orderBy {
+		row[ob.Field] = values[i]
+	}
+	return normalizeRow(row)
+}
+
+func filterRows(rows []Row, keep func(Row) bool) []Row {
+	out := []Row{}
+	for _, r := range rows {
+		if keep(r) {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+// distinctRows keeps the first row for each combination of fields.
+func distinctRows(rows []Row, fields []string) []Row {
+	seen := map[string]bool{}
+	out := []Row{}
+	for _, row := range rows {
+		key := make([]interface{}, len(fields))
+		for i, f := range fields {
+			key[i] = row[f]
+		}
+		data, _ := json.Marshal(key)
+		if !seen[string(data)] {
+			seen[string(data)] = true
+			out = append(out, row)
+		}
+	}
+	return out
+}
+
+// project copies row, keeping only fields when any are selected.
+func project(row Row, fields []string) Row {
+	out := Row{}
+	if len(fields) == 0 {
+		for k, v := range row {
+			out[k] = v
+		}
+		return out
+	}
+	for _, f := range fields {
+		if v, ok := row[f]; ok {
+			out[f] = v
+		}
+	}
+	return out
+}
+
+func foldCase(v interface{}) interface{} {
+	if s, ok := v.(string); ok {
+		return strings.ToLower(s)
+	}
+	return v
+}
+
+// jsonValue normalizes v to the value it decodes to from JSON.
+func jsonValue(v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func normalizeRow(row Row) (Row, error) {
+	v, err := jsonValue(row)
+	if err != nil {
+		return nil, err
+	}
+	out, _ := v.(Row)
+	if out == nil {
+		out = Row{}
+	}
+	return out, nil
+}
+
+func normalizeRows(rows []Row) ([]Row, error) {
+	out := make([]Row, len(rows))
+	for i, row := range rows {
+		normalized, err := normalizeRow(row)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = normalized
+	}
+	return out, nil
+}
@@ -0,0 +1,492 @@
+package mock_test
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/pagination"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func seededEngine(t *testing.T) *mock.MockEngine {
+	t.Helper()
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	if err := engine.SetSchema(blogSchema); err != nil {
+		t.Fatalf("SetSchema failed: %v", err)
+	}
+	seed := map[string][]mock.Row{
+		"User": {
+			{"id": "u1", "name": "Ada"},
+			{"id": "u2", "name": "Grace"},
+		},
+		"Post": {
+			{"id": "p1", "authorId": "u1", "title": "Engines", "views": 10, "status": "published"},
+			{"id": "p2", "authorId": "u1", "title": "Notes", "views": 30, "status": "draft"},
+			{"id": "p3", "authorId": "u2", "title": "Compilers", "views": 20, "status": "published"},
+			{"id": "p4", "authorId": "u2", "title": "Bugs", "views": nil, "status": "published"},
+		},
+		"Comment": {
+			{"id": "c1", "PostId": "p1", "authorId": "u2", "body": "Great"},
+			{"id": "c2", "PostId": "p1", "authorId": "u1", "body": "Thanks"},
+			{"id": "c3", "PostId": "p3", "authorId": "u1", "body": "Nice"},
+		},
+	}
+	for model, rows := range seed {
+		if err := engine.SeedData(model, rows); err != nil {
+			t.Fatalf("SeedData(%s) failed: %v", model, err)
+		}
+	}
+	return engine
+}
+
+func ids(rows []mock.Row) []string {
+	out := []string{}
+	for _, row := range rows {
+		out = append(out, row["id"].(string))
+	}
+	return out
+}
+
+func TestExecuteFiltersOrdersAndWindows(t *testing.T) {
+	engine := seededEngine(t)
+
+	tcs := []struct {
+		name  string
+		query *types.Query
+		want  []string
+	}{
+		{
+			name:  "where",
+			query: &types.Query{Model: "Post", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("status", "published"), types.Gt("views", 15)}}},
+			want:  []string{"p3"},
+		},
+		{
+			name: "or and not",
+			query: &types.Query{Model: "Post", Where: &types.Filter{
+				Or:  &[]types.Filter{{Conditions: &[]types.Condition{types.StartsWith("title", "En")}}, {Conditions: &[]types.Condition{types.IsNull("views")}}},
+				Not: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p4")}},
+			}},
+			want: []string{"p1"},
+		},
+		{
+			name:  "order nulls last ascending",
+			query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "views"}}},
+			want:  []string{"p1", "p3", "p2", "p4"},
+		},
+		{
+			name:  "order descending with limit and offset",
+			query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "views", Descending: boolPtr(true), NullsFirst: boolPtr(false)}}, Limit: intPtr(2), Offset: intPtr(1)},
+			want:  []string{"p3", "p1"},
+		},
+		{
+			name:  "distinct",
+			query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "id"}}, Distinct: &[]string{"authorId"}},
+			want:  []string{"p1", "p3"},
+		},
+		{
+			name: "distinct_on keeps a row per author before the limit",
+			query: &types.Query{
+				Model: "Post",
+				DistinctOn: &types.DistinctOn{
+					Fields:  []string{"authorId"},
+					OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "views", Descending: boolPtr(true), NullsFirst: boolPtr(false)}},
+				},
+				OrderBy: &[]types.OrderBy{{Field: "views"}},
+				Limit:   intPtr(1),
+			},
+			want: []string{"p3"},
+		},
+		{
+			name:  "like",
+			query: &types.Query{Model: "Post", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "title", Op: "ilike", Value: "%O%"}}}},
+			want:  []string{"p2", "p3"},
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := engine.Execute(types.Statement{Query: tt.query})
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got := ids(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecutePolicy(t *testing.T) {
+	engine := seededEngine(t)
+	published := &types.Filter{Conditions: &[]types.Condition{types.Eq("status", "published")}}
+	policy := &types.Filter{Conditions: &[]types.Condition{types.Eq("authorId", "u2")}}
+
+	tcs := []struct {
+		name string
+		stmt types.Statement
+		want []string
+	}{
+		{"policy alone", types.Statement{Query: &types.Query{Model: "Post"}, Policy: policy}, []string{"p3", "p4"}},
+		{"policy and where", types.Statement{Query: &types.Query{Model: "Post", Where: published, OrderBy: &[]types.OrderBy{{Field: "id"}}}, Policy: policy}, []string{"p3", "p4"}},
+		{"where alone", types.Statement{Query: &types.Query{Model: "Post", Where: published}}, []string{"p1", "p3", "p4"}},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, err := engine.Execute(tt.stmt)
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+			if got := ids(rows); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecuteIncludes(t *testing.T) {
+	engine := seededEngine(t)
+
+	rows, err := engine.Execute(types.Statement{
+		Query: &types.Query{Model: "User", Fields: &[]string{"id"}, Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "u1")}}},
+		Includes: []types.Include{{
+			Query:    &types.Query{Model: "posts", Fields: &[]string{"id", "title"}, OrderBy: &[]types.OrderBy{{Field: "id"}}},
+			Includes: []types.Include{{Query: &types.Query{Model: "comments", Fields: &[]string{"id"}, Limit: intPtr(1)}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := []mock.Row{{
+		"id": "u1",
+		"posts": []interface{}{
+			mock.Row{"id": "p1", "title": "Engines", "comments": []interface{}{mock.Row{"id": "c1"}}},
+			mock.Row{"id": "p2", "title": "Notes", "comments": []interface{}{}},
+		},
+	}}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Execute() = %v, want %v", rows, want)
+	}
+
+	// A "one" relation loads a single row
+	rows, err = engine.Execute(types.Statement{
+		Query:    &types.Query{Model: "Comment", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "c1")}}},
+		Includes: []types.Include{{Query: &types.Query{Model: "author", Fields: &[]string{"name"}}}},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if author := rows[0]["author"]; !reflect.DeepEqual(author, mock.Row{"name": "Grace"}) {
+		t.Errorf("author = %v, want Grace", author)
+	}
+}
+
+func TestExecuteRelationFilters(t *testing.T) {
+	engine := seededEngine(t)
+	some, none := "some", "none"
+
+	rows, err := engine.Execute(types.Statement{
+		Query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "id"}}},
+		Includes: []types.Include{{
+			Query: &types.Query{Model: "comments", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("authorId", "u1")}}},
+			Kind:  &some,
+		}},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"p1", "p3"}) {
+		t.Errorf("some = %v, want [p1 p3]", got)
+	}
+	if _, loaded := rows[0]["comments"]; loaded {
+		t.Error("A relation filter without fields should not load the relation")
+	}
+
+	rows, err = engine.Execute(types.Statement{
+		Query:    &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "id"}}},
+		Includes: []types.Include{{Query: &types.Query{Model: "comments"}, Kind: &none}},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"p2", "p4"}) {
+		t.Errorf("none = %v, want [p2 p4]", got)
+	}
+}
+
+func TestExecuteRelationCount(t *testing.T) {
+	engine := seededEngine(t)
+	count := "count"
+	stmt := types.Statement{
+		Query: &types.Query{Model: "User", OrderBy: &[]types.OrderBy{{Field: "id"}}},
+		Includes: []types.Include{{
+			Query: &types.Query{Model: "posts"},
+			Kind:  &count,
+			Count: &types.RelationCount{Op: "gt", Value: 2},
+		}},
+	}
+
+	rows, err := engine.Execute(stmt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); len(got) != 0 {
+		t.Errorf("more than 2 posts = %v, want none", got)
+	}
+	added, err := engine.AddQuery(mock.AddQueryRequest{Shape: stmt, ResultHint: mock.ResultHint("User", rows)})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	// Ada's third post crosses the threshold, so it must evict the shape.
+	resp, err := engine.Invalidate(types.Mutation{Changes: []types.Change{{
+		Model:  "Post",
+		Action: "insert",
+		Sets:   []types.KV{{Field: "id", Value: "p5"}, {Field: "authorId", Value: "u1"}},
+	}}})
+	if err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Evict, []string{added.ShapeID}) {
+		t.Errorf("Evict = %v, want [%s]", resp.Evict, added.ShapeID)
+	}
+
+	rows, err = engine.Execute(stmt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"u1"}) {
+		t.Errorf("Execute after insert = %v, want [u1]", got)
+	}
+}
+
+func TestExecuteCursorPagination(t *testing.T) {
+	engine := seededEngine(t)
+	orderBy := []types.OrderBy{{Field: "id"}}
+
+	after, err := pagination.CursorFromRow(orderBy, map[string]any{"id": "p1"})
+	if err != nil {
+		t.Fatalf("CursorFromRow failed: %v", err)
+	}
+	rows, err := engine.Execute(types.Statement{
+		Query:      &types.Query{Model: "Post", OrderBy: &orderBy},
+		Pagination: &types.Pagination{First: intPtr(2), After: &after},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"p2", "p3"}) {
+		t.Errorf("Execute() = %v, want [p2 p3]", got)
+	}
+
+	// {"id":"p1","title":"Go"} was not built for an order by id alone
+	extra := "eyJpZCI6InAxIiwidGl0bGUiOiJHbyJ9"
+	if _, err := engine.Execute(types.Statement{
+		Query:      &types.Query{Model: "Post", OrderBy: &orderBy},
+		Pagination: &types.Pagination{First: intPtr(2), After: &extra},
+	}); err == nil {
+		t.Error("Execute should reject a cursor with fields outside the order")
+	}
+}
+
+func TestExecuteRejectsUnsupported(t *testing.T) {
+	engine := seededEngine(t)
+
+	if _, err := engine.Execute(types.Statement{
+		Query: &types.Query{Model: "Post", Where: &types.Filter{Conditions: &[]types.Condition{{Field: "title", Op: "custom:soundex", Value: "x"}}}},
+	}); err == nil {
+		t.Error("Execute should reject custom operators")
+	}
+	if _, err := engine.Execute(types.Statement{
+		Query:   &types.Query{Model: "Post"},
+		GroupBy: &[]string{"authorId"},
+	}); err == nil {
+		t.Error("Execute should reject group_by")
+	}
+	if _, err := engine.Execute(types.Statement{
+		Query: &types.Query{Model: "Post", Joins: &[]types.Join{types.InnerJoin("author")}},
+	}); err == nil {
+		t.Error("Execute should reject joins")
+	}
+	if _, err := mock.NewMockEngine(mock.MockEngineConfig{}).Execute(types.Statement{
+		Query:    &types.Query{Model: "Post"},
+		Includes: []types.Include{{Query: &types.Query{Model: "comments"}}},
+	}); err == nil {
+		t.Error("Execute should require a schema for includes")
+	}
+}
+
+func TestExecuteEndToEnd(t *testing.T) {
+	engine := seededEngine(t)
+	stmt := types.Statement{Query: &types.Query{
+		Model: "Post",
+		Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("authorId", "u2")}},
+	}}
+
+	rows, err := engine.Execute(stmt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	added, err := engine.AddQuery(mock.AddQueryRequest{Shape: stmt, ResultHint: mock.ResultHint("Post", rows)})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	resp, err := engine.Invalidate(types.Mutation{Changes: []types.Change{{
+		Model:  "Post",
+		Action: "update",
+		Sets:   []types.KV{{Field: "authorId", Value: "u2"}},
+		Where:  &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}},
+	}}})
+	if err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Evict, []string{added.ShapeID}) {
+		t.Errorf("Evict = %v, want [%s]", resp.Evict, added.ShapeID)
+	}
+
+	rows, err = engine.Execute(stmt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"p1", "p3", "p4"}) {
+		t.Errorf("Execute after update = %v, want [p1 p3 p4]", got)
+	}
+}
+
+func TestExecuteSoftDeletes(t *testing.T) {
+	schema := mock.AppSchema{Version: 1, Models: []mock.Model{
+		{Name: "Post", ID: mock.IDConfig{Kind: "string"}, SoftDelete: "deletedAt"},
+	}}
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	if err := engine.SetSchema(schema); err != nil {
+		t.Fatalf("SetSchema failed: %v", err)
+	}
+	if err := engine.SeedData("Post", []mock.Row{
+		{"id": "p1", "status": "published", "deletedAt": nil},
+		{"id": "p2", "status": "published", "deletedAt": "2024-01-01"},
+		{"id": "p3", "status": "draft"},
+	}); err != nil {
+		t.Fatalf("SeedData failed: %v", err)
+	}
+	include, only := "include", "only"
+	for _, tt := range []struct {
+		deleted *string
+		want    []string
+	}{
+		{nil, []string{"p1", "p3"}},
+		{&include, []string{"p1", "p2", "p3"}},
+		{&only, []string{"p2"}},
+	} {
+		rows, err := engine.Execute(types.Statement{Query: &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "id"}}, Deleted: tt.deleted}})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if got := ids(rows); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Execute(deleted %v) = %v, want %v", tt.deleted, got, tt.want)
+		}
+	}
+
+	// Restoring p2 adds it to the published posts, as an insert would
+	stmt := types.Statement{Query: &types.Query{Model: "Post", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("status", "published")}}}}
+	rows, err := engine.Execute(stmt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	added, err := engine.AddQuery(mock.AddQueryRequest{Shape: stmt, ResultHint: mock.ResultHint("Post", rows)})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	if field, _ := added.Dependencies.GetSoftDeleteField(); field != "deletedAt" {
+		t.Errorf("SoftDeleteField = %q, want deletedAt", field)
+	}
+	restore := types.Mutation{Changes: []types.Change{{
+		Model:  "Post",
+		Action: "update",
+		Sets:   []types.KV{{Field: "deletedAt", Value: nil}},
+		Where:  &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p2")}},
+	}}}
+	explained, err := engine.ExplainInvalidation(mock.ExplainRequest{Mutation: restore, ShapeID: added.ShapeID})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+	if !slices.Contains(explained.Reasons, "soft_delete") {
+		t.Errorf("Reasons = %v, want soft_delete among them", explained.Reasons)
+	}
+	if _, err := engine.Invalidate(restore); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	rows, err = engine.Execute(stmt)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"p1", "p2"}) {
+		t.Errorf("Execute after restore = %v, want [p1 p2]", got)
+	}
+}
+
+func TestCommitTxAppliesChanges(t *testing.T) {
+	engine := seededEngine(t)
+	stmt := types.Statement{Query: &types.Query{Model: "User", OrderBy: &[]types.OrderBy{{Field: "id"}}}}
+
+	if err := engine.BeginTx("tx1"); err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := engine.StageChange("tx1", types.Change{Model: "User", Action: "insert", Sets: []types.KV{{Field: "id", Value: "u3"}}}); err != nil {
+		t.Fatalf("StageChange failed: %v", err)
+	}
+	if err := engine.StageChange("tx1", types.Change{
+		Model:  "User",
+		Action: "delete",
+		Where:  &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "u1")}},
+	}); err != nil {
+		t.Fatalf("StageChange failed: %v", err)
+	}
+
+	rows, _ := engine.Execute(stmt)
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"u1", "u2"}) {
+		t.Errorf("Staged changes should not be visible, got %v", got)
+	}
+
+	if _, err := engine.CommitTx("tx1"); err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+	rows, _ = engine.Execute(stmt)
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"u2", "u3"}) {
+		t.Errorf("Execute after commit = %v, want [u2 u3]", got)
+	}
+}
+
+func TestInvalidateTransactionIsAtomic(t *testing.T) {
+	engine := seededEngine(t)
+	stmt := types.Statement{Query: &types.Query{Model: "User", OrderBy: &[]types.OrderBy{{Field: "id"}}}}
+
+	insert := types.Mutation{Changes: []types.Change{
+		{Model: "User", Action: "insert", Sets: []types.KV{{Field: "id", Value: "u3"}}},
+	}}
+	// An update without a where fails to apply
+	bad := types.Mutation{Changes: []types.Change{
+		{Model: "User", Action: "update", Sets: []types.KV{{Field: "name", Value: "x"}}},
+	}}
+	if _, err := engine.InvalidateTransaction(types.Transaction{Seq: 1, Mutations: []types.Mutation{insert, bad}}); err == nil {
+		t.Fatal("Expected error for a change that cannot be applied")
+	}
+	rows, _ := engine.Execute(stmt)
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"u1", "u2"}) {
+		t.Errorf("A failed transaction should leave data unchanged, got %v", got)
+	}
+
+	// The failed transaction did not consume its seq
+	if _, err := engine.InvalidateTransaction(types.Transaction{Seq: 1, Mutations: []types.Mutation{insert}}); err != nil {
+		t.Fatalf("InvalidateTransaction failed: %v", err)
+	}
+	rows, _ = engine.Execute(stmt)
+	if got := ids(rows); !reflect.DeepEqual(got, []string{"u1", "u2", "u3"}) {
+		t.Errorf("Execute after transaction = %v, want [u1 u2 u3]", got)
+	}
+}
+
+func intPtr(i int) *int    { return &i }
+func boolPtr(b bool) *bool { return &b }
@@ -0,0 +1,104 @@
+package mock
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// EvictionEvent reports one shape evicted by Invalidate,
+// InvalidateTransaction, CommitTx, EvictExpired or EvictPolicy.
+type EvictionEvent struct {
+	ShapeID string
+	Reason  string        // "mutation" | "custom" | "expired" | "policy"
+	Change  *types.Change // first change that evicted the shape; nil unless Reason is "mutation"
+	TxID    string        // transaction of the change, if any
+}
+
+// Subscribe registers fn to receive an EvictionEvent for every shape the
+// engine evicts, the way production caches consume invalidation. Events are
+// delivered in order on a goroutine of their own, after the evicting call has
+// returned, so fn may call back into the engine.
+//
+// The returned function cancels the subscription; events not yet delivered
+// are dropped. Subscriptions survive Reset.
+func (m *MockEngine) Subscribe(fn func(EvictionEvent)) (unsubscribe func()) {
+	s := &subscriber{
+		fn:     fn,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+
+	m.mu.Lock()
+	m.subs = append(m.subs, s)
+	m.mu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			for i, sub := range m.subs {
+				if sub == s {
+					m.subs = append(m.subs[:i:i], m.subs[i+1:]...)
+					break
+				}
+			}
+			m.mu.Unlock()
+			close(s.done)
+		})
+	}
+}
+
+// subscriber queues events so publishing never blocks on fn.
+type subscriber struct {
+	fn     func(EvictionEvent)
+	mu     sync.Mutex
+	queue  []EvictionEvent
+	notify chan struct{}
+	done   chan struct{}
+}
+
+func (s *subscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.notify:
+		}
+
+		s.mu.Lock()
+		events := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, event := range events {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.fn(event)
+		}
+	}
+}
+
+// publish queues events for every subscriber, sorted by shape ID. Callers
+// hold m.mu, which keeps events from concurrent calls in call order.
+func (m *MockEngine) publish(events []EvictionEvent) {
+	if len(events) == 0 || len(m.subs) == 0 {
+		return
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].ShapeID < events[j].ShapeID })
+
+	for _, s := range m.subs {
+		s.mu.Lock()
+		s.queue = append(s.queue, events...)
+		s.mu.Unlock()
+		select {
+		case s.notify <- struct{}{}:
+		default:
+		}
+	}
+}
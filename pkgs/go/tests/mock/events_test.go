@@ -0,0 +1,158 @@
+package mock_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// collect subscribes to engine and returns a channel of its events.
+func collect(t *testing.T, engine *mock.MockEngine) <-chan mock.EvictionEvent {
+	t.Helper()
+	events := make(chan mock.EvictionEvent, 16)
+	unsubscribe := engine.Subscribe(func(e mock.EvictionEvent) { events <- e })
+	t.Cleanup(unsubscribe)
+	return events
+}
+
+func next(t *testing.T, events <-chan mock.EvictionEvent) mock.EvictionEvent {
+	t.Helper()
+	select {
+	case e := <-events:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for an eviction event")
+		return mock.EvictionEvent{}
+	}
+}
+
+func change(model, action string) types.Change {
+	return types.Change{Model: model, Action: action}
+}
+
+func addShape(t *testing.T, engine *mock.MockEngine, model string, ttl *int) string {
+	t.Helper()
+	resp, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape:      types.Statement{Query: &types.Query{Model: model}},
+		ResultHint: map[string][]interface{}{model: {map[string]interface{}{"id": "1"}}},
+		TTL:        ttl,
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	return resp.ShapeID
+}
+
+func TestSubscribeInvalidate(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	events := collect(t, engine)
+	posts := addShape(t, engine, "posts", nil)
+	addShape(t, engine, "users", nil)
+
+	update := change("posts", "update")
+	if _, err := engine.Invalidate(types.Mutation{Changes: []types.Change{change("comments", "insert"), update}}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+
+	e := next(t, events)
+	if e.ShapeID != posts || e.Reason != "mutation" || e.TxID != "" {
+		t.Errorf("event = %+v, want mutation eviction of %s", e, posts)
+	}
+	if e.Change == nil || e.Change.Model != "posts" || e.Change.Action != "update" {
+		t.Errorf("event change = %+v, want the posts update", e.Change)
+	}
+	select {
+	case e := <-events:
+		t.Errorf("unexpected event %+v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSubscribeCommitTx(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	events := collect(t, engine)
+	posts := addShape(t, engine, "posts", nil)
+
+	if err := engine.BeginTx("tx1"); err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := engine.StageChange("tx1", change("posts", "delete")); err != nil {
+		t.Fatalf("StageChange failed: %v", err)
+	}
+	if _, err := engine.CommitTx("tx1"); err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+
+	e := next(t, events)
+	if e.ShapeID != posts || e.TxID != "tx1" || e.Change == nil || e.Change.Action != "delete" {
+		t.Errorf("event = %+v, want delete eviction of %s in tx1", e, posts)
+	}
+}
+
+func TestSubscribeExpiredAndCustom(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	engine := mock.NewMockEngine(mock.MockEngineConfig{Now: func() time.Time { return now }})
+	events := collect(t, engine)
+	ttl := 60
+	shape := addShape(t, engine, "posts", &ttl)
+
+	now = now.Add(time.Minute)
+	if _, err := engine.EvictExpired(); err != nil {
+		t.Fatalf("EvictExpired failed: %v", err)
+	}
+	if e := next(t, events); e.ShapeID != shape || e.Reason != "expired" || e.Change != nil {
+		t.Errorf("event = %+v, want expiry of %s", e, shape)
+	}
+
+	engine.SetEvictList([]string{"b", "a"})
+	if _, err := engine.Invalidate(types.Mutation{Changes: []types.Change{change("posts", "insert")}}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	for _, want := range []string{"a", "b"} {
+		if e := next(t, events); e.ShapeID != want || e.Reason != "custom" {
+			t.Errorf("event = %+v, want custom eviction of %s", e, want)
+		}
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	addShape(t, engine, "posts", nil)
+
+	kept := collect(t, engine)
+	dropped := make(chan mock.EvictionEvent, 16)
+	unsubscribe := engine.Subscribe(func(e mock.EvictionEvent) { dropped <- e })
+	unsubscribe()
+	unsubscribe()
+
+	if _, err := engine.Invalidate(types.Mutation{Changes: []types.Change{change("posts", "insert")}}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	next(t, kept)
+	select {
+	case e := <-dropped:
+		t.Errorf("unsubscribed callback received %+v", e)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestSubscriberMayCallEngine(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	addShape(t, engine, "posts", nil)
+
+	done := make(chan bool, 1)
+	engine.Subscribe(func(e mock.EvictionEvent) {
+		_, ok := engine.GetDependencies(e.ShapeID)
+		done <- ok
+	})
+	if _, err := engine.Invalidate(types.Mutation{Changes: []types.Change{change("posts", "insert")}}); err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("subscriber did not run")
+	}
+}
@@ -0,0 +1,126 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// ErrInjected is matched by every error the mock returns because of
+// MockEngineConfig.Faults rather than because of its input.
+var ErrInjected = errors.New("mock: injected failure")
+
+// Faults makes engine methods slow or unreliable, so SDK retry, backoff and
+// timeout logic can be tested without the WASM engine. Every map and Failure
+// is keyed by method name ("AddQuery", "Invalidate", "CommitTx", ...); the
+// key "*" applies to every method without an entry of its own.
+//
+// An injected failure happens before the call has any effect and is not
+// recorded in GetCalls. Reset and GetVersion cannot fail, so only Latency
+// applies to them.
+type Faults struct {
+	Latency   map[string]time.Duration // delay before each call
+	ErrorRate map[string]float64       // probability in [0, 1] that a call fails
+	Failures  []Failure                // scripted failures, checked before ErrorRate
+	Seed      int64                    // seed for ErrorRate (default: 1)
+	Sleep     func(time.Duration)      // called with each delay (default: time.Sleep)
+}
+
+// Failure scripts the error returned by one call of a method.
+type Failure struct {
+	Method string
+	Call   int   // 1-based: Call 3 fails the third call
+	Err    error // returned as-is; nil returns an *InjectedError
+}
+
+// InjectedError reports a call failed by ErrorRate or a scripted Failure.
+type InjectedError struct {
+	Method string
+	Call   int
+}
+
+func (e *InjectedError) Error() string {
+	return fmt.Sprintf("mock: injected failure in %s (call %d)", e.Method, e.Call)
+}
+
+// Unwrap makes errors.Is match ErrInjected.
+func (e *InjectedError) Unwrap() error {
+	return ErrInjected
+}
+
+// faultState counts calls per method. It has its own lock so delays never
+// hold the engine lock.
+type faultState struct {
+	mu    sync.Mutex
+	calls map[string]int
+	rand  *rand.Rand
+}
+
+// fault applies the configured latency for method, then decides whether this
+// call fails. It must be called before taking m.mu.
+func (m *MockEngine) fault(method string) error {
+	f := m.config.Faults
+	if f == nil {
+		return nil
+	}
+
+	m.delay(method)
+
+	m.faults.mu.Lock()
+	defer m.faults.mu.Unlock()
+	if m.faults.calls == nil {
+		m.faults.calls = make(map[string]int)
+		seed := f.Seed
+		if seed == 0 {
+			seed = 1
+		}
+		m.faults.rand = rand.New(rand.NewSource(seed))
+	}
+	m.faults.calls[method]++
+	call := m.faults.calls[method]
+
+	for _, failure := range f.Failures {
+		if failure.Method == method && failure.Call == call {
+			if failure.Err != nil {
+				return failure.Err
+			}
+			return &InjectedError{Method: method, Call: call}
+		}
+	}
+	if rate := lookup(f.ErrorRate, method); rate > 0 && m.faults.rand.Float64() < rate {
+		return &InjectedError{Method: method, Call: call}
+	}
+	return nil
+}
+
+// delay applies the configured latency for a method that cannot fail.
+func (m *MockEngine) delay(method string) {
+	f := m.config.Faults
+	if f == nil {
+		return
+	}
+	if d := lookup(f.Latency, method); d > 0 {
+		if f.Sleep != nil {
+			f.Sleep(d)
+		} else {
+			time.Sleep(d)
+		}
+	}
+}
+
+// resetFaults restarts call counting and the ErrorRate sequence.
+func (m *MockEngine) resetFaults() {
+	m.faults.mu.Lock()
+	defer m.faults.mu.Unlock()
+	m.faults.calls = nil
+	m.faults.rand = nil
+}
+
+func lookup[V any](m map[string]V, method string) V {
+	if v, ok := m[method]; ok {
+		return v
+	}
+	return m["*"]
+}
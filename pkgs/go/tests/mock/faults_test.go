@@ -0,0 +1,157 @@
+package mock_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func postsQuery() mock.AddQueryRequest {
+	return mock.AddQueryRequest{Shape: types.Statement{Query: &types.Query{Model: "posts"}}}
+}
+
+func TestScriptedFailure(t *testing.T) {
+	retry := errors.New("engine busy")
+	engine := mock.NewMockEngine(mock.MockEngineConfig{
+		TrackCalls: true,
+		Faults: &mock.Faults{Failures: []mock.Failure{
+			{Method: "AddQuery", Call: 3},
+			{Method: "AddQuery", Call: 4, Err: retry},
+		}},
+	})
+
+	for call := 1; call <= 5; call++ {
+		_, err := engine.AddQuery(postsQuery())
+		switch call {
+		case 3:
+			var ierr *mock.InjectedError
+			if !errors.As(err, &ierr) || ierr.Method != "AddQuery" || ierr.Call != 3 {
+				t.Errorf("call 3 error = %v, want InjectedError for AddQuery call 3", err)
+			}
+			if !errors.Is(err, mock.ErrInjected) {
+				t.Errorf("errors.Is(%v, ErrInjected) = false", err)
+			}
+		case 4:
+			if err != retry {
+				t.Errorf("call 4 error = %v, want %v", err, retry)
+			}
+		default:
+			if err != nil {
+				t.Errorf("call %d failed: %v", call, err)
+			}
+		}
+	}
+
+	// Failed calls have no effect and are not tracked
+	if calls := engine.GetCalls(); len(calls.AddQuery) != 3 {
+		t.Errorf("Expected 3 tracked AddQuery calls, got %d", len(calls.AddQuery))
+	}
+
+	// Other methods keep their own count
+	if _, err := engine.Invalidate(types.Mutation{}); err != nil {
+		t.Errorf("Invalidate failed: %v", err)
+	}
+}
+
+func TestResetRestartsCallCounts(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{
+		Faults: &mock.Faults{Failures: []mock.Failure{{Method: "BeginTx", Call: 1}}},
+	})
+
+	if err := engine.BeginTx("tx1"); !errors.Is(err, mock.ErrInjected) {
+		t.Fatalf("first BeginTx error = %v, want ErrInjected", err)
+	}
+	if err := engine.BeginTx("tx1"); err != nil {
+		t.Fatalf("second BeginTx failed: %v", err)
+	}
+
+	engine.Reset()
+	if err := engine.BeginTx("tx1"); !errors.Is(err, mock.ErrInjected) {
+		t.Errorf("BeginTx after Reset error = %v, want ErrInjected", err)
+	}
+}
+
+func TestErrorRate(t *testing.T) {
+	run := func(seed int64) []bool {
+		engine := mock.NewMockEngine(mock.MockEngineConfig{
+			Faults: &mock.Faults{ErrorRate: map[string]float64{"*": 0.5, "GetVersion": 1}, Seed: seed},
+		})
+		var failed []bool
+		for i := 0; i < 200; i++ {
+			_, err := engine.ComputeShapeID(postsQuery().Shape)
+			failed = append(failed, err != nil)
+		}
+		return failed
+	}
+
+	first := run(42)
+	n := 0
+	for _, f := range first {
+		if f {
+			n++
+		}
+	}
+	if n < 60 || n > 140 {
+		t.Errorf("%d of 200 calls failed at rate 0.5", n)
+	}
+
+	second := run(42)
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("call %d differs between runs with the same seed", i+1)
+		}
+	}
+
+	never := mock.NewMockEngine(mock.MockEngineConfig{
+		Faults: &mock.Faults{ErrorRate: map[string]float64{"*": 1, "SetSchema": 0}},
+	})
+	if err := never.SetSchema(mock.AppSchema{Version: 1}); err != nil {
+		t.Errorf("SetSchema with rate 0 failed: %v", err)
+	}
+}
+
+func TestLatency(t *testing.T) {
+	var slept []time.Duration
+	engine := mock.NewMockEngine(mock.MockEngineConfig{
+		Faults: &mock.Faults{
+			Latency: map[string]time.Duration{"*": time.Millisecond, "CommitTx": time.Second},
+			Sleep:   func(d time.Duration) { slept = append(slept, d) },
+		},
+	})
+
+	if err := engine.BeginTx("tx1"); err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if _, err := engine.CommitTx("tx1"); err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+	engine.GetVersion()
+
+	want := []time.Duration{time.Millisecond, time.Second, time.Millisecond}
+	if len(slept) != len(want) {
+		t.Fatalf("slept %v, want %v", slept, want)
+	}
+	for i := range want {
+		if slept[i] != want[i] {
+			t.Errorf("slept %v, want %v", slept, want)
+			break
+		}
+	}
+}
+
+func TestLatencySleeps(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{
+		Faults: &mock.Faults{Latency: map[string]time.Duration{"ComputeShapeID": 20 * time.Millisecond}},
+	})
+
+	start := time.Now()
+	if _, err := engine.ComputeShapeID(postsQuery().Shape); err != nil {
+		t.Fatalf("ComputeShapeID failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("ComputeShapeID returned after %v, want at least 20ms", elapsed)
+	}
+}
@@ -11,29 +11,54 @@ type AppSchema struct {
 	Models  []Model `json:"models"`
 }
 
-// Model represents a model in the schema
+// Model represents a model in the schema.
+// SoftDelete names the field marking soft-deleted rows, e.g. "deletedAt": a
+// row whose field is non-null is deleted, and queries read only live rows
+// unless their deleted says otherwise. An update setting it then counts as
+// a delete or insert for invalidation (see Dependencies.SoftDeleteField).
 type Model struct {
-	Name      string     `json:"name"`
-	ID        IDConfig   `json:"id"`
-	Relations []Relation `json:"relations,omitempty"`
+	Name       string     `json:"name"`
+	ID         IDConfig   `json:"id"`
+	Fields     []string   `json:"fields,omitempty"` // optional; used for result shape inference
+	Relations  []Relation `json:"relations,omitempty"`
+	SoftDelete string     `json:"soft_delete,omitempty"`
 }
 
-// IDConfig represents ID field configuration
+// IDConfig represents ID field configuration.
+// Fields lists the primary key fields in key order, e.g. ["uuid"] or
+// ["orderId", "lineNo"]; omitted means MockEngineConfig.IDField, else "id".
+// A record of a composite key is tracked under types.RecordID of its values.
 type IDConfig struct {
-	Kind string `json:"kind"`
+	Kind   string   `json:"kind"`
+	Fields []string `json:"fields,omitempty"`
 }
 
-// Relation represents a model relation
+// Relation represents a model relation.
+// Kind is "one" or "many". Fields are the join fields on the owning model and
+// References the matching fields on the target; both may be omitted to use
+// the conventional keys (see ResolvedInclude), which assume single-field
+// "id" keys; relations to or from composite keys must declare both.
 type Relation struct {
-	Name   string `json:"name"`
-	Target string `json:"target"`
-	Kind   string `json:"kind"`
+	Name       string   `json:"name"`
+	Target     string   `json:"target"`
+	Kind       string   `json:"kind"`
+	Fields     []string `json:"fields,omitempty"`
+	References []string `json:"references,omitempty"`
 }
 
-// AddQueryRequest wraps a shape with optional result hint
+// AddQueryRequest wraps a shape with optional result hint.
+// ResultHint maps models to result rows; rows of included relations may be
+// nested under the relation name, as ORMs return them. Root rows are in page
+// order: when a shape with an orderBy and First (or Limit) gets a full page,
+// its last row becomes Dependencies.LastRow. For a shape with a GroupBy, the
+// distinct group keys of the root rows become Dependencies.GroupBy.
+// TTL (seconds) and ValidUntil (RFC 3339) make the shape expire by time, for
+// sources whose changes never arrive as Mutations; the earlier bound wins.
 type AddQueryRequest struct {
 	Shape      types.Statement          `json:"shape"`
 	ResultHint map[string][]interface{} `json:"result_hint,omitempty"`
+	TTL        *int                     `json:"ttl,omitempty"`
+	ValidUntil *string                  `json:"valid_until,omitempty"`
 }
 
 // AddQueryResponse contains shape ID and dependencies
@@ -52,16 +77,44 @@ type InvalidateResponse struct {
 	Evict []string `json:"evict"`
 }
 
+// StageChangeRequest is a change staged in an open transaction
+type StageChangeRequest struct {
+	TxID   string       `json:"tx_id"`
+	Change types.Change `json:"change"`
+}
+
 // ExplainRequest contains mutation and shape ID for explanation
 type ExplainRequest struct {
 	Mutation types.Mutation `json:"mutation"`
 	ShapeID  string         `json:"shape_id"`
 }
 
-// ExplainResponse explains why a shape would be invalidated
+// ExplainResponse explains why a shape would be invalidated. Reasons lists
+// the distinct reason codes; Details has one entry for each change and
+// reason, in change order.
 type ExplainResponse struct {
-	Invalidate bool     `json:"invalidate"`
-	Reasons    []string `json:"reasons"`
+	Invalidate bool            `json:"invalidate"`
+	Reasons    []string        `json:"reasons"`
+	Details    []ExplainReason `json:"details"`
+}
+
+// ExplainReason is one reason a change invalidates a shape.
+// Kind is the reason code: "record_membership", "filter_dependency",
+// "relation_dependency", "pagination_boundary", "group_by", "window",
+// "soft_delete" or "policy". FilterPath locates the dependency that matched
+// in the shape's Dependencies (e.g. "filters[0]", "includes[1].includes[0]",
+// "last_row", "group_by", "window_fields", "soft_delete_field" or "policy")
+// and is empty for record membership. MatchedRecordIDs lists the tracked
+// records the change touches, when the engine can tell: the ID an insert
+// sets, or the records an update or delete selects, judged by the seeded rows
+// or, for unseeded models, by a where that tests only the id field with eq,
+// ne, in or notIn.
+type ExplainReason struct {
+	ChangeIndex      int      `json:"change_index"`
+	Kind             string   `json:"kind"`
+	Model            string   `json:"model"`
+	FilterPath       string   `json:"filter_path,omitempty"`
+	MatchedRecordIDs []string `json:"matched_record_ids,omitempty"`
 }
 
 // VersionInfo contains engine version information
@@ -71,12 +124,24 @@ type VersionInfo struct {
 	ABI      string `json:"abi"`
 }
 
-// Engine interface matching WASM exports
+// Engine interface matching WASM exports.
+//
+// Invalidate evicts immediately. Transactional writers instead BeginTx, stage
+// each change with StageChange, and CommitTx to evict for all staged changes
+// at once; RollbackTx discards staged changes without evicting anything.
+// Sources that deliver whole commits, such as change data capture, call
+// InvalidateTransaction with each types.Transaction in commit order.
 type Engine interface {
 	SetSchema(schema AppSchema) error
 	ComputeShapeID(statement types.Statement) (ShapeIDResponse, error)
 	AddQuery(request AddQueryRequest) (AddQueryResponse, error)
 	Invalidate(mutation types.Mutation) (InvalidateResponse, error)
+	InvalidateTransaction(tx types.Transaction) (InvalidateResponse, error)
+	EvictExpired() (InvalidateResponse, error)
+	BeginTx(txID string) error
+	StageChange(txID string, change types.Change) error
+	CommitTx(txID string) (InvalidateResponse, error)
+	RollbackTx(txID string) error
 	ExplainInvalidation(request ExplainRequest) (ExplainResponse, error)
 	Reset()
 	GetVersion() VersionInfo
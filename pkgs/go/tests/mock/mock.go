@@ -1,30 +1,47 @@
 package mock
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
 	"sync"
+	"time"
 
+	"github.com/bold-minds/includekit-spec/go/pagination"
 	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/invalidate"
 	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/walk"
 )
 
 // MockEngineConfig configures the mock engine behavior
 type MockEngineConfig struct {
 	ShapeIDGenerator func(types.Statement) string
-	EvictBehavior    string // "conservative" | "custom"
+	ShapeIDCache     *tests.ShapeIDCache // reuses the shape IDs of statements hashed before (optional)
+	EvictBehavior    string              // "conservative" | "custom"
 	CustomEvictList  []string
 	TrackCalls       bool
+	Now              func() time.Time // clock for TTL expiry (default: time.Now)
+	Strict           bool             // reject input outside the pure spec subset (see tests.Strict)
+	Faults           *Faults          // artificial latency and failures (see Faults)
+	IDField          string           // record ID field of models the schema gives no key (default: "id")
 }
 
 // MockEngineCalls tracks all method calls when TrackCalls is enabled
 type MockEngineCalls struct {
-	SetSchema           []AppSchema
-	ComputeShapeID      []types.Statement
-	AddQuery            []AddQueryRequest
-	Invalidate          []types.Mutation
-	ExplainInvalidation []ExplainRequest
-	Reset               []struct{}
-	GetVersion          []struct{}
+	SetSchema             []AppSchema
+	ComputeShapeID        []types.Statement
+	AddQuery              []AddQueryRequest
+	Invalidate            []types.Mutation
+	InvalidateTransaction []types.Transaction
+	EvictExpired          []struct{}
+	BeginTx               []string
+	StageChange           []StageChangeRequest
+	CommitTx              []string
+	RollbackTx            []string
+	ExplainInvalidation   []ExplainRequest
+	Reset                 []struct{}
+	GetVersion            []struct{}
 }
 
 // MockEngine implements the Engine interface for testing
@@ -32,21 +49,40 @@ type MockEngine struct {
 	mu     sync.RWMutex
 	schema *AppSchema
 	shapes map[string]types.Dependencies
+	roots  map[string]string // root model of each shape, for resolving includes
+	expiry map[string]time.Time
+	txs    map[string][]types.Change
+	seq    int // Seq of the last transaction applied, -1 before the first
+	data   map[string][]Row
 	calls  MockEngineCalls
 	config MockEngineConfig
+	faults faultState
+	subs   []*subscriber
 }
 
 // NewMockEngine creates a new mock engine
 func NewMockEngine(config MockEngineConfig) *MockEngine {
 	return &MockEngine{
 		shapes: make(map[string]types.Dependencies),
+		roots:  make(map[string]string),
+		expiry: make(map[string]time.Time),
+		txs:    make(map[string][]types.Change),
+		seq:    -1,
+		data:   make(map[string][]Row),
 		config: config,
 		calls:  MockEngineCalls{},
 	}
 }
 
-// SetSchema stores the application schema
+// SetSchema stores the application schema. Its relations resolve joined and
+// included relations to their target models, so a write to a model evicts
+// the shapes that join or include it, however deeply. Without a schema the
+// relation name stands in for the model.
 func (m *MockEngine) SetSchema(schema AppSchema) error {
+	if err := m.fault("SetSchema"); err != nil {
+		return err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -60,6 +96,10 @@ func (m *MockEngine) SetSchema(schema AppSchema) error {
 
 // ComputeShapeID computes the shape ID for a statement
 func (m *MockEngine) ComputeShapeID(stmt types.Statement) (ShapeIDResponse, error) {
+	if err := m.fault("ComputeShapeID"); err != nil {
+		return ShapeIDResponse{}, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -77,13 +117,23 @@ func (m *MockEngine) ComputeShapeID(stmt types.Statement) (ShapeIDResponse, erro
 
 // computeShapeIDInternal computes shape ID without locking (internal use)
 func (m *MockEngine) computeShapeIDInternal(stmt types.Statement) (string, error) {
+	if m.config.Strict {
+		if err := tests.ValidateQueryShape(&stmt, tests.Strict()); err != nil {
+			return "", err
+		}
+	}
+
 	var shapeID string
 	if m.config.ShapeIDGenerator != nil {
 		shapeID = m.config.ShapeIDGenerator(stmt)
 	} else {
 		// Use real shapeId computation
 		var err error
-		shapeID, err = tests.ComputeQueryShapeID(&stmt)
+		if m.config.ShapeIDCache != nil {
+			shapeID, err = m.config.ShapeIDCache.ShapeID(&stmt)
+		} else {
+			shapeID, err = tests.ComputeQueryShapeID(&stmt)
+		}
 		if err != nil {
 			return "", err
 		}
@@ -94,6 +144,10 @@ func (m *MockEngine) computeShapeIDInternal(stmt types.Statement) (string, error
 
 // AddQuery adds a query and returns its dependencies
 func (m *MockEngine) AddQuery(req AddQueryRequest) (AddQueryResponse, error) {
+	if err := m.fault("AddQuery"); err != nil {
+		return AddQueryResponse{}, err
+	}
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -109,14 +163,37 @@ func (m *MockEngine) AddQuery(req AddQueryRequest) (AddQueryResponse, error) {
 		return AddQueryResponse{}, err
 	}
 
+	specVersion := types.SpecVersion
 	deps := types.Dependencies{
-		ShapeID:  shapeID,
-		Records:  m.extractRecords(req),
-		Filters:  m.extractFilters(req.Shape),
-		Includes: req.Shape.Includes,
+		SpecVersion:     &specVersion,
+		ShapeID:         shapeID,
+		Records:         m.extractRecords(req),
+		Filters:         m.extractFilters(req.Shape),
+		Includes:        req.Shape.Includes,
+		LastRow:         lastRow(req),
+		GroupBy:         groupBy(req),
+		WindowFields:    windowFields(req.Shape.GetQuery()),
+		SoftDeleteField: m.softDeleteField(req.Shape.GetQuery()),
+		Tenant:          req.Shape.Tenant,
+		Policy:          req.Shape.Policy,
+	}
+
+	expiresAt, ok, err := m.expiresAt(req)
+	if err != nil {
+		return AddQueryResponse{}, err
+	}
+	delete(m.expiry, shapeID)
+	if ok {
+		validUntil := expiresAt.UTC().Format(time.RFC3339Nano)
+		deps.TTL = req.TTL
+		deps.ValidUntil = &validUntil
+		m.expiry[shapeID] = expiresAt
 	}
 
 	m.shapes[shapeID] = deps
+	if req.Shape.Query != nil {
+		m.roots[shapeID] = req.Shape.Query.Model
+	}
 
 	return AddQueryResponse{
 		ShapeID:      shapeID,
@@ -124,36 +201,279 @@ func (m *MockEngine) AddQuery(req AddQueryRequest) (AddQueryResponse, error) {
 	}, nil
 }
 
-// Invalidate determines which shapes should be evicted
+// Invalidate determines which shapes should be evicted, and applies the
+// changes to any seeded data
 func (m *MockEngine) Invalidate(mutation types.Mutation) (InvalidateResponse, error) {
-	m.mu.RLock()
-	defer m.mu.RUnlock()
+	if err := m.fault("Invalidate"); err != nil {
+		return InvalidateResponse{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
 
 	if m.config.TrackCalls {
 		m.calls.Invalidate = append(m.calls.Invalidate, mutation)
 	}
 
+	if m.config.Strict {
+		if err := tests.ValidateMutationEvent(&mutation, tests.Strict()); err != nil {
+			return InvalidateResponse{}, err
+		}
+	}
+
+	if err := m.applyChanges(mutation.Changes); err != nil {
+		return InvalidateResponse{}, err
+	}
+	txID, _ := mutation.GetTxID()
+	return m.invalidateInternal([]types.Mutation{mutation}, txID), nil
+}
+
+// InvalidateTransaction applies every mutation of a transaction at once and
+// returns a single evict set covering all of them, each shape listed once.
+// Transactions must arrive in commit order: one whose Seq is not after the
+// last applied is rejected, so replays and reordered deliveries evict
+// nothing. If a change fails to apply, seeded data is left as it was and the
+// Seq is not consumed.
+func (m *MockEngine) InvalidateTransaction(tx types.Transaction) (InvalidateResponse, error) {
+	if err := m.fault("InvalidateTransaction"); err != nil {
+		return InvalidateResponse{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.TrackCalls {
+		m.calls.InvalidateTransaction = append(m.calls.InvalidateTransaction, tx)
+	}
+
+	if m.config.Strict {
+		if err := tests.ValidateTransaction(&tx, tests.Strict()); err != nil {
+			return InvalidateResponse{}, err
+		}
+	}
+	if tx.Seq <= m.seq {
+		return InvalidateResponse{}, fmt.Errorf("transaction seq %d is not after %d", tx.Seq, m.seq)
+	}
+
+	var changes []types.Change
+	for _, mutation := range tx.Mutations {
+		changes = append(changes, mutation.Changes...)
+	}
+
+	// applyChanges replaces a model's rows rather than editing them, so a
+	// shallow copy is enough to roll back.
+	data := make(map[string][]Row, len(m.data))
+	for model, rows := range m.data {
+		data[model] = rows
+	}
+	if err := m.applyChanges(changes); err != nil {
+		m.data = data
+		return InvalidateResponse{}, err
+	}
+	m.seq = tx.Seq
+	return m.invalidateInternal(tx.Mutations, ""), nil
+}
+
+// invalidateInternal computes evictions and publishes them without locking
+// (internal use). A shape is checked against the changes of the mutations
+// of its own tenant, or of none.
+func (m *MockEngine) invalidateInternal(mutations []types.Mutation, txID string) InvalidateResponse {
+	var events []EvictionEvent
+
 	// Custom evict list
 	if m.config.EvictBehavior == "custom" && len(m.config.CustomEvictList) > 0 {
-		return InvalidateResponse{Evict: m.config.CustomEvictList}, nil
+		for _, shapeID := range m.config.CustomEvictList {
+			events = append(events, EvictionEvent{ShapeID: shapeID, Reason: "custom", TxID: txID})
+		}
+		m.publish(events)
+		return InvalidateResponse{Evict: m.config.CustomEvictList}
 	}
 
 	evict := []string{}
 
 	for shapeID, deps := range m.shapes {
-		for _, change := range mutation.Changes {
-			if m.shouldInvalidate(change, deps) {
-				evict = append(evict, shapeID)
-				break
+	mutations:
+		for _, mutation := range mutations {
+			if invalidate.OtherTenant(deps, mutation) {
+				continue
 			}
+			for _, change := range mutation.Changes {
+				if m.shouldInvalidate(change, shapeID, deps) {
+					evict = append(evict, shapeID)
+					events = append(events, EvictionEvent{ShapeID: shapeID, Reason: "mutation", Change: &change, TxID: txID})
+					break mutations
+				}
+			}
+		}
+	}
+
+	m.publish(events)
+	return InvalidateResponse{Evict: evict}
+}
+
+// BeginTx opens a transaction for staging changes
+func (m *MockEngine) BeginTx(txID string) error {
+	if err := m.fault("BeginTx"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.TrackCalls {
+		m.calls.BeginTx = append(m.calls.BeginTx, txID)
+	}
+
+	if txID == "" {
+		return fmt.Errorf("tx_id must be non-empty")
+	}
+	if _, open := m.txs[txID]; open {
+		return fmt.Errorf("transaction %q is already open", txID)
+	}
+
+	m.txs[txID] = []types.Change{}
+	return nil
+}
+
+// StageChange records a change in an open transaction without evicting
+func (m *MockEngine) StageChange(txID string, change types.Change) error {
+	if err := m.fault("StageChange"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.TrackCalls {
+		m.calls.StageChange = append(m.calls.StageChange, StageChangeRequest{TxID: txID, Change: change})
+	}
+
+	staged, open := m.txs[txID]
+	if !open {
+		return fmt.Errorf("transaction %q is not open", txID)
+	}
+	if m.config.Strict {
+		mutation := types.Mutation{TxID: &txID, Changes: []types.Change{change}}
+		if err := tests.ValidateMutationEvent(&mutation, tests.Strict()); err != nil {
+			return err
 		}
 	}
 
+	m.txs[txID] = append(staged, change)
+	return nil
+}
+
+// CommitTx closes a transaction and evicts shapes affected by its changes
+func (m *MockEngine) CommitTx(txID string) (InvalidateResponse, error) {
+	if err := m.fault("CommitTx"); err != nil {
+		return InvalidateResponse{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.TrackCalls {
+		m.calls.CommitTx = append(m.calls.CommitTx, txID)
+	}
+
+	staged, open := m.txs[txID]
+	if !open {
+		return InvalidateResponse{}, fmt.Errorf("transaction %q is not open", txID)
+	}
+	delete(m.txs, txID)
+
+	if err := m.applyChanges(staged); err != nil {
+		return InvalidateResponse{}, err
+	}
+	return m.invalidateInternal([]types.Mutation{{Changes: staged}}, txID), nil
+}
+
+// RollbackTx closes a transaction and discards its changes
+func (m *MockEngine) RollbackTx(txID string) error {
+	if err := m.fault("RollbackTx"); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.TrackCalls {
+		m.calls.RollbackTx = append(m.calls.RollbackTx, txID)
+	}
+
+	if _, open := m.txs[txID]; !open {
+		return fmt.Errorf("transaction %q is not open", txID)
+	}
+
+	delete(m.txs, txID)
+	return nil
+}
+
+// EvictExpired removes and returns shapes whose TTL or ValidUntil has passed.
+// It complements Invalidate for data sources that never emit mutations.
+func (m *MockEngine) EvictExpired() (InvalidateResponse, error) {
+	if err := m.fault("EvictExpired"); err != nil {
+		return InvalidateResponse{}, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.config.TrackCalls {
+		m.calls.EvictExpired = append(m.calls.EvictExpired, struct{}{})
+	}
+
+	now := m.now()
+	evict := []string{}
+
+	var events []EvictionEvent
+
+	for shapeID, expiresAt := range m.expiry {
+		if !now.Before(expiresAt) {
+			evict = append(evict, shapeID)
+			events = append(events, EvictionEvent{ShapeID: shapeID, Reason: "expired"})
+			delete(m.expiry, shapeID)
+			delete(m.shapes, shapeID)
+			delete(m.roots, shapeID)
+		}
+	}
+	sort.Strings(evict)
+	m.publish(events)
+
 	return InvalidateResponse{Evict: evict}, nil
 }
 
+// EvictPolicy removes and returns the shapes read under policy, for when it
+// changes (see invalidate.EvictPolicy). It is not part of Engine: policies
+// change outside the mutation stream, so engines expose this their own way.
+func (m *MockEngine) EvictPolicy(policy types.Filter) InvalidateResponse {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	evict := []string{}
+	var events []EvictionEvent
+
+	for shapeID, deps := range m.shapes {
+		if deps.Policy.Equal(&policy) {
+			evict = append(evict, shapeID)
+			events = append(events, EvictionEvent{ShapeID: shapeID, Reason: "policy"})
+			delete(m.expiry, shapeID)
+			delete(m.shapes, shapeID)
+			delete(m.roots, shapeID)
+		}
+	}
+	sort.Strings(evict)
+	m.publish(events)
+
+	return InvalidateResponse{Evict: evict}
+}
+
 // ExplainInvalidation explains why a shape would be invalidated
 func (m *MockEngine) ExplainInvalidation(req ExplainRequest) (ExplainResponse, error) {
+	if err := m.fault("ExplainInvalidation"); err != nil {
+		return ExplainResponse{}, err
+	}
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -162,37 +482,109 @@ func (m *MockEngine) ExplainInvalidation(req ExplainRequest) (ExplainResponse, e
 	}
 
 	deps, ok := m.shapes[req.ShapeID]
-	if !ok {
-		return ExplainResponse{Invalidate: false, Reasons: []string{}}, nil
+	if !ok || invalidate.OtherTenant(deps, req.Mutation) {
+		return ExplainResponse{Invalidate: false, Reasons: []string{}, Details: []ExplainReason{}}, nil
 	}
 
 	reasons := []string{}
+	details := []ExplainReason{}
 
-	for _, change := range req.Mutation.Changes {
+	for i, change := range req.Mutation.Changes {
 		// Check record membership
-		if ids, exists := deps.Records[change.Model]; exists && len(ids) > 0 {
+		if ids, exists := deps.Records[change.Model]; exists {
 			reasons = append(reasons, "record_membership")
+			details = append(details, ExplainReason{
+				ChangeIndex:      i,
+				Kind:             "record_membership",
+				Model:            change.Model,
+				MatchedRecordIDs: m.matchedRecords(change, ids),
+			})
 		}
 
 		// Check filter dependencies
 		if len(deps.Filters) > 0 {
-			for _, filter := range deps.Filters {
+			for j, filter := range deps.Filters {
 				if m.filterReferencesModel(filter, change.Model) {
 					reasons = append(reasons, "filter_dependency")
+					details = append(details, ExplainReason{
+						ChangeIndex: i,
+						Kind:        "filter_dependency",
+						Model:       change.Model,
+						FilterPath:  fmt.Sprintf("filters[%d]", j),
+					})
 					break
 				}
 			}
 		}
 
 		// Check relation dependencies
-		if len(deps.Includes) > 0 {
-			for _, include := range deps.Includes {
-				if include.Query != nil && include.Query.Model == change.Model {
-					reasons = append(reasons, "relation_dependency")
-					break
-				}
+		for _, rel := range m.includedModels(req.ShapeID, deps) {
+			if rel.model == change.Model {
+				reasons = append(reasons, "relation_dependency")
+				details = append(details, ExplainReason{
+					ChangeIndex: i,
+					Kind:        "relation_dependency",
+					Model:       change.Model,
+					FilterPath:  rel.path,
+				})
+				break
 			}
 		}
+
+		// Check the pagination boundary
+		if m.crossesBoundary(change, req.ShapeID, deps) {
+			reasons = append(reasons, "pagination_boundary")
+			details = append(details, ExplainReason{
+				ChangeIndex: i,
+				Kind:        "pagination_boundary",
+				Model:       change.Model,
+				FilterPath:  "last_row",
+			})
+		}
+
+		// Check the group-by dimensions
+		if m.changesGroups(change, req.ShapeID, deps) {
+			reasons = append(reasons, "group_by")
+			details = append(details, ExplainReason{
+				ChangeIndex: i,
+				Kind:        "group_by",
+				Model:       change.Model,
+				FilterPath:  "group_by",
+			})
+		}
+
+		// Check the window partitions and order
+		if m.changesWindows(change, req.ShapeID, deps) {
+			reasons = append(reasons, "window")
+			details = append(details, ExplainReason{
+				ChangeIndex: i,
+				Kind:        "window",
+				Model:       change.Model,
+				FilterPath:  "window_fields",
+			})
+		}
+
+		// Check soft deletes and restores
+		if m.softDeletes(change, req.ShapeID, deps) {
+			reasons = append(reasons, "soft_delete")
+			details = append(details, ExplainReason{
+				ChangeIndex: i,
+				Kind:        "soft_delete",
+				Model:       change.Model,
+				FilterPath:  "soft_delete_field",
+			})
+		}
+
+		// Check rows moving into or out of the policy
+		if m.crossesPolicy(change, req.ShapeID, deps) {
+			reasons = append(reasons, "policy")
+			details = append(details, ExplainReason{
+				ChangeIndex: i,
+				Kind:        "policy",
+				Model:       change.Model,
+				FilterPath:  "policy",
+			})
+		}
 	}
 
 	// Deduplicate reasons
@@ -201,11 +593,170 @@ func (m *MockEngine) ExplainInvalidation(req ExplainRequest) (ExplainResponse, e
 	return ExplainResponse{
 		Invalidate: len(uniqueReasons) > 0,
 		Reasons:    uniqueReasons,
+		Details:    details,
 	}, nil
 }
 
+// matchedRecords returns the tracked IDs change touches, or nil when that
+// cannot be told (see ExplainReason).
+func (m *MockEngine) matchedRecords(change types.Change, tracked []string) []string {
+	key := m.keyFields(change.Model)
+	var matched []string
+	if change.Action == "insert" {
+		row := make(Row, len(change.Sets))
+		for _, kv := range change.Sets {
+			row[kv.Field] = kv.Value
+		}
+		if id, ok := rowID(row, key); ok {
+			for _, t := range tracked {
+				if t == id {
+					matched = append(matched, id)
+					break
+				}
+			}
+		}
+		return matched
+	}
+
+	if rows, seeded := m.data[change.Model]; seeded {
+		trackedSet := make(map[string]bool, len(tracked))
+		for _, id := range tracked {
+			trackedSet[id] = true
+		}
+		for _, row := range rows {
+			id, ok := rowID(row, key)
+			if !ok || !trackedSet[id] {
+				continue
+			}
+			if ok, err := matchFilter(row, change.Where); err != nil {
+				return nil
+			} else if ok {
+				matched = append(matched, id)
+			}
+		}
+		return m.deduplicateStrings(matched)
+	}
+
+	for _, id := range tracked {
+		parts, err := types.RecordIDParts(id, len(key))
+		if err != nil {
+			return nil
+		}
+		ok, known := matchKey(change.Where, key, parts)
+		if !known {
+			return nil
+		}
+		if ok {
+			matched = append(matched, id)
+		}
+	}
+	return matched
+}
+
+// keyFields returns the primary key fields of model: those the schema
+// declares, else the configured IDField, else "id".
+func (m *MockEngine) keyFields(model string) []string {
+	if m.schema != nil {
+		if def := m.schema.model(model); def != nil && len(def.ID.Fields) > 0 {
+			return def.ID.Fields
+		}
+	}
+	if m.config.IDField != "" {
+		return []string{m.config.IDField}
+	}
+	return []string{"id"}
+}
+
+// rowID returns the record ID of row under key, and false when row lacks a
+// key field or holds a key value with no record ID form.
+func rowID(row map[string]interface{}, key []string) (string, bool) {
+	values := make([]interface{}, len(key))
+	for i, field := range key {
+		v, ok := row[field]
+		if !ok {
+			return "", false
+		}
+		values[i] = v
+	}
+	id, err := types.RecordID(values...)
+	return id, err == nil
+}
+
+// matchKey evaluates a where that tests only key fields (eq, ne, in, notIn)
+// against the parts of a tracked record ID, comparing values as the strings
+// IDs are tracked as. known is false when the where tests anything else.
+func matchKey(f *types.Filter, key, parts []string) (ok, known bool) {
+	if f == nil {
+		return true, true
+	}
+	ok = true
+	for _, c := range f.GetConditions() {
+		part := -1
+		for i, field := range key {
+			if c.Field == field {
+				part = i
+			}
+		}
+		if part < 0 || len(c.FieldPath) > 0 {
+			return false, false
+		}
+		value, err := types.Value(c.Value)
+		if err != nil {
+			return false, false
+		}
+		list, isList := value.([]interface{})
+		if !isList {
+			list = []interface{}{value}
+		}
+		found := false
+		for _, v := range list {
+			id, err := types.RecordID(v)
+			if err != nil {
+				return false, false
+			}
+			found = found || id == parts[part]
+		}
+		switch c.Op {
+		case "eq", "in":
+			ok = ok && found
+		case "ne", "notIn":
+			ok = ok && !found
+		default:
+			return false, false
+		}
+	}
+	for i := range f.GetAnd() {
+		match, known := matchKey(&(*f.And)[i], key, parts)
+		if !known {
+			return false, false
+		}
+		ok = ok && match
+	}
+	if or := f.GetOr(); len(or) > 0 {
+		anyMatch := false
+		for i := range or {
+			match, known := matchKey(&or[i], key, parts)
+			if !known {
+				return false, false
+			}
+			anyMatch = anyMatch || match
+		}
+		ok = ok && anyMatch
+	}
+	if f.Not != nil {
+		match, known := matchKey(f.Not, key, parts)
+		if !known {
+			return false, false
+		}
+		ok = ok && !match
+	}
+	return ok, true
+}
+
 // Reset clears all engine state
 func (m *MockEngine) Reset() {
+	m.delay("Reset")
+
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -215,14 +766,22 @@ func (m *MockEngine) Reset() {
 
 	m.schema = nil
 	m.shapes = make(map[string]types.Dependencies)
+	m.roots = make(map[string]string)
+	m.expiry = make(map[string]time.Time)
+	m.txs = make(map[string][]types.Change)
+	m.seq = -1
+	m.data = make(map[string][]Row)
 
 	if m.config.TrackCalls {
 		m.calls = MockEngineCalls{}
 	}
+	m.resetFaults()
 }
 
 // GetVersion returns version information
 func (m *MockEngine) GetVersion() VersionInfo {
+	m.delay("GetVersion")
+
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
@@ -231,8 +790,8 @@ func (m *MockEngine) GetVersion() VersionInfo {
 	}
 
 	return VersionInfo{
-		Core:     "mock-0.1.0",
-		Contract: "0.1.0",
+		Core:     "mock-" + types.SpecVersion,
+		Contract: types.ContractVersion,
 		ABI:      "1",
 	}
 }
@@ -262,29 +821,140 @@ func (m *MockEngine) GetDependencies(shapeID string) (types.Dependencies, bool)
 
 // Helper methods
 
-func (m *MockEngine) extractRecords(req AddQueryRequest) map[string][]string {
-	if req.ResultHint == nil || req.Shape.Query == nil {
-		return map[string][]string{}
+func (m *MockEngine) now() time.Time {
+	if m.config.Now != nil {
+		return m.config.Now()
 	}
+	return time.Now()
+}
 
+// expiresAt resolves the request TTL and ValidUntil to the earliest expiry.
+func (m *MockEngine) expiresAt(req AddQueryRequest) (time.Time, bool, error) {
+	var expiresAt time.Time
+	ok := false
+
+	if req.TTL != nil {
+		if *req.TTL < 0 {
+			return time.Time{}, false, fmt.Errorf("ttl must be non-negative, got %d", *req.TTL)
+		}
+		expiresAt = m.now().Add(time.Duration(*req.TTL) * time.Second)
+		ok = true
+	}
+
+	if req.ValidUntil != nil {
+		validUntil, err := time.Parse(time.RFC3339, *req.ValidUntil)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("valid_until must be an RFC 3339 date-time: %w", err)
+		}
+		if !ok || validUntil.Before(expiresAt) {
+			expiresAt = validUntil
+		}
+		ok = true
+	}
+
+	return expiresAt, ok, nil
+}
+
+func (m *MockEngine) extractRecords(req AddQueryRequest) map[string][]string {
 	records := make(map[string][]string)
-	model := req.Shape.Query.Model
+	q := req.Shape.Query
+	if q == nil {
+		return records
+	}
+
+	if ids := m.hintedIDs(req.ResultHint, q.Model); len(ids) > 0 {
+		records[q.Model] = ids
+	}
+
+	// Included models are tracked with the rows ORMs nest under the relation
+	// in each hinted parent row, however deep.
+	m.nestedIDs(records, q.Model, req.ResultHint[q.Model], req.Shape.Includes)
 
-	if rows, exists := req.ResultHint[model]; exists {
-		ids := []string{}
+	// Joined models are tracked even without hinted rows: a write to any of
+	// their records can add, drop or change a joined row.
+	for _, join := range q.GetJoins() {
+		model := m.joinedModel(q.Model, join.Relation)
+		if _, tracked := records[model]; !tracked {
+			records[model] = m.hintedIDs(req.ResultHint, model)
+		}
+	}
+
+	// So are the models of condition subqueries, nested ones included: a
+	// write to them can change which rows a subquery selects.
+	for _, f := range m.extractFilters(req.Shape) {
+		_ = walk.WalkFilter(&f, "", walk.Visitor{Query: func(_ string, sub *types.Query) error {
+			if _, tracked := records[sub.Model]; !tracked {
+				records[sub.Model] = m.hintedIDs(req.ResultHint, sub.Model)
+			}
+			return nil
+		}})
+	}
+
+	return records
+}
+
+// hintedIDs returns the record IDs of the rows hinted for model.
+func (m *MockEngine) hintedIDs(hint map[string][]interface{}, model string) []string {
+	return m.rowIDs(hint[model], model)
+}
+
+// rowIDs returns the record IDs of the rows of model, skipping rows that are
+// not objects or lack a key field.
+func (m *MockEngine) rowIDs(rows []interface{}, model string) []string {
+	key := m.keyFields(model)
+	ids := []string{}
+	for _, row := range rows {
+		if rowMap, ok := row.(map[string]interface{}); ok {
+			if id, ok := rowID(rowMap, key); ok {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+// nestedIDs adds to records the IDs of the included rows nested in rows of
+// parent, under the relation name as a list or, for to-one relations, a
+// single object, and recurses into their own includes.
+func (m *MockEngine) nestedIDs(records map[string][]string, parent string, rows []interface{}, includes []types.Include) {
+	for _, include := range includes {
+		if include.Query == nil {
+			continue
+		}
+		relation := include.Query.Model
+		var nested []interface{}
 		for _, row := range rows {
-			if rowMap, ok := row.(map[string]interface{}); ok {
-				if id, ok := rowMap["id"]; ok {
-					ids = append(ids, fmt.Sprintf("%v", id))
-				}
+			rowMap, ok := row.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			switch v := rowMap[relation].(type) {
+			case []interface{}:
+				nested = append(nested, v...)
+			case map[string]interface{}:
+				nested = append(nested, v)
 			}
 		}
-		if len(ids) > 0 {
-			records[model] = ids
+		if len(nested) == 0 {
+			continue
 		}
+
+		model := m.joinedModel(parent, relation)
+		records[model] = m.deduplicateStrings(append(records[model], m.rowIDs(nested, model)...))
+		m.nestedIDs(records, model, nested, include.Includes)
 	}
+}
 
-	return records
+// joinedModel resolves a joined relation to its target model through the
+// schema, falling back to the relation name when there is no schema or it
+// does not know the relation.
+func (m *MockEngine) joinedModel(model, relation string) string {
+	if m.schema != nil {
+		if target, _, ok := m.schema.RelationTarget(model, relation); ok {
+			return target
+		}
+	}
+	return relation
 }
 
 func (m *MockEngine) extractFilters(stmt types.Statement) []types.Filter {
@@ -298,6 +968,12 @@ func (m *MockEngine) extractFilters(stmt types.Statement) []types.Filter {
 		filters = append(filters, *stmt.Having)
 	}
 
+	for _, join := range stmt.Query.GetJoins() {
+		if join.On != nil {
+			filters = append(filters, *join.On)
+		}
+	}
+
 	return filters
 }
 
@@ -310,21 +986,191 @@ func (m *MockEngine) filterReferencesModel(filter types.Filter, _ string) bool {
 	return filter.Conditions != nil && len(*filter.Conditions) > 0
 }
 
-func (m *MockEngine) shouldInvalidate(change types.Change, deps types.Dependencies) bool {
+func (m *MockEngine) shouldInvalidate(change types.Change, shapeID string, deps types.Dependencies) bool {
 	behavior := m.config.EvictBehavior
 	if behavior == "" {
 		behavior = "conservative"
 	}
 
 	if behavior == "conservative" {
-		// Conservative: evict if model is tracked
-		_, exists := deps.Records[change.Model]
-		return exists
+		// Conservative: evict if model is tracked or loaded by an include
+		if _, exists := deps.Records[change.Model]; exists {
+			return true
+		}
+		for _, rel := range m.includedModels(shapeID, deps) {
+			if rel.model == change.Model {
+				return true
+			}
+		}
+		if m.crossesBoundary(change, shapeID, deps) || m.changesGroups(change, shapeID, deps) ||
+			m.changesWindows(change, shapeID, deps) || m.softDeletes(change, shapeID, deps) ||
+			m.crossesPolicy(change, shapeID, deps) {
+			return true
+		}
 	}
 
 	return false
 }
 
+// crossesBoundary reports whether change may shift the page of a paginated
+// shape (see invalidate.CrossesBoundary).
+func (m *MockEngine) crossesBoundary(change types.Change, shapeID string, deps types.Dependencies) bool {
+	return deps.LastRow != nil && change.Model == m.roots[shapeID] && invalidate.CrossesBoundary(deps.LastRow, change)
+}
+
+// changesGroups reports whether change may add, empty or move rows between
+// the groups of a grouped shape (see invalidate.ChangesGroups).
+func (m *MockEngine) changesGroups(change types.Change, shapeID string, deps types.Dependencies) bool {
+	return deps.GroupBy != nil && change.Model == m.roots[shapeID] && invalidate.ChangesGroups(deps.GroupBy, change)
+}
+
+// changesWindows reports whether change may reorder or repartition the rows
+// of a shape with window columns (see invalidate.ChangesWindows).
+func (m *MockEngine) changesWindows(change types.Change, shapeID string, deps types.Dependencies) bool {
+	return change.Model == m.roots[shapeID] && invalidate.ChangesWindows(deps.WindowFields, change)
+}
+
+// softDeletes reports whether change soft-deletes or restores rows of the
+// root model of a shape that tells them from live ones (see
+// invalidate.SoftDeletes).
+func (m *MockEngine) softDeletes(change types.Change, shapeID string, deps types.Dependencies) bool {
+	field, ok := deps.GetSoftDeleteField()
+	return ok && change.Model == m.roots[shapeID] && invalidate.SoftDeletes(field, change)
+}
+
+// crossesPolicy reports whether change may move rows of the root model into
+// or out of the policy of a shape (see invalidate.CrossesPolicy).
+func (m *MockEngine) crossesPolicy(change types.Change, shapeID string, deps types.Dependencies) bool {
+	return deps.Policy != nil && change.Model == m.roots[shapeID] && invalidate.CrossesPolicy(deps.Policy, change)
+}
+
+// softDeleteField returns the schema's soft-delete field for the model of q,
+// unless q reads every row, or nil.
+func (m *MockEngine) softDeleteField(q *types.Query) *string {
+	if m.schema == nil || q == nil {
+		return nil
+	}
+	if deleted, _ := q.GetDeleted(); deleted == "include" {
+		return nil
+	}
+	if field := m.schema.SoftDeleteField(q.Model); field != "" {
+		return &field
+	}
+	return nil
+}
+
+// windowFields returns the sorted fields the windows of q partition by,
+// order by or aggregate, or nil without windows.
+func windowFields(q *types.Query) []string {
+	seen := map[string]bool{}
+	for _, w := range q.GetWindows() {
+		for _, f := range w.GetPartitionBy() {
+			seen[f] = true
+		}
+		for _, ob := range w.GetOrderBy() {
+			seen[ob.Field] = true
+		}
+		if f, ok := w.GetField(); ok {
+			seen[f] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil
+	}
+	fields := make([]string, 0, len(seen))
+	for f := range seen {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// lastRow returns the boundary of a full forward page: the last hinted row
+// of the root model, once the hint holds as many rows as First (or the query
+// Limit) asks for.
+func lastRow(req AddQueryRequest) *types.PaginationBoundary {
+	q := req.Shape.Query
+	if q == nil || len(req.Shape.GetOrderBy()) == 0 {
+		return nil
+	}
+	size, ok := req.Shape.GetPagination().GetFirst()
+	if !ok && q.Limit != nil {
+		size, ok = *q.Limit, true
+	}
+	rows := req.ResultHint[q.Model]
+	if !ok || size <= 0 || len(rows) < size {
+		return nil
+	}
+	row, _ := rows[size-1].(map[string]interface{})
+	boundary, err := pagination.Boundary(req.Shape.GetOrderBy(), row)
+	if err != nil {
+		return nil
+	}
+	return boundary
+}
+
+// groupBy returns the groups of a grouped shape: the distinct group key
+// values of the hinted root rows, in hint order, with a missing key as null.
+// Without a hint for the root model the groups are unknown, so it returns
+// nil.
+func groupBy(req AddQueryRequest) *types.GroupByKV {
+	keys := req.Shape.GetGroupBy()
+	rows, hinted := req.ResultHint[req.Shape.GetQuery().GetModel()]
+	if len(keys) == 0 || !hinted {
+		return nil
+	}
+	gb := &types.GroupByKV{Keys: keys, Values: []map[string]any{}}
+	seen := map[string]bool{}
+	for _, r := range rows {
+		row, _ := r.(map[string]interface{})
+		values := make(map[string]any, len(keys))
+		for _, k := range keys {
+			v, err := jsonValue(row[k])
+			if err != nil {
+				return nil
+			}
+			values[k] = v
+		}
+		id, err := json.Marshal(values)
+		if err != nil {
+			return nil
+		}
+		if !seen[string(id)] {
+			seen[string(id)] = true
+			gb.Values = append(gb.Values, values)
+		}
+	}
+	return gb
+}
+
+// includedModel is a model a shape loads through an include, at path in its
+// Dependencies (e.g. "includes[0].includes[1]").
+type includedModel struct {
+	path  string
+	model string
+}
+
+// includedModels walks the relation graph from the shape's root model along
+// its includes, nested ones included, resolving each relation to its target
+// model as joinedModel does.
+func (m *MockEngine) includedModels(shapeID string, deps types.Dependencies) []includedModel {
+	var out []includedModel
+	var visit func(parent string, includes []types.Include, path string)
+	visit = func(parent string, includes []types.Include, path string) {
+		for i, include := range includes {
+			if include.Query == nil {
+				continue
+			}
+			includePath := fmt.Sprintf("%sincludes[%d]", path, i)
+			model := m.joinedModel(parent, include.Query.Model)
+			out = append(out, includedModel{path: includePath, model: model})
+			visit(model, include.Includes, includePath+".")
+		}
+	}
+	visit(m.roots[shapeID], deps.Includes, "")
+	return out
+}
+
 func (m *MockEngine) deduplicateStrings(input []string) []string {
 	seen := make(map[string]bool)
 	result := []string{}
@@ -1,8 +1,11 @@
 package mock_test
 
 import (
+	"reflect"
 	"testing"
+	"time"
 
+	"github.com/bold-minds/includekit-spec/go/tests"
 	"github.com/bold-minds/includekit-spec/go/tests/mock"
 	"github.com/bold-minds/includekit-spec/go/types"
 )
@@ -84,6 +87,27 @@ func TestComputeShapeIDCustomGenerator(t *testing.T) {
 	}
 }
 
+func TestComputeShapeIDCache(t *testing.T) {
+	cache := tests.NewShapeIDCache(0)
+	engine := mock.NewMockEngine(mock.MockEngineConfig{ShapeIDCache: cache})
+	stmt := types.Statement{Query: &types.Query{Model: "users"}}
+
+	for i := 0; i < 3; i++ {
+		result, err := engine.ComputeShapeID(stmt)
+		if err != nil {
+			t.Fatalf("ComputeShapeID failed: %v", err)
+		}
+		want, _ := tests.ComputeQueryShapeID(&stmt)
+		if result.ShapeID != want {
+			t.Errorf("ShapeID = %s, want %s", result.ShapeID, want)
+		}
+	}
+
+	if stats := cache.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Stats() = %+v, want 2 hits and 1 miss", stats)
+	}
+}
+
 func TestAddQuery(t *testing.T) {
 	engine := mock.NewMockEngine(mock.MockEngineConfig{})
 
@@ -241,6 +265,101 @@ func TestInvalidateDoesNotEvictUnrelatedShapes(t *testing.T) {
 	}
 }
 
+func TestAddQueryTracksJoinedModels(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	if err := engine.SetSchema(blogSchema); err != nil {
+		t.Fatalf("SetSchema failed: %v", err)
+	}
+
+	onFilter := types.Filter{Conditions: &[]types.Condition{types.Eq("published", true)}}
+	stmt := types.Statement{
+		Query: &types.Query{
+			Model: "User",
+			Joins: &[]types.Join{{Relation: "posts", Kind: "inner", On: &onFilter}},
+		},
+	}
+
+	result, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: stmt,
+		ResultHint: map[string][]interface{}{
+			"User": {map[string]interface{}{"id": "u1"}},
+			"Post": {map[string]interface{}{"id": "p1"}, map[string]interface{}{"id": "p2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	want := map[string][]string{"User": {"u1"}, "Post": {"p1", "p2"}}
+	if !reflect.DeepEqual(result.Dependencies.Records, want) {
+		t.Errorf("Records = %v, want %v", result.Dependencies.Records, want)
+	}
+	if len(result.Dependencies.Filters) != 1 || !result.Dependencies.Filters[0].Equal(&onFilter) {
+		t.Errorf("Filters = %+v, want the join filter", result.Dependencies.Filters)
+	}
+}
+
+func TestInvalidateEvictsOnJoinedModelWrites(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+
+	// Without a schema or hinted rows, the relation name stands in for the
+	// joined model and all of its records are tracked.
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "posts", Joins: &[]types.Join{types.LeftJoin("tags")}}},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	if ids, ok := addResult.Dependencies.Records["tags"]; !ok || len(ids) != 0 {
+		t.Fatalf("Records = %v, want tags tracked with no IDs", addResult.Dependencies.Records)
+	}
+
+	mutation := types.Mutation{Changes: []types.Change{{
+		Model:  "tags",
+		Action: "insert",
+		Sets:   []types.KV{{Field: "id", Value: "t1"}},
+	}}}
+
+	explain, err := engine.ExplainInvalidation(mock.ExplainRequest{ShapeID: addResult.ShapeID, Mutation: mutation})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+	if !explain.Invalidate || explain.Reasons[0] != "record_membership" {
+		t.Errorf("ExplainInvalidation = %+v, want record_membership", explain)
+	}
+
+	invalidateResult, err := engine.Invalidate(mutation)
+	if err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if len(invalidateResult.Evict) != 1 || invalidateResult.Evict[0] != addResult.ShapeID {
+		t.Errorf("Evict = %v, want [%s]", invalidateResult.Evict, addResult.ShapeID)
+	}
+}
+
+func TestAddQueryTracksSubqueryModels(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+
+	orgs := types.Query{Model: "Org", Fields: &[]string{"id"}}
+	users := types.Query{Model: "User", Fields: &[]string{"id"}, Where: &types.Filter{
+		Conditions: &[]types.Condition{types.InQuery("orgId", orgs)},
+	}}
+	result, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "Post", Where: &types.Filter{
+			Conditions: &[]types.Condition{types.ExistsQuery("authorId", users)},
+		}}},
+		ResultHint: map[string][]interface{}{"Post": {map[string]interface{}{"id": "p1"}}},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	want := map[string][]string{"Post": {"p1"}, "User": {}, "Org": {}}
+	if !reflect.DeepEqual(result.Dependencies.Records, want) {
+		t.Errorf("Records = %v, want %v", result.Dependencies.Records, want)
+	}
+}
+
 func TestInvalidateCustomEvictList(t *testing.T) {
 	engine := mock.NewMockEngine(mock.MockEngineConfig{})
 	engine.SetEvictList([]string{"s_custom_1", "s_custom_2"})
@@ -337,6 +456,127 @@ func TestExplainInvalidationUnknownShape(t *testing.T) {
 	if len(result.Reasons) != 0 {
 		t.Errorf("Expected empty reasons, got %v", result.Reasons)
 	}
+
+	if result.Details == nil || len(result.Details) != 0 {
+		t.Errorf("Expected empty details, got %v", result.Details)
+	}
+}
+
+func TestExplainInvalidationDetails(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+
+	where := types.Filter{Conditions: &[]types.Condition{types.Eq("published", true)}}
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{
+			Query:    &types.Query{Model: "posts", Where: &where},
+			Includes: []types.Include{{Query: &types.Query{Model: "comments"}}},
+		},
+		ResultHint: map[string][]interface{}{
+			"posts": {map[string]interface{}{"id": "1"}, map[string]interface{}{"id": "2"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	byID := func(ids ...string) *types.Filter {
+		return &types.Filter{Conditions: &[]types.Condition{{Field: "id", Op: "in", Value: ids}}}
+	}
+	result, err := engine.ExplainInvalidation(mock.ExplainRequest{
+		ShapeID: addResult.ShapeID,
+		Mutation: types.Mutation{Changes: []types.Change{
+			{Model: "posts", Action: "update", Sets: []types.KV{{Field: "title", Value: "x"}}, Where: byID("2", "3")},
+			{Model: "comments", Action: "insert", Sets: []types.KV{{Field: "id", Value: "c1"}}},
+			{Model: "posts", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("title", "x")}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+
+	want := []mock.ExplainReason{
+		{ChangeIndex: 0, Kind: "record_membership", Model: "posts", MatchedRecordIDs: []string{"2"}},
+		{ChangeIndex: 0, Kind: "filter_dependency", Model: "posts", FilterPath: "filters[0]"},
+		{ChangeIndex: 1, Kind: "filter_dependency", Model: "comments", FilterPath: "filters[0]"},
+		{ChangeIndex: 1, Kind: "relation_dependency", Model: "comments", FilterPath: "includes[0]"},
+		{ChangeIndex: 2, Kind: "record_membership", Model: "posts"},
+		{ChangeIndex: 2, Kind: "filter_dependency", Model: "posts", FilterPath: "filters[0]"},
+	}
+	if !reflect.DeepEqual(result.Details, want) {
+		t.Errorf("Details = %+v\nwant %+v", result.Details, want)
+	}
+
+	// Seeded rows decide which records a change selects
+	if err := engine.SeedData("posts", []mock.Row{{"id": "1", "title": "x"}, {"id": "2", "title": "y"}}); err != nil {
+		t.Fatal(err)
+	}
+	result, err = engine.ExplainInvalidation(mock.ExplainRequest{
+		ShapeID: addResult.ShapeID,
+		Mutation: types.Mutation{Changes: []types.Change{
+			{Model: "posts", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("title", "x")}}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+	if got := result.Details[0].MatchedRecordIDs; !reflect.DeepEqual(got, []string{"1"}) {
+		t.Errorf("MatchedRecordIDs = %v, want [1]", got)
+	}
+}
+
+func TestExplainInvalidationCompositeKeys(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	engine.SetSchema(mock.AppSchema{Version: 1, Models: []mock.Model{
+		{Name: "OrderLine", ID: mock.IDConfig{Kind: "composite", Fields: []string{"orderId", "lineNo"}}},
+	}})
+
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "OrderLine"}},
+		ResultHint: map[string][]interface{}{"OrderLine": {
+			map[string]interface{}{"orderId": "o1", "lineNo": 1},
+			map[string]interface{}{"orderId": "o1", "lineNo": 2},
+			map[string]interface{}{"orderId": "o2", "lineNo": 1},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	wantRecords := []string{`["o1",1]`, `["o1",2]`, `["o2",1]`}
+	if got := addResult.Dependencies.Records["OrderLine"]; !reflect.DeepEqual(got, wantRecords) {
+		t.Fatalf("Records = %v, want %v", got, wantRecords)
+	}
+
+	explain := func(change types.Change) []string {
+		t.Helper()
+		result, err := engine.ExplainInvalidation(mock.ExplainRequest{
+			ShapeID:  addResult.ShapeID,
+			Mutation: types.Mutation{Changes: []types.Change{change}},
+		})
+		if err != nil {
+			t.Fatalf("ExplainInvalidation failed: %v", err)
+		}
+		return result.Details[0].MatchedRecordIDs
+	}
+
+	byOrder := &types.Filter{Conditions: &[]types.Condition{types.Eq("orderId", "o1"), types.In("lineNo", 2, 3)}}
+	if got := explain(types.Change{Model: "OrderLine", Action: "delete", Where: byOrder}); !reflect.DeepEqual(got, []string{`["o1",2]`}) {
+		t.Errorf("delete matched %v, want [[\"o1\",2]]", got)
+	}
+	insert := types.Change{Model: "OrderLine", Action: "insert", Sets: []types.KV{{Field: "lineNo", Value: 1}, {Field: "orderId", Value: "o2"}}}
+	if got := explain(insert); !reflect.DeepEqual(got, []string{`["o2",1]`}) {
+		t.Errorf("insert matched %v, want [[\"o2\",1]]", got)
+	}
+
+	if err := engine.SeedData("OrderLine", []mock.Row{
+		{"orderId": "o1", "lineNo": 1, "sku": "a"},
+		{"orderId": "o2", "lineNo": 1, "sku": "a"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	bySku := &types.Filter{Conditions: &[]types.Condition{types.Eq("sku", "a")}}
+	if got := explain(types.Change{Model: "OrderLine", Action: "delete", Where: bySku}); !reflect.DeepEqual(got, []string{`["o1",1]`, `["o2",1]`}) {
+		t.Errorf("seeded delete matched %v", got)
+	}
 }
 
 func TestReset(t *testing.T) {
@@ -364,12 +604,12 @@ func TestGetVersion(t *testing.T) {
 
 	version := engine.GetVersion()
 
-	if version.Core != "mock-0.1.0" {
-		t.Errorf("Expected core mock-0.1.0, got %s", version.Core)
+	if version.Core != "mock-"+types.SpecVersion {
+		t.Errorf("Expected core mock-%s, got %s", types.SpecVersion, version.Core)
 	}
 
-	if version.Contract != "0.1.0" {
-		t.Errorf("Expected contract 0.1.0, got %s", version.Contract)
+	if version.Contract != types.ContractVersion {
+		t.Errorf("Expected contract %s, got %s", types.ContractVersion, version.Contract)
 	}
 
 	if version.ABI != "1" {
@@ -405,3 +645,499 @@ func TestTrackCalls(t *testing.T) {
 		t.Errorf("Expected 1 GetVersion call, got %d", len(calls.GetVersion))
 	}
 }
+
+func TestEvictExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	engine := mock.NewMockEngine(mock.MockEngineConfig{
+		Now: func() time.Time { return now },
+	})
+
+	add := func(model string, ttl *int, validUntil *string) string {
+		t.Helper()
+		result, err := engine.AddQuery(mock.AddQueryRequest{
+			Shape:      types.Statement{Query: &types.Query{Model: model}},
+			TTL:        ttl,
+			ValidUntil: validUntil,
+		})
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		return result.ShapeID
+	}
+
+	ttl := 60
+	deadline := "2026-01-01T12:00:30Z"
+	short := add("rates", &ttl, &deadline) // ValidUntil is earlier than TTL
+	long := add("weather", &ttl, nil)
+	forever := add("users", nil, nil)
+
+	deps, _ := engine.GetDependencies(long)
+	if deps.ValidUntil == nil || *deps.ValidUntil != "2026-01-01T12:01:00Z" {
+		t.Errorf("Expected resolved valid_until, got %v", deps.ValidUntil)
+	}
+
+	result, err := engine.EvictExpired()
+	if err != nil {
+		t.Fatalf("EvictExpired failed: %v", err)
+	}
+	if len(result.Evict) != 0 {
+		t.Errorf("Expected nothing expired yet, got %v", result.Evict)
+	}
+
+	now = now.Add(45 * time.Second)
+	result, _ = engine.EvictExpired()
+	if len(result.Evict) != 1 || result.Evict[0] != short {
+		t.Errorf("Expected only %s evicted, got %v", short, result.Evict)
+	}
+	if _, ok := engine.GetDependencies(short); ok {
+		t.Error("Expired shape should be removed")
+	}
+
+	now = now.Add(time.Hour)
+	result, _ = engine.EvictExpired()
+	if len(result.Evict) != 1 || result.Evict[0] != long {
+		t.Errorf("Expected only %s evicted, got %v", long, result.Evict)
+	}
+	if _, ok := engine.GetDependencies(forever); !ok {
+		t.Error("Shape without TTL should never expire")
+	}
+}
+
+func TestAddQueryRejectsInvalidExpiry(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	shape := types.Statement{Query: &types.Query{Model: "users"}}
+
+	ttl := -1
+	if _, err := engine.AddQuery(mock.AddQueryRequest{Shape: shape, TTL: &ttl}); err == nil {
+		t.Error("Expected error for negative ttl")
+	}
+
+	validUntil := "soon"
+	if _, err := engine.AddQuery(mock.AddQueryRequest{Shape: shape, ValidUntil: &validUntil}); err == nil {
+		t.Error("Expected error for malformed valid_until")
+	}
+}
+
+func TestTransactionCommitEvictsStagedChanges(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "users"}},
+		ResultHint: map[string][]interface{}{
+			"users": {map[string]interface{}{"id": "1"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	change := types.Change{Model: "users", Action: "update", Sets: []types.KV{{Field: "name", Value: "Bob"}}}
+
+	if err := engine.BeginTx("tx_1"); err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := engine.StageChange("tx_1", change); err != nil {
+		t.Fatalf("StageChange failed: %v", err)
+	}
+
+	result, err := engine.CommitTx("tx_1")
+	if err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+	if len(result.Evict) != 1 || result.Evict[0] != addResult.ShapeID {
+		t.Errorf("Expected %s evicted on commit, got %v", addResult.ShapeID, result.Evict)
+	}
+
+	if _, err := engine.CommitTx("tx_1"); err == nil {
+		t.Error("Expected error committing a closed transaction")
+	}
+}
+
+func TestInvalidateTransaction(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{TrackCalls: true})
+
+	addQuery := func(model string) string {
+		t.Helper()
+		result, err := engine.AddQuery(mock.AddQueryRequest{
+			Shape:      types.Statement{Query: &types.Query{Model: model}},
+			ResultHint: map[string][]interface{}{model: {map[string]interface{}{"id": "1"}}},
+		})
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		return result.ShapeID
+	}
+	users, posts := addQuery("users"), addQuery("posts")
+	addQuery("tags")
+
+	insert := func(model string) types.Mutation {
+		return types.Mutation{Changes: []types.Change{
+			{Model: model, Action: "insert", Sets: []types.KV{{Field: "id", Value: "1"}}},
+		}}
+	}
+	tx := types.Transaction{Seq: 0, Mutations: []types.Mutation{insert("users"), insert("posts"), insert("users")}}
+
+	result, err := engine.InvalidateTransaction(tx)
+	if err != nil {
+		t.Fatalf("InvalidateTransaction failed: %v", err)
+	}
+	want := map[string]bool{users: true, posts: true}
+	if len(result.Evict) != len(want) {
+		t.Fatalf("Expected each affected shape evicted once, got %v", result.Evict)
+	}
+	for _, id := range result.Evict {
+		if !want[id] {
+			t.Errorf("Unexpected eviction of %s", id)
+		}
+	}
+
+	// Replays and reordered deliveries are rejected
+	for _, seq := range []int{0, -1} {
+		tx.Seq = seq
+		if _, err := engine.InvalidateTransaction(tx); err == nil {
+			t.Errorf("Expected error for seq %d after seq 0", seq)
+		}
+	}
+	tx.Seq = 5
+	if _, err := engine.InvalidateTransaction(tx); err != nil {
+		t.Errorf("InvalidateTransaction with a later seq failed: %v", err)
+	}
+
+	if calls := engine.GetCalls(); len(calls.InvalidateTransaction) != 4 {
+		t.Errorf("Expected 4 tracked InvalidateTransaction calls, got %d", len(calls.InvalidateTransaction))
+	}
+
+	// Reset forgets the last seq
+	engine.Reset()
+	tx.Seq = 0
+	if _, err := engine.InvalidateTransaction(tx); err != nil {
+		t.Errorf("InvalidateTransaction after Reset failed: %v", err)
+	}
+}
+
+func TestTransactionRollbackDiscardsStagedChanges(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{TrackCalls: true})
+
+	engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "users"}},
+		ResultHint: map[string][]interface{}{
+			"users": {map[string]interface{}{"id": "1"}},
+		},
+	})
+
+	change := types.Change{Model: "users", Action: "delete"}
+
+	if err := engine.BeginTx("tx_1"); err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	if err := engine.StageChange("tx_1", change); err != nil {
+		t.Fatalf("StageChange failed: %v", err)
+	}
+	if err := engine.RollbackTx("tx_1"); err != nil {
+		t.Fatalf("RollbackTx failed: %v", err)
+	}
+
+	// Reusing the ID opens a fresh, empty transaction
+	if err := engine.BeginTx("tx_1"); err != nil {
+		t.Fatalf("BeginTx failed: %v", err)
+	}
+	result, err := engine.CommitTx("tx_1")
+	if err != nil {
+		t.Fatalf("CommitTx failed: %v", err)
+	}
+	if len(result.Evict) != 0 {
+		t.Errorf("Rolled-back changes should not evict, got %v", result.Evict)
+	}
+
+	calls := engine.GetCalls()
+	if len(calls.StageChange) != 1 || calls.StageChange[0].TxID != "tx_1" {
+		t.Errorf("Expected 1 tracked StageChange call, got %+v", calls.StageChange)
+	}
+}
+
+func TestTransactionErrors(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+
+	if err := engine.BeginTx(""); err == nil {
+		t.Error("Expected error for empty tx_id")
+	}
+	if err := engine.StageChange("missing", types.Change{Model: "users", Action: "delete"}); err == nil {
+		t.Error("Expected error staging into unknown transaction")
+	}
+	if err := engine.RollbackTx("missing"); err == nil {
+		t.Error("Expected error rolling back unknown transaction")
+	}
+
+	engine.BeginTx("tx_1")
+	if err := engine.BeginTx("tx_1"); err == nil {
+		t.Error("Expected error reopening an open transaction")
+	}
+}
+
+func TestStrictEngineRejectsCustomOperators(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{Strict: true})
+	custom := &types.Filter{Conditions: &[]types.Condition{{Field: "name", Op: "custom:soundex", Value: "x"}}}
+
+	if _, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "users", Where: custom}},
+	}); err == nil {
+		t.Error("Expected strict AddQuery to reject custom operator")
+	}
+
+	if _, err := engine.Invalidate(types.Mutation{Changes: []types.Change{
+		{Model: "users", Action: "delete", Where: custom},
+	}}); err == nil {
+		t.Error("Expected strict Invalidate to reject custom operator")
+	}
+
+	if _, err := engine.InvalidateTransaction(types.Transaction{Seq: 1, Mutations: []types.Mutation{{Changes: []types.Change{
+		{Model: "users", Action: "delete", Where: custom},
+	}}}}); err == nil {
+		t.Error("Expected strict InvalidateTransaction to reject custom operator")
+	}
+
+	if _, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{Query: &types.Query{Model: "users"}},
+	}); err != nil {
+		t.Errorf("Strict AddQuery should accept pure statements: %v", err)
+	}
+}
+
+func TestAddQueryRecordsPaginationBoundary(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	desc := true
+	first := 2
+	shape := types.Statement{
+		Query:      &types.Query{Model: "Post", OrderBy: &[]types.OrderBy{{Field: "views", Descending: &desc}, {Field: "id"}}},
+		Pagination: &types.Pagination{First: &first},
+	}
+	hint := map[string][]interface{}{"Post": {
+		map[string]interface{}{"id": "p1", "views": 300},
+		map[string]interface{}{"id": "p2", "views": 250},
+	}}
+
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{Shape: shape, ResultHint: hint})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	lr := addResult.Dependencies.LastRow
+	if lr == nil || !reflect.DeepEqual(lr.Row, map[string]interface{}{"id": "p2", "views": 250}) {
+		t.Fatalf("LastRow = %+v, want the second row", lr)
+	}
+
+	explain := func(change types.Change) []string {
+		t.Helper()
+		result, err := engine.ExplainInvalidation(mock.ExplainRequest{
+			ShapeID:  addResult.ShapeID,
+			Mutation: types.Mutation{Changes: []types.Change{change}},
+		})
+		if err != nil {
+			t.Fatalf("ExplainInvalidation failed: %v", err)
+		}
+		return result.Reasons
+	}
+	before := types.Change{Model: "Post", Action: "insert", Sets: []types.KV{{Field: "id", Value: "p3"}, {Field: "views", Value: 400}}}
+	if got := explain(before); !reflect.DeepEqual(got, []string{"record_membership", "pagination_boundary"}) {
+		t.Errorf("insert before the boundary: reasons = %v", got)
+	}
+	after := types.Change{Model: "Post", Action: "insert", Sets: []types.KV{{Field: "id", Value: "p3"}, {Field: "views", Value: 10}}}
+	if got := explain(after); !reflect.DeepEqual(got, []string{"record_membership"}) {
+		t.Errorf("insert after the boundary: reasons = %v", got)
+	}
+
+	// A page shorter than First holds every later row, so it has no boundary
+	partial, err := engine.AddQuery(mock.AddQueryRequest{Shape: shape, ResultHint: map[string][]interface{}{"Post": hint["Post"][:1]}})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	if partial.Dependencies.LastRow != nil {
+		t.Errorf("LastRow = %+v for a partial page, want nil", partial.Dependencies.LastRow)
+	}
+}
+
+func TestAddQueryRecordsGroupBy(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	shape := types.Statement{
+		Query:   &types.Query{Model: "Post", Aggregations: &[]types.Aggregate{{Func: "count"}}},
+		GroupBy: &[]string{"authorId"},
+	}
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{Shape: shape, ResultHint: map[string][]interface{}{"Post": {
+		map[string]interface{}{"authorId": "u1", "count": 2},
+		map[string]interface{}{"authorId": "u2", "count": 1},
+	}}})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	want := &types.GroupByKV{Keys: []string{"authorId"}, Values: []map[string]any{{"authorId": "u1"}, {"authorId": "u2"}}}
+	if got := addResult.Dependencies.GroupBy; !reflect.DeepEqual(got, want) {
+		t.Fatalf("GroupBy = %+v, want %+v", got, want)
+	}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		evict  bool
+	}{
+		{"insert into a listed group", types.Change{Model: "Post", Action: "insert", Sets: []types.KV{{Field: "authorId", Value: "u1"}}}, false},
+		{"insert starting a group", types.Change{Model: "Post", Action: "insert", Sets: []types.KV{{Field: "authorId", Value: "u3"}}}, true},
+		{"update moving groups", types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}}, Sets: []types.KV{{Field: "authorId", Value: "u2"}}}, true},
+		{"update within a group", types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}}, Sets: []types.KV{{Field: "title", Value: "x"}}}, false},
+		{"delete from a listed group", types.Change{Model: "Post", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("authorId", "u2")}}}, true},
+		{"delete from an unlisted group", types.Change{Model: "Post", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("authorId", "u9")}}}, false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			explained, err := engine.ExplainInvalidation(mock.ExplainRequest{ShapeID: addResult.ShapeID, Mutation: types.Mutation{Changes: []types.Change{tt.change}}})
+			if err != nil {
+				t.Fatalf("ExplainInvalidation failed: %v", err)
+			}
+			if explained.Invalidate != tt.evict || (tt.evict && !reflect.DeepEqual(explained.Reasons, []string{"group_by"})) {
+				t.Errorf("ExplainInvalidation() = %v %v, want evict %v", explained.Invalidate, explained.Reasons, tt.evict)
+			}
+
+			resp, err := engine.Invalidate(types.Mutation{Changes: []types.Change{tt.change}})
+			if err != nil {
+				t.Fatalf("Invalidate failed: %v", err)
+			}
+			if evicted := len(resp.Evict) > 0; evicted != tt.evict {
+				t.Errorf("Invalidate() evicted %v, want %v", resp.Evict, tt.evict)
+			}
+		})
+	}
+}
+
+func TestAddQueryRecordsWindowFields(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	latest := types.RowNumber("latest").PartitionedBy("authorId").OrderedBy(types.OrderBy{Field: "createdAt"})
+	shape := types.Statement{Query: &types.Query{Model: "Post", Windows: &[]types.Window{latest}}}
+	addResult, err := engine.AddQuery(mock.AddQueryRequest{Shape: shape, ResultHint: map[string][]interface{}{"Post": {
+		map[string]interface{}{"authorId": "u1", "latest": 1},
+	}}})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+	if got, want := addResult.Dependencies.WindowFields, []string{"authorId", "createdAt"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("WindowFields = %v, want %v", got, want)
+	}
+
+	tcs := []struct {
+		name   string
+		change types.Change
+		evict  bool
+	}{
+		{"update moving partitions", types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p9")}}, Sets: []types.KV{{Field: "authorId", Value: "u1"}}}, true},
+		{"update reordering a partition", types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p9")}}, Sets: []types.KV{{Field: "createdAt", Value: "2024-01-01"}}}, true},
+		{"update of another field", types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p9")}}, Sets: []types.KV{{Field: "title", Value: "x"}}}, false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			explained, err := engine.ExplainInvalidation(mock.ExplainRequest{ShapeID: addResult.ShapeID, Mutation: types.Mutation{Changes: []types.Change{tt.change}}})
+			if err != nil {
+				t.Fatalf("ExplainInvalidation failed: %v", err)
+			}
+			if explained.Invalidate != tt.evict || (tt.evict && !reflect.DeepEqual(explained.Reasons, []string{"window"})) {
+				t.Errorf("ExplainInvalidation() = %v %v, want evict %v", explained.Invalidate, explained.Reasons, tt.evict)
+			}
+
+			resp, err := engine.Invalidate(types.Mutation{Changes: []types.Change{tt.change}})
+			if err != nil {
+				t.Fatalf("Invalidate failed: %v", err)
+			}
+			if evicted := len(resp.Evict) > 0; evicted != tt.evict {
+				t.Errorf("Invalidate() evicted %v, want %v", resp.Evict, tt.evict)
+			}
+		})
+	}
+}
+
+func TestTenantShapes(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	acme, globex := "acme", "globex"
+	shapes := map[string]string{}
+	for _, tenant := range []string{acme, globex} {
+		added, err := engine.AddQuery(mock.AddQueryRequest{
+			Shape:      types.Statement{Query: &types.Query{Model: "Post"}, Tenant: &tenant},
+			ResultHint: map[string][]interface{}{"Post": {map[string]interface{}{"id": "p1"}}},
+		})
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		if got, ok := added.Dependencies.GetTenant(); !ok || got != tenant {
+			t.Errorf("Dependencies.Tenant = %q, want %q", got, tenant)
+		}
+		shapes[tenant] = added.ShapeID
+	}
+	if shapes[acme] == shapes[globex] {
+		t.Fatalf("tenants share shape %s", shapes[acme])
+	}
+
+	change := types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}}, Sets: []types.KV{{Field: "title", Value: "x"}}}
+	explained, err := engine.ExplainInvalidation(mock.ExplainRequest{ShapeID: shapes[globex], Mutation: types.Mutation{Changes: []types.Change{change}, Tenant: &acme}})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+	if explained.Invalidate {
+		t.Errorf("a mutation of acme explains invalidating globex: %v", explained.Reasons)
+	}
+
+	resp, err := engine.Invalidate(types.Mutation{Changes: []types.Change{change}, Tenant: &acme})
+	if err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if !reflect.DeepEqual(resp.Evict, []string{shapes[acme]}) {
+		t.Errorf("Invalidate() evicted %v, want only acme's %s", resp.Evict, shapes[acme])
+	}
+
+	resp, err = engine.Invalidate(types.Mutation{Changes: []types.Change{change}})
+	if err != nil {
+		t.Fatalf("Invalidate failed: %v", err)
+	}
+	if len(resp.Evict) != 2 {
+		t.Errorf("a mutation without a tenant evicted %v, want both tenants' shapes", resp.Evict)
+	}
+}
+
+func TestPolicyShapes(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	o1 := types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}}
+	o2 := types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o2")}}
+	shapes := map[string]string{}
+	for name, policy := range map[string]types.Filter{"o1": o1, "o2": o2} {
+		added, err := engine.AddQuery(mock.AddQueryRequest{
+			Shape:      types.Statement{Query: &types.Query{Model: "Post"}, Policy: &policy},
+			ResultHint: map[string][]interface{}{"Post": {map[string]interface{}{"id": "p1"}}},
+		})
+		if err != nil {
+			t.Fatalf("AddQuery failed: %v", err)
+		}
+		if !added.Dependencies.GetPolicy().Equal(&policy) {
+			t.Errorf("Dependencies.Policy = %+v, want %+v", added.Dependencies.Policy, policy)
+		}
+		shapes[name] = added.ShapeID
+	}
+	if shapes["o1"] == shapes["o2"] {
+		t.Fatalf("policies share shape %s", shapes["o1"])
+	}
+
+	// Moving an untracked row into o1 is explained by the policy
+	change := types.Change{Model: "Post", Action: "update", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p9")}}, Sets: []types.KV{{Field: "orgId", Value: "o1"}}}
+	explained, err := engine.ExplainInvalidation(mock.ExplainRequest{ShapeID: shapes["o1"], Mutation: types.Mutation{Changes: []types.Change{change}}})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+	found := false
+	for _, d := range explained.Details {
+		found = found || (d.Kind == "policy" && d.FilterPath == "policy")
+	}
+	if !found {
+		t.Errorf("ExplainInvalidation() = %+v, want the policy", explained)
+	}
+
+	resp := engine.EvictPolicy(o1)
+	if !reflect.DeepEqual(resp.Evict, []string{shapes["o1"]}) {
+		t.Errorf("EvictPolicy() evicted %v, want only %s", resp.Evict, shapes["o1"])
+	}
+	if _, ok := engine.GetDependencies(shapes["o2"]); !ok {
+		t.Error("EvictPolicy() evicted a shape of another policy")
+	}
+}
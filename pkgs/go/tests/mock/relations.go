@@ -0,0 +1,170 @@
+package mock
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// ResolvedInclude annotates an Include with the schema relation it loads.
+type ResolvedInclude struct {
+	// Path is the dotted relation path from the root model (e.g., "posts.comments.author").
+	Path string
+	// Parent is the model owning the relation.
+	Parent string
+	// Target is the related model.
+	Target string
+	// Kind is the relation cardinality ("one" or "many").
+	Kind string
+	// ParentFields and TargetFields are the join keys, position by position.
+	// When the schema omits them, a "many" relation joins parent "id" to
+	// "<parent>Id" on the target, and a "one" relation joins "<relation>Id"
+	// on the parent to target "id".
+	ParentFields []string
+	TargetFields []string
+	// Include points at the resolved include within the statement.
+	Include *types.Include
+}
+
+// ResolveIncludes resolves every include of the statement against the schema
+// and returns them depth-first, parents before children.
+//
+// Returns a ValidationError if the root model, an include query, or a
+// relation cannot be resolved.
+func ResolveIncludes(schema AppSchema, stmt *types.Statement) ([]ResolvedInclude, error) {
+	if stmt == nil || stmt.Query == nil {
+		return nil, &tests.ValidationError{Code: tests.CodeRequired, Message: "query is required to resolve includes", Path: "statement.query"}
+	}
+	if schema.model(stmt.Query.Model) == nil {
+		return nil, &tests.ValidationError{
+			Code:    tests.CodeUnknownModel,
+			Message: fmt.Sprintf("unknown model: %s", stmt.Query.Model),
+			Path:    "statement.query.model",
+		}
+	}
+	return schema.resolveIncludes(nil, stmt.Query.Model, nil, stmt.Includes, "statement")
+}
+
+func (s AppSchema) resolveIncludes(out []ResolvedInclude, parent string, parentPath []string, includes []types.Include, path string) ([]ResolvedInclude, error) {
+	for i := range includes {
+		include := &includes[i]
+		includePath := fmt.Sprintf("%s.includes[%d]", path, i)
+		if include.Query == nil {
+			return nil, &tests.ValidationError{Code: tests.CodeRequired, Message: "include requires a query", Path: includePath + ".query"}
+		}
+
+		name := include.Query.Model
+		rel, ok := s.relation(parent, name)
+		if !ok {
+			return nil, &tests.ValidationError{
+				Code:    tests.CodeUnknownRelation,
+				Message: fmt.Sprintf("unknown relation %s on model %s", name, parent),
+				Path:    includePath + ".query.model",
+			}
+		}
+
+		relPath := append(append([]string{}, parentPath...), name)
+		parentFields, targetFields := joinFields(parent, rel)
+		out = append(out, ResolvedInclude{
+			Path:         strings.Join(relPath, "."),
+			Parent:       parent,
+			Target:       rel.Target,
+			Kind:         rel.Kind,
+			ParentFields: parentFields,
+			TargetFields: targetFields,
+			Include:      include,
+		})
+
+		var err error
+		out, err = s.resolveIncludes(out, rel.Target, relPath, include.Includes, includePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// JoinKeys returns a tests.JoinKeyFunc backed by the schema relations, for
+// use with tests.SplitWith. Composite keys use their first field; unknown
+// relations are an error.
+func (s AppSchema) JoinKeys() tests.JoinKeyFunc {
+	return func(parentModel, relation string) (tests.JoinKey, error) {
+		rel, ok := s.relation(parentModel, relation)
+		if !ok {
+			return tests.JoinKey{}, fmt.Errorf("unknown relation %s on model %s", relation, parentModel)
+		}
+		parentFields, targetFields := joinFields(parentModel, rel)
+		return tests.JoinKey{ParentField: parentFields[0], ChildField: targetFields[0], ChildModel: rel.Target}, nil
+	}
+}
+
+// ModelFields returns the declared fields of a model, or nil if the model or
+// its fields are not declared.
+func (s AppSchema) ModelFields(model string) []string {
+	if m := s.model(model); m != nil {
+		return m.Fields
+	}
+	return nil
+}
+
+// KeyFields returns the primary key fields of a model, or ["id"] if the
+// model or its key fields are not declared.
+func (s AppSchema) KeyFields(model string) []string {
+	if m := s.model(model); m != nil && len(m.ID.Fields) > 0 {
+		return m.ID.Fields
+	}
+	return []string{"id"}
+}
+
+// SoftDeleteField returns the soft-delete field of a model, or "" if the
+// model or its field is not declared.
+func (s AppSchema) SoftDeleteField(model string) string {
+	if m := s.model(model); m != nil {
+		return m.SoftDelete
+	}
+	return ""
+}
+
+// RelationTarget resolves a relation of a model to its target model and
+// whether it is to-many.
+func (s AppSchema) RelationTarget(model, relation string) (string, bool, bool) {
+	rel, ok := s.relation(model, relation)
+	if !ok {
+		return "", false, false
+	}
+	return rel.Target, rel.Kind != "one", true
+}
+
+func (s AppSchema) model(name string) *Model {
+	for i := range s.Models {
+		if s.Models[i].Name == name {
+			return &s.Models[i]
+		}
+	}
+	return nil
+}
+
+func (s AppSchema) relation(modelName, relation string) (Relation, bool) {
+	model := s.model(modelName)
+	if model == nil {
+		return Relation{}, false
+	}
+	for _, rel := range model.Relations {
+		if rel.Name == relation {
+			return rel, true
+		}
+	}
+	return Relation{}, false
+}
+
+func joinFields(parent string, rel Relation) (parentFields, targetFields []string) {
+	if len(rel.Fields) > 0 && len(rel.References) > 0 {
+		return rel.Fields, rel.References
+	}
+	if rel.Kind == "one" {
+		return []string{rel.Name + "Id"}, []string{"id"}
+	}
+	return []string{"id"}, []string{parent + "Id"}
+}
@@ -0,0 +1,205 @@
+package mock_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/tests/mock"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+var blogSchema = mock.AppSchema{
+	Version: 1,
+	Models: []mock.Model{
+		{Name: "User", ID: mock.IDConfig{Kind: "string"}, Relations: []mock.Relation{
+			{Name: "posts", Target: "Post", Kind: "many", Fields: []string{"id"}, References: []string{"authorId"}},
+		}},
+		{Name: "Post", ID: mock.IDConfig{Kind: "string"}, Relations: []mock.Relation{
+			{Name: "comments", Target: "Comment", Kind: "many"},
+		}},
+		{Name: "Comment", ID: mock.IDConfig{Kind: "string"}, Relations: []mock.Relation{
+			{Name: "author", Target: "User", Kind: "one"},
+		}},
+	},
+}
+
+func TestResolveIncludes(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{Model: "User"},
+		Includes: []types.Include{{
+			Query: &types.Query{Model: "posts"},
+			Includes: []types.Include{{
+				Query:    &types.Query{Model: "comments"},
+				Includes: []types.Include{{Query: &types.Query{Model: "author"}}},
+			}},
+		}},
+	}
+
+	resolved, err := mock.ResolveIncludes(blogSchema, stmt)
+	if err != nil {
+		t.Fatalf("ResolveIncludes failed: %v", err)
+	}
+
+	if len(resolved) != 3 {
+		t.Fatalf("Expected 3 resolved includes, got %d", len(resolved))
+	}
+
+	want := []struct {
+		path, target, kind, parentField, targetField string
+	}{
+		{"posts", "Post", "many", "id", "authorId"},
+		{"posts.comments", "Comment", "many", "id", "PostId"},
+		{"posts.comments.author", "User", "one", "authorId", "id"},
+	}
+	for i, w := range want {
+		r := resolved[i]
+		if r.Path != w.path || r.Target != w.target || r.Kind != w.kind ||
+			r.ParentFields[0] != w.parentField || r.TargetFields[0] != w.targetField {
+			t.Errorf("resolved[%d] = %+v, want %+v", i, r, w)
+		}
+	}
+
+	if resolved[2].Include != &stmt.Includes[0].Includes[0].Includes[0] {
+		t.Error("Include should point into the statement")
+	}
+}
+
+func TestResolveIncludesUnknownRelation(t *testing.T) {
+	_, err := mock.ResolveIncludes(blogSchema, &types.Statement{
+		Query:    &types.Query{Model: "User"},
+		Includes: []types.Include{{Query: &types.Query{Model: "followers"}}},
+	})
+	if err == nil {
+		t.Fatal("Expected unknown relation error")
+	}
+	if verr, ok := err.(*tests.ValidationError); !ok || verr.Path != "statement.includes[0].query.model" || verr.Code != tests.CodeUnknownRelation {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestJoinKeysWithSplit(t *testing.T) {
+	parts, err := tests.SplitWith(&types.Statement{
+		Query: &types.Query{Model: "User"},
+		Includes: []types.Include{{
+			Query:    &types.Query{Model: "posts"},
+			Includes: []types.Include{{Query: &types.Query{Model: "comments"}}},
+		}},
+	}, blogSchema.JoinKeys())
+	if err != nil {
+		t.Fatalf("SplitWith failed: %v", err)
+	}
+
+	if parts[1].JoinKey.ChildField != "authorId" {
+		t.Errorf("Expected authorId join key, got %+v", parts[1].JoinKey)
+	}
+	// comments resolves from Post, the target of posts
+	if parts[2].JoinKey.ChildField != "PostId" {
+		t.Errorf("Expected PostId join key, got %+v", parts[2].JoinKey)
+	}
+
+	_, err = tests.SplitWith(&types.Statement{
+		Query:    &types.Query{Model: "User"},
+		Includes: []types.Include{{Query: &types.Query{Model: "followers"}}},
+	}, blogSchema.JoinKeys())
+	if verr, ok := err.(*tests.ValidationError); !ok || verr.Code != tests.CodeUnknownRelation || verr.Path != "statement.includes[0].query.model" {
+		t.Errorf("Expected unknown relation error, got %v", err)
+	}
+}
+
+func TestInvalidateFollowsIncludedRelations(t *testing.T) {
+	engine := mock.NewMockEngine(mock.MockEngineConfig{})
+	if err := engine.SetSchema(blogSchema); err != nil {
+		t.Fatalf("SetSchema failed: %v", err)
+	}
+
+	// Post -> comments (Comment) -> author (User)
+	added, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{
+			Query: &types.Query{Model: "Post"},
+			Includes: []types.Include{{
+				Query:    &types.Query{Model: "comments"},
+				Includes: []types.Include{{Query: &types.Query{Model: "author"}}},
+			}},
+		},
+		ResultHint: map[string][]interface{}{"Post": {map[string]interface{}{"id": "p1"}}},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	insert := func(model string) types.Mutation {
+		return types.Mutation{Changes: []types.Change{{Model: model, Action: "insert", Sets: []types.KV{{Field: "id", Value: "x"}}}}}
+	}
+	tcs := []struct {
+		model string
+		evict bool
+	}{
+		{"Comment", true},
+		{"User", true},
+		{"comments", false}, // the relation name, not its target model
+		{"Tag", false},
+	}
+	for _, tt := range tcs {
+		result, err := engine.Invalidate(insert(tt.model))
+		if err != nil {
+			t.Fatalf("Invalidate(%s) failed: %v", tt.model, err)
+		}
+		if got := len(result.Evict) == 1 && result.Evict[0] == added.ShapeID; got != tt.evict {
+			t.Errorf("Invalidate(%s) evicted %v, want eviction %v", tt.model, result.Evict, tt.evict)
+		}
+	}
+
+	explained, err := engine.ExplainInvalidation(mock.ExplainRequest{ShapeID: added.ShapeID, Mutation: insert("User")})
+	if err != nil {
+		t.Fatalf("ExplainInvalidation failed: %v", err)
+	}
+	want := []mock.ExplainReason{{Kind: "relation_dependency", Model: "User", FilterPath: "includes[0].includes[0]"}}
+	if !reflect.DeepEqual(explained.Details, want) {
+		t.Errorf("Details = %+v, want %+v", explained.Details, want)
+	}
+}
+
+func TestAddQueryTracksNestedIncludeRows(t *testing.T) {
+	// ORM rows key posts by "PostID" and everything else by "uuid"
+	schema := mock.AppSchema{Version: 1, Models: append([]mock.Model{}, blogSchema.Models...)}
+	schema.Models[1].ID.Fields = []string{"PostID"}
+	engine := mock.NewMockEngine(mock.MockEngineConfig{IDField: "uuid"})
+	if err := engine.SetSchema(schema); err != nil {
+		t.Fatalf("SetSchema failed: %v", err)
+	}
+
+	added, err := engine.AddQuery(mock.AddQueryRequest{
+		Shape: types.Statement{
+			Query: &types.Query{Model: "User"},
+			Includes: []types.Include{{
+				Query: &types.Query{Model: "posts"},
+				Includes: []types.Include{{
+					Query:    &types.Query{Model: "comments"},
+					Includes: []types.Include{{Query: &types.Query{Model: "author"}}},
+				}},
+			}},
+		},
+		ResultHint: map[string][]interface{}{"User": {
+			map[string]interface{}{"uuid": "u1", "posts": []interface{}{
+				map[string]interface{}{"PostID": "p1", "comments": []interface{}{
+					map[string]interface{}{"uuid": "c1", "author": map[string]interface{}{"uuid": "u2"}},
+					map[string]interface{}{"uuid": "c2", "author": map[string]interface{}{"uuid": "u1"}},
+				}},
+				map[string]interface{}{"PostID": "p2", "comments": []interface{}{}},
+			}},
+		}},
+	})
+	if err != nil {
+		t.Fatalf("AddQuery failed: %v", err)
+	}
+
+	want := map[string][]string{
+		"User":    {"u1", "u2"},
+		"Post":    {"p1", "p2"},
+		"Comment": {"c1", "c2"},
+	}
+	if got := added.Dependencies.Records; !reflect.DeepEqual(got, want) {
+		t.Errorf("Records = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"sort"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// NormalizeFilter returns a simplified filter that matches the same rows, so
+// SDKs emitting equivalent trees produce the same shape ID:
+//   - and children, single-element or lists and or lists nested in or are
+//     flattened into their parent
+//   - empty branches are removed; an or with an empty (always-true) branch
+//     is dropped
+//   - double negation is removed
+//   - identical conditions and branches are deduplicated, keeping the first
+//
+// The order of the remaining parts is preserved; Equivalent additionally
+// sorts them. Returns nil when the filter matches every row. The input is
+// not modified.
+func NormalizeFilter(f *types.Filter) *types.Filter {
+	if f == nil {
+		return nil
+	}
+	n := simplify(toBoolNode(*f), false)
+	if isTrue(n) {
+		return nil
+	}
+	out := fromBoolNode(n)
+	return &out
+}
+
+// boolNode is a filter as an explicit boolean tree. An "and" node without
+// children is the always-true filter.
+type boolNode struct {
+	op       string // "and", "or", "not" or "cond"
+	cond     types.Condition
+	children []boolNode
+	key      string // canonical JSON of the node, for sorting and dedup
+}
+
+func toBoolNode(f types.Filter) boolNode {
+	n := boolNode{op: "and"}
+	for _, c := range f.GetConditions() {
+		n.children = append(n.children, boolNode{op: "cond", cond: c})
+	}
+	for _, child := range f.GetAnd() {
+		n.children = append(n.children, toBoolNode(child))
+	}
+	if or := f.GetOr(); len(or) > 0 {
+		orNode := boolNode{op: "or"}
+		for _, child := range or {
+			orNode.children = append(orNode.children, toBoolNode(child))
+		}
+		n.children = append(n.children, orNode)
+	}
+	if f.Not != nil {
+		n.children = append(n.children, boolNode{op: "not", children: []boolNode{toBoolNode(*f.Not)}})
+	}
+	return n
+}
+
+// simplify flattens, deduplicates and drops always-true children, sorting
+// the children of AND/OR when sorted is set.
+func simplify(n boolNode, sorted bool) boolNode {
+	switch n.op {
+	case "cond":
+	case "not":
+		child := simplify(n.children[0], sorted)
+		if child.op == "not" {
+			return child.children[0]
+		}
+		n.children = []boolNode{child}
+	default:
+		var children []boolNode
+		seen := map[string]bool{}
+		for _, child := range n.children {
+			child = simplify(child, sorted)
+			if isTrue(child) {
+				if n.op == "or" {
+					// One always-true branch makes the whole OR true
+					return boolNode{op: "and", key: "{}"}
+				}
+				continue
+			}
+			flattened := []boolNode{child}
+			if child.op == n.op {
+				flattened = child.children
+			}
+			for _, c := range flattened {
+				if !seen[c.key] {
+					seen[c.key] = true
+					children = append(children, c)
+				}
+			}
+		}
+		if sorted {
+			sort.SliceStable(children, func(i, j int) bool {
+				return children[i].key < children[j].key
+			})
+		}
+		if len(children) == 1 {
+			return children[0]
+		}
+		if len(children) == 0 {
+			// AND of nothing, and an OR whose branches were all empty, match
+			// every row
+			return boolNode{op: "and", key: "{}"}
+		}
+		n.children = children
+	}
+	n.key = sortKey(fromBoolNode(n))
+	return n
+}
+
+func isTrue(n boolNode) bool {
+	return n.op == "and" && len(n.children) == 0
+}
+
+func fromBoolNode(n boolNode) types.Filter {
+	switch n.op {
+	case "cond":
+		return types.Filter{Conditions: &[]types.Condition{n.cond}}
+	case "not":
+		not := fromBoolNode(n.children[0])
+		return types.Filter{Not: &not}
+	case "or":
+		or := make([]types.Filter, len(n.children))
+		for i, child := range n.children {
+			or[i] = fromBoolNode(child)
+		}
+		return types.Filter{Or: &or}
+	}
+
+	// An AND node fills the parts of one filter where it can: conditions,
+	// then the first OR and NOT children; the rest nest under and
+	var f types.Filter
+	var conds []types.Condition
+	var and []types.Filter
+	for _, child := range n.children {
+		switch {
+		case child.op == "cond":
+			conds = append(conds, child.cond)
+		case child.op == "or" && f.Or == nil:
+			f.Or = fromBoolNode(child).Or
+		case child.op == "not" && f.Not == nil:
+			f.Not = fromBoolNode(child).Not
+		default:
+			and = append(and, fromBoolNode(child))
+		}
+	}
+	if len(conds) > 0 {
+		f.Conditions = &conds
+	}
+	if len(and) > 0 {
+		f.And = &and
+	}
+	return f
+}
+
+// sortKey orders values by their canonical JSON. A value that cannot be
+// canonicalized sorts first; the final Canonicalize reports the error.
+func sortKey(v interface{}) string {
+	key, _ := Canonicalize(v)
+	return key
+}
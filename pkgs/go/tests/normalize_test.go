@@ -0,0 +1,121 @@
+package tests_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestNormalizeFilter(t *testing.T) {
+	a := types.Eq("status", "published")
+	b := types.Gt("views", 10)
+	c := types.IsNull("deletedAt")
+
+	tcs := []struct {
+		name string
+		in   *types.Filter
+		want *types.Filter
+	}{
+		{
+			name: "nil",
+			in:   nil,
+			want: nil,
+		},
+		{
+			name: "single-element and",
+			in:   &types.Filter{And: &[]types.Filter{conds(a)}},
+			want: &types.Filter{Conditions: &[]types.Condition{a}},
+		},
+		{
+			name: "single-element or",
+			in:   &types.Filter{Or: &[]types.Filter{{And: &[]types.Filter{conds(a, b)}}}},
+			want: &types.Filter{Conditions: &[]types.Condition{a, b}},
+		},
+		{
+			name: "nested and keeps order",
+			in:   &types.Filter{Conditions: &[]types.Condition{c}, And: &[]types.Filter{conds(b), {And: &[]types.Filter{conds(a)}}}},
+			want: &types.Filter{Conditions: &[]types.Condition{c, b, a}},
+		},
+		{
+			name: "nested or is flattened",
+			in:   &types.Filter{Or: &[]types.Filter{conds(a), {Or: &[]types.Filter{conds(b), conds(c)}}}},
+			want: &types.Filter{Or: &[]types.Filter{conds(a), conds(b), conds(c)}},
+		},
+		{
+			name: "empty branches",
+			in:   &types.Filter{Conditions: &[]types.Condition{}, And: &[]types.Filter{{}, conds(a)}, Or: &[]types.Filter{}},
+			want: &types.Filter{Conditions: &[]types.Condition{a}},
+		},
+		{
+			name: "or with an empty branch matches everything",
+			in:   &types.Filter{Or: &[]types.Filter{conds(a), {}}},
+			want: nil,
+		},
+		{
+			name: "double negation",
+			in:   &types.Filter{Conditions: &[]types.Condition{a}, Not: &types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{b}}}},
+			want: &types.Filter{Conditions: &[]types.Condition{a, b}},
+		},
+		{
+			name: "single negation is kept",
+			in:   &types.Filter{Not: &types.Filter{And: &[]types.Filter{conds(a)}}},
+			want: &types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{a}}},
+		},
+		{
+			name: "duplicates",
+			in:   &types.Filter{Conditions: &[]types.Condition{a, b, a}, Or: &[]types.Filter{conds(c), conds(b), conds(c)}},
+			want: &types.Filter{Conditions: &[]types.Condition{a, b}, Or: &[]types.Filter{conds(c), conds(b)}},
+		},
+		{
+			name: "parts stay in place",
+			in:   &types.Filter{Conditions: &[]types.Condition{a}, Or: &[]types.Filter{conds(b), conds(c)}, Not: &types.Filter{Conditions: &[]types.Condition{c}}},
+			want: &types.Filter{Conditions: &[]types.Condition{a}, Or: &[]types.Filter{conds(b), conds(c)}, Not: &types.Filter{Conditions: &[]types.Condition{c}}},
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tests.NormalizeFilter(tt.in)
+			if !reflect.DeepEqual(got, tt.want) {
+				gotJSON, _ := tests.Canonicalize(got)
+				wantJSON, _ := tests.Canonicalize(tt.want)
+				t.Errorf("NormalizeFilter() = %s, want %s", gotJSON, wantJSON)
+			}
+		})
+	}
+}
+
+func TestNormalizeFilterUnifiesShapeIDs(t *testing.T) {
+	a := types.Eq("status", "published")
+	b := types.Gt("views", 10)
+	variants := []types.Filter{
+		conds(a, b),
+		{And: &[]types.Filter{conds(a), conds(b)}},
+		{And: &[]types.Filter{{And: &[]types.Filter{conds(a)}}, conds(b, b)}},
+		{Conditions: &[]types.Condition{a}, Not: &types.Filter{Not: &types.Filter{Conditions: &[]types.Condition{b}}}},
+	}
+
+	var first string
+	for i, f := range variants {
+		id, err := tests.ComputeQueryShapeID(where(*tests.NormalizeFilter(&f)))
+		if err != nil {
+			t.Fatalf("ComputeQueryShapeID failed: %v", err)
+		}
+		if i == 0 {
+			first = id
+		} else if id != first {
+			t.Errorf("variant %d shape ID = %s, want %s", i, id, first)
+		}
+	}
+}
+
+func TestNormalizeFilterDoesNotModifyInput(t *testing.T) {
+	in := &types.Filter{And: &[]types.Filter{conds(types.Eq("a", 1)), conds(types.Eq("a", 1))}}
+	before, _ := tests.Canonicalize(in)
+	tests.NormalizeFilter(in)
+	if after, _ := tests.Canonicalize(in); after != before {
+		t.Errorf("input modified: %s -> %s", before, after)
+	}
+}
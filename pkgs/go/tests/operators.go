@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Arity is the value shape a custom operator takes.
+type Arity string
+
+const (
+	ArityNone Arity = "none" // no value: null or absent
+	ArityOne  Arity = "one"  // a single value, not an array
+	ArityPair Arity = "pair" // a two-element array, like between
+	ArityList Arity = "list" // an array, or null while unbound, like in
+)
+
+// ValueValidator checks the value of a condition using a custom operator,
+// after its shape matches the operator's Arity. The value has been coerced
+// with types.Value.
+type ValueValidator func(value any) error
+
+// OperatorSpec describes a registered custom operator. Operators returns
+// them in a form that marshals to the registry file read by
+// "codegen operators", so TypeScript validators check the same shapes.
+type OperatorSpec struct {
+	Name  string `json:"name"`
+	Arity Arity  `json:"arity"`
+}
+
+type registeredOperator struct {
+	arity    Arity
+	validate ValueValidator
+}
+
+var (
+	operatorsMu sync.RWMutex
+	operators   = map[string]registeredOperator{}
+)
+
+// RegisterOperator declares a custom operator, such as "custom:fts", so that
+// validators check the values it is given: their shape against arity, then
+// validate if it is not nil. Conditions using operators that were never
+// registered are accepted as before.
+//
+// Returns an error if name lacks the custom: prefix, arity is unknown, or
+// name is already registered.
+func RegisterOperator(name string, arity Arity, validate ValueValidator) error {
+	if !strings.HasPrefix(name, "custom:") || len(name) == len("custom:") {
+		return fmt.Errorf("operator %q must be named custom:<name>", name)
+	}
+	switch arity {
+	case ArityNone, ArityOne, ArityPair, ArityList:
+	default:
+		return fmt.Errorf("operator %s: unknown arity %q", name, arity)
+	}
+
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+	if _, ok := operators[name]; ok {
+		return fmt.Errorf("operator %s is already registered", name)
+	}
+	operators[name] = registeredOperator{arity: arity, validate: validate}
+	return nil
+}
+
+// Operators returns the registered custom operators sorted by name.
+func Operators() []OperatorSpec {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+	specs := make([]OperatorSpec, 0, len(operators))
+	for name, op := range operators {
+		specs = append(specs, OperatorSpec{Name: name, Arity: op.arity})
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs
+}
+
+func lookupOperator(name string) (registeredOperator, bool) {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+	op, ok := operators[name]
+	return op, ok
+}
+
+// validateCustomOperator checks a custom operator condition against the
+// registry.
+func validateCustomOperator(atom *types.Condition, path string, c *collector) {
+	op, ok := lookupOperator(atom.Op)
+	if !ok {
+		return
+	}
+
+	value, err := types.Value(atom.Value)
+	if err != nil {
+		c.add(CodeInvalidValue, err.Error(), fmt.Sprintf("%s.value", path))
+		return
+	}
+	list, isList := value.([]any)
+	var shapeErr string
+	switch op.arity {
+	case ArityNone:
+		if value != nil {
+			shapeErr = "takes no value"
+		}
+	case ArityOne:
+		if isList {
+			shapeErr = "requires a single value, not an array"
+		}
+	case ArityPair:
+		if !isList || len(list) != 2 {
+			shapeErr = "requires a two-element array value"
+		}
+	case ArityList:
+		if !isList && value != nil {
+			shapeErr = "requires an array value"
+		}
+	}
+	if shapeErr != "" {
+		c.add(CodeInvalidValue, fmt.Sprintf("%s %s", atom.Op, shapeErr), fmt.Sprintf("%s.value", path))
+		return
+	}
+	if op.validate != nil {
+		if err := op.validate(value); err != nil {
+			c.add(CodeInvalidValue, fmt.Sprintf("%s: %v", atom.Op, err), fmt.Sprintf("%s.value", path))
+		}
+	}
+}
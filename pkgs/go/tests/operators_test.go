@@ -0,0 +1,116 @@
+package tests_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func init() {
+	mustRegister("custom:test_flag", tests.ArityNone, nil)
+	mustRegister("custom:test_fts", tests.ArityOne, func(value any) error {
+		if _, ok := value.(string); !ok {
+			return errors.New("requires a string query")
+		}
+		return nil
+	})
+	mustRegister("custom:test_near", tests.ArityPair, nil)
+	mustRegister("custom:test_any", tests.ArityList, nil)
+}
+
+func mustRegister(name string, arity tests.Arity, validate tests.ValueValidator) {
+	if err := tests.RegisterOperator(name, arity, validate); err != nil {
+		panic(err)
+	}
+}
+
+func customCondition(op string, value any) *types.Statement {
+	return &types.Statement{Query: &types.Query{
+		Model: "posts",
+		Where: &types.Filter{Conditions: &[]types.Condition{{Field: "body", Op: op, Value: value}}},
+	}}
+}
+
+func TestRegisteredOperatorValues(t *testing.T) {
+	tcs := []struct {
+		name  string
+		op    string
+		value any
+		valid bool
+	}{
+		{"none without value", "custom:test_flag", nil, true},
+		{"none with value", "custom:test_flag", true, false},
+		{"one", "custom:test_fts", "go generics", true},
+		{"one array", "custom:test_fts", []any{"go"}, false},
+		{"one rejected by validator", "custom:test_fts", 7, false},
+		{"pair", "custom:test_near", []any{1.5, 2}, true},
+		{"pair of three", "custom:test_near", []any{1, 2, 3}, false},
+		{"list", "custom:test_any", []string{"a", "b"}, true},
+		{"list unbound", "custom:test_any", nil, true},
+		{"list scalar", "custom:test_any", "a", false},
+		{"unregistered", "custom:test_unknown", []any{1}, true},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tests.ValidateQueryShape(customCondition(tt.op, tt.value))
+			if tt.valid {
+				if err != nil {
+					t.Errorf("ValidateQueryShape failed: %v", err)
+				}
+				return
+			}
+			var verr *tests.ValidationError
+			if !errors.As(err, &verr) || verr.Code != tests.CodeInvalidValue {
+				t.Errorf("ValidateQueryShape() error = %v, want %s", err, tests.CodeInvalidValue)
+			}
+		})
+	}
+}
+
+func TestRegisterOperatorErrors(t *testing.T) {
+	tcs := []struct {
+		name  string
+		op    string
+		arity tests.Arity
+	}{
+		{"missing prefix", "fts", tests.ArityOne},
+		{"empty name", "custom:", tests.ArityOne},
+		{"unknown arity", "custom:test_bad", tests.Arity("three")},
+		{"duplicate", "custom:test_fts", tests.ArityOne},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tests.RegisterOperator(tt.op, tt.arity, nil); err == nil {
+				t.Error("Expected error")
+			}
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	var got []tests.OperatorSpec
+	for _, op := range tests.Operators() {
+		if strings.HasPrefix(op.Name, "custom:test_") {
+			got = append(got, op)
+		}
+	}
+	want := []tests.OperatorSpec{
+		{Name: "custom:test_any", Arity: tests.ArityList},
+		{Name: "custom:test_flag", Arity: tests.ArityNone},
+		{Name: "custom:test_fts", Arity: tests.ArityOne},
+		{Name: "custom:test_near", Arity: tests.ArityPair},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("Operators() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("Operators()[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// ShapeDiff is one difference between the canonical forms of two
+// statements: the value at Path in each, as canonical JSON, or nil where the
+// path is absent.
+type ShapeDiff struct {
+	Path string          `json:"path"`
+	A    json.RawMessage `json:"a,omitempty"`
+	B    json.RawMessage `json:"b,omitempty"`
+}
+
+func (d ShapeDiff) String() string {
+	side := func(v json.RawMessage) string {
+		if v == nil {
+			return "(absent)"
+		}
+		return string(v)
+	}
+	return fmt.Sprintf("%s: %s != %s", d.Path, side(d.A), side(d.B))
+}
+
+// DiffShapes lists every difference between the canonical forms of two
+// statements, in key order, which is why their shape IDs differ; it returns
+// none when the shape IDs are equal. Paths are like Equivalent's, e.g.
+// "query.where.conditions[0].value". An object or array present on one side
+// only is reported once, at its own path; arrays are compared element by
+// element, so an insertion shows as a difference at every later index.
+//
+// Unlike Equivalent, DiffShapes does not normalize: statements that differ
+// only in the order of their conditions or includes differ here too.
+//
+// Returns an error if either statement cannot be canonicalized.
+func DiffShapes(a, b *types.Statement) ([]ShapeDiff, error) {
+	ca, err := CanonicalizeQueryShape(a)
+	if err != nil {
+		return nil, fmt.Errorf("cannot canonicalize a: %w", err)
+	}
+	cb, err := CanonicalizeQueryShape(b)
+	if err != nil {
+		return nil, fmt.Errorf("cannot canonicalize b: %w", err)
+	}
+
+	var va, vb interface{}
+	if err := json.Unmarshal([]byte(ca), &va); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal([]byte(cb), &vb); err != nil {
+		return nil, err
+	}
+	return appendShapeDiffs(nil, "", va, vb), nil
+}
+
+func appendShapeDiffs(diffs []ShapeDiff, path string, a, b interface{}) []ShapeDiff {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(av)+len(bv))
+		for k := range av {
+			keys = append(keys, k)
+		}
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			x, inA := av[k]
+			y, inB := bv[k]
+			switch {
+			case !inA:
+				diffs = append(diffs, ShapeDiff{Path: joinPath(path, k), B: canonicalRaw(y)})
+			case !inB:
+				diffs = append(diffs, ShapeDiff{Path: joinPath(path, k), A: canonicalRaw(x)})
+			default:
+				diffs = appendShapeDiffs(diffs, joinPath(path, k), x, y)
+			}
+		}
+		return diffs
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			elem := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				diffs = append(diffs, ShapeDiff{Path: elem, B: canonicalRaw(bv[i])})
+			case i >= len(bv):
+				diffs = append(diffs, ShapeDiff{Path: elem, A: canonicalRaw(av[i])})
+			default:
+				diffs = appendShapeDiffs(diffs, elem, av[i], bv[i])
+			}
+		}
+		return diffs
+	}
+
+	ja, jb := canonicalRaw(a), canonicalRaw(b)
+	if string(ja) == string(jb) {
+		return diffs
+	}
+	return append(diffs, ShapeDiff{Path: displayPath(path), A: ja, B: jb})
+}
+
+func canonicalRaw(v interface{}) json.RawMessage {
+	return json.RawMessage(describeValue(v))
+}
@@ -0,0 +1,119 @@
+package tests_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestDiffShapes(t *testing.T) {
+	limit := func(n int) *types.Statement {
+		s := where(conds(types.Eq("status", "published"), types.Gt("views", 10)))
+		s.Query.Limit = &n
+		return s
+	}
+	sdk := "1.2.3"
+	withSDK := limit(10)
+	withSDK.SDKVersion = &sdk
+
+	tcs := []struct {
+		name string
+		x, y *types.Statement
+		want []string
+	}{
+		{
+			name: "same shape",
+			x:    limit(10),
+			y:    limit(10),
+		},
+		{
+			name: "diagnostic fields are left out",
+			x:    limit(10),
+			y:    withSDK,
+		},
+		{
+			name: "changed value",
+			x:    where(conds(types.Gt("views", 10))),
+			y:    where(conds(types.Gt("views", "10"))),
+			want: []string{`query.where.conditions[0].value: 10 != "10"`},
+		},
+		{
+			name: "every difference",
+			x:    limit(10),
+			y:    where(conds(types.Eq("status", "draft"), types.Gte("views", 10))),
+			want: []string{
+				`query.limit: 10 != (absent)`,
+				`query.where.conditions[0].value: "published" != "draft"`,
+				`query.where.conditions[1].op: "gt" != "gte"`,
+			},
+		},
+		{
+			name: "commuted conditions are not normalized",
+			x:    where(conds(types.Eq("a", 1), types.Eq("b", 2))),
+			y:    where(conds(types.Eq("b", 2), types.Eq("a", 1))),
+			want: []string{
+				`query.where.conditions[0].field: "a" != "b"`,
+				`query.where.conditions[0].value: 1 != 2`,
+				`query.where.conditions[1].field: "b" != "a"`,
+				`query.where.conditions[1].value: 2 != 1`,
+			},
+		},
+		{
+			name: "extra element and object",
+			x:    where(conds(types.Eq("a", 1))),
+			y: &types.Statement{
+				Query:      &types.Query{Model: "Post", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("a", 1), types.IsNull("b")}}},
+				Pagination: &types.Pagination{First: &[]int{5}[0]},
+			},
+			want: []string{
+				`pagination: (absent) != {"first":5}`,
+				`query.where.conditions[1]: (absent) != {"field":"b","op":"isNull"}`,
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			diffs, err := tests.DiffShapes(tc.x, tc.y)
+			if err != nil {
+				t.Fatalf("DiffShapes: %v", err)
+			}
+			var got []string
+			for _, d := range diffs {
+				got = append(got, d.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("DiffShapes() =\n  %q\nwant\n  %q", got, tc.want)
+			}
+
+			idX, _ := tests.ComputeQueryShapeID(tc.x)
+			idY, _ := tests.ComputeQueryShapeID(tc.y)
+			if (idX == idY) != (len(diffs) == 0) {
+				t.Errorf("shape IDs equal = %v, but %d differences", idX == idY, len(diffs))
+			}
+		})
+	}
+}
+
+func TestShapeDiffJSON(t *testing.T) {
+	x := where(conds(types.Eq("a", 1)))
+	y := where(conds(types.Eq("a", 2)))
+	n := 5
+	y.Query.Limit = &n
+
+	diffs, err := tests.DiffShapes(x, y)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(diffs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"path":"query.limit","b":5},{"path":"query.where.conditions[0].value","a":1,"b":2}]`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+}
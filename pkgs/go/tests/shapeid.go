@@ -1,23 +1,198 @@
 package tests
 
 import (
+	"crypto"
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"strings"
 
 	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/walk"
 )
 
+// ShapeIDConfig says how shape IDs are derived from canonical JSON: Prefix,
+// then the lowercase hex digest of the canonical JSON under Hash, then
+// "_v<Version>" for versions after the first. A spec revision that rolls the
+// hash adds a config with a new Version, so its IDs never collide with
+// existing cache keys and ParseShapeID can tell them apart.
+type ShapeIDConfig struct {
+	Hash    crypto.Hash
+	Prefix  string
+	Version int
+}
+
+// ShapeIDV1 derives the shape IDs of this spec version: "s_" followed by the
+// hex SHA-256, with no version suffix. The schema's shape_id pattern admits
+// only these.
+var ShapeIDV1 = ShapeIDConfig{Hash: crypto.SHA256, Prefix: ShapeIDPrefix, Version: 1}
+
 // ComputeShapeID computes shapeId from canonical JSON
 func ComputeShapeID(canonicalJSON string) string {
-	hash := sha256.Sum256([]byte(canonicalJSON))
-	return fmt.Sprintf("s_%x", hash)
+	return ComputeShapeIDWith(canonicalJSON, ShapeIDV1)
+}
+
+// ComputeShapeIDWith computes the shape ID of canonical JSON under cfg. It
+// panics if cfg.Hash is not linked into the binary, as crypto.Hash.New does.
+func ComputeShapeIDWith(canonicalJSON string, cfg ShapeIDConfig) string {
+	h := cfg.Hash.New()
+	h.Write([]byte(canonicalJSON))
+	return cfg.id(h.Sum(nil))
+}
+
+func (c ShapeIDConfig) id(digest []byte) string {
+	return c.Prefix + hex.EncodeToString(digest) + c.suffix()
+}
+
+func (c ShapeIDConfig) suffix() string {
+	if c.Version <= 1 {
+		return ""
+	}
+	return fmt.Sprintf("_v%d", c.Version)
 }
 
-// ComputeQueryShapeID is a convenience wrapper
+// ParseShapeID reports which of configs produced id, and its hex digest. With
+// no configs it recognizes ShapeIDV1 IDs only. Returns an error if id matches
+// none of them.
+func ParseShapeID(id string, configs ...ShapeIDConfig) (ShapeIDConfig, string, error) {
+	if len(configs) == 0 {
+		configs = []ShapeIDConfig{ShapeIDV1}
+	}
+	for _, cfg := range configs {
+		digest, ok := strings.CutPrefix(id, cfg.Prefix)
+		if !ok {
+			continue
+		}
+		if digest, ok = strings.CutSuffix(digest, cfg.suffix()); !ok {
+			continue
+		}
+		if cfg.Hash.Available() && len(digest) == 2*cfg.Hash.Size() && isLowerHex(digest) {
+			return cfg, digest, nil
+		}
+	}
+	return ShapeIDConfig{}, "", fmt.Errorf("shape ID %q matches no known shape ID version", id)
+}
+
+func isLowerHex(s string) bool {
+	for _, r := range s {
+		if !(r >= '0' && r <= '9' || r >= 'a' && r <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// ComputeQueryShapeID computes the shapeId of a statement, streaming its
+// canonical JSON into the hash instead of building the string. The fields
+// the schema marks x-diagnostic, ORMVersion, SDKVersion and SpecVersion, are
+// left out, so every SDK and ORM emitting the same read shares its shape.
+// The policy is ANDed into the root where, so one query under different
+// policies gets distinct shape IDs, and a policy shares its shape with the
+// same filter written in the where.
 func ComputeQueryShapeID(shape *types.Statement) (string, error) {
-	canonical, err := CanonicalizeQueryShape(shape)
+	h := ShapeIDV1.Hash.New()
+	if err := WriteCanonical(h, withPolicyMerged(withoutDiagnostics(shape))); err != nil {
+		return "", err
+	}
+	return ShapeIDV1.id(h.Sum(nil)), nil
+}
+
+// ComputeMutationID computes a mutationId ("m_" + hex SHA-256 of the
+// canonical mutation) that engines can use to deduplicate replayed events.
+func ComputeMutationID(m *types.Mutation) (string, error) {
+	h := sha256.New()
+	if err := WriteCanonical(h, m); err != nil {
+		return "", err
+	}
+	return "m_" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ComputePageInvariantShapeID computes a shapeId that ignores pagination
+// cursors, so every page of one query shares a shape family.
+//
+// The After and Before cursors are dropped before hashing; page sizes (First,
+// Last) are kept because they change what a page can contain. Register
+// dependencies under this ID to invalidate all pages together, and keep the
+// per-page boundary in Dependencies.LastRow.
+func ComputePageInvariantShapeID(stmt *types.Statement) (string, error) {
+	if stmt == nil || stmt.Pagination == nil {
+		return ComputeQueryShapeID(stmt)
+	}
+
+	invariant := *stmt
+	p := *stmt.Pagination
+	p.After, p.Before = nil, nil
+	if p.First == nil && p.Last == nil {
+		invariant.Pagination = nil
+	} else {
+		invariant.Pagination = &p
+	}
+	return ComputeQueryShapeID(&invariant)
+}
+
+// ComputeTenantSharedShapeID computes a shapeId that ignores the statement's
+// tenant, so every tenant's copy of one read shares a shape.
+//
+// ComputeQueryShapeID hashes the tenant in, which keeps tenants' caches
+// apart by key alone. Engines that share shape IDs across tenants instead
+// must store each tenant's Dependencies under the ID separately, and set
+// Dependencies.Tenant so mutations of other tenants leave them be.
+func ComputeTenantSharedShapeID(stmt *types.Statement) (string, error) {
+	if stmt == nil || stmt.Tenant == nil {
+		return ComputeQueryShapeID(stmt)
+	}
+	shared := *stmt
+	shared.Tenant = nil
+	return ComputeQueryShapeID(&shared)
+}
+
+// ComputeStructuralShapeID computes a shapeId that ignores literal values, so
+// statements differing only in their condition values share one structural
+// shape.
+//
+// Every condition value, in where, having, join, policy and include filters
+// and in subqueries, is replaced by a placeholder naming its JSON type:
+// "<string>", "<number>", "<boolean>", "<array>" or "<object>". Absent and
+// null values stay absent. Everything else, limits and cursors included, is
+// hashed as written. Key caches on this ID to group a query family, and keep
+// tracking the concrete values in Dependencies.Filters.
+func ComputeStructuralShapeID(stmt *types.Statement) (string, error) {
+	if stmt == nil {
+		return ComputeQueryShapeID(stmt)
+	}
+	structural := stmt.Clone()
+	err := walk.Walk(structural, walk.Visitor{Condition: func(path string, c *types.Condition) error {
+		placeholder, err := typePlaceholder(c.Value)
+		if err != nil {
+			return fmt.Errorf("%s.value: %w", path, err)
+		}
+		c.Value = placeholder
+		return nil
+	}})
 	if err != nil {
 		return "", err
 	}
-	return ComputeShapeID(canonical), nil
+	return ComputeQueryShapeID(structural)
+}
+
+// typePlaceholder returns the structural placeholder for a condition value,
+// or nil for a null one.
+func typePlaceholder(v any) (any, error) {
+	coerced, err := types.Value(v)
+	if err != nil {
+		return nil, err
+	}
+	switch coerced.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return "<string>", nil
+	case bool:
+		return "<boolean>", nil
+	case []any:
+		return "<array>", nil
+	case map[string]any:
+		return "<object>", nil
+	}
+	return "<number>", nil
 }
@@ -0,0 +1,304 @@
+package tests_test
+
+import (
+	"crypto"
+	_ "crypto/sha512"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestComputePageInvariantShapeID(t *testing.T) {
+	first, other := 10, 20
+	cursorA, cursorB := "eyJpZCI6MX0=", "eyJpZCI6Mn0="
+	query := &types.Query{Model: "posts"}
+
+	page := func(p *types.Pagination) *types.Statement {
+		return &types.Statement{Query: query, Pagination: p}
+	}
+	id := func(stmt *types.Statement) string {
+		t.Helper()
+		got, err := tests.ComputePageInvariantShapeID(stmt)
+		if err != nil {
+			t.Fatalf("ComputePageInvariantShapeID: %v", err)
+		}
+		return got
+	}
+
+	firstPage := id(page(&types.Pagination{First: &first}))
+	if got := id(page(&types.Pagination{First: &first, After: &cursorA})); got != firstPage {
+		t.Errorf("after cursor changed the shape: %s != %s", got, firstPage)
+	}
+	if got := id(page(&types.Pagination{First: &first, After: &cursorB})); got != firstPage {
+		t.Errorf("different cursor changed the shape: %s != %s", got, firstPage)
+	}
+	if got := id(page(&types.Pagination{First: &other, After: &cursorA})); got == firstPage {
+		t.Error("page size should remain part of the shape")
+	}
+
+	unpaged := id(page(nil))
+	if got := id(page(&types.Pagination{Before: &cursorA})); got != unpaged {
+		t.Errorf("cursor-only pagination should match the unpaged shape: %s != %s", got, unpaged)
+	}
+
+	// The regular shape ID still distinguishes pages.
+	withCursor := page(&types.Pagination{First: &first, After: &cursorA})
+	regular, err := tests.ComputeQueryShapeID(withCursor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regular == firstPage {
+		t.Error("ComputeQueryShapeID should include the cursor")
+	}
+	if withCursor.Pagination.After == nil {
+		t.Error("input statement was mutated")
+	}
+}
+
+func TestComputeQueryShapeIDIgnoresDiagnostics(t *testing.T) {
+	plain := &types.Statement{Query: &types.Query{Model: "posts"}}
+	want, err := tests.ComputeQueryShapeID(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantCanonical, err := tests.CanonicalizeQueryShape(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	orm, sdk, spec := "prisma@5", "1.2.3", types.SpecVersion
+	for name, set := range map[string]func(*types.Statement){
+		"orm_version":  func(s *types.Statement) { s.ORMVersion = &orm },
+		"sdk_version":  func(s *types.Statement) { s.SDKVersion = &sdk },
+		"spec_version": func(s *types.Statement) { s.SpecVersion = &spec },
+		"all": func(s *types.Statement) {
+			s.ORMVersion, s.SDKVersion, s.SpecVersion = &orm, &sdk, &spec
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			declared := &types.Statement{Query: plain.Query}
+			set(declared)
+			before := declared.Clone()
+
+			if got, err := tests.ComputeQueryShapeID(declared); err != nil || got != want {
+				t.Errorf("ComputeQueryShapeID() = %s, %v, want %s", got, err, want)
+			}
+			if got, err := tests.CanonicalizeQueryShape(declared); err != nil || got != wantCanonical {
+				t.Errorf("CanonicalizeQueryShape() = %s, %v, want %s", got, err, wantCanonical)
+			}
+			if !reflect.DeepEqual(declared, before) {
+				t.Error("the caller's diagnostic fields were cleared")
+			}
+		})
+	}
+}
+
+func TestComputeQueryShapeIDMergesPolicy(t *testing.T) {
+	query := &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("published", true)}}}
+	ids := map[string]bool{}
+	for _, policy := range []*types.Filter{
+		nil,
+		{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+		{Conditions: &[]types.Condition{types.Eq("orgId", "o2")}},
+	} {
+		id, err := tests.ComputeQueryShapeID(&types.Statement{Query: query, Policy: policy})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[id] = true
+	}
+	if len(ids) != 3 {
+		t.Errorf("one query under different policies should get distinct shape IDs, got %v", ids)
+	}
+
+	// The policy is ANDed into the root where, or stands in for a missing one
+	policy := &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}}
+	for _, tt := range []struct{ scoped, merged *types.Statement }{
+		{
+			scoped: &types.Statement{Query: query, Policy: policy},
+			merged: &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{And: &[]types.Filter{*query.Where, *policy}}}},
+		},
+		{
+			scoped: &types.Statement{Query: &types.Query{Model: "posts"}, Policy: policy},
+			merged: &types.Statement{Query: &types.Query{Model: "posts", Where: policy}},
+		},
+	} {
+		scoped, err := tests.ComputeQueryShapeID(tt.scoped)
+		if err != nil {
+			t.Fatal(err)
+		}
+		merged, err := tests.ComputeQueryShapeID(tt.merged)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if scoped != merged {
+			t.Errorf("policy %v should hash as part of the where, got %s and %s", tt.scoped.Policy, scoped, merged)
+		}
+	}
+}
+
+func TestComputeTenantSharedShapeID(t *testing.T) {
+	acme, globex := "acme", "globex"
+	query := &types.Query{Model: "posts"}
+	unscoped, err := tests.ComputeQueryShapeID(&types.Statement{Query: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ids := map[string]bool{}
+	for _, tenant := range []*string{&acme, &globex} {
+		stmt := &types.Statement{Query: query, Tenant: tenant}
+		shared, err := tests.ComputeTenantSharedShapeID(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if shared != unscoped {
+			t.Errorf("tenant %s changed the shared shape: %s != %s", *tenant, shared, unscoped)
+		}
+		scoped, err := tests.ComputeQueryShapeID(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[scoped] = true
+		if stmt.Tenant != tenant {
+			t.Error("input statement was mutated")
+		}
+	}
+	if len(ids) != 2 || ids[unscoped] {
+		t.Errorf("ComputeQueryShapeID should give each tenant a shape of its own, got %v", ids)
+	}
+}
+
+func TestComputeStructuralShapeID(t *testing.T) {
+	stmt := func(author any, views int, tags ...string) *types.Statement {
+		return &types.Statement{
+			Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{
+				types.Eq("authorId", author),
+				types.InQuery("categoryId", types.Query{Model: "categories", Fields: &[]string{"id"}, Where: &types.Filter{
+					Conditions: &[]types.Condition{types.In("tag", tags)},
+				}}),
+			}}},
+			Having: &types.Filter{Conditions: &[]types.Condition{types.Gt("views", views)}},
+		}
+	}
+	id := func(s *types.Statement) string {
+		t.Helper()
+		got, err := tests.ComputeStructuralShapeID(s)
+		if err != nil {
+			t.Fatalf("ComputeStructuralShapeID failed: %v", err)
+		}
+		return got
+	}
+
+	base := stmt("u1", 10, "go")
+	want := id(base)
+	if got := id(stmt("u2", 500, "sql", "db")); got != want {
+		t.Errorf("different values changed the structural shape: %s != %s", got, want)
+	}
+	if got := id(stmt(42, 10, "go")); got == want {
+		t.Error("a value of another type should change the structural shape")
+	}
+	if got := id(stmt(nil, 10, "go")); got == want {
+		t.Error("a null value should change the structural shape")
+	}
+
+	regular, err := tests.ComputeQueryShapeID(base)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if regular == want {
+		t.Error("ComputeQueryShapeID should include the values")
+	}
+	if (*base.Query.Where.Conditions)[0].Value != "u1" || (*base.Having.Conditions)[0].Value != 10 {
+		t.Error("input statement was mutated")
+	}
+
+	bad := &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{
+		{Field: "score", Op: "gt", Value: make(chan int)},
+	}}}}
+	if _, err := tests.ComputeStructuralShapeID(bad); err == nil {
+		t.Error("expected an error for a value with no JSON form")
+	}
+}
+
+func TestComputeMutationID(t *testing.T) {
+	tx1, tx2 := "tx-1", "tx-2"
+	mutation := func(txID *string) *types.Mutation {
+		return &types.Mutation{TxID: txID, Changes: []types.Change{{
+			Model:  "Post",
+			Action: "update",
+			Sets:   []types.KV{{Field: "views", Value: 10}},
+			Where:  &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "p1")}},
+		}}}
+	}
+	id := func(m *types.Mutation) string {
+		t.Helper()
+		got, err := tests.ComputeMutationID(m)
+		if err != nil {
+			t.Fatalf("ComputeMutationID failed: %v", err)
+		}
+		return got
+	}
+
+	if id(mutation(&tx1)) != id(mutation(&tx1)) {
+		t.Error("A replayed mutation should keep its mutationId")
+	}
+	if id(mutation(&tx1)) == id(mutation(&tx2)) {
+		t.Error("Mutations from different transactions should have different mutationIds")
+	}
+
+	m := mutation(nil)
+	m.Changes = append(m.Changes, types.Change{Model: "Post", Action: "delete"})
+	reordered := mutation(nil)
+	reordered.Changes = append([]types.Change{{Model: "Post", Action: "delete"}}, reordered.Changes...)
+	if id(m) == id(reordered) {
+		t.Error("Change order should be part of the mutationId")
+	}
+}
+
+func TestComputeShapeIDWith(t *testing.T) {
+	canonical := `{"query":{"model":"Post"}}`
+	if got := tests.ComputeShapeIDWith(canonical, tests.ShapeIDV1); got != tests.ComputeShapeID(canonical) {
+		t.Errorf("ShapeIDV1 ID = %s, want %s", got, tests.ComputeShapeID(canonical))
+	}
+
+	v2 := tests.ShapeIDConfig{Hash: crypto.SHA512, Prefix: "s_", Version: 2}
+	id := tests.ComputeShapeIDWith(canonical, v2)
+	if !strings.HasPrefix(id, "s_") || !strings.HasSuffix(id, "_v2") || len(id) != len("s_")+128+len("_v2") {
+		t.Errorf("v2 ID = %s, want s_ + 128 hex digits + _v2", id)
+	}
+}
+
+func TestParseShapeID(t *testing.T) {
+	canonical := `{"query":{"model":"Post"}}`
+	v2 := tests.ShapeIDConfig{Hash: crypto.SHA512, Prefix: "s_", Version: 2}
+	v1ID := tests.ComputeShapeID(canonical)
+	v2ID := tests.ComputeShapeIDWith(canonical, v2)
+
+	cfg, digest, err := tests.ParseShapeID(v1ID)
+	if err != nil || cfg != tests.ShapeIDV1 || "s_"+digest != v1ID {
+		t.Errorf("ParseShapeID(v1) = %v, %s, %v", cfg, digest, err)
+	}
+	if _, _, err := tests.ParseShapeID(v2ID); err == nil {
+		t.Error("v2 IDs should not parse without the v2 config")
+	}
+
+	known := []tests.ShapeIDConfig{tests.ShapeIDV1, v2}
+	for _, tt := range []struct {
+		id   string
+		want tests.ShapeIDConfig
+	}{{v1ID, tests.ShapeIDV1}, {v2ID, v2}} {
+		if cfg, _, err := tests.ParseShapeID(tt.id, known...); err != nil || cfg != tt.want {
+			t.Errorf("ParseShapeID(%s) = %v, %v; want %v", tt.id, cfg, err, tt.want)
+		}
+	}
+
+	for _, bad := range []string{"", "s_", "m_" + v1ID[2:], strings.ToUpper(v1ID), v1ID + "0", v1ID + "_v2", v2ID[:len(v2ID)-3]} {
+		if _, _, err := tests.ParseShapeID(bad, known...); err == nil {
+			t.Errorf("ParseShapeID(%q) should fail", bad)
+		}
+	}
+}
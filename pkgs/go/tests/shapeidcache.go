@@ -0,0 +1,421 @@
+package tests
+
+import (
+	"container/list"
+	"hash/maphash"
+	"math"
+	"reflect"
+	"sync"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// DefaultShapeIDCacheCapacity is the capacity of a ShapeIDCache created with
+// a capacity of zero or less.
+const DefaultShapeIDCacheCapacity = 1024
+
+// ShapeIDCache remembers the shape IDs of recently hashed statements, so
+// test suites and mock engines that hash the same statements over and over
+// skip canonicalizing and hashing them. A statement hits the cache when it
+// is identical to one hashed before, field for field and with condition
+// values of the same types: it may be a copy, or the same statement modified
+// since. Statements that are only Equal, such as ones holding int64(1) and
+// float64(1), or a time and its RFC 3339 string, are hashed apart.
+// Once full, the cache drops the least recently used statement.
+//
+// Condition values are cloned as Statement.Clone clones them, so values held
+// through pointers must not be modified after hashing.
+//
+// A ShapeIDCache is safe for concurrent use.
+type ShapeIDCache struct {
+	mu       sync.Mutex
+	capacity int
+	seed     maphash.Seed
+	lru      *list.List // of *shapeIDEntry, most recently used first
+	buckets  map[uint64][]*list.Element
+	hits     uint64
+	misses   uint64
+}
+
+// ShapeIDCacheStats counts the lookups of a ShapeIDCache.
+type ShapeIDCacheStats struct {
+	Hits   uint64
+	Misses uint64
+	Len    int // statements cached
+}
+
+type shapeIDEntry struct {
+	key     uint64
+	stmt    *types.Statement // a clone, so callers may modify theirs
+	shapeID string
+}
+
+// NewShapeIDCache returns a cache holding up to capacity statements, or
+// DefaultShapeIDCacheCapacity if capacity is zero or less.
+func NewShapeIDCache(capacity int) *ShapeIDCache {
+	if capacity <= 0 {
+		capacity = DefaultShapeIDCacheCapacity
+	}
+	return &ShapeIDCache{
+		capacity: capacity,
+		seed:     maphash.MakeSeed(),
+		lru:      list.New(),
+		buckets:  make(map[uint64][]*list.Element),
+	}
+}
+
+// ShapeID returns the shape ID of stmt as ComputeQueryShapeID computes it,
+// from the cache when an equal statement was hashed before.
+func (c *ShapeIDCache) ShapeID(stmt *types.Statement) (string, error) {
+	key := c.fingerprint(stmt)
+
+	c.mu.Lock()
+	if elem := c.lookup(key, stmt); elem != nil {
+		c.hits++
+		c.lru.MoveToFront(elem)
+		shapeID := elem.Value.(*shapeIDEntry).shapeID
+		c.mu.Unlock()
+		return shapeID, nil
+	}
+	c.misses++
+	c.mu.Unlock()
+
+	shapeID, err := ComputeQueryShapeID(stmt)
+	if err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.lookup(key, stmt) == nil {
+		c.add(&shapeIDEntry{key: key, stmt: stmt.Clone(), shapeID: shapeID})
+	}
+	return shapeID, nil
+}
+
+// Stats returns the cache's hit and miss counts and its size.
+func (c *ShapeIDCache) Stats() ShapeIDCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return ShapeIDCacheStats{Hits: c.hits, Misses: c.misses, Len: c.lru.Len()}
+}
+
+// Reset empties the cache and zeroes its counters.
+func (c *ShapeIDCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Init()
+	c.buckets = make(map[uint64][]*list.Element)
+	c.hits, c.misses = 0, 0
+}
+
+func (c *ShapeIDCache) lookup(key uint64, stmt *types.Statement) *list.Element {
+	for _, elem := range c.buckets[key] {
+		if sameStatement(elem.Value.(*shapeIDEntry).stmt, stmt) {
+			return elem
+		}
+	}
+	return nil
+}
+
+func (c *ShapeIDCache) add(entry *shapeIDEntry) {
+	c.buckets[entry.key] = append(c.buckets[entry.key], c.lru.PushFront(entry))
+	if c.lru.Len() <= c.capacity {
+		return
+	}
+
+	oldest := c.lru.Back()
+	c.lru.Remove(oldest)
+	evicted := oldest.Value.(*shapeIDEntry)
+	bucket := c.buckets[evicted.key]
+	for i, elem := range bucket {
+		if elem == oldest {
+			bucket = append(bucket[:i], bucket[i+1:]...)
+			break
+		}
+	}
+	if len(bucket) == 0 {
+		delete(c.buckets, evicted.key)
+	} else {
+		c.buckets[evicted.key] = bucket
+	}
+}
+
+// fingerprint hashes the parts of stmt that tell statements apart cheaply:
+// models, fields, operators and scalar values. Identical statements get the
+// same fingerprint; others that collide are told apart by sameStatement.
+func (c *ShapeIDCache) fingerprint(stmt *types.Statement) uint64 {
+	var h maphash.Hash
+	h.SetSeed(c.seed)
+	if stmt == nil {
+		return h.Sum64()
+	}
+	fingerprintQuery(&h, stmt.Query)
+	fingerprintFilter(&h, stmt.Having)
+	fingerprintIncludes(&h, stmt.Includes)
+	fingerprintFilter(&h, stmt.Policy)
+	if stmt.Tenant != nil {
+		h.WriteString(*stmt.Tenant)
+	}
+	return h.Sum64()
+}
+
+func fingerprintQuery(h *maphash.Hash, q *types.Query) {
+	if q == nil {
+		h.WriteByte(0)
+		return
+	}
+	h.WriteString(q.Model)
+	h.WriteByte(0)
+	fingerprintFilter(h, q.Where)
+	if q.Limit != nil {
+		fingerprintFloat(h, float64(*q.Limit))
+	}
+	if q.Offset != nil {
+		fingerprintFloat(h, float64(*q.Offset))
+	}
+}
+
+func fingerprintFilter(h *maphash.Hash, f *types.Filter) {
+	if f == nil {
+		h.WriteByte(0)
+		return
+	}
+	h.WriteByte('(')
+	if f.Conditions != nil {
+		for i := range *f.Conditions {
+			cond := &(*f.Conditions)[i]
+			h.WriteString(cond.Field)
+			h.WriteByte(0)
+			h.WriteString(cond.Op)
+			h.WriteByte(0)
+			fingerprintValue(h, cond.Value)
+			fingerprintQuery(h, cond.Subquery)
+		}
+	}
+	fingerprintFilters(h, f.And)
+	fingerprintFilters(h, f.Or)
+	fingerprintFilter(h, f.Not)
+	h.WriteByte(')')
+}
+
+func fingerprintFilters(h *maphash.Hash, filters *[]types.Filter) {
+	if filters != nil {
+		for i := range *filters {
+			fingerprintFilter(h, &(*filters)[i])
+		}
+	}
+}
+
+func fingerprintIncludes(h *maphash.Hash, includes []types.Include) {
+	for i := range includes {
+		fingerprintQuery(h, includes[i].Query)
+		fingerprintIncludes(h, includes[i].Includes)
+	}
+}
+
+// fingerprintValue hashes strings, booleans and common numbers. Other values
+// are left to sameStatement.
+func fingerprintValue(h *maphash.Hash, v any) {
+	switch val := v.(type) {
+	case string:
+		h.WriteString(val)
+		h.WriteByte(0)
+	case bool:
+		if val {
+			h.WriteByte('t')
+		} else {
+			h.WriteByte('f')
+		}
+	case float64:
+		fingerprintFloat(h, val)
+	case int:
+		fingerprintFloat(h, float64(val))
+	}
+}
+
+func fingerprintFloat(h *maphash.Hash, f float64) {
+	if f == 0 {
+		f = 0 // -0 equals 0
+	}
+	bits := math.Float64bits(f)
+	var b [8]byte
+	for i := range b {
+		b[i] = byte(bits >> (8 * i))
+	}
+	h.Write(b[:])
+}
+
+// The same* functions compare statements exactly, unlike Statement.Equal:
+// nil and empty slices differ, as do values of different types, since either
+// can change the canonical JSON. Diagnostic fields are not compared, as shape
+// IDs leave them out.
+
+func sameStatement(a, b *types.Statement) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return sameQuery(a.Query, b.Query) &&
+		samePagination(a.Pagination, b.Pagination) &&
+		samePtrSlice(a.GroupBy, b.GroupBy) &&
+		sameFilter(a.Having, b.Having) &&
+		sameEach(a.Includes, b.Includes, sameInclude) &&
+		samePtr(a.Tenant, b.Tenant) &&
+		sameFilter(a.Policy, b.Policy)
+}
+
+func samePagination(a, b *types.Pagination) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return samePtr(a.First, b.First) && samePtr(a.Last, b.Last) &&
+		samePtr(a.After, b.After) && samePtr(a.Before, b.Before)
+}
+
+func sameQuery(a, b *types.Query) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Model == b.Model &&
+		samePtrSlice(a.Fields, b.Fields) &&
+		sameFilter(a.Where, b.Where) &&
+		samePtrEach(a.OrderBy, b.OrderBy, sameOrderBy) &&
+		samePtr(a.Limit, b.Limit) &&
+		samePtr(a.Offset, b.Offset) &&
+		samePtrSlice(a.Distinct, b.Distinct) &&
+		samePtrEach(a.Aggregations, b.Aggregations, sameAggregate) &&
+		samePtrEach(a.Joins, b.Joins, sameJoin) &&
+		samePtrEach(a.Windows, b.Windows, sameWindow) &&
+		sameDistinctOn(a.DistinctOn, b.DistinctOn) &&
+		samePtr(a.Deleted, b.Deleted)
+}
+
+func sameAggregate(a, b *types.Aggregate) bool {
+	return a.Func == b.Func && samePtr(a.Field, b.Field) && samePtr(a.Alias, b.Alias)
+}
+
+func sameJoin(a, b *types.Join) bool {
+	return a.Relation == b.Relation && a.Kind == b.Kind &&
+		sameFilter(a.On, b.On) && samePtrSlice(a.Fields, b.Fields)
+}
+
+func sameWindow(a, b *types.Window) bool {
+	return a.Func == b.Func && samePtr(a.Field, b.Field) &&
+		samePtrSlice(a.PartitionBy, b.PartitionBy) &&
+		samePtrEach(a.OrderBy, b.OrderBy, sameOrderBy) &&
+		sameFrame(a.Frame, b.Frame) && a.Alias == b.Alias
+}
+
+func sameFrame(a, b *types.WindowFrame) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Unit == b.Unit && samePtr(a.Start, b.Start) && samePtr(a.End, b.End)
+}
+
+func sameDistinctOn(a, b *types.DistinctOn) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return sameSlice(a.Fields, b.Fields) && sameEach(a.OrderBy, b.OrderBy, sameOrderBy)
+}
+
+func sameInclude(a, b *types.Include) bool {
+	return sameQuery(a.Query, b.Query) && samePtr(a.Kind, b.Kind) &&
+		sameEach(a.Includes, b.Includes, sameInclude) && samePtr(a.Count, b.Count)
+}
+
+func sameOrderBy(a, b *types.OrderBy) bool {
+	return a.Field == b.Field &&
+		samePtr(a.Descending, b.Descending) &&
+		samePtr(a.NullsFirst, b.NullsFirst) &&
+		samePtr(a.CaseSensitive, b.CaseSensitive)
+}
+
+func sameFilter(a, b *types.Filter) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return samePtrEach(a.And, b.And, sameFilter) &&
+		samePtrEach(a.Or, b.Or, sameFilter) &&
+		sameFilter(a.Not, b.Not) &&
+		samePtrEach(a.Conditions, b.Conditions, sameCondition)
+}
+
+func sameCondition(a, b *types.Condition) bool {
+	return a.Field == b.Field &&
+		sameSlice(a.FieldPath, b.FieldPath) &&
+		a.Op == b.Op &&
+		sameValue(a.Value, b.Value) &&
+		sameQuery(a.Subquery, b.Subquery)
+}
+
+// sameValue compares condition values of the same type. Scalars are
+// compared directly and anything else with reflect.DeepEqual.
+func sameValue(a, b any) bool {
+	switch va := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		vb, ok := b.(string)
+		return ok && va == vb
+	case bool:
+		vb, ok := b.(bool)
+		return ok && va == vb
+	case int:
+		vb, ok := b.(int)
+		return ok && va == vb
+	case int64:
+		vb, ok := b.(int64)
+		return ok && va == vb
+	case float64:
+		vb, ok := b.(float64)
+		return ok && va == vb
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func samePtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func sameSlice[T comparable](a, b []T) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func samePtrSlice[T comparable](a, b *[]T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return sameSlice(*a, *b)
+}
+
+func sameEach[T any](a, b []T, same func(*T, *T) bool) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !same(&a[i], &b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func samePtrEach[T any](a, b *[]T, same func(*T, *T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return sameEach(*a, *b, same)
+}
@@ -0,0 +1,142 @@
+package tests_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestShapeIDCache(t *testing.T) {
+	cache := tests.NewShapeIDCache(2)
+	shapeID := func(stmt *types.Statement) string {
+		t.Helper()
+		got, err := cache.ShapeID(stmt)
+		if err != nil {
+			t.Fatalf("ShapeID failed: %v", err)
+		}
+		want, err := tests.ComputeQueryShapeID(stmt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("ShapeID() = %s, want %s", got, want)
+		}
+		return got
+	}
+	expect := func(hits, misses uint64, n int) {
+		t.Helper()
+		want := tests.ShapeIDCacheStats{Hits: hits, Misses: misses, Len: n}
+		if stats := cache.Stats(); stats != want {
+			t.Errorf("Stats() = %+v, want %+v", stats, want)
+		}
+	}
+
+	stmt := &types.Statement{Query: &types.Query{
+		Model: "posts",
+		Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("status", "published")}},
+	}}
+	first := 10
+	stmt.Pagination = &types.Pagination{First: &first}
+	shapeID(stmt)
+	shapeID(stmt)
+	shapeID(stmt.Clone())
+	expect(2, 1, 1)
+
+	// modifying a hashed statement does not return its old ID
+	(*stmt.Query.Where.Conditions)[0].Value = "draft"
+	shapeID(stmt)
+	five := 5
+	stmt.Query.Limit = &five
+	shapeID(stmt)
+	expect(2, 3, 2)
+
+	// the least recently used statement is dropped
+	shapeID(&types.Statement{Query: &types.Query{Model: "users"}})
+	expect(2, 4, 2)
+	shapeID(stmt)
+	expect(3, 4, 2)
+
+	// values that are only Equal are hashed apart
+	a := &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("n", 1)}}}}
+	b := &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("n", int64(1))}}}}
+	shapeID(a)
+	shapeID(b)
+	expect(3, 6, 2)
+
+	// diagnostic fields are left out of the shape, so share its ID
+	orm := "prisma@5"
+	diagnostic := b.Clone()
+	diagnostic.ORMVersion = &orm
+	shapeID(diagnostic)
+	expect(4, 6, 2)
+
+	// tenants are part of the shape, so each gets an ID of its own
+	acme, globex := b.Clone(), b.Clone()
+	acme.Tenant, globex.Tenant = strPtr("acme"), strPtr("globex")
+	if shapeID(acme) == shapeID(globex) {
+		t.Error("statements of different tenants share a shape ID")
+	}
+	expect(4, 8, 2)
+
+	// a policy is ANDed into the where, so it shares the shape of the same
+	// filter written there, but is cached apart
+	scoped, merged := b.Clone(), b.Clone()
+	scoped.Policy = &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}}
+	merged.Query.Where = &types.Filter{And: &[]types.Filter{*b.Query.Where, *scoped.Policy}}
+	if shapeID(scoped) != shapeID(merged) {
+		t.Error("a policy and the same filter in the where should share a shape ID")
+	}
+	expect(4, 10, 2)
+
+	cache.Reset()
+	expect(0, 0, 0)
+}
+
+func TestShapeIDCacheErrors(t *testing.T) {
+	cache := tests.NewShapeIDCache(0)
+	stmt := &types.Statement{Query: &types.Query{Model: "posts", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("c", make(chan int))}}}}
+	for i := 0; i < 2; i++ {
+		if _, err := cache.ShapeID(stmt); err == nil {
+			t.Fatal("expected error for unsupported value")
+		}
+	}
+	if stats := cache.Stats(); stats.Len != 0 || stats.Misses != 2 {
+		t.Errorf("Stats() = %+v, want 2 misses and nothing cached", stats)
+	}
+}
+
+func TestShapeIDCacheConcurrent(t *testing.T) {
+	cache := tests.NewShapeIDCache(8)
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				stmt := &types.Statement{Query: &types.Query{Model: fmt.Sprintf("m%d", i%16)}}
+				if _, err := cache.ShapeID(stmt); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if stats := cache.Stats(); stats.Hits+stats.Misses != 800 || stats.Len != 8 {
+		t.Errorf("Stats() = %+v", stats)
+	}
+}
+
+func BenchmarkShapeIDCacheHit(b *testing.B) {
+	cache := tests.NewShapeIDCache(0)
+	stmt := largeStatement(100)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := cache.ShapeID(stmt); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,307 @@
+package tests
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// JoinKey names the fields that link rows of a parent statement to rows of
+// a child relation statement.
+type JoinKey struct {
+	ParentField string // field read from parent rows (e.g., "id")
+	ChildField  string // field filtered on the child model (e.g., "authorId")
+	// ChildModel is the model the relation loads, which nested relations are
+	// resolved from. Empty means the relation name itself.
+	ChildModel string
+}
+
+// JoinKeyFunc resolves the join key for a relation loaded from a parent
+// model. It returns an error if the relation is unknown.
+type JoinKeyFunc func(parentModel, relation string) (JoinKey, error)
+
+// DefaultJoinKey links children to parents by the parent "id" field and a
+// "<parentModel>Id" field on the child. Executors with real relation metadata
+// should pass their own JoinKeyFunc to SplitWith.
+func DefaultJoinKey(parentModel, _ string) (JoinKey, error) {
+	return JoinKey{ParentField: "id", ChildField: parentModel + "Id"}, nil
+}
+
+// SplitPart is one independently executable statement produced by Split.
+type SplitPart struct {
+	// Path is the relation path from the root (empty for the root part).
+	Path []string
+	// Parent is the index of the parent part, or -1 for the root part.
+	Parent int
+	// JoinKey links this part to its parent. Zero for the root part.
+	JoinKey JoinKey
+	// Statement is the executable statement for this part. Child statements
+	// carry a join-key "in" condition whose value is filled by BindJoinKeys.
+	Statement types.Statement
+	// Limit and Offset are the include's, which bound the children of each
+	// parent row. They are left out of Statement, which reads the children
+	// of every parent at once; the caller applies them to each parent's
+	// children, in Statement's order_by order.
+	Limit  *int
+	Offset *int
+}
+
+// Split decomposes a Statement with loading includes into a root statement
+// plus one child statement per relation, using DefaultJoinKey.
+//
+// See SplitWith for details.
+func Split(stmt *types.Statement) ([]SplitPart, error) {
+	return SplitWith(stmt, DefaultJoinKey)
+}
+
+// SplitWith decomposes a Statement with loading includes into a root
+// statement plus one child statement per relation.
+//
+// Includes with Kind set filter the parent by the relation and cannot run
+// separately, so they stay on the statement they belong to. Loading includes
+// (Kind nil) become child parts whose where clause is ANDed with a join-key
+// "in" condition; their limit and offset move to SplitPart.Limit and
+// Offset, as they apply per parent row. Child statements keep the tenant,
+// spec version and diagnostic fields of stmt, so each tenant's children get
+// shape IDs of their own; the policy stays on the root part, as it scopes
+// the root model alone. Parts are returned parents-first, so executing them
+// in order always has the parent rows available for BindJoinKeys.
+//
+// Returns a ValidationError if the statement is nil, a loading include has
+// no query, or joinKey cannot resolve a relation.
+func SplitWith(stmt *types.Statement, joinKey JoinKeyFunc) ([]SplitPart, error) {
+	if stmt == nil {
+		return nil, &ValidationError{Code: CodeRequired, Message: "Statement cannot be nil", Path: "statement"}
+	}
+	if stmt.Query == nil {
+		return nil, &ValidationError{Code: CodeRequired, Message: "query is required to split a statement", Path: "statement.query"}
+	}
+
+	root := *stmt
+	root.Includes = filterIncludes(stmt.Includes)
+	parts := []SplitPart{{Parent: -1, Statement: root}}
+
+	base := types.Statement{
+		Tenant:      stmt.Tenant,
+		SpecVersion: stmt.SpecVersion,
+		ORMVersion:  stmt.ORMVersion,
+		SDKVersion:  stmt.SDKVersion,
+	}
+	return splitIncludes(parts, base, 0, stmt.Query.Model, nil, stmt.Includes, "statement", joinKey)
+}
+
+// splitIncludes appends a part for each loading include, with a statement
+// built on base.
+func splitIncludes(parts []SplitPart, base types.Statement, parent int, parentModel string, parentPath []string, includes []types.Include, path string, joinKey JoinKeyFunc) ([]SplitPart, error) {
+	for i, include := range includes {
+		if include.Kind != nil {
+			continue
+		}
+		includePath := fmt.Sprintf("%s.includes[%d]", path, i)
+		if include.Query == nil {
+			return nil, &ValidationError{Code: CodeRequired, Message: "loading include requires a query", Path: includePath + ".query"}
+		}
+
+		relation := include.Query.Model
+		key, err := joinKey(parentModel, relation)
+		if err != nil {
+			return nil, &ValidationError{Code: CodeUnknownRelation, Message: err.Error(), Path: includePath + ".query.model"}
+		}
+		childModel := key.ChildModel
+		if childModel == "" {
+			childModel = relation
+		}
+
+		query := *include.Query
+		query.Where = withJoinCondition(include.Query.Where, key.ChildField)
+		query.Limit, query.Offset = nil, nil
+
+		child := base
+		child.Query = &query
+		child.Includes = filterIncludes(include.Includes)
+
+		relPath := append(append([]string{}, parentPath...), relation)
+		parts = append(parts, SplitPart{
+			Path:      relPath,
+			Parent:    parent,
+			JoinKey:   key,
+			Statement: child,
+			Limit:     include.Query.Limit,
+			Offset:    include.Query.Offset,
+		})
+
+		parts, err = splitIncludes(parts, base, len(parts)-1, childModel, relPath, include.Includes, includePath, joinKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+// filterIncludes keeps only the includes with Kind set.
+func filterIncludes(includes []types.Include) []types.Include {
+	var kept []types.Include
+	for _, include := range includes {
+		if include.Kind != nil {
+			kept = append(kept, include)
+		}
+	}
+	return kept
+}
+
+func withJoinCondition(where *types.Filter, field string) *types.Filter {
+	join := types.Filter{Conditions: &[]types.Condition{{Field: field, Op: "in"}}}
+	if where == nil {
+		return &join
+	}
+	return &types.Filter{And: &[]types.Filter{join, *where}}
+}
+
+// BindJoinKeys returns a copy of a child part's statement with the join-key
+// condition bound to the given parent key values.
+func BindJoinKeys(part SplitPart, values []any) types.Statement {
+	stmt := part.Statement
+	if stmt.Query == nil || stmt.Query.Where == nil {
+		return stmt
+	}
+
+	query := *stmt.Query
+	join := *query.Where
+	if join.And != nil {
+		and := append([]types.Filter{}, (*join.And)...)
+		and[0] = bindJoinFilter(and[0], values)
+		join.And = &and
+	} else {
+		join = bindJoinFilter(join, values)
+	}
+	query.Where = &join
+	stmt.Query = &query
+	return stmt
+}
+
+func bindJoinFilter(f types.Filter, values []any) types.Filter {
+	if f.Conditions == nil || len(*f.Conditions) == 0 {
+		return f
+	}
+	conds := append([]types.Condition{}, (*f.Conditions)...)
+	conds[0].Value = values
+	f.Conditions = &conds
+	return f
+}
+
+// CombineDependencies merges the dependencies of executed split parts into
+// the dependencies of the original statement identified by shapeID.
+//
+// Record IDs are unioned per model and sorted; filters and includes are
+// concatenated in part order. The smallest TTL and earliest ValidUntil win,
+// so the combination goes stale when any part does, and window fields are
+// unioned and sorted. Tenant, Policy and SoftDeleteField are kept when every
+// part setting them agrees, and dropped otherwise.
+func CombineDependencies(shapeID string, parts ...types.Dependencies) types.Dependencies {
+	combined := types.Dependencies{
+		ShapeID:  shapeID,
+		Records:  map[string][]string{},
+		Filters:  []types.Filter{},
+		Includes: []types.Include{},
+	}
+
+	seen := make(map[string]map[string]bool)
+	windowFields := map[string]bool{}
+	var tenant, softDelete agreed[string]
+	var policy agreed[*types.Filter]
+	for _, deps := range parts {
+		for model, ids := range deps.Records {
+			if seen[model] == nil {
+				seen[model] = make(map[string]bool)
+			}
+			for _, id := range ids {
+				if !seen[model][id] {
+					seen[model][id] = true
+					combined.Records[model] = append(combined.Records[model], id)
+				}
+			}
+		}
+		combined.Filters = append(combined.Filters, deps.Filters...)
+		combined.Includes = append(combined.Includes, deps.Includes...)
+		if combined.LastRow == nil {
+			combined.LastRow = deps.LastRow
+		}
+		if combined.GroupBy == nil {
+			combined.GroupBy = deps.GroupBy
+		}
+		if deps.TTL != nil && (combined.TTL == nil || *deps.TTL < *combined.TTL) {
+			combined.TTL = deps.TTL
+		}
+		if deps.ValidUntil != nil && (combined.ValidUntil == nil || earlier(*deps.ValidUntil, *combined.ValidUntil)) {
+			combined.ValidUntil = deps.ValidUntil
+		}
+		for _, field := range deps.WindowFields {
+			windowFields[field] = true
+		}
+		if deps.Tenant != nil {
+			tenant.add(*deps.Tenant, func(a, b string) bool { return a == b })
+		}
+		if deps.SoftDeleteField != nil {
+			softDelete.add(*deps.SoftDeleteField, func(a, b string) bool { return a == b })
+		}
+		if deps.Policy != nil {
+			policy.add(deps.Policy, (*types.Filter).Equal)
+		}
+	}
+
+	for model := range combined.Records {
+		sort.Strings(combined.Records[model])
+	}
+	for field := range windowFields {
+		combined.WindowFields = append(combined.WindowFields, field)
+	}
+	sort.Strings(combined.WindowFields)
+	if v, ok := tenant.get(); ok {
+		combined.Tenant = &v
+	}
+	if v, ok := softDelete.get(); ok {
+		combined.SoftDeleteField = &v
+	}
+	if v, ok := policy.get(); ok {
+		combined.Policy = v
+	}
+
+	return combined
+}
+
+// agreed tracks a value that must be the same everywhere it is set.
+type agreed[T any] struct {
+	value    T
+	set      bool
+	conflict bool
+}
+
+func (a *agreed[T]) add(v T, equal func(a, b T) bool) {
+	switch {
+	case !a.set:
+		a.value, a.set = v, true
+	case !equal(a.value, v):
+		a.conflict = true
+	}
+}
+
+// get returns the value if it was set and never conflicted.
+func (a *agreed[T]) get() (T, bool) {
+	return a.value, a.set && !a.conflict
+}
+
+// earlier reports whether RFC 3339 instant a is before b. A value that does
+// not parse counts as earlier, so it is kept for validation to report.
+func earlier(a, b string) bool {
+	ta, err := time.Parse(time.RFC3339, a)
+	if err != nil {
+		return true
+	}
+	tb, err := time.Parse(time.RFC3339, b)
+	if err != nil {
+		return false
+	}
+	return ta.Before(tb)
+}
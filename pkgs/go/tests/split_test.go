@@ -0,0 +1,217 @@
+package tests_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestSplit(t *testing.T) {
+	some := "some"
+	stmt := &types.Statement{
+		Query: &types.Query{Model: "User"},
+		Includes: []types.Include{
+			{
+				Query: &types.Query{Model: "posts"},
+				Includes: []types.Include{
+					{Query: &types.Query{Model: "comments", Limit: intPtr(5), Offset: intPtr(2)}},
+				},
+			},
+			{
+				Kind:  &some,
+				Query: &types.Query{Model: "roles"},
+			},
+		},
+	}
+
+	parts, err := tests.Split(stmt)
+	if err != nil {
+		t.Fatalf("Split failed: %v", err)
+	}
+
+	if len(parts) != 3 {
+		t.Fatalf("Expected 3 parts, got %d", len(parts))
+	}
+
+	root := parts[0]
+	if root.Parent != -1 || len(root.Statement.Includes) != 1 || *root.Statement.Includes[0].Kind != "some" {
+		t.Errorf("Root should keep only the relation filter include, got %+v", root.Statement.Includes)
+	}
+
+	posts := parts[1]
+	if posts.Parent != 0 || posts.JoinKey.ChildField != "UserId" {
+		t.Errorf("Unexpected posts part: %+v", posts)
+	}
+
+	comments := parts[2]
+	if comments.Parent != 1 || len(comments.Path) != 2 || comments.Path[1] != "comments" {
+		t.Errorf("Unexpected comments part: %+v", comments)
+	}
+	// A per-parent limit would become global over every parent's children
+	if comments.Statement.Query.Limit != nil || comments.Statement.Query.Offset != nil {
+		t.Error("Child query should not keep the include's limit and offset")
+	}
+	if comments.Limit == nil || *comments.Limit != 5 || comments.Offset == nil || *comments.Offset != 2 {
+		t.Errorf("Child part should report the per-parent limit and offset, got %v, %v", comments.Limit, comments.Offset)
+	}
+	if stmt.Includes[0].Includes[0].Query.Limit == nil {
+		t.Error("Split modified the input statement")
+	}
+
+	// Every part must be a valid statement on its own
+	for i, part := range parts {
+		if err := tests.ValidateQueryShape(&part.Statement); err != nil {
+			t.Errorf("Part %d invalid: %v", i, err)
+		}
+	}
+}
+
+func TestSplitWithJoinKeyAndBind(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{Model: "User"},
+		Includes: []types.Include{
+			{Query: &types.Query{
+				Model: "posts",
+				Where: &types.Filter{Conditions: &[]types.Condition{
+					{Field: "published", Op: "eq", Value: true},
+				}},
+			}},
+		},
+	}
+
+	parts, err := tests.SplitWith(stmt, func(_, _ string) (tests.JoinKey, error) {
+		return tests.JoinKey{ParentField: "id", ChildField: "authorId"}, nil
+	})
+	if err != nil {
+		t.Fatalf("SplitWith failed: %v", err)
+	}
+
+	bound := tests.BindJoinKeys(parts[1], []any{"u1", "u2"})
+	and := *bound.Query.Where.And
+	join := (*and[0].Conditions)[0]
+	if join.Field != "authorId" || join.Op != "in" {
+		t.Errorf("Unexpected join condition: %+v", join)
+	}
+	if values, ok := join.Value.([]any); !ok || len(values) != 2 {
+		t.Errorf("Expected bound values, got %v", join.Value)
+	}
+
+	// Binding must not mutate the part
+	original := (*(*parts[1].Statement.Query.Where.And)[0].Conditions)[0]
+	if original.Value != nil {
+		t.Error("BindJoinKeys should not modify the split part")
+	}
+}
+
+func TestSplitKeepsTenant(t *testing.T) {
+	version := "0.1.0"
+	childShapeID := func(tenant string) string {
+		stmt := &types.Statement{
+			Query:       &types.Query{Model: "User"},
+			Includes:    []types.Include{{Query: &types.Query{Model: "posts"}}},
+			Tenant:      &tenant,
+			SpecVersion: &version,
+			Policy:      &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+		}
+		parts, err := tests.Split(stmt)
+		if err != nil {
+			t.Fatalf("Split failed: %v", err)
+		}
+		child := parts[1].Statement
+		if child.Tenant == nil || *child.Tenant != tenant || child.SpecVersion == nil || *child.SpecVersion != version {
+			t.Errorf("Child should keep the tenant and spec version, got %+v", child)
+		}
+		if child.Policy != nil || parts[0].Statement.Policy == nil {
+			t.Error("The policy should stay on the root part")
+		}
+		id, err := tests.ComputeQueryShapeID(&child)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+
+	if childShapeID("acme") == childShapeID("globex") {
+		t.Error("Children of different tenants share a shape ID")
+	}
+}
+
+func TestSplitRejectsInvalid(t *testing.T) {
+	if _, err := tests.Split(nil); err == nil {
+		t.Error("Should reject nil statement")
+	}
+
+	_, err := tests.Split(&types.Statement{
+		Query:    &types.Query{Model: "User"},
+		Includes: []types.Include{{}},
+	})
+	if err == nil || !contains(err.Error(), "statement.includes[0].query") {
+		t.Errorf("Expected include query error, got %v", err)
+	}
+}
+
+func TestCombineDependencies(t *testing.T) {
+	combined := tests.CombineDependencies("s_root",
+		types.Dependencies{Records: map[string][]string{"User": {"2", "1"}}},
+		types.Dependencies{
+			Records: map[string][]string{"User": {"1"}, "posts": {"p1"}},
+			Filters: []types.Filter{{}},
+		},
+	)
+
+	if combined.ShapeID != "s_root" {
+		t.Errorf("Expected s_root, got %s", combined.ShapeID)
+	}
+	if got := combined.Records["User"]; len(got) != 2 || got[0] != "1" || got[1] != "2" {
+		t.Errorf("Expected deduplicated sorted user IDs, got %v", got)
+	}
+	if len(combined.Records["posts"]) != 1 || len(combined.Filters) != 1 {
+		t.Errorf("Unexpected combined dependencies: %+v", combined)
+	}
+}
+
+func TestCombineDependenciesKeepsRules(t *testing.T) {
+	acme, deleted := "acme", "deletedAt"
+	policy := &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}}
+	combined := tests.CombineDependencies("s_root",
+		types.Dependencies{
+			TTL:             intPtr(60),
+			ValidUntil:      strPtr("2030-01-01T00:00:00Z"),
+			WindowFields:    []string{"views", "authorId"},
+			SoftDeleteField: &deleted,
+			Tenant:          &acme,
+			Policy:          policy,
+		},
+		types.Dependencies{
+			TTL:          intPtr(30),
+			ValidUntil:   strPtr("2029-12-31T23:00:00-02:00"),
+			WindowFields: []string{"authorId", "createdAt"},
+			Tenant:       strPtr("acme"),
+		},
+		types.Dependencies{TTL: intPtr(90)},
+	)
+
+	if combined.TTL == nil || *combined.TTL != 30 {
+		t.Errorf("Expected the smallest TTL, got %v", combined.TTL)
+	}
+	// 23:00 at -02:00 is 01:00 UTC the next day, after the first part's
+	if combined.ValidUntil == nil || *combined.ValidUntil != "2030-01-01T00:00:00Z" {
+		t.Errorf("Expected the earliest valid_until, got %v", combined.ValidUntil)
+	}
+	if got := combined.WindowFields; !reflect.DeepEqual(got, []string{"authorId", "createdAt", "views"}) {
+		t.Errorf("Expected the sorted union of window fields, got %v", got)
+	}
+	if combined.Tenant == nil || *combined.Tenant != "acme" || combined.SoftDeleteField == nil || *combined.SoftDeleteField != deleted || !combined.Policy.Equal(policy) {
+		t.Errorf("Expected the agreed tenant, soft-delete field and policy, got %+v", combined)
+	}
+
+	combined = tests.CombineDependencies("s_root",
+		types.Dependencies{Tenant: &acme, Policy: policy},
+		types.Dependencies{Tenant: strPtr("globex"), Policy: &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o2")}}},
+	)
+	if combined.Tenant != nil || combined.Policy != nil {
+		t.Errorf("Conflicting tenants and policies should be dropped, got %+v", combined)
+	}
+}
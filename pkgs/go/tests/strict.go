@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bold-minds/includekit-spec/go/limits"
+	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/walk"
+)
+
+// ValidateOption configures validation and decoding.
+type ValidateOption func(*validateOptions)
+
+type validateOptions struct {
+	strict bool
+	guard  limits.Guard
+}
+
+func newValidateOptions(opts []ValidateOption) validateOptions {
+	o := validateOptions{guard: limits.Default}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Strict restricts input to the pure spec subset, for platforms that need
+// byte-level cross-language determinism: custom: operators (the format's
+// escape hatch for raw, ORM-specific expressions) are rejected, and the
+// Decode functions also reject unknown or extension fields, including field
+// names in the wrong case.
+func Strict() ValidateOption {
+	return func(o *validateOptions) { o.strict = true }
+}
+
+// DecodeStatement reads one JSON document from r and decodes and validates a
+// Statement from it, in a single pass suited to untrusted input: the read
+// stops at the MaxPayloadBytes and MaxJSONDepth of the limits.Guard (see
+// WithLimits), and the document must be the only value in r. Unknown fields
+// are ignored unless the Strict option is given, as gateways should.
+func DecodeStatement(r io.Reader, opts ...ValidateOption) (*types.Statement, error) {
+	var stmt types.Statement
+	if err := decode(r, &stmt, "statement", opts); err != nil {
+		return nil, err
+	}
+	if err := ValidateQueryShape(&stmt, opts...); err != nil {
+		return nil, err
+	}
+	return &stmt, nil
+}
+
+// DecodeMutation is like DecodeStatement for a Mutation.
+func DecodeMutation(r io.Reader, opts ...ValidateOption) (*types.Mutation, error) {
+	var m types.Mutation
+	if err := decode(r, &m, "mutation", opts); err != nil {
+		return nil, err
+	}
+	if err := ValidateMutationEvent(&m, opts...); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// DecodeTransaction is like DecodeStatement for a Transaction.
+func DecodeTransaction(r io.Reader, opts ...ValidateOption) (*types.Transaction, error) {
+	var tx types.Transaction
+	if err := decode(r, &tx, "transaction", opts); err != nil {
+		return nil, err
+	}
+	if err := ValidateTransaction(&tx, opts...); err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// DecodeDependencies is like DecodeStatement for Dependencies.
+func DecodeDependencies(r io.Reader, opts ...ValidateOption) (*types.Dependencies, error) {
+	var deps types.Dependencies
+	if err := decode(r, &deps, "dependencies", opts); err != nil {
+		return nil, err
+	}
+	if err := ValidateDependencies(&deps, opts...); err != nil {
+		return nil, err
+	}
+	return &deps, nil
+}
+
+func decode(r io.Reader, v any, path string, opts []ValidateOption) error {
+	o := newValidateOptions(opts)
+	lr := o.guard.Reader(r, path)
+	dec := json.NewDecoder(lr)
+	var err error
+	if o.strict {
+		// Field names must match exactly, as they do in TypeScript, so the
+		// document is buffered for types.UnmarshalStrict.
+		var raw json.RawMessage
+		if err = dec.Decode(&raw); err == nil {
+			err = types.UnmarshalStrict(raw, v)
+		}
+	} else {
+		err = dec.Decode(v)
+	}
+	if err == nil && dec.More() {
+		err = errors.New("unexpected data after the document")
+	}
+	if err := lr.Err(); err != nil {
+		return limitExceeded(err)
+	}
+	if err != nil {
+		code := CodeInvalidJSON
+		var typeErr *json.UnmarshalTypeError
+		var fieldErr *types.UnknownFieldError
+		if errors.As(err, &typeErr) {
+			code = CodeInvalidType
+		} else if errors.As(err, &fieldErr) {
+			code = CodeUnknownField
+		}
+		return &ValidationError{Code: code, Message: fmt.Sprintf("invalid JSON: %v", err), Path: path}
+	}
+	return nil
+}
+
+func checkStrictStatement(stmt *types.Statement, c *collector) {
+	_ = walk.Walk(stmt, strictVisitor(c))
+}
+
+func checkStrictMutation(m *types.Mutation, c *collector) {
+	_ = walk.WalkMutation(m, strictVisitor(c))
+}
+
+func checkStrictDependencies(deps *types.Dependencies, c *collector) {
+	v := strictVisitor(c)
+	for i := range deps.Filters {
+		_ = walk.WalkFilter(&deps.Filters[i], fmt.Sprintf("dependencies.filters[%d]", i), v)
+	}
+	if deps.Policy != nil {
+		_ = walk.WalkFilter(deps.Policy, "dependencies.policy", v)
+	}
+	// Walk the includes as if they belonged to a statement, then fix the
+	// path prefix so errors point into the dependencies document.
+	start := len(c.errs)
+	_ = walk.Walk(&types.Statement{Includes: deps.Includes}, v)
+	for i := start; i < len(c.errs); i++ {
+		c.errs[i].Path = "dependencies" + strings.TrimPrefix(c.errs[i].Path, "statement")
+	}
+}
+
+// strictVisitor records every strict-mode violation and keeps walking.
+func strictVisitor(c *collector) walk.Visitor {
+	return walk.Visitor{Condition: func(path string, cond *types.Condition) error {
+		if err := checkStrictCondition(path, cond); err != nil {
+			c.addErr(err)
+		}
+		return nil
+	}}
+}
+
+func checkStrictCondition(path string, c *types.Condition) error {
+	if strings.HasPrefix(c.Op, "custom:") {
+		return &ValidationError{
+			Code:    CodeCustomOperator,
+			Message: fmt.Sprintf("custom operator %s is not allowed in strict mode", c.Op),
+			Path:    path + ".op",
+		}
+	}
+	return nil
+}
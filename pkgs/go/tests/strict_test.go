@@ -0,0 +1,106 @@
+package tests_test
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/tests"
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestStrictRejectsCustomOperators(t *testing.T) {
+	custom := &types.Filter{Conditions: &[]types.Condition{{Field: "name", Op: "custom:soundex", Value: "x"}}}
+	stmt := &types.Statement{
+		Query:    &types.Query{Model: "users"},
+		Includes: []types.Include{{Query: &types.Query{Model: "posts", Where: custom}}},
+	}
+
+	if err := tests.ValidateQueryShape(stmt); err != nil {
+		t.Fatalf("lenient validation should allow custom ops: %v", err)
+	}
+	err := tests.ValidateQueryShape(stmt, tests.Strict())
+	if err == nil || !strings.Contains(err.Error(), "statement.includes[0].query.where.conditions[0].op") {
+		t.Errorf("strict statement error = %v", err)
+	}
+
+	m := &types.Mutation{Changes: []types.Change{{Model: "users", Action: "delete", Where: custom}}}
+	if err := tests.ValidateMutationEvent(m, tests.Strict()); err == nil {
+		t.Error("strict mutation should reject custom ops")
+	}
+
+	deps := &types.Dependencies{
+		ShapeID:  "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Records:  map[string][]string{},
+		Filters:  []types.Filter{},
+		Includes: stmt.Includes,
+	}
+	err = tests.ValidateDependencies(deps, tests.Strict())
+	if err == nil || !strings.Contains(err.Error(), "at dependencies.includes[0]") {
+		t.Errorf("strict dependencies error = %v", err)
+	}
+}
+
+func TestDecodeStrictRejectsUnknownFields(t *testing.T) {
+	tcs := []struct {
+		name   string
+		decode func(io.Reader, ...tests.ValidateOption) error
+		doc    string
+	}{
+		{
+			name: "statement",
+			decode: func(r io.Reader, o ...tests.ValidateOption) error {
+				_, err := tests.DecodeStatement(r, o...)
+				return err
+			},
+			doc: `{"query":{"model":"users","x_raw_sql":"1=1"}}`,
+		},
+		{
+			name: "mutation",
+			decode: func(r io.Reader, o ...tests.ValidateOption) error {
+				_, err := tests.DecodeMutation(r, o...)
+				return err
+			},
+			doc: `{"changes":[{"model":"users","action":"insert","sets":[{"field":"id","value":1}],"x_hint":true}]}`,
+		},
+		{
+			name: "dependencies",
+			decode: func(r io.Reader, o ...tests.ValidateOption) error {
+				_, err := tests.DecodeDependencies(r, o...)
+				return err
+			},
+			doc: `{"shape_id":"s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef","records":{},"filters":[],"includes":[],"x_engine":1}`,
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.decode(strings.NewReader(tt.doc)); err != nil {
+				t.Errorf("lenient decode should ignore extension fields: %v", err)
+			}
+			err := tt.decode(strings.NewReader(tt.doc), tests.Strict())
+			if err == nil || !strings.Contains(err.Error(), "unknown field") {
+				t.Errorf("strict decode error = %v, want unknown field", err)
+			}
+		})
+	}
+}
+
+func TestDecodeValidates(t *testing.T) {
+	if _, err := tests.DecodeStatement(strings.NewReader(`{"query":{"model":""}}`)); err == nil {
+		t.Error("expected validation error for empty model")
+	}
+	if _, err := tests.DecodeStatement(strings.NewReader(`{`)); err == nil {
+		t.Error("expected error for malformed JSON")
+	}
+}
+
+func TestDecodeRejectsTrailingData(t *testing.T) {
+	_, err := tests.DecodeStatement(strings.NewReader(`{"query":{"model":"users"}} {"query":{"model":"posts"}}`))
+	if err == nil || !strings.Contains(err.Error(), "unexpected data") {
+		t.Errorf("DecodeStatement() error = %v, want unexpected data", err)
+	}
+	if _, err := tests.DecodeStatement(strings.NewReader("{\"query\":{\"model\":\"users\"}}\n")); err != nil {
+		t.Errorf("trailing whitespace: %v", err)
+	}
+}
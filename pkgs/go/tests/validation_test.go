@@ -1,8 +1,11 @@
 package tests_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
+	"github.com/bold-minds/includekit-spec/go/limits"
 	"github.com/bold-minds/includekit-spec/go/tests"
 	"github.com/bold-minds/includekit-spec/go/types"
 )
@@ -75,6 +78,275 @@ func TestValidateQueryShape_Comprehensive(t *testing.T) {
 			wantErr: true,
 			errMsg:  "distinct field must be non-empty",
 		},
+		{
+			name: "invalid aggregate func",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:        "Post",
+					Aggregations: &[]types.Aggregate{{Func: "median", Field: strPtr("views")}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "func must be",
+		},
+		{
+			name: "aggregate missing field",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:        "Post",
+					Aggregations: &[]types.Aggregate{{Func: "sum"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "sum requires a field",
+		},
+		{
+			name: "empty aggregate alias",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:        "Post",
+					Aggregations: &[]types.Aggregate{types.Count("").As("")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "alias must be non-empty",
+		},
+		{
+			name: "duplicate aggregate column",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:        "Post",
+					Aggregations: &[]types.Aggregate{types.Sum("views"), types.Max("views").As("sum_views")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "duplicate aggregate column: sum_views",
+		},
+		{
+			name: "windows",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Windows: &[]types.Window{
+						types.RowNumber("rank").PartitionedBy("authorId").OrderedBy(types.OrderBy{Field: "views"}),
+						types.WindowOf(types.Count(""), "n").Within(types.WindowFrame{Unit: "rows", Start: intPtr(-2), End: intPtr(0)}),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "ranking window without order_by",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", Windows: &[]types.Window{types.Rank("rank")}},
+			},
+			wantErr: true,
+			errMsg:  "rank requires order_by",
+		},
+		{
+			name: "aggregate window without field",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", Windows: &[]types.Window{{Func: "sum", Alias: "total"}}},
+			},
+			wantErr: true,
+			errMsg:  "sum requires a field",
+		},
+		{
+			name: "window column repeats an aggregate",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:        "Post",
+					Aggregations: &[]types.Aggregate{types.Count("")},
+					Windows:      &[]types.Window{types.WindowOf(types.Count(""), "count")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "duplicate window column: count",
+		},
+		{
+			name: "window frame ends before it starts",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", Windows: &[]types.Window{
+					types.WindowOf(types.Sum("views"), "total").Within(types.WindowFrame{Unit: "rows", Start: intPtr(1), End: intPtr(-1)}),
+				}},
+			},
+			wantErr: true,
+			errMsg:  "frame start must not follow its end",
+		},
+		{
+			name: "range frame offset without a single order_by",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", Windows: &[]types.Window{
+					types.WindowOf(types.Sum("views"), "total").Within(types.WindowFrame{Unit: "range", Start: intPtr(-7)}),
+				}},
+			},
+			wantErr: true,
+			errMsg:  "range frame offsets require exactly one order_by field",
+		},
+		{
+			name: "distinct_on",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", DistinctOn: &types.DistinctOn{
+					Fields:  []string{"authorId", "status"},
+					OrderBy: []types.OrderBy{{Field: "status"}, {Field: "authorId"}, {Field: "createdAt", Descending: boolPtr(true)}},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "distinct_on order_by not starting with its fields",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", DistinctOn: &types.DistinctOn{
+					Fields:  []string{"authorId"},
+					OrderBy: []types.OrderBy{{Field: "createdAt"}, {Field: "authorId"}},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "distinct_on order_by must start with its fields",
+		},
+		{
+			name: "distinct_on with distinct",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:      "Post",
+					Distinct:   &[]string{"authorId"},
+					DistinctOn: &types.DistinctOn{Fields: []string{"authorId"}, OrderBy: []types.OrderBy{{Field: "authorId"}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "distinct_on cannot be combined with distinct",
+		},
+		{
+			name: "distinct_on repeating a field",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "Post", DistinctOn: &types.DistinctOn{
+					Fields:  []string{"authorId", "authorId"},
+					OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "authorId"}},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "duplicate distinct_on field: authorId",
+		},
+		{
+			name:    "only deleted rows",
+			shape:   &types.Statement{Query: &types.Query{Model: "Post", Deleted: strPtr("only")}},
+			wantErr: false,
+		},
+		{
+			name:    "unknown deleted mode",
+			shape:   &types.Statement{Query: &types.Query{Model: "Post", Deleted: strPtr("exclude")}},
+			wantErr: true,
+			errMsg:  "deleted must be 'include' or 'only', got: exclude",
+		},
+		{
+			name: "invalid join kind",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Joins: &[]types.Join{{Relation: "author", Kind: "outer"}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "kind must be 'inner' or 'left'",
+		},
+		{
+			name: "empty join relation",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Joins: &[]types.Join{types.InnerJoin("")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "relation must be a non-empty string",
+		},
+		{
+			name: "duplicate join relation",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Joins: &[]types.Join{types.InnerJoin("author"), types.LeftJoin("author")},
+				},
+			},
+			wantErr: true,
+			errMsg:  "duplicate join of relation: author",
+		},
+		{
+			name: "invalid join condition",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Joins: &[]types.Join{types.InnerJoin("author", types.Condition{Field: "name", Op: "soundsLike"})},
+				},
+			},
+			wantErr: true,
+			errMsg:  "statement.query.joins[0].on.conditions[0].op",
+		},
+		{
+			name: "custom operator without a name",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Where: &types.Filter{Conditions: &[]types.Condition{{Field: "body", Op: "custom:"}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "invalid operator: custom: at statement.query.where.conditions[0].op",
+		},
+		{
+			name: "subquery selecting two fields",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Where: &types.Filter{Conditions: &[]types.Condition{
+						types.InQuery("authorId", types.Query{Model: "User", Fields: &[]string{"id", "name"}}),
+					}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "subquery must select exactly one field",
+		},
+		{
+			name: "subquery without op",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Where: &types.Filter{Conditions: &[]types.Condition{
+						{Field: "authorId", Op: "eq", Value: "u1", Subquery: &types.Query{Model: "User", Fields: &[]string{"id"}}},
+					}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "eq does not take a subquery",
+		},
+		{
+			name: "inQuery without subquery",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Where: &types.Filter{Conditions: &[]types.Condition{{Field: "authorId", Op: "inQuery"}}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "inQuery requires a subquery",
+		},
+		{
+			name: "invalid subquery filter",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Where: &types.Filter{Conditions: &[]types.Condition{
+						types.ExistsQuery("authorId", types.Query{
+							Model:  "User",
+							Fields: &[]string{"id"},
+							Where:  &types.Filter{Conditions: &[]types.Condition{{Field: "", Op: "eq", Value: 1}}},
+						}),
+					}},
+				},
+			},
+			wantErr: true,
+			errMsg:  "statement.query.where.conditions[0].subquery.where.conditions[0].field",
+		},
 		{
 			name: "empty groupBy field",
 			shape: &types.Statement{
@@ -110,6 +382,47 @@ func TestValidateQueryShape_Comprehensive(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid with aggregations",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model:        "Post",
+					Aggregations: &[]types.Aggregate{types.Count(""), types.Sum("views").As("totalViews")},
+				},
+				GroupBy: &[]string{"authorId"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid with joins",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Joins: &[]types.Join{
+						types.InnerJoin("author", types.Eq("active", true)).Select("name"),
+						types.LeftJoin("category"),
+					},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "valid with subqueries",
+			shape: &types.Statement{
+				Query: &types.Query{
+					Model: "Post",
+					Where: &types.Filter{Conditions: &[]types.Condition{
+						types.InQuery("authorId", types.Query{
+							Model:  "User",
+							Fields: &[]string{"id"},
+							Where:  &types.Filter{Conditions: &[]types.Condition{types.Eq("active", true)}},
+						}),
+						types.ExistsQuery("id", types.Query{Model: "Comment", Fields: &[]string{"postId"}}),
+					}},
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "valid with distinct",
 			shape: &types.Statement{
@@ -120,6 +433,118 @@ func TestValidateQueryShape_Comprehensive(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "malformed spec_version",
+			shape: &types.Statement{
+				Query:       &types.Query{Model: "Post"},
+				SpecVersion: strPtr("0.1"),
+			},
+			wantErr: true,
+			errMsg:  "must be X.Y.Z",
+		},
+		{
+			name: "spec_version from the future",
+			shape: &types.Statement{
+				Query:       &types.Query{Model: "Post"},
+				SpecVersion: strPtr("99.0.0"),
+			},
+			wantErr: true,
+			errMsg:  "newer than supported",
+		},
+		{
+			name: "empty tenant",
+			shape: &types.Statement{
+				Query:  &types.Query{Model: "Post"},
+				Tenant: strPtr(""),
+			},
+			wantErr: true,
+			errMsg:  "tenant must be non-empty",
+		},
+		{
+			name: "policy",
+			shape: &types.Statement{
+				Query:  &types.Query{Model: "Post"},
+				Policy: &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid policy operator",
+			shape: &types.Statement{
+				Query:  &types.Query{Model: "Post"},
+				Policy: &types.Filter{Conditions: &[]types.Condition{{Field: "orgId", Op: "==", Value: "o1"}}},
+			},
+			wantErr: true,
+			errMsg:  "invalid operator: ==",
+		},
+		{
+			name: "relation count",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "User"},
+				Includes: []types.Include{{
+					Query: &types.Query{Model: "posts"},
+					Kind:  strPtr("count"),
+					Count: &types.RelationCount{Op: "gt", Value: 5},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "count kind without count",
+			shape: &types.Statement{
+				Query:    &types.Query{Model: "User"},
+				Includes: []types.Include{{Query: &types.Query{Model: "posts"}, Kind: strPtr("count")}},
+			},
+			wantErr: true,
+			errMsg:  "kind count requires count",
+		},
+		{
+			name: "count without count kind",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "User"},
+				Includes: []types.Include{{
+					Query: &types.Query{Model: "posts"},
+					Kind:  strPtr("some"),
+					Count: &types.RelationCount{Op: "gt", Value: 5},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "count requires kind count",
+		},
+		{
+			name: "invalid count op",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "User"},
+				Includes: []types.Include{{
+					Query: &types.Query{Model: "posts"},
+					Kind:  strPtr("count"),
+					Count: &types.RelationCount{Op: "between", Value: 5},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "statement.includes[0].count.op",
+		},
+		{
+			name: "negative count",
+			shape: &types.Statement{
+				Query: &types.Query{Model: "User"},
+				Includes: []types.Include{{
+					Query: &types.Query{Model: "posts"},
+					Kind:  strPtr("count"),
+					Count: &types.RelationCount{Op: "lt", Value: -1},
+				}},
+			},
+			wantErr: true,
+			errMsg:  "count value must be non-negative",
+		},
+		{
+			name: "current spec_version",
+			shape: &types.Statement{
+				Query:       &types.Query{Model: "Post"},
+				SpecVersion: strPtr(types.SpecVersion),
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tcs {
@@ -160,7 +585,7 @@ func TestValidateDependencies_Comprehensive(t *testing.T) {
 				Includes: []types.Include{},
 			},
 			wantErr: true,
-			errMsg:  "shapeId must match pattern",
+			errMsg:  "shape_id must match pattern",
 		},
 		{
 			name: "shapeId too short",
@@ -171,12 +596,12 @@ func TestValidateDependencies_Comprehensive(t *testing.T) {
 				Includes: []types.Include{},
 			},
 			wantErr: true,
-			errMsg:  "shapeId must match pattern",
+			errMsg:  "shape_id must match pattern",
 		},
 		{
 			name: "missing records",
 			deps: &types.Dependencies{
-				ShapeID:  "s_" + string(make([]byte, 64)),
+				ShapeID:  "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
 				Filters:  []types.Filter{},
 				Includes: []types.Include{},
 			},
@@ -193,6 +618,113 @@ func TestValidateDependencies_Comprehensive(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "malformed spec_version",
+			deps: &types.Dependencies{
+				SpecVersion: strPtr("v1"),
+				ShapeID:     "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:     map[string][]string{},
+				Filters:     []types.Filter{},
+				Includes:    []types.Include{},
+			},
+			wantErr: true,
+			errMsg:  "must be X.Y.Z",
+		},
+		{
+			name: "spec_version from the future",
+			deps: &types.Dependencies{
+				SpecVersion: strPtr("99.0.0"),
+				ShapeID:     "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:     map[string][]string{},
+				Filters:     []types.Filter{},
+				Includes:    []types.Include{},
+			},
+			wantErr: true,
+			errMsg:  "newer than supported",
+		},
+		{
+			name: "current spec_version",
+			deps: &types.Dependencies{
+				SpecVersion: strPtr(types.SpecVersion),
+				ShapeID:     "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:     map[string][]string{},
+				Filters:     []types.Filter{},
+				Includes:    []types.Include{},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative ttl",
+			deps: &types.Dependencies{
+				ShapeID:  "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:  map[string][]string{},
+				Filters:  []types.Filter{},
+				Includes: []types.Include{},
+				TTL:      intPtr(-1),
+			},
+			wantErr: true,
+			errMsg:  "ttl must be non-negative",
+		},
+		{
+			name: "malformed valid_until",
+			deps: &types.Dependencies{
+				ShapeID:    "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:    map[string][]string{},
+				Filters:    []types.Filter{},
+				Includes:   []types.Include{},
+				ValidUntil: strPtr("tomorrow"),
+			},
+			wantErr: true,
+			errMsg:  "valid_until must be an RFC 3339",
+		},
+		{
+			name: "valid staleness metadata",
+			deps: &types.Dependencies{
+				ShapeID:    "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:    map[string][]string{},
+				Filters:    []types.Filter{},
+				Includes:   []types.Include{},
+				TTL:        intPtr(60),
+				ValidUntil: strPtr("2026-01-02T15:04:05Z"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "empty soft_delete_field",
+			deps: &types.Dependencies{
+				ShapeID:         "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:         map[string][]string{},
+				Filters:         []types.Filter{},
+				Includes:        []types.Include{},
+				SoftDeleteField: strPtr(""),
+			},
+			wantErr: true,
+			errMsg:  "soft_delete_field must be non-empty",
+		},
+		{
+			name: "empty tenant",
+			deps: &types.Dependencies{
+				ShapeID:  "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:  map[string][]string{},
+				Filters:  []types.Filter{},
+				Includes: []types.Include{},
+				Tenant:   strPtr(""),
+			},
+			wantErr: true,
+			errMsg:  "tenant must be non-empty",
+		},
+		{
+			name: "invalid policy operator",
+			deps: &types.Dependencies{
+				ShapeID:  "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+				Records:  map[string][]string{},
+				Filters:  []types.Filter{},
+				Includes: []types.Include{},
+				Policy:   &types.Filter{Conditions: &[]types.Condition{{Field: "orgId", Op: "==", Value: "o1"}}},
+			},
+			wantErr: true,
+			errMsg:  "invalid operator: ==",
+		},
 	}
 
 	for _, tt := range tcs {
@@ -266,6 +798,10 @@ func boolPtr(b bool) *bool {
 	return &b
 }
 
+func strPtr(s string) *string {
+	return &s
+}
+
 func contains(s, substr string) bool {
 	return len(s) >= len(substr) && (s == substr || len(s) > len(substr) && (s[:len(substr)] == substr || s[len(s)-len(substr):] == substr || findInString(s, substr)))
 }
@@ -278,3 +814,238 @@ func findInString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestValidateQueryShapeAll(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{
+			Model: "",
+			Where: &types.Filter{Conditions: &[]types.Condition{
+				{Field: "status", Op: "bogus"},
+				{Field: "", Op: "eq"},
+			}},
+			Limit: intPtr(-1),
+		},
+		GroupBy:    &[]string{""},
+		Pagination: &types.Pagination{First: intPtr(0)},
+		Includes: []types.Include{
+			{Query: &types.Query{Model: "author"}, Kind: strPtr("any")},
+		},
+	}
+
+	errs := tests.ValidateQueryShapeAll(stmt)
+	wantPaths := []string{
+		"statement.query.model",
+		"statement.query.where.conditions[0].op",
+		"statement.query.where.conditions[1].field",
+		"statement.query.limit",
+		"statement.groupBy[0]",
+		"statement.pagination.first",
+		"statement.includes[0].kind",
+	}
+	if len(errs) != len(wantPaths) {
+		t.Fatalf("ValidateQueryShapeAll() = %v, want %d errors", errs, len(wantPaths))
+	}
+	for i, path := range wantPaths {
+		if errs[i].Path != path {
+			t.Errorf("errs[%d].Path = %q, want %q", i, errs[i].Path, path)
+		}
+	}
+
+	// The single-error form reports the first violation
+	err := tests.ValidateQueryShape(stmt)
+	if err == nil || err.Error() != errs[0].Error() {
+		t.Errorf("ValidateQueryShape() = %v, want %v", err, errs[0])
+	}
+}
+
+func TestValidateConditionValues(t *testing.T) {
+	tcs := []struct {
+		op    string
+		value any
+		ok    bool
+	}{
+		{"between", []any{1, 10}, true},
+		{"between", []int{1, 10}, true},
+		{"between", 5, false},
+		{"between", []any{1}, false},
+		{"between", nil, false},
+		{"in", []string{"a", "b"}, true},
+		{"in", []any{}, true},
+		{"in", nil, true},
+		{"in", "a", false},
+		{"notIn", 1, false},
+		{"isNull", true, true},
+		{"isNull", nil, true},
+		{"isNull", "x", false},
+		{"exists", false, true},
+		{"exists", 1, false},
+		{"eq", []any{1, 2}, true},
+		{"jsonHasKey", "lang", true},
+		{"jsonHasKey", 1, false},
+		{"jsonType", "object", true},
+		{"jsonType", "integer", false},
+		{"jsonType", nil, false},
+		{"withinRadius", types.Circle(types.GeoPoint{Lat: 48.85, Lng: 2.35}, 500), true},
+		{"withinRadius", map[string]any{"center": map[string]any{"lat": 0, "lng": 0}, "radius": 1}, true},
+		{"withinRadius", types.Polygon(types.GeoPoint{}, types.GeoPoint{Lat: 1}, types.GeoPoint{Lng: 1}), false},
+		{"withinRadius", types.Circle(types.GeoPoint{Lat: 91}, 500), false},
+		{"withinRadius", types.Circle(types.GeoPoint{}, -1), false},
+		{"withinRadius", types.GeoPoint{}, false},
+		{"intersects", types.Polygon(types.GeoPoint{}, types.GeoPoint{Lat: 1}, types.GeoPoint{Lng: 1}), true},
+		{"intersects", types.Polygon(types.GeoPoint{}, types.GeoPoint{Lat: 1}), false},
+		{"intersects", types.GeoShape{Center: &types.GeoPoint{}, Radius: new(float64), Polygon: []types.GeoPoint{{}, {Lat: 1}, {Lng: 1}}}, false},
+		{"intersects", map[string]any{"center": map[string]any{"lat": 0, "lng": 0}, "radius": 1, "color": "red"}, false},
+		{"bbox", []any{types.GeoPoint{Lat: -1, Lng: 179}, types.GeoPoint{Lat: 1, Lng: -179}}, true},
+		{"bbox", []any{types.GeoPoint{Lat: 1}, types.GeoPoint{Lat: -1}}, false},
+		{"bbox", []any{types.GeoPoint{}}, false},
+	}
+
+	for _, tt := range tcs {
+		stmt := &types.Statement{Query: &types.Query{
+			Model: "posts",
+			Where: &types.Filter{Conditions: &[]types.Condition{{Field: "f", Op: tt.op, Value: tt.value}}},
+		}}
+		err := tests.ValidateQueryShape(stmt)
+		if tt.ok && err != nil {
+			t.Errorf("%s %v: unexpected error %v", tt.op, tt.value, err)
+		}
+		if !tt.ok && (err == nil || !strings.HasSuffix(err.Error(), "at statement.query.where.conditions[0].value")) {
+			t.Errorf("%s %v: error = %v, want a value error", tt.op, tt.value, err)
+		}
+	}
+}
+
+func TestValidateAllStrict(t *testing.T) {
+	stmt := &types.Statement{
+		Query: &types.Query{
+			Model: "Post",
+			Where: &types.Filter{Conditions: &[]types.Condition{
+				{Field: "a", Op: "custom:x"},
+				{Field: "b", Op: "custom:y"},
+			}},
+		},
+	}
+	if errs := tests.ValidateQueryShapeAll(stmt); len(errs) != 0 {
+		t.Errorf("custom operators are valid outside strict mode, got %v", errs)
+	}
+	if errs := tests.ValidateQueryShapeAll(stmt, tests.Strict()); len(errs) != 2 {
+		t.Errorf("strict mode should report both custom operators, got %v", errs)
+	}
+}
+
+func TestValidateMutationEventAll(t *testing.T) {
+	m := &types.Mutation{Changes: []types.Change{
+		{Model: "", Action: "insert"},
+		{Model: "Post", Action: "upsert"},
+	}, Tenant: strPtr("")}
+
+	errs := tests.ValidateMutationEventAll(m)
+	wantPaths := []string{
+		"mutation.tenant",
+		"mutation.changes[0].model",
+		"mutation.changes[0].sets",
+		"mutation.changes[1].action",
+	}
+	if len(errs) != len(wantPaths) {
+		t.Fatalf("ValidateMutationEventAll() = %v, want %d errors", errs, len(wantPaths))
+	}
+	for i, path := range wantPaths {
+		if errs[i].Path != path {
+			t.Errorf("errs[%d].Path = %q, want %q", i, errs[i].Path, path)
+		}
+	}
+}
+
+func TestValidateTransactionAll(t *testing.T) {
+	committedAt := "yesterday"
+	tx := &types.Transaction{
+		Seq:         -1,
+		CommittedAt: &committedAt,
+		Mutations: []types.Mutation{
+			{Changes: []types.Change{{Model: "Post", Action: "delete", Where: &types.Filter{}}}},
+			{Changes: []types.Change{{Model: "", Action: "delete", Where: &types.Filter{}}}},
+		},
+	}
+
+	errs := tests.ValidateTransactionAll(tx)
+	wantPaths := []string{
+		"transaction.seq",
+		"transaction.committed_at",
+		"transaction.mutations[1].changes[0].model",
+	}
+	if len(errs) != len(wantPaths) {
+		t.Fatalf("ValidateTransactionAll() = %v, want %d errors", errs, len(wantPaths))
+	}
+	for i, path := range wantPaths {
+		if errs[i].Path != path {
+			t.Errorf("errs[%d].Path = %q, want %q", i, errs[i].Path, path)
+		}
+	}
+
+	if errs := tests.ValidateTransactionAll(nil); len(errs) != 1 || errs[0].Code != tests.CodeRequired {
+		t.Errorf("nil Transaction should report one required error, got %v", errs)
+	}
+	if errs := tests.ValidateTransactionAll(&types.Transaction{}); len(errs) != 1 || errs[0].Path != "transaction.mutations" {
+		t.Errorf("missing mutations should be reported, got %v", errs)
+	}
+
+	over := &types.Transaction{Mutations: []types.Mutation{
+		{Changes: []types.Change{}},
+		{Changes: make([]types.Change, 2)},
+	}}
+	err := tests.ValidateTransaction(over, tests.WithLimits(limits.Guard{MaxNodes: 1}))
+	var limitErr *tests.LimitError
+	if !errors.As(err, &limitErr) || limitErr.Path != "transaction.mutations[1].changes[1]" {
+		t.Errorf("limit errors should point into the transaction, got %v", err)
+	}
+}
+
+func TestValidateDependenciesAll(t *testing.T) {
+	errs := tests.ValidateDependenciesAll(&types.Dependencies{ShapeID: "bad", TTL: intPtr(-5)})
+	wantPaths := []string{
+		"dependencies.shape_id",
+		"dependencies.records",
+		"dependencies.filters",
+		"dependencies.includes",
+		"dependencies.ttl",
+	}
+	if len(errs) != len(wantPaths) {
+		t.Fatalf("ValidateDependenciesAll() = %v, want %d errors", errs, len(wantPaths))
+	}
+	for i, path := range wantPaths {
+		if errs[i].Path != path {
+			t.Errorf("errs[%d].Path = %q, want %q", i, errs[i].Path, path)
+		}
+	}
+
+	if errs := tests.ValidateDependenciesAll(nil); len(errs) != 1 {
+		t.Errorf("nil Dependencies should report one error, got %v", errs)
+	}
+}
+
+func TestValidationErrorsErr(t *testing.T) {
+	if err := tests.ValidationErrors(nil).Err(); err != nil {
+		t.Errorf("Err() on no errors = %v, want nil", err)
+	}
+
+	err := tests.ValidateMutationEventAll(&types.Mutation{Changes: []types.Change{
+		{Model: "", Action: "delete"},
+	}}).Err()
+	if err == nil {
+		t.Fatal("Err() should be non-nil")
+	}
+	want := "model must be non-empty at mutation.changes[0].model\ndelete requires where clause at mutation.changes[0].where"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+
+	var verr *tests.ValidationError
+	if !errors.As(err, &verr) || verr.Path != "mutation.changes[0].model" {
+		t.Errorf("errors.As should find the first ValidationError, got %v", verr)
+	}
+
+	joined := errors.Join(err, errors.New("other"))
+	if !errors.As(joined, &verr) {
+		t.Error("errors.As should see through errors.Join")
+	}
+}
@@ -8,6 +8,9 @@ package tests
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/bold-minds/includekit-spec/go/types"
 )
@@ -21,8 +24,10 @@ const (
 
 // ValidationError represents a validation failure
 type ValidationError struct {
+	Code    ErrorCode
 	Message string
 	Path    string
+	Err     error // underlying error, such as a *LimitError; may be nil
 }
 
 func (e *ValidationError) Error() string {
@@ -32,143 +37,495 @@ func (e *ValidationError) Error() string {
 	return e.Message
 }
 
+// Unwrap returns the underlying error, if any.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// ValidationErrors lists every constraint violation found in one pass, in
+// document order. As an error it behaves like errors.Join of its entries, so
+// errors.As can pick out an individual *ValidationError.
+type ValidationErrors []ValidationError
+
+func (errs ValidationErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i := range errs {
+		msgs[i] = errs[i].Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap returns the individual errors.
+func (errs ValidationErrors) Unwrap() []error {
+	out := make([]error, len(errs))
+	for i := range errs {
+		out[i] = &errs[i]
+	}
+	return out
+}
+
+// Err returns errs as an error, or nil if there are none.
+func (errs ValidationErrors) Err() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func (errs ValidationErrors) first() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &errs[0]
+}
+
+// collector accumulates violations so validators can walk the whole
+// structure instead of stopping at the first problem.
+type collector struct {
+	errs ValidationErrors
+}
+
+func (c *collector) add(code ErrorCode, message, path string) {
+	c.errs = append(c.errs, ValidationError{Code: code, Message: message, Path: path})
+}
+
+func (c *collector) addErr(err error) {
+	if verr, ok := err.(*ValidationError); ok {
+		c.errs = append(c.errs, *verr)
+		return
+	}
+	c.add("", err.Error(), "")
+}
+
 // ValidateQueryShape validates a Statement structure.
 //
 // It checks that:
+//   - SpecVersion, when declared, is X.Y.Z and no newer than
+//     types.SpecVersion, so fields of a future version are not silently
+//     misread
 //   - Query is present with non-empty model
-//   - All filters, orderBy specs, pagination are valid
+//   - Tenant, when set, is non-empty
+//   - All filters, orderBy specs, pagination are valid, and operators that
+//     need a particular value shape (between, in, notIn, isNull, exists,
+//     jsonHasKey, jsonType) get it, and geospatial operators (withinRadius,
+//     intersects, bbox) get well-formed GeoPoints and GeoShapes
+//   - Custom operators declared with RegisterOperator get the values they
+//     declare
 //   - Limit and offset are non-negative
 //   - Distinct and groupBy fields are non-empty strings
+//   - Distinct_on, never set with distinct, has distinct non-empty fields
+//     and an order_by starting with one entry for each of them
+//   - Deleted, when set, is include or only
+//   - Aggregations use a known func, have a field unless counting rows, and
+//     produce distinct result columns
+//   - Joins name a relation at most once, with kind inner or left and valid
+//     on conditions
+//   - Windows use a known func, with a field for sum, avg, min and max, and
+//     order_by but no field or frame for the ranking functions; frames do
+//     not start after they end, and each window's alias is a column no
+//     aggregation or other window produces
+//   - inQuery and existsQuery conditions carry a valid subquery selecting
+//     exactly one field, and no other condition carries one
+//   - Having conditions do not put a field_path on an aggregate result
+//     column
+//   - The policy, if present, is a valid filter
+//   - Includes of kind count, and only those, carry a count with a known
+//     op and a non-negative value
 //   - Nested includes are valid
 //
-// Returns a ValidationError if any constraint is violated.
-func ValidateQueryShape(stmt *types.Statement) error {
+// With the Strict option it also rejects anything outside the pure spec
+// subset (see Strict). Documents over their limits (limits.Default unless
+// WithLimits is given) fail with a ValidationError wrapping a *LimitError,
+// and are not validated further.
+//
+// Returns a ValidationError for the first constraint violated.
+func ValidateQueryShape(stmt *types.Statement, opts ...ValidateOption) error {
+	return ValidateQueryShapeAll(stmt, opts...).first()
+}
+
+// ValidateQueryShapeAll is like ValidateQueryShape but keeps going after a
+// violation, returning all of them.
+func ValidateQueryShapeAll(stmt *types.Statement, opts ...ValidateOption) ValidationErrors {
+	o := newValidateOptions(opts)
+	c := &collector{}
+	if err := o.guard.CheckStatement(stmt); err != nil {
+		c.addErr(limitExceeded(err))
+		return c.errs
+	}
+	validateQueryShape(stmt, c)
+	if stmt != nil && o.strict {
+		checkStrictStatement(stmt, c)
+	}
+	return c.errs
+}
+
+func validateQueryShape(stmt *types.Statement, c *collector) {
 	if stmt == nil {
-		return &ValidationError{Message: "Statement cannot be nil", Path: "statement"}
+		c.add(CodeRequired, "Statement cannot be nil", "statement")
+		return
+	}
+	if stmt.SpecVersion != nil {
+		validateSpecVersion(*stmt.SpecVersion, "statement.spec_version", c)
+	}
+	if tenant, ok := stmt.GetTenant(); ok && tenant == "" {
+		c.add(CodeEmptyField, "tenant must be non-empty", "statement.tenant")
 	}
 
 	// Validate query
 	if stmt.Query != nil {
-		if err := validateQuery(stmt.Query, "statement.query"); err != nil {
-			return err
-		}
+		validateQuery(stmt.Query, "statement.query", c)
 	}
 
 	// Validate groupBy fields
 	if stmt.GroupBy != nil {
 		for i, field := range *stmt.GroupBy {
 			if field == "" {
-				return &ValidationError{
-					Message: "groupBy field must be non-empty",
-					Path:    fmt.Sprintf("statement.groupBy[%d]", i),
-				}
+				c.add(CodeEmptyField, "groupBy field must be non-empty", fmt.Sprintf("statement.groupBy[%d]", i))
 			}
 		}
 	}
 
 	// Validate having clause
 	if stmt.Having != nil {
-		if err := validateFilterSpec(stmt.Having, "statement.having"); err != nil {
-			return err
+		validateFilterSpec(stmt.Having, "statement.having", c)
+		if stmt.Query != nil {
+			columns := map[string]bool{}
+			for _, agg := range stmt.Query.GetAggregations() {
+				columns[agg.Column()] = true
+			}
+			validateHavingFieldPaths(stmt.Having, columns, "statement.having", c)
 		}
 	}
 
+	// Validate policy
+	if stmt.Policy != nil {
+		validateFilterSpec(stmt.Policy, "statement.policy", c)
+	}
+
 	// Validate pagination
 	if stmt.Pagination != nil {
-		if err := validatePagination(stmt.Pagination, "statement.pagination"); err != nil {
-			return err
-		}
+		validatePagination(stmt.Pagination, "statement.pagination", c)
 	}
 
 	// Validate includes
 	if stmt.Includes != nil {
 		for i, include := range stmt.Includes {
-			if err := validateInclude(&include, fmt.Sprintf("statement.includes[%d]", i)); err != nil {
-				return err
-			}
+			validateInclude(&include, fmt.Sprintf("statement.includes[%d]", i), c)
 		}
 	}
+}
 
-	return nil
+// validateHavingFieldPaths rejects field_path on having conditions that
+// name an aggregate result column: aggregates are numbers, not JSON.
+func validateHavingFieldPaths(f *types.Filter, columns map[string]bool, path string, c *collector) {
+	for i := range f.GetAnd() {
+		validateHavingFieldPaths(&(*f.And)[i], columns, fmt.Sprintf("%s.and[%d]", path, i), c)
+	}
+	for i := range f.GetOr() {
+		validateHavingFieldPaths(&(*f.Or)[i], columns, fmt.Sprintf("%s.or[%d]", path, i), c)
+	}
+	if f.Not != nil {
+		validateHavingFieldPaths(f.Not, columns, path+".not", c)
+	}
+	for i, cond := range f.GetConditions() {
+		if len(cond.FieldPath) > 0 && columns[cond.Field] {
+			c.add(CodeInvalidFieldPath, fmt.Sprintf("field_path cannot descend into aggregate column %s", cond.Field), fmt.Sprintf("%s.conditions[%d].field_path", path, i))
+		}
+	}
 }
 
-func validateQuery(q *types.Query, path string) error {
+func validateQuery(q *types.Query, path string, c *collector) {
 	if q.Model == "" {
-		return &ValidationError{Message: "model must be a non-empty string", Path: fmt.Sprintf("%s.model", path)}
+		c.add(CodeEmptyModel, "model must be a non-empty string", fmt.Sprintf("%s.model", path))
 	}
 
 	// Validate where clause
 	if q.Where != nil {
-		if err := validateFilterSpec(q.Where, fmt.Sprintf("%s.where", path)); err != nil {
-			return err
-		}
+		validateFilterSpec(q.Where, fmt.Sprintf("%s.where", path), c)
 	}
 
 	// Validate orderBy
 	if q.OrderBy != nil {
 		for i, ob := range *q.OrderBy {
-			if err := validateOrderBy(&ob, fmt.Sprintf("%s.orderBy[%d]", path, i)); err != nil {
-				return err
-			}
+			validateOrderBy(&ob, fmt.Sprintf("%s.orderBy[%d]", path, i), c)
 		}
 	}
 
 	// Validate limit (must be non-negative)
 	if q.Limit != nil && *q.Limit < 0 {
-		return &ValidationError{Message: "limit must be non-negative", Path: fmt.Sprintf("%s.limit", path)}
+		c.add(CodeOutOfRange, "limit must be non-negative", fmt.Sprintf("%s.limit", path))
 	}
 
 	// Validate offset (must be non-negative)
 	if q.Offset != nil && *q.Offset < 0 {
-		return &ValidationError{Message: "offset must be non-negative", Path: fmt.Sprintf("%s.offset", path)}
+		c.add(CodeOutOfRange, "offset must be non-negative", fmt.Sprintf("%s.offset", path))
 	}
 
 	// Validate distinct fields
 	if q.Distinct != nil {
 		for i, field := range *q.Distinct {
 			if field == "" {
-				return &ValidationError{
-					Message: "distinct field must be non-empty",
-					Path:    fmt.Sprintf("%s.distinct[%d]", path, i),
-				}
+				c.add(CodeEmptyField, "distinct field must be non-empty", fmt.Sprintf("%s.distinct[%d]", path, i))
+			}
+		}
+	}
+	if q.DistinctOn != nil {
+		if q.Distinct != nil {
+			c.add(CodeInvalidDistinct, "distinct_on cannot be combined with distinct", fmt.Sprintf("%s.distinct_on", path))
+		}
+		validateDistinctOn(q.DistinctOn, fmt.Sprintf("%s.distinct_on", path), c)
+	}
+	if deleted, ok := q.GetDeleted(); ok && !specAllows("Query.deleted", deleted) {
+		c.add(CodeInvalidDeleted, fmt.Sprintf("deleted must be %s, got: %s", specValues("Query.deleted"), deleted), fmt.Sprintf("%s.deleted", path))
+	}
+
+	// Validate aggregations
+	columns := map[string]bool{}
+	if q.Aggregations != nil {
+		for i, agg := range *q.Aggregations {
+			aggPath := fmt.Sprintf("%s.aggregations[%d]", path, i)
+			validateAggregate(&agg, aggPath, c)
+			column := agg.Column()
+			if columns[column] {
+				c.add(CodeInvalidAggregate, fmt.Sprintf("duplicate aggregate column: %s", column), aggPath)
+			}
+			columns[column] = true
+		}
+	}
+
+	// Validate joins
+	if q.Joins != nil {
+		relations := map[string]bool{}
+		for i, join := range *q.Joins {
+			joinPath := fmt.Sprintf("%s.joins[%d]", path, i)
+			validateJoin(&join, joinPath, c)
+			if relations[join.Relation] && join.Relation != "" {
+				c.add(CodeInvalidJoin, fmt.Sprintf("duplicate join of relation: %s", join.Relation), joinPath)
 			}
+			relations[join.Relation] = true
 		}
 	}
 
-	return nil
+	// Validate windows, whose columns sit beside the aggregate ones
+	for i, window := range q.GetWindows() {
+		windowPath := fmt.Sprintf("%s.windows[%d]", path, i)
+		validateWindow(&window, windowPath, c)
+		if columns[window.Alias] && window.Alias != "" {
+			c.add(CodeInvalidWindow, fmt.Sprintf("duplicate window column: %s", window.Alias), windowPath)
+		}
+		columns[window.Alias] = true
+	}
+}
+
+func validateDistinctOn(d *types.DistinctOn, path string, c *collector) {
+	if len(d.Fields) == 0 {
+		c.add(CodeRequired, "distinct_on requires fields", fmt.Sprintf("%s.fields", path))
+	}
+	fields := map[string]bool{}
+	for i, field := range d.Fields {
+		fieldPath := fmt.Sprintf("%s.fields[%d]", path, i)
+		switch {
+		case field == "":
+			c.add(CodeEmptyField, "distinct_on field must be non-empty", fieldPath)
+		case fields[field]:
+			c.add(CodeInvalidDistinct, fmt.Sprintf("duplicate distinct_on field: %s", field), fieldPath)
+		}
+		fields[field] = true
+	}
+	for i, ob := range d.OrderBy {
+		validateOrderBy(&ob, fmt.Sprintf("%s.order_by[%d]", path, i), c)
+	}
+
+	// The order_by must sort by the fields first, in any order, so that it
+	// only ranks the rows sharing them
+	prefix := len(d.Fields) <= len(d.OrderBy)
+	for i := 0; prefix && i < len(d.Fields); i++ {
+		prefix = fields[d.OrderBy[i].Field] && !slices.ContainsFunc(d.OrderBy[:i], func(ob types.OrderBy) bool {
+			return ob.Field == d.OrderBy[i].Field
+		})
+	}
+	if !prefix {
+		c.add(CodeInvalidDistinct, "distinct_on order_by must start with its fields", fmt.Sprintf("%s.order_by", path))
+	}
+}
+
+func validateAggregate(agg *types.Aggregate, path string, c *collector) {
+	validFunc := specAllows("Aggregate.func", agg.Func)
+	if !validFunc {
+		c.add(CodeInvalidAggregate, fmt.Sprintf("func must be %s, got: %s", specValues("Aggregate.func"), agg.Func), fmt.Sprintf("%s.func", path))
+	}
+	if agg.Field == nil && agg.Func != "count" && validFunc {
+		c.add(CodeInvalidAggregate, fmt.Sprintf("%s requires a field", agg.Func), fmt.Sprintf("%s.field", path))
+	}
+	if agg.Field != nil && *agg.Field == "" {
+		c.add(CodeEmptyField, "field must be non-empty", fmt.Sprintf("%s.field", path))
+	}
+	if agg.Alias != nil && *agg.Alias == "" {
+		c.add(CodeEmptyField, "alias must be non-empty", fmt.Sprintf("%s.alias", path))
+	}
+}
+
+// rankingFuncs are the window functions that number rows rather than
+// aggregate a field.
+var rankingFuncs = map[string]bool{"row_number": true, "rank": true, "dense_rank": true}
+
+func validateWindow(w *types.Window, path string, c *collector) {
+	validFunc := specAllows("Window.func", w.Func)
+	if !validFunc {
+		c.add(CodeInvalidWindow, fmt.Sprintf("func must be %s, got: %s", specValues("Window.func"), w.Func), fmt.Sprintf("%s.func", path))
+	}
+	switch {
+	case !validFunc:
+	case rankingFuncs[w.Func] && w.Field != nil:
+		c.add(CodeInvalidWindow, fmt.Sprintf("%s takes no field", w.Func), fmt.Sprintf("%s.field", path))
+	case rankingFuncs[w.Func] && len(w.GetOrderBy()) == 0:
+		c.add(CodeInvalidWindow, fmt.Sprintf("%s requires order_by", w.Func), fmt.Sprintf("%s.order_by", path))
+	case rankingFuncs[w.Func] && w.Frame != nil:
+		c.add(CodeInvalidWindow, fmt.Sprintf("%s takes no frame", w.Func), fmt.Sprintf("%s.frame", path))
+	case w.Field == nil && w.Func != "count" && !rankingFuncs[w.Func]:
+		c.add(CodeInvalidWindow, fmt.Sprintf("%s requires a field", w.Func), fmt.Sprintf("%s.field", path))
+	}
+	if w.Field != nil && *w.Field == "" {
+		c.add(CodeEmptyField, "field must be non-empty", fmt.Sprintf("%s.field", path))
+	}
+	if w.Alias == "" {
+		c.add(CodeEmptyField, "alias must be non-empty", fmt.Sprintf("%s.alias", path))
+	}
+	for i, field := range w.GetPartitionBy() {
+		if field == "" {
+			c.add(CodeEmptyField, "partition_by field must be non-empty", fmt.Sprintf("%s.partition_by[%d]", path, i))
+		}
+	}
+	for i, ob := range w.GetOrderBy() {
+		validateOrderBy(&ob, fmt.Sprintf("%s.order_by[%d]", path, i), c)
+	}
+
+	if f := w.Frame; f != nil {
+		framePath := fmt.Sprintf("%s.frame", path)
+		if !specAllows("WindowFrame.unit", f.Unit) {
+			c.add(CodeInvalidWindow, fmt.Sprintf("frame unit must be %s, got: %s", specValues("WindowFrame.unit"), f.Unit), framePath+".unit")
+		}
+		if f.Start != nil && f.End != nil && *f.Start > *f.End {
+			c.add(CodeOutOfRange, "frame start must not follow its end", framePath)
+		}
+		// A range offset is a difference of the sort key, so there must be
+		// exactly one
+		offset := f.Start != nil && *f.Start != 0 || f.End != nil && *f.End != 0
+		if f.Unit == "range" && offset && len(w.GetOrderBy()) != 1 {
+			c.add(CodeInvalidWindow, "range frame offsets require exactly one order_by field", framePath)
+		}
+	}
+}
+
+func validateJoin(join *types.Join, path string, c *collector) {
+	if join.Relation == "" {
+		c.add(CodeInvalidJoin, "relation must be a non-empty string", fmt.Sprintf("%s.relation", path))
+	}
+	if !specAllows("Join.kind", join.Kind) {
+		c.add(CodeInvalidJoin, fmt.Sprintf("kind must be %s, got: %s", specValues("Join.kind"), join.Kind), fmt.Sprintf("%s.kind", path))
+	}
+	if join.On != nil {
+		validateFilterSpec(join.On, fmt.Sprintf("%s.on", path), c)
+	}
+	for i, field := range join.GetFields() {
+		if field == "" {
+			c.add(CodeEmptyField, "join field must be non-empty", fmt.Sprintf("%s.fields[%d]", path, i))
+		}
+	}
 }
 
-// ValidateMutationEvent validates a Mutation
-func ValidateMutationEvent(event *types.Mutation) error {
+// ValidateMutationEvent validates a Mutation, rejecting anything outside the
+// pure spec subset with the Strict option.
+func ValidateMutationEvent(event *types.Mutation, opts ...ValidateOption) error {
+	return ValidateMutationEventAll(event, opts...).first()
+}
+
+// ValidateMutationEventAll is like ValidateMutationEvent but returns every
+// violation.
+func ValidateMutationEventAll(event *types.Mutation, opts ...ValidateOption) ValidationErrors {
+	o := newValidateOptions(opts)
+	c := &collector{}
+	if err := o.guard.CheckMutation(event); err != nil {
+		c.addErr(limitExceeded(err))
+		return c.errs
+	}
+	validateMutationEvent(event, c)
+	if event != nil && o.strict {
+		checkStrictMutation(event, c)
+	}
+	return c.errs
+}
+
+func validateMutationEvent(event *types.Mutation, c *collector) {
 	if event == nil {
-		return &ValidationError{Message: "Mutation cannot be nil", Path: "mutation"}
+		c.add(CodeRequired, "Mutation cannot be nil", "mutation")
+		return
 	}
 	if event.Changes == nil {
-		return &ValidationError{Message: "changes must be an array", Path: "mutation.changes"}
+		c.add(CodeRequired, "changes must be an array", "mutation.changes")
+	}
+	if tenant, ok := event.GetTenant(); ok && tenant == "" {
+		c.add(CodeEmptyField, "tenant must be non-empty", "mutation.tenant")
 	}
 
 	for i, change := range event.Changes {
-		if err := validateDataChange(&change, fmt.Sprintf("mutation.changes[%d]", i)); err != nil {
-			return err
+		validateDataChange(&change, fmt.Sprintf("mutation.changes[%d]", i), c)
+	}
+}
+
+// ValidateTransaction validates a Transaction: seq must be non-negative,
+// committed_at an RFC 3339 date-time, and every mutation valid as
+// ValidateMutationEvent sees it, strict checks included. Limits count the
+// changes of all mutations together.
+func ValidateTransaction(tx *types.Transaction, opts ...ValidateOption) error {
+	return ValidateTransactionAll(tx, opts...).first()
+}
+
+// ValidateTransactionAll is like ValidateTransaction but returns every
+// violation.
+func ValidateTransactionAll(tx *types.Transaction, opts ...ValidateOption) ValidationErrors {
+	c := &collector{}
+	if tx == nil {
+		c.add(CodeRequired, "Transaction cannot be nil", "transaction")
+		return c.errs
+	}
+	if err := newValidateOptions(opts).guard.CheckTransaction(tx); err != nil {
+		c.addErr(limitExceeded(err))
+		return c.errs
+	}
+	if tx.Seq < 0 {
+		c.add(CodeOutOfRange, "seq must be non-negative", "transaction.seq")
+	}
+	if tx.CommittedAt != nil {
+		if _, err := time.Parse(time.RFC3339, *tx.CommittedAt); err != nil {
+			c.add(CodeInvalidDateTime, "committed_at must be an RFC 3339 date-time", "transaction.committed_at")
 		}
 	}
+	if tx.Mutations == nil {
+		c.add(CodeRequired, "mutations must be an array", "transaction.mutations")
+	}
 
-	return nil
+	// Validate each mutation on its own, then fix the path prefix so errors
+	// point into the transaction.
+	for i := range tx.Mutations {
+		prefix := fmt.Sprintf("transaction.mutations[%d]", i)
+		for _, err := range ValidateMutationEventAll(&tx.Mutations[i], opts...) {
+			err.Path = prefix + strings.TrimPrefix(err.Path, "mutation")
+			c.errs = append(c.errs, err)
+		}
+	}
+	return c.errs
 }
 
-func validateDataChange(change *types.Change, path string) error {
+func validateDataChange(change *types.Change, path string, c *collector) {
 	// Validate model
 	if change.Model == "" {
-		return &ValidationError{Message: "model must be non-empty", Path: fmt.Sprintf("%s.model", path)}
+		c.add(CodeEmptyModel, "model must be non-empty", fmt.Sprintf("%s.model", path))
 	}
 
 	// Validate action
-	validActions := map[string]bool{"insert": true, "update": true, "delete": true}
-	if !validActions[change.Action] {
-		return &ValidationError{
-			Message: fmt.Sprintf("action must be 'insert', 'update', or 'delete', got: %s", change.Action),
-			Path:    fmt.Sprintf("%s.action", path),
-		}
+	if !specAllows("Change.action", change.Action) {
+		c.add(CodeInvalidAction, fmt.Sprintf("action must be %s, got: %s", specValues("Change.action"), change.Action), fmt.Sprintf("%s.action", path))
 	}
 
 	// Validate based on action type
@@ -176,224 +533,343 @@ func validateDataChange(change *types.Change, path string) error {
 	case "insert":
 		// Insert requires Set, no Where
 		if len(change.Sets) == 0 {
-			return &ValidationError{
-				Message: "insert requires non-empty set",
-				Path:    fmt.Sprintf("%s.set", path),
-			}
+			c.add(CodeMissingSet, "insert requires non-empty set", fmt.Sprintf("%s.sets", path))
 		}
 		if change.Where != nil {
-			return &ValidationError{
-				Message: "insert cannot have where clause",
-				Path:    fmt.Sprintf("%s.where", path),
-			}
+			c.add(CodeUnexpectedWhere, "insert cannot have where clause", fmt.Sprintf("%s.where", path))
 		}
 
 	case "update":
 		// Update requires both Set and Where
 		if len(change.Sets) == 0 {
-			return &ValidationError{
-				Message: "update requires non-empty set",
-				Path:    fmt.Sprintf("%s.set", path),
-			}
+			c.add(CodeMissingSet, "update requires non-empty set", fmt.Sprintf("%s.sets", path))
 		}
 		if change.Where == nil {
-			return &ValidationError{
-				Message: "update requires where clause",
-				Path:    fmt.Sprintf("%s.where", path),
-			}
+			c.add(CodeMissingWhere, "update requires where clause", fmt.Sprintf("%s.where", path))
 		}
 
 	case "delete":
 		// Delete requires Where, no Set
 		if len(change.Sets) > 0 {
-			return &ValidationError{
-				Message: "delete cannot have set clause",
-				Path:    fmt.Sprintf("%s.set", path),
-			}
+			c.add(CodeUnexpectedSet, "delete cannot have set clause", fmt.Sprintf("%s.sets", path))
 		}
 		if change.Where == nil {
-			return &ValidationError{
-				Message: "delete requires where clause",
-				Path:    fmt.Sprintf("%s.where", path),
-			}
+			c.add(CodeMissingWhere, "delete requires where clause", fmt.Sprintf("%s.where", path))
 		}
 	}
 
 	// Validate Set clauses
 	for j, setClause := range change.Sets {
 		if setClause.Field == "" {
-			return &ValidationError{
-				Message: "set clause field must be non-empty",
-				Path:    fmt.Sprintf("%s.set[%d].field", path, j),
-			}
+			c.add(CodeEmptyField, "set clause field must be non-empty", fmt.Sprintf("%s.sets[%d].field", path, j))
 		}
 	}
 
 	// Validate Where clause if present
 	if change.Where != nil {
-		if err := validateFilterSpec(change.Where, fmt.Sprintf("%s.where", path)); err != nil {
-			return err
-		}
+		validateFilterSpec(change.Where, fmt.Sprintf("%s.where", path), c)
 	}
-
-	return nil
 }
 
 // ValidateDependencies validates a Dependencies structure.
 //
-// It checks that the shapeId follows the correct format (s_ + 64 hex chars)
-// and that all required fields are present and valid.
-func ValidateDependencies(deps *types.Dependencies) error {
+// It checks that the spec version, if present, is no newer than
+// types.SpecVersion, that the shapeId follows the correct format (s_ + 64 hex
+// chars) and that all required fields are present and valid. Use the upgrade
+// package to bring older documents up to date. With the Strict option it also
+// rejects anything outside the pure spec subset.
+func ValidateDependencies(deps *types.Dependencies, opts ...ValidateOption) error {
+	return ValidateDependenciesAll(deps, opts...).first()
+}
+
+// ValidateDependenciesAll is like ValidateDependencies but returns every
+// violation.
+func ValidateDependenciesAll(deps *types.Dependencies, opts ...ValidateOption) ValidationErrors {
+	o := newValidateOptions(opts)
+	c := &collector{}
+	if err := o.guard.CheckDependencies(deps); err != nil {
+		c.addErr(limitExceeded(err))
+		return c.errs
+	}
+	validateDependencies(deps, c)
+	if deps != nil && o.strict {
+		checkStrictDependencies(deps, c)
+	}
+	return c.errs
+}
+
+func validateDependencies(deps *types.Dependencies, c *collector) {
 	if deps == nil {
-		return &ValidationError{Message: "Dependencies cannot be nil", Path: "dependencies"}
+		c.add(CodeRequired, "Dependencies cannot be nil", "dependencies")
+		return
 	}
-	if deps.ShapeID == "" || len(deps.ShapeID) != ShapeIDLength || deps.ShapeID[:len(ShapeIDPrefix)] != ShapeIDPrefix {
-		return &ValidationError{
-			Message: fmt.Sprintf("shapeId must match pattern ^%s[0-9a-f]{%d}$", ShapeIDPrefix, ShapeIDHexLength),
-			Path:    "dependencies.shapeId",
-		}
+	if deps.SpecVersion != nil {
+		validateSpecVersion(*deps.SpecVersion, "dependencies.spec_version", c)
+	}
+	if !validShapeID(deps.ShapeID) {
+		c.add(CodeInvalidShapeID, fmt.Sprintf("shape_id must match pattern ^%s[0-9a-f]{%d}$", ShapeIDPrefix, ShapeIDHexLength), "dependencies.shape_id")
 	}
 	if deps.Records == nil {
-		return &ValidationError{Message: "records must be an object", Path: "dependencies.records"}
+		c.add(CodeRequired, "records must be an object", "dependencies.records")
 	}
 	if deps.Filters == nil {
-		return &ValidationError{Message: "filterBounds must be an array", Path: "dependencies.filterBounds"}
+		c.add(CodeRequired, "filters must be an array", "dependencies.filters")
 	}
 	if deps.Includes == nil {
-		return &ValidationError{Message: "relationBounds must be an array", Path: "dependencies.relationBounds"}
+		c.add(CodeRequired, "includes must be an array", "dependencies.includes")
+	}
+	if deps.TTL != nil && *deps.TTL < 0 {
+		c.add(CodeOutOfRange, "ttl must be non-negative", "dependencies.ttl")
+	}
+	if deps.ValidUntil != nil {
+		if _, err := time.Parse(time.RFC3339, *deps.ValidUntil); err != nil {
+			c.add(CodeInvalidDateTime, "valid_until must be an RFC 3339 date-time", "dependencies.valid_until")
+		}
+	}
+	if field, ok := deps.GetSoftDeleteField(); ok && field == "" {
+		c.add(CodeEmptyField, "soft_delete_field must be non-empty", "dependencies.soft_delete_field")
+	}
+	if tenant, ok := deps.GetTenant(); ok && tenant == "" {
+		c.add(CodeEmptyField, "tenant must be non-empty", "dependencies.tenant")
+	}
+	if deps.Policy != nil {
+		validateFilterSpec(deps.Policy, "dependencies.policy", c)
+	}
+}
+
+// validateSpecVersion checks a declared spec version is X.Y.Z and no newer
+// than the version these validators implement.
+func validateSpecVersion(version, path string, c *collector) {
+	cmp, err := types.CompareSpecVersions(version, types.SpecVersion)
+	if err != nil {
+		c.add(CodeInvalidSpecVersion, err.Error(), path)
+	} else if cmp > 0 {
+		c.add(CodeInvalidSpecVersion, fmt.Sprintf("spec_version %s is newer than supported %s", version, types.SpecVersion), path)
 	}
+}
+
+// specAllows reports whether the schema allows value for an enum or pattern
+// property, named "<Definition>.<property>" as in constraints.go.
+func specAllows(key, value string) bool {
+	if slices.Contains(specEnums[key], value) {
+		return true
+	}
+	pattern := specPatterns[key]
+	return pattern != nil && pattern.MatchString(value)
+}
+
+// specValues lists the values of an enum property for messages, as
+// "'a' or 'b'" or "'a', 'b', or 'c'".
+func specValues(key string) string {
+	values := specEnums[key]
+	quoted := make([]string, len(values))
+	for i, v := range values {
+		quoted[i] = "'" + v + "'"
+	}
+	if len(quoted) <= 2 {
+		return strings.Join(quoted, " or ")
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+}
 
-	return nil
+// validShapeID reports whether id matches ^s_[0-9a-f]{64}$.
+func validShapeID(id string) bool {
+	_, _, err := ParseShapeID(id)
+	return err == nil
 }
 
-func validateFilterSpec(spec *types.Filter, path string) error {
+func validateFilterSpec(spec *types.Filter, path string, c *collector) {
 	if spec == nil {
-		return nil
+		return
 	}
 
 	if spec.And != nil {
 		for i, s := range *spec.And {
-			if err := validateFilterSpec(&s, fmt.Sprintf("%s.and[%d]", path, i)); err != nil {
-				return err
-			}
+			validateFilterSpec(&s, fmt.Sprintf("%s.and[%d]", path, i), c)
 		}
 	}
 	if spec.Or != nil {
 		for i, s := range *spec.Or {
-			if err := validateFilterSpec(&s, fmt.Sprintf("%s.or[%d]", path, i)); err != nil {
-				return err
-			}
+			validateFilterSpec(&s, fmt.Sprintf("%s.or[%d]", path, i), c)
 		}
 	}
 	if spec.Not != nil {
-		if err := validateFilterSpec(spec.Not, fmt.Sprintf("%s.not", path)); err != nil {
-			return err
-		}
+		validateFilterSpec(spec.Not, fmt.Sprintf("%s.not", path), c)
 	}
 	if spec.Conditions != nil {
 		for i, a := range *spec.Conditions {
-			if err := validateFilterAtom(&a, fmt.Sprintf("%s.atoms[%d]", path, i)); err != nil {
-				return err
-			}
+			validateFilterAtom(&a, fmt.Sprintf("%s.conditions[%d]", path, i), c)
 		}
 	}
-
-	return nil
 }
 
-func validateFilterAtom(atom *types.Condition, path string) error {
+func validateFilterAtom(atom *types.Condition, path string, c *collector) {
 	if atom.Field == "" {
-		return &ValidationError{Message: "field must be a non-empty string", Path: fmt.Sprintf("%s.field", path)}
+		c.add(CodeEmptyField, "field must be a non-empty string", fmt.Sprintf("%s.field", path))
 	}
 	if atom.Op == "" {
-		return &ValidationError{Message: "op must be a non-empty string", Path: fmt.Sprintf("%s.op", path)}
+		c.add(CodeInvalidOperator, "op must be a non-empty string", fmt.Sprintf("%s.op", path))
 	}
 
-	validOps := map[string]bool{
-		"eq": true, "ne": true, "in": true, "notIn": true, "isNull": true,
-		"gt": true, "gte": true, "lt": true, "lte": true, "between": true,
-		"contains": true, "startsWith": true, "endsWith": true,
-		"like": true, "ilike": true, "regex": true,
-		"has": true, "hasSome": true, "hasEvery": true, "jsonContains": true,
-		"lenEq": true, "lenGt": true, "lenLt": true, "exists": true,
+	// Built-in operators are the enum of Condition.op; custom ones match its
+	// pattern
+	if !specAllows("Condition.op", atom.Op) {
+		c.add(CodeInvalidOperator, fmt.Sprintf("invalid operator: %s", atom.Op), fmt.Sprintf("%s.op", path))
+	} else if !slices.Contains(specEnums["Condition.op"], atom.Op) {
+		validateCustomOperator(atom, path, c)
 	}
 
-	isCustomOp := len(atom.Op) >= 7 && atom.Op[:7] == "custom:"
-	if !validOps[atom.Op] && !isCustomOp {
-		return &ValidationError{Message: fmt.Sprintf("invalid operator: %s", atom.Op), Path: fmt.Sprintf("%s.op", path)}
+	for i, segment := range atom.FieldPath {
+		if segment == "" {
+			c.add(CodeEmptyField, "field_path segment must be non-empty", fmt.Sprintf("%s.field_path[%d]", path, i))
+		}
 	}
 
-	return nil
+	validateConditionValue(atom, path, c)
+	validateSubquery(atom, path, c)
 }
 
-func validateOrderBy(ob *types.OrderBy, path string) error {
+// validateSubquery checks the subquery of an inQuery or existsQuery
+// condition, which must select exactly one column, and that no other
+// operator carries one.
+func validateSubquery(atom *types.Condition, path string, c *collector) {
+	if atom.Op != "inQuery" && atom.Op != "existsQuery" {
+		if atom.Subquery != nil {
+			c.add(CodeInvalidSubquery, fmt.Sprintf("%s does not take a subquery", atom.Op), fmt.Sprintf("%s.subquery", path))
+		}
+		return
+	}
+	if atom.Value != nil {
+		c.add(CodeInvalidValue, fmt.Sprintf("%s takes a subquery, not a value", atom.Op), fmt.Sprintf("%s.value", path))
+	}
+	sub := atom.Subquery
+	if sub == nil {
+		c.add(CodeRequired, fmt.Sprintf("%s requires a subquery", atom.Op), fmt.Sprintf("%s.subquery", path))
+		return
+	}
+	validateQuery(sub, fmt.Sprintf("%s.subquery", path), c)
+
+	columns := len(sub.GetFields()) + len(sub.GetAggregations()) + len(sub.GetWindows())
+	for _, join := range sub.GetJoins() {
+		columns += len(join.GetFields())
+	}
+	if len(sub.GetFields()) != 1 || columns != 1 {
+		c.add(CodeInvalidSubquery, "subquery must select exactly one field", fmt.Sprintf("%s.subquery.fields", path))
+	}
+}
+
+// validateConditionValue checks the value shape of operators that need one:
+// between takes a [lo, hi] array, in and notIn an array (or null while the
+// values are unbound, as in Split parts), isNull and exists a boolean or
+// null, jsonHasKey a string key, jsonType one of jsonTypes, and the geospatial
+// operators the values validateGeoValue expects.
+func validateConditionValue(atom *types.Condition, path string, c *collector) {
+	switch atom.Op {
+	case "between", "in", "notIn", "isNull", "exists", "jsonHasKey", "jsonType", "withinRadius", "intersects", "bbox":
+	default:
+		return
+	}
+	value, err := types.Value(atom.Value)
+	if err != nil {
+		c.add(CodeInvalidValue, err.Error(), fmt.Sprintf("%s.value", path))
+		return
+	}
+
+	switch atom.Op {
+	case "between":
+		if list, ok := value.([]any); !ok || len(list) != 2 {
+			c.add(CodeInvalidValue, "between requires a [lo, hi] array value", fmt.Sprintf("%s.value", path))
+		}
+	case "in", "notIn":
+		if _, ok := value.([]any); !ok && value != nil {
+			c.add(CodeInvalidValue, fmt.Sprintf("%s requires an array value", atom.Op), fmt.Sprintf("%s.value", path))
+		}
+	case "jsonHasKey":
+		if _, ok := value.(string); !ok {
+			c.add(CodeInvalidValue, "jsonHasKey requires a string value", fmt.Sprintf("%s.value", path))
+		}
+	case "jsonType":
+		if s, ok := value.(string); !ok || !slices.Contains(jsonTypes, s) {
+			c.add(CodeInvalidValue, fmt.Sprintf("jsonType requires one of %s", strings.Join(jsonTypes, ", ")), fmt.Sprintf("%s.value", path))
+		}
+	case "withinRadius", "intersects", "bbox":
+		value, err = jsonValue(value)
+		if err == nil {
+			err = validateGeoValue(atom.Op, value)
+		}
+		if err != nil {
+			c.add(CodeInvalidValue, fmt.Sprintf("%s: %v", atom.Op, err), fmt.Sprintf("%s.value", path))
+		}
+	default:
+		if _, ok := value.(bool); !ok && value != nil {
+			c.add(CodeInvalidValue, fmt.Sprintf("%s requires a boolean or null value", atom.Op), fmt.Sprintf("%s.value", path))
+		}
+	}
+}
+
+func validateOrderBy(ob *types.OrderBy, path string, c *collector) {
 	if ob.Field == "" {
-		return &ValidationError{Message: "field must be a non-empty string", Path: fmt.Sprintf("%s.field", path)}
+		c.add(CodeEmptyField, "field must be a non-empty string", fmt.Sprintf("%s.field", path))
 	}
 	// Descending, NullsFirst and CaseSensitive are bools - no validation needed
-	return nil
 }
 
-func validatePagination(p *types.Pagination, path string) error {
+func validatePagination(p *types.Pagination, path string, c *collector) {
 	// Can't mix forward and backward pagination
 	hasForward := p.First != nil || p.After != nil
 	hasBackward := p.Last != nil || p.Before != nil
 
 	if hasForward && hasBackward {
-		return &ValidationError{
-			Message: "cannot mix forward pagination (first/after) with backward pagination (last/before)",
-			Path:    path,
-		}
+		c.add(CodeMixedPagination, "cannot mix forward pagination (first/after) with backward pagination (last/before)", path)
 	}
 
 	// Validate First (must be positive)
 	if p.First != nil && *p.First <= 0 {
-		return &ValidationError{
-			Message: "first must be a positive integer",
-			Path:    fmt.Sprintf("%s.first", path),
-		}
+		c.add(CodeOutOfRange, "first must be a positive integer", fmt.Sprintf("%s.first", path))
 	}
 
 	// Validate Last (must be positive)
 	if p.Last != nil && *p.Last <= 0 {
-		return &ValidationError{
-			Message: "last must be a positive integer",
-			Path:    fmt.Sprintf("%s.last", path),
-		}
+		c.add(CodeOutOfRange, "last must be a positive integer", fmt.Sprintf("%s.last", path))
 	}
 
 	// After/Before are opaque strings, no validation needed
 	// (SDKs encode them as base64 JSON)
-
-	return nil
 }
 
-func validateInclude(include *types.Include, path string) error {
+func validateInclude(include *types.Include, path string, c *collector) {
 	// Validate query if present
 	if include.Query != nil {
-		if err := validateQuery(include.Query, fmt.Sprintf("%s.query", path)); err != nil {
-			return err
-		}
+		validateQuery(include.Query, fmt.Sprintf("%s.query", path), c)
 	}
 
 	// Validate kind if present
 	if include.Kind != nil {
-		validKinds := map[string]bool{"some": true, "every": true, "none": true}
-		if !validKinds[*include.Kind] {
-			return &ValidationError{
-				Message: "kind must be 'some', 'every', or 'none'",
-				Path:    fmt.Sprintf("%s.kind", path),
-			}
+		if !specAllows("Include.kind", *include.Kind) {
+			c.add(CodeInvalidKind, fmt.Sprintf("kind must be %s", specValues("Include.kind")), fmt.Sprintf("%s.kind", path))
+		}
+	}
+
+	// A count goes with kind count, and only with it
+	isCount := include.Kind != nil && *include.Kind == "count"
+	switch {
+	case isCount && include.Count == nil:
+		c.add(CodeRequired, "kind count requires count", fmt.Sprintf("%s.count", path))
+	case !isCount && include.Count != nil:
+		c.add(CodeInvalidKind, "count requires kind count", fmt.Sprintf("%s.kind", path))
+	case include.Count != nil:
+		if !specAllows("RelationCount.op", include.Count.Op) {
+			c.add(CodeInvalidOperator, fmt.Sprintf("count op must be %s", specValues("RelationCount.op")), fmt.Sprintf("%s.count.op", path))
+		}
+		if include.Count.Value < 0 {
+			c.add(CodeOutOfRange, "count value must be non-negative", fmt.Sprintf("%s.count.value", path))
 		}
 	}
 
 	// Recursively validate nested includes
 	if include.Includes != nil {
 		for i, nested := range include.Includes {
-			if err := validateInclude(&nested, fmt.Sprintf("%s.includes[%d]", path, i)); err != nil {
-				return err
-			}
+			validateInclude(&nested, fmt.Sprintf("%s.includes[%d]", path, i), c)
 		}
 	}
-	return nil
 }
@@ -0,0 +1,409 @@
+package types
+
+// Nil-safe accessors for optional fields. Every accessor may be called on a
+// nil receiver; optional values report whether they were set.
+
+// GetQuery returns the statement query, or nil.
+func (s *Statement) GetQuery() *Query {
+	if s == nil {
+		return nil
+	}
+	return s.Query
+}
+
+// GetOrderBy returns the ordering of the statement query, or nil.
+func (s *Statement) GetOrderBy() []OrderBy {
+	return s.GetQuery().GetOrderBy()
+}
+
+// GetGroupBy returns the group-by fields, or nil.
+func (s *Statement) GetGroupBy() []string {
+	if s == nil || s.GroupBy == nil {
+		return nil
+	}
+	return *s.GroupBy
+}
+
+// GetPagination returns the pagination, or nil.
+func (s *Statement) GetPagination() *Pagination {
+	if s == nil {
+		return nil
+	}
+	return s.Pagination
+}
+
+// GetSpecVersion returns the spec version the statement was emitted with,
+// defaulting to 0.1.0 for statements that do not declare one.
+func (s *Statement) GetSpecVersion() string {
+	if s == nil || s.SpecVersion == nil {
+		return "0.1.0"
+	}
+	return *s.SpecVersion
+}
+
+// GetORMVersion returns the diagnostic ORM version and whether it is set.
+func (s *Statement) GetORMVersion() (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return deref(s.ORMVersion)
+}
+
+// GetSDKVersion returns the diagnostic SDK version and whether it is set.
+func (s *Statement) GetSDKVersion() (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return deref(s.SDKVersion)
+}
+
+// GetTenant returns the tenant the statement reads for and whether it is
+// set.
+func (s *Statement) GetTenant() (string, bool) {
+	if s == nil {
+		return "", false
+	}
+	return deref(s.Tenant)
+}
+
+// GetPolicy returns the row-level visibility predicate, or nil.
+func (s *Statement) GetPolicy() *Filter {
+	if s == nil {
+		return nil
+	}
+	return s.Policy
+}
+
+// GetModel returns the query model, or "".
+func (q *Query) GetModel() string {
+	if q == nil {
+		return ""
+	}
+	return q.Model
+}
+
+// GetFields returns the selected fields, or nil.
+func (q *Query) GetFields() []string {
+	if q == nil || q.Fields == nil {
+		return nil
+	}
+	return *q.Fields
+}
+
+// GetWhere returns the where filter, or nil.
+func (q *Query) GetWhere() *Filter {
+	if q == nil {
+		return nil
+	}
+	return q.Where
+}
+
+// GetOrderBy returns the ordering, or nil.
+func (q *Query) GetOrderBy() []OrderBy {
+	if q == nil || q.OrderBy == nil {
+		return nil
+	}
+	return *q.OrderBy
+}
+
+// GetLimit returns the limit and whether it is set.
+func (q *Query) GetLimit() (int, bool) {
+	if q == nil {
+		return 0, false
+	}
+	return deref(q.Limit)
+}
+
+// GetOffset returns the offset and whether it is set.
+func (q *Query) GetOffset() (int, bool) {
+	if q == nil {
+		return 0, false
+	}
+	return deref(q.Offset)
+}
+
+// GetAggregations returns the aggregate projections, or nil.
+func (q *Query) GetAggregations() []Aggregate {
+	if q == nil || q.Aggregations == nil {
+		return nil
+	}
+	return *q.Aggregations
+}
+
+// GetJoins returns the joins, or nil.
+func (q *Query) GetJoins() []Join {
+	if q == nil || q.Joins == nil {
+		return nil
+	}
+	return *q.Joins
+}
+
+// GetWindows returns the window function columns, or nil.
+func (q *Query) GetWindows() []Window {
+	if q == nil || q.Windows == nil {
+		return nil
+	}
+	return *q.Windows
+}
+
+// GetDistinct returns the distinct fields, or nil.
+func (q *Query) GetDistinct() []string {
+	if q == nil || q.Distinct == nil {
+		return nil
+	}
+	return *q.Distinct
+}
+
+// GetDeleted returns which soft-deleted rows to read ("include" or "only")
+// and whether it is set; unset reads live rows only.
+func (q *Query) GetDeleted() (string, bool) {
+	if q == nil {
+		return "", false
+	}
+	return deref(q.Deleted)
+}
+
+// GetKind returns the relation filter kind and whether it is set.
+func (i *Include) GetKind() (string, bool) {
+	if i == nil {
+		return "", false
+	}
+	return deref(i.Kind)
+}
+
+// IsRelationFilter reports whether the include filters its parent (Kind set).
+func (i *Include) IsRelationFilter() bool {
+	return i != nil && i.Kind != nil
+}
+
+// GetAnd returns the AND sub-filters, or nil.
+func (f *Filter) GetAnd() []Filter {
+	if f == nil || f.And == nil {
+		return nil
+	}
+	return *f.And
+}
+
+// GetOr returns the OR sub-filters, or nil.
+func (f *Filter) GetOr() []Filter {
+	if f == nil || f.Or == nil {
+		return nil
+	}
+	return *f.Or
+}
+
+// GetNot returns the negated sub-filter, or nil.
+func (f *Filter) GetNot() *Filter {
+	if f == nil {
+		return nil
+	}
+	return f.Not
+}
+
+// GetConditions returns the leaf conditions, or nil.
+func (f *Filter) GetConditions() []Condition {
+	if f == nil || f.Conditions == nil {
+		return nil
+	}
+	return *f.Conditions
+}
+
+// IsDescending reports whether the ordering is descending. Unset means ascending.
+func (o *OrderBy) IsDescending() bool {
+	return o != nil && o.Descending != nil && *o.Descending
+}
+
+// GetNullsFirst returns the nulls-first setting and whether it is set.
+func (o *OrderBy) GetNullsFirst() (bool, bool) {
+	if o == nil {
+		return false, false
+	}
+	return deref(o.NullsFirst)
+}
+
+// GetCaseSensitive returns the case-sensitivity setting and whether it is set.
+func (o *OrderBy) GetCaseSensitive() (bool, bool) {
+	if o == nil {
+		return false, false
+	}
+	return deref(o.CaseSensitive)
+}
+
+// GetField returns the aggregated field and whether it is set.
+func (a *Aggregate) GetField() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return deref(a.Field)
+}
+
+// GetAlias returns the result column alias and whether it is set.
+func (a *Aggregate) GetAlias() (string, bool) {
+	if a == nil {
+		return "", false
+	}
+	return deref(a.Alias)
+}
+
+// GetField returns the aggregated field and whether it is set.
+func (w *Window) GetField() (string, bool) {
+	if w == nil {
+		return "", false
+	}
+	return deref(w.Field)
+}
+
+// GetPartitionBy returns the partition fields, or nil.
+func (w *Window) GetPartitionBy() []string {
+	if w == nil || w.PartitionBy == nil {
+		return nil
+	}
+	return *w.PartitionBy
+}
+
+// GetOrderBy returns the order within partitions, or nil.
+func (w *Window) GetOrderBy() []OrderBy {
+	if w == nil || w.OrderBy == nil {
+		return nil
+	}
+	return *w.OrderBy
+}
+
+// GetOn returns the join conditions, or nil.
+func (j *Join) GetOn() *Filter {
+	if j == nil {
+		return nil
+	}
+	return j.On
+}
+
+// GetFields returns the projected fields of the joined model, or nil.
+func (j *Join) GetFields() []string {
+	if j == nil || j.Fields == nil {
+		return nil
+	}
+	return *j.Fields
+}
+
+// GetFirst returns the forward page size and whether it is set.
+func (p *Pagination) GetFirst() (int, bool) {
+	if p == nil {
+		return 0, false
+	}
+	return deref(p.First)
+}
+
+// GetLast returns the backward page size and whether it is set.
+func (p *Pagination) GetLast() (int, bool) {
+	if p == nil {
+		return 0, false
+	}
+	return deref(p.Last)
+}
+
+// GetAfter returns the forward cursor and whether it is set.
+func (p *Pagination) GetAfter() (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	return deref(p.After)
+}
+
+// GetBefore returns the backward cursor and whether it is set.
+func (p *Pagination) GetBefore() (string, bool) {
+	if p == nil {
+		return "", false
+	}
+	return deref(p.Before)
+}
+
+// GetTxID returns the transaction ID and whether it is set.
+func (m *Mutation) GetTxID() (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	return deref(m.TxID)
+}
+
+// GetTenant returns the tenant whose rows the mutation writes and whether it
+// is set.
+func (m *Mutation) GetTenant() (string, bool) {
+	if m == nil {
+		return "", false
+	}
+	return deref(m.Tenant)
+}
+
+// GetCommittedAt returns the commit instant and whether it is set.
+func (t *Transaction) GetCommittedAt() (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	return deref(t.CommittedAt)
+}
+
+// GetWhere returns the change filter, or nil.
+func (c *Change) GetWhere() *Filter {
+	if c == nil {
+		return nil
+	}
+	return c.Where
+}
+
+// GetSpecVersion returns the document spec version, defaulting to 0.1.0 for
+// documents written before versioning.
+func (d *Dependencies) GetSpecVersion() string {
+	if d == nil || d.SpecVersion == nil {
+		return "0.1.0"
+	}
+	return *d.SpecVersion
+}
+
+// GetTTL returns the staleness TTL in seconds and whether it is set.
+func (d *Dependencies) GetTTL() (int, bool) {
+	if d == nil {
+		return 0, false
+	}
+	return deref(d.TTL)
+}
+
+// GetValidUntil returns the RFC 3339 expiry instant and whether it is set.
+func (d *Dependencies) GetValidUntil() (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	return deref(d.ValidUntil)
+}
+
+// GetSoftDeleteField returns the root model's soft-delete field and whether
+// it is set.
+func (d *Dependencies) GetSoftDeleteField() (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	return deref(d.SoftDeleteField)
+}
+
+// GetTenant returns the tenant of the shape and whether it is set.
+func (d *Dependencies) GetTenant() (string, bool) {
+	if d == nil {
+		return "", false
+	}
+	return deref(d.Tenant)
+}
+
+// GetPolicy returns the policy of the shape, or nil.
+func (d *Dependencies) GetPolicy() *Filter {
+	if d == nil {
+		return nil
+	}
+	return d.Policy
+}
+
+func deref[T any](p *T) (T, bool) {
+	if p == nil {
+		var zero T
+		return zero, false
+	}
+	return *p, true
+}
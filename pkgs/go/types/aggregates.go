@@ -0,0 +1,46 @@
+package types
+
+// Aggregate constructors build Aggregates for the built-in functions. Use As
+// to name the result column.
+
+// Count counts rows; an empty field counts all rows (COUNT(*)).
+func Count(field string) Aggregate {
+	if field == "" {
+		return Aggregate{Func: "count"}
+	}
+	return agg("count", field)
+}
+
+// Sum totals field.
+func Sum(field string) Aggregate { return agg("sum", field) }
+
+// Avg averages field.
+func Avg(field string) Aggregate { return agg("avg", field) }
+
+// Min takes the smallest value of field.
+func Min(field string) Aggregate { return agg("min", field) }
+
+// Max takes the largest value of field.
+func Max(field string) Aggregate { return agg("max", field) }
+
+// As returns a copy of the aggregate with the result column named alias.
+func (a Aggregate) As(alias string) Aggregate {
+	a.Alias = &alias
+	return a
+}
+
+// Column returns the result column name: the alias if set, otherwise "<func>"
+// for COUNT(*) and "<func>_<field>" for everything else.
+func (a *Aggregate) Column() string {
+	if a.Alias != nil {
+		return *a.Alias
+	}
+	if a.Field == nil {
+		return a.Func
+	}
+	return a.Func + "_" + *a.Field
+}
+
+func agg(fn, field string) Aggregate {
+	return Aggregate{Func: fn, Field: &field}
+}
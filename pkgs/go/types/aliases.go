@@ -0,0 +1,14 @@
+package types
+
+// Names the 0.1.0 documentation used for the top-level documents. They are
+// aliases, so values convert freely and the testkit accepts either.
+
+// QueryShape is the former name of Statement.
+//
+// Deprecated: Use Statement.
+type QueryShape = Statement
+
+// MutationEvent is the former name of Mutation.
+//
+// Deprecated: Use Mutation.
+type MutationEvent = Mutation
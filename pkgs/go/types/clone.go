@@ -0,0 +1,382 @@
+package types
+
+import "reflect"
+
+// Clone methods return deep copies that share no pointers, slices or maps
+// with the original, so a shape can be modified without affecting callers
+// that hold the same value. They are nil-safe: cloning a nil pointer returns
+// nil, and nil and empty slices keep their distinction, since omitempty
+// treats them alike but a pointer-to-slice does not.
+//
+// Condition and KV values are copied deeply when they are slices or maps
+// (such as the []any of an in condition); other values, including pointers
+// and structs like time.Time, are copied as they are.
+
+// Clone returns a deep copy of the statement.
+func (s *Statement) Clone() *Statement {
+	if s == nil {
+		return nil
+	}
+	return &Statement{
+		Query:       s.Query.Clone(),
+		Pagination:  s.Pagination.Clone(),
+		GroupBy:     clonePtrSlice(s.GroupBy),
+		Having:      s.Having.Clone(),
+		Includes:    cloneEach(s.Includes, (*Include).Clone),
+		ORMVersion:  clonePtr(s.ORMVersion),
+		SDKVersion:  clonePtr(s.SDKVersion),
+		SpecVersion: clonePtr(s.SpecVersion),
+		Tenant:      clonePtr(s.Tenant),
+		Policy:      s.Policy.Clone(),
+	}
+}
+
+// Clone returns a deep copy of the query.
+func (q *Query) Clone() *Query {
+	if q == nil {
+		return nil
+	}
+	return &Query{
+		Model:        q.Model,
+		Fields:       clonePtrSlice(q.Fields),
+		Where:        q.Where.Clone(),
+		OrderBy:      clonePtrEach(q.OrderBy, (*OrderBy).Clone),
+		Limit:        clonePtr(q.Limit),
+		Offset:       clonePtr(q.Offset),
+		Distinct:     clonePtrSlice(q.Distinct),
+		Aggregations: clonePtrEach(q.Aggregations, (*Aggregate).Clone),
+		Joins:        clonePtrEach(q.Joins, (*Join).Clone),
+		Windows:      clonePtrEach(q.Windows, (*Window).Clone),
+		DistinctOn:   q.DistinctOn.Clone(),
+		Deleted:      clonePtr(q.Deleted),
+	}
+}
+
+// Clone returns a deep copy of the aggregate.
+func (a *Aggregate) Clone() *Aggregate {
+	if a == nil {
+		return nil
+	}
+	return &Aggregate{Func: a.Func, Field: clonePtr(a.Field), Alias: clonePtr(a.Alias)}
+}
+
+// Clone returns a deep copy of the join.
+func (j *Join) Clone() *Join {
+	if j == nil {
+		return nil
+	}
+	return &Join{Relation: j.Relation, Kind: j.Kind, On: j.On.Clone(), Fields: clonePtrSlice(j.Fields)}
+}
+
+// Clone returns a deep copy of the window.
+func (w *Window) Clone() *Window {
+	if w == nil {
+		return nil
+	}
+	return &Window{
+		Func:        w.Func,
+		Field:       clonePtr(w.Field),
+		PartitionBy: clonePtrSlice(w.PartitionBy),
+		OrderBy:     clonePtrEach(w.OrderBy, (*OrderBy).Clone),
+		Frame:       w.Frame.Clone(),
+		Alias:       w.Alias,
+	}
+}
+
+// Clone returns a deep copy of the window frame.
+func (f *WindowFrame) Clone() *WindowFrame {
+	if f == nil {
+		return nil
+	}
+	return &WindowFrame{Unit: f.Unit, Start: clonePtr(f.Start), End: clonePtr(f.End)}
+}
+
+// Clone returns a deep copy of the distinct-on clause.
+func (d *DistinctOn) Clone() *DistinctOn {
+	if d == nil {
+		return nil
+	}
+	return &DistinctOn{Fields: cloneSlice(d.Fields), OrderBy: cloneEach(d.OrderBy, (*OrderBy).Clone)}
+}
+
+// Clone returns a deep copy of the include and its nested includes.
+func (i *Include) Clone() *Include {
+	if i == nil {
+		return nil
+	}
+	return &Include{
+		Query:    i.Query.Clone(),
+		Kind:     clonePtr(i.Kind),
+		Includes: cloneEach(i.Includes, (*Include).Clone),
+		Count:    clonePtr(i.Count),
+	}
+}
+
+// Clone returns a deep copy of the filter.
+func (f *Filter) Clone() *Filter {
+	if f == nil {
+		return nil
+	}
+	return &Filter{
+		And:        clonePtrEach(f.And, (*Filter).Clone),
+		Or:         clonePtrEach(f.Or, (*Filter).Clone),
+		Not:        f.Not.Clone(),
+		Conditions: clonePtrEach(f.Conditions, (*Condition).Clone),
+	}
+}
+
+// Clone returns a deep copy of the condition.
+func (c *Condition) Clone() *Condition {
+	if c == nil {
+		return nil
+	}
+	return &Condition{
+		Field:     c.Field,
+		FieldPath: cloneSlice(c.FieldPath),
+		Op:        c.Op,
+		Value:     cloneValue(c.Value),
+		Subquery:  c.Subquery.Clone(),
+	}
+}
+
+// Clone returns a deep copy of the order spec.
+func (o *OrderBy) Clone() *OrderBy {
+	if o == nil {
+		return nil
+	}
+	return &OrderBy{
+		Field:         o.Field,
+		Descending:    clonePtr(o.Descending),
+		NullsFirst:    clonePtr(o.NullsFirst),
+		CaseSensitive: clonePtr(o.CaseSensitive),
+	}
+}
+
+// Clone returns a deep copy of the pagination.
+func (p *Pagination) Clone() *Pagination {
+	if p == nil {
+		return nil
+	}
+	return &Pagination{
+		First:  clonePtr(p.First),
+		Last:   clonePtr(p.Last),
+		After:  clonePtr(p.After),
+		Before: clonePtr(p.Before),
+	}
+}
+
+// Clone returns a deep copy of the mutation.
+func (m *Mutation) Clone() *Mutation {
+	if m == nil {
+		return nil
+	}
+	return &Mutation{TxID: clonePtr(m.TxID), Changes: cloneEach(m.Changes, (*Change).Clone), Tenant: clonePtr(m.Tenant)}
+}
+
+// Clone returns a deep copy of the change.
+func (c *Change) Clone() *Change {
+	if c == nil {
+		return nil
+	}
+	return &Change{
+		Model:  c.Model,
+		Action: c.Action,
+		Sets:   cloneEach(c.Sets, (*KV).Clone),
+		Where:  c.Where.Clone(),
+	}
+}
+
+// Clone returns a deep copy of the transaction.
+func (t *Transaction) Clone() *Transaction {
+	if t == nil {
+		return nil
+	}
+	return &Transaction{
+		Seq:         t.Seq,
+		CommittedAt: clonePtr(t.CommittedAt),
+		Mutations:   cloneEach(t.Mutations, (*Mutation).Clone),
+	}
+}
+
+// Clone returns a deep copy of the dependencies.
+func (d *Dependencies) Clone() *Dependencies {
+	if d == nil {
+		return nil
+	}
+	var records map[string][]string
+	if d.Records != nil {
+		records = make(map[string][]string, len(d.Records))
+		for model, ids := range d.Records {
+			records[model] = cloneSlice(ids)
+		}
+	}
+	return &Dependencies{
+		SpecVersion:     clonePtr(d.SpecVersion),
+		ShapeID:         d.ShapeID,
+		Records:         records,
+		Filters:         cloneEach(d.Filters, (*Filter).Clone),
+		Includes:        cloneEach(d.Includes, (*Include).Clone),
+		LastRow:         d.LastRow.Clone(),
+		GroupBy:         d.GroupBy.Clone(),
+		TTL:             clonePtr(d.TTL),
+		ValidUntil:      clonePtr(d.ValidUntil),
+		WindowFields:    cloneSlice(d.WindowFields),
+		SoftDeleteField: clonePtr(d.SoftDeleteField),
+		Tenant:          clonePtr(d.Tenant),
+		Policy:          d.Policy.Clone(),
+	}
+}
+
+// Clone returns a deep copy of the pagination boundary.
+func (b *PaginationBoundary) Clone() *PaginationBoundary {
+	if b == nil {
+		return nil
+	}
+	return &PaginationBoundary{
+		OrderBy: cloneEach(b.OrderBy, (*OrderBy).Clone),
+		Row:     cloneRow(b.Row),
+		Cursor:  b.Cursor.Clone(),
+	}
+}
+
+// Clone returns a deep copy of the group-by dimensions.
+func (g *GroupByKV) Clone() *GroupByKV {
+	if g == nil {
+		return nil
+	}
+	var values []map[string]any
+	if g.Values != nil {
+		values = make([]map[string]any, len(g.Values))
+		for i, row := range g.Values {
+			values[i] = cloneRow(row)
+		}
+	}
+	return &GroupByKV{Keys: cloneSlice(g.Keys), Values: values}
+}
+
+// Clone returns a deep copy of the field-value pair.
+func (kv *KV) Clone() *KV {
+	if kv == nil {
+		return nil
+	}
+	return &KV{Field: kv.Field, Value: cloneValue(kv.Value)}
+}
+
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+func cloneSlice[T any](s []T) []T {
+	if s == nil {
+		return nil
+	}
+	return append(make([]T, 0, len(s)), s...)
+}
+
+func clonePtrSlice[T any](p *[]T) *[]T {
+	if p == nil {
+		return nil
+	}
+	s := cloneSlice(*p)
+	return &s
+}
+
+// cloneEach deep-copies every element of s with clone.
+func cloneEach[T any](s []T, clone func(*T) *T) []T {
+	if s == nil {
+		return nil
+	}
+	out := make([]T, len(s))
+	for i := range s {
+		out[i] = *clone(&s[i])
+	}
+	return out
+}
+
+func clonePtrEach[T any](p *[]T, clone func(*T) *T) *[]T {
+	if p == nil {
+		return nil
+	}
+	s := cloneEach(*p, clone)
+	return &s
+}
+
+func cloneRow(row map[string]any) map[string]any {
+	if row == nil {
+		return nil
+	}
+	out := make(map[string]any, len(row))
+	for k, v := range row {
+		out[k] = cloneValue(v)
+	}
+	return out
+}
+
+// cloneValue deep-copies slices and maps, element by element; other values
+// are returned as they are.
+func cloneValue(v any) any {
+	switch val := v.(type) {
+	case nil, string, bool, int, int64, float64:
+		return val
+	case []any:
+		if val == nil {
+			return val
+		}
+		out := make([]any, len(val))
+		for i, elem := range val {
+			out[i] = cloneValue(elem)
+		}
+		return out
+	case map[string]any:
+		return cloneRow(val)
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Slice:
+		if rv.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(rv.Type(), rv.Len(), rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			out.Index(i).Set(cloneReflect(rv.Index(i)))
+		}
+		return out.Interface()
+	case reflect.Array:
+		out := reflect.New(rv.Type()).Elem()
+		for i := 0; i < rv.Len(); i++ {
+			out.Index(i).Set(cloneReflect(rv.Index(i)))
+		}
+		return out.Interface()
+	case reflect.Map:
+		if rv.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(rv.Type(), rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), cloneReflect(iter.Value()))
+		}
+		return out.Interface()
+	}
+	return v
+}
+
+// cloneReflect clones an element of a slice, array or map, keeping its type.
+func cloneReflect(elem reflect.Value) reflect.Value {
+	if !elem.CanInterface() {
+		return elem
+	}
+	if elem.Kind() == reflect.Interface && elem.IsNil() {
+		return elem
+	}
+	cloned := reflect.ValueOf(cloneValue(elem.Interface()))
+	if !cloned.IsValid() {
+		return reflect.Zero(elem.Type())
+	}
+	return cloned
+}
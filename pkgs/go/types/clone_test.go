@@ -0,0 +1,241 @@
+package types_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func fullStatement() *types.Statement {
+	return &types.Statement{
+		Query: &types.Query{
+			Model:  "posts",
+			Fields: &[]string{"id", "title"},
+			Where: &types.Filter{
+				And: &[]types.Filter{{Conditions: &[]types.Condition{types.In("status", "draft", "published")}}},
+				Or:  &[]types.Filter{{Conditions: &[]types.Condition{types.Between("views", 1, 10)}}},
+				Not: &types.Filter{Conditions: &[]types.Condition{{
+					Field:     "meta",
+					FieldPath: []string{"tags"},
+					Op:        "jsonContains",
+					Value:     map[string]any{"tags": []any{"go"}},
+				}}},
+				Conditions: &[]types.Condition{
+					types.Eq("authorId", "u1"),
+					types.InQuery("categoryId", types.Query{Model: "categories", Fields: &[]string{"id"}, Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("visible", true)}}}),
+				},
+			},
+			OrderBy:      &[]types.OrderBy{{Field: "createdAt", Descending: ptr(true), NullsFirst: ptr(false), CaseSensitive: ptr(true)}},
+			Limit:        ptr(10),
+			Offset:       ptr(5),
+			Distinct:     &[]string{"authorId"},
+			Aggregations: &[]types.Aggregate{types.Count("").As("n"), types.Sum("views")},
+			Joins:        &[]types.Join{types.InnerJoin("author", types.Eq("active", true)).Select("name")},
+			Windows: &[]types.Window{types.WindowOf(types.Sum("views"), "running").
+				PartitionedBy("authorId").
+				OrderedBy(types.OrderBy{Field: "createdAt"}).
+				Within(types.WindowFrame{Unit: "rows", Start: ptr(-6), End: ptr(0)})},
+			DistinctOn: &types.DistinctOn{Fields: []string{"authorId"}, OrderBy: []types.OrderBy{{Field: "authorId"}, {Field: "views", Descending: ptr(true)}}},
+			Deleted:    ptr("include"),
+		},
+		Pagination: &types.Pagination{First: ptr(10), After: ptr("c1")},
+		GroupBy:    &[]string{"authorId"},
+		Having:     &types.Filter{Conditions: &[]types.Condition{types.Gt("n", 1)}},
+		Includes: []types.Include{{
+			Query:    &types.Query{Model: "comments", Where: &types.Filter{Conditions: &[]types.Condition{types.IsNull("deletedAt")}}},
+			Includes: []types.Include{{Query: &types.Query{Model: "author"}, Kind: ptr("some")}},
+		}},
+		ORMVersion: ptr("prisma@5"),
+		SDKVersion: ptr("1.0.0"),
+		Tenant:     ptr("acme"),
+		Policy:     &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+	}
+}
+
+func fullDependencies() *types.Dependencies {
+	return &types.Dependencies{
+		SpecVersion: ptr("0.1.0"),
+		ShapeID:     "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+		Records:     map[string][]string{"posts": {"p1", "p2"}},
+		Filters:     []types.Filter{{Conditions: &[]types.Condition{types.Eq("published", true)}}},
+		Includes:    []types.Include{{Query: &types.Query{Model: "tags"}, Kind: ptr("some")}},
+		LastRow: &types.PaginationBoundary{
+			OrderBy: []types.OrderBy{{Field: "createdAt", Descending: ptr(true)}},
+			Row:     map[string]any{"createdAt": "2024-01-01", "tags": []any{"a"}},
+			Cursor:  &types.KV{Field: "id", Value: "p2"},
+		},
+		GroupBy:         &types.GroupByKV{Keys: []string{"authorId"}, Values: []map[string]any{{"authorId": "u1"}}},
+		TTL:             ptr(60),
+		ValidUntil:      ptr("2030-01-01T00:00:00Z"),
+		WindowFields:    []string{"authorId", "createdAt"},
+		SoftDeleteField: ptr("deletedAt"),
+		Tenant:          ptr("acme"),
+		Policy:          &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+	}
+}
+
+func fullMutation() *types.Mutation {
+	return &types.Mutation{
+		TxID: ptr("tx1"),
+		Changes: []types.Change{{
+			Model:  "posts",
+			Action: "update",
+			Sets:   []types.KV{{Field: "tags", Value: []string{"a", "b"}}, {Field: "meta", Value: map[string]any{"k": []any{1}}}},
+			Where:  &types.Filter{Conditions: &[]types.Condition{types.In("id", "p1", "p2")}},
+		}},
+		Tenant: ptr("acme"),
+	}
+}
+
+func mustJSON(t *testing.T, v any) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestStatementClone(t *testing.T) {
+	orig := fullStatement()
+	before := mustJSON(t, orig)
+	cp := orig.Clone()
+	if !reflect.DeepEqual(orig, cp) {
+		t.Fatalf("Clone() = %s, want %s", mustJSON(t, cp), before)
+	}
+
+	// Mutate every level of the copy through its pointers, slices and maps.
+	cp.Query.Model = "x"
+	(*cp.Query.Fields)[0] = "x"
+	(*cp.Query.Where.And)[0].Conditions = nil
+	(*(*cp.Query.Where.Or)[0].Conditions)[0].Value.([]any)[0] = 99
+	(*cp.Query.Where.Not.Conditions)[0].FieldPath[0] = "x"
+	(*cp.Query.Where.Not.Conditions)[0].Value.(map[string]any)["tags"].([]any)[0] = "x"
+	(*cp.Query.Where.Conditions)[0].Op = "ne"
+	(*(*cp.Query.Where.Conditions)[1].Subquery.Fields)[0] = "x"
+	(*(*cp.Query.Where.Conditions)[1].Subquery.Where.Conditions)[0].Value = false
+	*(*cp.Query.OrderBy)[0].Descending = false
+	*cp.Query.Limit = 99
+	*cp.Query.Offset = 99
+	(*cp.Query.Distinct)[0] = "x"
+	*(*cp.Query.Aggregations)[0].Alias = "x"
+	(*(*cp.Query.Joins)[0].On.Conditions)[0].Value = false
+	(*(*cp.Query.Joins)[0].Fields)[0] = "x"
+	*(*cp.Query.Windows)[0].Field = "x"
+	(*(*cp.Query.Windows)[0].PartitionBy)[0] = "x"
+	(*(*cp.Query.Windows)[0].OrderBy)[0].Field = "x"
+	*(*cp.Query.Windows)[0].Frame.Start = 99
+	cp.Query.DistinctOn.Fields[0] = "x"
+	*cp.Query.DistinctOn.OrderBy[1].Descending = false
+	*cp.Query.Deleted = "only"
+	*cp.Pagination.First = 99
+	*cp.Pagination.After = "x"
+	(*cp.GroupBy)[0] = "x"
+	(*cp.Having.Conditions)[0].Field = "x"
+	cp.Includes[0].Query.Where.Conditions = nil
+	*cp.Includes[0].Includes[0].Kind = "none"
+	*cp.ORMVersion = "x"
+	*cp.SDKVersion = "x"
+	*cp.Tenant = "x"
+	(*cp.Policy.Conditions)[0].Value = "x"
+
+	if after := mustJSON(t, orig); after != before {
+		t.Errorf("mutating the clone changed the original:\n  got:  %s\n  want: %s", after, before)
+	}
+}
+
+func TestDependenciesClone(t *testing.T) {
+	orig := fullDependencies()
+	before := mustJSON(t, orig)
+	cp := orig.Clone()
+	if !reflect.DeepEqual(orig, cp) {
+		t.Fatalf("Clone() = %s, want %s", mustJSON(t, cp), before)
+	}
+
+	*cp.SpecVersion = "x"
+	cp.Records["posts"][0] = "x"
+	cp.Records["users"] = []string{"u1"}
+	(*cp.Filters[0].Conditions)[0].Field = "x"
+	*cp.Includes[0].Kind = "none"
+	*cp.LastRow.OrderBy[0].Descending = false
+	cp.LastRow.Row["tags"].([]any)[0] = "x"
+	cp.LastRow.Cursor.Value = "x"
+	cp.GroupBy.Keys[0] = "x"
+	cp.GroupBy.Values[0]["authorId"] = "x"
+	*cp.TTL = 0
+	*cp.ValidUntil = "x"
+	cp.WindowFields[0] = "x"
+	*cp.SoftDeleteField = "x"
+	*cp.Tenant = "x"
+	(*cp.Policy.Conditions)[0].Value = "x"
+
+	if after := mustJSON(t, orig); after != before {
+		t.Errorf("mutating the clone changed the original:\n  got:  %s\n  want: %s", after, before)
+	}
+}
+
+func TestMutationClone(t *testing.T) {
+	orig := fullMutation()
+	before := mustJSON(t, orig)
+	cp := orig.Clone()
+	if !reflect.DeepEqual(orig, cp) {
+		t.Fatalf("Clone() = %s, want %s", mustJSON(t, cp), before)
+	}
+
+	*cp.TxID = "x"
+	*cp.Tenant = "x"
+	cp.Changes[0].Sets[0].Value.([]string)[0] = "x"
+	cp.Changes[0].Sets[1].Value.(map[string]any)["k"].([]any)[0] = 2
+	(*cp.Changes[0].Where.Conditions)[0].Value.([]any)[0] = "x"
+	cp.Changes = append(cp.Changes, types.Change{Model: "x"})
+
+	if after := mustJSON(t, orig); after != before {
+		t.Errorf("mutating the clone changed the original:\n  got:  %s\n  want: %s", after, before)
+	}
+}
+
+func TestTransactionClone(t *testing.T) {
+	committedAt := "2024-01-15T10:30:00Z"
+	orig := &types.Transaction{Seq: 7, CommittedAt: &committedAt, Mutations: []types.Mutation{*fullMutation()}}
+	before := mustJSON(t, orig)
+	cp := orig.Clone()
+	if !reflect.DeepEqual(orig, cp) {
+		t.Fatalf("Clone() = %s, want %s", mustJSON(t, cp), before)
+	}
+
+	*cp.CommittedAt = "x"
+	cp.Mutations[0].Changes[0].Model = "x"
+	cp.Mutations = append(cp.Mutations, types.Mutation{})
+
+	if after := mustJSON(t, orig); after != before {
+		t.Errorf("mutating the clone changed the original:\n  got:  %s\n  want: %s", after, before)
+	}
+}
+
+func TestCloneNil(t *testing.T) {
+	if (*types.Statement)(nil).Clone() != nil || (*types.Filter)(nil).Clone() != nil ||
+		(*types.Dependencies)(nil).Clone() != nil || (*types.Mutation)(nil).Clone() != nil {
+		t.Error("Clone() of nil should be nil")
+	}
+}
+
+func TestClonePreservesEmptySlices(t *testing.T) {
+	// A pointer to an empty slice serializes as [], unlike nil.
+	f := &types.Filter{And: &[]types.Filter{}, Conditions: &[]types.Condition{}}
+	cp := f.Clone()
+	if cp.And == nil || len(*cp.And) != 0 || cp.Or != nil || cp.Conditions == nil {
+		t.Errorf("Clone() = %+v, want empty And and Conditions and nil Or", cp)
+	}
+	if mustJSON(t, cp) != mustJSON(t, f) {
+		t.Errorf("Clone() JSON = %s, want %s", mustJSON(t, cp), mustJSON(t, f))
+	}
+
+	deps := &types.Dependencies{Records: map[string][]string{}, Filters: []types.Filter{}, Includes: []types.Include{}}
+	if got, want := mustJSON(t, deps.Clone()), mustJSON(t, deps); got != want {
+		t.Errorf("Clone() JSON = %s, want %s", got, want)
+	}
+}
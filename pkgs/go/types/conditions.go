@@ -0,0 +1,85 @@
+package types
+
+// Condition constructors build correctly-shaped Conditions for the built-in
+// operators. Values are coerced with Value; a value that cannot be coerced is
+// stored unchanged so that validation and canonicalization report it.
+
+// Eq matches rows where field equals v.
+func Eq(field string, v any) Condition { return cond(field, "eq", v) }
+
+// Ne matches rows where field does not equal v.
+func Ne(field string, v any) Condition { return cond(field, "ne", v) }
+
+// Gt matches rows where field is greater than v.
+func Gt(field string, v any) Condition { return cond(field, "gt", v) }
+
+// Gte matches rows where field is greater than or equal to v.
+func Gte(field string, v any) Condition { return cond(field, "gte", v) }
+
+// Lt matches rows where field is less than v.
+func Lt(field string, v any) Condition { return cond(field, "lt", v) }
+
+// Lte matches rows where field is less than or equal to v.
+func Lte(field string, v any) Condition { return cond(field, "lte", v) }
+
+// In matches rows where field equals any of vs.
+func In(field string, vs ...any) Condition { return cond(field, "in", vs) }
+
+// NotIn matches rows where field equals none of vs.
+func NotIn(field string, vs ...any) Condition { return cond(field, "notIn", vs) }
+
+// Between matches rows where lo <= field <= hi. The value is encoded as the
+// two-element array [lo, hi].
+func Between(field string, lo, hi any) Condition { return cond(field, "between", []any{lo, hi}) }
+
+// Contains matches rows where the string field contains s.
+func Contains(field, s string) Condition { return cond(field, "contains", s) }
+
+// StartsWith matches rows where the string field starts with s.
+func StartsWith(field, s string) Condition { return cond(field, "startsWith", s) }
+
+// EndsWith matches rows where the string field ends with s.
+func EndsWith(field, s string) Condition { return cond(field, "endsWith", s) }
+
+// IsNull matches rows where field is null. It carries no value.
+func IsNull(field string) Condition { return Condition{Field: field, Op: "isNull"} }
+
+// InQuery matches rows where field equals a value of the one field sub
+// selects, as in SQL's field IN (SELECT ...).
+func InQuery(field string, sub Query) Condition {
+	return Condition{Field: field, Op: "inQuery", Subquery: &sub}
+}
+
+// ExistsQuery matches rows for which sub returns a row whose selected field
+// equals field: a correlated EXISTS. It matches the same rows as InQuery, but
+// under Not it keeps rows where field is null, as NOT EXISTS does.
+func ExistsQuery(field string, sub Query) Condition {
+	return Condition{Field: field, Op: "existsQuery", Subquery: &sub}
+}
+
+// WithinRadius matches rows where the GeoPoint field is within meters of
+// center, by great-circle distance.
+func WithinRadius(field string, center GeoPoint, meters float64) Condition {
+	return cond(field, "withinRadius", Circle(center, meters))
+}
+
+// Intersects matches rows where the GeoPoint or GeoShape field touches or
+// overlaps shape.
+func Intersects(field string, shape GeoShape) Condition {
+	return cond(field, "intersects", shape)
+}
+
+// BBox matches rows where the GeoPoint field lies in the box spanning
+// southwest to northeast. The value is encoded as the two-element array
+// [southwest, northeast]; a box whose southwest lies east of its northeast
+// crosses the antimeridian.
+func BBox(field string, southwest, northeast GeoPoint) Condition {
+	return cond(field, "bbox", []any{southwest, northeast})
+}
+
+func cond(field, op string, v any) Condition {
+	if coerced, err := Value(v); err == nil {
+		v = coerced
+	}
+	return Condition{Field: field, Op: op, Value: v}
+}
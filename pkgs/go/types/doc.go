@@ -1,8 +1,8 @@
 // Package types provides production type definitions for the IncludeKit Universal Format.
 //
-// This is a PRODUCTION package containing only type definitions with no runtime utilities.
-// For validation, canonicalization, and shape ID computation, use the testkit package:
-// github.com/bold-minds/ik-spec/go/tests
+// This is a PRODUCTION package containing type definitions and small zero-dependency
+// helpers for building them. For validation, canonicalization, and shape ID computation, use the testkit package:
+// github.com/bold-minds/includekit-spec/go/tests
 //
 // # Overview
 //
@@ -41,6 +41,11 @@
 //	    },
 //	}
 //
+// Transaction groups the mutations of one source commit, to be applied
+// atomically and in Seq order:
+//
+//	tx := &types.Transaction{Seq: 1042, Mutations: []types.Mutation{*event}}
+//
 // Dependencies tracks what a read depends on (engine output):
 //
 //	deps := &types.Dependencies{
@@ -50,6 +55,15 @@
 //	    Includes: []types.Include{...},
 //	}
 //
+// # Values
+//
+// Condition and KV values must be JSON-safe. Value coerces Go-native values
+// (time.Time, []byte, UUIDs, decimals) into their canonical encodings so that
+// equivalent values always hash to the same shape ID:
+//
+//	v, err := types.Value(time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC))
+//	// v == "2024-01-15T10:30:00Z"
+//
 // # Implementation Boundary
 //
 // Production code should ONLY import this package for type definitions.
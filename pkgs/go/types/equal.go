@@ -0,0 +1,340 @@
+package types
+
+import (
+	"math"
+	"reflect"
+)
+
+// Equal methods compare semantic content rather than pointer identity: two
+// values are equal when they would serialize to the same JSON document, up
+// to the order of object keys. Pointers are compared by what they point to,
+// but a nil pointer-to-slice differs from a pointer to an empty one, since
+// one is omitted and the other serializes as []. Condition and KV values are
+// compared after coercion with Value, so numbers compare as numbers
+// (int64(1) equals float64(1)) and a time.Time equals its RFC 3339 string.
+//
+// Equal is exact: it does not reorder filters or includes. Use the testkit's
+// Equivalent to compare normalized statements.
+
+// Equal reports whether s and other describe the same statement. The
+// diagnostic ORMVersion and SDKVersion, and SpecVersion, are ignored, as they
+// are for shape IDs; Tenant and Policy are compared, as they are part of them.
+func (s *Statement) Equal(other *Statement) bool {
+	if s == nil || other == nil {
+		return s == other
+	}
+	return s.Query.Equal(other.Query) &&
+		s.Pagination.Equal(other.Pagination) &&
+		equalPtrSlice(s.GroupBy, other.GroupBy) &&
+		s.Having.Equal(other.Having) &&
+		equalEach(s.Includes, other.Includes, (*Include).Equal) &&
+		equalPtr(s.Tenant, other.Tenant) &&
+		s.Policy.Equal(other.Policy)
+}
+
+// Equal reports whether q and other are the same query.
+func (q *Query) Equal(other *Query) bool {
+	if q == nil || other == nil {
+		return q == other
+	}
+	return q.Model == other.Model &&
+		equalPtrSlice(q.Fields, other.Fields) &&
+		q.Where.Equal(other.Where) &&
+		equalPtrEach(q.OrderBy, other.OrderBy, (*OrderBy).Equal) &&
+		equalPtr(q.Limit, other.Limit) &&
+		equalPtr(q.Offset, other.Offset) &&
+		equalPtrSlice(q.Distinct, other.Distinct) &&
+		equalPtrEach(q.Aggregations, other.Aggregations, (*Aggregate).Equal) &&
+		equalPtrEach(q.Joins, other.Joins, (*Join).Equal) &&
+		equalPtrEach(q.Windows, other.Windows, (*Window).Equal) &&
+		q.DistinctOn.Equal(other.DistinctOn) &&
+		equalPtr(q.Deleted, other.Deleted)
+}
+
+// Equal reports whether a and other are the same aggregate.
+func (a *Aggregate) Equal(other *Aggregate) bool {
+	if a == nil || other == nil {
+		return a == other
+	}
+	return a.Func == other.Func && equalPtr(a.Field, other.Field) && equalPtr(a.Alias, other.Alias)
+}
+
+// Equal reports whether j and other are the same join.
+func (j *Join) Equal(other *Join) bool {
+	if j == nil || other == nil {
+		return j == other
+	}
+	return j.Relation == other.Relation &&
+		j.Kind == other.Kind &&
+		j.On.Equal(other.On) &&
+		equalPtrSlice(j.Fields, other.Fields)
+}
+
+// Equal reports whether w and other are the same window.
+func (w *Window) Equal(other *Window) bool {
+	if w == nil || other == nil {
+		return w == other
+	}
+	return w.Func == other.Func &&
+		equalPtr(w.Field, other.Field) &&
+		equalPtrSlice(w.PartitionBy, other.PartitionBy) &&
+		equalPtrEach(w.OrderBy, other.OrderBy, (*OrderBy).Equal) &&
+		w.Frame.Equal(other.Frame) &&
+		w.Alias == other.Alias
+}
+
+// Equal reports whether f and other are the same window frame.
+func (f *WindowFrame) Equal(other *WindowFrame) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return f.Unit == other.Unit && equalPtr(f.Start, other.Start) && equalPtr(f.End, other.End)
+}
+
+// Equal reports whether d and other are the same distinct-on clause.
+func (d *DistinctOn) Equal(other *DistinctOn) bool {
+	if d == nil || other == nil {
+		return d == other
+	}
+	return equalSlice(d.Fields, other.Fields) && equalEach(d.OrderBy, other.OrderBy, (*OrderBy).Equal)
+}
+
+// Equal reports whether i and other are the same include, nested includes
+// and all.
+func (i *Include) Equal(other *Include) bool {
+	if i == nil || other == nil {
+		return i == other
+	}
+	return i.Query.Equal(other.Query) &&
+		equalPtr(i.Kind, other.Kind) &&
+		equalEach(i.Includes, other.Includes, (*Include).Equal) &&
+		equalPtr(i.Count, other.Count)
+}
+
+// Equal reports whether f and other are the same filter.
+func (f *Filter) Equal(other *Filter) bool {
+	if f == nil || other == nil {
+		return f == other
+	}
+	return equalPtrEach(f.And, other.And, (*Filter).Equal) &&
+		equalPtrEach(f.Or, other.Or, (*Filter).Equal) &&
+		f.Not.Equal(other.Not) &&
+		equalPtrEach(f.Conditions, other.Conditions, (*Condition).Equal)
+}
+
+// Equal reports whether c and other are the same condition.
+func (c *Condition) Equal(other *Condition) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return c.Field == other.Field &&
+		equalSlice(c.FieldPath, other.FieldPath) &&
+		c.Op == other.Op &&
+		equalValue(c.Value, other.Value) &&
+		c.Subquery.Equal(other.Subquery)
+}
+
+// Equal reports whether o and other are the same order spec.
+func (o *OrderBy) Equal(other *OrderBy) bool {
+	if o == nil || other == nil {
+		return o == other
+	}
+	return o.Field == other.Field &&
+		equalPtr(o.Descending, other.Descending) &&
+		equalPtr(o.NullsFirst, other.NullsFirst) &&
+		equalPtr(o.CaseSensitive, other.CaseSensitive)
+}
+
+// Equal reports whether p and other are the same pagination.
+func (p *Pagination) Equal(other *Pagination) bool {
+	if p == nil || other == nil {
+		return p == other
+	}
+	return equalPtr(p.First, other.First) &&
+		equalPtr(p.Last, other.Last) &&
+		equalPtr(p.After, other.After) &&
+		equalPtr(p.Before, other.Before)
+}
+
+// Equal reports whether m and other are the same mutation.
+func (m *Mutation) Equal(other *Mutation) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+	return equalPtr(m.TxID, other.TxID) &&
+		(m.Changes == nil) == (other.Changes == nil) &&
+		equalEach(m.Changes, other.Changes, (*Change).Equal) &&
+		equalPtr(m.Tenant, other.Tenant)
+}
+
+// Equal reports whether c and other are the same change.
+func (c *Change) Equal(other *Change) bool {
+	if c == nil || other == nil {
+		return c == other
+	}
+	return c.Model == other.Model &&
+		c.Action == other.Action &&
+		equalEach(c.Sets, other.Sets, (*KV).Equal) &&
+		c.Where.Equal(other.Where)
+}
+
+// Equal reports whether t and other are the same transaction.
+func (t *Transaction) Equal(other *Transaction) bool {
+	if t == nil || other == nil {
+		return t == other
+	}
+	return t.Seq == other.Seq &&
+		equalPtr(t.CommittedAt, other.CommittedAt) &&
+		(t.Mutations == nil) == (other.Mutations == nil) &&
+		equalEach(t.Mutations, other.Mutations, (*Mutation).Equal)
+}
+
+// Equal reports whether kv and other are the same field-value pair.
+func (kv *KV) Equal(other *KV) bool {
+	if kv == nil || other == nil {
+		return kv == other
+	}
+	return kv.Field == other.Field && equalValue(kv.Value, other.Value)
+}
+
+func equalPtr[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// equalSlice treats nil and empty slices alike, as omitempty does.
+func equalSlice[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPtrSlice[T comparable](a, b *[]T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equalSlice(*a, *b)
+}
+
+// equalEach compares a and b element by element with equal, treating
+// nil and empty slices alike.
+func equalEach[T any](a, b []T, equal func(*T, *T) bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !equal(&a[i], &b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalPtrEach[T any](a, b *[]T, equal func(*T, *T) bool) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return equalEach(*a, *b, equal)
+}
+
+// equalValue compares condition and KV values in their coerced JSON form.
+// Values Value rejects are compared with reflect.DeepEqual.
+func equalValue(a, b any) bool {
+	ca, errA := Value(a)
+	cb, errB := Value(b)
+	if errA != nil || errB != nil {
+		return reflect.DeepEqual(a, b)
+	}
+	return equalJSON(ca, cb)
+}
+
+// equalJSON compares two coerced values.
+func equalJSON(a, b any) bool {
+	switch va := a.(type) {
+	case nil:
+		return b == nil
+	case string:
+		vb, ok := b.(string)
+		return ok && va == vb
+	case bool:
+		vb, ok := b.(bool)
+		return ok && va == vb
+	case []any:
+		vb, ok := b.([]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for i := range va {
+			if !equalJSON(va[i], vb[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]any:
+		vb, ok := b.(map[string]any)
+		if !ok || len(va) != len(vb) {
+			return false
+		}
+		for k, elem := range va {
+			other, ok := vb[k]
+			if !ok || !equalJSON(elem, other) {
+				return false
+			}
+		}
+		return true
+	}
+	return equalNumber(a, b)
+}
+
+// equalNumber compares two numbers of any Go numeric type by value.
+// Integers compare exactly; once either side is a float, both compare as
+// float64, as they would after a JSON round trip.
+func equalNumber(a, b any) bool {
+	ra, rb := reflect.ValueOf(a), reflect.ValueOf(b)
+	fa, intA, okA := number(ra)
+	fb, intB, okB := number(rb)
+	if !okA || !okB {
+		return false
+	}
+	if intA && intB {
+		return equalInts(ra, rb)
+	}
+	return fa == fb
+}
+
+// number returns v as a float64 and whether it is an integer.
+func number(v reflect.Value) (f float64, isInt, ok bool) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true, true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true, true
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		return f, false, !math.IsNaN(f)
+	}
+	return 0, false, false
+}
+
+func equalInts(a, b reflect.Value) bool {
+	signedA := a.CanInt()
+	signedB := b.CanInt()
+	switch {
+	case signedA && signedB:
+		return a.Int() == b.Int()
+	case !signedA && !signedB:
+		return a.Uint() == b.Uint()
+	case signedA:
+		return a.Int() >= 0 && uint64(a.Int()) == b.Uint()
+	default:
+		return b.Int() >= 0 && uint64(b.Int()) == a.Uint()
+	}
+}
@@ -0,0 +1,157 @@
+package types_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestStatementEqual(t *testing.T) {
+	a := fullStatement()
+	if !a.Equal(a.Clone()) {
+		t.Fatal("a statement should equal its clone")
+	}
+	if !(*types.Statement)(nil).Equal(nil) || a.Equal(nil) || (*types.Statement)(nil).Equal(a) {
+		t.Error("nil statements should only equal nil")
+	}
+
+	diagnostics := a.Clone()
+	diagnostics.ORMVersion, diagnostics.SDKVersion = nil, nil
+	if !a.Equal(diagnostics) {
+		t.Error("diagnostic fields should be ignored")
+	}
+
+	tcs := []struct {
+		name   string
+		mutate func(s *types.Statement)
+	}{
+		{"model", func(s *types.Statement) { s.Query.Model = "users" }},
+		{"fields", func(s *types.Statement) { (*s.Query.Fields)[1] = "body" }},
+		{"nested condition value", func(s *types.Statement) {
+			(*(*s.Query.Where.And)[0].Conditions)[0] = types.In("status", "draft")
+		}},
+		{"not", func(s *types.Statement) { s.Query.Where.Not = nil }},
+		{"subquery", func(s *types.Statement) { (*s.Query.Where.Conditions)[1].Subquery.Model = "tags" }},
+		{"order by direction", func(s *types.Statement) { *(*s.Query.OrderBy)[0].Descending = false }},
+		{"limit", func(s *types.Statement) { *s.Query.Limit = 11 }},
+		{"offset removed", func(s *types.Statement) { s.Query.Offset = nil }},
+		{"aggregate alias", func(s *types.Statement) { (*s.Query.Aggregations)[0].Alias = nil }},
+		{"join kind", func(s *types.Statement) { (*s.Query.Joins)[0].Kind = "left" }},
+		{"join on", func(s *types.Statement) { (*s.Query.Joins)[0].On = nil }},
+		{"window partition", func(s *types.Statement) { (*(*s.Query.Windows)[0].PartitionBy)[0] = "status" }},
+		{"window frame end", func(s *types.Statement) { (*s.Query.Windows)[0].Frame.End = nil }},
+		{"distinct_on order", func(s *types.Statement) { s.Query.DistinctOn.OrderBy[1].Field = "createdAt" }},
+		{"deleted", func(s *types.Statement) { s.Query.Deleted = nil }},
+		{"pagination cursor", func(s *types.Statement) { *s.Pagination.After = "c2" }},
+		{"group by", func(s *types.Statement) { s.GroupBy = &[]string{} }},
+		{"having", func(s *types.Statement) { (*s.Having.Conditions)[0].Op = "gte" }},
+		{"nested include kind", func(s *types.Statement) { *s.Includes[0].Includes[0].Kind = "none" }},
+		{"include removed", func(s *types.Statement) { s.Includes = nil }},
+		{"tenant", func(s *types.Statement) { *s.Tenant = "globex" }},
+		{"policy", func(s *types.Statement) { (*s.Policy.Conditions)[0].Value = "o2" }},
+		{"policy removed", func(s *types.Statement) { s.Policy = nil }},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			b := a.Clone()
+			tt.mutate(b)
+			if a.Equal(b) || b.Equal(a) {
+				t.Errorf("statements differing in %s should not be equal", tt.name)
+			}
+		})
+	}
+}
+
+func TestFilterEqualEmptySlices(t *testing.T) {
+	// A pointer to an empty slice serializes as [], unlike nil.
+	if (&types.Filter{And: &[]types.Filter{}}).Equal(&types.Filter{}) {
+		t.Error("a pointer to an empty slice should not equal nil")
+	}
+	// Plain slices with omitempty are omitted either way.
+	a := types.Condition{Field: "f", Op: "eq", FieldPath: []string{}}
+	b := types.Condition{Field: "f", Op: "eq"}
+	if !a.Equal(&b) {
+		t.Error("nil and empty field paths should be equal")
+	}
+}
+
+func TestConditionEqualValues(t *testing.T) {
+	at := time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)
+	tcs := []struct {
+		name  string
+		a, b  any
+		equal bool
+	}{
+		{"int and float", 1, 1.0, true},
+		{"int and int64", 1, int64(1), true},
+		{"uint and negative int", uint(1), -1, false},
+		{"different numbers", 1, 2, false},
+		{"number and string", 1, "1", false},
+		{"typed and generic slices", []string{"a", "b"}, []any{"a", "b"}, true},
+		{"slice order", []any{"a", "b"}, []any{"b", "a"}, false},
+		{"maps", map[string]int{"x": 1}, map[string]any{"x": 1.0}, true},
+		{"map values", map[string]any{"x": 1}, map[string]any{"x": 2}, false},
+		{"time and string", at, "2024-01-15T10:30:00Z", true},
+		{"nil and missing", nil, nil, true},
+		{"nil and false", nil, false, false},
+	}
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			a := types.Condition{Field: "f", Op: "eq", Value: tt.a}
+			b := types.Condition{Field: "f", Op: "eq", Value: tt.b}
+			if got := a.Equal(&b); got != tt.equal {
+				t.Errorf("Equal(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.equal)
+			}
+		})
+	}
+}
+
+func TestMutationEqual(t *testing.T) {
+	a := fullMutation()
+	if !a.Equal(a.Clone()) {
+		t.Fatal("a mutation should equal its clone")
+	}
+
+	b := a.Clone()
+	b.Changes[0].Sets[0].Value = []any{"a", "c"}
+	if a.Equal(b) {
+		t.Error("mutations differing in a set value should not be equal")
+	}
+
+	b = a.Clone()
+	b.Changes[0].Where = nil
+	if a.Equal(b) {
+		t.Error("mutations differing in where should not be equal")
+	}
+
+	b = a.Clone()
+	b.Tenant = nil
+	if a.Equal(b) {
+		t.Error("mutations differing in tenant should not be equal")
+	}
+
+	// Changes is not omitempty: null and [] are different documents.
+	if (&types.Mutation{}).Equal(&types.Mutation{Changes: []types.Change{}}) {
+		t.Error("nil and empty changes should not be equal")
+	}
+}
+
+func TestTransactionEqual(t *testing.T) {
+	a := &types.Transaction{Seq: 1, Mutations: []types.Mutation{*fullMutation()}}
+	if !a.Equal(a.Clone()) {
+		t.Fatal("a transaction should equal its clone")
+	}
+
+	b := a.Clone()
+	b.Seq = 2
+	if a.Equal(b) {
+		t.Error("transactions differing in seq should not be equal")
+	}
+
+	b = a.Clone()
+	b.Mutations[0].Changes[0].Model = "x"
+	if a.Equal(b) {
+		t.Error("transactions differing in a mutation should not be equal")
+	}
+}
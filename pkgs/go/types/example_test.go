@@ -3,11 +3,12 @@ package types_test
 import (
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/bold-minds/includekit-spec/go/types"
 )
 
-// Example demonstrates basic QueryShape construction
+// Example demonstrates basic Query construction
 func Example() {
 	shape := &types.Query{
 		Model: "Post",
@@ -193,6 +194,150 @@ func ExamplePagination() {
 	// Previous page: last=20, before cursor present=true
 }
 
+// ExampleValue demonstrates coercing Go-native values into canonical JSON values
+func ExampleValue() {
+	ts := time.Date(2024, 1, 15, 11, 30, 0, 0, time.FixedZone("CET", 3600))
+	when, _ := types.Value(ts)
+	raw, _ := types.Value([]byte("hi"))
+	list, _ := types.Value([]time.Time{ts})
+
+	fmt.Println(when)
+	fmt.Println(raw)
+	fmt.Println(list)
+	// Output:
+	// 2024-01-15T10:30:00Z
+	// aGk=
+	// [2024-01-15T10:30:00Z]
+}
+
+// ExampleEq demonstrates building conditions with the typed constructors
+func ExampleEq() {
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	filter := &types.Filter{
+		Conditions: &[]types.Condition{
+			types.Eq("status", "published"),
+			types.Between("createdAt", since, since.AddDate(0, 1, 0)),
+			types.IsNull("deletedAt"),
+		},
+	}
+
+	data, _ := json.Marshal(filter)
+	fmt.Println(string(data))
+	// Output:
+	// {"conditions":[{"field":"status","op":"eq","value":"published"},{"field":"createdAt","op":"between","value":["2024-01-01T00:00:00Z","2024-02-01T00:00:00Z"]},{"field":"deletedAt","op":"isNull"}]}
+}
+
+// ExampleWithinRadius demonstrates geospatial conditions on a GeoPoint field
+func ExampleWithinRadius() {
+	louvre := types.GeoPoint{Lat: 48.8606, Lng: 2.3376}
+	filter := &types.Filter{
+		Conditions: &[]types.Condition{
+			types.WithinRadius("location", louvre, 500),
+			types.BBox("location", types.GeoPoint{Lat: 48.8, Lng: 2.2}, types.GeoPoint{Lat: 48.9, Lng: 2.5}),
+		},
+	}
+
+	data, _ := json.Marshal(filter)
+	fmt.Println(string(data))
+	// Output:
+	// {"conditions":[{"field":"location","op":"withinRadius","value":{"center":{"lat":48.8606,"lng":2.3376},"radius":500}},{"field":"location","op":"bbox","value":[{"lat":48.8,"lng":2.2},{"lat":48.9,"lng":2.5}]}]}
+}
+
+// ExampleQuery_GetLimit demonstrates nil-safe access to optional fields
+func ExampleQuery_GetLimit() {
+	stmt := &types.Statement{
+		Query: &types.Query{
+			Model:   "posts",
+			OrderBy: &[]types.OrderBy{{Field: "createdAt", Descending: boolPtr(true)}},
+		},
+	}
+
+	limit, ok := stmt.GetQuery().GetLimit()
+	fmt.Printf("limit=%d set=%v\n", limit, ok)
+	for _, ob := range stmt.GetOrderBy() {
+		fmt.Printf("%s descending=%v\n", ob.Field, ob.IsDescending())
+	}
+
+	var missing *types.Statement
+	fmt.Printf("nil statement order_by=%d\n", len(missing.GetOrderBy()))
+	// Output:
+	// limit=0 set=false
+	// createdAt descending=true
+	// nil statement order_by=0
+}
+
+// ExampleNewStatement demonstrates quick construction with functional options
+func ExampleNewStatement() {
+	stmt := types.NewStatement(
+		types.WithModel("posts"),
+		types.WithConditions(types.Eq("status", "published")),
+		types.WithOrderBy(types.OrderBy{Field: "createdAt", Descending: boolPtr(true)}),
+		types.WithLimit(10),
+		types.WithInclude(types.Include{Query: &types.Query{Model: "author"}}),
+	)
+
+	data, _ := json.Marshal(stmt)
+	fmt.Println(string(data))
+	// Output:
+	// {"query":{"model":"posts","where":{"conditions":[{"field":"status","op":"eq","value":"published"}]},"order_by":[{"field":"createdAt","descending":true}],"limit":10},"includes":[{"query":{"model":"author"}}]}
+}
+
+// ExampleCount demonstrates typed aggregations and their result columns
+func ExampleCount() {
+	query := &types.Query{
+		Model:        "Post",
+		Fields:       &[]string{"authorId"},
+		Aggregations: &[]types.Aggregate{types.Count(""), types.Sum("views").As("totalViews"), types.Avg("rating")},
+	}
+
+	data, _ := json.Marshal(query)
+	fmt.Println(string(data))
+	for _, agg := range query.GetAggregations() {
+		fmt.Println(agg.Column())
+	}
+	// Output:
+	// {"model":"Post","fields":["authorId"],"aggregations":[{"func":"count"},{"func":"sum","field":"views","alias":"totalViews"},{"func":"avg","field":"rating"}]}
+	// count
+	// totalViews
+	// avg_rating
+}
+
+// ExampleInnerJoin demonstrates joining a relation into the result rows
+func ExampleInnerJoin() {
+	query := &types.Query{
+		Model: "Post",
+		Joins: &[]types.Join{
+			types.InnerJoin("author", types.Eq("active", true)).Select("name"),
+			types.LeftJoin("category"),
+		},
+	}
+
+	data, _ := json.Marshal(query)
+	fmt.Println(string(data))
+	// Output:
+	// {"model":"Post","joins":[{"relation":"author","kind":"inner","on":{"conditions":[{"field":"active","op":"eq","value":true}]},"fields":["name"]},{"relation":"category","kind":"left"}]}
+}
+
+// ExampleRowNumber demonstrates window function columns: each post's rank
+// among its author's posts, and a running total of views
+func ExampleRowNumber() {
+	byViews := types.OrderBy{Field: "views", Descending: boolPtr(true)}
+	query := &types.Query{
+		Model: "Post",
+		Windows: &[]types.Window{
+			types.RowNumber("rank").PartitionedBy("authorId").OrderedBy(byViews),
+			types.WindowOf(types.Sum("views"), "runningViews").
+				OrderedBy(types.OrderBy{Field: "createdAt"}).
+				Within(types.WindowFrame{Unit: "rows", End: intPtr(0)}),
+		},
+	}
+
+	data, _ := json.Marshal(query)
+	fmt.Println(string(data))
+	// Output:
+	// {"model":"Post","windows":[{"func":"row_number","partition_by":["authorId"],"order_by":[{"field":"views","descending":true}],"alias":"rank"},{"func":"sum","field":"views","order_by":[{"field":"createdAt"}],"frame":{"unit":"rows","end":0},"alias":"runningViews"}]}
+}
+
 func intPtr(i int) *int {
 	return &i
 }
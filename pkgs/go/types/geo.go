@@ -0,0 +1,36 @@
+package types
+
+// Circle returns the GeoShape of the points within meters of center.
+func Circle(center GeoPoint, meters float64) GeoShape {
+	return GeoShape{Center: &center, Radius: &meters}
+}
+
+// Polygon returns the GeoShape of the polygon with the given vertices, which
+// it closes implicitly.
+func Polygon(vertices ...GeoPoint) GeoShape {
+	return GeoShape{Polygon: vertices}
+}
+
+// value returns the point as the object it encodes to in JSON.
+func (p GeoPoint) value() map[string]any {
+	return map[string]any{"lat": p.Lat, "lng": p.Lng}
+}
+
+// value returns the shape as the object it encodes to in JSON.
+func (s GeoShape) value() map[string]any {
+	out := map[string]any{}
+	if s.Center != nil {
+		out["center"] = s.Center.value()
+	}
+	if s.Radius != nil {
+		out["radius"] = *s.Radius
+	}
+	if s.Polygon != nil {
+		vertices := make([]any, len(s.Polygon))
+		for i, p := range s.Polygon {
+			vertices[i] = p.value()
+		}
+		out["polygon"] = vertices
+	}
+	return out
+}
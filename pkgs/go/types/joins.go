@@ -0,0 +1,25 @@
+package types
+
+// Join constructors build Joins of the query model's relations. Use Select to
+// project fields of the joined model.
+
+// InnerJoin joins relation, keeping only rows with a related record matching
+// on. With no conditions, any related record matches.
+func InnerJoin(relation string, on ...Condition) Join { return join(relation, "inner", on) }
+
+// LeftJoin joins relation, keeping rows without a related record matching on.
+func LeftJoin(relation string, on ...Condition) Join { return join(relation, "left", on) }
+
+// Select returns a copy of the join projecting fields of the joined model.
+func (j Join) Select(fields ...string) Join {
+	j.Fields = &fields
+	return j
+}
+
+func join(relation, kind string, on []Condition) Join {
+	j := Join{Relation: relation, Kind: kind}
+	if len(on) > 0 {
+		j.On = &Filter{Conditions: &on}
+	}
+	return j
+}
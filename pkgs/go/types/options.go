@@ -0,0 +1,167 @@
+package types
+
+// StatementOption configures a Statement built by NewStatement.
+type StatementOption func(*Statement)
+
+// NewStatement builds a Statement from functional options. Query options
+// create the Query on first use, so options may be given in any order:
+//
+//	stmt := types.NewStatement(
+//	    types.WithModel("posts"),
+//	    types.WithConditions(types.Eq("status", "published")),
+//	    types.WithLimit(10),
+//	)
+func NewStatement(opts ...StatementOption) *Statement {
+	stmt := &Statement{}
+	for _, opt := range opts {
+		opt(stmt)
+	}
+	return stmt
+}
+
+// WithModel sets the query model.
+func WithModel(model string) StatementOption {
+	return func(s *Statement) { s.query().Model = model }
+}
+
+// WithFields sets the selected fields.
+func WithFields(fields ...string) StatementOption {
+	return func(s *Statement) { s.query().Fields = &fields }
+}
+
+// WithWhere sets the query filter, replacing any previous filter.
+func WithWhere(where Filter) StatementOption {
+	return func(s *Statement) { s.query().Where = &where }
+}
+
+// WithConditions appends conditions to the top level of the query filter.
+func WithConditions(conds ...Condition) StatementOption {
+	return func(s *Statement) {
+		q := s.query()
+		if q.Where == nil {
+			q.Where = &Filter{}
+		}
+		merged := append(append([]Condition{}, q.Where.GetConditions()...), conds...)
+		q.Where.Conditions = &merged
+	}
+}
+
+// WithOrderBy appends orderings to the query.
+func WithOrderBy(orderBy ...OrderBy) StatementOption {
+	return func(s *Statement) {
+		q := s.query()
+		merged := append(append([]OrderBy{}, q.GetOrderBy()...), orderBy...)
+		q.OrderBy = &merged
+	}
+}
+
+// WithLimit sets the query limit.
+func WithLimit(limit int) StatementOption {
+	return func(s *Statement) { s.query().Limit = &limit }
+}
+
+// WithOffset sets the query offset.
+func WithOffset(offset int) StatementOption {
+	return func(s *Statement) { s.query().Offset = &offset }
+}
+
+// WithDistinct sets the distinct fields.
+func WithDistinct(fields ...string) StatementOption {
+	return func(s *Statement) { s.query().Distinct = &fields }
+}
+
+// WithDistinctOn keeps one row per combination of fields: the first in
+// tieBreak order. The DistinctOn order_by is fields, ascending, then
+// tieBreak.
+func WithDistinctOn(fields []string, tieBreak ...OrderBy) StatementOption {
+	return func(s *Statement) {
+		orderBy := make([]OrderBy, 0, len(fields)+len(tieBreak))
+		for _, f := range fields {
+			orderBy = append(orderBy, OrderBy{Field: f})
+		}
+		s.query().DistinctOn = &DistinctOn{Fields: fields, OrderBy: append(orderBy, tieBreak...)}
+	}
+}
+
+// WithIncludeDeleted reads soft-deleted rows alongside live ones.
+func WithIncludeDeleted() StatementOption {
+	return func(s *Statement) {
+		deleted := "include"
+		s.query().Deleted = &deleted
+	}
+}
+
+// WithOnlyDeleted reads only soft-deleted rows.
+func WithOnlyDeleted() StatementOption {
+	return func(s *Statement) {
+		deleted := "only"
+		s.query().Deleted = &deleted
+	}
+}
+
+// WithAggregations appends aggregate projections to the query.
+func WithAggregations(aggs ...Aggregate) StatementOption {
+	return func(s *Statement) {
+		q := s.query()
+		merged := append(append([]Aggregate{}, q.GetAggregations()...), aggs...)
+		q.Aggregations = &merged
+	}
+}
+
+// WithJoins appends joins to the query.
+func WithJoins(joins ...Join) StatementOption {
+	return func(s *Statement) {
+		q := s.query()
+		merged := append(append([]Join{}, q.GetJoins()...), joins...)
+		q.Joins = &merged
+	}
+}
+
+// WithWindows appends window function columns to the query.
+func WithWindows(windows ...Window) StatementOption {
+	return func(s *Statement) {
+		q := s.query()
+		merged := append(append([]Window{}, q.GetWindows()...), windows...)
+		q.Windows = &merged
+	}
+}
+
+// WithInclude appends an include.
+func WithInclude(include Include) StatementOption {
+	return func(s *Statement) { s.Includes = append(s.Includes, include) }
+}
+
+// WithPagination sets the pagination.
+func WithPagination(pagination Pagination) StatementOption {
+	return func(s *Statement) { s.Pagination = &pagination }
+}
+
+// WithGroupBy sets the group-by fields.
+func WithGroupBy(fields ...string) StatementOption {
+	return func(s *Statement) { s.GroupBy = &fields }
+}
+
+// WithHaving sets the having filter.
+func WithHaving(having Filter) StatementOption {
+	return func(s *Statement) { s.Having = &having }
+}
+
+// WithTenant scopes the statement to a tenant, giving it a shape ID of its
+// own.
+func WithTenant(tenant string) StatementOption {
+	return func(s *Statement) { s.Tenant = &tenant }
+}
+
+// WithPolicy sets the row-level visibility predicate the statement reads
+// under. It is ANDed with the root where, both when reading and when hashing
+// the shape ID, and Dependencies.Policy keeps it apart from the filters.
+func WithPolicy(policy Filter) StatementOption {
+	return func(s *Statement) { s.Policy = &policy }
+}
+
+func (s *Statement) query() *Query {
+	if s.Query == nil {
+		s.Query = &Query{}
+	}
+	return s.Query
+}
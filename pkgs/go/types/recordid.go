@@ -0,0 +1,94 @@
+package types
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// RecordID encodes the key of a record as an entry of Dependencies.Records.
+// Values are coerced with Value and must be strings, numbers or booleans.
+//
+// A single-field key is its value as a string: strings as they are, numbers
+// and booleans in their JSON form, so 7 and 7.0 name the same record. A
+// composite key is the compact JSON array of its values in key order, e.g.
+// ["order_1",3], so its parts cannot run together.
+//
+// Returns an error if values is empty or a value is null or not a scalar.
+func RecordID(values ...any) (string, error) {
+	if len(values) == 0 {
+		return "", fmt.Errorf("record id: at least one key value is required")
+	}
+	coerced := make([]any, len(values))
+	for i, v := range values {
+		c, err := Value(v)
+		if err != nil {
+			return "", fmt.Errorf("record id: key value %d: %w", i, err)
+		}
+		switch c.(type) {
+		case nil, []any, map[string]any:
+			return "", fmt.Errorf("record id: key value %d must be a string, number or boolean, got %T", i, v)
+		}
+		coerced[i] = c
+	}
+
+	if s, ok := coerced[0].(string); ok && len(coerced) == 1 {
+		return s, nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	var err error
+	if len(coerced) == 1 {
+		err = enc.Encode(coerced[0])
+	} else {
+		err = enc.Encode(coerced)
+	}
+	if err != nil {
+		return "", fmt.Errorf("record id: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// RecordIDParts splits the record ID of an n-field key into the IDs of its
+// parts, in key order, each as RecordID would encode that value alone. A
+// single-field ID is its own only part.
+//
+// Returns an error if a composite ID is not a JSON array of n scalars.
+func RecordIDParts(id string, n int) ([]string, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("record id: key must have at least one field")
+	}
+	if n == 1 {
+		return []string{id}, nil
+	}
+
+	dec := json.NewDecoder(strings.NewReader(id))
+	dec.UseNumber()
+	var values []any
+	if err := dec.Decode(&values); err != nil {
+		return nil, fmt.Errorf("record id: %q is not a JSON array: %w", id, err)
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("record id: trailing data after %q", id)
+	}
+	if len(values) != n {
+		return nil, fmt.Errorf("record id: %q has %d parts, want %d", id, len(values), n)
+	}
+
+	parts := make([]string, n)
+	for i, v := range values {
+		switch val := v.(type) {
+		case string:
+			parts[i] = val
+		case json.Number:
+			parts[i] = val.String()
+		case bool:
+			parts[i] = fmt.Sprint(val)
+		default:
+			return nil, fmt.Errorf("record id: part %d of %q is not a scalar", i, id)
+		}
+	}
+	return parts, nil
+}
@@ -0,0 +1,76 @@
+package types_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestRecordID(t *testing.T) {
+	tcs := []struct {
+		name   string
+		values []any
+		want   string
+	}{
+		{"string", []any{"u1"}, "u1"},
+		{"int", []any{7}, "7"},
+		{"integral float", []any{7.0}, "7"},
+		{"bool", []any{true}, "true"},
+		{"time", []any{time.Date(2024, 1, 15, 10, 30, 0, 0, time.UTC)}, "2024-01-15T10:30:00Z"},
+		{"composite", []any{"o1", 3}, `["o1",3]`},
+		{"composite keeps html", []any{"<a>", "b&c"}, `["<a>","b&c"]`},
+		{"composite of one string per part", []any{"a,b", "c"}, `["a,b","c"]`},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := types.RecordID(tt.values...)
+			if err != nil {
+				t.Fatalf("RecordID failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("RecordID() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordIDErrors(t *testing.T) {
+	tcs := map[string][]any{
+		"empty":     nil,
+		"null":      {nil},
+		"list part": {"o1", []string{"x"}},
+		"map part":  {map[string]any{"a": 1}},
+	}
+	for name, values := range tcs {
+		if _, err := types.RecordID(values...); err == nil {
+			t.Errorf("%s: RecordID(%v) should fail", name, values)
+		}
+	}
+}
+
+func TestRecordIDParts(t *testing.T) {
+	id, err := types.RecordID("o1", 3, false)
+	if err != nil {
+		t.Fatalf("RecordID failed: %v", err)
+	}
+	parts, err := types.RecordIDParts(id, 3)
+	if err != nil {
+		t.Fatalf("RecordIDParts failed: %v", err)
+	}
+	if want := []string{"o1", "3", "false"}; !reflect.DeepEqual(parts, want) {
+		t.Errorf("RecordIDParts() = %v, want %v", parts, want)
+	}
+
+	if parts, err := types.RecordIDParts(`["not","split"]`, 1); err != nil || len(parts) != 1 {
+		t.Errorf("single-field ids are their own part, got %v, %v", parts, err)
+	}
+
+	for _, bad := range []string{`o1`, `["o1"]`, `["o1",3,4]`, `["o1",null]`, `["o1",[3]]`, `["o1",3] x`} {
+		if _, err := types.RecordIDParts(bad, 2); err == nil {
+			t.Errorf("RecordIDParts(%s, 2) should fail", bad)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// CompareSpecVersions compares two X.Y.Z spec versions, returning -1, 0 or +1.
+// Returns an error if either version is malformed.
+func CompareSpecVersions(a, b string) (int, error) {
+	pa, err := parseSpecVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := parseSpecVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1, nil
+		case pa[i] > pb[i]:
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func parseSpecVersion(v string) ([3]int, error) {
+	var parts [3]int
+	fields := strings.Split(v, ".")
+	if len(fields) != 3 {
+		return parts, fmt.Errorf("spec version %q must be X.Y.Z", v)
+	}
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < 0 || f != strconv.Itoa(n) {
+			return parts, fmt.Errorf("spec version %q must be X.Y.Z", v)
+		}
+		parts[i] = n
+	}
+	return parts, nil
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Strict wraps a document type so that unmarshaling it fails where
+// encoding/json would silently drop or misplace data: see UnmarshalStrict.
+//
+//	var s types.Strict[types.Statement]
+//	err := json.Unmarshal(data, &s) // "orderBy" fails instead of dropping the clause
+//	stmt := s.Value
+type Strict[T any] struct {
+	Value T
+}
+
+// UnmarshalJSON decodes data into s.Value with UnmarshalStrict.
+func (s *Strict[T]) UnmarshalJSON(data []byte) error {
+	return UnmarshalStrict(data, &s.Value)
+}
+
+// MarshalJSON encodes s.Value.
+func (s Strict[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Value)
+}
+
+// UnknownFieldError reports a JSON member with no field of that exact name.
+type UnknownFieldError struct {
+	Field string
+	Path  string // dotted path of the enclosing object, empty at the top level
+}
+
+func (e *UnknownFieldError) Error() string {
+	if e.Path == "" {
+		return fmt.Sprintf("json: unknown field %q", e.Field)
+	}
+	return fmt.Sprintf("json: unknown field %q in %s", e.Field, e.Path)
+}
+
+// UnmarshalStrict is like json.Unmarshal, but fails with an
+// *UnknownFieldError on a member with no field of exactly that name, which a
+// lenient decode drops ("orderBy" for "order_by") or matches
+// case-insensitively ("Limit"), silently changing the shape ID. Values typed
+// any, such as Condition.Value, are taken as they come.
+func UnmarshalStrict(data []byte, v any) error {
+	var raw any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	if err := checkFields(reflect.TypeOf(v), raw, ""); err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// checkFields walks raw alongside t, requiring every object member to name a
+// field of t exactly.
+func checkFields(t reflect.Type, raw any, path string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := raw.(map[string]any)
+		if !ok {
+			return nil
+		}
+		fields := jsonFields(t)
+		keys := make([]string, 0, len(obj))
+		for key := range obj {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			ft, ok := fields[key]
+			if !ok {
+				return &UnknownFieldError{Field: key, Path: path}
+			}
+			if err := checkFields(ft, obj[key], joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		arr, _ := raw.([]any)
+		for i, elem := range arr {
+			if err := checkFields(t.Elem(), elem, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		obj, _ := raw.(map[string]any)
+		for key, elem := range obj {
+			if err := checkFields(t.Elem(), elem, joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// jsonFields maps the JSON names of t's exported fields to their types.
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = f.Type
+	}
+	return fields
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
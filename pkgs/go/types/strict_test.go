@@ -0,0 +1,73 @@
+package types_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestUnmarshalStrict(t *testing.T) {
+	tcs := []struct {
+		name  string
+		doc   string
+		field string
+		path  string
+	}{
+		{"valid", `{"query":{"model":"posts","order_by":[{"field":"id"}],"where":{"conditions":[{"field":"meta","op":"eq","value":{"anyKey":1}}]}}}`, "", ""},
+		{"camel case", `{"query":{"model":"posts","orderBy":[{"field":"id"}]}}`, "orderBy", "query"},
+		{"case mismatch", `{"query":{"model":"posts","Limit":10}}`, "Limit", "query"},
+		{"top level", `{"query":{"model":"posts"},"x_hint":true}`, "x_hint", ""},
+		{"nested", `{"query":{"model":"posts","where":{"or":[{"conditions":[{"field":"id","op":"eq","value":1,"Op":"ne"}]}]}}}`, "Op", "query.where.or[0].conditions[0]"},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			var stmt types.Statement
+			err := types.UnmarshalStrict([]byte(tt.doc), &stmt)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("UnmarshalStrict failed: %v", err)
+				}
+				return
+			}
+			var ferr *types.UnknownFieldError
+			if !errors.As(err, &ferr) || ferr.Field != tt.field || ferr.Path != tt.path {
+				t.Errorf("UnmarshalStrict() error = %v, want unknown field %q in %q", err, tt.field, tt.path)
+			}
+		})
+	}
+}
+
+func TestUnmarshalStrictRejectsWrongTypes(t *testing.T) {
+	var stmt types.Statement
+	err := types.UnmarshalStrict([]byte(`{"query":{"model":"posts","limit":"10"}}`), &stmt)
+	var typeErr *json.UnmarshalTypeError
+	if !errors.As(err, &typeErr) {
+		t.Errorf("UnmarshalStrict() error = %v, want *json.UnmarshalTypeError", err)
+	}
+	if err := types.UnmarshalStrict([]byte(`{"query":{"model":"posts"}} {}`), &stmt); err == nil {
+		t.Error("expected error for trailing data")
+	}
+}
+
+func TestStrictWrapper(t *testing.T) {
+	var body struct {
+		Statement types.Strict[types.Statement] `json:"statement"`
+	}
+	if err := json.Unmarshal([]byte(`{"statement":{"query":{"model":"posts","orderBy":[]}}}`), &body); err == nil {
+		t.Error("expected error for orderBy")
+	}
+	if err := json.Unmarshal([]byte(`{"statement":{"query":{"model":"posts","limit":5}}}`), &body); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if limit, ok := body.Statement.Value.Query.GetLimit(); !ok || limit != 5 {
+		t.Errorf("limit = %d, %v; want 5", limit, ok)
+	}
+
+	data, err := json.Marshal(body.Statement)
+	if err != nil || string(data) != `{"query":{"model":"posts","limit":5}}` {
+		t.Errorf("Marshal() = %s, %v", data, err)
+	}
+}
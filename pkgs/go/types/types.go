@@ -1,20 +1,24 @@
 // Package types provides type definitions for IncludeKit Universal Format v0.1
-// This is a PRODUCTION package - types only, no runtime utilities.
+// This is a PRODUCTION package - types and zero-dependency helpers only, no validation or hashing.
 //
-// IMPORTANT: These types are HAND-WRITTEN (not auto-generated) to preserve idiomatic
-// Go patterns like pointer-to-slice for optionals.
-// When schema/v0-1-0.json changes, these types must be updated manually.
+// IMPORTANT: These types are HAND-WRITTEN to preserve idiomatic Go patterns like
+// pointer-to-slice for optionals. codegen renders the same file from
+// schema/v0-1-0.json and "codegen -lang go -check" fails when the two drift,
+// so update this file whenever the schema changes.
 package types
 
 // Statement is the normalized, language-agnostic description of a read
 type Statement struct {
-	Query      *Query      `json:"query,omitempty"`
-	Pagination *Pagination `json:"pagination,omitempty"`
-	GroupBy    *[]string   `json:"group_by,omitempty"`
-	Having     *Filter     `json:"having,omitempty"`
-	Includes   []Include   `json:"includes,omitempty"`
-	ORMVersion *string     `json:"orm_version,omitempty"` // diagnostic only
-	SDKVersion *string     `json:"sdk_version,omitempty"`
+	Query       *Query      `json:"query,omitempty"`
+	Pagination  *Pagination `json:"pagination,omitempty"`
+	GroupBy     *[]string   `json:"group_by,omitempty"`
+	Having      *Filter     `json:"having,omitempty"`
+	Includes    []Include   `json:"includes,omitempty"`
+	ORMVersion  *string     `json:"orm_version,omitempty"` // diagnostic only
+	SDKVersion  *string     `json:"sdk_version,omitempty"`
+	SpecVersion *string     `json:"spec_version,omitempty"` // spec version the SDK emitted; absent means 0.1.0
+	Tenant      *string     `json:"tenant,omitempty"`       // tenant the read is scoped to; part of the shape ID
+	Policy      *Filter     `json:"policy,omitempty"`       // row-level visibility predicate; ANDed with the root where
 }
 
 type Query struct {
@@ -25,6 +29,57 @@ type Query struct {
 	Limit    *int       `json:"limit,omitempty"`
 	Offset   *int       `json:"offset,omitempty"`
 	Distinct *[]string  `json:"distinct,omitempty"`
+
+	Aggregations *[]Aggregate `json:"aggregations,omitempty"` // aggregate projections, typically with Statement.GroupBy
+	Joins        *[]Join      `json:"joins,omitempty"`        // relations joined into the result rows
+	Windows      *[]Window    `json:"windows,omitempty"`      // window function columns
+	DistinctOn   *DistinctOn  `json:"distinct_on,omitempty"`  // one row per distinct combination, picked by its own order
+	Deleted      *string      `json:"deleted,omitempty"`      // soft-deleted rows to read: "include" or "only"; live rows when nil
+}
+
+// Aggregate is a typed aggregate projection such as COUNT(*) or SUM(views).
+type Aggregate struct {
+	Func  string  `json:"func"`            // "count" | "sum" | "avg" | "min" | "max"
+	Field *string `json:"field,omitempty"` // nil only for count, meaning COUNT(*)
+	Alias *string `json:"alias,omitempty"` // result column; defaults to "<func>" or "<func>_<field>"
+}
+
+// Join joins a relation of the query's model into its result rows. Unlike an
+// Include, which loads related records as a nested result, a join flattens
+// them into the parent rows; an inner join also drops parents without a match.
+type Join struct {
+	Relation string    `json:"relation"`
+	Kind     string    `json:"kind"`             // "inner" | "left"
+	On       *Filter   `json:"on,omitempty"`     // conditions on the joined model's fields
+	Fields   *[]string `json:"fields,omitempty"` // joined fields to project; nil projects none
+}
+
+// Window is a window function column such as
+// ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC).
+type Window struct {
+	Func        string       `json:"func"`            // "row_number" | "rank" | "dense_rank" | "count" | "sum" | "avg" | "min" | "max"
+	Field       *string      `json:"field,omitempty"` // sum, avg, min and max; nil for count means COUNT(*)
+	PartitionBy *[]string    `json:"partition_by,omitempty"`
+	OrderBy     *[]OrderBy   `json:"order_by,omitempty"`
+	Frame       *WindowFrame `json:"frame,omitempty"` // nil uses the SQL default frame
+	Alias       string       `json:"alias"`
+}
+
+// WindowFrame bounds the rows an aggregate window function covers, as
+// offsets from the current row.
+type WindowFrame struct {
+	Unit  string `json:"unit"`            // "rows" | "range"
+	Start *int   `json:"start,omitempty"` // negative precedes the current row; nil is unbounded preceding
+	End   *int   `json:"end,omitempty"`   // nil is unbounded following
+}
+
+// DistinctOn keeps the first row, in OrderBy order, of each combination of
+// Fields. OrderBy starts with one entry for each of Fields. It is separate
+// from Query.OrderBy, which sorts the kept rows, so the row kept need not
+// be the first in listing order; Query.Distinct picks by Query.OrderBy.
+type DistinctOn struct {
+	Fields  []string  `json:"fields"`
+	OrderBy []OrderBy `json:"order_by"`
 }
 
 // Include defines nested relation loading and optional relation-based filtering.
@@ -32,9 +87,17 @@ type Query struct {
 // When Kind is set, this filters the parent records based on the relation.
 // When Fields is nil/empty and Kind is set, this filters without loading data.
 type Include struct {
-	Query    *Query    `json:"query,omitempty"`
-	Kind     *string   `json:"kind,omitempty"`     // "some" | "every" | "none" - filters parent by relation
-	Includes []Include `json:"includes,omitempty"` // nested includes
+	Query    *Query         `json:"query,omitempty"`
+	Kind     *string        `json:"kind,omitempty"`     // "some" | "every" | "none" | "count" - filters parent by relation
+	Includes []Include      `json:"includes,omitempty"` // nested includes
+	Count    *RelationCount `json:"count,omitempty"`    // set exactly when Kind is "count"
+}
+
+// RelationCount filters parents by how many relation rows match the
+// include's where, as in users with more than 5 posts.
+type RelationCount struct {
+	Op    string `json:"op"` // "eq" | "ne" | "gt" | "gte" | "lt" | "lte"
+	Value int    `json:"value"`
 }
 
 // Filter composes predicates with boolean logic
@@ -51,6 +114,21 @@ type Condition struct {
 	FieldPath []string `json:"field_path,omitempty"`
 	Op        string   `json:"op"`
 	Value     any      `json:"value,omitempty"`
+	Subquery  *Query   `json:"subquery,omitempty"` // inQuery and existsQuery only
+}
+
+// GeoPoint is a WGS 84 location, the value geospatial operators compare
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// GeoShape is a circle, with Center and Radius, or a polygon. It is the value
+// of withinRadius (a circle) and intersects conditions.
+type GeoShape struct {
+	Center  *GeoPoint  `json:"center,omitempty"`
+	Radius  *float64   `json:"radius,omitempty"`  // meters
+	Polygon []GeoPoint `json:"polygon,omitempty"` // implicitly closed
 }
 
 // OrderBy defines field ordering
@@ -76,6 +154,15 @@ type Pagination struct {
 type Mutation struct {
 	TxID    *string  `json:"tx_id,omitempty"`
 	Changes []Change `json:"changes"`
+	Tenant  *string  `json:"tenant,omitempty"` // tenant whose rows are written; any tenant's when nil
+}
+
+// Transaction groups the mutations of one source commit. Engines apply them
+// atomically, and reject a Seq at or below the last one they applied.
+type Transaction struct {
+	Seq         int        `json:"seq"`                    // strictly increasing per source
+	CommittedAt *string    `json:"committed_at,omitempty"` // RFC 3339 instant
+	Mutations   []Mutation `json:"mutations"`
 }
 
 // Change represents a single mutation operation (insert/update/delete)
@@ -88,12 +175,22 @@ type Change struct {
 
 // Dependencies tracks what a read depends on (engine output)
 type Dependencies struct {
+	SpecVersion *string `json:"spec_version,omitempty"` // format version; absent means 0.1.0
+
 	ShapeID  string              `json:"shape_id"`
-	Records  map[string][]string `json:"records"`
+	Records  map[string][]string `json:"records"` // record IDs by model; see RecordID
 	Filters  []Filter            `json:"filters"`
 	Includes []Include           `json:"includes"` // includes with Kind set
 	LastRow  *PaginationBoundary `json:"last_row,omitempty"`
 	GroupBy  *GroupByKV          `json:"group_by,omitempty"`
+	// TTL and ValidUntil bound staleness for sources whose changes never
+	// arrive as Mutations (external APIs, views).
+	TTL             *int     `json:"ttl,omitempty"`               // seconds after registration
+	ValidUntil      *string  `json:"valid_until,omitempty"`       // RFC 3339 instant
+	WindowFields    []string `json:"window_fields,omitempty"`     // fields the root query's windows depend on
+	SoftDeleteField *string  `json:"soft_delete_field,omitempty"` // root model's soft-delete field, unless the root query reads every row
+	Tenant          *string  `json:"tenant,omitempty"`            // tenant of the statement; other tenants' mutations never invalidate
+	Policy          *Filter  `json:"policy,omitempty"`            // policy of the statement, tracked apart from filters
 }
 
 // PaginationBoundary tracks the last included row for paginated queries
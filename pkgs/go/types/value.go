@@ -0,0 +1,128 @@
+package types
+
+import (
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+)
+
+// Value coerces a Go-native value into the JSON-safe canonical representation
+// used for Condition.Value and KV.Value, so that equivalent values always
+// produce the same shape ID regardless of how the caller serialized them.
+//
+// Encodings:
+//   - time.Time: RFC 3339 string in UTC, with fractional seconds only when
+//     non-zero (e.g., "2024-01-15T10:30:00Z", "2024-01-15T10:30:00.5Z")
+//   - []byte: standard base64 string with padding
+//   - encoding.TextMarshaler (uuid.UUID, decimal.Decimal, ...): its text form
+//     (lower-case hyphenated UUIDs, plain decimal strings)
+//   - slices, arrays and string-keyed maps: coerced element by element into
+//     []any and map[string]any
+//   - GeoPoint and GeoShape: their JSON objects as map[string]any
+//   - json.Number (from a json.Decoder with UseNumber): int64 when it is an
+//     integer that fits, float64 otherwise
+//   - nil, strings, booleans and numbers are returned unchanged
+//
+// Returns an error for NaN or infinite floats, non-string map keys, and
+// values with no JSON representation (channels, functions, complex numbers).
+func Value(v any) (any, error) {
+	switch val := v.(type) {
+	case nil, string, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return val, nil
+	case float32:
+		return checkFloat(float64(val), v)
+	case float64:
+		return checkFloat(val, v)
+	case time.Time:
+		return val.UTC().Format(time.RFC3339Nano), nil
+	case *time.Time:
+		if val == nil {
+			return nil, nil
+		}
+		return val.UTC().Format(time.RFC3339Nano), nil
+	case []byte:
+		return base64.StdEncoding.EncodeToString(val), nil
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i, nil
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("types.Value: json.Number %q: %w", string(val), err)
+		}
+		return checkFloat(f, f)
+	case GeoPoint:
+		return Value(val.value())
+	case GeoShape:
+		return Value(val.value())
+	case encoding.TextMarshaler:
+		text, err := val.MarshalText()
+		if err != nil {
+			return nil, fmt.Errorf("types.Value: %T: %w", v, err)
+		}
+		return string(text), nil
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer:
+		if rv.IsNil() {
+			return nil, nil
+		}
+		return Value(rv.Elem().Interface())
+	case reflect.String:
+		return rv.String(), nil
+	case reflect.Bool:
+		return rv.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), nil
+	case reflect.Float32, reflect.Float64:
+		return checkFloat(rv.Float(), rv.Float())
+	case reflect.Slice, reflect.Array:
+		if rv.Kind() == reflect.Slice && rv.IsNil() {
+			return nil, nil
+		}
+		out := make([]any, rv.Len())
+		for i := range out {
+			elem, err := Value(rv.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			out[i] = elem
+		}
+		return out, nil
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("types.Value: map key must be a string, got %s", rv.Type().Key())
+		}
+		if rv.IsNil() {
+			return nil, nil
+		}
+		out := make(map[string]any, rv.Len())
+		iter := rv.MapRange()
+		for iter.Next() {
+			elem, err := Value(iter.Value().Interface())
+			if err != nil {
+				return nil, err
+			}
+			out[iter.Key().String()] = elem
+		}
+		return out, nil
+	}
+
+	return nil, fmt.Errorf("types.Value: unsupported type %T", v)
+}
+
+// checkFloat returns out unless f has no JSON representation.
+func checkFloat(f float64, out any) (any, error) {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return nil, fmt.Errorf("types.Value: %v is not representable in JSON", f)
+	}
+	return out, nil
+}
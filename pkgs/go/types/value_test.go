@@ -0,0 +1,46 @@
+package types_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+func TestValueJSONNumber(t *testing.T) {
+	tcs := []struct {
+		in   json.Number
+		want any
+	}{
+		{"42", int64(42)},
+		{"-7", int64(-7)},
+		{"1.5", 1.5},
+		{"1e3", 1000.0},
+		{"18446744073709551616", 18446744073709551616.0},
+	}
+	for _, tt := range tcs {
+		got, err := types.Value(tt.in)
+		if err != nil {
+			t.Fatalf("Value(%s) failed: %v", tt.in, err)
+		}
+		if got != tt.want {
+			t.Errorf("Value(%s) = %#v, want %#v", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := types.Value(json.Number("forty")); err == nil {
+		t.Error("Value of a malformed json.Number should fail")
+	}
+
+	// Conditions built from UseNumber input compare as numbers
+	dec := json.NewDecoder(strings.NewReader(`{"views": 100}`))
+	dec.UseNumber()
+	var row map[string]any
+	if err := dec.Decode(&row); err != nil {
+		t.Fatal(err)
+	}
+	if c := types.Gt("views", row["views"]); c.Value != int64(100) {
+		t.Errorf("Gt(views, json.Number) value = %#v, want int64(100)", c.Value)
+	}
+}
@@ -0,0 +1,37 @@
+package types
+
+// Window constructors build Windows named by their result column. Use
+// PartitionedBy, OrderedBy and Within to shape the window.
+
+// RowNumber numbers the rows of each partition from 1, in order_by order.
+func RowNumber(alias string) Window { return Window{Func: "row_number", Alias: alias} }
+
+// Rank ranks the rows of each partition, leaving gaps after ties.
+func Rank(alias string) Window { return Window{Func: "rank", Alias: alias} }
+
+// DenseRank ranks the rows of each partition without gaps after ties.
+func DenseRank(alias string) Window { return Window{Func: "dense_rank", Alias: alias} }
+
+// WindowOf computes the aggregate over the window of each row, as in a
+// running total: WindowOf(Sum("views"), "running_views").
+func WindowOf(a Aggregate, alias string) Window {
+	return Window{Func: a.Func, Field: a.Field, Alias: alias}
+}
+
+// PartitionedBy returns a copy of the window partitioned by fields.
+func (w Window) PartitionedBy(fields ...string) Window {
+	w.PartitionBy = &fields
+	return w
+}
+
+// OrderedBy returns a copy of the window ordering its partitions by orderBy.
+func (w Window) OrderedBy(orderBy ...OrderBy) Window {
+	w.OrderBy = &orderBy
+	return w
+}
+
+// Within returns a copy of the window covering frame.
+func (w Window) Within(frame WindowFrame) Window {
+	w.Frame = &frame
+	return w
+}
@@ -0,0 +1,402 @@
+package typesv2
+
+import (
+	"encoding/json"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// FromV1 converts a types.Statement. A nil statement converts to nil.
+func FromV1(s *types.Statement) *Statement {
+	if s == nil {
+		return nil
+	}
+	return &Statement{
+		Query:       queryFromV1(s.Query),
+		Pagination:  paginationFromV1(s.Pagination),
+		GroupBy:     FromPtr(s.GroupBy),
+		Having:      filterFromV1(s.Having),
+		Includes:    includesFromV1(s.Includes),
+		ORMVersion:  FromPtr(s.ORMVersion),
+		SDKVersion:  FromPtr(s.SDKVersion),
+		SpecVersion: FromPtr(s.SpecVersion),
+		Tenant:      FromPtr(s.Tenant),
+		Policy:      filterFromV1(s.Policy),
+	}
+}
+
+// V1 converts the statement back to a types.Statement. A nil statement
+// converts to nil.
+func (s *Statement) V1() *types.Statement {
+	if s == nil {
+		return nil
+	}
+	return &types.Statement{
+		Query:       s.Query.V1(),
+		Pagination:  s.Pagination.V1(),
+		GroupBy:     s.GroupBy.Ptr(),
+		Having:      s.Having.V1(),
+		Includes:    includesToV1(s.Includes),
+		ORMVersion:  s.ORMVersion.Ptr(),
+		SDKVersion:  s.SDKVersion.Ptr(),
+		SpecVersion: s.SpecVersion.Ptr(),
+		Tenant:      s.Tenant.Ptr(),
+		Policy:      s.Policy.V1(),
+	}
+}
+
+// MarshalJSON encodes the statement exactly as the equivalent types.Statement.
+func (s Statement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.V1())
+}
+
+// UnmarshalJSON decodes the statement via types.Statement.
+func (s *Statement) UnmarshalJSON(data []byte) error {
+	var v1 types.Statement
+	if err := json.Unmarshal(data, &v1); err != nil {
+		return err
+	}
+	*s = *FromV1(&v1)
+	return nil
+}
+
+func queryFromV1(q *types.Query) *Query {
+	if q == nil {
+		return nil
+	}
+	var orderBy Optional[[]OrderBy]
+	if q.OrderBy != nil {
+		converted := make([]OrderBy, len(*q.OrderBy))
+		for i, ob := range *q.OrderBy {
+			converted[i] = orderByFromV1(ob)
+		}
+		orderBy = Some(converted)
+	}
+	var aggregations Optional[[]Aggregate]
+	if q.Aggregations != nil {
+		converted := make([]Aggregate, len(*q.Aggregations))
+		for i, a := range *q.Aggregations {
+			converted[i] = aggregateFromV1(a)
+		}
+		aggregations = Some(converted)
+	}
+	var joins Optional[[]Join]
+	if q.Joins != nil {
+		converted := make([]Join, len(*q.Joins))
+		for i, j := range *q.Joins {
+			converted[i] = joinFromV1(j)
+		}
+		joins = Some(converted)
+	}
+	var windows Optional[[]Window]
+	if q.Windows != nil {
+		converted := make([]Window, len(*q.Windows))
+		for i, w := range *q.Windows {
+			converted[i] = windowFromV1(w)
+		}
+		windows = Some(converted)
+	}
+	return &Query{
+		Model:        q.Model,
+		Fields:       FromPtr(q.Fields),
+		Where:        filterFromV1(q.Where),
+		OrderBy:      orderBy,
+		Limit:        FromPtr(q.Limit),
+		Offset:       FromPtr(q.Offset),
+		Distinct:     FromPtr(q.Distinct),
+		Aggregations: aggregations,
+		Joins:        joins,
+		Windows:      windows,
+		DistinctOn:   distinctOnFromV1(q.DistinctOn),
+		Deleted:      FromPtr(q.Deleted),
+	}
+}
+
+// V1 converts the query back to a types.Query.
+func (q *Query) V1() *types.Query {
+	if q == nil {
+		return nil
+	}
+	var orderBy *[]types.OrderBy
+	if obs, ok := q.OrderBy.Get(); ok {
+		converted := make([]types.OrderBy, len(obs))
+		for i, ob := range obs {
+			converted[i] = ob.V1()
+		}
+		orderBy = &converted
+	}
+	var aggregations *[]types.Aggregate
+	if aggs, ok := q.Aggregations.Get(); ok {
+		converted := make([]types.Aggregate, len(aggs))
+		for i, a := range aggs {
+			converted[i] = a.V1()
+		}
+		aggregations = &converted
+	}
+	var joins *[]types.Join
+	if js, ok := q.Joins.Get(); ok {
+		converted := make([]types.Join, len(js))
+		for i, j := range js {
+			converted[i] = j.V1()
+		}
+		joins = &converted
+	}
+	var windows *[]types.Window
+	if ws, ok := q.Windows.Get(); ok {
+		converted := make([]types.Window, len(ws))
+		for i, w := range ws {
+			converted[i] = w.V1()
+		}
+		windows = &converted
+	}
+	return &types.Query{
+		Model:        q.Model,
+		Fields:       q.Fields.Ptr(),
+		Where:        q.Where.V1(),
+		OrderBy:      orderBy,
+		Limit:        q.Limit.Ptr(),
+		Offset:       q.Offset.Ptr(),
+		Distinct:     q.Distinct.Ptr(),
+		Aggregations: aggregations,
+		Joins:        joins,
+		Windows:      windows,
+		DistinctOn:   q.DistinctOn.V1(),
+		Deleted:      q.Deleted.Ptr(),
+	}
+}
+
+func includesFromV1(includes []types.Include) []Include {
+	if includes == nil {
+		return nil
+	}
+	converted := make([]Include, len(includes))
+	for i, inc := range includes {
+		converted[i] = Include{
+			Query:    queryFromV1(inc.Query),
+			Kind:     FromPtr(inc.Kind),
+			Includes: includesFromV1(inc.Includes),
+			Count:    inc.Count,
+		}
+	}
+	return converted
+}
+
+func includesToV1(includes []Include) []types.Include {
+	if includes == nil {
+		return nil
+	}
+	converted := make([]types.Include, len(includes))
+	for i, inc := range includes {
+		converted[i] = types.Include{
+			Query:    inc.Query.V1(),
+			Kind:     inc.Kind.Ptr(),
+			Includes: includesToV1(inc.Includes),
+			Count:    inc.Count,
+		}
+	}
+	return converted
+}
+
+func filterFromV1(f *types.Filter) *Filter {
+	if f == nil {
+		return nil
+	}
+	return &Filter{
+		And:        filtersFromV1(f.And),
+		Or:         filtersFromV1(f.Or),
+		Not:        filterFromV1(f.Not),
+		Conditions: FromPtr(f.Conditions),
+	}
+}
+
+func filtersFromV1(fs *[]types.Filter) Optional[[]Filter] {
+	if fs == nil {
+		return None[[]Filter]()
+	}
+	converted := make([]Filter, len(*fs))
+	for i := range *fs {
+		converted[i] = *filterFromV1(&(*fs)[i])
+	}
+	return Some(converted)
+}
+
+// V1 converts the filter back to a types.Filter.
+func (f *Filter) V1() *types.Filter {
+	if f == nil {
+		return nil
+	}
+	return &types.Filter{
+		And:        filtersToV1(f.And),
+		Or:         filtersToV1(f.Or),
+		Not:        f.Not.V1(),
+		Conditions: f.Conditions.Ptr(),
+	}
+}
+
+func filtersToV1(fs Optional[[]Filter]) *[]types.Filter {
+	filters, ok := fs.Get()
+	if !ok {
+		return nil
+	}
+	converted := make([]types.Filter, len(filters))
+	for i := range filters {
+		converted[i] = *filters[i].V1()
+	}
+	return &converted
+}
+
+func orderByFromV1(ob types.OrderBy) OrderBy {
+	return OrderBy{
+		Field:         ob.Field,
+		Descending:    FromPtr(ob.Descending),
+		NullsFirst:    FromPtr(ob.NullsFirst),
+		CaseSensitive: FromPtr(ob.CaseSensitive),
+	}
+}
+
+// V1 converts the ordering back to a types.OrderBy.
+func (ob OrderBy) V1() types.OrderBy {
+	return types.OrderBy{
+		Field:         ob.Field,
+		Descending:    ob.Descending.Ptr(),
+		NullsFirst:    ob.NullsFirst.Ptr(),
+		CaseSensitive: ob.CaseSensitive.Ptr(),
+	}
+}
+
+func aggregateFromV1(a types.Aggregate) Aggregate {
+	return Aggregate{
+		Func:  a.Func,
+		Field: FromPtr(a.Field),
+		Alias: FromPtr(a.Alias),
+	}
+}
+
+// V1 converts the aggregate back to a types.Aggregate.
+func (a Aggregate) V1() types.Aggregate {
+	return types.Aggregate{
+		Func:  a.Func,
+		Field: a.Field.Ptr(),
+		Alias: a.Alias.Ptr(),
+	}
+}
+
+func joinFromV1(j types.Join) Join {
+	return Join{
+		Relation: j.Relation,
+		Kind:     j.Kind,
+		On:       filterFromV1(j.On),
+		Fields:   FromPtr(j.Fields),
+	}
+}
+
+// V1 converts the join back to a types.Join.
+func (j Join) V1() types.Join {
+	return types.Join{
+		Relation: j.Relation,
+		Kind:     j.Kind,
+		On:       j.On.V1(),
+		Fields:   j.Fields.Ptr(),
+	}
+}
+
+func windowFromV1(w types.Window) Window {
+	var orderBy Optional[[]OrderBy]
+	if w.OrderBy != nil {
+		converted := make([]OrderBy, len(*w.OrderBy))
+		for i, ob := range *w.OrderBy {
+			converted[i] = orderByFromV1(ob)
+		}
+		orderBy = Some(converted)
+	}
+	var frame *WindowFrame
+	if w.Frame != nil {
+		frame = &WindowFrame{Unit: w.Frame.Unit, Start: FromPtr(w.Frame.Start), End: FromPtr(w.Frame.End)}
+	}
+	return Window{
+		Func:        w.Func,
+		Field:       FromPtr(w.Field),
+		PartitionBy: FromPtr(w.PartitionBy),
+		OrderBy:     orderBy,
+		Frame:       frame,
+		Alias:       w.Alias,
+	}
+}
+
+// V1 converts the window back to a types.Window.
+func (w Window) V1() types.Window {
+	var orderBy *[]types.OrderBy
+	if obs, ok := w.OrderBy.Get(); ok {
+		converted := make([]types.OrderBy, len(obs))
+		for i, ob := range obs {
+			converted[i] = ob.V1()
+		}
+		orderBy = &converted
+	}
+	var frame *types.WindowFrame
+	if w.Frame != nil {
+		frame = &types.WindowFrame{Unit: w.Frame.Unit, Start: w.Frame.Start.Ptr(), End: w.Frame.End.Ptr()}
+	}
+	return types.Window{
+		Func:        w.Func,
+		Field:       w.Field.Ptr(),
+		PartitionBy: w.PartitionBy.Ptr(),
+		OrderBy:     orderBy,
+		Frame:       frame,
+		Alias:       w.Alias,
+	}
+}
+
+func distinctOnFromV1(d *types.DistinctOn) *DistinctOn {
+	if d == nil {
+		return nil
+	}
+	var orderBy []OrderBy
+	if d.OrderBy != nil {
+		orderBy = make([]OrderBy, len(d.OrderBy))
+		for i, ob := range d.OrderBy {
+			orderBy[i] = orderByFromV1(ob)
+		}
+	}
+	return &DistinctOn{Fields: d.Fields, OrderBy: orderBy}
+}
+
+// V1 converts the distinct-on clause back to a types.DistinctOn.
+func (d *DistinctOn) V1() *types.DistinctOn {
+	if d == nil {
+		return nil
+	}
+	var orderBy []types.OrderBy
+	if d.OrderBy != nil {
+		orderBy = make([]types.OrderBy, len(d.OrderBy))
+		for i, ob := range d.OrderBy {
+			orderBy[i] = ob.V1()
+		}
+	}
+	return &types.DistinctOn{Fields: d.Fields, OrderBy: orderBy}
+}
+
+func paginationFromV1(p *types.Pagination) *Pagination {
+	if p == nil {
+		return nil
+	}
+	return &Pagination{
+		First:  FromPtr(p.First),
+		Last:   FromPtr(p.Last),
+		After:  FromPtr(p.After),
+		Before: FromPtr(p.Before),
+	}
+}
+
+// V1 converts the pagination back to a types.Pagination.
+func (p *Pagination) V1() *types.Pagination {
+	if p == nil {
+		return nil
+	}
+	return &types.Pagination{
+		First:  p.First.Ptr(),
+		Last:   p.Last.Ptr(),
+		After:  p.After.Ptr(),
+		Before: p.Before.Ptr(),
+	}
+}
@@ -0,0 +1,65 @@
+package typesv2_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/typesv2"
+)
+
+// Example demonstrates building a statement without pointer helpers
+func Example() {
+	stmt := typesv2.Statement{
+		Query: &typesv2.Query{
+			Model: "posts",
+			OrderBy: typesv2.Some([]typesv2.OrderBy{
+				{Field: "createdAt", Descending: typesv2.Some(true)},
+			}),
+			Limit: typesv2.Some(10),
+		},
+		Pagination: &typesv2.Pagination{First: typesv2.Some(20)},
+	}
+
+	data, _ := json.Marshal(stmt)
+	fmt.Println(string(data))
+	// Output:
+	// {"query":{"model":"posts","order_by":[{"field":"createdAt","descending":true}],"limit":10},"pagination":{"first":20}}
+}
+
+// ExampleFromV1 demonstrates lossless conversion to and from types.Statement
+func ExampleFromV1() {
+	limit, deleted := 5, "include"
+	v1 := &types.Statement{
+		Query: &types.Query{
+			Model:  "users",
+			Fields: &[]string{},
+			Limit:  &limit,
+			Aggregations: &[]types.Aggregate{
+				types.Count("").As("n"),
+				types.Max("age"),
+			},
+			Joins: &[]types.Join{
+				types.InnerJoin("team", types.Eq("active", true)).Select("name"),
+			},
+			Windows: &[]types.Window{
+				types.RowNumber("seniority").PartitionedBy("team").OrderedBy(types.OrderBy{Field: "joinedAt"}),
+			},
+			DistinctOn: &types.DistinctOn{Fields: []string{"team"}, OrderBy: []types.OrderBy{{Field: "team"}, {Field: "joinedAt"}}},
+			Deleted:    &deleted,
+			Where: &types.Filter{
+				Or: &[]types.Filter{{Conditions: &[]types.Condition{types.Eq("role", "admin")}}},
+			},
+		},
+		Includes: []types.Include{{Query: &types.Query{Model: "posts"}}},
+	}
+
+	v2 := typesv2.FromV1(v1)
+	limitV2, _ := v2.Query.Limit.Get()
+	fmt.Println("limit:", limitV2)
+	fmt.Println("round trip equal:", reflect.DeepEqual(v1, v2.V1()))
+	// Output:
+	// limit: 5
+	// round trip equal: true
+}
@@ -0,0 +1,53 @@
+package typesv2
+
+// Optional holds a value that may be absent. The zero Optional is absent, so
+// struct literals only mention the fields that are set.
+type Optional[T any] struct {
+	value T
+	set   bool
+}
+
+// Some returns an Optional holding v.
+func Some[T any](v T) Optional[T] {
+	return Optional[T]{value: v, set: true}
+}
+
+// None returns an absent Optional.
+func None[T any]() Optional[T] {
+	return Optional[T]{}
+}
+
+// FromPtr returns an Optional holding *p, or an absent Optional if p is nil.
+func FromPtr[T any](p *T) Optional[T] {
+	if p == nil {
+		return Optional[T]{}
+	}
+	return Some(*p)
+}
+
+// IsSet reports whether the Optional holds a value.
+func (o Optional[T]) IsSet() bool {
+	return o.set
+}
+
+// Get returns the value and whether it is set.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.set
+}
+
+// OrElse returns the value, or def if it is absent.
+func (o Optional[T]) OrElse(def T) T {
+	if !o.set {
+		return def
+	}
+	return o.value
+}
+
+// Ptr returns a pointer to a copy of the value, or nil if it is absent.
+func (o Optional[T]) Ptr() *T {
+	if !o.set {
+		return nil
+	}
+	v := o.value
+	return &v
+}
@@ -0,0 +1,123 @@
+// Package typesv2 is an EXPERIMENTAL variant of the types package that uses a
+// generic Optional[T] instead of pointer fields for optional scalars and
+// lists, to evaluate an API without intPtr/boolPtr helpers.
+//
+// Nested structs (Query, Filter, Pagination) stay pointers, since Filter is
+// recursive and nil already reads naturally for them. Every type converts
+// losslessly to and from its types counterpart, and marshals to exactly the
+// same JSON, so shape IDs are unaffected.
+//
+// This package may change or be removed without notice.
+package typesv2
+
+import (
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Statement is the Optional-based counterpart of types.Statement
+type Statement struct {
+	Query       *Query
+	Pagination  *Pagination
+	GroupBy     Optional[[]string]
+	Having      *Filter
+	Includes    []Include
+	ORMVersion  Optional[string] // diagnostic only
+	SDKVersion  Optional[string]
+	SpecVersion Optional[string] // spec version the SDK emitted; absent means 0.1.0
+	Tenant      Optional[string] // tenant the read is scoped to; part of the shape ID
+	Policy      *Filter          // row-level visibility predicate; ANDed with the root where
+}
+
+// Query is the Optional-based counterpart of types.Query
+type Query struct {
+	Model    string
+	Fields   Optional[[]string]
+	Where    *Filter
+	OrderBy  Optional[[]OrderBy]
+	Limit    Optional[int]
+	Offset   Optional[int]
+	Distinct Optional[[]string]
+
+	Aggregations Optional[[]Aggregate]
+	Joins        Optional[[]Join]
+	Windows      Optional[[]Window]
+	DistinctOn   *DistinctOn
+	Deleted      Optional[string]
+}
+
+// Aggregate is the Optional-based counterpart of types.Aggregate
+type Aggregate struct {
+	Func  string
+	Field Optional[string]
+	Alias Optional[string]
+}
+
+// Join is the Optional-based counterpart of types.Join
+type Join struct {
+	Relation string
+	Kind     string // "inner" | "left"
+	On       *Filter
+	Fields   Optional[[]string]
+}
+
+// Window is the Optional-based counterpart of types.Window
+type Window struct {
+	Func        string
+	Field       Optional[string]
+	PartitionBy Optional[[]string]
+	OrderBy     Optional[[]OrderBy]
+	Frame       *WindowFrame
+	Alias       string
+}
+
+// WindowFrame is the Optional-based counterpart of types.WindowFrame
+type WindowFrame struct {
+	Unit  string // "rows" | "range"
+	Start Optional[int]
+	End   Optional[int]
+}
+
+// DistinctOn is the Optional-based counterpart of types.DistinctOn
+type DistinctOn struct {
+	Fields  []string
+	OrderBy []OrderBy
+}
+
+// Include is the Optional-based counterpart of types.Include
+type Include struct {
+	Query    *Query
+	Kind     Optional[string] // "some" | "every" | "none" | "count"
+	Includes []Include
+	Count    *RelationCount
+}
+
+// RelationCount is shared with types, having no optional fields.
+type RelationCount = types.RelationCount
+
+// Filter is the Optional-based counterpart of types.Filter
+type Filter struct {
+	And        Optional[[]Filter]
+	Or         Optional[[]Filter]
+	Not        *Filter
+	Conditions Optional[[]Condition]
+}
+
+// Condition is shared with types: its only pointer field, Subquery, keeps its
+// *types.Query so conditions convert without copying.
+type Condition = types.Condition
+
+// OrderBy is the Optional-based counterpart of types.OrderBy
+type OrderBy struct {
+	Field         string
+	Descending    Optional[bool]
+	NullsFirst    Optional[bool]
+	CaseSensitive Optional[bool]
+}
+
+// Pagination is the Optional-based counterpart of types.Pagination
+type Pagination struct {
+	First  Optional[int]
+	Last   Optional[int]
+	After  Optional[string]
+	Before Optional[string]
+}
@@ -0,0 +1,93 @@
+// Package upgrade migrates stored Dependencies documents to the current spec
+// version, so long-lived shape registries survive format changes without
+// purging every entry.
+//
+// Migrations operate on the decoded JSON object rather than types.Dependencies
+// because older documents may carry fields the current types no longer have.
+package upgrade
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// Step migrates a document from one spec version to the next.
+type Step struct {
+	From  string
+	To    string
+	Apply func(doc map[string]any) error
+}
+
+// Steps is the built-in migration chain, ordered by From. Versions with no
+// step between them are format-compatible and only get restamped.
+var Steps = []Step{}
+
+// Dependencies upgrades a stored Dependencies document using Steps.
+//
+// See DependenciesWith for details.
+func Dependencies(data []byte) (*types.Dependencies, error) {
+	return DependenciesWith(data, Steps)
+}
+
+// DependenciesWith upgrades a stored Dependencies document to
+// types.SpecVersion by applying every step whose From matches the document's
+// version in turn. Documents without spec_version are treated as 0.1.0. The
+// result is stamped with types.SpecVersion.
+//
+// Returns an error if the document is newer than types.SpecVersion, a step
+// fails, or the upgraded document has fields types.Dependencies does not know.
+func DependenciesWith(data []byte, steps []Step) (*types.Dependencies, error) {
+	var doc map[string]any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("upgrade: decode dependencies: %w", err)
+	}
+	if doc == nil {
+		return nil, fmt.Errorf("upgrade: dependencies document is null")
+	}
+
+	version := "0.1.0"
+	if v, ok := doc["spec_version"]; ok {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("upgrade: spec_version must be a string")
+		}
+		version = s
+	}
+
+	cmp, err := types.CompareSpecVersions(version, types.SpecVersion)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: %w", err)
+	}
+	if cmp > 0 {
+		return nil, fmt.Errorf("upgrade: spec_version %s is newer than supported %s", version, types.SpecVersion)
+	}
+
+	for _, step := range steps {
+		if step.From != version {
+			continue
+		}
+		if err := step.Apply(doc); err != nil {
+			return nil, fmt.Errorf("upgrade: %s -> %s: %w", step.From, step.To, err)
+		}
+		version = step.To
+	}
+	doc["spec_version"] = types.SpecVersion
+
+	upgraded, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade: encode dependencies: %w", err)
+	}
+
+	var deps types.Dependencies
+	dec = json.NewDecoder(bytes.NewReader(upgraded))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&deps); err != nil {
+		return nil, fmt.Errorf("upgrade: dependencies at %s: %w", version, err)
+	}
+	return &deps, nil
+}
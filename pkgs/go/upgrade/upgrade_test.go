@@ -0,0 +1,90 @@
+package upgrade_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/upgrade"
+)
+
+const shapeID = "s_0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestDependenciesStampsUnversionedDocuments(t *testing.T) {
+	deps, err := upgrade.Dependencies([]byte(`{"shape_id":"` + shapeID + `","records":{"posts":["1"]},"filters":[],"includes":[]}`))
+	if err != nil {
+		t.Fatalf("Dependencies: %v", err)
+	}
+	if deps.GetSpecVersion() != types.SpecVersion || deps.SpecVersion == nil {
+		t.Errorf("spec_version = %v, want %s", deps.SpecVersion, types.SpecVersion)
+	}
+	if len(deps.Records["posts"]) != 1 {
+		t.Errorf("records not preserved: %v", deps.Records)
+	}
+}
+
+func TestDependenciesWithAppliesChain(t *testing.T) {
+	steps := []upgrade.Step{
+		{From: "0.0.1", To: "0.0.2", Apply: func(doc map[string]any) error {
+			doc["shape_id"] = doc["shapeId"]
+			delete(doc, "shapeId")
+			return nil
+		}},
+		{From: "0.0.2", To: types.SpecVersion, Apply: func(doc map[string]any) error {
+			doc["filters"] = doc["filterBounds"]
+			doc["includes"] = doc["relationBounds"]
+			delete(doc, "filterBounds")
+			delete(doc, "relationBounds")
+			return nil
+		}},
+	}
+
+	legacy := `{"spec_version":"0.0.1","shapeId":"` + shapeID + `","records":{},"filterBounds":[],"relationBounds":[],"ttl":30}`
+	deps, err := upgrade.DependenciesWith([]byte(legacy), steps)
+	if err != nil {
+		t.Fatalf("DependenciesWith: %v", err)
+	}
+	if deps.ShapeID != shapeID {
+		t.Errorf("shape_id = %q", deps.ShapeID)
+	}
+	if deps.Filters == nil || deps.Includes == nil {
+		t.Error("renamed fields were not migrated")
+	}
+	if ttl, _ := deps.GetTTL(); ttl != 30 {
+		t.Errorf("ttl = %d, want 30", ttl)
+	}
+}
+
+func TestDependenciesErrors(t *testing.T) {
+	tcs := []struct {
+		name   string
+		doc    string
+		steps  []upgrade.Step
+		errMsg string
+	}{
+		{name: "malformed json", doc: `{`, errMsg: "decode"},
+		{name: "null document", doc: `null`, errMsg: "is null"},
+		{name: "non-string version", doc: `{"spec_version":1}`, errMsg: "must be a string"},
+		{name: "malformed version", doc: `{"spec_version":"1.0"}`, errMsg: "must be X.Y.Z"},
+		{name: "newer version", doc: `{"spec_version":"99.0.0"}`, errMsg: "newer than supported"},
+		{name: "unmigrated field", doc: `{"spec_version":"0.0.1","shapeId":"x"}`, errMsg: "unknown field"},
+		{
+			name:   "failing step",
+			doc:    `{"spec_version":"0.0.1"}`,
+			steps:  []upgrade.Step{{From: "0.0.1", To: types.SpecVersion, Apply: func(map[string]any) error { return errBoom }}},
+			errMsg: "0.0.1 -> " + types.SpecVersion + ": boom",
+		},
+	}
+
+	for _, tt := range tcs {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := upgrade.DependenciesWith([]byte(tt.doc), tt.steps)
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Errorf("error = %v, want containing %q", err, tt.errMsg)
+			}
+		})
+	}
+}
+
+var errBoom = errors.New("boom")
@@ -0,0 +1,166 @@
+// Package walk provides a depth-first traversal over Statements, Mutations
+// and Filters for the IncludeKit Universal Format.
+//
+// Callbacks receive pointers into the walked value, so they may modify nodes
+// in place (e.g., anonymizers rewriting condition values). Each callback also
+// receives the node path in the same dotted form used by validation errors,
+// e.g. "statement.query.where.and[0].conditions[1]".
+package walk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+)
+
+// SkipChildren may be returned by the Query, Filter and Include callbacks to
+// skip the children of the current node. It is never returned by Walk.
+var SkipChildren = errors.New("walk: skip children")
+
+// Visitor holds typed callbacks for each node kind. Nil callbacks are skipped.
+// Any error other than SkipChildren stops the walk and is returned.
+type Visitor struct {
+	Query     func(path string, q *types.Query) error
+	Filter    func(path string, f *types.Filter) error
+	Condition func(path string, c *types.Condition) error
+	Include   func(path string, inc *types.Include) error
+	OrderBy   func(path string, ob *types.OrderBy) error
+}
+
+// Walk visits every node of the statement in depth-first pre-order: the
+// query (its where filter, order specs and join filters), the having filter,
+// the policy, then includes (each include before its query and nested includes). The
+// subquery of a condition is visited, as a query, right after the condition.
+func Walk(stmt *types.Statement, v Visitor) error {
+	if stmt == nil {
+		return nil
+	}
+	if err := walkQuery(stmt.Query, "statement.query", v); err != nil {
+		return err
+	}
+	if err := walkFilter(stmt.Having, "statement.having", v); err != nil {
+		return err
+	}
+	if err := walkFilter(stmt.Policy, "statement.policy", v); err != nil {
+		return err
+	}
+	return walkIncludes(stmt.Includes, "statement", v)
+}
+
+// WalkMutation visits the where filter of every change in the mutation.
+func WalkMutation(m *types.Mutation, v Visitor) error {
+	if m == nil {
+		return nil
+	}
+	for i := range m.Changes {
+		if err := walkFilter(m.Changes[i].Where, fmt.Sprintf("mutation.changes[%d].where", i), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WalkFilter visits the filter and its sub-filters and conditions, using path
+// as the root path.
+func WalkFilter(f *types.Filter, path string, v Visitor) error {
+	return walkFilter(f, path, v)
+}
+
+func walkQuery(q *types.Query, path string, v Visitor) error {
+	if q == nil {
+		return nil
+	}
+	if v.Query != nil {
+		if err := v.Query(path, q); err != nil {
+			return skip(err)
+		}
+	}
+	if err := walkFilter(q.Where, path+".where", v); err != nil {
+		return err
+	}
+	if q.OrderBy != nil && v.OrderBy != nil {
+		for i := range *q.OrderBy {
+			if err := v.OrderBy(fmt.Sprintf("%s.order_by[%d]", path, i), &(*q.OrderBy)[i]); err != nil {
+				return err
+			}
+		}
+	}
+	for i, j := range q.GetJoins() {
+		if err := walkFilter(j.On, fmt.Sprintf("%s.joins[%d].on", path, i), v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkFilter(f *types.Filter, path string, v Visitor) error {
+	if f == nil {
+		return nil
+	}
+	if v.Filter != nil {
+		if err := v.Filter(path, f); err != nil {
+			return skip(err)
+		}
+	}
+	if f.And != nil {
+		for i := range *f.And {
+			if err := walkFilter(&(*f.And)[i], fmt.Sprintf("%s.and[%d]", path, i), v); err != nil {
+				return err
+			}
+		}
+	}
+	if f.Or != nil {
+		for i := range *f.Or {
+			if err := walkFilter(&(*f.Or)[i], fmt.Sprintf("%s.or[%d]", path, i), v); err != nil {
+				return err
+			}
+		}
+	}
+	if err := walkFilter(f.Not, path+".not", v); err != nil {
+		return err
+	}
+	for i := range f.GetConditions() {
+		c := &(*f.Conditions)[i]
+		condPath := fmt.Sprintf("%s.conditions[%d]", path, i)
+		if v.Condition != nil {
+			if err := v.Condition(condPath, c); err != nil {
+				return err
+			}
+		}
+		if err := walkQuery(c.Subquery, condPath+".subquery", v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkIncludes(includes []types.Include, path string, v Visitor) error {
+	for i := range includes {
+		includePath := fmt.Sprintf("%s.includes[%d]", path, i)
+		include := &includes[i]
+		if v.Include != nil {
+			if err := v.Include(includePath, include); err != nil {
+				if err == SkipChildren {
+					continue
+				}
+				return err
+			}
+		}
+		if err := walkQuery(include.Query, includePath+".query", v); err != nil {
+			return err
+		}
+		if err := walkIncludes(include.Includes, includePath, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// skip converts SkipChildren into a nil error for the caller.
+func skip(err error) error {
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,150 @@
+package walk_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bold-minds/includekit-spec/go/types"
+	"github.com/bold-minds/includekit-spec/go/walk"
+)
+
+func sampleStatement() *types.Statement {
+	return &types.Statement{
+		Query: &types.Query{
+			Model: "User",
+			Where: &types.Filter{
+				And: &[]types.Filter{
+					{Conditions: &[]types.Condition{types.Eq("email", "a@example.com")}},
+				},
+				Not: &types.Filter{Conditions: &[]types.Condition{types.Eq("banned", true)}},
+				Conditions: &[]types.Condition{types.InQuery("teamId", types.Query{
+					Model:  "teams",
+					Fields: &[]string{"id"},
+					Where:  &types.Filter{Conditions: &[]types.Condition{types.Eq("plan", "pro")}},
+				})},
+			},
+			OrderBy: &[]types.OrderBy{{Field: "createdAt"}},
+			Joins:   &[]types.Join{types.InnerJoin("team", types.Eq("active", true))},
+		},
+		Policy: &types.Filter{Conditions: &[]types.Condition{types.Eq("orgId", "o1")}},
+		Includes: []types.Include{
+			{
+				Query: &types.Query{
+					Model: "posts",
+					Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("title", "Hello")}},
+				},
+				Includes: []types.Include{{Query: &types.Query{Model: "comments"}}},
+			},
+		},
+	}
+}
+
+func TestWalkVisitsAllNodesWithPaths(t *testing.T) {
+	var paths []string
+	record := func(path string) { paths = append(paths, path) }
+
+	err := walk.Walk(sampleStatement(), walk.Visitor{
+		Query:     func(path string, _ *types.Query) error { record(path); return nil },
+		Filter:    func(path string, _ *types.Filter) error { record(path); return nil },
+		Condition: func(path string, _ *types.Condition) error { record(path); return nil },
+		Include:   func(path string, _ *types.Include) error { record(path); return nil },
+		OrderBy:   func(path string, _ *types.OrderBy) error { record(path); return nil },
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	want := []string{
+		"statement.query",
+		"statement.query.where",
+		"statement.query.where.and[0]",
+		"statement.query.where.and[0].conditions[0]",
+		"statement.query.where.not",
+		"statement.query.where.not.conditions[0]",
+		"statement.query.where.conditions[0]",
+		"statement.query.where.conditions[0].subquery",
+		"statement.query.where.conditions[0].subquery.where",
+		"statement.query.where.conditions[0].subquery.where.conditions[0]",
+		"statement.query.order_by[0]",
+		"statement.query.joins[0].on",
+		"statement.query.joins[0].on.conditions[0]",
+		"statement.policy",
+		"statement.policy.conditions[0]",
+		"statement.includes[0]",
+		"statement.includes[0].query",
+		"statement.includes[0].query.where",
+		"statement.includes[0].query.where.conditions[0]",
+		"statement.includes[0].includes[0]",
+		"statement.includes[0].includes[0].query",
+	}
+	if len(paths) != len(want) {
+		t.Fatalf("Visited %d nodes, want %d: %v", len(paths), len(want), paths)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Errorf("paths[%d] = %s, want %s", i, paths[i], want[i])
+		}
+	}
+}
+
+func TestWalkMutatesInPlace(t *testing.T) {
+	stmt := sampleStatement()
+
+	err := walk.Walk(stmt, walk.Visitor{
+		Condition: func(_ string, c *types.Condition) error {
+			c.Value = "?"
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+
+	got := (*(*stmt.Query.Where.And)[0].Conditions)[0].Value
+	if got != "?" {
+		t.Errorf("Expected anonymized value, got %v", got)
+	}
+	if (*stmt.Includes[0].Query.Where.Conditions)[0].Value != "?" {
+		t.Error("Expected include condition to be anonymized")
+	}
+}
+
+func TestWalkSkipChildrenAndErrors(t *testing.T) {
+	count := 0
+	err := walk.Walk(sampleStatement(), walk.Visitor{
+		Include:   func(string, *types.Include) error { return walk.SkipChildren },
+		Condition: func(string, *types.Condition) error { count++; return nil },
+	})
+	if err != nil {
+		t.Fatalf("Walk failed: %v", err)
+	}
+	if count != 6 {
+		t.Errorf("Expected 6 root conditions, got %d", count)
+	}
+
+	stop := errors.New("stop")
+	err = walk.Walk(sampleStatement(), walk.Visitor{
+		Condition: func(string, *types.Condition) error { return stop },
+	})
+	if err != stop {
+		t.Errorf("Expected stop error, got %v", err)
+	}
+}
+
+func TestWalkMutation(t *testing.T) {
+	m := &types.Mutation{Changes: []types.Change{
+		{Model: "posts", Action: "insert", Sets: []types.KV{{Field: "id", Value: "1"}}},
+		{Model: "posts", Action: "delete", Where: &types.Filter{Conditions: &[]types.Condition{types.Eq("id", "2")}}},
+	}}
+
+	var paths []string
+	err := walk.WalkMutation(m, walk.Visitor{
+		Condition: func(path string, _ *types.Condition) error { paths = append(paths, path); return nil },
+	})
+	if err != nil {
+		t.Fatalf("WalkMutation failed: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != "mutation.changes[1].where.conditions[0]" {
+		t.Errorf("Unexpected paths: %v", paths)
+	}
+}
@@ -0,0 +1,19 @@
+plugins {
+    kotlin("jvm") version "2.0.21"
+    kotlin("plugin.serialization") version "2.0.21"
+}
+
+group = "dev.includekit"
+version = "0.1.0"
+
+repositories {
+    mavenCentral()
+}
+
+dependencies {
+    api("org.jetbrains.kotlinx:kotlinx-serialization-json:1.7.3")
+}
+
+kotlin {
+    jvmToolchain(17)
+}
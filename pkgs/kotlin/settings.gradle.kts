@@ -0,0 +1 @@
+rootProject.name = "includekit-spec"
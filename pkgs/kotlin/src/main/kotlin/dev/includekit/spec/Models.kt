@@ -0,0 +1,314 @@
+/**
+ * IncludeKit Universal Format v0.1
+ * Auto-generated from schema/v0-1-0.json
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+package dev.includekit.spec
+
+import kotlinx.serialization.SerialName
+import kotlinx.serialization.Serializable
+import kotlinx.serialization.json.JsonElement
+
+@Serializable
+data class Condition(
+    @SerialName("field") val field: String,
+    /** Optional path into the JSON value of field (e.g., ['address', 'city']): each segment is an object key, or an array index in plain decimal */
+    @SerialName("field_path") val fieldPath: List<String>? = null,
+    @SerialName("op") val op: String,
+    /** Operand of op; withinRadius and intersects take a GeoShape, bbox a [southwest, northeast] pair of GeoPoints */
+    @SerialName("value") val value: JsonElement? = null,
+    /** Deprecated: use field_path instead */
+    @Deprecated("Deprecated in the schema")
+    @SerialName("path") val path: List<String>? = null,
+    /** Sub-select for inQuery and existsQuery, selecting exactly one field */
+    @SerialName("subquery") val subquery: Query? = null,
+)
+
+/** A point on the earth, the value of a geospatial field */
+@Serializable
+data class GeoPoint(
+    /** Latitude in WGS 84 degrees */
+    @SerialName("lat") val lat: Double,
+    /** Longitude in WGS 84 degrees */
+    @SerialName("lng") val lng: Double,
+)
+
+/** A circle (center and radius) or a polygon, exactly one of the two */
+@Serializable
+data class GeoShape(
+    /** Center of a circle; set with radius, not polygon */
+    @SerialName("center") val center: GeoPoint? = null,
+    /** Radius of a circle in meters */
+    @SerialName("radius") val radius: Double? = null,
+    /** Three or more vertices of a polygon, implicitly closed, with edges straight in latitude and longitude */
+    @SerialName("polygon") val polygon: List<GeoPoint>? = null,
+)
+
+@Serializable
+data class Filter(
+    @SerialName("and") val and: List<Filter>? = null,
+    @SerialName("or") val or: List<Filter>? = null,
+    @SerialName("not") val not: Filter? = null,
+    @SerialName("conditions") val conditions: List<Condition>? = null,
+)
+
+@Serializable
+data class OrderBy(
+    @SerialName("field") val field: String,
+    @SerialName("descending") val descending: Boolean? = null,
+    @SerialName("nulls_first") val nullsFirst: Boolean? = null,
+    @SerialName("case_sensitive") val caseSensitive: Boolean? = null,
+)
+
+@Serializable
+enum class QueryDeleted {
+    @SerialName("include") INCLUDE,
+    @SerialName("only") ONLY,
+}
+
+@Serializable
+data class Query(
+    @SerialName("model") val model: String,
+    @SerialName("fields") val fields: List<String>? = null,
+    @SerialName("where") val where: Filter? = null,
+    @SerialName("order_by") val orderBy: List<OrderBy>? = null,
+    @SerialName("limit") val limit: Long? = null,
+    @SerialName("offset") val offset: Long? = null,
+    @SerialName("distinct") val distinct: List<String>? = null,
+    @SerialName("aggregations") val aggregations: List<Aggregate>? = null,
+    @SerialName("joins") val joins: List<Join>? = null,
+    /** Window function columns, computed over the rows the where selects */
+    @SerialName("windows") val windows: List<Window>? = null,
+    /** Keeps one row per combination of fields, chosen by its own order; order_by, offset and limit then apply to the kept rows. Not combined with distinct */
+    @SerialName("distinct_on") val distinctOn: DistinctOn? = null,
+    /** Soft-deleted rows to read, on models whose schema names a soft-delete field: include reads them alongside live rows, only reads nothing else; live rows only when omitted */
+    @SerialName("deleted") val deleted: QueryDeleted? = null,
+)
+
+@Serializable
+enum class WindowFunc {
+    @SerialName("row_number") ROW_NUMBER,
+    @SerialName("rank") RANK,
+    @SerialName("dense_rank") DENSE_RANK,
+    @SerialName("count") COUNT,
+    @SerialName("sum") SUM,
+    @SerialName("avg") AVG,
+    @SerialName("min") MIN,
+    @SerialName("max") MAX,
+}
+
+/** A window function column, such as ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC) */
+@Serializable
+data class Window(
+    @SerialName("func") val func: WindowFunc,
+    /** Aggregated field; required for sum, avg, min and max, optional for count (COUNT(*) when omitted), and not taken by the ranking functions */
+    @SerialName("field") val field: String? = null,
+    /** Fields whose values split the rows into partitions; one partition when omitted */
+    @SerialName("partition_by") val partitionBy: List<String>? = null,
+    /** Order of the rows within a partition; required for the ranking functions */
+    @SerialName("order_by") val orderBy: List<OrderBy>? = null,
+    @SerialName("frame") val frame: WindowFrame? = null,
+    /** Result column name */
+    @SerialName("alias") val alias: String,
+)
+
+@Serializable
+enum class WindowFrameUnit {
+    @SerialName("rows") ROWS,
+    @SerialName("range") RANGE,
+}
+
+/** Rows of the partition an aggregate window function covers, relative to the current row; without a frame, the partition up to the current row and its order_by peers, or the whole partition when there is no order_by */
+@Serializable
+data class WindowFrame(
+    /** Whether start and end count rows, or differences of the single order_by value */
+    @SerialName("unit") val unit: WindowFrameUnit,
+    /** Offset of the first row of the frame: negative precedes the current row, 0 is the current row; unbounded preceding when omitted */
+    @SerialName("start") val start: Long? = null,
+    /** Offset of the last row of the frame, no less than start; unbounded following when omitted */
+    @SerialName("end") val end: Long? = null,
+)
+
+/** Distinct fields tied to the order that picks the row kept for each of their combinations, as in PostgreSQL's SELECT DISTINCT ON (author_id) ... ORDER BY author_id, created_at DESC. The order is separate from the query's order_by, which sorts the kept rows, so the pick need not follow the listing order; distinct already picks by the query's order_by */
+@Serializable
+data class DistinctOn(
+    /** Fields whose combinations are kept once; non-empty and without repeats */
+    @SerialName("fields") val fields: List<String>,
+    /** Starts with one entry for each of fields, in any order; the first row in this order is kept for each combination */
+    @SerialName("order_by") val orderBy: List<OrderBy>,
+)
+
+@Serializable
+enum class AggregateFunc {
+    @SerialName("count") COUNT,
+    @SerialName("sum") SUM,
+    @SerialName("avg") AVG,
+    @SerialName("min") MIN,
+    @SerialName("max") MAX,
+}
+
+@Serializable
+data class Aggregate(
+    @SerialName("func") val func: AggregateFunc,
+    /** Aggregated field; omitted only for count, meaning COUNT(*) */
+    @SerialName("field") val field: String? = null,
+    /** Result column name; defaults to <func> for COUNT(*) and <func>_<field> otherwise */
+    @SerialName("alias") val alias: String? = null,
+)
+
+@Serializable
+enum class JoinKind {
+    @SerialName("inner") INNER,
+    @SerialName("left") LEFT,
+}
+
+@Serializable
+data class Join(
+    /** Relation of the query's model to join, as in Include */
+    @SerialName("relation") val relation: String,
+    @SerialName("kind") val kind: JoinKind,
+    /** Join conditions on the joined model's fields */
+    @SerialName("on") val on: Filter? = null,
+    /** Joined model fields to project; none when omitted */
+    @SerialName("fields") val fields: List<String>? = null,
+)
+
+@Serializable
+enum class IncludeKind {
+    @SerialName("some") SOME,
+    @SerialName("every") EVERY,
+    @SerialName("none") NONE,
+    @SerialName("count") COUNT,
+}
+
+@Serializable
+data class Include(
+    @SerialName("query") val query: Query? = null,
+    @SerialName("kind") val kind: IncludeKind? = null,
+    @SerialName("includes") val includes: List<Include>? = null,
+    /** Threshold on the number of matching relation rows; set exactly when kind is count */
+    @SerialName("count") val count: RelationCount? = null,
+)
+
+@Serializable
+enum class RelationCountOp {
+    @SerialName("eq") EQ,
+    @SerialName("ne") NE,
+    @SerialName("gt") GT,
+    @SerialName("gte") GTE,
+    @SerialName("lt") LT,
+    @SerialName("lte") LTE,
+}
+
+/** Filters parents by how many relation rows match the include's where, as in users with more than 5 posts */
+@Serializable
+data class RelationCount(
+    @SerialName("op") val op: RelationCountOp,
+    /** Number of matching relation rows op compares against */
+    @SerialName("value") val value: Long,
+)
+
+@Serializable
+data class Pagination(
+    @SerialName("first") val first: Long? = null,
+    @SerialName("last") val last: Long? = null,
+    @SerialName("after") val after: String? = null,
+    @SerialName("before") val before: String? = null,
+)
+
+@Serializable
+data class Statement(
+    @SerialName("query") val query: Query? = null,
+    @SerialName("pagination") val pagination: Pagination? = null,
+    @SerialName("group_by") val groupBy: List<String>? = null,
+    @SerialName("having") val having: Filter? = null,
+    @SerialName("includes") val includes: List<Include>? = null,
+    /** Diagnostic only; excluded from canonicalization */
+    @SerialName("orm_version") val ormVersion: String? = null,
+    /** Diagnostic only; excluded from canonicalization */
+    @SerialName("sdk_version") val sdkVersion: String? = null,
+    /** Spec version the SDK emitted the statement with; absent means 0.1.0. Validators reject versions newer than they support. Excluded from canonicalization */
+    @SerialName("spec_version") val specVersion: String? = null,
+    /** Tenant the statement reads for, in multi-tenant engines. Hashed into the shape ID, so tenants never share a shape; absent for reads that are not tenant-scoped */
+    @SerialName("tenant") val tenant: String? = null,
+    /** Row-level visibility predicate of the reader, ANDed with the root query's where when reading and when hashing the shape ID, so one query under different policies never shares a shape; Dependencies.policy keeps it apart */
+    @SerialName("policy") val policy: Filter? = null,
+)
+
+@Serializable
+data class KV(
+    @SerialName("field") val field: String,
+    @SerialName("value") val value: JsonElement,
+)
+
+@Serializable
+enum class ChangeAction {
+    @SerialName("insert") INSERT,
+    @SerialName("update") UPDATE,
+    @SerialName("delete") DELETE,
+}
+
+@Serializable
+data class Change(
+    @SerialName("model") val model: String,
+    @SerialName("action") val action: ChangeAction,
+    @SerialName("sets") val sets: List<KV>? = null,
+    @SerialName("where") val where: Filter? = null,
+)
+
+@Serializable
+data class Mutation(
+    @SerialName("tx_id") val txId: String? = null,
+    @SerialName("changes") val changes: List<Change>,
+    /** Tenant whose rows the mutation writes; it invalidates only that tenant's shapes and those without a tenant. Absent means it may write rows of any tenant */
+    @SerialName("tenant") val tenant: String? = null,
+)
+
+@Serializable
+data class Transaction(
+    /** Position in the source's commit order; strictly increasing per source */
+    @SerialName("seq") val seq: Long,
+    /** RFC 3339 instant the source committed the transaction */
+    @SerialName("committed_at") val committedAt: String? = null,
+    @SerialName("mutations") val mutations: List<Mutation>,
+)
+
+@Serializable
+data class PaginationBoundary(
+    @SerialName("order_by") val orderBy: List<OrderBy>,
+    /** Field values of the last included row */
+    @SerialName("row") val row: Map<String, JsonElement>,
+    @SerialName("cursor") val cursor: KV? = null,
+)
+
+@Serializable
+data class DependenciesGroupBy(
+    @SerialName("keys") val keys: List<String>,
+    @SerialName("values") val values: List<Map<String, JsonElement>>,
+)
+
+@Serializable
+data class Dependencies(
+    /** Spec version the document was written with; absent means 0.1.0 */
+    @SerialName("spec_version") val specVersion: String? = null,
+    @SerialName("shape_id") val shapeId: String,
+    /** Record IDs by model; a composite key's ID is the JSON array of its values in key order */
+    @SerialName("records") val records: Map<String, List<String>>,
+    @SerialName("filters") val filters: List<Filter>,
+    @SerialName("includes") val includes: List<Include>,
+    @SerialName("last_row") val lastRow: PaginationBoundary? = null,
+    @SerialName("group_by") val groupBy: DependenciesGroupBy? = null,
+    /** Seconds after registration before the shape is stale, for sources that never emit mutations */
+    @SerialName("ttl") val ttl: Long? = null,
+    /** RFC 3339 instant after which the shape is stale */
+    @SerialName("valid_until") val validUntil: String? = null,
+    /** Fields the root query's windows partition by, order by or aggregate; an update that sets one may change window columns */
+    @SerialName("window_fields") val windowFields: List<String>? = null,
+    /** Soft-delete field of the root model, when the root query leaves out soft-deleted rows or reads only them; an update that sets it removes or restores rows as a delete or insert would */
+    @SerialName("soft_delete_field") val softDeleteField: String? = null,
+    /** Tenant of the statement; mutations of other tenants never invalidate the shape */
+    @SerialName("tenant") val tenant: String? = null,
+    /** Policy of the statement, tracked apart from filters; a change that may move a row into or out of it invalidates, and a changed policy evicts every shape recorded under it */
+    @SerialName("policy") val policy: Filter? = null,
+)
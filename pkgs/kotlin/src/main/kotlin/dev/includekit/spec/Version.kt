@@ -0,0 +1,16 @@
+/**
+ * Version of IncludeKit Universal Format v0.1
+ * Auto-generated from schema/v0-1-0.json
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+package dev.includekit.spec
+
+/** Universal Format version this library implements, stamped into Dependencies.specVersion */
+const val SPEC_VERSION = "0.1.0"
+
+/** $id of the JSON Schema this library is generated from */
+const val SCHEMA_ID = "https://github.com/bold-minds/includekit-spec/schema/v0-1-0.json"
+
+/** Version of the Engine contract, which engines report as VersionInfo.contract */
+const val CONTRACT_VERSION = "0.1.0"
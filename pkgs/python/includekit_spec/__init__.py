@@ -0,0 +1,57 @@
+"""IncludeKit Universal Format models for Python."""
+
+from .models import (
+    Aggregate,
+    Change,
+    Condition,
+    Dependencies,
+    DependenciesGroupBy,
+    DistinctOn,
+    Filter,
+    GeoPoint,
+    GeoShape,
+    Include,
+    Join,
+    KV,
+    Mutation,
+    OrderBy,
+    Pagination,
+    PaginationBoundary,
+    Query,
+    RelationCount,
+    SpecModel,
+    Statement,
+    Transaction,
+    Window,
+    WindowFrame,
+)
+from .version import CONTRACT_VERSION, SCHEMA_ID, SPEC_VERSION
+
+__all__ = [
+    "CONTRACT_VERSION",
+    "SCHEMA_ID",
+    "SPEC_VERSION",
+    "Aggregate",
+    "Change",
+    "Condition",
+    "Dependencies",
+    "DependenciesGroupBy",
+    "DistinctOn",
+    "Filter",
+    "GeoPoint",
+    "GeoShape",
+    "Include",
+    "Join",
+    "KV",
+    "Mutation",
+    "OrderBy",
+    "Pagination",
+    "PaginationBoundary",
+    "Query",
+    "RelationCount",
+    "SpecModel",
+    "Statement",
+    "Transaction",
+    "Window",
+    "WindowFrame",
+]
@@ -0,0 +1,219 @@
+"""
+IncludeKit Universal Format v0.1
+Auto-generated from schema/v0-1-0.json
+DO NOT EDIT - This file is automatically generated
+"""
+
+from __future__ import annotations
+
+from typing import Annotated, Any, Dict, List, Literal, Optional, Union
+
+from pydantic import BaseModel, ConfigDict, Field
+
+
+class SpecModel(BaseModel):
+    """Base for all models: unknown fields are rejected, and fields can be
+    populated by attribute or wire name."""
+
+    model_config = ConfigDict(extra="forbid", populate_by_name=True)
+
+
+class Condition(SpecModel):
+    field: str = Field(alias="field", min_length=1)
+    field_path: Optional[List[Annotated[str, Field(min_length=1)]]] = Field(default=None, alias="field_path", description="Optional path into the JSON value of field (e.g., ['address', 'city']): each segment is an object key, or an array index in plain decimal")
+    op: Union[Literal["eq", "ne", "in", "notIn", "isNull", "gt", "gte", "lt", "lte", "between", "contains", "startsWith", "endsWith", "like", "ilike", "regex", "has", "hasSome", "hasEvery", "jsonContains", "lenEq", "lenGt", "lenLt", "exists", "inQuery", "existsQuery", "withinRadius", "intersects", "bbox", "jsonHasKey", "jsonType"], Annotated[str, Field(pattern="^custom:.+$")]] = Field(alias="op")
+    value: Optional[Any] = Field(default=None, alias="value", description="Operand of op; withinRadius and intersects take a GeoShape, bbox a [southwest, northeast] pair of GeoPoints")
+    path: Optional[List[str]] = Field(default=None, alias="path", description="Deprecated: use field_path instead", deprecated=True)
+    subquery: Optional[Query] = Field(default=None, alias="subquery", description="Sub-select for inQuery and existsQuery, selecting exactly one field")
+
+
+class GeoPoint(SpecModel):
+    """A point on the earth, the value of a geospatial field"""
+
+    lat: float = Field(alias="lat", ge=-90, le=90, description="Latitude in WGS 84 degrees")
+    lng: float = Field(alias="lng", ge=-180, le=180, description="Longitude in WGS 84 degrees")
+
+
+class GeoShape(SpecModel):
+    """A circle (center and radius) or a polygon, exactly one of the two"""
+
+    center: Optional[GeoPoint] = Field(default=None, alias="center", description="Center of a circle; set with radius, not polygon")
+    radius: Optional[float] = Field(default=None, alias="radius", ge=0, description="Radius of a circle in meters")
+    polygon: Optional[List[GeoPoint]] = Field(default=None, alias="polygon", description="Three or more vertices of a polygon, implicitly closed, with edges straight in latitude and longitude")
+
+
+class Filter(SpecModel):
+    and_: Optional[List[Filter]] = Field(default=None, alias="and")
+    or_: Optional[List[Filter]] = Field(default=None, alias="or")
+    not_: Optional[Filter] = Field(default=None, alias="not")
+    conditions: Optional[List[Condition]] = Field(default=None, alias="conditions")
+
+
+class OrderBy(SpecModel):
+    field: str = Field(alias="field", min_length=1)
+    descending: Optional[bool] = Field(default=None, alias="descending")
+    nulls_first: Optional[bool] = Field(default=None, alias="nulls_first")
+    case_sensitive: Optional[bool] = Field(default=None, alias="case_sensitive")
+
+
+class Query(SpecModel):
+    model: str = Field(alias="model", min_length=1)
+    fields: Optional[List[str]] = Field(default=None, alias="fields")
+    where: Optional[Filter] = Field(default=None, alias="where")
+    order_by: Optional[List[OrderBy]] = Field(default=None, alias="order_by")
+    limit: Optional[int] = Field(default=None, alias="limit")
+    offset: Optional[int] = Field(default=None, alias="offset")
+    distinct: Optional[List[str]] = Field(default=None, alias="distinct")
+    aggregations: Optional[List[Aggregate]] = Field(default=None, alias="aggregations")
+    joins: Optional[List[Join]] = Field(default=None, alias="joins")
+    windows: Optional[List[Window]] = Field(default=None, alias="windows", description="Window function columns, computed over the rows the where selects")
+    distinct_on: Optional[DistinctOn] = Field(default=None, alias="distinct_on", description="Keeps one row per combination of fields, chosen by its own order; order_by, offset and limit then apply to the kept rows. Not combined with distinct")
+    deleted: Optional[Literal["include", "only"]] = Field(default=None, alias="deleted", description="Soft-deleted rows to read, on models whose schema names a soft-delete field: include reads them alongside live rows, only reads nothing else; live rows only when omitted")
+
+
+class Window(SpecModel):
+    """A window function column, such as ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC)"""
+
+    func: Literal["row_number", "rank", "dense_rank", "count", "sum", "avg", "min", "max"] = Field(alias="func")
+    field: Optional[str] = Field(default=None, alias="field", min_length=1, description="Aggregated field; required for sum, avg, min and max, optional for count (COUNT(*) when omitted), and not taken by the ranking functions")
+    partition_by: Optional[List[str]] = Field(default=None, alias="partition_by", description="Fields whose values split the rows into partitions; one partition when omitted")
+    order_by: Optional[List[OrderBy]] = Field(default=None, alias="order_by", description="Order of the rows within a partition; required for the ranking functions")
+    frame: Optional[WindowFrame] = Field(default=None, alias="frame")
+    alias: str = Field(alias="alias", min_length=1, description="Result column name")
+
+
+class WindowFrame(SpecModel):
+    """Rows of the partition an aggregate window function covers, relative to the current row; without a frame, the partition up to the current row and its order_by peers, or the whole partition when there is no order_by"""
+
+    unit: Literal["rows", "range"] = Field(alias="unit", description="Whether start and end count rows, or differences of the single order_by value")
+    start: Optional[int] = Field(default=None, alias="start", description="Offset of the first row of the frame: negative precedes the current row, 0 is the current row; unbounded preceding when omitted")
+    end: Optional[int] = Field(default=None, alias="end", description="Offset of the last row of the frame, no less than start; unbounded following when omitted")
+
+
+class DistinctOn(SpecModel):
+    """Distinct fields tied to the order that picks the row kept for each of their combinations, as in PostgreSQL's SELECT DISTINCT ON (author_id) ... ORDER BY author_id, created_at DESC. The order is separate from the query's order_by, which sorts the kept rows, so the pick need not follow the listing order; distinct already picks by the query's order_by"""
+
+    fields: List[str] = Field(alias="fields", description="Fields whose combinations are kept once; non-empty and without repeats")
+    order_by: List[OrderBy] = Field(alias="order_by", description="Starts with one entry for each of fields, in any order; the first row in this order is kept for each combination")
+
+
+class Aggregate(SpecModel):
+    func: Literal["count", "sum", "avg", "min", "max"] = Field(alias="func")
+    field: Optional[str] = Field(default=None, alias="field", min_length=1, description="Aggregated field; omitted only for count, meaning COUNT(*)")
+    alias: Optional[str] = Field(default=None, alias="alias", min_length=1, description="Result column name; defaults to <func> for COUNT(*) and <func>_<field> otherwise")
+
+
+class Join(SpecModel):
+    relation: str = Field(alias="relation", min_length=1, description="Relation of the query's model to join, as in Include")
+    kind: Literal["inner", "left"] = Field(alias="kind")
+    on: Optional[Filter] = Field(default=None, alias="on", description="Join conditions on the joined model's fields")
+    fields: Optional[List[str]] = Field(default=None, alias="fields", description="Joined model fields to project; none when omitted")
+
+
+class Include(SpecModel):
+    query: Optional[Query] = Field(default=None, alias="query")
+    kind: Optional[Literal["some", "every", "none", "count"]] = Field(default=None, alias="kind")
+    includes: Optional[List[Include]] = Field(default=None, alias="includes")
+    count: Optional[RelationCount] = Field(default=None, alias="count", description="Threshold on the number of matching relation rows; set exactly when kind is count")
+
+
+class RelationCount(SpecModel):
+    """Filters parents by how many relation rows match the include's where, as in users with more than 5 posts"""
+
+    op: Literal["eq", "ne", "gt", "gte", "lt", "lte"] = Field(alias="op")
+    value: int = Field(alias="value", ge=0, description="Number of matching relation rows op compares against")
+
+
+class Pagination(SpecModel):
+    first: Optional[int] = Field(default=None, alias="first", ge=1)
+    last: Optional[int] = Field(default=None, alias="last", ge=1)
+    after: Optional[str] = Field(default=None, alias="after")
+    before: Optional[str] = Field(default=None, alias="before")
+
+
+class Statement(SpecModel):
+    query: Optional[Query] = Field(default=None, alias="query")
+    pagination: Optional[Pagination] = Field(default=None, alias="pagination")
+    group_by: Optional[List[str]] = Field(default=None, alias="group_by")
+    having: Optional[Filter] = Field(default=None, alias="having")
+    includes: Optional[List[Include]] = Field(default=None, alias="includes")
+    orm_version: Optional[str] = Field(default=None, alias="orm_version", description="Diagnostic only; excluded from canonicalization")
+    sdk_version: Optional[str] = Field(default=None, alias="sdk_version", description="Diagnostic only; excluded from canonicalization")
+    spec_version: Optional[str] = Field(default=None, alias="spec_version", pattern="^\\d+\\.\\d+\\.\\d+$", description="Spec version the SDK emitted the statement with; absent means 0.1.0. Validators reject versions newer than they support. Excluded from canonicalization")
+    tenant: Optional[str] = Field(default=None, alias="tenant", min_length=1, description="Tenant the statement reads for, in multi-tenant engines. Hashed into the shape ID, so tenants never share a shape; absent for reads that are not tenant-scoped")
+    policy: Optional[Filter] = Field(default=None, alias="policy", description="Row-level visibility predicate of the reader, ANDed with the root query's where when reading and when hashing the shape ID, so one query under different policies never shares a shape; Dependencies.policy keeps it apart")
+
+
+class KV(SpecModel):
+    field: str = Field(alias="field", min_length=1)
+    value: Any = Field(alias="value")
+
+
+class Change(SpecModel):
+    model: str = Field(alias="model", min_length=1)
+    action: Literal["insert", "update", "delete"] = Field(alias="action")
+    sets: Optional[List[KV]] = Field(default=None, alias="sets")
+    where: Optional[Filter] = Field(default=None, alias="where")
+
+
+class Mutation(SpecModel):
+    tx_id: Optional[str] = Field(default=None, alias="tx_id")
+    changes: List[Change] = Field(alias="changes")
+    tenant: Optional[str] = Field(default=None, alias="tenant", min_length=1, description="Tenant whose rows the mutation writes; it invalidates only that tenant's shapes and those without a tenant. Absent means it may write rows of any tenant")
+
+
+class Transaction(SpecModel):
+    seq: int = Field(alias="seq", ge=0, description="Position in the source's commit order; strictly increasing per source")
+    committed_at: Optional[str] = Field(default=None, alias="committed_at", description="RFC 3339 instant the source committed the transaction")
+    mutations: List[Mutation] = Field(alias="mutations")
+
+
+class PaginationBoundary(SpecModel):
+    order_by: List[OrderBy] = Field(alias="order_by")
+    row: Dict[str, Any] = Field(alias="row", description="Field values of the last included row")
+    cursor: Optional[KV] = Field(default=None, alias="cursor")
+
+
+class DependenciesGroupBy(SpecModel):
+    keys: List[str] = Field(alias="keys")
+    values: List[Dict[str, Any]] = Field(alias="values")
+
+
+class Dependencies(SpecModel):
+    spec_version: Optional[str] = Field(default=None, alias="spec_version", pattern="^\\d+\\.\\d+\\.\\d+$", description="Spec version the document was written with; absent means 0.1.0")
+    shape_id: str = Field(alias="shape_id", pattern="^s_[0-9a-f]{64}$")
+    records: Dict[str, List[str]] = Field(alias="records", description="Record IDs by model; a composite key's ID is the JSON array of its values in key order")
+    filters: List[Filter] = Field(alias="filters")
+    includes: List[Include] = Field(alias="includes")
+    last_row: Optional[PaginationBoundary] = Field(default=None, alias="last_row")
+    group_by: Optional[DependenciesGroupBy] = Field(default=None, alias="group_by")
+    ttl: Optional[int] = Field(default=None, alias="ttl", ge=0, description="Seconds after registration before the shape is stale, for sources that never emit mutations")
+    valid_until: Optional[str] = Field(default=None, alias="valid_until", description="RFC 3339 instant after which the shape is stale")
+    window_fields: Optional[List[str]] = Field(default=None, alias="window_fields", description="Fields the root query's windows partition by, order by or aggregate; an update that sets one may change window columns")
+    soft_delete_field: Optional[str] = Field(default=None, alias="soft_delete_field", min_length=1, description="Soft-delete field of the root model, when the root query leaves out soft-deleted rows or reads only them; an update that sets it removes or restores rows as a delete or insert would")
+    tenant: Optional[str] = Field(default=None, alias="tenant", min_length=1, description="Tenant of the statement; mutations of other tenants never invalidate the shape")
+    policy: Optional[Filter] = Field(default=None, alias="policy", description="Policy of the statement, tracked apart from filters; a change that may move a row into or out of it invalidates, and a changed policy evicts every shape recorded under it")
+
+
+Condition.model_rebuild()
+GeoPoint.model_rebuild()
+GeoShape.model_rebuild()
+Filter.model_rebuild()
+OrderBy.model_rebuild()
+Query.model_rebuild()
+Window.model_rebuild()
+WindowFrame.model_rebuild()
+DistinctOn.model_rebuild()
+Aggregate.model_rebuild()
+Join.model_rebuild()
+Include.model_rebuild()
+RelationCount.model_rebuild()
+Pagination.model_rebuild()
+Statement.model_rebuild()
+KV.model_rebuild()
+Change.model_rebuild()
+Mutation.model_rebuild()
+Transaction.model_rebuild()
+PaginationBoundary.model_rebuild()
+DependenciesGroupBy.model_rebuild()
+Dependencies.model_rebuild()
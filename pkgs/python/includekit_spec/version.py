@@ -0,0 +1,14 @@
+"""Version of IncludeKit Universal Format v0.1.
+
+Auto-generated from schema/v0-1-0.json
+DO NOT EDIT - This file is automatically generated
+"""
+
+SPEC_VERSION = "0.1.0"
+"""Universal Format version this package implements, stamped into Dependencies.spec_version"""
+
+SCHEMA_ID = "https://github.com/bold-minds/includekit-spec/schema/v0-1-0.json"
+"""$id of the JSON Schema this package is generated from"""
+
+CONTRACT_VERSION = "0.1.0"
+"""Version of the Engine contract, which engines report as VersionInfo.contract"""
@@ -0,0 +1,623 @@
+//! IncludeKit Universal Format v0.1
+//!
+//! Auto-generated from schema/v0-1-0.json
+//! DO NOT EDIT - This file is automatically generated
+
+use std::collections::BTreeMap;
+use std::fmt;
+use std::str::FromStr;
+
+use serde::{de, Deserialize, Deserializer, Serialize, Serializer};
+
+mod version;
+
+pub use version::{CONTRACT_VERSION, SCHEMA_ID, SPEC_VERSION};
+
+/// One of the values below, or custom:<name>
+#[derive(Debug, Clone, PartialEq, Eq, Hash)]
+pub enum Operator {
+    Eq,
+    Ne,
+    In,
+    NotIn,
+    IsNull,
+    Gt,
+    Gte,
+    Lt,
+    Lte,
+    Between,
+    Contains,
+    StartsWith,
+    EndsWith,
+    Like,
+    Ilike,
+    Regex,
+    Has,
+    HasSome,
+    HasEvery,
+    JsonContains,
+    LenEq,
+    LenGt,
+    LenLt,
+    Exists,
+    InQuery,
+    ExistsQuery,
+    WithinRadius,
+    Intersects,
+    Bbox,
+    JsonHasKey,
+    JsonType,
+    /// custom:<name>, holding the name
+    Custom(String),
+}
+
+impl Operator {
+    /// The values other than custom:<name>
+    pub const ALL: &'static [Operator] = &[
+        Operator::Eq,
+        Operator::Ne,
+        Operator::In,
+        Operator::NotIn,
+        Operator::IsNull,
+        Operator::Gt,
+        Operator::Gte,
+        Operator::Lt,
+        Operator::Lte,
+        Operator::Between,
+        Operator::Contains,
+        Operator::StartsWith,
+        Operator::EndsWith,
+        Operator::Like,
+        Operator::Ilike,
+        Operator::Regex,
+        Operator::Has,
+        Operator::HasSome,
+        Operator::HasEvery,
+        Operator::JsonContains,
+        Operator::LenEq,
+        Operator::LenGt,
+        Operator::LenLt,
+        Operator::Exists,
+        Operator::InQuery,
+        Operator::ExistsQuery,
+        Operator::WithinRadius,
+        Operator::Intersects,
+        Operator::Bbox,
+        Operator::JsonHasKey,
+        Operator::JsonType,
+    ];
+}
+
+impl fmt::Display for Operator {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Operator::Eq => f.write_str("eq"),
+            Operator::Ne => f.write_str("ne"),
+            Operator::In => f.write_str("in"),
+            Operator::NotIn => f.write_str("notIn"),
+            Operator::IsNull => f.write_str("isNull"),
+            Operator::Gt => f.write_str("gt"),
+            Operator::Gte => f.write_str("gte"),
+            Operator::Lt => f.write_str("lt"),
+            Operator::Lte => f.write_str("lte"),
+            Operator::Between => f.write_str("between"),
+            Operator::Contains => f.write_str("contains"),
+            Operator::StartsWith => f.write_str("startsWith"),
+            Operator::EndsWith => f.write_str("endsWith"),
+            Operator::Like => f.write_str("like"),
+            Operator::Ilike => f.write_str("ilike"),
+            Operator::Regex => f.write_str("regex"),
+            Operator::Has => f.write_str("has"),
+            Operator::HasSome => f.write_str("hasSome"),
+            Operator::HasEvery => f.write_str("hasEvery"),
+            Operator::JsonContains => f.write_str("jsonContains"),
+            Operator::LenEq => f.write_str("lenEq"),
+            Operator::LenGt => f.write_str("lenGt"),
+            Operator::LenLt => f.write_str("lenLt"),
+            Operator::Exists => f.write_str("exists"),
+            Operator::InQuery => f.write_str("inQuery"),
+            Operator::ExistsQuery => f.write_str("existsQuery"),
+            Operator::WithinRadius => f.write_str("withinRadius"),
+            Operator::Intersects => f.write_str("intersects"),
+            Operator::Bbox => f.write_str("bbox"),
+            Operator::JsonHasKey => f.write_str("jsonHasKey"),
+            Operator::JsonType => f.write_str("jsonType"),
+            Operator::Custom(name) => write!(f, "custom:{}", name),
+        }
+    }
+}
+
+/// Error returned when a string is not a valid Operator
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct ParseOperatorError(pub String);
+
+impl fmt::Display for ParseOperatorError {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        write!(f, "unknown operator {:?}", self.0)
+    }
+}
+
+impl std::error::Error for ParseOperatorError {}
+
+impl FromStr for Operator {
+    type Err = ParseOperatorError;
+
+    fn from_str(s: &str) -> Result<Self, Self::Err> {
+        Ok(match s {
+            "eq" => Operator::Eq,
+            "ne" => Operator::Ne,
+            "in" => Operator::In,
+            "notIn" => Operator::NotIn,
+            "isNull" => Operator::IsNull,
+            "gt" => Operator::Gt,
+            "gte" => Operator::Gte,
+            "lt" => Operator::Lt,
+            "lte" => Operator::Lte,
+            "between" => Operator::Between,
+            "contains" => Operator::Contains,
+            "startsWith" => Operator::StartsWith,
+            "endsWith" => Operator::EndsWith,
+            "like" => Operator::Like,
+            "ilike" => Operator::Ilike,
+            "regex" => Operator::Regex,
+            "has" => Operator::Has,
+            "hasSome" => Operator::HasSome,
+            "hasEvery" => Operator::HasEvery,
+            "jsonContains" => Operator::JsonContains,
+            "lenEq" => Operator::LenEq,
+            "lenGt" => Operator::LenGt,
+            "lenLt" => Operator::LenLt,
+            "exists" => Operator::Exists,
+            "inQuery" => Operator::InQuery,
+            "existsQuery" => Operator::ExistsQuery,
+            "withinRadius" => Operator::WithinRadius,
+            "intersects" => Operator::Intersects,
+            "bbox" => Operator::Bbox,
+            "jsonHasKey" => Operator::JsonHasKey,
+            "jsonType" => Operator::JsonType,
+            _ => match s.strip_prefix("custom:") {
+                Some(name) if !name.is_empty() => Operator::Custom(name.to_string()),
+                _ => return Err(ParseOperatorError(s.to_string())),
+            },
+        })
+    }
+}
+
+impl Serialize for Operator {
+    fn serialize<S: Serializer>(&self, serializer: S) -> Result<S::Ok, S::Error> {
+        serializer.collect_str(self)
+    }
+}
+
+impl<'de> Deserialize<'de> for Operator {
+    fn deserialize<D: Deserializer<'de>>(deserializer: D) -> Result<Self, D::Error> {
+        let s = String::deserialize(deserializer)?;
+        s.parse().map_err(de::Error::custom)
+    }
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Condition {
+    pub field: String,
+    /// Optional path into the JSON value of field (e.g., ['address', 'city']): each segment is an object key, or an array index in plain decimal
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub field_path: Option<Vec<String>>,
+    pub op: Operator,
+    /// Operand of op; withinRadius and intersects take a GeoShape, bbox a [southwest, northeast] pair of GeoPoints
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub value: Option<serde_json::Value>,
+    /// Deprecated: use field_path instead
+    ///
+    /// Deprecated in the schema.
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub path: Option<Vec<String>>,
+    /// Sub-select for inQuery and existsQuery, selecting exactly one field
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub subquery: Option<Query>,
+}
+
+/// A point on the earth, the value of a geospatial field
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct GeoPoint {
+    /// Latitude in WGS 84 degrees
+    pub lat: f64,
+    /// Longitude in WGS 84 degrees
+    pub lng: f64,
+}
+
+/// A circle (center and radius) or a polygon, exactly one of the two
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct GeoShape {
+    /// Center of a circle; set with radius, not polygon
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub center: Option<GeoPoint>,
+    /// Radius of a circle in meters
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub radius: Option<f64>,
+    /// Three or more vertices of a polygon, implicitly closed, with edges straight in latitude and longitude
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub polygon: Option<Vec<GeoPoint>>,
+}
+
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Filter {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub and: Option<Vec<Filter>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub or: Option<Vec<Filter>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub not: Option<Box<Filter>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub conditions: Option<Vec<Condition>>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct OrderBy {
+    pub field: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub descending: Option<bool>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub nulls_first: Option<bool>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub case_sensitive: Option<bool>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum QueryDeleted {
+    #[serde(rename = "include")]
+    Include,
+    #[serde(rename = "only")]
+    Only,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Query {
+    pub model: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub fields: Option<Vec<String>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub r#where: Option<Filter>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub order_by: Option<Vec<OrderBy>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub limit: Option<i64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub offset: Option<i64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub distinct: Option<Vec<String>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub aggregations: Option<Vec<Aggregate>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub joins: Option<Vec<Join>>,
+    /// Window function columns, computed over the rows the where selects
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub windows: Option<Vec<Window>>,
+    /// Keeps one row per combination of fields, chosen by its own order; order_by, offset and limit then apply to the kept rows. Not combined with distinct
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub distinct_on: Option<DistinctOn>,
+    /// Soft-deleted rows to read, on models whose schema names a soft-delete field: include reads them alongside live rows, only reads nothing else; live rows only when omitted
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub deleted: Option<QueryDeleted>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum WindowFunc {
+    #[serde(rename = "row_number")]
+    RowNumber,
+    #[serde(rename = "rank")]
+    Rank,
+    #[serde(rename = "dense_rank")]
+    DenseRank,
+    #[serde(rename = "count")]
+    Count,
+    #[serde(rename = "sum")]
+    Sum,
+    #[serde(rename = "avg")]
+    Avg,
+    #[serde(rename = "min")]
+    Min,
+    #[serde(rename = "max")]
+    Max,
+}
+
+/// A window function column, such as ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC)
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Window {
+    pub func: WindowFunc,
+    /// Aggregated field; required for sum, avg, min and max, optional for count (COUNT(*) when omitted), and not taken by the ranking functions
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub field: Option<String>,
+    /// Fields whose values split the rows into partitions; one partition when omitted
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub partition_by: Option<Vec<String>>,
+    /// Order of the rows within a partition; required for the ranking functions
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub order_by: Option<Vec<OrderBy>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub frame: Option<WindowFrame>,
+    /// Result column name
+    pub alias: String,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum WindowFrameUnit {
+    #[serde(rename = "rows")]
+    Rows,
+    #[serde(rename = "range")]
+    Range,
+}
+
+/// Rows of the partition an aggregate window function covers, relative to the current row; without a frame, the partition up to the current row and its order_by peers, or the whole partition when there is no order_by
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct WindowFrame {
+    /// Whether start and end count rows, or differences of the single order_by value
+    pub unit: WindowFrameUnit,
+    /// Offset of the first row of the frame: negative precedes the current row, 0 is the current row; unbounded preceding when omitted
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub start: Option<i64>,
+    /// Offset of the last row of the frame, no less than start; unbounded following when omitted
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub end: Option<i64>,
+}
+
+/// Distinct fields tied to the order that picks the row kept for each of their combinations, as in PostgreSQL's SELECT DISTINCT ON (author_id) ... ORDER BY author_id, created_at DESC. The order is separate from the query's order_by, which sorts the kept rows, so the pick need not follow the listing order; distinct already picks by the query's order_by
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct DistinctOn {
+    /// Fields whose combinations are kept once; non-empty and without repeats
+    pub fields: Vec<String>,
+    /// Starts with one entry for each of fields, in any order; the first row in this order is kept for each combination
+    pub order_by: Vec<OrderBy>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum AggregateFunc {
+    #[serde(rename = "count")]
+    Count,
+    #[serde(rename = "sum")]
+    Sum,
+    #[serde(rename = "avg")]
+    Avg,
+    #[serde(rename = "min")]
+    Min,
+    #[serde(rename = "max")]
+    Max,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Aggregate {
+    pub func: AggregateFunc,
+    /// Aggregated field; omitted only for count, meaning COUNT(*)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub field: Option<String>,
+    /// Result column name; defaults to <func> for COUNT(*) and <func>_<field> otherwise
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub alias: Option<String>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum JoinKind {
+    #[serde(rename = "inner")]
+    Inner,
+    #[serde(rename = "left")]
+    Left,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Join {
+    /// Relation of the query's model to join, as in Include
+    pub relation: String,
+    pub kind: JoinKind,
+    /// Join conditions on the joined model's fields
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub on: Option<Filter>,
+    /// Joined model fields to project; none when omitted
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub fields: Option<Vec<String>>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum IncludeKind {
+    #[serde(rename = "some")]
+    Some,
+    #[serde(rename = "every")]
+    Every,
+    #[serde(rename = "none")]
+    None,
+    #[serde(rename = "count")]
+    Count,
+}
+
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Include {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub query: Option<Query>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub kind: Option<IncludeKind>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub includes: Option<Vec<Include>>,
+    /// Threshold on the number of matching relation rows; set exactly when kind is count
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub count: Option<RelationCount>,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum RelationCountOp {
+    #[serde(rename = "eq")]
+    Eq,
+    #[serde(rename = "ne")]
+    Ne,
+    #[serde(rename = "gt")]
+    Gt,
+    #[serde(rename = "gte")]
+    Gte,
+    #[serde(rename = "lt")]
+    Lt,
+    #[serde(rename = "lte")]
+    Lte,
+}
+
+/// Filters parents by how many relation rows match the include's where, as in users with more than 5 posts
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct RelationCount {
+    pub op: RelationCountOp,
+    /// Number of matching relation rows op compares against
+    pub value: u64,
+}
+
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Pagination {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub first: Option<u64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub last: Option<u64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub after: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub before: Option<String>,
+}
+
+#[derive(Debug, Clone, Default, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Statement {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub query: Option<Query>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub pagination: Option<Pagination>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub group_by: Option<Vec<String>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub having: Option<Filter>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub includes: Option<Vec<Include>>,
+    /// Diagnostic only; excluded from canonicalization
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub orm_version: Option<String>,
+    /// Diagnostic only; excluded from canonicalization
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub sdk_version: Option<String>,
+    /// Spec version the SDK emitted the statement with; absent means 0.1.0. Validators reject versions newer than they support. Excluded from canonicalization
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub spec_version: Option<String>,
+    /// Tenant the statement reads for, in multi-tenant engines. Hashed into the shape ID, so tenants never share a shape; absent for reads that are not tenant-scoped
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub tenant: Option<String>,
+    /// Row-level visibility predicate of the reader, ANDed with the root query's where when reading and when hashing the shape ID, so one query under different policies never shares a shape; Dependencies.policy keeps it apart
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub policy: Option<Filter>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct KV {
+    pub field: String,
+    pub value: serde_json::Value,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Hash, Serialize, Deserialize)]
+pub enum ChangeAction {
+    #[serde(rename = "insert")]
+    Insert,
+    #[serde(rename = "update")]
+    Update,
+    #[serde(rename = "delete")]
+    Delete,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Change {
+    pub model: String,
+    pub action: ChangeAction,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub sets: Option<Vec<KV>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub r#where: Option<Filter>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Mutation {
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub tx_id: Option<String>,
+    pub changes: Vec<Change>,
+    /// Tenant whose rows the mutation writes; it invalidates only that tenant's shapes and those without a tenant. Absent means it may write rows of any tenant
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub tenant: Option<String>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Transaction {
+    /// Position in the source's commit order; strictly increasing per source
+    pub seq: u64,
+    /// RFC 3339 instant the source committed the transaction
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub committed_at: Option<String>,
+    pub mutations: Vec<Mutation>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct PaginationBoundary {
+    pub order_by: Vec<OrderBy>,
+    /// Field values of the last included row
+    pub row: BTreeMap<String, serde_json::Value>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub cursor: Option<KV>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct DependenciesGroupBy {
+    pub keys: Vec<String>,
+    pub values: Vec<BTreeMap<String, serde_json::Value>>,
+}
+
+#[derive(Debug, Clone, PartialEq, Serialize, Deserialize)]
+#[serde(deny_unknown_fields)]
+pub struct Dependencies {
+    /// Spec version the document was written with; absent means 0.1.0
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub spec_version: Option<String>,
+    pub shape_id: String,
+    /// Record IDs by model; a composite key's ID is the JSON array of its values in key order
+    pub records: BTreeMap<String, Vec<String>>,
+    pub filters: Vec<Filter>,
+    pub includes: Vec<Include>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub last_row: Option<PaginationBoundary>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub group_by: Option<DependenciesGroupBy>,
+    /// Seconds after registration before the shape is stale, for sources that never emit mutations
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub ttl: Option<u64>,
+    /// RFC 3339 instant after which the shape is stale
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub valid_until: Option<String>,
+    /// Fields the root query's windows partition by, order by or aggregate; an update that sets one may change window columns
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub window_fields: Option<Vec<String>>,
+    /// Soft-delete field of the root model, when the root query leaves out soft-deleted rows or reads only them; an update that sets it removes or restores rows as a delete or insert would
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub soft_delete_field: Option<String>,
+    /// Tenant of the statement; mutations of other tenants never invalidate the shape
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub tenant: Option<String>,
+    /// Policy of the statement, tracked apart from filters; a change that may move a row into or out of it invalidates, and a changed policy evicts every shape recorded under it
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub policy: Option<Filter>,
+}
@@ -0,0 +1,13 @@
+//! Version of IncludeKit Universal Format v0.1
+//!
+//! Auto-generated from schema/v0-1-0.json
+//! DO NOT EDIT - This file is automatically generated
+
+/// Universal Format version this crate implements, stamped into Dependencies::spec_version
+pub const SPEC_VERSION: &str = "0.1.0";
+
+/// $id of the JSON Schema this crate is generated from
+pub const SCHEMA_ID: &str = "https://github.com/bold-minds/includekit-spec/schema/v0-1-0.json";
+
+/// Version of the Engine contract, which engines report as VersionInfo.contract
+pub const CONTRACT_VERSION: &str = "0.1.0";
@@ -0,0 +1,75 @@
+//! Round-trips the conformance vectors through the generated types: every
+//! document must deserialize and serialize back to the same JSON.
+
+use std::fs;
+
+use includekit_spec::{Dependencies, Mutation, Operator, Statement};
+use serde::de::DeserializeOwned;
+use serde::Serialize;
+use serde_json::Value;
+
+fn vectors(file: &str) -> Vec<Value> {
+    let path = format!(
+        "{}/../../tools/tests/vectors/{}",
+        env!("CARGO_MANIFEST_DIR"),
+        file
+    );
+    let data = fs::read_to_string(&path).unwrap_or_else(|err| panic!("read {}: {}", path, err));
+    serde_json::from_str(&data).unwrap_or_else(|err| panic!("parse {}: {}", path, err))
+}
+
+fn round_trip<T: Serialize + DeserializeOwned>(name: &str, doc: &Value) {
+    let typed: T =
+        serde_json::from_value(doc.clone()).unwrap_or_else(|err| panic!("{}: {}", name, err));
+    let back = serde_json::to_value(&typed).unwrap();
+    assert_eq!(&back, doc, "{} did not round-trip", name);
+}
+
+#[test]
+fn query_shapes_round_trip() {
+    for v in vectors("query-shapes.json") {
+        round_trip::<Statement>(v["name"].as_str().unwrap(), &v["shape"]);
+    }
+}
+
+#[test]
+fn mutations_round_trip() {
+    for v in vectors("mutations.json") {
+        round_trip::<Mutation>(v["name"].as_str().unwrap(), &v["mutation"]);
+    }
+}
+
+#[test]
+fn dependencies_round_trip() {
+    for file in ["dependencies.json", "pagination-cursors.json"] {
+        for v in vectors(file) {
+            round_trip::<Dependencies>(v["name"].as_str().unwrap(), &v["dependencies"]);
+        }
+    }
+}
+
+#[test]
+fn operators() {
+    for op in Operator::ALL {
+        assert_eq!(&op.to_string().parse::<Operator>().unwrap(), op);
+    }
+    assert_eq!("notIn".parse::<Operator>().unwrap(), Operator::NotIn);
+    assert_eq!(
+        serde_json::from_str::<Operator>("\"custom:geo\"").unwrap(),
+        Operator::Custom("geo".to_string())
+    );
+    assert_eq!(
+        serde_json::to_string(&Operator::Custom("geo".to_string())).unwrap(),
+        "\"custom:geo\""
+    );
+    for bad in ["", "custom:", "notin", "equals"] {
+        assert!(bad.parse::<Operator>().is_err(), "{:?} parsed", bad);
+    }
+}
+
+#[test]
+fn unknown_fields_rejected() {
+    let err =
+        serde_json::from_str::<Statement>(r#"{"query":{"model":"Post","bogus":1}}"#).unwrap_err();
+    assert!(err.to_string().contains("bogus"), "{}", err);
+}
@@ -0,0 +1,13 @@
+// swift-tools-version:5.9
+import PackageDescription
+
+let package = Package(
+    name: "IncludeKitSpec",
+    platforms: [.iOS(.v13), .macOS(.v10_15), .tvOS(.v13), .watchOS(.v6)],
+    products: [
+        .library(name: "IncludeKitSpec", targets: ["IncludeKitSpec"]),
+    ],
+    targets: [
+        .target(name: "IncludeKitSpec"),
+    ]
+)
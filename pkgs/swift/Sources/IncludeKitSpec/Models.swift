@@ -0,0 +1,675 @@
+// IncludeKit Universal Format v0.1
+// Auto-generated from schema/v0-1-0.json
+// DO NOT EDIT - This file is automatically generated
+
+/// Any JSON value, for properties the schema leaves untyped
+public enum JSONValue: Codable, Equatable, Sendable {
+    case null
+    case bool(Bool)
+    case int(Int)
+    case double(Double)
+    case string(String)
+    case array([JSONValue])
+    case object([String: JSONValue])
+
+    public init(from decoder: Decoder) throws {
+        let container = try decoder.singleValueContainer()
+        if container.decodeNil() {
+            self = .null
+        } else if let value = try? container.decode(Bool.self) {
+            self = .bool(value)
+        } else if let value = try? container.decode(Int.self) {
+            self = .int(value)
+        } else if let value = try? container.decode(Double.self) {
+            self = .double(value)
+        } else if let value = try? container.decode(String.self) {
+            self = .string(value)
+        } else if let value = try? container.decode([JSONValue].self) {
+            self = .array(value)
+        } else {
+            self = .object(try container.decode([String: JSONValue].self))
+        }
+    }
+
+    public func encode(to encoder: Encoder) throws {
+        var container = encoder.singleValueContainer()
+        switch self {
+        case .null: try container.encodeNil()
+        case .bool(let value): try container.encode(value)
+        case .int(let value): try container.encode(value)
+        case .double(let value): try container.encode(value)
+        case .string(let value): try container.encode(value)
+        case .array(let value): try container.encode(value)
+        case .object(let value): try container.encode(value)
+        }
+    }
+}
+
+/// Boxes a property whose type contains its own struct, which a struct
+/// cannot hold inline. It encodes as the boxed value.
+final class Indirect<Value: Codable & Equatable & Sendable>: Codable, Equatable, Sendable {
+    let value: Value
+
+    init(_ value: Value) {
+        self.value = value
+    }
+
+    init(from decoder: Decoder) throws {
+        value = try Value(from: decoder)
+    }
+
+    func encode(to encoder: Encoder) throws {
+        try value.encode(to: encoder)
+    }
+
+    static func == (lhs: Indirect, rhs: Indirect) -> Bool {
+        lhs.value == rhs.value
+    }
+}
+
+public struct Condition: Codable, Equatable, Sendable {
+    public var field: String
+    /// Optional path into the JSON value of field (e.g., ['address', 'city']): each segment is an object key, or an array index in plain decimal
+    public var fieldPath: [String]?
+    public var op: String
+    /// Operand of op; withinRadius and intersects take a GeoShape, bbox a [southwest, northeast] pair of GeoPoints
+    public var value: JSONValue?
+    /// Deprecated: use field_path instead
+    /// - Note: Deprecated in the schema.
+    public var path: [String]?
+    /// Sub-select for inQuery and existsQuery, selecting exactly one field
+    public var subquery: Query?
+
+    public init(field: String, fieldPath: [String]? = nil, op: String, value: JSONValue? = nil, path: [String]? = nil, subquery: Query? = nil) {
+        self.field = field
+        self.fieldPath = fieldPath
+        self.op = op
+        self.value = value
+        self.path = path
+        self.subquery = subquery
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case field
+        case fieldPath = "field_path"
+        case op
+        case value
+        case path
+        case subquery
+    }
+}
+
+/// A point on the earth, the value of a geospatial field
+public struct GeoPoint: Codable, Equatable, Sendable {
+    /// Latitude in WGS 84 degrees
+    public var lat: Double
+    /// Longitude in WGS 84 degrees
+    public var lng: Double
+
+    public init(lat: Double, lng: Double) {
+        self.lat = lat
+        self.lng = lng
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case lat
+        case lng
+    }
+}
+
+/// A circle (center and radius) or a polygon, exactly one of the two
+public struct GeoShape: Codable, Equatable, Sendable {
+    /// Center of a circle; set with radius, not polygon
+    public var center: GeoPoint?
+    /// Radius of a circle in meters
+    public var radius: Double?
+    /// Three or more vertices of a polygon, implicitly closed, with edges straight in latitude and longitude
+    public var polygon: [GeoPoint]?
+
+    public init(center: GeoPoint? = nil, radius: Double? = nil, polygon: [GeoPoint]? = nil) {
+        self.center = center
+        self.radius = radius
+        self.polygon = polygon
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case center
+        case radius
+        case polygon
+    }
+}
+
+public struct Filter: Codable, Equatable, Sendable {
+    public var and: [Filter]?
+    public var or: [Filter]?
+    public var not: Filter? {
+        get { _not?.value }
+        set { _not = newValue.map(Indirect.init) }
+    }
+    public var conditions: [Condition]?
+    private var _not: Indirect<Filter>?
+
+    public init(and: [Filter]? = nil, or: [Filter]? = nil, not: Filter? = nil, conditions: [Condition]? = nil) {
+        self.and = and
+        self.or = or
+        self._not = not.map(Indirect.init)
+        self.conditions = conditions
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case and
+        case or
+        case _not = "not"
+        case conditions
+    }
+}
+
+public struct OrderBy: Codable, Equatable, Sendable {
+    public var field: String
+    public var descending: Bool?
+    public var nullsFirst: Bool?
+    public var caseSensitive: Bool?
+
+    public init(field: String, descending: Bool? = nil, nullsFirst: Bool? = nil, caseSensitive: Bool? = nil) {
+        self.field = field
+        self.descending = descending
+        self.nullsFirst = nullsFirst
+        self.caseSensitive = caseSensitive
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case field
+        case descending
+        case nullsFirst = "nulls_first"
+        case caseSensitive = "case_sensitive"
+    }
+}
+
+public enum QueryDeleted: String, Codable, CaseIterable, Sendable {
+    case include
+    case only
+}
+
+public struct Query: Codable, Equatable, Sendable {
+    public var model: String
+    public var fields: [String]?
+    public var `where`: Filter?
+    public var orderBy: [OrderBy]?
+    public var limit: Int?
+    public var offset: Int?
+    public var distinct: [String]?
+    public var aggregations: [Aggregate]?
+    public var joins: [Join]?
+    /// Window function columns, computed over the rows the where selects
+    public var windows: [Window]?
+    /// Keeps one row per combination of fields, chosen by its own order; order_by, offset and limit then apply to the kept rows. Not combined with distinct
+    public var distinctOn: DistinctOn?
+    /// Soft-deleted rows to read, on models whose schema names a soft-delete field: include reads them alongside live rows, only reads nothing else; live rows only when omitted
+    public var deleted: QueryDeleted?
+
+    public init(model: String, fields: [String]? = nil, `where`: Filter? = nil, orderBy: [OrderBy]? = nil, limit: Int? = nil, offset: Int? = nil, distinct: [String]? = nil, aggregations: [Aggregate]? = nil, joins: [Join]? = nil, windows: [Window]? = nil, distinctOn: DistinctOn? = nil, deleted: QueryDeleted? = nil) {
+        self.model = model
+        self.fields = fields
+        self.`where` = `where`
+        self.orderBy = orderBy
+        self.limit = limit
+        self.offset = offset
+        self.distinct = distinct
+        self.aggregations = aggregations
+        self.joins = joins
+        self.windows = windows
+        self.distinctOn = distinctOn
+        self.deleted = deleted
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case model
+        case fields
+        case `where`
+        case orderBy = "order_by"
+        case limit
+        case offset
+        case distinct
+        case aggregations
+        case joins
+        case windows
+        case distinctOn = "distinct_on"
+        case deleted
+    }
+}
+
+public enum WindowFunc: String, Codable, CaseIterable, Sendable {
+    case rowNumber = "row_number"
+    case rank
+    case denseRank = "dense_rank"
+    case count
+    case sum
+    case avg
+    case min
+    case max
+}
+
+/// A window function column, such as ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC)
+public struct Window: Codable, Equatable, Sendable {
+    public var `func`: WindowFunc
+    /// Aggregated field; required for sum, avg, min and max, optional for count (COUNT(*) when omitted), and not taken by the ranking functions
+    public var field: String?
+    /// Fields whose values split the rows into partitions; one partition when omitted
+    public var partitionBy: [String]?
+    /// Order of the rows within a partition; required for the ranking functions
+    public var orderBy: [OrderBy]?
+    public var frame: WindowFrame?
+    /// Result column name
+    public var alias: String
+
+    public init(`func`: WindowFunc, field: String? = nil, partitionBy: [String]? = nil, orderBy: [OrderBy]? = nil, frame: WindowFrame? = nil, alias: String) {
+        self.`func` = `func`
+        self.field = field
+        self.partitionBy = partitionBy
+        self.orderBy = orderBy
+        self.frame = frame
+        self.alias = alias
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case `func`
+        case field
+        case partitionBy = "partition_by"
+        case orderBy = "order_by"
+        case frame
+        case alias
+    }
+}
+
+public enum WindowFrameUnit: String, Codable, CaseIterable, Sendable {
+    case rows
+    case range
+}
+
+/// Rows of the partition an aggregate window function covers, relative to the current row; without a frame, the partition up to the current row and its order_by peers, or the whole partition when there is no order_by
+public struct WindowFrame: Codable, Equatable, Sendable {
+    /// Whether start and end count rows, or differences of the single order_by value
+    public var unit: WindowFrameUnit
+    /// Offset of the first row of the frame: negative precedes the current row, 0 is the current row; unbounded preceding when omitted
+    public var start: Int?
+    /// Offset of the last row of the frame, no less than start; unbounded following when omitted
+    public var end: Int?
+
+    public init(unit: WindowFrameUnit, start: Int? = nil, end: Int? = nil) {
+        self.unit = unit
+        self.start = start
+        self.end = end
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case unit
+        case start
+        case end
+    }
+}
+
+/// Distinct fields tied to the order that picks the row kept for each of their combinations, as in PostgreSQL's SELECT DISTINCT ON (author_id) ... ORDER BY author_id, created_at DESC. The order is separate from the query's order_by, which sorts the kept rows, so the pick need not follow the listing order; distinct already picks by the query's order_by
+public struct DistinctOn: Codable, Equatable, Sendable {
+    /// Fields whose combinations are kept once; non-empty and without repeats
+    public var fields: [String]
+    /// Starts with one entry for each of fields, in any order; the first row in this order is kept for each combination
+    public var orderBy: [OrderBy]
+
+    public init(fields: [String], orderBy: [OrderBy]) {
+        self.fields = fields
+        self.orderBy = orderBy
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case fields
+        case orderBy = "order_by"
+    }
+}
+
+public enum AggregateFunc: String, Codable, CaseIterable, Sendable {
+    case count
+    case sum
+    case avg
+    case min
+    case max
+}
+
+public struct Aggregate: Codable, Equatable, Sendable {
+    public var `func`: AggregateFunc
+    /// Aggregated field; omitted only for count, meaning COUNT(*)
+    public var field: String?
+    /// Result column name; defaults to <func> for COUNT(*) and <func>_<field> otherwise
+    public var alias: String?
+
+    public init(`func`: AggregateFunc, field: String? = nil, alias: String? = nil) {
+        self.`func` = `func`
+        self.field = field
+        self.alias = alias
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case `func`
+        case field
+        case alias
+    }
+}
+
+public enum JoinKind: String, Codable, CaseIterable, Sendable {
+    case inner
+    case left
+}
+
+public struct Join: Codable, Equatable, Sendable {
+    /// Relation of the query's model to join, as in Include
+    public var relation: String
+    public var kind: JoinKind
+    /// Join conditions on the joined model's fields
+    public var on: Filter?
+    /// Joined model fields to project; none when omitted
+    public var fields: [String]?
+
+    public init(relation: String, kind: JoinKind, on: Filter? = nil, fields: [String]? = nil) {
+        self.relation = relation
+        self.kind = kind
+        self.on = on
+        self.fields = fields
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case relation
+        case kind
+        case on
+        case fields
+    }
+}
+
+public enum IncludeKind: String, Codable, CaseIterable, Sendable {
+    case some
+    case every
+    case none
+    case count
+}
+
+public struct Include: Codable, Equatable, Sendable {
+    public var query: Query?
+    public var kind: IncludeKind?
+    public var includes: [Include]?
+    /// Threshold on the number of matching relation rows; set exactly when kind is count
+    public var count: RelationCount?
+
+    public init(query: Query? = nil, kind: IncludeKind? = nil, includes: [Include]? = nil, count: RelationCount? = nil) {
+        self.query = query
+        self.kind = kind
+        self.includes = includes
+        self.count = count
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case query
+        case kind
+        case includes
+        case count
+    }
+}
+
+public enum RelationCountOp: String, Codable, CaseIterable, Sendable {
+    case eq
+    case ne
+    case gt
+    case gte
+    case lt
+    case lte
+}
+
+/// Filters parents by how many relation rows match the include's where, as in users with more than 5 posts
+public struct RelationCount: Codable, Equatable, Sendable {
+    public var op: RelationCountOp
+    /// Number of matching relation rows op compares against
+    public var value: Int
+
+    public init(op: RelationCountOp, value: Int) {
+        self.op = op
+        self.value = value
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case op
+        case value
+    }
+}
+
+public struct Pagination: Codable, Equatable, Sendable {
+    public var first: Int?
+    public var last: Int?
+    public var after: String?
+    public var before: String?
+
+    public init(first: Int? = nil, last: Int? = nil, after: String? = nil, before: String? = nil) {
+        self.first = first
+        self.last = last
+        self.after = after
+        self.before = before
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case first
+        case last
+        case after
+        case before
+    }
+}
+
+public struct Statement: Codable, Equatable, Sendable {
+    public var query: Query?
+    public var pagination: Pagination?
+    public var groupBy: [String]?
+    public var having: Filter?
+    public var includes: [Include]?
+    /// Diagnostic only; excluded from canonicalization
+    public var ormVersion: String?
+    /// Diagnostic only; excluded from canonicalization
+    public var sdkVersion: String?
+    /// Spec version the SDK emitted the statement with; absent means 0.1.0. Validators reject versions newer than they support. Excluded from canonicalization
+    public var specVersion: String?
+    /// Tenant the statement reads for, in multi-tenant engines. Hashed into the shape ID, so tenants never share a shape; absent for reads that are not tenant-scoped
+    public var tenant: String?
+    /// Row-level visibility predicate of the reader, ANDed with the root query's where when reading and when hashing the shape ID, so one query under different policies never shares a shape; Dependencies.policy keeps it apart
+    public var policy: Filter?
+
+    public init(query: Query? = nil, pagination: Pagination? = nil, groupBy: [String]? = nil, having: Filter? = nil, includes: [Include]? = nil, ormVersion: String? = nil, sdkVersion: String? = nil, specVersion: String? = nil, tenant: String? = nil, policy: Filter? = nil) {
+        self.query = query
+        self.pagination = pagination
+        self.groupBy = groupBy
+        self.having = having
+        self.includes = includes
+        self.ormVersion = ormVersion
+        self.sdkVersion = sdkVersion
+        self.specVersion = specVersion
+        self.tenant = tenant
+        self.policy = policy
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case query
+        case pagination
+        case groupBy = "group_by"
+        case having
+        case includes
+        case ormVersion = "orm_version"
+        case sdkVersion = "sdk_version"
+        case specVersion = "spec_version"
+        case tenant
+        case policy
+    }
+}
+
+public struct KV: Codable, Equatable, Sendable {
+    public var field: String
+    public var value: JSONValue
+
+    public init(field: String, value: JSONValue) {
+        self.field = field
+        self.value = value
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case field
+        case value
+    }
+}
+
+public enum ChangeAction: String, Codable, CaseIterable, Sendable {
+    case insert
+    case update
+    case delete
+}
+
+public struct Change: Codable, Equatable, Sendable {
+    public var model: String
+    public var action: ChangeAction
+    public var sets: [KV]?
+    public var `where`: Filter?
+
+    public init(model: String, action: ChangeAction, sets: [KV]? = nil, `where`: Filter? = nil) {
+        self.model = model
+        self.action = action
+        self.sets = sets
+        self.`where` = `where`
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case model
+        case action
+        case sets
+        case `where`
+    }
+}
+
+public struct Mutation: Codable, Equatable, Sendable {
+    public var txId: String?
+    public var changes: [Change]
+    /// Tenant whose rows the mutation writes; it invalidates only that tenant's shapes and those without a tenant. Absent means it may write rows of any tenant
+    public var tenant: String?
+
+    public init(txId: String? = nil, changes: [Change], tenant: String? = nil) {
+        self.txId = txId
+        self.changes = changes
+        self.tenant = tenant
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case txId = "tx_id"
+        case changes
+        case tenant
+    }
+}
+
+public struct Transaction: Codable, Equatable, Sendable {
+    /// Position in the source's commit order; strictly increasing per source
+    public var seq: Int
+    /// RFC 3339 instant the source committed the transaction
+    public var committedAt: String?
+    public var mutations: [Mutation]
+
+    public init(seq: Int, committedAt: String? = nil, mutations: [Mutation]) {
+        self.seq = seq
+        self.committedAt = committedAt
+        self.mutations = mutations
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case seq
+        case committedAt = "committed_at"
+        case mutations
+    }
+}
+
+public struct PaginationBoundary: Codable, Equatable, Sendable {
+    public var orderBy: [OrderBy]
+    /// Field values of the last included row
+    public var row: [String: JSONValue]
+    public var cursor: KV?
+
+    public init(orderBy: [OrderBy], row: [String: JSONValue], cursor: KV? = nil) {
+        self.orderBy = orderBy
+        self.row = row
+        self.cursor = cursor
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case orderBy = "order_by"
+        case row
+        case cursor
+    }
+}
+
+public struct DependenciesGroupBy: Codable, Equatable, Sendable {
+    public var keys: [String]
+    public var values: [[String: JSONValue]]
+
+    public init(keys: [String], values: [[String: JSONValue]]) {
+        self.keys = keys
+        self.values = values
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case keys
+        case values
+    }
+}
+
+public struct Dependencies: Codable, Equatable, Sendable {
+    /// Spec version the document was written with; absent means 0.1.0
+    public var specVersion: String?
+    public var shapeId: String
+    /// Record IDs by model; a composite key's ID is the JSON array of its values in key order
+    public var records: [String: [String]]
+    public var filters: [Filter]
+    public var includes: [Include]
+    public var lastRow: PaginationBoundary?
+    public var groupBy: DependenciesGroupBy?
+    /// Seconds after registration before the shape is stale, for sources that never emit mutations
+    public var ttl: Int?
+    /// RFC 3339 instant after which the shape is stale
+    public var validUntil: String?
+    /// Fields the root query's windows partition by, order by or aggregate; an update that sets one may change window columns
+    public var windowFields: [String]?
+    /// Soft-delete field of the root model, when the root query leaves out soft-deleted rows or reads only them; an update that sets it removes or restores rows as a delete or insert would
+    public var softDeleteField: String?
+    /// Tenant of the statement; mutations of other tenants never invalidate the shape
+    public var tenant: String?
+    /// Policy of the statement, tracked apart from filters; a change that may move a row into or out of it invalidates, and a changed policy evicts every shape recorded under it
+    public var policy: Filter?
+
+    public init(specVersion: String? = nil, shapeId: String, records: [String: [String]], filters: [Filter], includes: [Include], lastRow: PaginationBoundary? = nil, groupBy: DependenciesGroupBy? = nil, ttl: Int? = nil, validUntil: String? = nil, windowFields: [String]? = nil, softDeleteField: String? = nil, tenant: String? = nil, policy: Filter? = nil) {
+        self.specVersion = specVersion
+        self.shapeId = shapeId
+        self.records = records
+        self.filters = filters
+        self.includes = includes
+        self.lastRow = lastRow
+        self.groupBy = groupBy
+        self.ttl = ttl
+        self.validUntil = validUntil
+        self.windowFields = windowFields
+        self.softDeleteField = softDeleteField
+        self.tenant = tenant
+        self.policy = policy
+    }
+
+    enum CodingKeys: String, CodingKey {
+        case specVersion = "spec_version"
+        case shapeId = "shape_id"
+        case records
+        case filters
+        case includes
+        case lastRow = "last_row"
+        case groupBy = "group_by"
+        case ttl
+        case validUntil = "valid_until"
+        case windowFields = "window_fields"
+        case softDeleteField = "soft_delete_field"
+        case tenant
+        case policy
+    }
+}
@@ -0,0 +1,12 @@
+// Version of IncludeKit Universal Format v0.1
+// Auto-generated from schema/v0-1-0.json
+// DO NOT EDIT - This file is automatically generated
+
+/// Universal Format version this package implements, stamped into Dependencies.specVersion
+public let specVersion = "0.1.0"
+
+/// $id of the JSON Schema this package is generated from
+public let schemaID = "https://github.com/bold-minds/includekit-spec/schema/v0-1-0.json"
+
+/// Version of the Engine contract, which engines report as VersionInfo.contract
+public let contractVersion = "0.1.0"
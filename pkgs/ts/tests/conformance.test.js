@@ -4,9 +4,30 @@ import { readFile } from 'fs/promises';
 import { join, dirname } from 'path';
 import { fileURLToPath } from 'url';
 import {
+  boundary,
+  canonicalize,
+  compareBoundary,
+  crossesBoundary,
+  canonicalizeDependencies,
+  canonicalizeMutation,
   canonicalizeQueryShape,
+  computeMutationId,
   computeShapeId,
+  computeStructuralShapeId,
+  DependenciesSchema,
+  MutationSchema,
+  operators,
+  parseShapeId,
+  recordId,
+  recordIdParts,
+  registerOperator,
+  SHAPE_ID_V1,
+  StatementSchema,
+  validateDependencies,
+  validateMutation,
+  validateTransaction,
   validateStatement,
+  ValidationError,
 } from './dist/index.js';
 
 const __dirname = dirname(fileURLToPath(import.meta.url));
@@ -45,6 +66,9 @@ test('conformance: query shapes produce expected canonical JSON and shapeId', as
 
       const shapeId2 = computeShapeId(canonical2);
       assert.equal(shapeId, shapeId2, 'shapeId should be deterministic');
+
+      assert.equal(computeStructuralShapeId(vector.shape), vector.expectedStructuralShapeId,
+        `Structural shapeId must match expected for ${vector.name}`);
     });
   }
 });
@@ -57,4 +81,228 @@ test('conformance: validation catches invalid shapes', () => {
   assert.throws(() => {
     validateStatement({ query: { model: 'Post', order_by: [{ field: '' }] } }); // empty field
   }, /field must be a non-empty string/);
+
+  const where = (op, value) => ({ query: { model: 'Post', where: { conditions: [{ field: 'f', op, value }] } } });
+  assert.throws(() => validateStatement(where('between', 5)), /between requires a \[lo, hi\] array value/);
+  assert.throws(() => validateStatement(where('in', 'a')), /in requires an array value/);
+  assert.throws(() => validateStatement(where('isNull', 'x')), /isNull requires a boolean or null value/);
+  validateStatement(where('between', [1, 10]));
+  validateStatement(where('exists', false));
+});
+
+test('conformance: strict validation rejects non-pure input', () => {
+  const custom = { query: { model: 'Post', where: { conditions: [{ field: 'title', op: 'custom:soundex', value: 'x' }] } } };
+  validateStatement(custom);
+  assert.throws(() => {
+    validateStatement(custom, { strict: true });
+  }, /Custom operator custom:soundex is not allowed in strict mode/);
+
+  const extension = { query: { model: 'Post', x_raw_sql: '1=1' } };
+  validateStatement(extension);
+  assert.throws(() => {
+    validateStatement(extension, { strict: true });
+  }, /Unknown field x_raw_sql/);
+});
+
+test('conformance: registered custom operators get the values they declare', () => {
+  registerOperator('custom:test_fts', 'one', value => {
+    if (typeof value !== 'string') {
+      throw new Error('requires a string query');
+    }
+  });
+  registerOperator('custom:test_near', 'pair');
+  assert.throws(() => registerOperator('custom:test_fts', 'one'), /already registered/);
+  assert.throws(() => registerOperator('fts', 'one'), /must be named custom:<name>/);
+  assert.deepEqual(operators().filter(op => op.name.startsWith('custom:test_')), [
+    { name: 'custom:test_fts', arity: 'one' },
+    { name: 'custom:test_near', arity: 'pair' },
+  ]);
+
+  const where = (op, value) => ({ query: { model: 'Post', where: { conditions: [{ field: 'body', op, value }] } } });
+  validateStatement(where('custom:test_fts', 'go generics'));
+  validateStatement(where('custom:test_near', [1.5, 2]));
+  validateStatement(where('custom:test_unknown', [1]));
+  assert.throws(() => validateStatement(where('custom:test_fts', ['go'])), /custom:test_fts requires a single value, not an array/);
+  assert.throws(() => validateStatement(where('custom:test_fts', 7)), /custom:test_fts: requires a string query/);
+  assert.throws(() => validateStatement(where('custom:test_near', [1, 2, 3])), /custom:test_near requires a two-element array value/);
+});
+
+test('conformance: shape IDs carry the version that hashed them', () => {
+  const canonical = '{"query":{"model":"Post"}}';
+  const v2 = { algorithm: 'sha512', prefix: 's_', version: 2 };
+  const v1Id = computeShapeId(canonical);
+  const v2Id = computeShapeId(canonical, v2);
+  assert.equal(computeShapeId(canonical, SHAPE_ID_V1), v1Id);
+  assert.match(v2Id, /^s_[0-9a-f]{128}_v2$/);
+
+  assert.deepEqual(parseShapeId(v1Id), { config: SHAPE_ID_V1, digest: v1Id.slice(2) });
+  assert.throws(() => parseShapeId(v2Id), /matches no known shape ID version/);
+  assert.equal(parseShapeId(v2Id, [SHAPE_ID_V1, v2]).config, v2);
+  for (const bad of ['', 's_', v1Id.toUpperCase(), v1Id + '0', v1Id + '_v2']) {
+    assert.throws(() => parseShapeId(bad, [SHAPE_ID_V1, v2]));
+  }
+});
+
+test('conformance: mutations produce expected canonical JSON and mutationId', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'mutations.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+
+  for (const vector of vectors) {
+    await test(`mutation: ${vector.name}`, () => {
+      validateMutation(vector.mutation);
+
+      const canonical = canonicalizeMutation(vector.mutation);
+      assert.equal(canonical, vector.expectedCanonical,
+        `Canonical JSON must match expected for ${vector.name}`);
+
+      const mutationId = computeMutationId(vector.mutation);
+      assert.equal(mutationId, vector.expectedMutationId,
+        `mutationId must match expected for ${vector.name}`);
+      assert.ok(mutationId.startsWith('m_'), 'mutationId should start with m_');
+      assert.equal(mutationId.length, 66, 'mutationId should be 66 characters (m_ + 64 hex)');
+    });
+  }
+});
+
+test('conformance: dependencies produce expected canonical JSON', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'dependencies.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+
+  for (const vector of vectors) {
+    await test(`dependencies: ${vector.name}`, () => {
+      validateDependencies(vector.dependencies);
+
+      if (vector.shape) {
+        assert.equal(computeShapeId(canonicalizeQueryShape(vector.shape)), vector.dependencies.shape_id,
+          `Shape ID must match dependencies.shape_id for ${vector.name}`);
+      }
+
+      assert.equal(canonicalizeDependencies(vector.dependencies), vector.expectedCanonical,
+        `Canonical JSON must match expected for ${vector.name}`);
+    });
+  }
+});
+
+test('conformance: schema-derived Zod schemas accept every vector', async () => {
+  const vectorsDir = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors');
+  const load = async (name) => JSON.parse(await readFile(join(vectorsDir, name), 'utf-8'));
+
+  for (const vector of await load('query-shapes.json')) {
+    StatementSchema.parse(vector.shape);
+  }
+  for (const vector of await load('mutations.json')) {
+    MutationSchema.parse(vector.mutation);
+  }
+  for (const vector of await load('dependencies.json')) {
+    DependenciesSchema.parse(vector.dependencies);
+  }
+
+  // What the schema itself rules out
+  for (const shape of [
+    { query: { model: '' } },
+    { query: { model: 'Post', where: { conditions: [{ field: 'a', op: 'near' }] } } },
+    { query: { model: 'Post', limit: 1.5 } },
+    { query: { model: 'Post' }, pagination: { first: 0 } },
+    { query: { model: 'Post', unknown: true } },
+  ]) {
+    assert.equal(StatementSchema.safeParse(shape).success, false, JSON.stringify(shape));
+  }
+  assert.equal(MutationSchema.safeParse({ changes: [{ model: 'Post', action: 'update', sets: [{ field: 'a' }] }] }).success, false,
+    'KV.value is required, even though it may be any value');
+});
+
+test('conformance: invalid shapes are rejected with the expected error code', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'invalid-shapes.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+  const validators = {
+    statement: validateStatement,
+    mutation: validateMutation,
+    transaction: validateTransaction,
+    dependencies: validateDependencies,
+  };
+
+  for (const vector of vectors) {
+    await test(`invalid: ${vector.name}`, () => {
+      const validate = validators[vector.kind];
+      assert.ok(validate, `unknown vector kind: ${vector.kind}`);
+
+      assert.throws(() => validate(vector.input, { strict: vector.strict }), (err) => {
+        assert.ok(err instanceof ValidationError, `${vector.name} must throw a ValidationError`);
+        assert.equal(err.code, vector.expectedCode, `${vector.name}: ${err.message}`);
+        return true;
+      });
+
+      // Strict vectors are otherwise valid
+      if (vector.strict) {
+        validate(vector.input);
+      }
+    });
+  }
+});
+
+test('conformance: JCS vectors canonicalize as RFC 8785 requires', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'jcs.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+
+  for (const vector of vectors.canonicalize) {
+    assert.equal(canonicalize(JSON.parse(vector.input)), vector.expected, vector.name);
+  }
+
+  for (const n of vectors.numbers) {
+    const value = Buffer.from(n.ieee, 'hex').readDoubleBE(0);
+    if (n.error) {
+      assert.throws(() => canonicalize(value), `${n.ieee} should be rejected`);
+    } else {
+      assert.equal(canonicalize(value), n.expected, n.ieee);
+    }
+  }
+});
+
+test('conformance: record ID vectors encode single and composite keys', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'record-ids.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+
+  for (const vector of vectors.recordIds) {
+    if (vector.error) {
+      assert.throws(() => recordId(...vector.key), `${vector.name} should be rejected`);
+      continue;
+    }
+    const id = recordId(...vector.key);
+    assert.equal(id, vector.expected, vector.name);
+    assert.deepEqual(recordIdParts(id, vector.key.length), vector.key.map(v => recordId(v)), vector.name);
+  }
+});
+
+test('conformance: pagination boundary vectors place rows and changes against last_row', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'pagination-boundaries.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+
+  const places = { '-1': 'before', '0': 'at', '1': 'after' };
+  for (const vector of vectors.boundaries) {
+    const cmp = compareBoundary(vector.lastRow, vector.row);
+    assert.equal(cmp === undefined ? 'unknown' : places[cmp], vector.expected, vector.name);
+  }
+  for (const vector of vectors.changes) {
+    assert.equal(crossesBoundary(vector.lastRow, vector.change), vector.invalidate, vector.name);
+  }
+});
+
+test('conformance: pagination cursor vectors build last_row and cursors from the page\'s last row', async () => {
+  const vectorsPath = join(__dirname, '..', '..', '..', 'tools', 'tests', 'vectors', 'pagination-cursors.json');
+  const vectors = JSON.parse(await readFile(vectorsPath, 'utf-8'));
+
+  const encode = (obj) => Buffer.from(JSON.stringify(obj), 'utf-8').toString('base64');
+  for (const vector of vectors) {
+    validateDependencies(vector.dependencies);
+    const lastRow = vector.dependencies.last_row;
+    assert.deepEqual(boundary(lastRow.order_by, vector.row), lastRow, vector.name);
+
+    // The cursor encodes the boundary row with keys in order_by order
+    const fields = Object.fromEntries(lastRow.order_by.map(ob => [ob.field, lastRow.row[ob.field]]));
+    assert.equal(encode(fields), vector.expectedCursor, vector.name);
+
+    for (const c of vector.changes) {
+      assert.equal(crossesBoundary(lastRow, c.change), c.invalidate, `${vector.name}/${c.name}`);
+    }
+  }
 });
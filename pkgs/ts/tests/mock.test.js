@@ -164,6 +164,58 @@ test('MockIncludeKitEngine: explainInvalidation explains record membership', ()
   assert.ok(result.reasons.includes('record_membership'));
 });
 
+test('MockIncludeKitEngine: explainInvalidation details each change and reason', () => {
+  const engine = new MockIncludeKitEngine();
+  const { shape_id } = engine.addQuery({
+    shape: {
+      query: { model: 'posts', where: { conditions: [{ field: 'published', op: 'eq', value: true }] } },
+      includes: [{ query: { model: 'comments' } }]
+    },
+    result_hint: { posts: [{ id: '1' }, { id: '2' }] }
+  });
+  
+  const result = engine.explainInvalidation({
+    shape_id,
+    mutation: {
+      changes: [
+        { model: 'posts', action: 'update', sets: [{ field: 'title', value: 'x' }], where: { conditions: [{ field: 'id', op: 'in', value: ['2', '3'] }] } },
+        { model: 'comments', action: 'insert', sets: [{ field: 'id', value: 'c1' }] }
+      ]
+    }
+  });
+  
+  assert.deepEqual(result.details, [
+    { change_index: 0, kind: 'record_membership', model: 'posts', matched_record_ids: ['2'] },
+    { change_index: 0, kind: 'filter_dependency', model: 'posts', filter_path: 'filters[0]' },
+    { change_index: 1, kind: 'filter_dependency', model: 'comments', filter_path: 'filters[0]' },
+    { change_index: 1, kind: 'relation_dependency', model: 'comments', filter_path: 'includes[0]' }
+  ]);
+});
+
+test('MockIncludeKitEngine: composite primary keys track and match records by recordId', () => {
+  const engine = new MockIncludeKitEngine();
+  engine.setSchema({
+    version: 1,
+    models: [{ name: 'OrderLine', id: { kind: 'composite', fields: ['orderId', 'lineNo'] } }]
+  });
+  const { shape_id, dependencies } = engine.addQuery({
+    shape: { query: { model: 'OrderLine' } },
+    result_hint: { OrderLine: [{ orderId: 'o1', lineNo: 1 }, { orderId: 'o1', lineNo: 2 }, { orderId: 'o2', lineNo: 1 }] }
+  });
+  assert.deepEqual(dependencies.records.OrderLine, ['["o1",1]', '["o1",2]', '["o2",1]']);
+  
+  const result = engine.explainInvalidation({
+    shape_id,
+    mutation: {
+      changes: [
+        { model: 'OrderLine', action: 'delete', where: { conditions: [{ field: 'orderId', op: 'eq', value: 'o1' }, { field: 'lineNo', op: 'in', value: [2, 3] }] } },
+        { model: 'OrderLine', action: 'insert', sets: [{ field: 'lineNo', value: 1 }, { field: 'orderId', value: 'o2' }] }
+      ]
+    }
+  });
+  assert.deepEqual(result.details.map(d => d.matched_record_ids), [['["o1",2]'], ['["o2",1]']]);
+});
+
 test('MockIncludeKitEngine: explainInvalidation returns false for unknown shape_id', () => {
   const engine = new MockIncludeKitEngine();
   
@@ -178,6 +230,7 @@ test('MockIncludeKitEngine: explainInvalidation returns false for unknown shape_
   
   assert.equal(result.invalidate, false);
   assert.deepEqual(result.reasons, []);
+  assert.deepEqual(result.details, []);
 });
 
 test('MockIncludeKitEngine: reset clears all state', () => {
@@ -216,3 +269,242 @@ test('MockIncludeKitEngine: trackCalls records all method calls', () => {
   assert.equal(calls.addQuery.length, 1);
   assert.equal(calls.getVersion.length, 1);
 });
+
+test('MockIncludeKitEngine: evictExpired removes shapes past ttl or valid_until', () => {
+  let now = new Date('2026-01-01T12:00:00Z');
+  const engine = new MockIncludeKitEngine({ now: () => now });
+  
+  const short = engine.addQuery({
+    shape: { query: { model: 'rates' } },
+    ttl: 60,
+    valid_until: '2026-01-01T12:00:30Z'
+  }).shape_id;
+  const long = engine.addQuery({ shape: { query: { model: 'weather' } }, ttl: 60 });
+  const forever = engine.addQuery({ shape: { query: { model: 'users' } } }).shape_id;
+  
+  assert.equal(long.dependencies.valid_until, '2026-01-01T12:01:00.000Z');
+  assert.deepEqual(engine.evictExpired().evict, []);
+  
+  now = new Date('2026-01-01T12:00:45Z');
+  assert.deepEqual(engine.evictExpired().evict, [short]);
+  assert.equal(engine.getDependencies(short), undefined);
+  
+  now = new Date('2026-01-01T13:00:00Z');
+  assert.deepEqual(engine.evictExpired().evict, [long.shape_id]);
+  assert.ok(engine.getDependencies(forever));
+});
+
+test('MockIncludeKitEngine: commitTx evicts staged changes, rollbackTx discards them', () => {
+  const engine = new MockIncludeKitEngine();
+  const { shape_id } = engine.addQuery({
+    shape: { query: { model: 'users' } },
+    result_hint: { users: [{ id: '1' }] }
+  });
+  const change = { model: 'users', action: 'update', sets: [{ field: 'name', value: 'Bob' }] };
+  
+  engine.beginTx('tx_1');
+  engine.stageChange('tx_1', change);
+  engine.rollbackTx('tx_1');
+  
+  engine.beginTx('tx_1');
+  assert.deepEqual(engine.commitTx('tx_1').evict, []);
+  
+  engine.beginTx('tx_2');
+  engine.stageChange('tx_2', change);
+  assert.deepEqual(engine.commitTx('tx_2').evict, [shape_id]);
+  
+  assert.throws(() => engine.commitTx('tx_2'), /not open/);
+  assert.throws(() => engine.stageChange('missing', change), /not open/);
+});
+
+test('MockIncludeKitEngine: invalidateTransaction evicts once per shape, in seq order', () => {
+  const engine = new MockIncludeKitEngine();
+  const users = engine.addQuery({ shape: { query: { model: 'users' } }, result_hint: { users: [{ id: '1' }] } });
+  const posts = engine.addQuery({ shape: { query: { model: 'posts' } }, result_hint: { posts: [{ id: '1' }] } });
+  engine.addQuery({ shape: { query: { model: 'tags' } }, result_hint: { tags: [{ id: '1' }] } });
+  const insert = (model) => ({ changes: [{ model, action: 'insert', sets: [{ field: 'id', value: '1' }] }] });
+  const tx = { seq: 0, mutations: [insert('users'), insert('posts'), insert('users')] };
+  
+  assert.deepEqual(engine.invalidateTransaction(tx).evict.sort(), [users.shape_id, posts.shape_id].sort());
+  assert.throws(() => engine.invalidateTransaction(tx), /not after 0/);
+  assert.deepEqual(engine.invalidateTransaction({ ...tx, seq: 5 }).evict.length, 2);
+  
+  engine.reset();
+  assert.deepEqual(engine.invalidateTransaction(tx).evict, []);
+});
+
+test('MockIncludeKitEngine: invalidate follows included relations through the schema', () => {
+  const engine = new MockIncludeKitEngine();
+  engine.setSchema({
+    version: 1,
+    models: [
+      { name: 'Post', id: { kind: 'string' }, relations: [{ name: 'comments', target: 'Comment', kind: 'many' }] },
+      { name: 'Comment', id: { kind: 'string' }, relations: [{ name: 'author', target: 'User', kind: 'one' }] },
+      { name: 'User', id: { kind: 'string' } }
+    ]
+  });
+  const { shape_id } = engine.addQuery({
+    shape: {
+      query: { model: 'Post' },
+      includes: [{ query: { model: 'comments' }, includes: [{ query: { model: 'author' } }] }]
+    },
+    result_hint: { Post: [{ id: 'p1' }] }
+  });
+  const insert = (model) => ({ changes: [{ model, action: 'insert', sets: [{ field: 'id', value: 'x' }] }] });
+  
+  assert.deepEqual(engine.invalidate(insert('Comment')).evict, [shape_id]);
+  assert.deepEqual(engine.invalidate(insert('User')).evict, [shape_id]);
+  assert.deepEqual(engine.invalidate(insert('Tag')).evict, []);
+  
+  const { details } = engine.explainInvalidation({ shape_id, mutation: insert('User') });
+  assert.deepEqual(details, [
+    { change_index: 0, kind: 'relation_dependency', model: 'User', filter_path: 'includes[0].includes[0]' }
+  ]);
+});
+
+test('MockIncludeKitEngine: addQuery tracks rows nested under includes by their key field', () => {
+  const engine = new MockIncludeKitEngine({ idField: 'uuid' });
+  engine.setSchema({
+    version: 1,
+    models: [
+      { name: 'Post', id: { kind: 'string', fields: ['PostID'] }, relations: [{ name: 'comments', target: 'Comment', kind: 'many' }] },
+      { name: 'Comment', id: { kind: 'string' }, relations: [{ name: 'author', target: 'User', kind: 'one' }] },
+      { name: 'User', id: { kind: 'string' } }
+    ]
+  });
+  const { dependencies } = engine.addQuery({
+    shape: {
+      query: { model: 'Post' },
+      includes: [{ query: { model: 'comments' }, includes: [{ query: { model: 'author' } }] }]
+    },
+    result_hint: {
+      Post: [
+        { PostID: 'p1', comments: [{ uuid: 'c1', author: { uuid: 'u1' } }, { uuid: 'c2', author: { uuid: 'u1' } }] },
+        { PostID: 'p2', comments: [] }
+      ]
+    }
+  });
+  
+  assert.deepEqual(dependencies.records, { Post: ['p1', 'p2'], Comment: ['c1', 'c2'], User: ['u1'] });
+});
+
+test('MockIncludeKitEngine: addQuery records the boundary of a full page', () => {
+  const engine = new MockIncludeKitEngine();
+  const shape = {
+    query: { model: 'Post', order_by: [{ field: 'views', descending: true }, { field: 'id' }] },
+    pagination: { first: 2 }
+  };
+  const rows = [{ id: 'p1', views: 300 }, { id: 'p2', views: 250 }];
+  const { shape_id, dependencies } = engine.addQuery({ shape, result_hint: { Post: rows } });
+  assert.deepEqual(dependencies.last_row, { order_by: shape.query.order_by, row: { id: 'p2', views: 250 } });
+  
+  const explain = sets => engine.explainInvalidation({
+    shape_id,
+    mutation: { changes: [{ model: 'Post', action: 'insert', sets }] }
+  }).reasons;
+  assert.deepEqual(explain([{ field: 'id', value: 'p3' }, { field: 'views', value: 400 }]), ['record_membership', 'pagination_boundary']);
+  assert.deepEqual(explain([{ field: 'id', value: 'p3' }, { field: 'views', value: 10 }]), ['record_membership']);
+  
+  // A page shorter than first holds every later row, so it has no boundary
+  const partial = engine.addQuery({ shape, result_hint: { Post: rows.slice(0, 1) } });
+  assert.equal(partial.dependencies.last_row, undefined);
+});
+
+test('MockIncludeKitEngine: group_by evicts on changes that add, empty or move between groups', () => {
+  const engine = new MockIncludeKitEngine();
+  const { shape_id, dependencies } = engine.addQuery({
+    shape: { query: { model: 'Post', aggregations: [{ func: 'count' }] }, group_by: ['authorId'] },
+    result_hint: { Post: [{ authorId: 'u1', count: 2 }, { authorId: 'u2', count: 1 }] }
+  });
+  assert.deepEqual(dependencies.group_by, { keys: ['authorId'], values: [{ authorId: 'u1' }, { authorId: 'u2' }] });
+  
+  const byId = { conditions: [{ field: 'id', op: 'eq', value: 'p1' }] };
+  const cases = [
+    [{ action: 'insert', sets: [{ field: 'authorId', value: 'u1' }] }, false],
+    [{ action: 'insert', sets: [{ field: 'authorId', value: 'u3' }] }, true],
+    [{ action: 'update', where: byId, sets: [{ field: 'authorId', value: 'u2' }] }, true],
+    [{ action: 'update', where: byId, sets: [{ field: 'title', value: 'x' }] }, false],
+    [{ action: 'delete', where: { conditions: [{ field: 'authorId', op: 'eq', value: 'u2' }] } }, true],
+    [{ action: 'delete', where: { conditions: [{ field: 'authorId', op: 'eq', value: 'u9' }] } }, false]
+  ];
+  for (const [change, evict] of cases) {
+    const mutation = { changes: [{ model: 'Post', ...change }] };
+    const explained = engine.explainInvalidation({ shape_id, mutation });
+    assert.deepEqual(explained.reasons, evict ? ['group_by'] : [], JSON.stringify(change));
+    assert.equal(engine.invalidate(mutation).evict.length > 0, evict, JSON.stringify(change));
+  }
+});
+
+test('MockIncludeKitEngine: windows evict on updates of their partition and order fields', () => {
+  const engine = new MockIncludeKitEngine();
+  const { shape_id, dependencies } = engine.addQuery({
+    shape: { query: { model: 'Post', windows: [{ func: 'row_number', partition_by: ['authorId'], order_by: [{ field: 'createdAt' }], alias: 'latest' }] } },
+    result_hint: { Post: [{ authorId: 'u1', latest: 1 }] }
+  });
+  assert.deepEqual(dependencies.window_fields, ['authorId', 'createdAt']);
+  
+  const byId = { conditions: [{ field: 'id', op: 'eq', value: 'p9' }] };
+  const cases = [
+    [{ action: 'update', where: byId, sets: [{ field: 'authorId', value: 'u1' }] }, true],
+    [{ action: 'update', where: byId, sets: [{ field: 'createdAt', value: '2024-01-01' }] }, true],
+    [{ action: 'update', where: byId, sets: [{ field: 'title', value: 'x' }] }, false]
+  ];
+  for (const [change, evict] of cases) {
+    const mutation = { changes: [{ model: 'Post', ...change }] };
+    const explained = engine.explainInvalidation({ shape_id, mutation });
+    assert.deepEqual(explained.reasons, evict ? ['window'] : [], JSON.stringify(change));
+    assert.equal(engine.invalidate(mutation).evict.length > 0, evict, JSON.stringify(change));
+  }
+});
+
+test('MockIncludeKitEngine: soft deletes and restores evict queries reading live rows', () => {
+  const engine = new MockIncludeKitEngine();
+  engine.setSchema({ version: 1, models: [{ name: 'Post', id: { kind: 'string' }, soft_delete: 'deletedAt' }] });
+  const live = engine.addQuery({ shape: { query: { model: 'Post' } } });
+  assert.equal(live.dependencies.soft_delete_field, 'deletedAt');
+  const all = engine.addQuery({ shape: { query: { model: 'Post', deleted: 'include' } } });
+  assert.equal(all.dependencies.soft_delete_field, undefined);
+  
+  const byId = { conditions: [{ field: 'id', op: 'eq', value: 'p9' }] };
+  const cases = [
+    [{ action: 'update', where: byId, sets: [{ field: 'deletedAt', value: null }] }, true],
+    [{ action: 'update', where: byId, sets: [{ field: 'deletedAt', value: '2024-01-01' }] }, true],
+    [{ action: 'update', where: byId, sets: [{ field: 'title', value: 'x' }] }, false]
+  ];
+  for (const [change, evict] of cases) {
+    const mutation = { changes: [{ model: 'Post', ...change }] };
+    const explained = engine.explainInvalidation({ shape_id: live.shape_id, mutation });
+    assert.deepEqual(explained.reasons, evict ? ['soft_delete'] : [], JSON.stringify(change));
+    assert.deepEqual(engine.explainInvalidation({ shape_id: all.shape_id, mutation }).reasons, [], JSON.stringify(change));
+  }
+});
+
+test('MockIncludeKitEngine: mutations of one tenant never evict shapes of another', () => {
+  const engine = new MockIncludeKitEngine();
+  const acme = engine.addQuery({ shape: { query: { model: 'Post' }, tenant: 'acme' }, result_hint: { Post: [{ id: 'p1' }] } });
+  const globex = engine.addQuery({ shape: { query: { model: 'Post' }, tenant: 'globex' }, result_hint: { Post: [{ id: 'p1' }] } });
+  assert.equal(acme.dependencies.tenant, 'acme');
+  assert.notEqual(acme.shape_id, globex.shape_id);
+  
+  const changes = [{ model: 'Post', action: 'update', where: { conditions: [{ field: 'id', op: 'eq', value: 'p1' }] }, sets: [{ field: 'title', value: 'x' }] }];
+  assert.deepEqual(engine.explainInvalidation({ shape_id: globex.shape_id, mutation: { changes, tenant: 'acme' } }).reasons, []);
+  assert.deepEqual(engine.invalidate({ changes, tenant: 'acme' }).evict, [acme.shape_id]);
+  assert.deepEqual(engine.invalidate({ changes }).evict.sort(), [acme.shape_id, globex.shape_id].sort());
+});
+
+test('MockIncludeKitEngine: policies get shapes of their own and evict by policy', () => {
+  const engine = new MockIncludeKitEngine();
+  const o1 = { conditions: [{ field: 'orgId', op: 'eq', value: 'o1' }] };
+  const o2 = { conditions: [{ field: 'orgId', op: 'eq', value: 'o2' }] };
+  const first = engine.addQuery({ shape: { query: { model: 'Post' }, policy: o1 }, result_hint: { Post: [{ id: 'p1' }] } });
+  const second = engine.addQuery({ shape: { query: { model: 'Post' }, policy: o2 }, result_hint: { Post: [{ id: 'p1' }] } });
+  assert.deepEqual(first.dependencies.policy, o1);
+  assert.notEqual(first.shape_id, second.shape_id);
+  
+  const move = { model: 'Post', action: 'update', where: { conditions: [{ field: 'id', op: 'eq', value: 'p9' }] }, sets: [{ field: 'orgId', value: 'o1' }] };
+  const explained = engine.explainInvalidation({ shape_id: first.shape_id, mutation: { changes: [move] } });
+  assert.ok(explained.details.some(d => d.kind === 'policy' && d.filter_path === 'policy'));
+  
+  assert.deepEqual(engine.evictPolicy(o1).evict, [first.shape_id]);
+  assert.ok(engine.getDependencies(second.shape_id));
+});
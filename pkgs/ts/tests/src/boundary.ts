@@ -0,0 +1,93 @@
+/**
+ * Pagination boundaries: Dependencies.last_row and the changes that shift a
+ * page
+ */
+
+import type { Change, OrderBy, PaginationBoundary } from '@includekit/spec';
+import { jsonValue, pinnedRows, setRow } from './pins.js';
+
+/**
+ * Build the boundary of a page whose last row is lastRow: the orderBy and the
+ * row's orderBy values. Only a full page has a boundary; a page shorter than
+ * its size holds every row after its start.
+ */
+export function boundary(orderBy: OrderBy[], lastRow: Record<string, unknown>): PaginationBoundary {
+  if (orderBy.length === 0) {
+    throw new Error('pagination: orderBy is required to build a boundary');
+  }
+  const row: Record<string, unknown> = {};
+  for (const ob of orderBy) {
+    if (!(ob.field in lastRow)) {
+      throw new Error(`pagination: row is missing orderBy field "${ob.field}"`);
+    }
+    row[ob.field] = jsonValue(lastRow[ob.field]);
+  }
+  return { order_by: [...orderBy], row };
+}
+
+/**
+ * Place row relative to the last row of a page: -1 if it sorts before, 0 if
+ * it ties on every orderBy field, 1 if it sorts after. Nulls sort as if
+ * larger than any value (last ascending, first descending) unless
+ * nulls_first says otherwise. Undefined when row lacks an orderBy field the
+ * comparison reaches or holds a value that does not compare with the
+ * boundary's.
+ */
+export function compareBoundary(lastRow: PaginationBoundary, row: Record<string, unknown>): number | undefined {
+  for (const ob of lastRow.order_by) {
+    if (!(ob.field in row)) {
+      return undefined;
+    }
+    let a = jsonValue(row[ob.field]);
+    let b = jsonValue(lastRow.row[ob.field]);
+
+    if (a === null || b === null) {
+      if (a === null && b === null) {
+        continue;
+      }
+      const nullsFirst = ob.nulls_first ?? ob.descending === true;
+      return (a === null) === nullsFirst ? -1 : 1;
+    }
+
+    if (ob.case_sensitive === false) {
+      a = typeof a === 'string' ? a.toLowerCase() : a;
+      b = typeof b === 'string' ? b.toLowerCase() : b;
+    }
+    if (typeof a !== typeof b || !['number', 'string', 'boolean'].includes(typeof a)) {
+      return undefined;
+    }
+    let cmp = a === b ? 0 : (a as number | string | boolean) < (b as number | string | boolean) ? -1 : 1;
+    if (ob.descending === true) {
+      cmp = -cmp;
+    }
+    if (cmp !== 0) {
+      return cmp;
+    }
+  }
+  return 0;
+}
+
+/**
+ * Whether change may add a row at or before the last row of a page, remove
+ * one, or move one past it: an insert whose row may sort at or before it, a
+ * delete whose where may select such rows, or an update that sets an orderBy
+ * field of rows that may sort at or before it, before or after the update.
+ * A where only tells the values it requires with eq and in.
+ */
+export function crossesBoundary(lastRow: PaginationBoundary, change: Change): boolean {
+  const sets = setRow(change);
+  const atOrBefore = (row: Record<string, unknown>): boolean => {
+    const cmp = compareBoundary(lastRow, row);
+    return cmp === undefined || cmp <= 0;
+  };
+  if (change.action === 'insert') {
+    return atOrBefore(sets);
+  }
+
+  const pins = pinnedRows(change.where);
+  if (change.action === 'delete') {
+    return pins.some(atOrBefore);
+  }
+  const sorts = lastRow.order_by.some(ob => ob.field in sets);
+  return sorts && pins.some(row => atOrBefore(row) || atOrBefore({ ...row, ...sets }));
+}
@@ -3,28 +3,75 @@
  * RFC 8785: https://tools.ietf.org/html/rfc8785
  */
 
+import { SPEC_DIAGNOSTICS } from './constraints.js';
+
 export function canonicalize(obj: any): string {
-  return JSON.stringify(obj, canonicalReplacer);
+  return serialize(obj) ?? 'null';
 }
 
-function canonicalReplacer(_key: string, value: any): any {
-  if (value && typeof value === 'object' && !Array.isArray(value)) {
-    // Sort object keys
-    const sorted: Record<string, any> = {};
-    Object.keys(value)
-      .sort()
-      .forEach((k) => {
-        sorted[k] = value[k];
-      });
-    return sorted;
-  }
-  return value;
+// serialize writes a value the way RFC 8785 requires. It cannot rely on
+// JSON.stringify for objects: JavaScript enumerates integer-like keys such
+// as "1" before all others, whatever order they were added in.
+function serialize(value: any): string | undefined {
+  if (value !== null && typeof value?.toJSON === 'function') {
+    value = value.toJSON();
+  }
+  if (value === undefined || typeof value === 'function' || typeof value === 'symbol') {
+    return undefined;
+  }
+  if (typeof value === 'number' && !Number.isFinite(value)) {
+    throw new Error(`Cannot canonicalize non-finite number ${value}`);
+  }
+  if (value === null || typeof value !== 'object') {
+    // JSON.stringify already formats numbers as ECMAScript does (-0 as 0)
+    // and escapes strings as RFC 8785 requires
+    return JSON.stringify(value);
+  }
+  if (Array.isArray(value)) {
+    return '[' + value.map((v) => serialize(v) ?? 'null').join(',') + ']';
+  }
+  // Array.prototype.sort compares UTF-16 code units, the order RFC 8785 uses
+  const members: string[] = [];
+  for (const key of Object.keys(value).sort()) {
+    const member = serialize(value[key]);
+    if (member !== undefined) {
+      members.push(JSON.stringify(key) + ':' + member);
+    }
+  }
+  return '{' + members.join(',') + '}';
 }
 
 export function canonicalizeQueryShape(shape: any): string {
-  // Remove diagnostic fields before canonicalization
+  // Remove the fields the schema marks x-diagnostic, the spec version among
+  // them, before canonicalization
   const cleaned = JSON.parse(JSON.stringify(shape));
-  delete cleaned.orm_version;
-  delete cleaned.sdk_version;
+  for (const field of SPEC_DIAGNOSTICS['Statement'] ?? []) {
+    delete cleaned[field];
+  }
+  // The policy is ANDed into the root where, so it is hashed as part of what
+  // the statement reads
+  if (cleaned?.policy != null && cleaned.query) {
+    cleaned.query.where = cleaned.query.where === undefined
+      ? cleaned.policy
+      : { and: [cleaned.query.where, cleaned.policy] };
+    delete cleaned.policy;
+  }
   return canonicalize(cleaned);
 }
+
+/**
+ * Canonicalize a mutation event so replays of the same event can be
+ * recognised. Every field is kept: tx_id tells apart identical writes from
+ * different transactions, and change order is significant.
+ */
+export function canonicalizeMutation(mutation: any): string {
+  return canonicalize(mutation);
+}
+
+/**
+ * Canonicalize a Dependencies document so its checksum is the same on every
+ * transport. Every field is kept.
+ */
+export function canonicalizeDependencies(deps: any): string {
+  return canonicalize(deps);
+}
@@ -0,0 +1,65 @@
+/**
+ * Constraints of IncludeKit Universal Format v0.1 for the validators
+ * Auto-generated from schema/v0-1-0.json
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+/** Values the schema allows for each enum property, by "<Definition>.<property>" */
+export const SPEC_ENUMS: Record<string, readonly string[]> = {
+  'Condition.op': ['eq', 'ne', 'in', 'notIn', 'isNull', 'gt', 'gte', 'lt', 'lte', 'between', 'contains', 'startsWith', 'endsWith', 'like', 'ilike', 'regex', 'has', 'hasSome', 'hasEvery', 'jsonContains', 'lenEq', 'lenGt', 'lenLt', 'exists', 'inQuery', 'existsQuery', 'withinRadius', 'intersects', 'bbox', 'jsonHasKey', 'jsonType'],
+  'Query.deleted': ['include', 'only'],
+  'Window.func': ['row_number', 'rank', 'dense_rank', 'count', 'sum', 'avg', 'min', 'max'],
+  'WindowFrame.unit': ['rows', 'range'],
+  'Aggregate.func': ['count', 'sum', 'avg', 'min', 'max'],
+  'Join.kind': ['inner', 'left'],
+  'Include.kind': ['some', 'every', 'none', 'count'],
+  'RelationCount.op': ['eq', 'ne', 'gt', 'gte', 'lt', 'lte'],
+  'Change.action': ['insert', 'update', 'delete'],
+};
+
+/** Patterns of string properties; a property with an enum as well allows values matching either */
+export const SPEC_PATTERNS: Record<string, RegExp> = {
+  'Condition.op': /^custom:.+$/,
+  'Statement.spec_version': /^\d+\.\d+\.\d+$/,
+  'Dependencies.spec_version': /^\d+\.\d+\.\d+$/,
+  'Dependencies.shape_id': /^s_[0-9a-f]{64}$/,
+};
+
+/** Properties of each definition but the deprecated ones, which is all strict mode allows */
+export const SPEC_KEYS: Record<string, readonly string[]> = {
+  'Condition': ['field', 'field_path', 'op', 'value', 'subquery'],
+  'GeoPoint': ['lat', 'lng'],
+  'GeoShape': ['center', 'radius', 'polygon'],
+  'Filter': ['and', 'or', 'not', 'conditions'],
+  'OrderBy': ['field', 'descending', 'nulls_first', 'case_sensitive'],
+  'Query': ['model', 'fields', 'where', 'order_by', 'limit', 'offset', 'distinct', 'aggregations', 'joins', 'windows', 'distinct_on', 'deleted'],
+  'Window': ['func', 'field', 'partition_by', 'order_by', 'frame', 'alias'],
+  'WindowFrame': ['unit', 'start', 'end'],
+  'DistinctOn': ['fields', 'order_by'],
+  'Aggregate': ['func', 'field', 'alias'],
+  'Join': ['relation', 'kind', 'on', 'fields'],
+  'Include': ['query', 'kind', 'includes', 'count'],
+  'RelationCount': ['op', 'value'],
+  'Pagination': ['first', 'last', 'after', 'before'],
+  'Statement': ['query', 'pagination', 'group_by', 'having', 'includes', 'orm_version', 'sdk_version', 'spec_version', 'tenant', 'policy'],
+  'KV': ['field', 'value'],
+  'Change': ['model', 'action', 'sets', 'where'],
+  'Mutation': ['tx_id', 'changes', 'tenant'],
+  'Transaction': ['seq', 'committed_at', 'mutations'],
+  'PaginationBoundary': ['order_by', 'row', 'cursor'],
+  'Dependencies': ['spec_version', 'shape_id', 'records', 'filters', 'includes', 'last_row', 'group_by', 'ttl', 'valid_until', 'window_fields', 'soft_delete_field', 'tenant', 'policy'],
+  'Dependencies.group_by': ['keys', 'values'],
+};
+
+/** Properties annotated x-diagnostic, by definition, which canonicalization leaves out of shape IDs */
+export const SPEC_DIAGNOSTICS: Record<string, readonly string[]> = {
+  'Statement': ['orm_version', 'sdk_version', 'spec_version'],
+};
+
+/** Whether the schema allows value for an enum or pattern property */
+export function specAllows(key: string, value: unknown): boolean {
+  if (typeof value !== 'string') {
+    return false;
+  }
+  return (SPEC_ENUMS[key]?.includes(value) ?? false) || (SPEC_PATTERNS[key]?.test(value) ?? false);
+}
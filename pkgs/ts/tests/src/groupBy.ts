@@ -0,0 +1,75 @@
+/**
+ * Group-by dimensions: Dependencies.group_by and the changes that alter its
+ * groups
+ */
+
+import type { Change, Dependencies } from '@includekit/spec';
+import { jsonValue, pinnedRows, setRow } from './pins.js';
+
+export type GroupBy = NonNullable<Dependencies['group_by']>;
+
+/**
+ * Build the group_by of a grouped result: the distinct values of keys in
+ * rows, in row order, with a missing key as null
+ */
+export function groupBy(keys: string[], rows: Array<Record<string, unknown>>): GroupBy {
+  const values: Array<Record<string, unknown>> = [];
+  const seen = new Set<string>();
+  for (const row of rows) {
+    const group: Record<string, unknown> = {};
+    for (const key of keys) {
+      group[key] = jsonValue(row?.[key]);
+    }
+    const id = JSON.stringify(keys.map(key => group[key]));
+    if (!seen.has(id)) {
+      seen.add(id);
+      values.push(group);
+    }
+  }
+  return { keys: [...keys], values };
+}
+
+/**
+ * Whether change may add a group, move a row between groups or empty a
+ * listed group: an insert whose row may start a group missing from groupBy,
+ * an update that sets a group key, or a delete whose where may select rows
+ * of a listed group. A where only tells the values it requires with eq and
+ * in.
+ */
+export function changesGroups(groupBy: GroupBy, change: Change): boolean {
+  if (change.action === 'insert') {
+    const sets = setRow(change);
+    return !groupBy.values.some(group => inGroup(groupBy.keys, sets, group) === true);
+  }
+  if (change.action === 'delete') {
+    return pinnedRows(change.where).some(row =>
+      groupBy.values.some(group => inGroup(groupBy.keys, row, group) !== false));
+  }
+  return (change.sets ?? []).some(kv => groupBy.keys.includes(kv.field));
+}
+
+// Whether a partial row belongs to group: undefined when a key is unknown,
+// or its value differs in kind from the group's (an engine may coerce it)
+function inGroup(keys: string[], row: Record<string, unknown>, group: Record<string, unknown>): boolean | undefined {
+  let result: boolean | undefined = true;
+  for (const key of keys) {
+    if (!(key in row)) {
+      result = undefined;
+      continue;
+    }
+    const a = jsonValue(row[key]);
+    const b = jsonValue(group[key]);
+    if (a === null || b === null) {
+      if (a !== b) {
+        return false;
+      }
+      continue;
+    }
+    if (typeof a !== typeof b) {
+      result = undefined;
+    } else if (JSON.stringify(a) !== JSON.stringify(b)) {
+      return false;
+    }
+  }
+  return result;
+}
@@ -1,3 +1,12 @@
 export * from './validators.js';
 export * from './canonicalize.js';
 export * from './shapeId.js';
+export * from './recordId.js';
+export * from './boundary.js';
+export * from './groupBy.js';
+export * from './windows.js';
+export * from './softDelete.js';
+export * from './tenant.js';
+export * from './policy.js';
+export * from './schemas.js';
+export * from './version.js';
@@ -17,5 +17,6 @@ export type {
   InvalidateResponse,
   ExplainRequest,
   ExplainResponse,
+  ExplainReason,
   VersionInfo
 } from './interface.js';
@@ -6,6 +6,8 @@
 import type {
   Statement,
   Mutation,
+  Transaction,
+  Change,
   Dependencies
 } from '@includekit/spec';
 
@@ -16,21 +18,43 @@ export interface AppSchema {
   version: number;
   models: Array<{
     name: string;
-    id: { kind: string };
+    /**
+     * fields lists the primary key fields in key order, e.g. ['uuid'] or
+     * ['orderId', 'lineNo']; omitted means the engine's idField, else "id".
+     * A record of a composite key is tracked under recordId of its values.
+     */
+    id: { kind: string; fields?: string[] };
+    fields?: string[];
     relations?: Array<{
       name: string;
       target: string;
       kind: string;
+      fields?: string[];
+      references?: string[];
     }>;
+    /**
+     * soft_delete names the field marking rows soft-deleted while it is
+     * non-null; queries read only live rows unless they set deleted.
+     */
+    soft_delete?: string;
   }>;
 }
 
 /**
- * Request to add a query and track its dependencies
+ * Request to add a query and track its dependencies.
+ * result_hint maps models to result rows; rows of included relations may be
+ * nested under the relation name, as ORMs return them. Root rows are in page
+ * order: when a shape with an order_by and first (or limit) gets a full page,
+ * its last row becomes dependencies.last_row. For a shape with a group_by, the
+ * distinct group keys of the root rows become dependencies.group_by.
+ * ttl (seconds) and valid_until (RFC 3339) make the shape expire by time;
+ * the earlier bound wins.
  */
 export interface AddQueryRequest {
   shape: Statement;
   result_hint?: Record<string, any[]>;
+  ttl?: number;
+  valid_until?: string;
 }
 
 /**
@@ -64,11 +88,29 @@ export interface ExplainRequest {
 }
 
 /**
- * Response from explainInvalidation
+ * Response from explainInvalidation. reasons lists the distinct reason codes;
+ * details has one entry for each change and reason, in change order.
  */
 export interface ExplainResponse {
   invalidate: boolean;
   reasons: string[];
+  details: ExplainReason[];
+}
+
+/**
+ * One reason a change invalidates a shape
+ */
+export interface ExplainReason {
+  change_index: number;
+  kind: 'record_membership' | 'filter_dependency' | 'relation_dependency' | 'pagination_boundary' | 'group_by' | 'window' | 'soft_delete' | 'policy';
+  model: string;
+  /** Dependency that matched, e.g. "filters[0]", "includes[1]", "last_row", "group_by", "window_fields", "soft_delete_field" or "policy"; absent for record membership */
+  filter_path?: string;
+  /**
+   * Tracked records the change touches, when the engine can tell: the id an
+   * insert sets, or the records selected by a where that tests only the id field
+   */
+  matched_record_ids?: string[];
 }
 
 /**
@@ -81,13 +123,25 @@ export interface VersionInfo {
 }
 
 /**
- * Engine interface matching WASM exports
+ * Engine interface matching WASM exports.
+ *
+ * invalidate evicts immediately. Transactional writers instead beginTx, stage
+ * each change with stageChange, and commitTx to evict for all staged changes
+ * at once; rollbackTx discards staged changes without evicting anything.
+ * Sources that deliver whole commits, such as change data capture, call
+ * invalidateTransaction with each Transaction in commit order.
  */
 export interface IIncludeKitEngine {
   setSchema(schema: AppSchema): void;
   computeShapeId(statement: Statement): ShapeIdResponse;
   addQuery(request: AddQueryRequest): AddQueryResponse;
   invalidate(mutation: Mutation): InvalidateResponse;
+  invalidateTransaction(tx: Transaction): InvalidateResponse;
+  evictExpired(): InvalidateResponse;
+  beginTx(txId: string): void;
+  stageChange(txId: string, change: Change): void;
+  commitTx(txId: string): InvalidateResponse;
+  rollbackTx(txId: string): void;
   explainInvalidation(request: ExplainRequest): ExplainResponse;
   reset(): void;
   getVersion(): VersionInfo;
@@ -5,10 +5,24 @@
 import type {
   Statement,
   Mutation,
+  Transaction,
   Dependencies,
-  Filter
+  Filter,
+  Include,
+  Change,
+  PaginationBoundary,
+  Query
 } from '@includekit/spec';
 import { computeQueryShapeId } from '../shapeId.js';
+import { recordId, recordIdParts, type KeyValue } from '../recordId.js';
+import { boundary, crossesBoundary } from '../boundary.js';
+import { changesGroups, groupBy } from '../groupBy.js';
+import { softDeletes } from '../softDelete.js';
+import { otherTenant } from '../tenant.js';
+import { crossesPolicy, underPolicy } from '../policy.js';
+import { changesWindows, windowFields } from '../windows.js';
+import { validateStatement, validateMutation, validateTransaction } from '../validators.js';
+import { CONTRACT_VERSION, SPEC_VERSION } from '../version.js';
 import type {
   IIncludeKitEngine,
   AppSchema,
@@ -18,6 +32,7 @@ import type {
   InvalidateResponse,
   ExplainRequest,
   ExplainResponse,
+  ExplainReason,
   VersionInfo
 } from './interface.js';
 
@@ -43,6 +58,21 @@ export interface MockEngineConfig {
    * Track all method calls for assertions
    */
   trackCalls?: boolean;
+  
+  /**
+   * Clock for TTL expiry (default: () => new Date())
+   */
+  now?: () => Date;
+  
+  /**
+   * Reject input outside the pure spec subset (see ValidateOptions.strict)
+   */
+  strict?: boolean;
+  
+  /**
+   * Record id field of models the schema gives no key (default: 'id')
+   */
+  idField?: string;
 }
 
 export interface MockEngineCalls {
@@ -50,6 +80,12 @@ export interface MockEngineCalls {
   computeShapeId: Array<{ statement: Statement }>;
   addQuery: Array<{ request: AddQueryRequest }>;
   invalidate: Array<{ mutation: Mutation }>;
+  invalidateTransaction: Array<{ tx: Transaction }>;
+  evictExpired: Array<Record<string, never>>;
+  beginTx: Array<{ txId: string }>;
+  stageChange: Array<{ txId: string; change: Change }>;
+  commitTx: Array<{ txId: string }>;
+  rollbackTx: Array<{ txId: string }>;
   explainInvalidation: Array<{ request: ExplainRequest }>;
   reset: Array<Record<string, never>>;
   getVersion: Array<Record<string, never>>;
@@ -58,6 +94,12 @@ export interface MockEngineCalls {
 export class MockIncludeKitEngine implements IIncludeKitEngine {
   private schema?: AppSchema;
   private shapes = new Map<string, Dependencies>();
+  // Root model of each shape, for resolving includes
+  private roots = new Map<string, string>();
+  private expiry = new Map<string, number>();
+  private txs = new Map<string, Change[]>();
+  // seq of the last transaction applied, -1 before the first
+  private seq = -1;
   private calls: MockEngineCalls;
   
   constructor(private config: MockEngineConfig = {}) {
@@ -70,6 +112,12 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
       computeShapeId: [],
       addQuery: [],
       invalidate: [],
+      invalidateTransaction: [],
+      evictExpired: [],
+      beginTx: [],
+      stageChange: [],
+      commitTx: [],
+      rollbackTx: [],
       explainInvalidation: [],
       reset: [],
       getVersion: []
@@ -88,6 +136,10 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
       this.calls.computeShapeId.push({ statement });
     }
     
+    if (this.config.strict) {
+      validateStatement(statement, { strict: true });
+    }
+    
     let shapeId: string;
     if (this.config.shapeIdGenerator) {
       shapeId = this.config.shapeIdGenerator(statement);
@@ -108,14 +160,52 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     
     // Build dependencies
     const dependencies: Dependencies = {
+      spec_version: SPEC_VERSION,
       shape_id,
       records: this.extractRecords(request),
       filters: this.extractFilters(request.shape),
       includes: request.shape.includes || []
     };
+    const lastRow = this.lastRow(request);
+    if (lastRow) {
+      dependencies.last_row = lastRow;
+    }
+    const keys = request.shape.group_by || [];
+    const model = request.shape.query?.model;
+    if (keys.length > 0 && model && request.result_hint && model in request.result_hint) {
+      // Without a hint for the root model the groups are unknown
+      dependencies.group_by = groupBy(keys, request.result_hint[model]);
+    }
+    const fields = windowFields(request.shape.query);
+    if (fields) {
+      dependencies.window_fields = fields;
+    }
+    const softDelete = this.softDeleteField(request.shape.query);
+    if (softDelete) {
+      dependencies.soft_delete_field = softDelete;
+    }
+    if (request.shape.tenant !== undefined) {
+      dependencies.tenant = request.shape.tenant;
+    }
+    if (request.shape.policy !== undefined) {
+      dependencies.policy = request.shape.policy;
+    }
+    
+    const expiresAt = this.expiresAt(request);
+    this.expiry.delete(shape_id);
+    if (expiresAt !== undefined) {
+      if (request.ttl !== undefined) {
+        dependencies.ttl = request.ttl;
+      }
+      dependencies.valid_until = new Date(expiresAt).toISOString();
+      this.expiry.set(shape_id, expiresAt);
+    }
     
     // Store for invalidation checks
     this.shapes.set(shape_id, dependencies);
+    if (request.shape.query) {
+      this.roots.set(shape_id, request.shape.query.model);
+    }
     
     return { shape_id, dependencies };
   }
@@ -125,6 +215,92 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
       this.calls.invalidate.push({ mutation });
     }
     
+    if (this.config.strict) {
+      validateMutation(mutation, { strict: true });
+    }
+    
+    return this.evictFor([mutation]);
+  }
+  
+  /**
+   * Evict for every mutation of a transaction at once, listing each shape
+   * once. Transactions must arrive in commit order: one whose seq is not
+   * after the last applied is rejected, so replays evict nothing
+   */
+  invalidateTransaction(tx: Transaction): InvalidateResponse {
+    if (this.config.trackCalls) {
+      this.calls.invalidateTransaction.push({ tx });
+    }
+    
+    if (this.config.strict) {
+      validateTransaction(tx, { strict: true });
+    }
+    if (tx.seq <= this.seq) {
+      throw new Error(`transaction seq ${tx.seq} is not after ${this.seq}`);
+    }
+    this.seq = tx.seq;
+    
+    return this.evictFor(tx.mutations);
+  }
+  
+  beginTx(txId: string): void {
+    if (this.config.trackCalls) {
+      this.calls.beginTx.push({ txId });
+    }
+    
+    if (!txId) {
+      throw new Error('tx_id must be non-empty');
+    }
+    if (this.txs.has(txId)) {
+      throw new Error(`transaction "${txId}" is already open`);
+    }
+    
+    this.txs.set(txId, []);
+  }
+  
+  stageChange(txId: string, change: Change): void {
+    if (this.config.trackCalls) {
+      this.calls.stageChange.push({ txId, change });
+    }
+    
+    const staged = this.txs.get(txId);
+    if (!staged) {
+      throw new Error(`transaction "${txId}" is not open`);
+    }
+    if (this.config.strict) {
+      validateMutation({ tx_id: txId, changes: [change] }, { strict: true });
+    }
+    
+    staged.push(change);
+  }
+  
+  commitTx(txId: string): InvalidateResponse {
+    if (this.config.trackCalls) {
+      this.calls.commitTx.push({ txId });
+    }
+    
+    const staged = this.txs.get(txId);
+    if (!staged) {
+      throw new Error(`transaction "${txId}" is not open`);
+    }
+    this.txs.delete(txId);
+    
+    return this.evictFor([{ changes: staged }]);
+  }
+  
+  rollbackTx(txId: string): void {
+    if (this.config.trackCalls) {
+      this.calls.rollbackTx.push({ txId });
+    }
+    
+    if (!this.txs.delete(txId)) {
+      throw new Error(`transaction "${txId}" is not open`);
+    }
+  }
+  
+  // Checks each shape against the changes of the mutations of its own
+  // tenant, or of none
+  private evictFor(mutations: Mutation[]): InvalidateResponse {
     // Custom evict list (for testing)
     if (this.config.evictBehavior === 'custom' && this.config.customEvictList) {
       return { evict: this.config.customEvictList };
@@ -133,8 +309,9 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     const evict: string[] = [];
     
     for (const [shapeId, deps] of this.shapes.entries()) {
-      for (const change of mutation.changes) {
-        const shouldEvict = this.shouldInvalidate(change, deps);
+      const changes = mutations.filter(m => !otherTenant(deps, m)).flatMap(m => m.changes);
+      for (const change of changes) {
+        const shouldEvict = this.shouldInvalidate(change, shapeId, deps);
         if (shouldEvict) {
           evict.push(shapeId);
           break;
@@ -145,54 +322,158 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     return { evict };
   }
   
+  /**
+   * Remove and return shapes whose ttl or valid_until has passed
+   */
+  evictExpired(): InvalidateResponse {
+    if (this.config.trackCalls) {
+      this.calls.evictExpired.push({});
+    }
+    
+    const now = this.now();
+    const evict: string[] = [];
+    
+    for (const [shapeId, expiresAt] of this.expiry.entries()) {
+      if (now >= expiresAt) {
+        evict.push(shapeId);
+        this.expiry.delete(shapeId);
+        this.shapes.delete(shapeId);
+        this.roots.delete(shapeId);
+      }
+    }
+    
+    return { evict: evict.sort() };
+  }
+  
+  /**
+   * Remove and return shapes read under policy, for when it changes. Not part
+   * of the engine interface: policies change outside the mutation stream
+   */
+  evictPolicy(policy: Filter): InvalidateResponse {
+    const evict: string[] = [];
+    
+    for (const [shapeId, deps] of this.shapes.entries()) {
+      if (underPolicy(deps, policy)) {
+        evict.push(shapeId);
+        this.expiry.delete(shapeId);
+        this.shapes.delete(shapeId);
+        this.roots.delete(shapeId);
+      }
+    }
+    
+    return { evict: evict.sort() };
+  }
+  
   explainInvalidation(request: ExplainRequest): ExplainResponse {
     if (this.config.trackCalls) {
       this.calls.explainInvalidation.push({ request });
     }
     
     const deps = this.shapes.get(request.shape_id);
-    if (!deps) {
-      return { invalidate: false, reasons: [] };
+    if (!deps || otherTenant(deps, request.mutation)) {
+      return { invalidate: false, reasons: [], details: [] };
     }
     
     const reasons: string[] = [];
+    const details: ExplainReason[] = [];
     
-    for (const change of request.mutation.changes) {
+    request.mutation.changes.forEach((change, i) => {
       // Check record membership
-      if (deps.records[change.model] && deps.records[change.model].length > 0) {
+      const ids = deps.records[change.model];
+      if (ids) {
         reasons.push('record_membership');
+        const detail: ExplainReason = { change_index: i, kind: 'record_membership', model: change.model };
+        const matched = this.matchedRecords(change, ids);
+        if (matched && matched.length > 0) {
+          detail.matched_record_ids = matched;
+        }
+        details.push(detail);
       }
       
       // Check filter dependencies
-      if (deps.filters.length > 0) {
-        for (const filter of deps.filters) {
-          if (this.filterReferencesModel(filter, change.model)) {
-            reasons.push('filter_dependency');
-            break;
-          }
-        }
+      const filterIndex = deps.filters.findIndex(filter => this.filterReferencesModel(filter, change.model));
+      if (filterIndex >= 0) {
+        reasons.push('filter_dependency');
+        details.push({ change_index: i, kind: 'filter_dependency', model: change.model, filter_path: `filters[${filterIndex}]` });
       }
       
       // Check relation dependencies
-      if (deps.includes.length > 0) {
-        for (const include of deps.includes) {
-          if (include.query?.model === change.model) {
-            reasons.push('relation_dependency');
-            break;
-          }
-        }
+      const included = this.includedModels(request.shape_id, deps).find(rel => rel.model === change.model);
+      if (included) {
+        reasons.push('relation_dependency');
+        details.push({ change_index: i, kind: 'relation_dependency', model: change.model, filter_path: included.path });
       }
-    }
+      
+      // Check the pagination boundary
+      if (this.crossesBoundary(change, request.shape_id, deps)) {
+        reasons.push('pagination_boundary');
+        details.push({ change_index: i, kind: 'pagination_boundary', model: change.model, filter_path: 'last_row' });
+      }
+      
+      // Check the group-by dimensions
+      if (this.changesGroups(change, request.shape_id, deps)) {
+        reasons.push('group_by');
+        details.push({ change_index: i, kind: 'group_by', model: change.model, filter_path: 'group_by' });
+      }
+      
+      // Check the window partitions and order
+      if (this.changesWindows(change, request.shape_id, deps)) {
+        reasons.push('window');
+        details.push({ change_index: i, kind: 'window', model: change.model, filter_path: 'window_fields' });
+      }
+      
+      // Check soft deletes and restores
+      if (this.softDeletes(change, request.shape_id, deps)) {
+        reasons.push('soft_delete');
+        details.push({ change_index: i, kind: 'soft_delete', model: change.model, filter_path: 'soft_delete_field' });
+      }
+      
+      // Check rows moving into or out of the policy
+      if (this.crossesPolicy(change, request.shape_id, deps)) {
+        reasons.push('policy');
+        details.push({ change_index: i, kind: 'policy', model: change.model, filter_path: 'policy' });
+      }
+    });
     
     // Deduplicate reasons
     const uniqueReasons = [...new Set(reasons)];
     
     return {
       invalidate: uniqueReasons.length > 0,
-      reasons: uniqueReasons
+      reasons: uniqueReasons,
+      details
     };
   }
   
+  /**
+   * Tracked ids the change touches, or undefined when that cannot be told
+   */
+  private matchedRecords(change: Change, tracked: string[]): string[] | undefined {
+    const key = this.keyFields(change.model);
+    if (change.action === 'insert') {
+      const row = Object.fromEntries((change.sets ?? []).map(kv => [kv.field, kv.value]));
+      const id = rowId(row, key);
+      return id !== undefined && tracked.includes(id) ? [id] : undefined;
+    }
+    const matched: string[] = [];
+    for (const id of tracked) {
+      let parts: string[];
+      try {
+        parts = recordIdParts(id, key.length);
+      } catch {
+        return undefined;
+      }
+      const ok = matchKey(change.where, key, parts);
+      if (ok === undefined) {
+        return undefined;
+      }
+      if (ok) {
+        matched.push(id);
+      }
+    }
+    return matched;
+  }
+  
   reset(): void {
     if (this.config.trackCalls) {
       this.calls.reset.push({});
@@ -200,6 +481,10 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     
     this.schema = undefined;
     this.shapes.clear();
+    this.roots.clear();
+    this.expiry.clear();
+    this.txs.clear();
+    this.seq = -1;
     
     if (this.config.trackCalls) {
       this.calls = this.initCalls();
@@ -212,36 +497,149 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     }
     
     return {
-      core: 'mock-0.1.0',
-      contract: '0.1.0',
+      core: `mock-${SPEC_VERSION}`,
+      contract: CONTRACT_VERSION,
       abi: '1'
     };
   }
   
   // Helpers
   
-  private extractRecords(request: AddQueryRequest): Record<string, string[]> {
-    if (!request.result_hint || !request.shape.query) {
-      return {};
+  private now(): number {
+    return (this.config.now ? this.config.now() : new Date()).getTime();
+  }
+  
+  private expiresAt(request: AddQueryRequest): number | undefined {
+    let expiresAt: number | undefined;
+    
+    if (request.ttl !== undefined) {
+      if (!Number.isInteger(request.ttl) || request.ttl < 0) {
+        throw new Error(`ttl must be a non-negative integer, got ${request.ttl}`);
+      }
+      expiresAt = this.now() + request.ttl * 1000;
+    }
+    
+    if (request.valid_until !== undefined) {
+      const validUntil = Date.parse(request.valid_until);
+      if (Number.isNaN(validUntil)) {
+        throw new Error(`valid_until must be an RFC 3339 date-time, got ${request.valid_until}`);
+      }
+      if (expiresAt === undefined || validUntil < expiresAt) {
+        expiresAt = validUntil;
+      }
     }
     
+    return expiresAt;
+  }
+  
+  private extractRecords(request: AddQueryRequest): Record<string, string[]> {
     const records: Record<string, string[]> = {};
-    const model = request.shape.query.model;
+    const query = request.shape.query;
+    if (!query) {
+      return records;
+    }
     
-    if (request.result_hint[model]) {
-      const rows = request.result_hint[model];
-      const ids = rows
-        .map(row => row?.id?.toString())
-        .filter((id): id is string => id !== undefined);
-      
-      if (ids.length > 0) {
-        records[model] = ids;
+    const ids = this.hintedIds(request, query.model);
+    if (ids.length > 0) {
+      records[query.model] = ids;
+    }
+    
+    // Included models are tracked with the rows ORMs nest under the relation
+    // in each hinted parent row, however deep.
+    this.nestedIds(records, query.model, request.result_hint?.[query.model] || [], request.shape.includes || []);
+    
+    // Joined models are tracked even without hinted rows: a write to any of
+    // their records can add, drop or change a joined row.
+    for (const join of query.joins || []) {
+      const model = this.joinedModel(query.model, join.relation);
+      if (!(model in records)) {
+        records[model] = this.hintedIds(request, model);
+      }
+    }
+    
+    // So are the models of condition subqueries, nested ones included: a
+    // write to them can change which rows a subquery selects.
+    for (const filter of this.extractFilters(request.shape)) {
+      for (const model of this.subqueryModels(filter)) {
+        if (!(model in records)) {
+          records[model] = this.hintedIds(request, model);
+        }
       }
     }
     
     return records;
   }
   
+  private subqueryModels(filter: Filter | undefined): string[] {
+    if (!filter) {
+      return [];
+    }
+    const models: string[] = [];
+    for (const f of [...(filter.and || []), ...(filter.or || []), ...(filter.not ? [filter.not] : [])]) {
+      models.push(...this.subqueryModels(f));
+    }
+    for (const condition of filter.conditions || []) {
+      const sub = condition.subquery;
+      if (sub) {
+        models.push(sub.model, ...this.subqueryModels(sub.where));
+        for (const join of sub.joins || []) {
+          models.push(...this.subqueryModels(join.on));
+        }
+      }
+    }
+    return models;
+  }
+  
+  private hintedIds(request: AddQueryRequest, model: string): string[] {
+    return this.rowIds(request.result_hint?.[model] || [], model);
+  }
+  
+  private rowIds(rows: any[], model: string): string[] {
+    const key = this.keyFields(model);
+    return rows
+      .map(row => rowId(row, key))
+      .filter((id): id is string => id !== undefined);
+  }
+  
+  // Adds the ids of included rows nested in rows of parent, under the
+  // relation name as a list or a single object, and recurses into their
+  // own includes.
+  private nestedIds(records: Record<string, string[]>, parent: string, rows: any[], includes: Include[]): void {
+    for (const include of includes) {
+      if (!include.query) {
+        continue;
+      }
+      const relation = include.query.model;
+      const nested = rows.flatMap(row => {
+        const value = row && typeof row === 'object' ? row[relation] : undefined;
+        if (Array.isArray(value)) {
+          return value;
+        }
+        return value && typeof value === 'object' ? [value] : [];
+      });
+      if (nested.length === 0) {
+        continue;
+      }
+      const model = this.joinedModel(parent, relation);
+      records[model] = [...new Set([...(records[model] || []), ...this.rowIds(nested, model)])];
+      this.nestedIds(records, model, nested, include.includes || []);
+    }
+  }
+  
+  // Primary key fields of a model: those the schema declares, else the
+  // configured idField, else "id".
+  private keyFields(model: string): string[] {
+    const fields = this.schema?.models.find(m => m.name === model)?.id.fields;
+    return fields && fields.length > 0 ? fields : [this.config.idField || 'id'];
+  }
+  
+  // Resolves a joined relation to its target model through the schema,
+  // falling back to the relation name.
+  private joinedModel(model: string, relation: string): string {
+    const relations = this.schema?.models.find(m => m.name === model)?.relations;
+    return relations?.find(r => r.name === relation)?.target ?? relation;
+  }
+  
   private extractFilters(statement: Statement): Filter[] {
     const filters: Filter[] = [];
     
@@ -253,6 +651,12 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
       filters.push(statement.having);
     }
     
+    for (const join of statement.query?.joins || []) {
+      if (join.on) {
+        filters.push(join.on);
+      }
+    }
+    
     return filters;
   }
   
@@ -262,17 +666,89 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     return !!_filter.conditions && _filter.conditions.length > 0;
   }
   
-  private shouldInvalidate(change: any, deps: Dependencies): boolean {
+  private shouldInvalidate(change: any, shapeId: string, deps: Dependencies): boolean {
     const behavior = this.config.evictBehavior || 'conservative';
     
     if (behavior === 'conservative') {
-      // Conservative: evict if model is tracked
-      return !!deps.records[change.model];
+      // Conservative: evict if model is tracked or loaded by an include
+      return !!deps.records[change.model] ||
+        this.includedModels(shapeId, deps).some(rel => rel.model === change.model) ||
+        this.crossesBoundary(change, shapeId, deps) ||
+        this.changesGroups(change, shapeId, deps) ||
+        this.changesWindows(change, shapeId, deps) ||
+        this.softDeletes(change, shapeId, deps) ||
+        this.crossesPolicy(change, shapeId, deps);
     }
     
     return false;
   }
   
+  private crossesBoundary(change: Change, shapeId: string, deps: Dependencies): boolean {
+    return !!deps.last_row && change.model === this.roots.get(shapeId) && crossesBoundary(deps.last_row, change);
+  }
+  
+  private changesGroups(change: Change, shapeId: string, deps: Dependencies): boolean {
+    return !!deps.group_by && change.model === this.roots.get(shapeId) && changesGroups(deps.group_by, change);
+  }
+  
+  private changesWindows(change: Change, shapeId: string, deps: Dependencies): boolean {
+    return !!deps.window_fields && change.model === this.roots.get(shapeId) && changesWindows(deps.window_fields, change);
+  }
+  
+  private softDeletes(change: Change, shapeId: string, deps: Dependencies): boolean {
+    return !!deps.soft_delete_field && change.model === this.roots.get(shapeId) && softDeletes(deps.soft_delete_field, change);
+  }
+  
+  private crossesPolicy(change: Change, shapeId: string, deps: Dependencies): boolean {
+    return !!deps.policy && change.model === this.roots.get(shapeId) && crossesPolicy(deps.policy, change);
+  }
+  
+  // The soft-delete field the schema names for the root model, unless the
+  // query reads every row whatever it holds.
+  private softDeleteField(query: Query | undefined): string | undefined {
+    if (!query || query.deleted === 'include') {
+      return undefined;
+    }
+    return this.schema?.models.find(m => m.name === query.model)?.soft_delete;
+  }
+  
+  // The boundary of a full forward page: the last hinted row of the root
+  // model, once the hint holds as many rows as first (or the query limit)
+  // asks for.
+  private lastRow(request: AddQueryRequest): PaginationBoundary | undefined {
+    const query = request.shape.query;
+    const orderBy = query?.order_by || [];
+    const size = request.shape.pagination?.first ?? query?.limit;
+    const rows = query ? request.result_hint?.[query.model] || [] : [];
+    if (!query || orderBy.length === 0 || !size || size <= 0 || rows.length < size) {
+      return undefined;
+    }
+    try {
+      return boundary(orderBy, rows[size - 1]);
+    } catch {
+      return undefined;
+    }
+  }
+  
+  // Walks the relation graph from the shape's root model along its
+  // includes, nested ones included, resolving relations as joinedModel does.
+  private includedModels(shapeId: string, deps: Dependencies): Array<{ path: string; model: string }> {
+    const out: Array<{ path: string; model: string }> = [];
+    const visit = (parent: string, includes: Include[] | undefined, path: string): void => {
+      (includes || []).forEach((include, i) => {
+        if (!include.query) {
+          return;
+        }
+        const includePath = `${path}includes[${i}]`;
+        const model = this.joinedModel(parent, include.query.model);
+        out.push({ path: includePath, model });
+        visit(model, include.includes, `${includePath}.`);
+      });
+    };
+    visit(this.roots.get(shapeId) ?? '', deps.includes, '');
+    return out;
+  }
+  
   // Public API for test assertions
   
   /**
@@ -297,3 +773,79 @@ export class MockIncludeKitEngine implements IIncludeKitEngine {
     return this.shapes.get(shapeId);
   }
 }
+
+/**
+ * Record id of a row under key; undefined when the row lacks a key field or
+ * holds a key value with no record id form
+ */
+function rowId(row: any, key: string[]): string | undefined {
+  if (!row || typeof row !== 'object' || !key.every(field => field in row)) {
+    return undefined;
+  }
+  try {
+    return recordId(...key.map(field => row[field] as KeyValue));
+  } catch {
+    return undefined;
+  }
+}
+
+/**
+ * Evaluates a where that tests only key fields (eq, ne, in, notIn) against
+ * the parts of a record id; undefined when it tests anything else
+ */
+function matchKey(where: Filter | undefined, key: string[], parts: string[]): boolean | undefined {
+  if (!where) {
+    return true;
+  }
+  let result = true;
+  for (const c of where.conditions ?? []) {
+    const part = key.indexOf(c.field);
+    if (part < 0 || (c.field_path && c.field_path.length > 0)) {
+      return undefined;
+    }
+    let values: string[];
+    try {
+      values = (Array.isArray(c.value) ? c.value : [c.value]).map(v => recordId(v as KeyValue));
+    } catch {
+      return undefined;
+    }
+    switch (c.op) {
+      case 'eq':
+      case 'in':
+        result = result && values.includes(parts[part]);
+        break;
+      case 'ne':
+      case 'notIn':
+        result = result && !values.includes(parts[part]);
+        break;
+      default:
+        return undefined;
+    }
+  }
+  for (const f of where.and ?? []) {
+    const ok = matchKey(f, key, parts);
+    if (ok === undefined) {
+      return undefined;
+    }
+    result = result && ok;
+  }
+  if (where.or && where.or.length > 0) {
+    let any = false;
+    for (const f of where.or) {
+      const ok = matchKey(f, key, parts);
+      if (ok === undefined) {
+        return undefined;
+      }
+      any = any || ok;
+    }
+    result = result && any;
+  }
+  if (where.not) {
+    const ok = matchKey(where.not, key, parts);
+    if (ok === undefined) {
+      return undefined;
+    }
+    result = result && !ok;
+  }
+  return result;
+}
@@ -0,0 +1,61 @@
+/**
+ * What a change tells about the rows it writes
+ */
+
+import type { Change, Filter } from '@includekit/spec';
+
+// Bounds the rows a where's in lists expand into; past it, further fields
+// are left unpinned
+const MAX_PINNED_ROWS = 64;
+
+/**
+ * The values a change sets, by field
+ */
+export function setRow(change: Change): Record<string, unknown> {
+  const row: Record<string, unknown> = {};
+  for (const kv of change.sets ?? []) {
+    row[kv.field] = kv.value;
+  }
+  return row;
+}
+
+/**
+ * Partial rows covering every row where can select, from the eq and in
+ * conditions it requires, one row per in value
+ */
+export function pinnedRows(where: Filter | undefined): Array<Record<string, unknown>> {
+  let rows: Array<Record<string, unknown>> = [{}];
+  const pin = (f: Filter | undefined): void => {
+    if (!f) {
+      return;
+    }
+    for (const c of f.conditions ?? []) {
+      if ((c.field_path && c.field_path.length > 0) || c.subquery || (c.op !== 'eq' && c.op !== 'in') || c.field in rows[0]) {
+        continue;
+      }
+      let values = [c.value];
+      if (c.op === 'in') {
+        if (!Array.isArray(c.value) || c.value.length === 0 || rows.length * c.value.length > MAX_PINNED_ROWS) {
+          continue;
+        }
+        values = c.value;
+      }
+      rows = rows.flatMap(row => values.map(v => ({ ...row, [c.field]: v })));
+    }
+    for (const g of f.and ?? []) {
+      pin(g);
+    }
+  };
+  pin(where);
+  return rows;
+}
+
+/**
+ * A row value as it compares: null when absent, dates in their JSON form
+ */
+export function jsonValue(v: unknown): unknown {
+  if (v === undefined || v === null) {
+    return null;
+  }
+  return v instanceof Date ? v.toISOString() : v;
+}
@@ -0,0 +1,42 @@
+/**
+ * Policies: Dependencies.policy and the changes that may move rows across it
+ */
+
+import type { Change, Dependencies, Filter } from '@includekit/spec';
+import { canonicalize } from './canonicalize.js';
+
+/**
+ * Whether change, to the root model of a shape read under policy, may move a
+ * row into or out of it: any insert or delete, or an update that sets a field
+ * the policy tests. Values are not compared, so this fires wherever the Go
+ * reference might, and at times where it would not.
+ */
+export function crossesPolicy(policy: Filter, change: Change): boolean {
+  if (change.action !== 'update') {
+    return true;
+  }
+  const fields = new Set<string>();
+  collectFields(policy, fields);
+  return (change.sets ?? []).some(kv => fields.has(kv.field));
+}
+
+/**
+ * Whether deps were recorded under policy, which an engine evicts when the
+ * policy changes. Policies are compared by their canonical JSON.
+ */
+export function underPolicy(deps: Dependencies, policy: Filter): boolean {
+  return deps.policy !== undefined && canonicalize(deps.policy) === canonicalize(policy);
+}
+
+function collectFields(f: Filter | undefined, fields: Set<string>): void {
+  if (!f) {
+    return;
+  }
+  for (const c of f.conditions ?? []) {
+    fields.add(c.field);
+  }
+  for (const g of [...(f.and ?? []), ...(f.or ?? [])]) {
+    collectFields(g, fields);
+  }
+  collectFields(f.not, fields);
+}
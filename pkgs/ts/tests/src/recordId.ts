@@ -0,0 +1,59 @@
+/**
+ * Record IDs: the entries of Dependencies.records
+ */
+
+export type KeyValue = string | number | boolean;
+
+/**
+ * Encode the primary key of a record, given its values in key order. A
+ * single-field key is its value as a string (numbers and booleans in their
+ * JSON form); a composite key is the compact JSON array of its values, e.g.
+ * ["order_1",3].
+ */
+export function recordId(...values: KeyValue[]): string {
+  if (values.length === 0) {
+    throw new Error('record id: at least one key value is required');
+  }
+  values.forEach((v, i) => {
+    const ok = typeof v === 'string' || typeof v === 'boolean' || (typeof v === 'number' && Number.isFinite(v));
+    if (!ok) {
+      throw new Error(`record id: key value ${i} must be a string, finite number or boolean`);
+    }
+  });
+  if (values.length === 1) {
+    return typeof values[0] === 'string' ? values[0] : JSON.stringify(values[0]);
+  }
+  return JSON.stringify(values);
+}
+
+/**
+ * Split the record id of an n-field key into the ids of its parts, each as
+ * recordId would encode that value alone. A single-field id is its own only
+ * part.
+ */
+export function recordIdParts(id: string, n: number): string[] {
+  if (n < 1) {
+    throw new Error('record id: key must have at least one field');
+  }
+  if (n === 1) {
+    return [id];
+  }
+  let values: unknown;
+  try {
+    values = JSON.parse(id);
+  } catch {
+    throw new Error(`record id: ${JSON.stringify(id)} is not a JSON array`);
+  }
+  if (!Array.isArray(values)) {
+    throw new Error(`record id: ${JSON.stringify(id)} is not a JSON array`);
+  }
+  if (values.length !== n) {
+    throw new Error(`record id: ${JSON.stringify(id)} has ${values.length} parts, want ${n}`);
+  }
+  return values.map((v, i) => {
+    if (typeof v !== 'string' && typeof v !== 'number' && typeof v !== 'boolean') {
+      throw new Error(`record id: part ${i} of ${JSON.stringify(id)} is not a scalar`);
+    }
+    return recordId(v);
+  });
+}
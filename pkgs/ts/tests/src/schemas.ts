@@ -0,0 +1,244 @@
+/**
+ * Zod schemas for IncludeKit Universal Format v0.1
+ * Auto-generated from schema/v0-1-0.json
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+import { z } from 'zod';
+import type {
+  Condition,
+  GeoPoint,
+  GeoShape,
+  Filter,
+  OrderBy,
+  Query,
+  Window,
+  WindowFrame,
+  DistinctOn,
+  Aggregate,
+  Join,
+  Include,
+  RelationCount,
+  Pagination,
+  Statement,
+  KV,
+  Change,
+  Mutation,
+  Transaction,
+  PaginationBoundary,
+  Dependencies,
+} from '@includekit/spec';
+
+// A required property of any type: present, but any JSON value
+const jsonValue = z.custom<{} | null>((value) => value !== undefined);
+
+export const ConditionSchema: z.ZodType<Condition> = z.lazy(() =>
+  z.object({
+    field: z.string().min(1),
+    field_path: z.array(z.string().min(1)).optional(),
+    op: z.union([z.enum(['eq', 'ne', 'in', 'notIn', 'isNull', 'gt', 'gte', 'lt', 'lte', 'between', 'contains', 'startsWith', 'endsWith', 'like', 'ilike', 'regex', 'has', 'hasSome', 'hasEvery', 'jsonContains', 'lenEq', 'lenGt', 'lenLt', 'exists', 'inQuery', 'existsQuery', 'withinRadius', 'intersects', 'bbox', 'jsonHasKey', 'jsonType']), z.string().regex(/^custom:.+$/)]),
+    value: z.unknown().optional(),
+    path: z.array(z.string()).optional(),
+    subquery: QuerySchema.optional(),
+  }).strict()
+);
+
+/** A point on the earth, the value of a geospatial field */
+export const GeoPointSchema: z.ZodType<GeoPoint> = z.lazy(() =>
+  z.object({
+    lat: z.number().min(-90).max(90),
+    lng: z.number().min(-180).max(180),
+  }).strict()
+);
+
+/** A circle (center and radius) or a polygon, exactly one of the two */
+export const GeoShapeSchema: z.ZodType<GeoShape> = z.lazy(() =>
+  z.object({
+    center: GeoPointSchema.optional(),
+    radius: z.number().min(0).optional(),
+    polygon: z.array(GeoPointSchema).optional(),
+  }).strict()
+);
+
+export const FilterSchema: z.ZodType<Filter> = z.lazy(() =>
+  z.object({
+    and: z.array(FilterSchema).optional(),
+    or: z.array(FilterSchema).optional(),
+    not: FilterSchema.optional(),
+    conditions: z.array(ConditionSchema).optional(),
+  }).strict()
+);
+
+export const OrderBySchema: z.ZodType<OrderBy> = z.lazy(() =>
+  z.object({
+    field: z.string().min(1),
+    descending: z.boolean().optional(),
+    nulls_first: z.boolean().optional(),
+    case_sensitive: z.boolean().optional(),
+  }).strict()
+);
+
+export const QuerySchema: z.ZodType<Query> = z.lazy(() =>
+  z.object({
+    model: z.string().min(1),
+    fields: z.array(z.string()).optional(),
+    where: FilterSchema.optional(),
+    order_by: z.array(OrderBySchema).optional(),
+    limit: z.number().int().optional(),
+    offset: z.number().int().optional(),
+    distinct: z.array(z.string()).optional(),
+    aggregations: z.array(AggregateSchema).optional(),
+    joins: z.array(JoinSchema).optional(),
+    windows: z.array(WindowSchema).optional(),
+    distinct_on: DistinctOnSchema.optional(),
+    deleted: z.enum(['include', 'only']).optional(),
+  }).strict()
+);
+
+/** A window function column, such as ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC) */
+export const WindowSchema: z.ZodType<Window> = z.lazy(() =>
+  z.object({
+    func: z.enum(['row_number', 'rank', 'dense_rank', 'count', 'sum', 'avg', 'min', 'max']),
+    field: z.string().min(1).optional(),
+    partition_by: z.array(z.string()).optional(),
+    order_by: z.array(OrderBySchema).optional(),
+    frame: WindowFrameSchema.optional(),
+    alias: z.string().min(1),
+  }).strict()
+);
+
+/** Rows of the partition an aggregate window function covers, relative to the current row; without a frame, the partition up to the current row and its order_by peers, or the whole partition when there is no order_by */
+export const WindowFrameSchema: z.ZodType<WindowFrame> = z.lazy(() =>
+  z.object({
+    unit: z.enum(['rows', 'range']),
+    start: z.number().int().optional(),
+    end: z.number().int().optional(),
+  }).strict()
+);
+
+/** Distinct fields tied to the order that picks the row kept for each of their combinations, as in PostgreSQL's SELECT DISTINCT ON (author_id) ... ORDER BY author_id, created_at DESC. The order is separate from the query's order_by, which sorts the kept rows, so the pick need not follow the listing order; distinct already picks by the query's order_by */
+export const DistinctOnSchema: z.ZodType<DistinctOn> = z.lazy(() =>
+  z.object({
+    fields: z.array(z.string()),
+    order_by: z.array(OrderBySchema),
+  }).strict()
+);
+
+export const AggregateSchema: z.ZodType<Aggregate> = z.lazy(() =>
+  z.object({
+    func: z.enum(['count', 'sum', 'avg', 'min', 'max']),
+    field: z.string().min(1).optional(),
+    alias: z.string().min(1).optional(),
+  }).strict()
+);
+
+export const JoinSchema: z.ZodType<Join> = z.lazy(() =>
+  z.object({
+    relation: z.string().min(1),
+    kind: z.enum(['inner', 'left']),
+    on: FilterSchema.optional(),
+    fields: z.array(z.string()).optional(),
+  }).strict()
+);
+
+export const IncludeSchema: z.ZodType<Include> = z.lazy(() =>
+  z.object({
+    query: QuerySchema.optional(),
+    kind: z.enum(['some', 'every', 'none', 'count']).optional(),
+    includes: z.array(IncludeSchema).optional(),
+    count: RelationCountSchema.optional(),
+  }).strict()
+);
+
+/** Filters parents by how many relation rows match the include's where, as in users with more than 5 posts */
+export const RelationCountSchema: z.ZodType<RelationCount> = z.lazy(() =>
+  z.object({
+    op: z.enum(['eq', 'ne', 'gt', 'gte', 'lt', 'lte']),
+    value: z.number().int().min(0),
+  }).strict()
+);
+
+export const PaginationSchema: z.ZodType<Pagination> = z.lazy(() =>
+  z.object({
+    first: z.number().int().min(1).optional(),
+    last: z.number().int().min(1).optional(),
+    after: z.string().optional(),
+    before: z.string().optional(),
+  }).strict()
+);
+
+export const StatementSchema: z.ZodType<Statement> = z.lazy(() =>
+  z.object({
+    query: QuerySchema.optional(),
+    pagination: PaginationSchema.optional(),
+    group_by: z.array(z.string()).optional(),
+    having: FilterSchema.optional(),
+    includes: z.array(IncludeSchema).optional(),
+    orm_version: z.string().optional(),
+    sdk_version: z.string().optional(),
+    spec_version: z.string().regex(/^\d+\.\d+\.\d+$/).optional(),
+    tenant: z.string().min(1).optional(),
+    policy: FilterSchema.optional(),
+  }).strict()
+);
+
+export const KVSchema: z.ZodType<KV> = z.lazy(() =>
+  z.object({
+    field: z.string().min(1),
+    value: jsonValue,
+  }).strict()
+);
+
+export const ChangeSchema: z.ZodType<Change> = z.lazy(() =>
+  z.object({
+    model: z.string().min(1),
+    action: z.enum(['insert', 'update', 'delete']),
+    sets: z.array(KVSchema).optional(),
+    where: FilterSchema.optional(),
+  }).strict()
+);
+
+export const MutationSchema: z.ZodType<Mutation> = z.lazy(() =>
+  z.object({
+    tx_id: z.string().optional(),
+    changes: z.array(ChangeSchema),
+    tenant: z.string().min(1).optional(),
+  }).strict()
+);
+
+export const TransactionSchema: z.ZodType<Transaction> = z.lazy(() =>
+  z.object({
+    seq: z.number().int().min(0),
+    committed_at: z.string().datetime({ offset: true }).optional(),
+    mutations: z.array(MutationSchema),
+  }).strict()
+);
+
+export const PaginationBoundarySchema: z.ZodType<PaginationBoundary> = z.lazy(() =>
+  z.object({
+    order_by: z.array(OrderBySchema),
+    row: z.record(z.string(), z.unknown()),
+    cursor: KVSchema.optional(),
+  }).strict()
+);
+
+export const DependenciesSchema: z.ZodType<Dependencies> = z.lazy(() =>
+  z.object({
+    spec_version: z.string().regex(/^\d+\.\d+\.\d+$/).optional(),
+    shape_id: z.string().regex(/^s_[0-9a-f]{64}$/),
+    records: z.record(z.string(), z.array(z.string())),
+    filters: z.array(FilterSchema),
+    includes: z.array(IncludeSchema),
+    last_row: PaginationBoundarySchema.optional(),
+    group_by: z.object({
+      keys: z.array(z.string()),
+      values: z.array(z.record(z.string(), z.unknown())),
+    }).strict().optional(),
+    ttl: z.number().int().min(0).optional(),
+    valid_until: z.string().datetime({ offset: true }).optional(),
+    window_fields: z.array(z.string()).optional(),
+    soft_delete_field: z.string().min(1).optional(),
+    tenant: z.string().min(1).optional(),
+    policy: FilterSchema.optional(),
+  }).strict()
+);
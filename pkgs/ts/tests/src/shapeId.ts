@@ -3,14 +3,143 @@
  */
 
 import { createHash } from 'crypto';
-import { canonicalizeQueryShape } from './canonicalize.js';
+import { canonicalizeMutation, canonicalizeQueryShape } from './canonicalize.js';
 
-export function computeShapeId(canonicalJson: string): string {
-  const hash = createHash('sha256').update(canonicalJson, 'utf8').digest('hex');
-  return 's_' + hash;
+/**
+ * How shape IDs are derived from canonical JSON: prefix, then the lowercase
+ * hex digest under algorithm (a node:crypto hash name), then "_v<version>"
+ * for versions after the first. A spec revision that rolls the hash adds a
+ * config with a new version, so its IDs never collide with existing cache
+ * keys and parseShapeId can tell them apart.
+ */
+export interface ShapeIdConfig {
+  algorithm: string;
+  prefix: string;
+  version: number;
+}
+
+/**
+ * The shape IDs of this spec version: "s_" followed by the hex SHA-256, with
+ * no version suffix. The schema's shape_id pattern admits only these.
+ */
+export const SHAPE_ID_V1: ShapeIdConfig = { algorithm: 'sha256', prefix: 's_', version: 1 };
+
+export function computeShapeId(canonicalJson: string, config: ShapeIdConfig = SHAPE_ID_V1): string {
+  const hash = createHash(config.algorithm).update(canonicalJson, 'utf8').digest('hex');
+  return config.prefix + hash + versionSuffix(config);
+}
+
+function versionSuffix(config: ShapeIdConfig): string {
+  return config.version > 1 ? `_v${config.version}` : '';
+}
+
+/**
+ * Tell which of configs produced id, and its hex digest. Recognizes
+ * SHAPE_ID_V1 IDs unless configs says otherwise. Throws if id matches none of
+ * them.
+ */
+export function parseShapeId(id: string, configs: ShapeIdConfig[] = [SHAPE_ID_V1]): { config: ShapeIdConfig; digest: string } {
+  for (const config of configs) {
+    const suffix = versionSuffix(config);
+    if (!id.startsWith(config.prefix) || !id.endsWith(suffix) || id.length < config.prefix.length + suffix.length) {
+      continue;
+    }
+    const digest = id.slice(config.prefix.length, id.length - suffix.length);
+    const size = createHash(config.algorithm).digest().length;
+    if (digest.length === 2 * size && /^[0-9a-f]*$/.test(digest)) {
+      return { config, digest };
+    }
+  }
+  throw new Error(`Shape ID ${id} matches no known shape ID version`);
 }
 
 export function computeQueryShapeId(shape: any): string {
   const canonical = canonicalizeQueryShape(shape);
   return computeShapeId(canonical);
 }
+
+/**
+ * Compute a mutationId ("m_" + hex SHA-256 of the canonical mutation) that
+ * engines can use to deduplicate replayed events.
+ */
+export function computeMutationId(mutation: any): string {
+  const hash = createHash('sha256').update(canonicalizeMutation(mutation), 'utf8').digest('hex');
+  return 'm_' + hash;
+}
+
+/**
+ * Compute a shapeId that ignores pagination cursors (after/before), so every
+ * page of one query shares a shape family. Page sizes (first/last) are kept.
+ */
+export function computePageInvariantShapeId(shape: any): string {
+  if (!shape || !shape.pagination) {
+    return computeQueryShapeId(shape);
+  }
+  const { after: _after, before: _before, ...pagination } = shape.pagination;
+  const invariant = { ...shape, pagination };
+  if (pagination.first === undefined && pagination.last === undefined) {
+    delete invariant.pagination;
+  }
+  return computeQueryShapeId(invariant);
+}
+
+/**
+ * Compute a shapeId that ignores literal values: every condition value, in
+ * where, having, join, policy and include filters and in subqueries, becomes
+ * a placeholder naming its JSON type ("<string>", "<number>", "<boolean>",
+ * "<array>" or "<object>"). Absent and null values stay absent.
+ */
+export function computeStructuralShapeId(shape: any): string {
+  const structural = shape ? JSON.parse(JSON.stringify(shape)) : shape;
+  structuralizeQuery(structural?.query);
+  structuralizeFilter(structural?.having);
+  structuralizeFilter(structural?.policy);
+  structuralizeIncludes(structural?.includes);
+  return computeQueryShapeId(structural);
+}
+
+function structuralizeQuery(query: any): void {
+  structuralizeFilter(query?.where);
+  for (const join of query?.joins || []) {
+    structuralizeFilter(join?.on);
+  }
+}
+
+function structuralizeIncludes(includes: any): void {
+  for (const include of includes || []) {
+    structuralizeQuery(include?.query);
+    structuralizeIncludes(include?.includes);
+  }
+}
+
+function structuralizeFilter(filter: any): void {
+  if (!filter) {
+    return;
+  }
+  for (const f of [...(filter.and || []), ...(filter.or || []), filter.not]) {
+    structuralizeFilter(f);
+  }
+  for (const condition of filter.conditions || []) {
+    if (condition.value === undefined || condition.value === null) {
+      delete condition.value;
+    } else {
+      condition.value = typePlaceholder(condition.value);
+    }
+    structuralizeQuery(condition.subquery);
+  }
+}
+
+function typePlaceholder(value: unknown): string {
+  if (Array.isArray(value)) {
+    return '<array>';
+  }
+  switch (typeof value) {
+    case 'string':
+      return '<string>';
+    case 'boolean':
+      return '<boolean>';
+    case 'object':
+      return '<object>';
+  }
+  return '<number>';
+}
@@ -0,0 +1,17 @@
+/**
+ * Soft deletes: Dependencies.soft_delete_field and the changes that set it
+ */
+
+import type { Change } from '@includekit/spec';
+
+/**
+ * Whether change, to the root model of a shape with a soft-delete field, is
+ * an update setting field, which soft-deletes or restores rows as a delete or
+ * insert would. Inserts and deletes are left to the filter bounds.
+ */
+export function softDeletes(field: string, change: Change): boolean {
+  if (change.action !== 'update') {
+    return false;
+  }
+  return (change.sets ?? []).some(kv => kv.field === field);
+}
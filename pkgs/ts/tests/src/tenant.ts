@@ -0,0 +1,14 @@
+/**
+ * Tenants: Dependencies.tenant and the mutations that may concern it
+ */
+
+import type { Dependencies, Mutation } from '@includekit/spec';
+
+/**
+ * Whether mutation writes the rows of a tenant other than the one deps were
+ * recorded for. A mutation or shape without a tenant may concern every
+ * tenant, so it is never another's.
+ */
+export function otherTenant(deps: Dependencies, mutation: Mutation): boolean {
+  return deps.tenant !== undefined && mutation.tenant !== undefined && mutation.tenant !== deps.tenant;
+}
@@ -0,0 +1,14 @@
+/**
+ * Version of IncludeKit Universal Format v0.1
+ * Auto-generated from schema/v0-1-0.json
+ * DO NOT EDIT - This file is automatically generated
+ */
+
+/** Universal Format version this package implements, stamped into Dependencies.spec_version */
+export const SPEC_VERSION = '0.1.0';
+
+/** $id of the JSON Schema this package is generated from */
+export const SCHEMA_ID = 'https://github.com/bold-minds/includekit-spec/schema/v0-1-0.json';
+
+/** Version of the Engine contract, which engines report as VersionInfo.contract */
+export const CONTRACT_VERSION = '0.1.0';
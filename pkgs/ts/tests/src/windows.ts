@@ -0,0 +1,34 @@
+/**
+ * Window columns: Dependencies.window_fields and the changes that alter them
+ */
+
+import type { Change, Query } from '@includekit/spec';
+
+/**
+ * The sorted fields the windows of query partition by, order by or
+ * aggregate, or undefined without windows
+ */
+export function windowFields(query: Query | undefined): string[] | undefined {
+  const fields = new Set<string>();
+  for (const w of query?.windows ?? []) {
+    w.partition_by?.forEach(f => fields.add(f));
+    w.order_by?.forEach(o => fields.add(o.field));
+    if (w.field !== undefined) {
+      fields.add(w.field);
+    }
+  }
+  return fields.size > 0 ? [...fields].sort() : undefined;
+}
+
+/**
+ * Whether change, to the root model of a shape with window columns, is an
+ * update setting one of fields, which may reorder or repartition the rows the
+ * windows are computed over. Inserts and deletes are left to the filter
+ * bounds.
+ */
+export function changesWindows(fields: string[], change: Change): boolean {
+  if (change.action !== 'update') {
+    return false;
+  }
+  return (change.sets ?? []).some(kv => fields.includes(kv.field));
+}
@@ -7,7 +7,10 @@
 export interface IncludeKitUniversalFormatV01 {
   Statement?: Statement;
   Mutation?: Mutation;
+  Transaction?: Transaction;
   Dependencies?: Dependencies;
+  GeoPoint?: GeoPoint;
+  GeoShape?: GeoShape;
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -27,6 +30,18 @@ export interface Statement {
    * Diagnostic only; excluded from canonicalization
    */
   sdk_version?: string;
+  /**
+   * Spec version the SDK emitted the statement with; absent means 0.1.0. Validators reject versions newer than they support. Excluded from canonicalization
+   */
+  spec_version?: string;
+  /**
+   * Tenant the statement reads for, in multi-tenant engines. Hashed into the shape ID, so tenants never share a shape; absent for reads that are not tenant-scoped
+   */
+  tenant?: string;
+  /**
+   * Row-level visibility predicate of the reader, ANDed with the root query's where when reading and when hashing the shape ID, so one query under different policies never shares a shape; Dependencies.policy keeps it apart
+   */
+  policy?: Filter;
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -40,6 +55,116 @@ export interface Query {
   limit?: number;
   offset?: number;
   distinct?: string[];
+  aggregations?: Aggregate[];
+  joins?: Join[];
+  /**
+   * Window function columns, computed over the rows the where selects
+   */
+  windows?: Window[];
+  /**
+   * Keeps one row per combination of fields, chosen by its own order; order_by, offset and limit then apply to the kept rows. Not combined with distinct
+   */
+  distinct_on?: DistinctOn;
+  /**
+   * Soft-deleted rows to read, on models whose schema names a soft-delete field: include reads them alongside live rows, only reads nothing else; live rows only when omitted
+   */
+  deleted?: "include" | "only";
+}
+/**
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "Aggregate".
+ */
+export interface Aggregate {
+  func: "count" | "sum" | "avg" | "min" | "max";
+  /**
+   * Aggregated field; omitted only for count, meaning COUNT(*)
+   */
+  field?: string;
+  /**
+   * Result column name; defaults to <func> for COUNT(*) and <func>_<field> otherwise
+   */
+  alias?: string;
+}
+/**
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "Join".
+ */
+export interface Join {
+  /**
+   * Relation of the query's model to join, as in Include
+   */
+  relation: string;
+  kind: "inner" | "left";
+  /**
+   * Join conditions on the joined model's fields
+   */
+  on?: Filter;
+  /**
+   * Joined model fields to project; none when omitted
+   */
+  fields?: string[];
+}
+/**
+ * A window function column, such as ROW_NUMBER() OVER (PARTITION BY author_id ORDER BY views DESC)
+ *
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "Window".
+ */
+export interface Window {
+  func: "row_number" | "rank" | "dense_rank" | "count" | "sum" | "avg" | "min" | "max";
+  /**
+   * Aggregated field; required for sum, avg, min and max, optional for count (COUNT(*) when omitted), and not taken by the ranking functions
+   */
+  field?: string;
+  /**
+   * Fields whose values split the rows into partitions; one partition when omitted
+   */
+  partition_by?: string[];
+  /**
+   * Order of the rows within a partition; required for the ranking functions
+   */
+  order_by?: OrderBy[];
+  frame?: WindowFrame;
+  /**
+   * Result column name
+   */
+  alias: string;
+}
+/**
+ * Rows of the partition an aggregate window function covers, relative to the current row; without a frame, the partition up to the current row and its order_by peers, or the whole partition when there is no order_by
+ *
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "WindowFrame".
+ */
+export interface WindowFrame {
+  /**
+   * Whether start and end count rows, or differences of the single order_by value
+   */
+  unit: "rows" | "range";
+  /**
+   * Offset of the first row of the frame: negative precedes the current row, 0 is the current row; unbounded preceding when omitted
+   */
+  start?: number;
+  /**
+   * Offset of the last row of the frame, no less than start; unbounded following when omitted
+   */
+  end?: number;
+}
+/**
+ * Distinct fields tied to the order that picks the row kept for each of their combinations, as in PostgreSQL's SELECT DISTINCT ON (author_id) ... ORDER BY author_id, created_at DESC. The order is separate from the query's order_by, which sorts the kept rows, so the pick need not follow the listing order; distinct already picks by the query's order_by
+ *
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "DistinctOn".
+ */
+export interface DistinctOn {
+  /**
+   * Fields whose combinations are kept once; non-empty and without repeats
+   */
+  fields: string[];
+  /**
+   * Starts with one entry for each of fields, in any order; the first row in this order is kept for each combination
+   */
+  order_by: OrderBy[];
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -58,7 +183,7 @@ export interface Filter {
 export interface Condition {
   field: string;
   /**
-   * Optional path for nested field access (e.g., ['address', 'city'])
+   * Optional path into the JSON value of field (e.g., ['address', 'city']): each segment is an object key, or an array index in plain decimal
    */
   field_path?: string[];
   op:
@@ -87,14 +212,28 @@ export interface Condition {
         | "lenGt"
         | "lenLt"
         | "exists"
+        | "inQuery"
+        | "existsQuery"
+        | "withinRadius"
+        | "intersects"
+        | "bbox"
+        | "jsonHasKey"
+        | "jsonType"
       )
     | string;
+  /**
+   * Operand of op; withinRadius and intersects take a GeoShape, bbox a [southwest, northeast] pair of GeoPoints
+   */
   value?: unknown;
   /**
    * @deprecated
    * Deprecated: use field_path instead
    */
   path?: string[];
+  /**
+   * Sub-select for inQuery and existsQuery, selecting exactly one field
+   */
+  subquery?: Query;
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -122,8 +261,25 @@ export interface Pagination {
  */
 export interface Include {
   query?: Query;
-  kind?: "some" | "every" | "none";
+  kind?: "some" | "every" | "none" | "count";
   includes?: Include[];
+  /**
+   * Threshold on the number of matching relation rows; set exactly when kind is count
+   */
+  count?: RelationCount;
+}
+/**
+ * Filters parents by how many relation rows match the include's where, as in users with more than 5 posts
+ *
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "RelationCount".
+ */
+export interface RelationCount {
+  op: "eq" | "ne" | "gt" | "gte" | "lt" | "lte";
+  /**
+   * Number of matching relation rows op compares against
+   */
+  value: number;
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -132,6 +288,10 @@ export interface Include {
 export interface Mutation {
   tx_id?: string;
   changes: Change[];
+  /**
+   * Tenant whose rows the mutation writes; it invalidates only that tenant's shapes and those without a tenant. Absent means it may write rows of any tenant
+   */
+  tenant?: string;
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -156,7 +316,14 @@ export interface KV {
  * via the `definition` "Dependencies".
  */
 export interface Dependencies {
+  /**
+   * Spec version the document was written with; absent means 0.1.0
+   */
+  spec_version?: string;
   shape_id: string;
+  /**
+   * Record IDs by model; a composite key's ID is the JSON array of its values in key order
+   */
   records: {
     [k: string]: string[];
   };
@@ -169,6 +336,81 @@ export interface Dependencies {
       [k: string]: unknown;
     }[];
   };
+  /**
+   * Seconds after registration before the shape is stale, for sources that never emit mutations
+   */
+  ttl?: number;
+  /**
+   * RFC 3339 instant after which the shape is stale
+   */
+  valid_until?: string;
+  /**
+   * Fields the root query's windows partition by, order by or aggregate; an update that sets one may change window columns
+   */
+  window_fields?: string[];
+  /**
+   * Soft-delete field of the root model, when the root query leaves out soft-deleted rows or reads only them; an update that sets it removes or restores rows as a delete or insert would
+   */
+  soft_delete_field?: string;
+  /**
+   * Tenant of the statement; mutations of other tenants never invalidate the shape
+   */
+  tenant?: string;
+  /**
+   * Policy of the statement, tracked apart from filters; a change that may move a row into or out of it invalidates, and a changed policy evicts every shape recorded under it
+   */
+  policy?: Filter;
+}
+/**
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "Transaction".
+ */
+export interface Transaction {
+  /**
+   * Position in the source's commit order; strictly increasing per source
+   */
+  seq: number;
+  /**
+   * RFC 3339 instant the source committed the transaction
+   */
+  committed_at?: string;
+  mutations: Mutation[];
+}
+/**
+ * A point on the earth, the value of a geospatial field
+ *
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "GeoPoint".
+ */
+export interface GeoPoint {
+  /**
+   * Latitude in WGS 84 degrees
+   */
+  lat: number;
+  /**
+   * Longitude in WGS 84 degrees
+   */
+  lng: number;
+}
+/**
+ * A circle (center and radius) or a polygon, exactly one of the two
+ *
+ * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
+ * via the `definition` "GeoShape".
+ */
+export interface GeoShape {
+  /**
+   * Center of a circle; set with radius, not polygon
+   */
+  center?: GeoPoint;
+  /**
+   * Radius of a circle in meters
+   */
+  radius?: number;
+  /**
+   * Three or more vertices of a polygon, implicitly closed, with edges straight in latitude and longitude
+   */
+  polygon?: GeoPoint[];
 }
 /**
  * This interface was referenced by `IncludeKitUniversalFormatV01`'s JSON-Schema
@@ -184,3 +426,7 @@ export interface PaginationBoundary {
   };
   cursor?: KV;
 }
+/** @deprecated Use Statement */
+export type QueryShape = Statement;
+/** @deprecated Use Mutation */
+export type MutationEvent = Mutation;
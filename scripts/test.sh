@@ -14,6 +14,12 @@ cd "$REPO_ROOT/codegen" || exit 1
 go build -o ../bin/codegen .
 cd "$REPO_ROOT" || exit 1
 
+# Hand-written Go types and the schema reference must match what the schema
+# would produce
+echo "🔍 Checking Go types and schema reference against the schema..."
+cd "$REPO_ROOT" || exit 1
+./bin/codegen -lang go,docs -check
+
 # Generate code from schema
 echo "🔧 Generating code from schema..."
 cd "$REPO_ROOT" || exit 1
@@ -35,6 +41,15 @@ echo "🧪 Testing Go..."
 cd "$REPO_ROOT/pkgs/go" || exit 1
 go test ./...
 
+echo ""
+if command -v cargo >/dev/null 2>&1; then
+    echo "🧪 Testing Rust..."
+    cd "$REPO_ROOT/pkgs/rust" || exit 1
+    cargo test --quiet
+else
+    echo "⚠️  cargo not found, skipping Rust tests"
+fi
+
 echo ""
 echo "🔍 Verifying no-runtime constraint..."
 cd "$REPO_ROOT" || exit 1
@@ -2,20 +2,42 @@
 package main
 
 import (
+	"bytes"
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 )
 
+// TestVector also carries the structural shape ID, computed with every
+// condition value replaced by its type placeholder.
 type TestVector struct {
+	Name                      string      `json:"name"`
+	Shape                     interface{} `json:"shape"`
+	ExpectedCanonical         string      `json:"expectedCanonical"`
+	ExpectedShapeID           string      `json:"expectedShapeId"`
+	ExpectedStructuralShapeID string      `json:"expectedStructuralShapeId"`
+}
+
+type MutationVector struct {
+	Name               string      `json:"name"`
+	Mutation           interface{} `json:"mutation"`
+	ExpectedCanonical  string      `json:"expectedCanonical"`
+	ExpectedMutationID string      `json:"expectedMutationId"`
+}
+
+// DependenciesVector optionally carries the shape the dependencies were
+// recorded for; its shape ID must equal dependencies.shape_id.
+type DependenciesVector struct {
 	Name              string      `json:"name"`
-	Shape             interface{} `json:"shape"`
+	Shape             interface{} `json:"shape,omitempty"`
+	Dependencies      interface{} `json:"dependencies"`
 	ExpectedCanonical string      `json:"expectedCanonical"`
-	ExpectedShapeID   string      `json:"expectedShapeId"`
 }
 
 func main() {
@@ -123,6 +145,110 @@ func main() {
 				},
 			},
 		},
+		{
+			Name: "with-relation-count",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "User",
+				},
+				"includes": []map[string]interface{}{
+					{
+						"kind":  "count",
+						"count": map[string]interface{}{"op": "gt", "value": 5},
+						"query": map[string]interface{}{
+							"model": "posts",
+							"where": map[string]interface{}{
+								"conditions": []map[string]interface{}{
+									{"field": "published", "op": "eq", "value": true},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "with-windows",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"windows": []map[string]interface{}{
+						{
+							"func":         "row_number",
+							"partition_by": []string{"authorId"},
+							"order_by":     []map[string]interface{}{{"field": "views", "descending": true}},
+							"alias":        "rank",
+						},
+						{
+							"func":     "sum",
+							"field":    "views",
+							"order_by": []map[string]interface{}{{"field": "createdAt"}},
+							"frame":    map[string]interface{}{"unit": "rows", "start": -6, "end": 0},
+							"alias":    "weekly_views",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "with-distinct-on",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"distinct_on": map[string]interface{}{
+						"fields":   []string{"authorId"},
+						"order_by": []map[string]interface{}{{"field": "authorId"}, {"field": "createdAt", "descending": true}},
+					},
+					"order_by": []map[string]interface{}{{"field": "createdAt", "descending": true}},
+					"limit":    10,
+				},
+			},
+		},
+		{
+			// distinct_on picks each author's most viewed post; the query
+			// lists the kept posts newest first
+			Name: "with-distinct-on-own-order",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"distinct_on": map[string]interface{}{
+						"fields":   []string{"authorId"},
+						"order_by": []map[string]interface{}{{"field": "authorId"}, {"field": "views", "descending": true}},
+					},
+					"order_by": []map[string]interface{}{{"field": "createdAt", "descending": true}},
+				},
+			},
+		},
+		{
+			Name: "with-tenant",
+			Shape: map[string]interface{}{
+				"query":  map[string]interface{}{"model": "Post"},
+				"tenant": "acme",
+			},
+		},
+		{
+			Name: "with-policy",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"where": map[string]interface{}{
+						"conditions": []map[string]interface{}{{"field": "published", "op": "eq", "value": true}},
+					},
+				},
+				"policy": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "orgId", "op": "eq", "value": "o1"}},
+				},
+			},
+		},
+		{
+			Name: "with-deleted",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model":   "Post",
+					"deleted": "only",
+				},
+			},
+		},
 		{
 			Name: "with-pagination",
 			Shape: map[string]interface{}{
@@ -185,17 +311,166 @@ func main() {
 				},
 			},
 		},
+		{
+			Name: "with-aggregations",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model":  "Post",
+					"fields": []string{"authorId"},
+					"aggregations": []map[string]interface{}{
+						{"func": "count", "alias": "count"},
+						{"func": "sum", "field": "views", "alias": "totalViews"},
+						{"func": "avg", "field": "rating"},
+					},
+				},
+				"group_by": []string{"authorId"},
+				"having": map[string]interface{}{
+					"conditions": []map[string]interface{}{
+						{"field": "totalViews", "op": "gte", "value": 1000},
+					},
+				},
+			},
+		},
+		{
+			Name: "with-joins",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model":  "Post",
+					"fields": []string{"id", "title"},
+					"joins": []map[string]interface{}{
+						{
+							"relation": "author",
+							"kind":     "inner",
+							"on": map[string]interface{}{
+								"conditions": []map[string]interface{}{
+									{"field": "active", "op": "eq", "value": true},
+								},
+							},
+							"fields": []string{"name"},
+						},
+						{"relation": "category", "kind": "left"},
+					},
+				},
+			},
+		},
+		{
+			Name: "with-subqueries",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"where": map[string]interface{}{
+						"conditions": []map[string]interface{}{
+							{
+								"field": "authorId",
+								"op":    "inQuery",
+								"subquery": map[string]interface{}{
+									"model":  "User",
+									"fields": []string{"id"},
+									"where": map[string]interface{}{
+										"conditions": []map[string]interface{}{{"field": "active", "op": "eq", "value": true}},
+									},
+								},
+							},
+						},
+						"not": map[string]interface{}{
+							"conditions": []map[string]interface{}{
+								{
+									"field":    "categoryId",
+									"op":       "existsQuery",
+									"subquery": map[string]interface{}{"model": "Category", "fields": []string{"id"}},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "with-geo-conditions",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Venue",
+					"where": map[string]interface{}{
+						"conditions": []map[string]interface{}{
+							{
+								"field": "location",
+								"op":    "withinRadius",
+								"value": map[string]interface{}{"center": map[string]interface{}{"lat": 48.8566, "lng": 2.3522}, "radius": 1500},
+							},
+							{
+								"field": "location",
+								"op":    "bbox",
+								"value": []interface{}{map[string]interface{}{"lat": 48.8, "lng": 2.2}, map[string]interface{}{"lat": 48.9, "lng": 2.5}},
+							},
+							{
+								"field": "deliveryArea",
+								"op":    "intersects",
+								"value": map[string]interface{}{"polygon": []interface{}{
+									map[string]interface{}{"lat": 48.85, "lng": 2.34},
+									map[string]interface{}{"lat": 48.87, "lng": 2.34},
+									map[string]interface{}{"lat": 48.87, "lng": 2.37},
+								}},
+							},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "with-json-paths",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"where": map[string]interface{}{
+						"conditions": []map[string]interface{}{
+							{"field": "meta", "field_path": []string{"seo", "keywords", "0"}, "op": "eq", "value": "go"},
+							{"field": "meta", "field_path": []string{"translations"}, "op": "jsonHasKey", "value": "fr"},
+							{"field": "meta", "field_path": []string{"2024"}, "op": "jsonType", "value": "object"},
+						},
+					},
+				},
+			},
+		},
+		{
+			// Same read as simple-query-with-filter, so the same shape IDs
+			Name: "with-diagnostic-fields",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"where": map[string]interface{}{
+						"conditions": []map[string]interface{}{
+							{"field": "published", "op": "eq", "value": true},
+						},
+					},
+				},
+				"orm_version":  "prisma@5.10.0",
+				"sdk_version":  "0.1.0",
+				"spec_version": "0.1.0",
+			},
+		},
 	}
 
 	// Compute canonical JSON and shape IDs
 	for i := range vectors {
-		canonical, err := canonicalize(vectors[i].Shape)
+		canonical, err := canonicalize(withPolicyMerged(withoutDiagnostics(vectors[i].Shape)))
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error canonicalizing %s: %v\n", vectors[i].Name, err)
 			os.Exit(1)
 		}
 		vectors[i].ExpectedCanonical = canonical
 		vectors[i].ExpectedShapeID = computeShapeID(canonical)
+
+		var generic interface{}
+		if err := json.Unmarshal([]byte(canonical), &generic); err != nil {
+			fmt.Fprintf(os.Stderr, "Error decoding %s: %v\n", vectors[i].Name, err)
+			os.Exit(1)
+		}
+		structural, err := canonicalize(structuralize(generic))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error canonicalizing %s: %v\n", vectors[i].Name, err)
+			os.Exit(1)
+		}
+		vectors[i].ExpectedStructuralShapeID = computeShapeID(structural)
 	}
 
 	// Write to file
@@ -212,6 +487,786 @@ func main() {
 	}
 
 	fmt.Printf("✅ Generated %d test vectors in %s\n", len(vectors), outputPath)
+
+	generateMutationVectors()
+	generateDependenciesVectors()
+	generateInvalidShapeVectors()
+	generatePaginationCursorVectors()
+	generateMigrationVectors()
+}
+
+func generateMutationVectors() {
+	vectors := []MutationVector{
+		{
+			Name: "single-insert",
+			Mutation: map[string]interface{}{
+				"changes": []map[string]interface{}{
+					{"model": "Post", "action": "insert", "sets": []map[string]interface{}{
+						{"field": "id", "value": "p1"},
+						{"field": "title", "value": "Hello"},
+					}},
+				},
+			},
+		},
+		{
+			Name: "transaction-with-update-and-delete",
+			Mutation: map[string]interface{}{
+				"tx_id": "tx-42",
+				"changes": []map[string]interface{}{
+					{
+						"model":  "Post",
+						"action": "update",
+						"sets":   []map[string]interface{}{{"field": "views", "value": 10}},
+						"where": map[string]interface{}{
+							"conditions": []map[string]interface{}{{"field": "id", "op": "eq", "value": "p1"}},
+						},
+					},
+					{
+						"model":  "Comment",
+						"action": "delete",
+						"where": map[string]interface{}{
+							"conditions": []map[string]interface{}{{"field": "postId", "op": "eq", "value": "p1"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "tenant-scoped-update",
+			Mutation: map[string]interface{}{
+				"tenant": "acme",
+				"changes": []map[string]interface{}{
+					{
+						"model":  "Post",
+						"action": "update",
+						"sets":   []map[string]interface{}{{"field": "title", "value": "Hello"}},
+						"where": map[string]interface{}{
+							"conditions": []map[string]interface{}{{"field": "id", "op": "eq", "value": "p1"}},
+						},
+					},
+				},
+			},
+		},
+		{
+			Name: "insert-with-json-values",
+			Mutation: map[string]interface{}{
+				"changes": []map[string]interface{}{
+					{"model": "Product", "action": "insert", "sets": []map[string]interface{}{
+						{"field": "sku", "value": "A-1"},
+						{"field": "name", "value": "Café crème"},
+						{"field": "price", "value": 12.5},
+						{"field": "stock", "value": -3},
+						{"field": "discontinued", "value": false},
+						{"field": "supplier", "value": nil},
+						{"field": "tags", "value": []interface{}{"hot", "new"}},
+						{"field": "dimensions", "value": map[string]interface{}{"w": 10, "h": 2.25}},
+					}},
+				},
+			},
+		},
+		{
+			Name: "update-with-compound-where",
+			Mutation: map[string]interface{}{
+				"changes": []map[string]interface{}{
+					{
+						"model":  "Order",
+						"action": "update",
+						"sets":   []map[string]interface{}{{"field": "status", "value": "archived"}},
+						"where": map[string]interface{}{
+							"and": []map[string]interface{}{
+								{"conditions": []map[string]interface{}{{"field": "status", "op": "in", "value": []string{"shipped", "cancelled"}}}},
+								{"or": []map[string]interface{}{
+									{"conditions": []map[string]interface{}{{"field": "total", "op": "between", "value": []int{0, 100}}}},
+									{"not": map[string]interface{}{
+										"conditions": []map[string]interface{}{{"field": "customerId", "op": "isNull", "value": true}},
+									}},
+								}},
+							},
+							"conditions": []map[string]interface{}{
+								{"field": "meta", "field_path": []string{"region", "code"}, "op": "eq", "value": "EU"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for i := range vectors {
+		canonical, err := canonicalize(vectors[i].Mutation)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error canonicalizing %s: %v\n", vectors[i].Name, err)
+			os.Exit(1)
+		}
+		vectors[i].ExpectedCanonical = canonical
+		hash := sha256.Sum256([]byte(canonical))
+		vectors[i].ExpectedMutationID = "m_" + hex.EncodeToString(hash[:])
+	}
+
+	writeVectors(filepath.Join("tools", "tests", "vectors", "mutations.json"), vectors, len(vectors))
+}
+
+func generateDependenciesVectors() {
+	vectors := []DependenciesVector{
+		{
+			Name:  "records-and-filters",
+			Shape: map[string]interface{}{"query": map[string]interface{}{"model": "Post"}},
+			Dependencies: map[string]interface{}{
+				"shape_id": computeShapeID(`{"query":{"model":"Post"}}`),
+				"records":  map[string]interface{}{"Post": []string{"p1", "p2"}, "Comment": []string{"c1"}},
+				"filters": []map[string]interface{}{
+					{"conditions": []map[string]interface{}{{"field": "published", "op": "eq", "value": true}}},
+				},
+				"includes": []map[string]interface{}{},
+			},
+		},
+		{
+			Name:  "composite-record-ids",
+			Shape: map[string]interface{}{"query": map[string]interface{}{"model": "OrderLine"}},
+			Dependencies: map[string]interface{}{
+				"spec_version": "0.1.0",
+				"records":      map[string]interface{}{"OrderLine": []string{`["o1",1]`, `["o1",2]`}, "Order": []string{"o1"}},
+				"filters":      []map[string]interface{}{},
+				"includes":     []map[string]interface{}{},
+				"last_row": map[string]interface{}{
+					"order_by": []map[string]interface{}{{"field": "orderId"}, {"field": "lineNo"}},
+					"row":      map[string]interface{}{"orderId": "o1", "lineNo": 2, "sku": "a"},
+				},
+			},
+		},
+		{
+			Name: "with-last-row-and-ttl",
+			Dependencies: map[string]interface{}{
+				"spec_version": "0.1.0",
+				"shape_id":     computeShapeID(`{"query":{"model":"User"}}`),
+				"records":      map[string]interface{}{},
+				"filters":      []map[string]interface{}{},
+				"includes":     []map[string]interface{}{},
+				"last_row": map[string]interface{}{
+					"order_by": []map[string]interface{}{{"field": "id"}},
+					"row":      map[string]interface{}{"id": "u9", "name": "Ada"},
+				},
+				"ttl": 60,
+			},
+		},
+		{
+			Name: "joined-models",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"joins": []map[string]interface{}{
+						{
+							"relation": "author",
+							"kind":     "inner",
+							"on": map[string]interface{}{
+								"conditions": []map[string]interface{}{{"field": "active", "op": "eq", "value": true}},
+							},
+						},
+						{"relation": "tags", "kind": "left"},
+					},
+				},
+			},
+			Dependencies: map[string]interface{}{
+				"spec_version": "0.1.0",
+				"records":      map[string]interface{}{"Post": []string{"p1"}, "author": []string{"u1"}, "tags": []string{}},
+				"filters": []map[string]interface{}{
+					{"conditions": []map[string]interface{}{{"field": "active", "op": "eq", "value": true}}},
+				},
+				"includes": []map[string]interface{}{},
+			},
+		},
+		{
+			Name: "includes-group-by-and-valid-until",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model":        "Post",
+					"aggregations": []map[string]interface{}{{"func": "count"}},
+				},
+				"group_by": []string{"authorId"},
+				"includes": []map[string]interface{}{
+					{"query": map[string]interface{}{"model": "author", "fields": []string{"name"}}},
+					{"kind": "some", "query": map[string]interface{}{"model": "tags"}},
+				},
+			},
+			Dependencies: map[string]interface{}{
+				"spec_version": "0.1.0",
+				"records":      map[string]interface{}{"author": []string{"u1"}},
+				"filters":      []map[string]interface{}{},
+				"includes": []map[string]interface{}{
+					{"query": map[string]interface{}{"model": "author", "fields": []string{"name"}}},
+					{"kind": "some", "query": map[string]interface{}{"model": "tags"}},
+				},
+				"group_by": map[string]interface{}{
+					"keys":   []string{"authorId"},
+					"values": []map[string]interface{}{{"authorId": "u1"}, {"authorId": nil}},
+				},
+				"valid_until": "2030-01-01T00:00:00Z",
+			},
+		},
+		{
+			Name: "tenant-scoped",
+			Shape: map[string]interface{}{
+				"query":  map[string]interface{}{"model": "Post"},
+				"tenant": "acme",
+			},
+			Dependencies: map[string]interface{}{
+				"spec_version": "0.1.0",
+				"records":      map[string]interface{}{"Post": []string{"p1"}},
+				"filters":      []map[string]interface{}{},
+				"includes":     []map[string]interface{}{},
+				"tenant":       "acme",
+			},
+		},
+		{
+			Name: "policy-scoped",
+			Shape: map[string]interface{}{
+				"query": map[string]interface{}{
+					"model": "Post",
+					"where": map[string]interface{}{
+						"conditions": []map[string]interface{}{{"field": "published", "op": "eq", "value": true}},
+					},
+				},
+				"policy": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "orgId", "op": "eq", "value": "o1"}},
+				},
+			},
+			Dependencies: map[string]interface{}{
+				"spec_version": "0.1.0",
+				"records":      map[string]interface{}{"Post": []string{"p1"}},
+				"filters": []map[string]interface{}{
+					{"conditions": []map[string]interface{}{{"field": "published", "op": "eq", "value": true}}},
+				},
+				"includes": []map[string]interface{}{},
+				"policy": map[string]interface{}{
+					"conditions": []map[string]interface{}{{"field": "orgId", "op": "eq", "value": "o1"}},
+				},
+			},
+		},
+	}
+
+	for i := range vectors {
+		if vectors[i].Shape != nil {
+			shapeCanonical, err := canonicalize(withPolicyMerged(vectors[i].Shape))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error canonicalizing %s shape: %v\n", vectors[i].Name, err)
+				os.Exit(1)
+			}
+			deps := vectors[i].Dependencies.(map[string]interface{})
+			deps["shape_id"] = computeShapeID(shapeCanonical)
+		}
+		canonical, err := canonicalize(vectors[i].Dependencies)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error canonicalizing %s: %v\n", vectors[i].Name, err)
+			os.Exit(1)
+		}
+		vectors[i].ExpectedCanonical = canonical
+	}
+
+	writeVectors(filepath.Join("tools", "tests", "vectors", "dependencies.json"), vectors, len(vectors))
+}
+
+// InvalidVector is a document every implementation must reject with
+// ExpectedCode, the first violation found. Kind is statement, mutation,
+// transaction or dependencies; Strict vectors are only rejected in strict mode.
+type InvalidVector struct {
+	Name         string      `json:"name"`
+	Kind         string      `json:"kind"`
+	Strict       bool        `json:"strict,omitempty"`
+	Input        interface{} `json:"input"`
+	ExpectedCode string      `json:"expectedCode"`
+}
+
+func generateInvalidShapeVectors() {
+	postWhere := map[string]interface{}{
+		"conditions": []map[string]interface{}{{"field": "id", "op": "eq", "value": "p1"}},
+	}
+	query := func(q map[string]interface{}) map[string]interface{} {
+		q["model"] = "Post"
+		return map[string]interface{}{"query": q}
+	}
+	where := func(conditions ...map[string]interface{}) map[string]interface{} {
+		return query(map[string]interface{}{"where": map[string]interface{}{"conditions": conditions}})
+	}
+	change := func(c map[string]interface{}) map[string]interface{} {
+		c["model"] = "Post"
+		return map[string]interface{}{"changes": []map[string]interface{}{c}}
+	}
+	deps := func(overrides map[string]interface{}) map[string]interface{} {
+		d := map[string]interface{}{
+			"shape_id": computeShapeID(`{"query":{"model":"Post"}}`),
+			"records":  map[string]interface{}{"Post": []string{"p1"}},
+			"filters":  []interface{}{},
+			"includes": []interface{}{},
+		}
+		for k, v := range overrides {
+			if v == nil {
+				delete(d, k)
+			} else {
+				d[k] = v
+			}
+		}
+		return d
+	}
+	sets := []map[string]interface{}{{"field": "title", "value": "Hello"}}
+
+	vectors := []InvalidVector{
+		{Name: "empty-model", Kind: "statement", Input: map[string]interface{}{"query": map[string]interface{}{"model": ""}}, ExpectedCode: "IK1001_EMPTY_MODEL"},
+		{Name: "mixed-pagination", Kind: "statement", Input: map[string]interface{}{
+			"query":      map[string]interface{}{"model": "Post"},
+			"pagination": map[string]interface{}{"first": 10, "before": "c1"},
+		}, ExpectedCode: "IK1010_MIXED_PAGINATION"},
+		{Name: "non-positive-first", Kind: "statement", Input: map[string]interface{}{
+			"query":      map[string]interface{}{"model": "Post"},
+			"pagination": map[string]interface{}{"first": 0},
+		}, ExpectedCode: "IK1007_OUT_OF_RANGE"},
+		{Name: "negative-limit", Kind: "statement", Input: query(map[string]interface{}{"limit": -1}), ExpectedCode: "IK1007_OUT_OF_RANGE"},
+		{Name: "empty-condition-field", Kind: "statement", Input: where(map[string]interface{}{"field": "", "op": "eq", "value": 1}), ExpectedCode: "IK1002_EMPTY_FIELD"},
+		{Name: "unknown-operator", Kind: "statement", Input: where(map[string]interface{}{"field": "title", "op": "approx", "value": "x"}), ExpectedCode: "IK1005_INVALID_OPERATOR"},
+		{Name: "between-scalar", Kind: "statement", Input: where(map[string]interface{}{"field": "views", "op": "between", "value": 10}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "within-radius-polygon", Kind: "statement", Input: where(map[string]interface{}{"field": "location", "op": "withinRadius", "value": map[string]interface{}{
+			"polygon": []interface{}{map[string]interface{}{"lat": 0, "lng": 0}, map[string]interface{}{"lat": 1, "lng": 0}, map[string]interface{}{"lat": 0, "lng": 1}},
+		}}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "geo-point-out-of-range", Kind: "statement", Input: where(map[string]interface{}{"field": "location", "op": "withinRadius", "value": map[string]interface{}{
+			"center": map[string]interface{}{"lat": 91, "lng": 0}, "radius": 100,
+		}}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "geo-shape-circle-and-polygon", Kind: "statement", Input: where(map[string]interface{}{"field": "area", "op": "intersects", "value": map[string]interface{}{
+			"center": map[string]interface{}{"lat": 0, "lng": 0}, "radius": 100,
+			"polygon": []interface{}{map[string]interface{}{"lat": 0, "lng": 0}, map[string]interface{}{"lat": 1, "lng": 0}, map[string]interface{}{"lat": 0, "lng": 1}},
+		}}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "bbox-southwest-north-of-northeast", Kind: "statement", Input: where(map[string]interface{}{"field": "location", "op": "bbox", "value": []interface{}{
+			map[string]interface{}{"lat": 10, "lng": 0}, map[string]interface{}{"lat": -10, "lng": 5},
+		}}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "empty-field-path-segment", Kind: "statement", Input: where(map[string]interface{}{"field": "meta", "field_path": []string{"seo", ""}, "op": "eq", "value": "go"}), ExpectedCode: "IK1002_EMPTY_FIELD"},
+		{Name: "unknown-json-type", Kind: "statement", Input: where(map[string]interface{}{"field": "meta", "op": "jsonType", "value": "integer"}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "field-path-on-aggregate", Kind: "statement", Input: map[string]interface{}{
+			"query":    map[string]interface{}{"model": "Post", "aggregations": []map[string]interface{}{{"func": "count", "alias": "total"}}},
+			"group_by": []string{"authorId"},
+			"having":   map[string]interface{}{"conditions": []map[string]interface{}{{"field": "total", "field_path": []string{"value"}, "op": "gt", "value": 1}}},
+		}, ExpectedCode: "IK1013_INVALID_FIELD_PATH"},
+		{Name: "count-kind-without-count", Kind: "statement", Input: map[string]interface{}{
+			"query":    map[string]interface{}{"model": "User"},
+			"includes": []map[string]interface{}{{"kind": "count", "query": map[string]interface{}{"model": "posts"}}},
+		}, ExpectedCode: "IK1004_REQUIRED"},
+		{Name: "count-without-count-kind", Kind: "statement", Input: map[string]interface{}{
+			"query":    map[string]interface{}{"model": "User"},
+			"includes": []map[string]interface{}{{"kind": "some", "count": map[string]interface{}{"op": "gt", "value": 5}, "query": map[string]interface{}{"model": "posts"}}},
+		}, ExpectedCode: "IK1009_INVALID_KIND"},
+		{Name: "negative-relation-count", Kind: "statement", Input: map[string]interface{}{
+			"query":    map[string]interface{}{"model": "User"},
+			"includes": []map[string]interface{}{{"kind": "count", "count": map[string]interface{}{"op": "lt", "value": -1}, "query": map[string]interface{}{"model": "posts"}}},
+		}, ExpectedCode: "IK1007_OUT_OF_RANGE"},
+		{Name: "in-scalar", Kind: "statement", Input: where(map[string]interface{}{"field": "id", "op": "in", "value": "p1"}), ExpectedCode: "IK1006_INVALID_VALUE"},
+		{Name: "duplicate-aggregate-column", Kind: "statement", Input: query(map[string]interface{}{
+			"aggregations": []map[string]interface{}{{"func": "count"}, {"func": "count"}},
+		}), ExpectedCode: "IK1008_INVALID_AGGREGATE"},
+		{Name: "ranking-window-without-order-by", Kind: "statement", Input: query(map[string]interface{}{
+			"windows": []map[string]interface{}{{"func": "rank", "alias": "rank"}},
+		}), ExpectedCode: "IK1014_INVALID_WINDOW"},
+		{Name: "window-frame-start-after-end", Kind: "statement", Input: query(map[string]interface{}{
+			"windows": []map[string]interface{}{{"func": "sum", "field": "views", "frame": map[string]interface{}{"unit": "rows", "start": 1, "end": -1}, "alias": "total"}},
+		}), ExpectedCode: "IK1007_OUT_OF_RANGE"},
+		{Name: "duplicate-window-column", Kind: "statement", Input: query(map[string]interface{}{
+			"aggregations": []map[string]interface{}{{"func": "count"}},
+			"windows":      []map[string]interface{}{{"func": "count", "alias": "count"}},
+		}), ExpectedCode: "IK1014_INVALID_WINDOW"},
+		{Name: "distinct-on-order-by-mismatch", Kind: "statement", Input: query(map[string]interface{}{
+			"distinct_on": map[string]interface{}{"fields": []string{"authorId"}, "order_by": []map[string]interface{}{{"field": "createdAt"}, {"field": "authorId"}}},
+		}), ExpectedCode: "IK1015_INVALID_DISTINCT"},
+		{Name: "distinct-on-repeated-field", Kind: "statement", Input: query(map[string]interface{}{
+			"distinct_on": map[string]interface{}{"fields": []string{"authorId", "authorId"}, "order_by": []map[string]interface{}{{"field": "authorId"}}},
+		}), ExpectedCode: "IK1015_INVALID_DISTINCT"},
+		{Name: "distinct-on-with-distinct", Kind: "statement", Input: query(map[string]interface{}{
+			"distinct":    []string{"authorId"},
+			"distinct_on": map[string]interface{}{"fields": []string{"authorId"}, "order_by": []map[string]interface{}{{"field": "authorId"}}},
+		}), ExpectedCode: "IK1015_INVALID_DISTINCT"},
+		{Name: "unknown-deleted-mode", Kind: "statement", Input: query(map[string]interface{}{
+			"deleted": "exclude",
+		}), ExpectedCode: "IK1016_INVALID_DELETED"},
+		{Name: "invalid-join-kind", Kind: "statement", Input: query(map[string]interface{}{
+			"joins": []map[string]interface{}{{"relation": "author", "kind": "outer"}},
+		}), ExpectedCode: "IK1011_INVALID_JOIN"},
+		{Name: "duplicate-join-relation", Kind: "statement", Input: query(map[string]interface{}{
+			"joins": []map[string]interface{}{{"relation": "author", "kind": "inner"}, {"relation": "author", "kind": "left"}},
+		}), ExpectedCode: "IK1011_INVALID_JOIN"},
+		{Name: "subquery-selecting-two-fields", Kind: "statement", Input: where(map[string]interface{}{
+			"field": "authorId", "op": "inQuery", "subquery": map[string]interface{}{"model": "User", "fields": []string{"id", "name"}},
+		}), ExpectedCode: "IK1012_INVALID_SUBQUERY"},
+		{Name: "subquery-on-eq", Kind: "statement", Input: where(map[string]interface{}{
+			"field": "authorId", "op": "eq", "value": "u1", "subquery": map[string]interface{}{"model": "User", "fields": []string{"id"}},
+		}), ExpectedCode: "IK1012_INVALID_SUBQUERY"},
+		{Name: "malformed-statement-spec-version", Kind: "statement", Input: map[string]interface{}{
+			"query": map[string]interface{}{"model": "Post"}, "spec_version": "0.1",
+		}, ExpectedCode: "IK1202_INVALID_SPEC_VERSION"},
+		{Name: "future-statement-spec-version", Kind: "statement", Input: map[string]interface{}{
+			"query": map[string]interface{}{"model": "Post"}, "spec_version": "99.0.0",
+		}, ExpectedCode: "IK1202_INVALID_SPEC_VERSION"},
+		{Name: "empty-statement-tenant", Kind: "statement", Input: map[string]interface{}{
+			"query": map[string]interface{}{"model": "Post"}, "tenant": "",
+		}, ExpectedCode: "IK1002_EMPTY_FIELD"},
+		{Name: "unknown-policy-operator", Kind: "statement", Input: map[string]interface{}{
+			"query":  map[string]interface{}{"model": "Post"},
+			"policy": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{"field": "orgId", "op": "approx", "value": "o1"}}},
+		}, ExpectedCode: "IK1005_INVALID_OPERATOR"},
+		{Name: "custom-operator-in-strict-mode", Kind: "statement", Strict: true, Input: where(map[string]interface{}{"field": "title", "op": "custom:soundex", "value": "x"}), ExpectedCode: "IK1302_CUSTOM_OPERATOR"},
+		{Name: "unknown-field-in-strict-mode", Kind: "statement", Strict: true, Input: query(map[string]interface{}{"hint": "idx_posts"}), ExpectedCode: "IK1301_UNKNOWN_FIELD"},
+		{Name: "miscased-field-in-strict-mode", Kind: "statement", Strict: true, Input: query(map[string]interface{}{"Limit": 10}), ExpectedCode: "IK1301_UNKNOWN_FIELD"},
+
+		{Name: "invalid-action", Kind: "mutation", Input: change(map[string]interface{}{"action": "upsert", "sets": sets, "where": postWhere}), ExpectedCode: "IK1101_INVALID_ACTION"},
+		{Name: "empty-change-model", Kind: "mutation", Input: map[string]interface{}{
+			"changes": []map[string]interface{}{{"model": "", "action": "insert", "sets": sets}},
+		}, ExpectedCode: "IK1001_EMPTY_MODEL"},
+		{Name: "insert-without-sets", Kind: "mutation", Input: change(map[string]interface{}{"action": "insert"}), ExpectedCode: "IK1102_MISSING_SET"},
+		{Name: "insert-with-where", Kind: "mutation", Input: change(map[string]interface{}{"action": "insert", "sets": sets, "where": postWhere}), ExpectedCode: "IK1105_UNEXPECTED_WHERE"},
+		{Name: "update-without-where", Kind: "mutation", Input: change(map[string]interface{}{"action": "update", "sets": sets}), ExpectedCode: "IK1104_MISSING_WHERE"},
+		{Name: "delete-with-sets", Kind: "mutation", Input: change(map[string]interface{}{"action": "delete", "sets": sets, "where": postWhere}), ExpectedCode: "IK1103_UNEXPECTED_SET"},
+		{Name: "delete-without-where", Kind: "mutation", Input: change(map[string]interface{}{"action": "delete"}), ExpectedCode: "IK1104_MISSING_WHERE"},
+		{Name: "missing-changes", Kind: "mutation", Input: map[string]interface{}{"tx_id": "tx1"}, ExpectedCode: "IK1004_REQUIRED"},
+		{Name: "empty-mutation-tenant", Kind: "mutation", Input: map[string]interface{}{"changes": []interface{}{}, "tenant": ""}, ExpectedCode: "IK1002_EMPTY_FIELD"},
+
+		{Name: "negative-seq", Kind: "transaction", Input: map[string]interface{}{"seq": -1, "mutations": []interface{}{}}, ExpectedCode: "IK1007_OUT_OF_RANGE"},
+		{Name: "malformed-committed-at", Kind: "transaction", Input: map[string]interface{}{"seq": 1, "committed_at": "yesterday", "mutations": []interface{}{}}, ExpectedCode: "IK1203_INVALID_DATE_TIME"},
+		{Name: "missing-mutations", Kind: "transaction", Input: map[string]interface{}{"seq": 1}, ExpectedCode: "IK1004_REQUIRED"},
+		{Name: "invalid-transaction-mutation", Kind: "transaction", Input: map[string]interface{}{
+			"seq": 1, "mutations": []interface{}{change(map[string]interface{}{"action": "delete"})},
+		}, ExpectedCode: "IK1104_MISSING_WHERE"},
+		{Name: "unknown-transaction-field-in-strict-mode", Kind: "transaction", Strict: true, Input: map[string]interface{}{"seq": 1, "source": "pg", "mutations": []interface{}{}}, ExpectedCode: "IK1301_UNKNOWN_FIELD"},
+
+		{Name: "bad-shape-id", Kind: "dependencies", Input: deps(map[string]interface{}{"shape_id": "s_1234"}), ExpectedCode: "IK1201_INVALID_SHAPE_ID"},
+		{Name: "uppercase-shape-id", Kind: "dependencies", Input: deps(map[string]interface{}{"shape_id": "s_" + strings.Repeat("AB", 32)}), ExpectedCode: "IK1201_INVALID_SHAPE_ID"},
+		{Name: "shape-id-without-prefix", Kind: "dependencies", Input: deps(map[string]interface{}{"shape_id": strings.Repeat("ab", 33)}), ExpectedCode: "IK1201_INVALID_SHAPE_ID"},
+		{Name: "malformed-spec-version", Kind: "dependencies", Input: deps(map[string]interface{}{"spec_version": "1.0"}), ExpectedCode: "IK1202_INVALID_SPEC_VERSION"},
+		{Name: "future-spec-version", Kind: "dependencies", Input: deps(map[string]interface{}{"spec_version": "99.0.0"}), ExpectedCode: "IK1202_INVALID_SPEC_VERSION"},
+		{Name: "missing-records", Kind: "dependencies", Input: deps(map[string]interface{}{"records": nil}), ExpectedCode: "IK1004_REQUIRED"},
+		{Name: "negative-ttl", Kind: "dependencies", Input: deps(map[string]interface{}{"ttl": -5}), ExpectedCode: "IK1007_OUT_OF_RANGE"},
+		{Name: "malformed-valid-until", Kind: "dependencies", Input: deps(map[string]interface{}{"valid_until": "tomorrow"}), ExpectedCode: "IK1203_INVALID_DATE_TIME"},
+		{Name: "empty-dependencies-tenant", Kind: "dependencies", Input: deps(map[string]interface{}{"tenant": ""}), ExpectedCode: "IK1002_EMPTY_FIELD"},
+	}
+
+	writeVectors(filepath.Join("tools", "tests", "vectors", "invalid-shapes.json"), vectors, len(vectors))
+}
+
+// PaginationCursorVector is a full page of Shape ending in Row. Its
+// dependencies carry the page's boundary in last_row, built from the
+// order_by fields of Row. ExpectedCursor is the cursor for the next page
+// (keys in order_by order). Changes give whether each change must invalidate
+// the page.
+type PaginationCursorVector struct {
+	Name           string                 `json:"name"`
+	Shape          map[string]interface{} `json:"shape"`
+	Row            map[string]interface{} `json:"row"`
+	Dependencies   map[string]interface{} `json:"dependencies"`
+	ExpectedCursor string                 `json:"expectedCursor"`
+	Changes        []BoundaryChange       `json:"changes"`
+}
+
+type BoundaryChange struct {
+	Name       string                 `json:"name"`
+	Change     map[string]interface{} `json:"change"`
+	Invalidate bool                   `json:"invalidate"`
+}
+
+func generatePaginationCursorVectors() {
+	page := func(model string, orderBy ...map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{
+			"query":      map[string]interface{}{"model": model, "order_by": orderBy},
+			"pagination": map[string]interface{}{"first": 20},
+		}
+	}
+	field := func(name string, opts ...interface{}) map[string]interface{} {
+		ob := map[string]interface{}{"field": name}
+		for i := 0; i < len(opts); i += 2 {
+			ob[opts[i].(string)] = opts[i+1]
+		}
+		return ob
+	}
+	change := func(model, action string, sets map[string]interface{}, where ...map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{"model": model, "action": action}
+		if len(sets) > 0 {
+			keys := make([]string, 0, len(sets))
+			for k := range sets {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			kvs := make([]map[string]interface{}, len(keys))
+			for i, k := range keys {
+				kvs[i] = map[string]interface{}{"field": k, "value": sets[k]}
+			}
+			c["sets"] = kvs
+		}
+		if len(where) > 0 {
+			c["where"] = map[string]interface{}{"conditions": where}
+		}
+		return c
+	}
+	eq := func(f string, v interface{}) map[string]interface{} {
+		return map[string]interface{}{"field": f, "op": "eq", "value": v}
+	}
+	row := func(kv ...interface{}) map[string]interface{} {
+		r := make(map[string]interface{}, len(kv)/2)
+		for i := 0; i < len(kv); i += 2 {
+			r[kv[i].(string)] = kv[i+1]
+		}
+		return r
+	}
+
+	vectors := []PaginationCursorVector{
+		{
+			Name:  "descending-with-id-tiebreaker",
+			Shape: page("Post", field("views", "descending", true), field("id")),
+			Row:   row("views", 250, "id", "p789", "title", "Hello"),
+			Changes: []BoundaryChange{
+				{Name: "insert-before", Change: change("Post", "insert", row("id", "p1", "views", 300)), Invalidate: true},
+				{Name: "insert-after", Change: change("Post", "insert", row("id", "p1", "views", 10))},
+				{Name: "insert-tie-broken-before", Change: change("Post", "insert", row("id", "p100", "views", 250)), Invalidate: true},
+				{Name: "insert-tie-broken-after", Change: change("Post", "insert", row("id", "p800", "views", 250))},
+				{Name: "update-moves-into-page", Change: change("Post", "update", row("views", 900), eq("id", "p1"), eq("views", 5)), Invalidate: true},
+				{Name: "update-stays-after", Change: change("Post", "update", row("views", 6), eq("id", "p1"), eq("views", 5))},
+				{Name: "update-title-only", Change: change("Post", "update", row("title", "Hi"), eq("id", "p789"))},
+				{Name: "delete-in-page", Change: change("Post", "delete", nil, eq("id", "p2"), eq("views", 400)), Invalidate: true},
+			},
+		},
+		{
+			Name:  "newest-first-timestamps",
+			Shape: page("Post", field("createdAt", "descending", true), field("id")),
+			Row:   row("createdAt", "2024-01-15T10:30:00Z", "id", "post_123"),
+			Changes: []BoundaryChange{
+				{Name: "insert-newer", Change: change("Post", "insert", row("createdAt", "2024-02-01T00:00:00Z", "id", "post_900")), Invalidate: true},
+				{Name: "insert-older", Change: change("Post", "insert", row("createdAt", "2023-12-31T23:59:59Z", "id", "post_001"))},
+			},
+		},
+		{
+			Name:  "ascending-nulls-first-null-boundary",
+			Shape: page("Post", field("publishedAt", "nulls_first", true), field("id")),
+			Row:   row("publishedAt", nil, "id", "p5"),
+			Changes: []BoundaryChange{
+				{Name: "insert-null-before", Change: change("Post", "insert", row("publishedAt", nil, "id", "p1")), Invalidate: true},
+				{Name: "insert-null-after", Change: change("Post", "insert", row("publishedAt", nil, "id", "p9"))},
+				{Name: "insert-published", Change: change("Post", "insert", row("publishedAt", "2024-01-15", "id", "p1"))},
+			},
+		},
+		{
+			Name:  "descending-nulls-last",
+			Shape: page("Post", field("publishedAt", "descending", true, "nulls_first", false), field("id")),
+			Row:   row("publishedAt", "2024-01-15", "id", "p5"),
+			Changes: []BoundaryChange{
+				{Name: "insert-null", Change: change("Post", "insert", row("publishedAt", nil, "id", "p1"))},
+				{Name: "insert-later", Change: change("Post", "insert", row("publishedAt", "2024-03-01", "id", "p9")), Invalidate: true},
+			},
+		},
+		{
+			Name:  "case-insensitive-name",
+			Shape: page("User", field("name", "case_sensitive", false), field("id")),
+			Row:   row("name", "bob", "id", "u2"),
+			Changes: []BoundaryChange{
+				{Name: "insert-capitalized-before", Change: change("User", "insert", row("name", "Alice", "id", "u9")), Invalidate: true},
+				{Name: "insert-capitalized-after", Change: change("User", "insert", row("name", "Carol", "id", "u1"))},
+				{Name: "insert-case-tie-broken-before", Change: change("User", "insert", row("name", "BOB", "id", "u1")), Invalidate: true},
+			},
+		},
+		{
+			Name:  "composite-key",
+			Shape: page("OrderLine", field("orderId"), field("lineNo")),
+			Row:   row("orderId", "o1", "lineNo", 2, "sku", "a"),
+			Changes: []BoundaryChange{
+				{Name: "insert-earlier-line", Change: change("OrderLine", "insert", row("orderId", "o1", "lineNo", 1)), Invalidate: true},
+				{Name: "insert-next-order", Change: change("OrderLine", "insert", row("orderId", "o2", "lineNo", 1))},
+				{Name: "delete-later-line", Change: change("OrderLine", "delete", nil, eq("orderId", "o1"), eq("lineNo", 3))},
+				{Name: "delete-unpinned", Change: change("OrderLine", "delete", nil, eq("sku", "a")), Invalidate: true},
+			},
+		},
+		{
+			Name:  "pinned-then-score",
+			Shape: page("Post", field("pinned", "descending", true), field("score", "descending", true), field("id")),
+			Row:   row("pinned", false, "score", 4.5, "id", "café"),
+			Changes: []BoundaryChange{
+				{Name: "insert-pinned", Change: change("Post", "insert", row("pinned", true, "score", 0.5, "id", "z")), Invalidate: true},
+				{Name: "insert-lower-score", Change: change("Post", "insert", row("pinned", false, "score", 4.25, "id", "a"))},
+				{Name: "update-pins-row", Change: change("Post", "update", row("pinned", true), eq("id", "zz"), eq("pinned", false), eq("score", 1)), Invalidate: true},
+			},
+		},
+	}
+
+	for i := range vectors {
+		v := &vectors[i]
+		orderBy := v.Shape["query"].(map[string]interface{})["order_by"].([]map[string]interface{})
+
+		boundary := make(map[string]interface{}, len(orderBy))
+		var cursor bytes.Buffer
+		cursor.WriteByte('{')
+		for j, ob := range orderBy {
+			name := ob["field"].(string)
+			boundary[name] = v.Row[name]
+			if j > 0 {
+				cursor.WriteByte(',')
+			}
+			cursor.WriteString(mustEncode(name) + ":" + mustEncode(v.Row[name]))
+		}
+		cursor.WriteByte('}')
+		v.ExpectedCursor = base64.StdEncoding.EncodeToString(cursor.Bytes())
+
+		shapeCanonical, err := canonicalize(v.Shape)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error canonicalizing %s shape: %v\n", v.Name, err)
+			os.Exit(1)
+		}
+		v.Dependencies = map[string]interface{}{
+			"spec_version": "0.1.0",
+			"shape_id":     computeShapeID(shapeCanonical),
+			"records":      map[string]interface{}{},
+			"filters":      []map[string]interface{}{},
+			"includes":     []map[string]interface{}{},
+			"last_row":     map[string]interface{}{"order_by": orderBy, "row": boundary},
+		}
+	}
+
+	writeVectors(filepath.Join("tools", "tests", "vectors", "pagination-cursors.json"), vectors, len(vectors))
+}
+
+// MigrationVector is a statement in the format of spec version From that
+// migrates to Expected in the current format, keeping the shape ID an SDK
+// built against the current version would compute.
+type MigrationVector struct {
+	Name            string      `json:"name"`
+	From            string      `json:"from"`
+	Statement       interface{} `json:"statement"`
+	Expected        interface{} `json:"expected"`
+	ExpectedShapeID string      `json:"expectedShapeId"`
+}
+
+// generateMigrationVectors writes round trips through the migration chain.
+// 0.1.0 is the first spec version, so its statements migrate unchanged; a
+// version that renames fields or restructures filters adds vectors in its
+// old format here.
+func generateMigrationVectors() {
+	current := []map[string]interface{}{
+		{"query": map[string]interface{}{"model": "Post"}},
+		{
+			"query": map[string]interface{}{
+				"model": "Post",
+				"where": map[string]interface{}{
+					"or": []map[string]interface{}{
+						{"conditions": []map[string]interface{}{{"field": "featured", "op": "eq", "value": true}}},
+						{"not": map[string]interface{}{
+							"conditions": []map[string]interface{}{{"field": "views", "op": "lt", "value": 100}},
+						}},
+					},
+				},
+				"order_by": []map[string]interface{}{{"field": "createdAt", "descending": true}},
+			},
+			"pagination": map[string]interface{}{"first": 20},
+		},
+		{
+			"query": map[string]interface{}{"model": "User"},
+			"includes": []map[string]interface{}{
+				{
+					"kind": "some",
+					"query": map[string]interface{}{
+						"model": "posts",
+						"where": map[string]interface{}{
+							"conditions": []map[string]interface{}{{
+								"field":    "categoryId",
+								"op":       "inQuery",
+								"subquery": map[string]interface{}{"model": "Category", "fields": []string{"id"}},
+							}},
+						},
+					},
+				},
+			},
+		},
+	}
+	vectors := []MigrationVector{
+		{Name: "minimal-query", From: "0.1.0", Statement: current[0], Expected: current[0]},
+		{Name: "nested-filters-and-pagination", From: "0.1.0", Statement: current[1], Expected: current[1]},
+		{Name: "relation-filter-with-subquery", From: "0.1.0", Statement: current[2], Expected: current[2]},
+	}
+
+	for i := range vectors {
+		canonical, err := canonicalize(vectors[i].Expected)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error canonicalizing %s: %v\n", vectors[i].Name, err)
+			os.Exit(1)
+		}
+		vectors[i].ExpectedShapeID = computeShapeID(canonical)
+	}
+
+	writeVectors(filepath.Join("tools", "tests", "vectors", "migrations.json"), vectors, len(vectors))
+}
+
+// mustEncode returns the JSON of v without HTML escaping.
+func mustEncode(v interface{}) string {
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "Error encoding %v: %v\n", v, err)
+		os.Exit(1)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func writeVectors(outputPath string, vectors interface{}, count int) {
+	data, err := json.MarshalIndent(vectors, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error marshaling vectors: %v\n", err)
+		os.Exit(1)
+	}
+
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error writing file: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Generated %d test vectors in %s\n", count, outputPath)
+}
+
+// diagnosticFields are the Statement properties the schema annotates
+// x-diagnostic, which canonicalization leaves out of shapes.
+var diagnosticFields = []string{"orm_version", "sdk_version", "spec_version"}
+
+// withoutDiagnostics returns a copy of a statement without its diagnostic
+// fields.
+func withoutDiagnostics(shape interface{}) interface{} {
+	stmt, ok := shape.(map[string]interface{})
+	if !ok {
+		return shape
+	}
+	out := make(map[string]interface{}, len(stmt))
+	for k, v := range stmt {
+		out[k] = v
+	}
+	for _, field := range diagnosticFields {
+		delete(out, field)
+	}
+	return out
+}
+
+// withPolicyMerged returns a copy of a statement with its policy ANDed into
+// the root where, as shape IDs hash it.
+func withPolicyMerged(shape interface{}) interface{} {
+	stmt, ok := shape.(map[string]interface{})
+	if !ok || stmt["policy"] == nil {
+		return shape
+	}
+	query, ok := stmt["query"].(map[string]interface{})
+	if !ok {
+		return shape
+	}
+	out := make(map[string]interface{}, len(stmt))
+	for k, v := range stmt {
+		out[k] = v
+	}
+	merged := make(map[string]interface{}, len(query))
+	for k, v := range query {
+		merged[k] = v
+	}
+	if where, ok := query["where"]; ok {
+		merged["where"] = map[string]interface{}{"and": []interface{}{where, stmt["policy"]}}
+	} else {
+		merged["where"] = stmt["policy"]
+	}
+	out["query"] = merged
+	delete(out, "policy")
+	return out
 }
 
 // canonicalize produces JCS (RFC 8785) canonical JSON
@@ -231,13 +1286,15 @@ func canonicalize(v interface{}) (string, error) {
 	// Canonicalize
 	canonical := canonicalizeValue(obj)
 
-	// Marshal back to canonical JSON
-	result, err := json.Marshal(canonical)
-	if err != nil {
+	// Marshal back to canonical JSON; JCS does not escape <, > and &
+	var buf strings.Builder
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(canonical); err != nil {
 		return "", err
 	}
 
-	return string(result), nil
+	return strings.TrimSuffix(buf.String(), "\n"), nil
 }
 
 func canonicalizeValue(val interface{}) interface{} {
@@ -268,6 +1325,46 @@ func canonicalizeValue(val interface{}) interface{} {
 	}
 }
 
+// structuralize replaces the value of every condition in a statement with a
+// placeholder naming its JSON type. Conditions only appear in conditions
+// arrays, so a generic walk finds all of them, subqueries included.
+func structuralize(val interface{}) interface{} {
+	switch val := val.(type) {
+	case map[string]interface{}:
+		for k, v := range val {
+			if conds, ok := v.([]interface{}); ok && k == "conditions" {
+				for _, c := range conds {
+					if cond, ok := c.(map[string]interface{}); ok {
+						if value, ok := cond["value"]; ok && value != nil {
+							cond["value"] = typePlaceholder(value)
+						}
+					}
+				}
+			}
+			structuralize(v)
+		}
+	case []interface{}:
+		for _, item := range val {
+			structuralize(item)
+		}
+	}
+	return val
+}
+
+func typePlaceholder(v interface{}) string {
+	switch v.(type) {
+	case string:
+		return "<string>"
+	case bool:
+		return "<boolean>"
+	case []interface{}:
+		return "<array>"
+	case map[string]interface{}:
+		return "<object>"
+	}
+	return "<number>"
+}
+
 func computeShapeID(canonical string) string {
 	hash := sha256.Sum256([]byte(canonical))
 	return "s_" + hex.EncodeToString(hash[:])
@@ -1,16 +1,25 @@
 // Package main synchronizes version across all files from VERSION file (SSOT)
+//
+// With -tag it also regenerates code from the renamed schema, commits the
+// bump and tags it vX.Y.Z, so a release bump is one command.
 package main
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 )
 
 func main() {
+	tag := flag.Bool("tag", false, "Regenerate code, commit the bump and create the vX.Y.Z git tag")
+	flag.Parse()
+
 	// Read version from VERSION file (single source of truth)
 	versionBytes, err := os.ReadFile("VERSION")
 	if err != nil {
@@ -71,12 +80,123 @@ func main() {
 		os.Exit(1)
 	}
 
+	// 6. Update schema path in the Go packages, scripts and docs
+	schemaRef := regexp.MustCompile(`schema/v\d+-\d+-\d+\.json`)
+	for _, path := range []string{
+		"codegen/lint.go",
+		"codegen/internal/generators/go_test.go",
+		"pkgs/go/types/doc.go",
+		"scripts/build.sh",
+		"README.md",
+		"schema/README.md",
+	} {
+		if err := updateFile(path, schemaRef, fmt.Sprintf("schema/v%s.json", versionDashed)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating %s: %v\n", path, err)
+			os.Exit(1)
+		}
+	}
+
+	// 7. Update version in the schema reference
+	if err := updateFile("schema/README.md",
+		regexp.MustCompile(`Universal Format v\d+\.\d+ —`),
+		fmt.Sprintf("Universal Format v%s —", versionMajorMinor)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating schema README: %v\n", err)
+		os.Exit(1)
+	}
+	if err := updateFile("schema/README.md",
+		regexp.MustCompile(`Current version: \*\*v\d+\.\d+\.\d+\*\*`),
+		fmt.Sprintf("Current version: **v%s**", version)); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating schema README: %v\n", err)
+		os.Exit(1)
+	}
+
+	// 8. Add a CHANGELOG section for the release
+	if err := addChangelogSection("CHANGELOG.md", version, time.Now().Format("2006-01-02")); err != nil {
+		fmt.Fprintf(os.Stderr, "Error updating CHANGELOG: %v\n", err)
+		os.Exit(1)
+	}
+
 	fmt.Println("✅ Version sync complete!")
+
+	if *tag {
+		if err := release(version, versionDashed); err != nil {
+			fmt.Fprintf(os.Stderr, "Error releasing v%s: %v\n", version, err)
+			os.Exit(1)
+		}
+		fmt.Printf("✅ Tagged v%s\n", version)
+		fmt.Printf("\nNext steps:\n")
+		fmt.Printf("  1. Review the release notes in CHANGELOG.md\n")
+		fmt.Printf("  2. Push: git push --follow-tags\n")
+		return
+	}
+
 	fmt.Printf("\nNext steps:\n")
-	fmt.Printf("  1. Run: go run tools/version/sync.go\n")
-	fmt.Printf("  2. Regenerate code: cd codegen && go run .\n")
-	fmt.Printf("  3. Run tests: ./scripts/test.sh\n")
+	fmt.Printf("  1. Regenerate code: (cd codegen && go build -o ../bin/codegen .) && ./bin/codegen -schema schema/v%s.json\n", versionDashed)
+	fmt.Printf("  2. Run tests: ./scripts/test.sh\n")
+	fmt.Printf("  3. Review the release notes in CHANGELOG.md\n")
 	fmt.Printf("  4. Commit: git add -A && git commit -m 'chore: bump version to v%s'\n", version)
+	fmt.Printf("\nOr run with -tag to regenerate, commit and tag in one step.\n")
+}
+
+// addChangelogSection adds a "## [version] - date" section under
+// Unreleased, so the unreleased notes become the release's, and a link for
+// it. A changelog that already has the section is left as it is.
+func addChangelogSection(path, version, date string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	content := string(data)
+	if strings.Contains(content, "## ["+version+"]") {
+		fmt.Printf("  ✓ %s already has v%s\n", path, version)
+		return nil
+	}
+
+	const unreleased = "## [Unreleased]\n"
+	if !strings.Contains(content, unreleased) {
+		return fmt.Errorf("%s has no %q section", path, strings.TrimSpace(unreleased))
+	}
+	content = strings.Replace(content, unreleased, fmt.Sprintf("%s\n## [%s] - %s\n", unreleased, version, date), 1)
+
+	// Newest link first, above the existing ones
+	link := fmt.Sprintf("[%s]: https://github.com/bold-minds/includekit-spec/releases/tag/v%s\n", version, version)
+	if loc := regexp.MustCompile(`(?m)^\[\d`).FindStringIndex(content); loc != nil {
+		content = content[:loc[0]] + link + content[loc[0]:]
+	} else {
+		content = strings.TrimRight(content, "\n") + "\n\n" + link
+	}
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("  ✓ Added v%s section to %s\n", version, path)
+	return nil
+}
+
+// release regenerates code from the renamed schema, commits everything and
+// creates an annotated vX.Y.Z tag. It refuses to tag over an existing tag.
+func release(version, versionDashed string) error {
+	tag := "v" + version
+	if err := exec.Command("git", "rev-parse", "-q", "--verify", "refs/tags/"+tag).Run(); err == nil {
+		return fmt.Errorf("tag %s already exists", tag)
+	}
+
+	build := exec.Command("go", "build", "-o", "../bin/codegen", ".")
+	build.Dir = "codegen"
+	steps := []*exec.Cmd{
+		build,
+		exec.Command("./bin/codegen", "-schema", fmt.Sprintf("schema/v%s.json", versionDashed)),
+		exec.Command("git", "add", "-A"),
+		exec.Command("git", "commit", "-m", "chore: bump version to "+tag),
+		exec.Command("git", "tag", "-a", tag, "-m", "Release "+tag),
+	}
+	for _, cmd := range steps {
+		cmd.Stdout, cmd.Stderr = os.Stdout, os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("%s: %w", strings.Join(cmd.Args, " "), err)
+		}
+	}
+	return nil
 }
 
 func syncSchema(version, versionDashed, versionMajorMinor string) error {
@@ -101,20 +221,16 @@ func syncSchema(version, versionDashed, versionMajorMinor string) error {
 		return err
 	}
 
-	var schema map[string]interface{}
-	if err := json.Unmarshal(data, &schema); err != nil {
-		return err
+	if !json.Valid(data) {
+		return fmt.Errorf("%s is not valid JSON", schemaPath)
 	}
 
-	// Update schema metadata
-	schema["$id"] = fmt.Sprintf("https://github.com/bold-minds/includekit-spec/schema/v%s.json", versionDashed)
-	schema["title"] = fmt.Sprintf("IncludeKit Universal Format v%s", versionMajorMinor)
-
-	// Write updated schema
-	updatedData, err := json.MarshalIndent(schema, "", "  ")
-	if err != nil {
-		return err
-	}
+	// Update schema metadata in place; codegen emits definitions in
+	// document order, which re-marshaling would sort
+	updatedData := regexp.MustCompile(`"\$id": "[^"]*"`).ReplaceAllLiteral(data,
+		[]byte(fmt.Sprintf(`"$id": "https://github.com/bold-minds/includekit-spec/schema/v%s.json"`, versionDashed)))
+	updatedData = regexp.MustCompile(`"title": "IncludeKit Universal Format v[^"]*"`).ReplaceAllLiteral(updatedData,
+		[]byte(fmt.Sprintf(`"title": "IncludeKit Universal Format v%s"`, versionMajorMinor)))
 
 	if err := os.WriteFile(newPath, updatedData, 0644); err != nil {
 		return err